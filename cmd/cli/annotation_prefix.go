@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/openservicemesh/osm/pkg/injector"
+)
+
+const annotationPrefixFlag = "--annotation-prefix"
+
+// getAnnotationPrefix returns the annotation prefix given with --annotation-prefix if set, or else the prefix the
+// sidecar injector of the given mesh is configured with, read from the arguments of its deployment. The mesh running
+// in the namespace given with --osm-namespace is used when no mesh name is given. The default prefix is returned when
+// the deployment of the sidecar injector is not found or does not set the prefix.
+func getAnnotationPrefix(clientSet kubernetes.Interface, meshName, prefix string) (string, error) {
+	if prefix != "" {
+		if err := injector.ValidateAnnotationPrefix(prefix); err != nil {
+			return "", errors.Errorf("Invalid value for flag %s: %s", annotationPrefixFlag, err)
+		}
+		return prefix, nil
+	}
+
+	namespace := settings.Namespace()
+	selector := map[string]string{"app": injectorLabel}
+	if meshName != "" {
+		namespace = "" // Find the mesh in any namespace
+		selector["meshName"] = meshName
+	}
+	deployments, err := clientSet.AppsV1().Deployments(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: labels.Set(selector).String(),
+	})
+	if err != nil {
+		return "", errors.Errorf("Error listing the sidecar injector deployments: %s", err)
+	}
+
+	for _, deployment := range deployments.Items {
+		for _, container := range deployment.Spec.Template.Spec.Containers {
+			if prefix := getArgValue(container.Args, annotationPrefixFlag); prefix != "" {
+				return prefix, nil
+			}
+		}
+	}
+	return injector.DefaultAnnotationPrefix, nil
+}
+
+// getArgValue returns the value of the given flag in the given container arguments, set either as "--flag value" or
+// as "--flag=value", or an empty string if the flag is not set
+func getArgValue(args []string, flag string) string {
+	for i, arg := range args {
+		if arg == flag && i+1 < len(args) {
+			return args[i+1]
+		}
+		if strings.HasPrefix(arg, flag+"=") {
+			return strings.TrimPrefix(arg, flag+"=")
+		}
+	}
+	return ""
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/injector"
+)
+
+func TestGetAnnotationPrefix(t *testing.T) {
+	osmNamespace := settings.Namespace()
+
+	testCases := []struct {
+		name           string
+		objects        []runtime.Object
+		meshName       string
+		prefix         string
+		expectedPrefix string
+		expectedErr    string
+	}{
+		{
+			name:           "prefix given with the flag",
+			objects:        []runtime.Object{newInjectorDeployment(osmNamespace, testMesh, "--annotation-prefix", "mesh.example.com")},
+			meshName:       testMesh,
+			prefix:         "flag.example.com",
+			expectedPrefix: "flag.example.com",
+		},
+		{
+			name:        "invalid prefix given with the flag",
+			meshName:    testMesh,
+			prefix:      "Not_A_Prefix",
+			expectedErr: fmt.Sprintf("Invalid value for flag --annotation-prefix: %s", injector.ValidateAnnotationPrefix("Not_A_Prefix")),
+		},
+		{
+			name:           "prefix read from the sidecar injector of the mesh",
+			objects:        []runtime.Object{newInjectorDeployment("osm-prod", testMesh, "--verbosity", "info", "--annotation-prefix", "mesh.example.com")},
+			meshName:       testMesh,
+			expectedPrefix: "mesh.example.com",
+		},
+		{
+			name:           "prefix read from an argument with a value",
+			objects:        []runtime.Object{newInjectorDeployment(osmNamespace, testMesh, "--annotation-prefix=mesh.example.com")},
+			meshName:       testMesh,
+			expectedPrefix: "mesh.example.com",
+		},
+		{
+			name:           "prefix read from the sidecar injector in the OSM namespace without a mesh name",
+			objects:        []runtime.Object{newInjectorDeployment(osmNamespace, testMesh, "--annotation-prefix", "mesh.example.com")},
+			expectedPrefix: "mesh.example.com",
+		},
+		{
+			name:           "sidecar injector of another mesh",
+			objects:        []runtime.Object{newInjectorDeployment(osmNamespace, "other", "--annotation-prefix", "mesh.example.com")},
+			meshName:       testMesh,
+			expectedPrefix: injector.DefaultAnnotationPrefix,
+		},
+		{
+			name:           "sidecar injector without the prefix argument",
+			objects:        []runtime.Object{newInjectorDeployment(osmNamespace, testMesh, "--verbosity", "info")},
+			meshName:       testMesh,
+			expectedPrefix: injector.DefaultAnnotationPrefix,
+		},
+		{
+			name:           "sidecar injector not found",
+			meshName:       testMesh,
+			expectedPrefix: injector.DefaultAnnotationPrefix,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			prefix, err := getAnnotationPrefix(fake.NewSimpleClientset(tc.objects...), tc.meshName, tc.prefix)
+			if tc.expectedErr != "" {
+				assert.EqualError(err, tc.expectedErr)
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(tc.expectedPrefix, prefix)
+		})
+	}
+}
@@ -0,0 +1,383 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiSpecs "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/specs/v1alpha4"
+	smiSplit "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/split/v1alpha2"
+	"github.com/spf13/cobra"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes"
+
+	k8s "github.com/openservicemesh/osm/pkg/kubernetes"
+)
+
+const checkCmdDescription = `
+This command checks that the cluster is ready to run OSM and that the control
+plane of the mesh is healthy. Each check is run as a named probe reporting one
+of the following statuses:
+
+  pass: the check succeeded
+  warn: the check found a problem that does not prevent the mesh from working
+  fail: the check found a problem that prevents the mesh from working
+
+Warnings and failures are followed by a hint to remediate the problem. The
+command exits with a non-zero exit code when any of the probes fails.
+
+Use --pre-install before installing OSM to only run the probes that do not
+depend on the control plane of the mesh.
+
+Use 'osm check webhook' to check the configuration of the sidecar injector
+webhook field by field, and 'osm check injection' to detect the pods lacking a
+sidecar in the monitored namespaces.
+`
+
+const checkCmdExample = `
+# Check that the cluster is ready to install OSM
+osm check --pre-install
+
+# Check the mesh named 'osm' whose control plane runs in the 'osm-system' namespace
+osm check
+
+# Check the mesh named 'prod' whose control plane runs in the 'osm-prod' namespace
+osm check --mesh-name prod --osm-namespace osm-prod
+`
+
+const (
+	// webhookConfigNamePrefix is the default prefix of the name of the MutatingWebhookConfiguration of a mesh
+	webhookConfigNamePrefix = "osm-webhook"
+
+	// sidecarInjectorWebhookName is the name of the webhook injecting the sidecar into the pods of a mesh
+	sidecarInjectorWebhookName = "osm-inject.k8s.io"
+)
+
+// minKubernetesVersion is the minimum version of Kubernetes supported by OSM, as required by the OSM chart
+var minKubernetesVersion = []int{1, 18, 0}
+
+// smiGroupVersions are the SMI API versions whose CRDs must be installed for OSM to run
+var smiGroupVersions = []schema.GroupVersion{
+	smiAccess.SchemeGroupVersion,
+	smiSpecs.SchemeGroupVersion,
+	smiSplit.SchemeGroupVersion,
+}
+
+// probeStatus is the status reported by a probe
+type probeStatus string
+
+const (
+	probeStatusPass probeStatus = "pass"
+	probeStatusWarn probeStatus = "warn"
+	probeStatusFail probeStatus = "fail"
+)
+
+// probeResult is the result of a probe, the hint remediates the problem reported by a warning or a failure
+type probeResult struct {
+	status  probeStatus
+	message string
+	hint    string
+}
+
+// probe is a named check run by osm check
+type probe struct {
+	name string
+	run  func() probeResult
+}
+
+type checkCmd struct {
+	out            io.Writer
+	clientSet      kubernetes.Interface
+	meshName       string
+	meshConfigName string
+	preInstall     bool
+}
+
+func newCheckCmd(out io.Writer) *cobra.Command {
+	checkCmd := &checkCmd{
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "check",
+		Short: "check that the cluster is ready to run OSM",
+		Long:  checkCmdDescription,
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			config, err := settings.RESTClientGetter().ToRESTConfig()
+			if err != nil {
+				return errors.Errorf("Error fetching kubeconfig: %s", err)
+			}
+
+			clientset, err := kubernetes.NewForConfig(config)
+			if err != nil {
+				return errors.Errorf("Could not access Kubernetes cluster, check kubeconfig: %s", err)
+			}
+			checkCmd.clientSet = clientset
+			return checkCmd.run()
+		},
+		Example: checkCmdExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&checkCmd.meshName, "mesh-name", defaultMeshName, "Name of the mesh to check")
+	f.StringVar(&checkCmd.meshConfigName, "mesh-config-name", osmConfigMapName, "Name of the mesh config in the namespace of the control plane")
+	f.BoolVar(&checkCmd.preInstall, "pre-install", false, "Only run the probes checking that the cluster is ready to install OSM")
+
+	cmd.AddCommand(newCheckWebhookCmd(out))
+	cmd.AddCommand(newCheckInjectionCmd(out))
+	cmd.AddCommand(newCheckPortConflictsCmd(out))
+
+	return cmd
+}
+
+func (cmd *checkCmd) run() error {
+	probes := cmd.probes()
+
+	failed, _ := runProbes(cmd.out, probes)
+	if failed > 0 {
+		return errors.Errorf("%d of %d checks failed", failed, len(probes))
+	}
+	fmt.Fprintln(cmd.out, "All checks passed")
+	return nil
+}
+
+// runProbes runs the given probes in order and prints their results, it returns the number of probes that failed and
+// the number of probes that warned
+func runProbes(out io.Writer, probes []probe) (int, int) {
+	var failed, warned int
+	for _, p := range probes {
+		result := p.run()
+		fmt.Fprintf(out, "[%s] %s: %s\n", result.status, p.name, result.message)
+		if result.hint != "" {
+			fmt.Fprintf(out, "       hint: %s\n", result.hint)
+		}
+		switch result.status {
+		case probeStatusFail:
+			failed++
+		case probeStatusWarn:
+			warned++
+		}
+	}
+	return failed, warned
+}
+
+// probes returns the probes to run, in order
+func (cmd *checkCmd) probes() []probe {
+	probes := []probe{
+		{name: "Kubernetes version", run: cmd.checkKubernetesVersion},
+		{name: "SMI CRDs", run: cmd.checkSMICRDs},
+	}
+	if cmd.preInstall {
+		return probes
+	}
+	return append(probes,
+		probe{name: "Mesh config", run: cmd.checkMeshConfig},
+		probe{name: "Sidecar injector webhook", run: cmd.checkSidecarInjectorWebhook},
+	)
+}
+
+func (cmd *checkCmd) checkKubernetesVersion() probeResult {
+	version, err := k8s.GetKubernetesServerVersionNumber(cmd.clientSet)
+	if err != nil {
+		return probeResult{
+			status:  probeStatusFail,
+			message: err.Error(),
+			hint:    "Check that the Kubernetes API server is reachable with the current kubeconfig",
+		}
+	}
+
+	if compareVersions(version, minKubernetesVersion) < 0 {
+		return probeResult{
+			status:  probeStatusFail,
+			message: fmt.Sprintf("Kubernetes %s is not supported, the minimum supported version is %s", formatVersion(version), formatVersion(minKubernetesVersion)),
+			hint:    fmt.Sprintf("Upgrade the cluster to Kubernetes %s or later", formatVersion(minKubernetesVersion)),
+		}
+	}
+	return probeResult{
+		status:  probeStatusPass,
+		message: fmt.Sprintf("Kubernetes %s is supported", formatVersion(version)),
+	}
+}
+
+func (cmd *checkCmd) checkSMICRDs() probeResult {
+	groups, err := cmd.clientSet.Discovery().ServerGroups()
+	if err != nil {
+		return probeResult{
+			status:  probeStatusFail,
+			message: fmt.Sprintf("Error discovering the API groups of the cluster: %s", err),
+			hint:    "Check that the Kubernetes API server is reachable with the current kubeconfig",
+		}
+	}
+
+	servedVersions := make(map[schema.GroupVersion]bool)
+	for _, group := range groups.Groups {
+		for _, version := range group.Versions {
+			servedVersions[schema.GroupVersion{Group: group.Name, Version: version.Version}] = true
+		}
+	}
+
+	var missing []string
+	for _, groupVersion := range smiGroupVersions {
+		if !servedVersions[groupVersion] {
+			missing = append(missing, groupVersion.String())
+		}
+	}
+	if len(missing) == 0 {
+		return probeResult{
+			status:  probeStatusPass,
+			message: "The SMI APIs used by OSM are served",
+		}
+	}
+
+	result := probeResult{
+		status:  probeStatusFail,
+		message: fmt.Sprintf("The SMI APIs %s are not served", strings.Join(missing, ", ")),
+		hint:    "Install the SMI CRDs of the OSM chart, they are installed by osm install",
+	}
+	if cmd.preInstall {
+		// The CRDs are installed along with the control plane
+		result.status = probeStatusWarn
+	}
+	return result
+}
+
+func (cmd *checkCmd) checkMeshConfig() probeResult {
+	osmNamespace := settings.Namespace()
+	configMap, err := getMeshConfig(cmd.clientSet, osmNamespace, cmd.meshConfigName)
+	if err != nil {
+		return probeResult{
+			status:  probeStatusFail,
+			message: err.Error(),
+			hint:    fmt.Sprintf("Check that OSM is installed in namespace %s, or use --osm-namespace with the namespace of the control plane", osmNamespace),
+		}
+	}
+	return probeResult{
+		status:  probeStatusPass,
+		message: fmt.Sprintf("Mesh config %s/%s exists", configMap.Namespace, configMap.Name),
+	}
+}
+
+func (cmd *checkCmd) checkSidecarInjectorWebhook() probeResult {
+	webhook, result := getSidecarInjectorWebhook(cmd.clientSet, cmd.meshName)
+	if webhook == nil {
+		return result
+	}
+
+	service := webhook.ClientConfig.Service
+	if service == nil {
+		return probeResult{
+			status:  probeStatusFail,
+			message: fmt.Sprintf("Webhook %s of MutatingWebhookConfiguration %s-%s does not reference the sidecar injector service", sidecarInjectorWebhookName, webhookConfigNamePrefix, cmd.meshName),
+			hint:    "Reinstall OSM to restore the MutatingWebhookConfiguration",
+		}
+	}
+
+	ready, err := hasReadyEndpoints(cmd.clientSet, service.Namespace, service.Name)
+	if err != nil {
+		return probeResult{
+			status:  probeStatusFail,
+			message: fmt.Sprintf("Error fetching the endpoints of service %s/%s: %s", service.Namespace, service.Name, err),
+			hint:    fmt.Sprintf("Check that the sidecar injector service %s exists in namespace %s", service.Name, service.Namespace),
+		}
+	}
+	if !ready {
+		return probeResult{
+			status:  probeStatusFail,
+			message: fmt.Sprintf("Service %s/%s of the sidecar injector webhook has no ready endpoints", service.Namespace, service.Name),
+			hint:    fmt.Sprintf("Check the status and logs of the %s pods in namespace %s", service.Name, service.Namespace),
+		}
+	}
+	if len(webhook.ClientConfig.CABundle) == 0 {
+		return probeResult{
+			status:  probeStatusWarn,
+			message: fmt.Sprintf("Webhook %s has no CA bundle, the API server cannot verify the sidecar injector yet", sidecarInjectorWebhookName),
+			hint:    fmt.Sprintf("The CA bundle is patched by the sidecar injector when it starts, check the logs of the %s pods in namespace %s", service.Name, service.Namespace),
+		}
+	}
+	return probeResult{
+		status:  probeStatusPass,
+		message: fmt.Sprintf("Webhook %s is registered and service %s/%s is ready", sidecarInjectorWebhookName, service.Namespace, service.Name),
+	}
+}
+
+// getSidecarInjectorWebhook returns the sidecar injector webhook of the given mesh along with the result of a probe
+// checking that it is registered, the webhook is nil when the probe fails
+func getSidecarInjectorWebhook(clientSet kubernetes.Interface, meshName string) (*admissionregv1.MutatingWebhook, probeResult) {
+	webhookConfigName := fmt.Sprintf("%s-%s", webhookConfigNamePrefix, meshName)
+	webhookConfig, err := clientSet.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(context.TODO(), webhookConfigName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, probeResult{
+			status:  probeStatusFail,
+			message: fmt.Sprintf("MutatingWebhookConfiguration %s not found", webhookConfigName),
+			hint:    fmt.Sprintf("Check that the mesh %s is installed, or use --mesh-name with the name of the mesh", meshName),
+		}
+	}
+	if err != nil {
+		return nil, probeResult{
+			status:  probeStatusFail,
+			message: fmt.Sprintf("Error fetching MutatingWebhookConfiguration %s: %s", webhookConfigName, err),
+		}
+	}
+
+	for i := range webhookConfig.Webhooks {
+		if webhookConfig.Webhooks[i].Name == sidecarInjectorWebhookName {
+			return &webhookConfig.Webhooks[i], probeResult{
+				status:  probeStatusPass,
+				message: fmt.Sprintf("Webhook %s of MutatingWebhookConfiguration %s is registered", sidecarInjectorWebhookName, webhookConfigName),
+			}
+		}
+	}
+	return nil, probeResult{
+		status:  probeStatusFail,
+		message: fmt.Sprintf("MutatingWebhookConfiguration %s has no webhook named %s", webhookConfigName, sidecarInjectorWebhookName),
+		hint:    "Reinstall OSM to restore the MutatingWebhookConfiguration",
+	}
+}
+
+// hasReadyEndpoints returns true if the given service has at least one ready endpoint
+func hasReadyEndpoints(clientSet kubernetes.Interface, namespace, name string) (bool, error) {
+	endpoints, err := clientSet.CoreV1().Endpoints(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return false, err
+	}
+	for _, subset := range endpoints.Subsets {
+		if len(subset.Addresses) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// compareVersions compares the given version numbers chunk by chunk, it returns a negative number if a is lower than
+// b, zero if they are equal, and a positive number if a is greater than b. Missing chunks are treated as zeros.
+func compareVersions(a, b []int) int {
+	for i := 0; i < len(a) || i < len(b); i++ {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			return x - y
+		}
+	}
+	return 0
+}
+
+// formatVersion formats the given version number, ex. [1, 19, 3] => 1.19.3
+func formatVersion(version []int) string {
+	chunks := make([]string, len(version))
+	for i, chunk := range version {
+		chunks[i] = fmt.Sprint(chunk)
+	}
+	return strings.Join(chunks, ".")
+}
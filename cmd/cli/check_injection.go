@@ -0,0 +1,350 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+	"github.com/openservicemesh/osm/pkg/injector"
+)
+
+const checkInjectionDescription = `
+This command detects the pods that were silently admitted without a sidecar.
+It scans the pods of the namespaces monitored by the mesh, except the namespaces
+explicitly ignored, and reports each pod that should have a sidecar but lacks
+the osm-proxy-uuid label or the envoy container.
+
+The sidecar injection annotation and the osm-proxy-uuid label take the
+annotation prefix the sidecar injector of the mesh is configured with, unless
+given with --annotation-prefix.
+
+A pod should have a sidecar when it is annotated to enable sidecar injection,
+or when its namespace is annotated to enable sidecar injection and the pod is
+not annotated to disable it, as decided by the sidecar injector. Pods that have
+terminated are skipped.
+
+Each pod lacking a sidecar is reported with the likely causes found among:
+
+  - the sidecar injector webhook is not registered, or its namespace selector
+    does not select the namespace of the pod
+  - the sidecar injector service has no ready endpoints while the failure
+    policy of the webhook admits pods when the webhook is unreachable
+  - the pod was created before its namespace was enrolled in the mesh
+  - a ResourceQuota of the namespace is exhausted, so that the pods recreated
+    with the sidecar are rejected
+
+The command exits with a non-zero exit code when any pod lacks a sidecar.
+`
+
+const checkInjectionExample = `
+# Detect the pods lacking a sidecar in the namespaces monitored by the mesh named 'osm'
+osm check injection
+
+# Detect the pods lacking a sidecar in the 'bookstore' namespace monitored by the mesh named 'prod'
+osm check injection --mesh-name prod --osm-namespace osm-prod -n bookstore
+`
+
+// quotaResourcesBlockingSidecar are the resources of a ResourceQuota whose exhaustion rejects the pods recreated with
+// the sidecar, as the sidecar requests more of them
+var quotaResourcesBlockingSidecar = []corev1.ResourceName{
+	corev1.ResourcePods,
+	corev1.ResourceCPU,
+	corev1.ResourceMemory,
+	corev1.ResourceRequestsCPU,
+	corev1.ResourceRequestsMemory,
+	corev1.ResourceLimitsCPU,
+	corev1.ResourceLimitsMemory,
+}
+
+type checkInjectionCmd struct {
+	out       io.Writer
+	clientSet kubernetes.Interface
+	meshName  string
+	namespace string
+	// annotationPrefix is the prefix of the sidecar injection annotation and of the proxy UUID label
+	annotationPrefix string
+
+	// webhook is the sidecar injector webhook of the mesh, nil if it is not registered
+	webhook *admissionregv1.MutatingWebhook
+	// webhookCause is the likely cause of the pods lacking a sidecar in every namespace found on the webhook
+	webhookCause string
+}
+
+func newCheckInjectionCmd(out io.Writer) *cobra.Command {
+	injectionCmd := &checkInjectionCmd{
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "injection",
+		Short: "detect the pods lacking a sidecar in the monitored namespaces",
+		Long:  checkInjectionDescription,
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			config, err := settings.RESTClientGetter().ToRESTConfig()
+			if err != nil {
+				return errors.Errorf("Error fetching kubeconfig: %s", err)
+			}
+
+			clientset, err := kubernetes.NewForConfig(config)
+			if err != nil {
+				return errors.Errorf("Could not access Kubernetes cluster, check kubeconfig: %s", err)
+			}
+			injectionCmd.clientSet = clientset
+			return injectionCmd.run()
+		},
+		Example: checkInjectionExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&injectionCmd.meshName, "mesh-name", defaultMeshName, "Name of the mesh whose namespaces are checked")
+	f.StringVarP(&injectionCmd.namespace, "namespace", "n", "", "Namespace of the pods, all the monitored namespaces if unset")
+	f.StringVar(&injectionCmd.annotationPrefix, "annotation-prefix", "", "Prefix of the sidecar injection annotation and the proxy UUID label, the prefix the sidecar injector of the mesh is configured with if unset")
+
+	return cmd
+}
+
+func (cmd *checkInjectionCmd) run() error {
+	prefix, err := getAnnotationPrefix(cmd.clientSet, cmd.meshName, cmd.annotationPrefix)
+	if err != nil {
+		return err
+	}
+	cmd.annotationPrefix = prefix
+
+	namespaces, err := cmd.listInjectedNamespaces()
+	if err != nil {
+		return err
+	}
+
+	webhook, webhookResult := getSidecarInjectorWebhook(cmd.clientSet, cmd.meshName)
+	cmd.webhook = webhook
+	if webhook == nil {
+		cmd.webhookCause = webhookResult.message
+	} else {
+		cmd.webhookCause = cmd.getWebhookServiceCause()
+	}
+
+	var probes []probe
+	expected := 0
+	for i := range namespaces {
+		ns := &namespaces[i]
+		pods, err := cmd.clientSet.CoreV1().Pods(ns.Name).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return errors.Errorf("Error listing pods in namespace %s: %s", ns.Name, err)
+		}
+		sort.Slice(pods.Items, func(i, j int) bool {
+			return pods.Items[i].Name < pods.Items[j].Name
+		})
+
+		var quotas []corev1.ResourceQuota
+		for j := range pods.Items {
+			pod := &pods.Items[j]
+			if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed || !isSidecarInjectionExpected(ns, pod, cmd.annotationPrefix) {
+				continue
+			}
+			expected++
+
+			missing := getMissingSidecarParts(pod, cmd.annotationPrefix)
+			if len(missing) == 0 {
+				continue
+			}
+			if quotas == nil {
+				quotaList, err := cmd.clientSet.CoreV1().ResourceQuotas(ns.Name).List(context.TODO(), metav1.ListOptions{})
+				if err != nil {
+					return errors.Errorf("Error listing resource quotas in namespace %s: %s", ns.Name, err)
+				}
+				quotas = append([]corev1.ResourceQuota{}, quotaList.Items...)
+			}
+
+			result := probeResult{
+				status:  probeStatusFail,
+				message: fmt.Sprintf("Pod should have a sidecar but has no %s", strings.Join(missing, " and no ")),
+				hint:    cmd.getInjectionSkipHint(ns, pod, quotas),
+			}
+			probes = append(probes, probe{name: "Pod " + pod.Namespace + namespaceSeparator + pod.Name, run: func() probeResult { return result }})
+		}
+	}
+
+	if len(probes) == 0 {
+		result := probeResult{
+			status:  probeStatusPass,
+			message: fmt.Sprintf("The %d pods that should have a sidecar in %d namespaces are injected", expected, len(namespaces)),
+		}
+		probes = append(probes, probe{name: "Sidecar injection", run: func() probeResult { return result }})
+	}
+
+	failed, _ := runProbes(cmd.out, probes)
+	if failed > 0 {
+		return errors.Errorf("%d of %d pods that should have a sidecar are not injected", failed, expected)
+	}
+	fmt.Fprintln(cmd.out, "All checks passed")
+	return nil
+}
+
+// listInjectedNamespaces returns the namespaces monitored by the mesh that are not explicitly ignored, sorted by name,
+// or the namespace given with --namespace. The namespace of the control plane and the system namespaces are never
+// injected by the sidecar injector and are not returned.
+func (cmd *checkInjectionCmd) listInjectedNamespaces() ([]corev1.Namespace, error) {
+	selector := fmt.Sprintf("%s=%s", constants.OSMKubeResourceMonitorAnnotation, cmd.meshName)
+	namespaces, err := cmd.clientSet.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, errors.Errorf("Error listing the namespaces monitored by the mesh %s: %s", cmd.meshName, err)
+	}
+
+	var injected []corev1.Namespace
+	found := false
+	for _, ns := range namespaces.Items {
+		if cmd.namespace != "" && ns.Name != cmd.namespace {
+			continue
+		}
+		found = true
+		if _, ignored := ns.Labels[ignoreLabel]; ignored {
+			continue
+		}
+		switch ns.Name {
+		case settings.Namespace(), metav1.NamespaceSystem, metav1.NamespacePublic:
+			continue
+		}
+		injected = append(injected, ns)
+	}
+	if cmd.namespace != "" && !found {
+		return nil, errors.Errorf("Namespace %s is not monitored by the mesh %s", cmd.namespace, cmd.meshName)
+	}
+	sort.Slice(injected, func(i, j int) bool {
+		return injected[i].Name < injected[j].Name
+	})
+	return injected, nil
+}
+
+// isSidecarInjectionExpected returns true if the sidecar injector configured with the given annotation prefix injects
+// the sidecar into the given pod of the given monitored namespace, the annotation of the pod taking precedence over the
+// annotation of the namespace
+func isSidecarInjectionExpected(ns *corev1.Namespace, pod *corev1.Pod, annotationPrefix string) bool {
+	sidecarInjectionAnnotation := injector.ResolveAnnotation(annotationPrefix, constants.SidecarInjectionAnnotation)
+	switch strings.ToLower(pod.Annotations[sidecarInjectionAnnotation]) {
+	case "enabled", "yes", "true":
+		return true
+	case "disabled", "no", "false":
+		return false
+	default:
+		return isSidecarInjectionEnabled(ns, sidecarInjectionAnnotation)
+	}
+}
+
+// getMissingSidecarParts returns the parts of the sidecar the given pod lacks, among the proxy UUID label for the given
+// annotation prefix and the envoy container
+func getMissingSidecarParts(pod *corev1.Pod, annotationPrefix string) []string {
+	var missing []string
+	proxyUUIDLabel := injector.ProxyUUIDLabel(annotationPrefix)
+	if _, ok := pod.Labels[proxyUUIDLabel]; !ok {
+		missing = append(missing, fmt.Sprintf("%s label", proxyUUIDLabel))
+	}
+	hasEnvoyContainer := false
+	for _, container := range pod.Spec.Containers {
+		if container.Name == constants.EnvoyContainerName {
+			hasEnvoyContainer = true
+			break
+		}
+	}
+	if !hasEnvoyContainer {
+		missing = append(missing, fmt.Sprintf("%s container", constants.EnvoyContainerName))
+	}
+	return missing
+}
+
+// getWebhookServiceCause returns why the sidecar injector webhook admits pods without a sidecar when its service has no
+// ready endpoints and its failure policy ignores the failures of the webhook, or an empty string otherwise
+func (cmd *checkInjectionCmd) getWebhookServiceCause() string {
+	service := cmd.webhook.ClientConfig.Service
+	if service == nil || cmd.webhook.FailurePolicy == nil || *cmd.webhook.FailurePolicy != admissionregv1.Ignore {
+		return ""
+	}
+	ready, err := hasReadyEndpoints(cmd.clientSet, service.Namespace, service.Name)
+	if err == nil && ready {
+		return ""
+	}
+	return fmt.Sprintf("the sidecar injector service %s/%s has no ready endpoints and the failure policy %s of the webhook admits pods without a sidecar",
+		service.Namespace, service.Name, admissionregv1.Ignore)
+}
+
+// getInjectionSkipHint returns the likely causes of the given pod lacking a sidecar, along with how to remediate them
+func (cmd *checkInjectionCmd) getInjectionSkipHint(ns *corev1.Namespace, pod *corev1.Pod, quotas []corev1.ResourceQuota) string {
+	var causes []string
+	if cmd.webhookCause != "" {
+		causes = append(causes, cmd.webhookCause)
+	}
+	if cmd.webhook != nil && cmd.webhook.NamespaceSelector != nil {
+		selector, err := metav1.LabelSelectorAsSelector(cmd.webhook.NamespaceSelector)
+		if err == nil && !selector.Matches(labels.Set(ns.Labels)) {
+			causes = append(causes, fmt.Sprintf("the namespace selector %q of the sidecar injector webhook does not select namespace %s", selector, ns.Name))
+		}
+	}
+	if enrolled := getNamespaceEnrollmentTime(ns, cmd.annotationPrefix); !enrolled.IsZero() && pod.CreationTimestamp.Time.Before(enrolled) {
+		causes = append(causes, fmt.Sprintf("the pod was created at %s, before namespace %s was enrolled in the mesh at %s",
+			pod.CreationTimestamp.UTC().Format(time.RFC3339), ns.Name, enrolled.UTC().Format(time.RFC3339)))
+	}
+	for _, quota := range quotas {
+		if exhausted := getExhaustedQuotaResources(quota); len(exhausted) > 0 {
+			causes = append(causes, fmt.Sprintf("ResourceQuota %s is exhausted for %s, the pod may not be recreated with the sidecar", quota.Name, strings.Join(exhausted, ", ")))
+		}
+	}
+
+	if len(causes) == 0 {
+		return "No likely cause found, check the logs of the sidecar injector pods, then restart the pod to inject the sidecar"
+	}
+	return fmt.Sprintf("Likely causes: %s; restart the pod to inject the sidecar once fixed", strings.Join(causes, "; "))
+}
+
+// getNamespaceEnrollmentTime returns the last time the label enrolling the given namespace in the mesh or the
+// annotation enabling sidecar injection, with the given annotation prefix, were set, according to the managed fields of
+// the namespace, or the zero time when unknown
+func getNamespaceEnrollmentTime(ns *corev1.Namespace, annotationPrefix string) time.Time {
+	sidecarInjectionAnnotation := injector.ResolveAnnotation(annotationPrefix, constants.SidecarInjectionAnnotation)
+	var enrolled time.Time
+	for _, entry := range ns.ManagedFields {
+		if entry.Time == nil || entry.FieldsV1 == nil {
+			continue
+		}
+		fields := string(entry.FieldsV1.Raw)
+		if strings.Contains(fields, fmt.Sprintf("%q", "f:"+constants.OSMKubeResourceMonitorAnnotation)) ||
+			strings.Contains(fields, fmt.Sprintf("%q", "f:"+sidecarInjectionAnnotation)) {
+			if entry.Time.Time.After(enrolled) {
+				enrolled = entry.Time.Time
+			}
+		}
+	}
+	return enrolled
+}
+
+// getExhaustedQuotaResources returns the resources of the given ResourceQuota whose usage reached their hard limit,
+// among the resources requested by the sidecar
+func getExhaustedQuotaResources(quota corev1.ResourceQuota) []string {
+	var exhausted []string
+	for _, name := range quotaResourcesBlockingSidecar {
+		hard, ok := quota.Status.Hard[name]
+		if !ok {
+			continue
+		}
+		used, ok := quota.Status.Used[name]
+		if !ok {
+			used = resource.Quantity{}
+		}
+		if used.Cmp(hard) >= 0 {
+			exhausted = append(exhausted, string(name))
+		}
+	}
+	return exhausted
+}
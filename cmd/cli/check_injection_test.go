@@ -0,0 +1,285 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	tassert "github.com/stretchr/testify/assert"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+func TestCheckInjection(t *testing.T) {
+	osmNamespace := settings.Namespace()
+	enrolledAt := time.Date(2021, time.January, 2, 0, 0, 0, 0, time.UTC)
+
+	monitored := map[string]string{constants.OSMKubeResourceMonitorAnnotation: defaultMeshName}
+	injectionEnabled := map[string]string{constants.SidecarInjectionAnnotation: "enabled"}
+	enrolledNamespace := newNamespace("bookstore", injectionEnabled)
+	enrolledNamespace.ManagedFields = []metav1.ManagedFieldsEntry{
+		{
+			Manager:  "osm",
+			Time:     &metav1.Time{Time: enrolledAt},
+			FieldsV1: &metav1.FieldsV1{Raw: []byte(fmt.Sprintf(`{"f:metadata":{"f:labels":{"f:%s":{}}}}`, constants.OSMKubeResourceMonitorAnnotation))},
+		},
+	}
+	legacyNamespace := newNamespace("legacy", injectionEnabled)
+	legacyNamespace.Labels[ignoreLabel] = "true"
+	otherMeshNamespace := newNamespace("other-mesh", injectionEnabled)
+	otherMeshNamespace.Labels[constants.OSMKubeResourceMonitorAnnotation] = "other"
+	namespaces := []runtime.Object{
+		enrolledNamespace,
+		newNamespace("bookbuyer", nil),
+		legacyNamespace,
+		otherMeshNamespace,
+	}
+
+	afterEnrollment := enrolledAt.Add(time.Hour)
+	injectedPods := []runtime.Object{
+		newTestPod("bookstore", "bookstore-1", "", true),
+		newTestPod("bookbuyer", "bookbuyer-1", "", true),
+		newTestPod("bookbuyer", "bookbuyer-2", "", false),
+		newTestPod("bookstore", "bookstore-job", "", false),
+		newTestPod("bookstore", "bookstore-opt-out", "", false),
+		newTestPod("legacy", "legacy-1", "", false),
+		newTestPod("other-mesh", "other-1", "", false),
+	}
+	for _, pod := range injectedPods {
+		pod.(*corev1.Pod).CreationTimestamp = metav1.Time{Time: afterEnrollment}
+	}
+	for _, pod := range injectedPods[:2] {
+		pod.(*corev1.Pod).Spec.Containers = []corev1.Container{{Name: "app"}, {Name: constants.EnvoyContainerName}}
+	}
+	injectedPods[1].(*corev1.Pod).Annotations = map[string]string{constants.SidecarInjectionAnnotation: "yes"}
+	injectedPods[3].(*corev1.Pod).Status.Phase = corev1.PodSucceeded
+	injectedPods[4].(*corev1.Pod).Annotations = map[string]string{constants.SidecarInjectionAnnotation: "disabled"}
+
+	uninjectedPod := newTestPod("bookstore", "bookstore-2", "", false)
+	uninjectedPod.CreationTimestamp = metav1.Time{Time: afterEnrollment}
+	sidecarlessPod := newTestPod("bookstore", "bookstore-2", "", true)
+	sidecarlessPod.CreationTimestamp = metav1.Time{Time: afterEnrollment}
+	annotatedPod := newTestPod("bookbuyer", "bookbuyer-3", "", false)
+	annotatedPod.CreationTimestamp = metav1.Time{Time: afterEnrollment}
+	annotatedPod.Annotations = map[string]string{constants.SidecarInjectionAnnotation: "true"}
+	preEnrollmentPod := newTestPod("bookstore", "bookstore-0", "", false)
+	preEnrollmentPod.CreationTimestamp = metav1.Time{Time: enrolledAt.Add(-time.Hour)}
+
+	// With a custom annotation prefix, the pods are injected when annotated with the prefix and labeled with it
+	prefixedInjector := newInjectorDeployment(osmNamespace, defaultMeshName, "--annotation-prefix", "mesh.example.com")
+	prefixedPod := newTestPod("bookbuyer", "bookbuyer-4", "", false)
+	prefixedPod.CreationTimestamp = metav1.Time{Time: afterEnrollment}
+	prefixedPod.Annotations = map[string]string{"mesh.example.com/sidecar-injection": "enabled"}
+	prefixedPod.Labels = map[string]string{"mesh.example.com/" + constants.EnvoyUniqueIDLabelName: "bookbuyer-4-uuid"}
+	prefixedPod.Spec.Containers = []corev1.Container{{Name: "app"}, {Name: constants.EnvoyContainerName}}
+	unprefixedPod := newTestPod("bookbuyer", "bookbuyer-5", "", true)
+	unprefixedPod.CreationTimestamp = metav1.Time{Time: afterEnrollment}
+	unprefixedPod.Annotations = map[string]string{"mesh.example.com/sidecar-injection": "enabled"}
+	unprefixedPod.Spec.Containers = []corev1.Container{{Name: "app"}, {Name: constants.EnvoyContainerName}}
+
+	ignore := admissionregv1.Ignore
+	port := int32(9090)
+	newWebhookConfig := func(selector *metav1.LabelSelector, failurePolicy *admissionregv1.FailurePolicyType) *admissionregv1.MutatingWebhookConfiguration {
+		return &admissionregv1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "osm-webhook-osm"},
+			Webhooks: []admissionregv1.MutatingWebhook{
+				{
+					Name: sidecarInjectorWebhookName,
+					ClientConfig: admissionregv1.WebhookClientConfig{
+						Service: &admissionregv1.ServiceReference{Name: "osm-injector", Namespace: osmNamespace, Port: &port},
+					},
+					NamespaceSelector: selector,
+					FailurePolicy:     failurePolicy,
+				},
+			},
+		}
+	}
+	chartSelector := &metav1.LabelSelector{
+		MatchLabels: monitored,
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: ignoreLabel, Operator: metav1.LabelSelectorOpDoesNotExist},
+		},
+	}
+	injectorEndpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "osm-injector", Namespace: osmNamespace},
+		Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}}},
+	}
+	exhaustedQuota := &corev1.ResourceQuota{
+		ObjectMeta: metav1.ObjectMeta{Name: "compute", Namespace: "bookstore"},
+		Status: corev1.ResourceQuotaStatus{
+			Hard: corev1.ResourceList{
+				corev1.ResourceRequestsCPU:    resource.MustParse("2"),
+				corev1.ResourceRequestsMemory: resource.MustParse("1Gi"),
+			},
+			Used: corev1.ResourceList{
+				corev1.ResourceRequestsCPU:    resource.MustParse("2"),
+				corev1.ResourceRequestsMemory: resource.MustParse("512Mi"),
+			},
+		},
+	}
+	objects := func(extra ...runtime.Object) []runtime.Object {
+		all := append([]runtime.Object{}, namespaces...)
+		all = append(all, injectedPods...)
+		return append(all, extra...)
+	}
+
+	testCases := []struct {
+		name          string
+		objects       []runtime.Object
+		namespace     string
+		expectedOut   []string
+		unexpectedOut []string
+		expectedErr   string
+	}{
+		{
+			name:    "pods injected or opted out",
+			objects: objects(newWebhookConfig(chartSelector, nil), injectorEndpoints),
+			expectedOut: []string{
+				"[pass] Sidecar injection: The 2 pods that should have a sidecar in 2 namespaces are injected\n",
+				"All checks passed\n",
+			},
+		},
+		{
+			name: "pod lacking a sidecar without a likely cause",
+			objects: objects(newWebhookConfig(chartSelector, nil), injectorEndpoints,
+				uninjectedPod,
+			),
+			expectedOut: []string{
+				"[fail] Pod bookstore/bookstore-2: Pod should have a sidecar but has no osm-proxy-uuid label and no envoy container\n",
+				"       hint: No likely cause found, check the logs of the sidecar injector pods, then restart the pod to inject the sidecar\n",
+			},
+			unexpectedOut: []string{"bookstore-opt-out", "bookstore-job", "bookbuyer-2", "legacy-1", "other-1"},
+			expectedErr:   "1 of 3 pods that should have a sidecar are not injected",
+		},
+		{
+			name:    "pod lacking the envoy container only",
+			objects: objects(newWebhookConfig(chartSelector, nil), injectorEndpoints, sidecarlessPod),
+			expectedOut: []string{
+				"[fail] Pod bookstore/bookstore-2: Pod should have a sidecar but has no envoy container\n",
+			},
+			expectedErr: "1 of 3 pods that should have a sidecar are not injected",
+		},
+		{
+			name: "pod annotated to enable sidecar injection",
+			objects: objects(newWebhookConfig(chartSelector, nil), injectorEndpoints,
+				annotatedPod,
+			),
+			expectedOut: []string{
+				"[fail] Pod bookbuyer/bookbuyer-3: Pod should have a sidecar",
+			},
+			expectedErr: "1 of 3 pods that should have a sidecar are not injected",
+		},
+		{
+			name: "pod created before the enrollment of its namespace",
+			objects: objects(newWebhookConfig(chartSelector, nil), injectorEndpoints,
+				preEnrollmentPod,
+			),
+			expectedOut: []string{
+				"       hint: Likely causes: the pod was created at 2021-01-01T23:00:00Z, before namespace bookstore was enrolled in the mesh at 2021-01-02T00:00:00Z; restart the pod to inject the sidecar once fixed\n",
+			},
+			expectedErr: "1 of 3 pods that should have a sidecar are not injected",
+		},
+		{
+			name: "webhook not registered",
+			objects: objects(
+				uninjectedPod,
+			),
+			expectedOut: []string{
+				"       hint: Likely causes: MutatingWebhookConfiguration osm-webhook-osm not found; restart the pod to inject the sidecar once fixed\n",
+			},
+			expectedErr: "1 of 3 pods that should have a sidecar are not injected",
+		},
+		{
+			name: "webhook unreachable with the Ignore failure policy",
+			objects: objects(newWebhookConfig(chartSelector, &ignore),
+				uninjectedPod,
+			),
+			expectedOut: []string{
+				fmt.Sprintf("the sidecar injector service %s/osm-injector has no ready endpoints and the failure policy Ignore of the webhook admits pods without a sidecar", osmNamespace),
+			},
+			expectedErr: "1 of 3 pods that should have a sidecar are not injected",
+		},
+		{
+			name: "namespace not selected by the webhook",
+			objects: objects(newWebhookConfig(&metav1.LabelSelector{MatchLabels: map[string]string{"team": "books"}}, nil), injectorEndpoints,
+				uninjectedPod,
+			),
+			expectedOut: []string{
+				`the namespace selector "team=books" of the sidecar injector webhook does not select namespace bookstore`,
+			},
+			expectedErr: "1 of 3 pods that should have a sidecar are not injected",
+		},
+		{
+			name: "resource quota exhausted",
+			objects: objects(newWebhookConfig(chartSelector, nil), injectorEndpoints, exhaustedQuota,
+				uninjectedPod,
+			),
+			expectedOut: []string{
+				"ResourceQuota compute is exhausted for requests.cpu, the pod may not be recreated with the sidecar",
+			},
+			expectedErr: "1 of 3 pods that should have a sidecar are not injected",
+		},
+		{
+			name: "single namespace",
+			objects: objects(newWebhookConfig(chartSelector, nil), injectorEndpoints,
+				uninjectedPod,
+			),
+			namespace: "bookbuyer",
+			expectedOut: []string{
+				"[pass] Sidecar injection: The 1 pods that should have a sidecar in 1 namespaces are injected\n",
+			},
+			unexpectedOut: []string{"bookstore-2"},
+		},
+		{
+			name: "annotation prefix of the sidecar injector",
+			objects: objects(newWebhookConfig(chartSelector, nil), injectorEndpoints, prefixedInjector,
+				prefixedPod, unprefixedPod,
+			),
+			expectedOut: []string{
+				"[fail] Pod bookbuyer/bookbuyer-5: Pod should have a sidecar but has no mesh.example.com/osm-proxy-uuid label\n",
+			},
+			unexpectedOut: []string{"bookbuyer-4", "bookstore-1", "bookbuyer-1"},
+			expectedErr:   "1 of 2 pods that should have a sidecar are not injected",
+		},
+		{
+			name:        "namespace not monitored",
+			objects:     objects(newWebhookConfig(chartSelector, nil), injectorEndpoints),
+			namespace:   "other-mesh",
+			expectedErr: "Namespace other-mesh is not monitored by the mesh osm",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			out := new(bytes.Buffer)
+			cmd := &checkInjectionCmd{
+				out:       out,
+				clientSet: fake.NewSimpleClientset(tc.objects...),
+				meshName:  defaultMeshName,
+				namespace: tc.namespace,
+			}
+
+			err := cmd.run()
+			if tc.expectedErr != "" {
+				assert.EqualError(err, tc.expectedErr)
+			} else {
+				assert.Nil(err)
+			}
+			for _, expected := range tc.expectedOut {
+				assert.Contains(out.String(), expected)
+			}
+			for _, unexpected := range tc.unexpectedOut {
+				assert.NotContains(out.String(), unexpected)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+	"github.com/openservicemesh/osm/pkg/injector"
+)
+
+const checkPortConflictsDescription = `
+This command detects the meshed pods whose ports collide with the ports
+reserved for the sidecar proxy, which breaks the pod: an application container
+listening on a port the proxy listens on either fails to start or steals the
+traffic of the proxy.
+
+It scans the meshed pods of the monitored namespaces, or of the namespace given
+with --namespace, and reports each pod that:
+
+  - has a container, other than the sidecar, declaring one of the ports
+    reserved for the sidecar proxy (15000, 15001, 15003 and 15010)
+  - lists one of these ports in its outbound port exclusion annotation, which
+    the sidecar injector rejects for the pods injected since it validates it
+
+The port exclusion lists of the mesh config only hold IP ranges, they can't
+collide with the reserved ports. Pods that have terminated are skipped.
+
+The command exits with a non-zero exit code when any pod has a port conflict.
+`
+
+const checkPortConflictsExample = `
+# Detect the meshed pods whose ports collide with the ports reserved for the sidecar proxy
+osm check port-conflicts
+
+# Detect the meshed pods of the 'bookstore' namespace whose ports collide with the ports reserved for the sidecar proxy
+osm check port-conflicts -n bookstore
+`
+
+type checkPortConflictsCmd struct {
+	out       io.Writer
+	clientSet kubernetes.Interface
+	namespace string
+}
+
+func newCheckPortConflictsCmd(out io.Writer) *cobra.Command {
+	portConflictsCmd := &checkPortConflictsCmd{
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "port-conflicts",
+		Short: "detect the meshed pods whose ports collide with the ports reserved for the sidecar",
+		Long:  checkPortConflictsDescription,
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			config, err := settings.RESTClientGetter().ToRESTConfig()
+			if err != nil {
+				return errors.Errorf("Error fetching kubeconfig: %s", err)
+			}
+
+			clientset, err := kubernetes.NewForConfig(config)
+			if err != nil {
+				return errors.Errorf("Could not access Kubernetes cluster, check kubeconfig: %s", err)
+			}
+			portConflictsCmd.clientSet = clientset
+			return portConflictsCmd.run()
+		},
+		Example: checkPortConflictsExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&portConflictsCmd.namespace, "namespace", "n", "", "Namespace of the pods, all the monitored namespaces if unset")
+
+	return cmd
+}
+
+func (cmd *checkPortConflictsCmd) run() error {
+	pods, err := listMeshedPods(cmd.clientSet, cmd.namespace)
+	if err != nil {
+		return err
+	}
+
+	var probes []probe
+	checked := 0
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		checked++
+
+		conflicts := getReservedPortConflicts(pod)
+		if len(conflicts) == 0 {
+			continue
+		}
+		result := probeResult{
+			status:  probeStatusFail,
+			message: strings.Join(conflicts, "; "),
+			hint:    fmt.Sprintf("Move the application off the ports reserved for the sidecar proxy (%s) and remove them from the %s annotation", describeReservedProxyPorts(), constants.OutboundPortExclusionListAnnotation),
+		}
+		probes = append(probes, probe{name: "Pod " + pod.Namespace + namespaceSeparator + pod.Name, run: func() probeResult { return result }})
+	}
+
+	if len(probes) == 0 {
+		result := probeResult{
+			status:  probeStatusPass,
+			message: fmt.Sprintf("None of the %d meshed pods use the ports reserved for the sidecar proxy", checked),
+		}
+		probes = append(probes, probe{name: "Port conflicts", run: func() probeResult { return result }})
+	}
+
+	failed, _ := runProbes(cmd.out, probes)
+	if failed > 0 {
+		return errors.Errorf("%d of %d meshed pods have ports colliding with the ports reserved for the sidecar proxy", failed, checked)
+	}
+	fmt.Fprintln(cmd.out, "All checks passed")
+	return nil
+}
+
+// getReservedPortConflicts returns the descriptions of the ports of the given pod colliding with the ports reserved
+// for the sidecar proxy, among the ports declared by its containers other than the sidecar, and the ports listed in
+// its outbound port exclusion annotation
+func getReservedPortConflicts(pod *corev1.Pod) []string {
+	var conflicts []string
+	for _, container := range pod.Spec.Containers {
+		if container.Name == constants.EnvoyContainerName {
+			continue
+		}
+		for _, port := range container.Ports {
+			if use, ok := injector.ReservedProxyPorts[int(port.ContainerPort)]; ok {
+				conflicts = append(conflicts, fmt.Sprintf("Container %s declares port %d, reserved for the %s of the sidecar proxy", container.Name, port.ContainerPort, use))
+			}
+		}
+	}
+
+	if value, ok := pod.Annotations[constants.OutboundPortExclusionListAnnotation]; ok {
+		for _, portStr := range strings.Split(value, ",") {
+			port, err := strconv.Atoi(strings.TrimSpace(portStr))
+			if err != nil {
+				continue
+			}
+			if use, ok := injector.ReservedProxyPorts[port]; ok {
+				conflicts = append(conflicts, fmt.Sprintf("Annotation %s excludes port %d from interception, reserved for the %s of the sidecar proxy", constants.OutboundPortExclusionListAnnotation, port, use))
+			}
+		}
+	}
+	return conflicts
+}
+
+// describeReservedProxyPorts returns the sorted, comma separated ports reserved for the sidecar proxy
+func describeReservedProxyPorts() string {
+	var ports []int
+	for port := range injector.ReservedProxyPorts {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+
+	var portStrs []string
+	for _, port := range ports {
+		portStrs = append(portStrs, strconv.Itoa(port))
+	}
+	return strings.Join(portStrs, ", ")
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+func TestCheckPortConflicts(t *testing.T) {
+	var pods []*corev1.Pod
+	for _, pod := range []struct {
+		namespace, name string
+		meshed          bool
+		ports           []int32
+	}{
+		{namespace: "bookstore", name: "bookstore", meshed: true, ports: []int32{14001}},
+		{namespace: "bookbuyer", name: "bookbuyer", meshed: true, ports: []int32{8080, 15001}},
+		{namespace: "bookbuyer", name: "bookbuyer-excluded", meshed: true},
+		{namespace: "bookbuyer", name: "completed", meshed: true, ports: []int32{15000}},
+		{namespace: "bookbuyer", name: "unmeshed", ports: []int32{15003}},
+	} {
+		var containerPorts []corev1.ContainerPort
+		for _, port := range pod.ports {
+			containerPorts = append(containerPorts, corev1.ContainerPort{ContainerPort: port})
+		}
+		p := newTestPod(pod.namespace, pod.name, "", pod.meshed)
+		p.Spec.Containers = []corev1.Container{
+			{Name: "app", Ports: containerPorts},
+			{Name: constants.EnvoyContainerName, Ports: []corev1.ContainerPort{{ContainerPort: constants.EnvoyAdminPort}}},
+		}
+		pods = append(pods, p)
+	}
+	pods[2].Annotations = map[string]string{constants.OutboundPortExclusionListAnnotation: "6379, 15010"}
+	pods[3].Status.Phase = corev1.PodSucceeded
+	objects := []runtime.Object{newNamespace("bookstore", nil), newNamespace("bookbuyer", nil)}
+	for _, pod := range pods {
+		objects = append(objects, pod)
+	}
+
+	testCases := []struct {
+		name               string
+		namespace          string
+		expectedOutSubstrs []string
+		expectedErr        string
+	}{
+		{
+			name:      "pods colliding with the reserved ports are reported",
+			namespace: "",
+			expectedOutSubstrs: []string{
+				"[fail] Pod bookbuyer/bookbuyer: Container app declares port 15001, reserved for the outbound listener of the sidecar proxy\n",
+				"       hint: Move the application off the ports reserved for the sidecar proxy (15000, 15001, 15003, 15010)",
+				"[fail] Pod bookbuyer/bookbuyer-excluded: Annotation openservicemesh.io/outbound-port-exclusion-list excludes port 15010 from interception, reserved for the metrics listener of the sidecar proxy\n",
+			},
+			expectedErr: "2 of 3 meshed pods have ports colliding with the ports reserved for the sidecar proxy",
+		},
+		{
+			name:      "no pod colliding with the reserved ports",
+			namespace: "bookstore",
+			expectedOutSubstrs: []string{
+				"[pass] Port conflicts: None of the 1 meshed pods use the ports reserved for the sidecar proxy\n",
+				"All checks passed\n",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			out := new(bytes.Buffer)
+			cmd := &checkPortConflictsCmd{
+				out:       out,
+				clientSet: fake.NewSimpleClientset(objects...),
+				namespace: tc.namespace,
+			}
+
+			err := cmd.run()
+			if tc.expectedErr != "" {
+				assert.NotNil(err)
+				assert.Equal(tc.expectedErr, err.Error())
+			} else {
+				assert.Nil(err)
+			}
+			for _, substr := range tc.expectedOutSubstrs {
+				assert.Contains(out.String(), substr)
+			}
+			assert.NotContains(out.String(), "completed")
+			assert.NotContains(out.String(), "unmeshed")
+		})
+	}
+}
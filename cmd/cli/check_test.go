@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/version"
+	fakediscovery "k8s.io/client-go/discovery/fake"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+)
+
+func TestCheck(t *testing.T) {
+	osmNamespace := settings.Namespace()
+	smiResources := []*metav1.APIResourceList{
+		{GroupVersion: "access.smi-spec.io/v1alpha3"},
+		{GroupVersion: "specs.smi-spec.io/v1alpha4"},
+		{GroupVersion: "split.smi-spec.io/v1alpha2"},
+	}
+	meshConfig := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: osmConfigMapName, Namespace: osmNamespace},
+		Data:       map[string]string{configurator.PermissiveTrafficPolicyModeKey: "false"},
+	}
+	newWebhookConfig := func(caBundle []byte) *admissionregv1.MutatingWebhookConfiguration {
+		return &admissionregv1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "osm-webhook-osm"},
+			Webhooks: []admissionregv1.MutatingWebhook{
+				{
+					Name: sidecarInjectorWebhookName,
+					ClientConfig: admissionregv1.WebhookClientConfig{
+						Service:  &admissionregv1.ServiceReference{Name: "osm-injector", Namespace: osmNamespace},
+						CABundle: caBundle,
+					},
+				},
+			},
+		}
+	}
+	newEndpoints := func(addresses ...corev1.EndpointAddress) *corev1.Endpoints {
+		return &corev1.Endpoints{
+			ObjectMeta: metav1.ObjectMeta{Name: "osm-injector", Namespace: osmNamespace},
+			Subsets:    []corev1.EndpointSubset{{Addresses: addresses}},
+		}
+	}
+	readyAddress := corev1.EndpointAddress{IP: "10.0.0.1"}
+
+	testCases := []struct {
+		name          string
+		serverVersion string
+		resources     []*metav1.APIResourceList
+		objects       []runtime.Object
+		preInstall    bool
+		expectedOut   string
+		expectedErr   string
+	}{
+		{
+			name:          "all checks pass",
+			serverVersion: "v1.19.3",
+			resources:     smiResources,
+			objects:       []runtime.Object{meshConfig, newWebhookConfig([]byte("ca")), newEndpoints(readyAddress)},
+			expectedOut: "[pass] Kubernetes version: Kubernetes 1.19.3 is supported\n" +
+				"[pass] SMI CRDs: The SMI APIs used by OSM are served\n" +
+				fmt.Sprintf("[pass] Mesh config: Mesh config %s/osm-config exists\n", osmNamespace) +
+				fmt.Sprintf("[pass] Sidecar injector webhook: Webhook osm-inject.k8s.io is registered and service %s/osm-injector is ready\n", osmNamespace) +
+				"All checks passed\n",
+		},
+		{
+			name:          "pre-install checks with missing SMI CRDs",
+			serverVersion: "v1.18.0",
+			preInstall:    true,
+			expectedOut: "[pass] Kubernetes version: Kubernetes 1.18.0 is supported\n" +
+				"[warn] SMI CRDs: The SMI APIs access.smi-spec.io/v1alpha3, specs.smi-spec.io/v1alpha4, split.smi-spec.io/v1alpha2 are not served\n" +
+				"       hint: Install the SMI CRDs of the OSM chart, they are installed by osm install\n" +
+				"All checks passed\n",
+		},
+		{
+			name:          "unsupported Kubernetes version and missing SMI CRD",
+			serverVersion: "v1.17.9",
+			resources:     smiResources[:2],
+			objects:       []runtime.Object{meshConfig, newWebhookConfig([]byte("ca")), newEndpoints(readyAddress)},
+			expectedOut: "[fail] Kubernetes version: Kubernetes 1.17.9 is not supported, the minimum supported version is 1.18.0\n" +
+				"       hint: Upgrade the cluster to Kubernetes 1.18.0 or later\n" +
+				"[fail] SMI CRDs: The SMI APIs split.smi-spec.io/v1alpha2 are not served\n" +
+				"       hint: Install the SMI CRDs of the OSM chart, they are installed by osm install\n" +
+				fmt.Sprintf("[pass] Mesh config: Mesh config %s/osm-config exists\n", osmNamespace) +
+				fmt.Sprintf("[pass] Sidecar injector webhook: Webhook osm-inject.k8s.io is registered and service %s/osm-injector is ready\n", osmNamespace),
+			expectedErr: "2 of 4 checks failed",
+		},
+		{
+			name:          "mesh not installed",
+			serverVersion: "v1.19.3",
+			resources:     smiResources,
+			expectedOut: "[pass] Kubernetes version: Kubernetes 1.19.3 is supported\n" +
+				"[pass] SMI CRDs: The SMI APIs used by OSM are served\n" +
+				fmt.Sprintf("[fail] Mesh config: Mesh config osm-config not found in namespace %s, no mesh configs exist in this namespace\n", osmNamespace) +
+				fmt.Sprintf("       hint: Check that OSM is installed in namespace %s, or use --osm-namespace with the namespace of the control plane\n", osmNamespace) +
+				"[fail] Sidecar injector webhook: MutatingWebhookConfiguration osm-webhook-osm not found\n" +
+				"       hint: Check that the mesh osm is installed, or use --mesh-name with the name of the mesh\n",
+			expectedErr: "2 of 4 checks failed",
+		},
+		{
+			name:          "sidecar injector not ready",
+			serverVersion: "v1.19.3",
+			resources:     smiResources,
+			objects:       []runtime.Object{meshConfig, newWebhookConfig([]byte("ca")), newEndpoints()},
+			expectedOut: "[pass] Kubernetes version: Kubernetes 1.19.3 is supported\n" +
+				"[pass] SMI CRDs: The SMI APIs used by OSM are served\n" +
+				fmt.Sprintf("[pass] Mesh config: Mesh config %s/osm-config exists\n", osmNamespace) +
+				fmt.Sprintf("[fail] Sidecar injector webhook: Service %s/osm-injector of the sidecar injector webhook has no ready endpoints\n", osmNamespace) +
+				fmt.Sprintf("       hint: Check the status and logs of the osm-injector pods in namespace %s\n", osmNamespace),
+			expectedErr: "1 of 4 checks failed",
+		},
+		{
+			name:          "sidecar injector webhook without CA bundle",
+			serverVersion: "v1.19.3",
+			resources:     smiResources,
+			objects:       []runtime.Object{meshConfig, newWebhookConfig(nil), newEndpoints(readyAddress)},
+			expectedOut: "[pass] Kubernetes version: Kubernetes 1.19.3 is supported\n" +
+				"[pass] SMI CRDs: The SMI APIs used by OSM are served\n" +
+				fmt.Sprintf("[pass] Mesh config: Mesh config %s/osm-config exists\n", osmNamespace) +
+				"[warn] Sidecar injector webhook: Webhook osm-inject.k8s.io has no CA bundle, the API server cannot verify the sidecar injector yet\n" +
+				fmt.Sprintf("       hint: The CA bundle is patched by the sidecar injector when it starts, check the logs of the osm-injector pods in namespace %s\n", osmNamespace) +
+				"All checks passed\n",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			clientSet := fake.NewSimpleClientset(tc.objects...)
+			discovery := clientSet.Discovery().(*fakediscovery.FakeDiscovery)
+			discovery.FakedServerVersion = &version.Info{GitVersion: tc.serverVersion}
+			discovery.Resources = tc.resources
+
+			out := new(bytes.Buffer)
+			cmd := &checkCmd{
+				out:        out,
+				clientSet:  clientSet,
+				meshName:   defaultMeshName,
+				preInstall: tc.preInstall,
+			}
+			err := cmd.run()
+			if tc.expectedErr != "" {
+				assert.NotNil(err)
+				assert.Equal(tc.expectedErr, err.Error())
+			} else {
+				assert.Nil(err)
+			}
+			assert.Equal(tc.expectedOut, out.String())
+		})
+	}
+}
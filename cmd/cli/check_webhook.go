@@ -0,0 +1,365 @@
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+const checkWebhookDescription = `
+This command checks that the sidecar injector webhook of the mesh is configured
+to inject the sidecar into the pods of the monitored namespaces. Each field of
+the webhook of the MutatingWebhookConfiguration of the mesh is checked by a
+named probe, whose message names the offending field when it fails:
+
+  namespaceSelector: selects every namespace monitored by the mesh, except the
+      namespaces explicitly ignored, and neither the namespace of the control
+      plane nor the namespaces that are not monitored by the mesh
+  clientConfig.caBundle: holds a CA certificate that is currently valid
+  clientConfig.service: references a service that exists, exposes the port of
+      the webhook and has ready endpoints
+  failurePolicy: is the policy given with --failure-policy
+
+A webhook whose namespace selector does not select a namespace is a common
+cause of pods not being injected with a sidecar. The command exits with a
+non-zero exit code when any of the probes fails.
+`
+
+const checkWebhookExample = `
+# Check the sidecar injector webhook of the mesh named 'osm' whose control plane runs in the 'osm-system' namespace
+osm check webhook
+
+# Check the sidecar injector webhook of the mesh named 'prod', expecting pods to be admitted when the webhook is down
+osm check webhook --mesh-name prod --osm-namespace osm-prod --failure-policy Ignore
+`
+
+// caBundleExpiryWarningPeriod is the period before the expiry of the CA bundle of the webhook from which the probe
+// warns that it is about to expire
+const caBundleExpiryWarningPeriod = 7 * 24 * time.Hour
+
+type checkWebhookCmd struct {
+	out           io.Writer
+	clientSet     kubernetes.Interface
+	meshName      string
+	failurePolicy string
+
+	// now returns the current time, against which the validity of the CA bundle is checked
+	now func() time.Time
+}
+
+func newCheckWebhookCmd(out io.Writer) *cobra.Command {
+	webhookCmd := &checkWebhookCmd{
+		out: out,
+		now: time.Now,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "webhook",
+		Short: "check the configuration of the sidecar injector webhook",
+		Long:  checkWebhookDescription,
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			config, err := settings.RESTClientGetter().ToRESTConfig()
+			if err != nil {
+				return errors.Errorf("Error fetching kubeconfig: %s", err)
+			}
+
+			clientset, err := kubernetes.NewForConfig(config)
+			if err != nil {
+				return errors.Errorf("Could not access Kubernetes cluster, check kubeconfig: %s", err)
+			}
+			webhookCmd.clientSet = clientset
+			return webhookCmd.run()
+		},
+		Example: checkWebhookExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&webhookCmd.meshName, "mesh-name", defaultMeshName, "Name of the mesh whose webhook is checked")
+	f.StringVar(&webhookCmd.failurePolicy, "failure-policy", string(admissionregv1.Fail), "Expected failure policy of the webhook, one of: Fail, Ignore")
+
+	return cmd
+}
+
+func (cmd *checkWebhookCmd) run() error {
+	switch admissionregv1.FailurePolicyType(cmd.failurePolicy) {
+	case admissionregv1.Fail, admissionregv1.Ignore:
+	default:
+		return errors.Errorf("Invalid value %q for flag --failure-policy, expected one of: %s, %s", cmd.failurePolicy, admissionregv1.Fail, admissionregv1.Ignore)
+	}
+
+	webhook, result := getSidecarInjectorWebhook(cmd.clientSet, cmd.meshName)
+	probes := []probe{
+		{name: "Sidecar injector webhook", run: func() probeResult { return result }},
+	}
+	// The fields of the webhook can only be checked once it is found
+	if webhook != nil {
+		probes = append(probes,
+			probe{name: "Namespace selector", run: func() probeResult { return cmd.checkNamespaceSelector(webhook) }},
+			probe{name: "CA bundle", run: func() probeResult { return cmd.checkCABundle(webhook) }},
+			probe{name: "Service", run: func() probeResult { return cmd.checkService(webhook) }},
+			probe{name: "Failure policy", run: func() probeResult { return cmd.checkFailurePolicy(webhook) }},
+		)
+	}
+
+	failed, _ := runProbes(cmd.out, probes)
+	if failed > 0 {
+		return errors.Errorf("%d of %d checks failed", failed, len(probes))
+	}
+	fmt.Fprintln(cmd.out, "All checks passed")
+	return nil
+}
+
+// checkNamespaceSelector checks that the namespace selector of the webhook selects the namespaces monitored by the
+// mesh that are not explicitly ignored, and no other namespace
+func (cmd *checkWebhookCmd) checkNamespaceSelector(webhook *admissionregv1.MutatingWebhook) probeResult {
+	selector := labels.Everything()
+	if webhook.NamespaceSelector != nil {
+		var err error
+		if selector, err = metav1.LabelSelectorAsSelector(webhook.NamespaceSelector); err != nil {
+			return probeResult{
+				status:  probeStatusFail,
+				message: fmt.Sprintf("namespaceSelector is invalid: %s", err),
+				hint:    "Reinstall OSM to restore the MutatingWebhookConfiguration",
+			}
+		}
+	}
+
+	namespaces, err := cmd.clientSet.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return probeResult{
+			status:  probeStatusFail,
+			message: fmt.Sprintf("Error listing namespaces: %s", err),
+		}
+	}
+
+	osmNamespace := settings.Namespace()
+	var unselected, unmonitored []string
+	selectsControlPlane := false
+	monitoredCount := 0
+	for _, ns := range namespaces.Items {
+		selected := selector.Matches(labels.Set(ns.Labels))
+		if ns.Name == osmNamespace {
+			selectsControlPlane = selected
+			continue
+		}
+		monitored := ns.Labels[constants.OSMKubeResourceMonitorAnnotation] == cmd.meshName
+		_, ignored := ns.Labels[ignoreLabel]
+		switch {
+		case monitored && !ignored:
+			monitoredCount++
+			if !selected {
+				unselected = append(unselected, ns.Name)
+			}
+		case selected:
+			unmonitored = append(unmonitored, ns.Name)
+		}
+	}
+	sort.Strings(unselected)
+	sort.Strings(unmonitored)
+
+	if selectsControlPlane {
+		return probeResult{
+			status:  probeStatusFail,
+			message: fmt.Sprintf("namespaceSelector %q selects the namespace %s of the control plane", selector, osmNamespace),
+			hint:    fmt.Sprintf("Exclude namespace %s from the namespace selector, e.g. with the expression 'name NotIn (%s)' set by the OSM chart", osmNamespace, osmNamespace),
+		}
+	}
+	if len(unselected) > 0 {
+		return probeResult{
+			status: probeStatusFail,
+			message: fmt.Sprintf("namespaceSelector %q does not select the monitored namespaces %s, their pods are not injected with a sidecar",
+				selector, strings.Join(unselected, ", ")),
+			hint: fmt.Sprintf("Check the labels of the namespaces against the namespace selector, or reinstall OSM to restore the selector of the mesh %s", cmd.meshName),
+		}
+	}
+	if len(unmonitored) > 0 {
+		return probeResult{
+			status: probeStatusFail,
+			message: fmt.Sprintf("namespaceSelector %q selects the namespaces %s that are ignored or not monitored by the mesh %s",
+				selector, strings.Join(unmonitored, ", "), cmd.meshName),
+			hint: fmt.Sprintf("Restrict the namespace selector to the namespaces labeled %s=%s", constants.OSMKubeResourceMonitorAnnotation, cmd.meshName),
+		}
+	}
+	return probeResult{
+		status:  probeStatusPass,
+		message: fmt.Sprintf("namespaceSelector selects the %d namespaces monitored by the mesh %s", monitoredCount, cmd.meshName),
+	}
+}
+
+// checkCABundle checks that the CA bundle of the webhook holds certificates that are currently valid
+func (cmd *checkWebhookCmd) checkCABundle(webhook *admissionregv1.MutatingWebhook) probeResult {
+	hint := "The CA bundle is patched by the sidecar injector when it starts, check the logs of the sidecar injector pods"
+	if len(webhook.ClientConfig.CABundle) == 0 {
+		return probeResult{
+			status:  probeStatusFail,
+			message: "clientConfig.caBundle is empty, the API server cannot verify the sidecar injector",
+			hint:    hint,
+		}
+	}
+
+	var certs []*x509.Certificate
+	rest := webhook.ClientConfig.CABundle
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return probeResult{
+				status:  probeStatusFail,
+				message: fmt.Sprintf("clientConfig.caBundle holds an invalid certificate: %s", err),
+				hint:    hint,
+			}
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return probeResult{
+			status:  probeStatusFail,
+			message: "clientConfig.caBundle holds no PEM encoded certificate",
+			hint:    hint,
+		}
+	}
+
+	now := cmd.now()
+	for _, cert := range certs {
+		if now.After(cert.NotAfter) {
+			return probeResult{
+				status:  probeStatusFail,
+				message: fmt.Sprintf("clientConfig.caBundle holds the certificate %q that expired on %s", cert.Subject.CommonName, cert.NotAfter.UTC().Format(time.RFC3339)),
+				hint:    "Restart the sidecar injector to issue a new certificate and patch the CA bundle",
+			}
+		}
+		if now.Before(cert.NotBefore) {
+			return probeResult{
+				status:  probeStatusFail,
+				message: fmt.Sprintf("clientConfig.caBundle holds the certificate %q that is not valid before %s", cert.Subject.CommonName, cert.NotBefore.UTC().Format(time.RFC3339)),
+				hint:    "Check that the clocks of the nodes of the cluster are synchronized",
+			}
+		}
+	}
+	for _, cert := range certs {
+		if cert.NotAfter.Sub(now) < caBundleExpiryWarningPeriod {
+			return probeResult{
+				status:  probeStatusWarn,
+				message: fmt.Sprintf("clientConfig.caBundle holds the certificate %q that expires on %s", cert.Subject.CommonName, cert.NotAfter.UTC().Format(time.RFC3339)),
+				hint:    "Restart the sidecar injector before the certificate expires to issue a new certificate",
+			}
+		}
+	}
+	return probeResult{
+		status:  probeStatusPass,
+		message: fmt.Sprintf("clientConfig.caBundle holds %d valid certificates", len(certs)),
+	}
+}
+
+// checkService checks that the service referenced by the webhook exists, exposes the port of the webhook and has
+// ready endpoints
+func (cmd *checkWebhookCmd) checkService(webhook *admissionregv1.MutatingWebhook) probeResult {
+	serviceRef := webhook.ClientConfig.Service
+	if serviceRef == nil {
+		return probeResult{
+			status:  probeStatusFail,
+			message: "clientConfig.service is not set, the webhook does not reference the sidecar injector service",
+			hint:    "Reinstall OSM to restore the MutatingWebhookConfiguration",
+		}
+	}
+
+	service, err := cmd.clientSet.CoreV1().Services(serviceRef.Namespace).Get(context.TODO(), serviceRef.Name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return probeResult{
+			status:  probeStatusFail,
+			message: fmt.Sprintf("clientConfig.service references the service %s/%s that does not exist", serviceRef.Namespace, serviceRef.Name),
+			hint:    fmt.Sprintf("Check that the sidecar injector is installed in namespace %s", serviceRef.Namespace),
+		}
+	}
+	if err != nil {
+		return probeResult{
+			status:  probeStatusFail,
+			message: fmt.Sprintf("Error fetching service %s/%s: %s", serviceRef.Namespace, serviceRef.Name, err),
+		}
+	}
+
+	// The port defaults to 443 when unset
+	port := int32(443)
+	if serviceRef.Port != nil {
+		port = *serviceRef.Port
+	}
+	exposed := false
+	for _, servicePort := range service.Spec.Ports {
+		if servicePort.Port == port {
+			exposed = true
+		}
+	}
+	if !exposed {
+		return probeResult{
+			status:  probeStatusFail,
+			message: fmt.Sprintf("clientConfig.service.port %d is not a port of service %s/%s", port, service.Namespace, service.Name),
+			hint:    "Reinstall OSM to restore the MutatingWebhookConfiguration and the sidecar injector service",
+		}
+	}
+
+	ready, err := hasReadyEndpoints(cmd.clientSet, service.Namespace, service.Name)
+	if err != nil {
+		return probeResult{
+			status:  probeStatusFail,
+			message: fmt.Sprintf("Error fetching the endpoints of service %s/%s: %s", service.Namespace, service.Name, err),
+		}
+	}
+	if !ready {
+		return probeResult{
+			status:  probeStatusFail,
+			message: fmt.Sprintf("clientConfig.service %s/%s has no ready endpoints", service.Namespace, service.Name),
+			hint:    fmt.Sprintf("Check the status and logs of the %s pods in namespace %s", service.Name, service.Namespace),
+		}
+	}
+	return probeResult{
+		status:  probeStatusPass,
+		message: fmt.Sprintf("clientConfig.service %s/%s exposes port %d and has ready endpoints", service.Namespace, service.Name, port),
+	}
+}
+
+// checkFailurePolicy checks that the failure policy of the webhook is the policy given with --failure-policy
+func (cmd *checkWebhookCmd) checkFailurePolicy(webhook *admissionregv1.MutatingWebhook) probeResult {
+	// The failure policy defaults to Fail when unset
+	failurePolicy := admissionregv1.Fail
+	if webhook.FailurePolicy != nil {
+		failurePolicy = *webhook.FailurePolicy
+	}
+	if string(failurePolicy) != cmd.failurePolicy {
+		hint := "With the Ignore policy, pods are created without a sidecar while the sidecar injector is down, bypassing the traffic policies"
+		if failurePolicy == admissionregv1.Fail {
+			hint = "With the Fail policy, pods of the monitored namespaces cannot be created while the sidecar injector is down"
+		}
+		return probeResult{
+			status:  probeStatusFail,
+			message: fmt.Sprintf("failurePolicy is %s, expected %s", failurePolicy, cmd.failurePolicy),
+			hint:    hint,
+		}
+	}
+	return probeResult{
+		status:  probeStatusPass,
+		message: fmt.Sprintf("failurePolicy is %s", failurePolicy),
+	}
+}
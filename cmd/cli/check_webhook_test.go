@@ -0,0 +1,252 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	tassert "github.com/stretchr/testify/assert"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+func TestCheckWebhook(t *testing.T) {
+	osmNamespace := settings.Namespace()
+	now := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	validCA := newTestCertificatePEMWithValidity(t, "osm-ca.openservicemesh.io", now.Add(-time.Hour), now.Add(365*24*time.Hour))
+	expiredCA := newTestCertificatePEM(t, "osm-ca.openservicemesh.io", now.Add(-time.Hour))
+	expiringCA := newTestCertificatePEM(t, "osm-ca.openservicemesh.io", now.Add(time.Hour))
+
+	// The namespace selector set by the OSM chart
+	chartSelector := &metav1.LabelSelector{
+		MatchLabels: map[string]string{constants.OSMKubeResourceMonitorAnnotation: "osm"},
+		MatchExpressions: []metav1.LabelSelectorRequirement{
+			{Key: ignoreLabel, Operator: metav1.LabelSelectorOpDoesNotExist},
+			{Key: "name", Operator: metav1.LabelSelectorOpNotIn, Values: []string{osmNamespace}},
+		},
+	}
+	ignore := admissionregv1.Ignore
+	port := int32(9090)
+	newWebhookConfig := func(selector *metav1.LabelSelector, caBundle []byte, failurePolicy *admissionregv1.FailurePolicyType) *admissionregv1.MutatingWebhookConfiguration {
+		return &admissionregv1.MutatingWebhookConfiguration{
+			ObjectMeta: metav1.ObjectMeta{Name: "osm-webhook-osm"},
+			Webhooks: []admissionregv1.MutatingWebhook{
+				{
+					Name: sidecarInjectorWebhookName,
+					ClientConfig: admissionregv1.WebhookClientConfig{
+						Service:  &admissionregv1.ServiceReference{Name: "osm-injector", Namespace: osmNamespace, Port: &port},
+						CABundle: caBundle,
+					},
+					NamespaceSelector: selector,
+					FailurePolicy:     failurePolicy,
+				},
+			},
+		}
+	}
+	controlPlaneNamespace := newNamespace(osmNamespace, nil)
+	controlPlaneNamespace.Labels = map[string]string{"name": osmNamespace}
+	legacyNamespace := newNamespace("legacy", nil)
+	legacyNamespace.Labels[ignoreLabel] = "true"
+	otherMeshNamespace := newNamespace("other-mesh", nil)
+	otherMeshNamespace.Labels[constants.OSMKubeResourceMonitorAnnotation] = "other"
+	namespaces := []runtime.Object{
+		controlPlaneNamespace,
+		newNamespace("bookstore", nil),
+		newNamespace("bookbuyer", nil),
+		legacyNamespace,
+		otherMeshNamespace,
+	}
+	injectorService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: "osm-injector", Namespace: osmNamespace},
+		Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: port}}},
+	}
+	injectorEndpoints := &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: "osm-injector", Namespace: osmNamespace},
+		Subsets:    []corev1.EndpointSubset{{Addresses: []corev1.EndpointAddress{{IP: "10.0.0.1"}}}},
+	}
+	withNamespaces := func(objects ...runtime.Object) []runtime.Object {
+		return append(objects, namespaces...)
+	}
+
+	testCases := []struct {
+		name          string
+		objects       []runtime.Object
+		failurePolicy string
+		expectedOut   []string
+		expectedErr   string
+	}{
+		{
+			name:          "webhook configured by the chart",
+			objects:       withNamespaces(newWebhookConfig(chartSelector, validCA, nil), injectorService, injectorEndpoints),
+			failurePolicy: "Fail",
+			expectedOut: []string{
+				"[pass] Sidecar injector webhook: Webhook osm-inject.k8s.io of MutatingWebhookConfiguration osm-webhook-osm is registered\n",
+				"[pass] Namespace selector: namespaceSelector selects the 2 namespaces monitored by the mesh osm\n",
+				"[pass] CA bundle: clientConfig.caBundle holds 1 valid certificates\n",
+				fmt.Sprintf("[pass] Service: clientConfig.service %s/osm-injector exposes port 9090 and has ready endpoints\n", osmNamespace),
+				"[pass] Failure policy: failurePolicy is Fail\n",
+				"All checks passed\n",
+			},
+		},
+		{
+			name:          "webhook not found",
+			objects:       namespaces,
+			failurePolicy: "Fail",
+			expectedOut: []string{
+				"[fail] Sidecar injector webhook: MutatingWebhookConfiguration osm-webhook-osm not found\n",
+			},
+			expectedErr: "1 of 1 checks failed",
+		},
+		{
+			name: "namespace selector not selecting a monitored namespace",
+			objects: withNamespaces(newWebhookConfig(&metav1.LabelSelector{
+				MatchLabels: map[string]string{constants.OSMKubeResourceMonitorAnnotation: "osm", "team": "books"},
+			}, validCA, nil), injectorService, injectorEndpoints),
+			failurePolicy: "Fail",
+			expectedOut: []string{
+				`[fail] Namespace selector: namespaceSelector "openservicemesh.io/monitored-by=osm,team=books" does not select the monitored namespaces bookbuyer, bookstore, their pods are not injected with a sidecar`,
+			},
+			expectedErr: "1 of 5 checks failed",
+		},
+		{
+			name:          "namespace selector selecting every namespace",
+			objects:       withNamespaces(newWebhookConfig(nil, validCA, nil), injectorService, injectorEndpoints),
+			failurePolicy: "Fail",
+			expectedOut: []string{
+				fmt.Sprintf(`[fail] Namespace selector: namespaceSelector "" selects the namespace %s of the control plane`, osmNamespace),
+			},
+			expectedErr: "1 of 5 checks failed",
+		},
+		{
+			name: "namespace selector selecting ignored namespaces",
+			objects: withNamespaces(newWebhookConfig(&metav1.LabelSelector{
+				MatchLabels: map[string]string{constants.OSMKubeResourceMonitorAnnotation: "osm"},
+			}, validCA, nil), injectorService, injectorEndpoints),
+			failurePolicy: "Fail",
+			expectedOut: []string{
+				`[fail] Namespace selector: namespaceSelector "openservicemesh.io/monitored-by=osm" selects the namespaces legacy that are ignored or not monitored by the mesh osm`,
+			},
+			expectedErr: "1 of 5 checks failed",
+		},
+		{
+			name:          "missing CA bundle",
+			objects:       withNamespaces(newWebhookConfig(chartSelector, nil, nil), injectorService, injectorEndpoints),
+			failurePolicy: "Fail",
+			expectedOut: []string{
+				"[fail] CA bundle: clientConfig.caBundle is empty, the API server cannot verify the sidecar injector\n",
+			},
+			expectedErr: "1 of 5 checks failed",
+		},
+		{
+			name:          "invalid CA bundle",
+			objects:       withNamespaces(newWebhookConfig(chartSelector, []byte("ca"), nil), injectorService, injectorEndpoints),
+			failurePolicy: "Fail",
+			expectedOut: []string{
+				"[fail] CA bundle: clientConfig.caBundle holds no PEM encoded certificate\n",
+			},
+			expectedErr: "1 of 5 checks failed",
+		},
+		{
+			name:          "expired CA bundle",
+			objects:       withNamespaces(newWebhookConfig(chartSelector, expiredCA, nil), injectorService, injectorEndpoints),
+			failurePolicy: "Fail",
+			expectedOut: []string{
+				`[fail] CA bundle: clientConfig.caBundle holds the certificate "osm-ca.openservicemesh.io" that expired on 2020-12-31T23:00:00Z`,
+			},
+			expectedErr: "1 of 5 checks failed",
+		},
+		{
+			name:          "CA bundle about to expire",
+			objects:       withNamespaces(newWebhookConfig(chartSelector, expiringCA, nil), injectorService, injectorEndpoints),
+			failurePolicy: "Fail",
+			expectedOut: []string{
+				`[warn] CA bundle: clientConfig.caBundle holds the certificate "osm-ca.openservicemesh.io" that expires on 2021-01-01T01:00:00Z`,
+				"All checks passed\n",
+			},
+		},
+		{
+			name:          "service without endpoints",
+			objects:       withNamespaces(newWebhookConfig(chartSelector, validCA, nil), injectorService),
+			failurePolicy: "Fail",
+			expectedOut: []string{
+				fmt.Sprintf("[fail] Service: Error fetching the endpoints of service %s/osm-injector", osmNamespace),
+			},
+			expectedErr: "1 of 5 checks failed",
+		},
+		{
+			name: "service not exposing the port of the webhook",
+			objects: withNamespaces(newWebhookConfig(chartSelector, validCA, nil), injectorEndpoints, &corev1.Service{
+				ObjectMeta: metav1.ObjectMeta{Name: "osm-injector", Namespace: osmNamespace},
+				Spec:       corev1.ServiceSpec{Ports: []corev1.ServicePort{{Port: 443}}},
+			}),
+			failurePolicy: "Fail",
+			expectedOut: []string{
+				fmt.Sprintf("[fail] Service: clientConfig.service.port 9090 is not a port of service %s/osm-injector\n", osmNamespace),
+			},
+			expectedErr: "1 of 5 checks failed",
+		},
+		{
+			name:          "service not found",
+			objects:       withNamespaces(newWebhookConfig(chartSelector, validCA, nil)),
+			failurePolicy: "Fail",
+			expectedOut: []string{
+				fmt.Sprintf("[fail] Service: clientConfig.service references the service %s/osm-injector that does not exist\n", osmNamespace),
+			},
+			expectedErr: "1 of 5 checks failed",
+		},
+		{
+			name:          "unexpected failure policy",
+			objects:       withNamespaces(newWebhookConfig(chartSelector, validCA, &ignore), injectorService, injectorEndpoints),
+			failurePolicy: "Fail",
+			expectedOut: []string{
+				"[fail] Failure policy: failurePolicy is Ignore, expected Fail\n",
+			},
+			expectedErr: "1 of 5 checks failed",
+		},
+		{
+			name:          "expected Ignore failure policy",
+			objects:       withNamespaces(newWebhookConfig(chartSelector, validCA, &ignore), injectorService, injectorEndpoints),
+			failurePolicy: "Ignore",
+			expectedOut: []string{
+				"[pass] Failure policy: failurePolicy is Ignore\n",
+			},
+		},
+		{
+			name:          "invalid failure policy",
+			objects:       nil,
+			failurePolicy: "Retry",
+			expectedErr:   `Invalid value "Retry" for flag --failure-policy, expected one of: Fail, Ignore`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			out := new(bytes.Buffer)
+			cmd := &checkWebhookCmd{
+				out:           out,
+				clientSet:     fake.NewSimpleClientset(tc.objects...),
+				meshName:      defaultMeshName,
+				failurePolicy: tc.failurePolicy,
+				now:           func() time.Time { return now },
+			}
+
+			err := cmd.run()
+			if tc.expectedErr != "" {
+				assert.EqualError(err, tc.expectedErr)
+			} else {
+				assert.Nil(err)
+			}
+			for _, expected := range tc.expectedOut {
+				assert.Contains(out.String(), expected)
+			}
+		})
+	}
+}
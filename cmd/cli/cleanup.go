@@ -0,0 +1,24 @@
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+const cleanupDescription = `
+This command consists of subcommands cleaning up the resources left behind
+by the mesh in the cluster.
+`
+
+func newCleanupCmd(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cleanup",
+		Short: "clean up resources left behind by the mesh",
+		Long:  cleanupDescription,
+		Args:  cobra.NoArgs,
+	}
+	cmd.AddCommand(newCleanupBootstrapSecretsCmd(out))
+
+	return cmd
+}
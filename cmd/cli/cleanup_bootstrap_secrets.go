@@ -0,0 +1,219 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+const cleanupBootstrapSecretsDescription = `
+This command lists the bootstrap config secrets of Envoy proxy sidecars that
+are orphaned, and deletes them when --yes is set.
+
+The OSM sidecar injector creates an 'envoy-bootstrap-config-<proxy UUID>'
+secret for every pod joining the mesh, where the proxy UUID is the value of
+the osm-proxy-uuid label of the pod. A bootstrap config secret is orphaned
+when no pod in its namespace has the proxy UUID of the secret, which happens
+when the secret is not garbage collected once its pod is deleted.
+
+The injector creates the bootstrap config secret of a pod before the pod is
+created. Secrets created within the grace period given with --grace-period
+are therefore never considered orphaned, and are reported as skipped.
+
+No secret is deleted unless --yes is set: by default the orphaned secrets are
+only listed. --dry-run lists the orphaned secrets without deleting them even
+when --yes is set.
+`
+
+const cleanupBootstrapSecretsExample = `
+# List the orphaned bootstrap config secrets in all the namespaces
+osm cleanup bootstrap-secrets
+
+# Delete the orphaned bootstrap config secrets in the 'bookstore' namespace
+osm cleanup bootstrap-secrets --namespace bookstore --yes
+
+# Delete the orphaned bootstrap config secrets of the mesh named 'prod' created more than an hour ago
+osm cleanup bootstrap-secrets --mesh-name prod --grace-period 1h --yes
+`
+
+// defaultBootstrapSecretGracePeriod is the default duration after its creation during which a bootstrap config
+// secret is not considered orphaned
+const defaultBootstrapSecretGracePeriod = 10 * time.Minute
+
+type cleanupBootstrapSecretsCmd struct {
+	out         io.Writer
+	clientSet   kubernetes.Interface
+	namespace   string
+	meshName    string
+	gracePeriod time.Duration
+	dryRun      bool
+	yes         bool
+
+	// now returns the current time, against which the grace period of the secrets is evaluated
+	now func() time.Time
+}
+
+func newCleanupBootstrapSecretsCmd(out io.Writer) *cobra.Command {
+	cleanupCmd := &cleanupBootstrapSecretsCmd{
+		out: out,
+		now: time.Now,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "bootstrap-secrets",
+		Short: "delete the orphaned bootstrap config secrets of proxies",
+		Long:  cleanupBootstrapSecretsDescription,
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			config, err := settings.RESTClientGetter().ToRESTConfig()
+			if err != nil {
+				return errors.Errorf("Error fetching kubeconfig: %s", err)
+			}
+
+			clientset, err := kubernetes.NewForConfig(config)
+			if err != nil {
+				return errors.Errorf("Could not access Kubernetes cluster, check kubeconfig: %s", err)
+			}
+			cleanupCmd.clientSet = clientset
+			return cleanupCmd.run()
+		},
+		Example: cleanupBootstrapSecretsExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&cleanupCmd.namespace, "namespace", "n", metav1.NamespaceAll, "Namespace whose bootstrap config secrets are cleaned up, all the namespaces if unset")
+	f.StringVar(&cleanupCmd.meshName, "mesh-name", "", "Name of the mesh whose bootstrap config secrets are cleaned up, the secrets of all the meshes if unset")
+	f.DurationVar(&cleanupCmd.gracePeriod, "grace-period", defaultBootstrapSecretGracePeriod, "Duration after its creation during which a bootstrap config secret is not considered orphaned")
+	f.BoolVar(&cleanupCmd.dryRun, "dry-run", false, "List the orphaned bootstrap config secrets without deleting them, even if --yes is set")
+	f.BoolVarP(&cleanupCmd.yes, "yes", "y", false, "Delete the orphaned bootstrap config secrets, which are only listed otherwise")
+
+	return cmd
+}
+
+func (cmd *cleanupBootstrapSecretsCmd) run() error {
+	orphaned, skipped, err := cmd.findOrphanedBootstrapSecrets()
+	if err != nil {
+		return err
+	}
+
+	if len(skipped) > 0 {
+		fmt.Fprintf(cmd.out, "Skipped %d bootstrap config secret(s) created within the grace period of %s:\n", len(skipped), cmd.gracePeriod)
+		cmd.printSecrets(skipped)
+		fmt.Fprintln(cmd.out)
+	}
+
+	if len(orphaned) == 0 {
+		fmt.Fprintln(cmd.out, "No orphaned bootstrap config secrets found")
+		return nil
+	}
+
+	fmt.Fprintf(cmd.out, "Found %d orphaned bootstrap config secret(s):\n", len(orphaned))
+	cmd.printSecrets(orphaned)
+	fmt.Fprintln(cmd.out)
+
+	if cmd.dryRun || !cmd.yes {
+		fmt.Fprintln(cmd.out, "No secrets were deleted, use the flag --yes to delete the orphaned bootstrap config secrets")
+		return nil
+	}
+
+	var deleted int
+	var failed []string
+	for _, secret := range orphaned {
+		err := cmd.clientSet.CoreV1().Secrets(secret.Namespace).Delete(context.TODO(), secret.Name, metav1.DeleteOptions{})
+		if err != nil && !apierrors.IsNotFound(err) {
+			failed = append(failed, fmt.Sprintf("%s/%s: %s", secret.Namespace, secret.Name, err))
+			continue
+		}
+		deleted++
+	}
+	fmt.Fprintf(cmd.out, "Deleted %d of %d orphaned bootstrap config secret(s)\n", deleted, len(orphaned))
+
+	if len(failed) > 0 {
+		return errors.Errorf("Error deleting %d orphaned bootstrap config secret(s):\n%s", len(failed), strings.Join(failed, "\n"))
+	}
+	return nil
+}
+
+// findOrphanedBootstrapSecrets returns the bootstrap config secrets whose proxy UUID is not the proxy UUID of any pod
+// in their namespace, along with the secrets skipped for being created within the grace period, sorted by
+// namespace and name
+func (cmd *cleanupBootstrapSecretsCmd) findOrphanedBootstrapSecrets() ([]corev1.Secret, []corev1.Secret, error) {
+	secretSelector := labels.Set{constants.OSMAppNameLabelKey: constants.OSMAppNameLabelValue}
+	if cmd.meshName != "" {
+		secretSelector[constants.OSMAppInstanceLabelKey] = cmd.meshName
+	}
+	secrets, err := cmd.clientSet.CoreV1().Secrets(cmd.namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: secretSelector.String(),
+	})
+	if err != nil {
+		return nil, nil, errors.Errorf("Error listing bootstrap config secrets: %s", err)
+	}
+
+	pods, err := cmd.clientSet.CoreV1().Pods(cmd.namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: constants.EnvoyUniqueIDLabelName,
+	})
+	if err != nil {
+		return nil, nil, errors.Errorf("Error listing meshed pods: %s", err)
+	}
+
+	// Bootstrap config secrets are mounted by the pods of their namespace
+	liveSecrets := make(map[string]bool, len(pods.Items))
+	for _, pod := range pods.Items {
+		secretName := constants.EnvoyBootstrapConfigSecretPrefix + pod.Labels[constants.EnvoyUniqueIDLabelName]
+		liveSecrets[pod.Namespace+namespaceSeparator+secretName] = true
+	}
+
+	var orphaned, skipped []corev1.Secret
+	gracePeriodStart := cmd.now().Add(-cmd.gracePeriod)
+	for _, secret := range secrets.Items {
+		if !strings.HasPrefix(secret.Name, constants.EnvoyBootstrapConfigSecretPrefix) {
+			continue
+		}
+		if liveSecrets[secret.Namespace+namespaceSeparator+secret.Name] {
+			continue
+		}
+		if secret.CreationTimestamp.Time.After(gracePeriodStart) {
+			skipped = append(skipped, secret)
+			continue
+		}
+		orphaned = append(orphaned, secret)
+	}
+
+	sortSecrets(orphaned)
+	sortSecrets(skipped)
+	return orphaned, skipped, nil
+}
+
+// printSecrets prints the namespace, name and age of the given secrets
+func (cmd *cleanupBootstrapSecretsCmd) printSecrets(secrets []corev1.Secret) {
+	w := newTabWriter(cmd.out)
+	fmt.Fprintln(w, "NAMESPACE\tNAME\tAGE\t")
+	for _, secret := range secrets {
+		fmt.Fprintf(w, "%s\t%s\t%s\t\n", secret.Namespace, secret.Name, duration.HumanDuration(cmd.now().Sub(secret.CreationTimestamp.Time)))
+	}
+	_ = w.Flush()
+}
+
+// sortSecrets sorts the given secrets by namespace and name
+func sortSecrets(secrets []corev1.Secret) {
+	sort.Slice(secrets, func(i, j int) bool {
+		if secrets[i].Namespace != secrets[j].Namespace {
+			return secrets[i].Namespace < secrets[j].Namespace
+		}
+		return secrets[i].Name < secrets[j].Name
+	})
+}
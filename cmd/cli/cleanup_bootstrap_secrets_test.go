@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+func TestCleanupBootstrapSecrets(t *testing.T) {
+	now := time.Date(2021, time.January, 1, 12, 0, 0, 0, time.UTC)
+
+	newSecret := func(namespace, proxyUUID, meshName string, age time.Duration) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              constants.EnvoyBootstrapConfigSecretPrefix + proxyUUID,
+				Namespace:         namespace,
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+				Labels: map[string]string{
+					constants.OSMAppNameLabelKey:     constants.OSMAppNameLabelValue,
+					constants.OSMAppInstanceLabelKey: meshName,
+				},
+			},
+		}
+	}
+	livePod := newTestPod("bookstore", "bookstore-v1", "", true)
+	livePod.Labels[constants.EnvoyUniqueIDLabelName] = "uuid-1"
+	objects := []runtime.Object{
+		// Secret of a live pod
+		newSecret("bookstore", "uuid-1", "osm", time.Hour),
+		livePod,
+		// Orphaned secrets
+		newSecret("bookstore", "uuid-2", "osm", time.Hour),
+		newSecret("bookbuyer", "uuid-3", "prod", 2*time.Hour),
+		// Secret whose proxy UUID is the one of a pod in another namespace
+		newSecret("bookthief", "uuid-1", "osm", time.Hour),
+		// Secret created within the grace period
+		newSecret("bookstore", "uuid-4", "osm", time.Minute),
+		// Secrets that are not bootstrap config secrets
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:      "osm-ca-bundle",
+			Namespace: "osm-system",
+			Labels:    map[string]string{constants.OSMAppNameLabelKey: constants.OSMAppNameLabelValue},
+		}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name:      constants.EnvoyBootstrapConfigSecretPrefix + "unlabeled",
+			Namespace: "bookstore",
+		}},
+	}
+
+	testCases := []struct {
+		name              string
+		namespace         string
+		meshName          string
+		dryRun            bool
+		yes               bool
+		expectedOut       []string
+		expectedRemaining []string
+	}{
+		{
+			name: "orphaned secrets are only listed by default",
+			expectedOut: []string{
+				"Skipped 1 bootstrap config secret(s) created within the grace period of 10m0s:",
+				"bookstore   envoy-bootstrap-config-uuid-4   60s",
+				"Found 3 orphaned bootstrap config secret(s):",
+				"bookbuyer   envoy-bootstrap-config-uuid-3   120m",
+				"bookstore   envoy-bootstrap-config-uuid-2   60m",
+				"bookthief   envoy-bootstrap-config-uuid-1   60m",
+				"No secrets were deleted, use the flag --yes to delete the orphaned bootstrap config secrets",
+			},
+			expectedRemaining: []string{
+				"bookbuyer/envoy-bootstrap-config-uuid-3",
+				"bookstore/envoy-bootstrap-config-uuid-1",
+				"bookstore/envoy-bootstrap-config-uuid-2",
+				"bookstore/envoy-bootstrap-config-uuid-4",
+				"bookstore/envoy-bootstrap-config-unlabeled",
+				"bookthief/envoy-bootstrap-config-uuid-1",
+				"osm-system/osm-ca-bundle",
+			},
+		},
+		{
+			name:   "dry run takes precedence over --yes",
+			dryRun: true,
+			yes:    true,
+			expectedOut: []string{
+				"Found 3 orphaned bootstrap config secret(s):",
+				"No secrets were deleted",
+			},
+			expectedRemaining: []string{
+				"bookbuyer/envoy-bootstrap-config-uuid-3",
+				"bookstore/envoy-bootstrap-config-uuid-2",
+				"bookthief/envoy-bootstrap-config-uuid-1",
+			},
+		},
+		{
+			name: "orphaned secrets are deleted with --yes",
+			yes:  true,
+			expectedOut: []string{
+				"Found 3 orphaned bootstrap config secret(s):",
+				"Deleted 3 of 3 orphaned bootstrap config secret(s)",
+			},
+			expectedRemaining: []string{
+				"bookstore/envoy-bootstrap-config-uuid-1",
+				"bookstore/envoy-bootstrap-config-uuid-4",
+				"bookstore/envoy-bootstrap-config-unlabeled",
+				"osm-system/osm-ca-bundle",
+			},
+		},
+		{
+			name:      "single namespace",
+			namespace: "bookstore",
+			yes:       true,
+			expectedOut: []string{
+				"Found 1 orphaned bootstrap config secret(s):",
+				"Deleted 1 of 1 orphaned bootstrap config secret(s)",
+			},
+			expectedRemaining: []string{
+				"bookbuyer/envoy-bootstrap-config-uuid-3",
+				"bookstore/envoy-bootstrap-config-uuid-1",
+				"bookstore/envoy-bootstrap-config-uuid-4",
+				"bookthief/envoy-bootstrap-config-uuid-1",
+			},
+		},
+		{
+			name:     "single mesh",
+			meshName: "prod",
+			yes:      true,
+			expectedOut: []string{
+				"Found 1 orphaned bootstrap config secret(s):",
+				"bookbuyer   envoy-bootstrap-config-uuid-3   120m",
+				"Deleted 1 of 1 orphaned bootstrap config secret(s)",
+			},
+			expectedRemaining: []string{
+				"bookstore/envoy-bootstrap-config-uuid-2",
+				"bookthief/envoy-bootstrap-config-uuid-1",
+			},
+		},
+		{
+			name:      "no orphaned secrets",
+			namespace: "osm-system",
+			yes:       true,
+			expectedOut: []string{
+				"No orphaned bootstrap config secrets found",
+			},
+			expectedRemaining: []string{
+				"osm-system/osm-ca-bundle",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			clientSet := fake.NewSimpleClientset(objects...)
+			out := new(bytes.Buffer)
+			cmd := &cleanupBootstrapSecretsCmd{
+				out:         out,
+				clientSet:   clientSet,
+				namespace:   tc.namespace,
+				meshName:    tc.meshName,
+				gracePeriod: defaultBootstrapSecretGracePeriod,
+				dryRun:      tc.dryRun,
+				yes:         tc.yes,
+				now:         func() time.Time { return now },
+			}
+
+			assert.Nil(cmd.run())
+			for _, expected := range tc.expectedOut {
+				assert.Contains(out.String(), expected)
+			}
+
+			secrets, err := clientSet.CoreV1().Secrets(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+			assert.Nil(err)
+			remaining := make(map[string]bool)
+			for _, secret := range secrets.Items {
+				remaining[secret.Namespace+"/"+secret.Name] = true
+			}
+			for _, expected := range tc.expectedRemaining {
+				assert.True(remaining[expected], "expected secret %s to remain", expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+const configCmdDescription = `
+This command consists of subcommands related to the MeshConfig resource
+holding the configuration of the mesh.
+`
+
+func newConfigCmd(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "manage the mesh configuration",
+		Long:  configCmdDescription,
+		Args:  cobra.NoArgs,
+	}
+	cmd.AddCommand(newConfigGetCmd(out))
+	cmd.AddCommand(newConfigSetCmd(out))
+
+	return cmd
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"sigs.k8s.io/yaml"
+
+	configv1alpha1 "github.com/openservicemesh/osm/pkg/apis/config/v1alpha1"
+	"github.com/openservicemesh/osm/pkg/cli"
+	meshConfigClient "github.com/openservicemesh/osm/pkg/gen/client/config/clientset/versioned"
+)
+
+const configGetDescription = `
+This command prints the spec of the MeshConfig resource holding the
+configuration of the mesh, or the single field of the spec given by its dotted
+path, e.g. traffic.enablePermissiveTrafficPolicyMode. Fields left unset in the
+MeshConfig are printed with their zero value.
+
+With --profile, the fields of the spec that the given profile file would
+change with 'osm config set --profile' are printed instead, along with their
+current and profile values, without changing the MeshConfig.
+
+The MeshConfig is looked up in the namespace of the control plane of the mesh
+given with --mesh-name, or in the namespace given with --osm-namespace when no
+mesh name is set.
+`
+
+const configGetExample = `
+# Print the spec of the MeshConfig of the mesh running in the osm-system namespace
+osm config get
+
+# Print whether the mesh operates in permissive traffic policy mode
+osm config get traffic.enablePermissiveTrafficPolicyMode
+
+# Print the sidecar configuration of the mesh named 'prod' as JSON
+osm config get sidecar --mesh-name prod -o json
+
+# Print the fields of the configuration of the mesh named 'prod' that differ from the prod profile
+osm config get --profile prod.yaml --mesh-name prod
+`
+
+const (
+	// defaultMeshConfigName is the default name of the MeshConfig resource holding the configuration of a mesh
+	defaultMeshConfigName = "osm-mesh-config"
+
+	// configOutputYAML and configOutputJSON are the supported --output formats of the command
+	configOutputYAML = "yaml"
+	configOutputJSON = "json"
+)
+
+type configGetCmd struct {
+	out              io.Writer
+	field            string
+	profile          string
+	meshName         string
+	meshConfigName   string
+	output           string
+	clientSet        kubernetes.Interface
+	meshConfigClient meshConfigClient.Interface
+}
+
+func newConfigGetCmd(out io.Writer) *cobra.Command {
+	getCmd := &configGetCmd{
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "get [FIELD]",
+		Short: "print the mesh configuration",
+		Long:  configGetDescription,
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			if len(args) > 0 {
+				getCmd.field = args[0]
+			}
+
+			clients, err := cli.NewClients(settings)
+			if err != nil {
+				return err
+			}
+			getCmd.clientSet = clients.KubeClient
+			getCmd.meshConfigClient = clients.ConfigClient
+			return getCmd.run()
+		},
+		Example: configGetExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&getCmd.meshName, "mesh-name", "", "Name of the mesh whose configuration is printed, the mesh running in the namespace given with --osm-namespace if unset")
+	f.StringVar(&getCmd.meshConfigName, "mesh-config-name", defaultMeshConfigName, "Name of the MeshConfig holding the configuration of the mesh")
+	f.StringVar(&getCmd.profile, "profile", "", "Path of a YAML or JSON profile file whose changes to the MeshConfig spec are printed")
+	f.StringVarP(&getCmd.output, "output", "o", configOutputYAML, fmt.Sprintf("Output format, one of: %s, %s", configOutputYAML, configOutputJSON))
+
+	return cmd
+}
+
+func (cmd *configGetCmd) run() error {
+	if cmd.output != configOutputYAML && cmd.output != configOutputJSON {
+		return errors.Errorf("Invalid value %q for flag --output, expected one of: %s, %s", cmd.output, configOutputYAML, configOutputJSON)
+	}
+
+	if cmd.profile != "" && cmd.field != "" {
+		return errors.New("FIELD cannot be set along with --profile")
+	}
+
+	meshConfig, err := getMeshConfigResource(cmd.clientSet, cmd.meshConfigClient, cmd.meshName, cmd.meshConfigName)
+	if err != nil {
+		return err
+	}
+
+	if cmd.profile != "" {
+		return cmd.printProfileChanges(meshConfig)
+	}
+
+	value, err := getConfigField(reflect.ValueOf(meshConfig.Spec), cmd.field)
+	if err != nil {
+		return err
+	}
+
+	var out []byte
+	if cmd.output == configOutputJSON {
+		out, err = json.MarshalIndent(value, "", "  ")
+		out = append(out, '\n')
+	} else {
+		out, err = yaml.Marshal(value)
+	}
+	if err != nil {
+		return errors.Errorf("Error marshaling MeshConfig field %q: %s", cmd.field, err)
+	}
+	fmt.Fprint(cmd.out, string(out))
+	return nil
+}
+
+// printProfileChanges prints the changes that the profile given with --profile would make to the given MeshConfig
+func (cmd *configGetCmd) printProfileChanges(meshConfig *configv1alpha1.MeshConfig) error {
+	profile, err := readConfigProfile(cmd.profile)
+	if err != nil {
+		return err
+	}
+	spec, err := applyConfigPatch(meshConfig.Spec, profile)
+	if err != nil {
+		return err
+	}
+	changes := getConfigChanges(meshConfig.Spec, spec)
+
+	name := meshConfig.Namespace + namespaceSeparator + meshConfig.Name
+	if len(changes) == 0 {
+		fmt.Fprintf(cmd.out, "MeshConfig %s matches the profile %s\n", name, cmd.profile)
+		return nil
+	}
+	fmt.Fprintf(cmd.out, "The profile %s would change %d field(s) of MeshConfig %s:\n", cmd.profile, len(changes), name)
+	printConfigChanges(cmd.out, changes)
+	return nil
+}
+
+// getMeshConfigResource returns the MeshConfig with the given name in the namespace of the control plane of the given
+// mesh, or in the namespace given with --osm-namespace when no mesh name is set
+func getMeshConfigResource(clientSet kubernetes.Interface, client meshConfigClient.Interface, meshName, meshConfigName string) (*configv1alpha1.MeshConfig, error) {
+	osmNamespace, err := getMeshNamespace(clientSet, meshName)
+	if err != nil {
+		return nil, err
+	}
+
+	meshConfig, err := client.ConfigV1alpha1().MeshConfigs(osmNamespace).Get(context.TODO(), meshConfigName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, errors.Errorf("MeshConfig %s not found in namespace %s, use --mesh-config-name to set the name of the MeshConfig of the mesh", meshConfigName, osmNamespace)
+	}
+	if err != nil {
+		return nil, errors.Errorf("Error fetching MeshConfig %s/%s: %s", osmNamespace, meshConfigName, err)
+	}
+	return meshConfig, nil
+}
+
+// getConfigField returns the field of the given MeshConfig spec value at the given dotted path of JSON field names, or
+// the whole spec when the path is empty. Struct fields are looked up by their JSON name, and map values by their key.
+func getConfigField(spec reflect.Value, path string) (interface{}, error) {
+	if path == "" {
+		return spec.Interface(), nil
+	}
+
+	value := spec
+	var walked []string
+	for _, name := range strings.Split(path, ".") {
+		parent := "spec"
+		if len(walked) > 0 {
+			parent = strings.Join(walked, ".")
+		}
+
+		switch value.Kind() {
+		case reflect.Struct:
+			field, fieldNames := getJSONField(value, name)
+			if !field.IsValid() {
+				return nil, errors.Errorf("Field %q not found in %s, expected one of: %s", name, parent, strings.Join(fieldNames, ", "))
+			}
+			value = field
+		case reflect.Map:
+			mapValue := value.MapIndex(reflect.ValueOf(name))
+			if !mapValue.IsValid() {
+				return nil, errors.Errorf("Key %q not found in %s", name, parent)
+			}
+			value = mapValue
+		default:
+			return nil, errors.Errorf("Field %q not found, %s is not an object", name, parent)
+		}
+		walked = append(walked, name)
+	}
+	return value.Interface(), nil
+}
+
+// getJSONField returns the field of the given struct value with the given JSON name, along with the sorted JSON
+// names of the fields of the struct
+func getJSONField(value reflect.Value, name string) (reflect.Value, []string) {
+	var field reflect.Value
+	var fieldNames []string
+	for i := 0; i < value.NumField(); i++ {
+		jsonName := strings.Split(value.Type().Field(i).Tag.Get("json"), ",")[0]
+		if jsonName == "" || jsonName == "-" {
+			continue
+		}
+		fieldNames = append(fieldNames, jsonName)
+		if jsonName == name {
+			field = value.Field(i)
+		}
+	}
+	sort.Strings(fieldNames)
+	return field, fieldNames
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	configv1alpha1 "github.com/openservicemesh/osm/pkg/apis/config/v1alpha1"
+	fakeMeshConfigClient "github.com/openservicemesh/osm/pkg/gen/client/config/clientset/versioned/fake"
+)
+
+func TestConfigGet(t *testing.T) {
+	meshConfig := &configv1alpha1.MeshConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      defaultMeshConfigName,
+			Namespace: settings.Namespace(),
+		},
+		Spec: configv1alpha1.MeshConfigSpec{
+			Sidecar: configv1alpha1.SidecarSpec{
+				LogLevel:  "debug",
+				PodLabels: map[string]string{"team": "bookstore"},
+			},
+			Traffic: configv1alpha1.TrafficSpec{
+				EnableEgress: true,
+			},
+		},
+	}
+
+	testCases := []struct {
+		name           string
+		field          string
+		meshConfigName string
+		output         string
+		expectedOutput string
+		expectErr      bool
+	}{
+		{
+			name:           "single field",
+			field:          "sidecar.logLevel",
+			output:         configOutputYAML,
+			expectedOutput: "debug\n",
+		},
+		{
+			name:           "unset field is printed with its zero value",
+			field:          "traffic.enablePermissiveTrafficPolicyMode",
+			output:         configOutputJSON,
+			expectedOutput: "false\n",
+		},
+		{
+			name:           "map key",
+			field:          "sidecar.podLabels.team",
+			output:         configOutputJSON,
+			expectedOutput: "\"bookstore\"\n",
+		},
+		{
+			name:           "nested object",
+			field:          "traffic",
+			output:         configOutputYAML,
+			expectedOutput: "enableEgress: true\n",
+		},
+		{
+			name:      "unknown field",
+			field:     "traffic.enableEgres",
+			output:    configOutputYAML,
+			expectErr: true,
+		},
+		{
+			name:      "field of a scalar",
+			field:     "sidecar.logLevel.value",
+			output:    configOutputYAML,
+			expectErr: true,
+		},
+		{
+			name:           "MeshConfig not found",
+			meshConfigName: "osm-mesh-config-prod",
+			output:         configOutputYAML,
+			expectErr:      true,
+		},
+		{
+			name:      "invalid output format",
+			output:    "table",
+			expectErr: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			meshConfigName := tc.meshConfigName
+			if meshConfigName == "" {
+				meshConfigName = defaultMeshConfigName
+			}
+
+			out := new(bytes.Buffer)
+			cmd := &configGetCmd{
+				out:              out,
+				field:            tc.field,
+				meshConfigName:   meshConfigName,
+				output:           tc.output,
+				clientSet:        fake.NewSimpleClientset(),
+				meshConfigClient: fakeMeshConfigClient.NewSimpleClientset(meshConfig),
+			}
+
+			err := cmd.run()
+			assert.Equal(tc.expectErr, err != nil)
+			if !tc.expectErr {
+				assert.Equal(tc.expectedOutput, out.String())
+			}
+		})
+	}
+}
+
+func TestGetConfigFieldUnknownFieldListsFields(t *testing.T) {
+	assert := tassert.New(t)
+
+	_, err := getConfigField(reflect.ValueOf(configv1alpha1.MeshConfigSpec{}), "tls")
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "expected one of: certificate, observability, sidecar, traffic")
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/yaml"
+
+	configv1alpha1 "github.com/openservicemesh/osm/pkg/apis/config/v1alpha1"
+)
+
+// unsetConfigValue describes a map key of the MeshConfig spec that is not set
+const unsetConfigValue = "<unset>"
+
+// configDurationFields are the dotted paths of the fields of the MeshConfig spec holding a duration
+var configDurationFields = []string{
+	"sidecar.configResyncInterval",
+	"sidecar.proxyDrainTimeout",
+	"certificate.serviceCertValidityDuration",
+}
+
+// configChange is a field of the MeshConfig spec changed from its old value to its new value, both JSON encoded or
+// unsetConfigValue for a map key that is not set
+type configChange struct {
+	Field    string
+	OldValue string
+	NewValue string
+}
+
+// readConfigProfile returns the fields of the MeshConfig spec set in the given YAML or JSON profile file
+func readConfigProfile(path string) (map[string]interface{}, error) {
+	content, err := ioutil.ReadFile(filepath.Clean(path))
+	if err != nil {
+		return nil, errors.Errorf("Error reading profile %s: %s", path, err)
+	}
+
+	var profile map[string]interface{}
+	if err := yaml.Unmarshal(content, &profile); err != nil {
+		return nil, errors.Errorf("Error parsing profile %s: %s", path, err)
+	}
+	if len(profile) == 0 {
+		return nil, errors.Errorf("Profile %s sets no field of the MeshConfig spec", path)
+	}
+	return profile, nil
+}
+
+// getConfigFieldPatch returns the patch setting the field of the MeshConfig spec at the given dotted path to the given
+// value. The value is parsed as YAML, unless the field holds a string.
+func getConfigFieldPatch(path, value string) (map[string]interface{}, error) {
+	fieldType, err := getConfigFieldType(reflect.TypeOf(configv1alpha1.MeshConfigSpec{}), path)
+	if err != nil {
+		return nil, err
+	}
+
+	var parsed interface{} = value
+	if fieldType.Kind() != reflect.String {
+		if err := yaml.Unmarshal([]byte(value), &parsed); err != nil {
+			return nil, errors.Errorf("Invalid value %q for field %s: %s", value, path, err)
+		}
+	}
+
+	names := strings.Split(path, ".")
+	patch := map[string]interface{}{names[len(names)-1]: parsed}
+	for i := len(names) - 2; i >= 0; i-- {
+		patch = map[string]interface{}{names[i]: patch}
+	}
+	return patch, nil
+}
+
+// getConfigFieldType returns the type of the field of the given MeshConfig spec type at the given dotted path of JSON
+// field names, where map values are looked up by any key
+func getConfigFieldType(specType reflect.Type, path string) (reflect.Type, error) {
+	fieldType := specType
+	var walked []string
+	for _, name := range strings.Split(path, ".") {
+		parent := "spec"
+		if len(walked) > 0 {
+			parent = strings.Join(walked, ".")
+		}
+
+		switch fieldType.Kind() {
+		case reflect.Struct:
+			field, fieldNames := getJSONFieldType(fieldType, name)
+			if field == nil {
+				return nil, errors.Errorf("Field %q not found in %s, expected one of: %s", name, parent, strings.Join(fieldNames, ", "))
+			}
+			fieldType = field
+		case reflect.Map:
+			fieldType = fieldType.Elem()
+		default:
+			return nil, errors.Errorf("Field %q not found, %s is not an object", name, parent)
+		}
+		walked = append(walked, name)
+	}
+	return fieldType, nil
+}
+
+// getJSONFieldType returns the type of the field of the given struct type with the given JSON name, along with the
+// sorted JSON names of the fields of the struct
+func getJSONFieldType(structType reflect.Type, name string) (reflect.Type, []string) {
+	var fieldType reflect.Type
+	var fieldNames []string
+	for i := 0; i < structType.NumField(); i++ {
+		jsonName := strings.Split(structType.Field(i).Tag.Get("json"), ",")[0]
+		if jsonName == "" || jsonName == "-" {
+			continue
+		}
+		fieldNames = append(fieldNames, jsonName)
+		if jsonName == name {
+			fieldType = structType.Field(i).Type
+		}
+	}
+	sort.Strings(fieldNames)
+	return fieldType, fieldNames
+}
+
+// applyConfigPatch returns a copy of the given MeshConfig spec with the fields set by the given patch. The whole patch
+// is validated before the copy is returned, so that a patch with any invalid field changes nothing. Maps are merged
+// with the maps of the spec, while lists replace the lists of the spec.
+func applyConfigPatch(spec configv1alpha1.MeshConfigSpec, patch map[string]interface{}) (configv1alpha1.MeshConfigSpec, error) {
+	patchJSON, err := json.Marshal(patch)
+	if err != nil {
+		return spec, errors.Errorf("Error marshaling the changes to the MeshConfig spec: %s", err)
+	}
+
+	patched := *spec.DeepCopy()
+	decoder := json.NewDecoder(bytes.NewReader(patchJSON))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&patched); err != nil {
+		return spec, errors.Errorf("Invalid changes to the MeshConfig spec: %s", strings.TrimPrefix(err.Error(), "json: "))
+	}
+
+	for _, path := range configDurationFields {
+		value, err := getConfigField(reflect.ValueOf(patched), path)
+		if err != nil {
+			return spec, err
+		}
+		if duration := value.(string); duration != "" {
+			if _, err := time.ParseDuration(duration); err != nil {
+				return spec, errors.Errorf("Invalid duration %q for field %s: %s", duration, path, err)
+			}
+		}
+	}
+	return patched, nil
+}
+
+// getConfigChanges returns the fields that differ between the given MeshConfig specs, sorted by their dotted path.
+// Lists are compared as a whole.
+func getConfigChanges(oldSpec, newSpec configv1alpha1.MeshConfigSpec) []configChange {
+	oldFields := make(map[string]string)
+	flattenConfig(reflect.ValueOf(oldSpec), "", oldFields)
+	newFields := make(map[string]string)
+	flattenConfig(reflect.ValueOf(newSpec), "", newFields)
+
+	paths := make(map[string]bool)
+	for path := range oldFields {
+		paths[path] = true
+	}
+	for path := range newFields {
+		paths[path] = true
+	}
+
+	var changes []configChange
+	for path := range paths {
+		oldValue, ok := oldFields[path]
+		if !ok {
+			oldValue = unsetConfigValue
+		}
+		newValue, ok := newFields[path]
+		if !ok {
+			newValue = unsetConfigValue
+		}
+		if oldValue != newValue {
+			changes = append(changes, configChange{Field: path, OldValue: oldValue, NewValue: newValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Field < changes[j].Field
+	})
+	return changes
+}
+
+// flattenConfig adds to the given fields the JSON encoded value of every field of the given MeshConfig spec value, by
+// dotted path prefixed with the given prefix. Struct fields are flattened by their JSON name, and map values by their
+// key, so that only the map keys that are set are added.
+func flattenConfig(value reflect.Value, prefix string, fields map[string]string) {
+	switch value.Kind() {
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			jsonName := strings.Split(value.Type().Field(i).Tag.Get("json"), ",")[0]
+			if jsonName == "" || jsonName == "-" {
+				continue
+			}
+			flattenConfig(value.Field(i), prefix+jsonName+".", fields)
+		}
+	case reflect.Map:
+		for _, key := range value.MapKeys() {
+			flattenConfig(value.MapIndex(key), prefix+key.String()+".", fields)
+		}
+	default:
+		valueJSON, _ := json.Marshal(value.Interface())
+		fields[strings.TrimSuffix(prefix, ".")] = string(valueJSON)
+	}
+}
+
+// printConfigChanges prints the given changes, one per line
+func printConfigChanges(out io.Writer, changes []configChange) {
+	for _, change := range changes {
+		fmt.Fprintf(out, "    %s: %s -> %s\n", change.Field, change.OldValue, change.NewValue)
+	}
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/openservicemesh/osm/pkg/cli"
+	meshConfigClient "github.com/openservicemesh/osm/pkg/gen/client/config/clientset/versioned"
+)
+
+const configSetDescription = `
+This command changes the spec of the MeshConfig resource holding the
+configuration of the mesh, and prints the fields it changed.
+
+A single field is set by its dotted path, e.g.
+traffic.enablePermissiveTrafficPolicyMode, followed by its value. The value is
+parsed as YAML, unless the field holds a string.
+
+With --profile, the fields set in the given profile file are changed instead.
+A profile is a YAML or JSON file holding a partial MeshConfig spec, e.g. the
+settings of a prod or staging environment:
+
+  traffic:
+    enablePermissiveTrafficPolicyMode: false
+  sidecar:
+    logLevel: error
+
+The maps of a profile are merged with the maps of the MeshConfig, while its
+lists replace the lists of the MeshConfig. The whole profile is validated
+before any field is changed, and the changes are applied with a single update
+of the MeshConfig: either every field of the profile is changed, or none is.
+The update fails without changing any field if the MeshConfig is modified
+while the command runs. Use 'osm config get --profile' to print the changes a
+profile would make without applying them.
+
+The MeshConfig is looked up in the namespace of the control plane of the mesh
+given with --mesh-name, or in the namespace given with --osm-namespace when no
+mesh name is set.
+`
+
+const configSetExample = `
+# Enable permissive traffic policy mode in the mesh running in the osm-system namespace
+osm config set traffic.enablePermissiveTrafficPolicyMode true
+
+# Apply the settings of the prod profile to the mesh named 'prod'
+osm config set --profile prod.yaml --mesh-name prod
+`
+
+type configSetCmd struct {
+	out              io.Writer
+	field            string
+	value            string
+	profile          string
+	meshName         string
+	meshConfigName   string
+	clientSet        kubernetes.Interface
+	meshConfigClient meshConfigClient.Interface
+}
+
+func newConfigSetCmd(out io.Writer) *cobra.Command {
+	setCmd := &configSetCmd{
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "set [FIELD VALUE]",
+		Short: "change the mesh configuration",
+		Long:  configSetDescription,
+		Args: func(_ *cobra.Command, args []string) error {
+			if setCmd.profile != "" && len(args) > 0 {
+				return errors.New("FIELD and VALUE cannot be set along with --profile")
+			}
+			if setCmd.profile == "" && len(args) != 2 {
+				return errors.Errorf("Expected FIELD and VALUE, or --profile, got %d argument(s)", len(args))
+			}
+			return nil
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			if len(args) == 2 {
+				setCmd.field = args[0]
+				setCmd.value = args[1]
+			}
+
+			clients, err := cli.NewClients(settings)
+			if err != nil {
+				return err
+			}
+			setCmd.clientSet = clients.KubeClient
+			setCmd.meshConfigClient = clients.ConfigClient
+			return setCmd.run()
+		},
+		Example: configSetExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&setCmd.profile, "profile", "", "Path of a YAML or JSON profile file holding the fields of the MeshConfig spec to set")
+	f.StringVar(&setCmd.meshName, "mesh-name", "", "Name of the mesh whose configuration is changed, the mesh running in the namespace given with --osm-namespace if unset")
+	f.StringVar(&setCmd.meshConfigName, "mesh-config-name", defaultMeshConfigName, "Name of the MeshConfig holding the configuration of the mesh")
+
+	return cmd
+}
+
+func (cmd *configSetCmd) run() error {
+	var patch map[string]interface{}
+	var err error
+	if cmd.profile != "" {
+		patch, err = readConfigProfile(cmd.profile)
+	} else {
+		patch, err = getConfigFieldPatch(cmd.field, cmd.value)
+	}
+	if err != nil {
+		return err
+	}
+
+	meshConfig, err := getMeshConfigResource(cmd.clientSet, cmd.meshConfigClient, cmd.meshName, cmd.meshConfigName)
+	if err != nil {
+		return err
+	}
+	name := meshConfig.Namespace + namespaceSeparator + meshConfig.Name
+
+	spec, err := applyConfigPatch(meshConfig.Spec, patch)
+	if err != nil {
+		return errors.Errorf("%s, no field of MeshConfig %s was changed", err, name)
+	}
+	changes := getConfigChanges(meshConfig.Spec, spec)
+	if len(changes) == 0 {
+		fmt.Fprintf(cmd.out, "MeshConfig %s is up to date, no field was changed\n", name)
+		return nil
+	}
+
+	// The update is rejected if the MeshConfig was modified since it was fetched, as its resource version is kept
+	meshConfig.Spec = spec
+	_, err = cmd.meshConfigClient.ConfigV1alpha1().MeshConfigs(meshConfig.Namespace).Update(context.TODO(), meshConfig, metav1.UpdateOptions{})
+	if apierrors.IsConflict(err) {
+		return errors.Errorf("MeshConfig %s was modified while the command ran, no field was changed, retry the command", name)
+	}
+	if err != nil {
+		return errors.Errorf("Error updating MeshConfig %s, no field was changed: %s", name, err)
+	}
+
+	fmt.Fprintf(cmd.out, "[+] Changed %d field(s) of MeshConfig %s:\n", len(changes), name)
+	printConfigChanges(cmd.out, changes)
+	return nil
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	configv1alpha1 "github.com/openservicemesh/osm/pkg/apis/config/v1alpha1"
+	fakeMeshConfigClient "github.com/openservicemesh/osm/pkg/gen/client/config/clientset/versioned/fake"
+)
+
+func TestConfigSet(t *testing.T) {
+	dir, err := ioutil.TempDir(os.TempDir(), "osm-test")
+	tassert.Nil(t, err)
+	defer os.RemoveAll(dir) //nolint: errcheck
+
+	writeProfile := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		tassert.Nil(t, ioutil.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+	prodProfile := writeProfile("prod.yaml", `
+traffic:
+  enablePermissiveTrafficPolicyMode: false
+  outboundIPRangeExclusionList: [10.0.0.0/8]
+sidecar:
+  logLevel: error
+  podLabels:
+    env: prod
+certificate:
+  serviceCertValidityDuration: 1h
+`)
+	unknownFieldProfile := writeProfile("unknown-field.yaml", `
+sidecar:
+  logLevel: error
+  logLevels: error
+`)
+	invalidTypeProfile := writeProfile("invalid-type.yaml", `
+sidecar:
+  logLevel: error
+traffic:
+  enableEgress: "yes"
+`)
+	invalidDurationProfile := writeProfile("invalid-duration.yaml", `
+sidecar:
+  logLevel: error
+certificate:
+  serviceCertValidityDuration: 1 day
+`)
+	emptyProfile := writeProfile("empty.yaml", "")
+
+	newMeshConfig := func() *configv1alpha1.MeshConfig {
+		return &configv1alpha1.MeshConfig{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      defaultMeshConfigName,
+				Namespace: settings.Namespace(),
+			},
+			Spec: configv1alpha1.MeshConfigSpec{
+				Sidecar: configv1alpha1.SidecarSpec{
+					LogLevel:  "debug",
+					PodLabels: map[string]string{"team": "bookstore"},
+				},
+				Traffic: configv1alpha1.TrafficSpec{
+					EnablePermissiveTrafficPolicyMode: true,
+					OutboundIPRangeExclusionList:      []string{"1.1.1.1/32"},
+				},
+			},
+		}
+	}
+	meshConfigName := settings.Namespace() + namespaceSeparator + defaultMeshConfigName
+
+	testCases := []struct {
+		name         string
+		field        string
+		value        string
+		profile      string
+		conflict     bool
+		expectedOut  string
+		expectedErr  string
+		expectedSpec func(spec *configv1alpha1.MeshConfigSpec)
+	}{
+		{
+			name:  "boolean field",
+			field: "traffic.enablePermissiveTrafficPolicyMode",
+			value: "false",
+			expectedOut: fmt.Sprintf("[+] Changed 1 field(s) of MeshConfig %s:\n", meshConfigName) +
+				"    traffic.enablePermissiveTrafficPolicyMode: true -> false\n",
+			expectedSpec: func(spec *configv1alpha1.MeshConfigSpec) {
+				spec.Traffic.EnablePermissiveTrafficPolicyMode = false
+			},
+		},
+		{
+			name:  "string field holding a number",
+			field: "sidecar.podLabels.version",
+			value: "2",
+			expectedOut: fmt.Sprintf("[+] Changed 1 field(s) of MeshConfig %s:\n", meshConfigName) +
+				"    sidecar.podLabels.version: <unset> -> \"2\"\n",
+			expectedSpec: func(spec *configv1alpha1.MeshConfigSpec) {
+				spec.Sidecar.PodLabels["version"] = "2"
+			},
+		},
+		{
+			name:        "unknown field",
+			field:       "traffic.enablePermissiveMode",
+			value:       "false",
+			expectedErr: `Field "enablePermissiveMode" not found in traffic, expected one of: enableEgress, enablePermissiveTrafficPolicyMode, outboundIPRangeExclusionList, useHTTPSIngress`,
+		},
+		{
+			name:        "field already set",
+			field:       "sidecar.logLevel",
+			value:       "debug",
+			expectedOut: fmt.Sprintf("MeshConfig %s is up to date, no field was changed\n", meshConfigName),
+		},
+		{
+			name:    "profile",
+			profile: prodProfile,
+			expectedOut: fmt.Sprintf("[+] Changed 5 field(s) of MeshConfig %s:\n", meshConfigName) +
+				"    certificate.serviceCertValidityDuration: \"\" -> \"1h\"\n" +
+				"    sidecar.logLevel: \"debug\" -> \"error\"\n" +
+				"    sidecar.podLabels.env: <unset> -> \"prod\"\n" +
+				"    traffic.enablePermissiveTrafficPolicyMode: true -> false\n" +
+				"    traffic.outboundIPRangeExclusionList: [\"1.1.1.1/32\"] -> [\"10.0.0.0/8\"]\n",
+			expectedSpec: func(spec *configv1alpha1.MeshConfigSpec) {
+				spec.Certificate.ServiceCertValidityDuration = "1h"
+				spec.Sidecar.LogLevel = "error"
+				spec.Sidecar.PodLabels["env"] = "prod"
+				spec.Traffic.EnablePermissiveTrafficPolicyMode = false
+				spec.Traffic.OutboundIPRangeExclusionList = []string{"10.0.0.0/8"}
+			},
+		},
+		{
+			name:        "profile with an unknown field",
+			profile:     unknownFieldProfile,
+			expectedErr: fmt.Sprintf(`Invalid changes to the MeshConfig spec: unknown field "logLevels", no field of MeshConfig %s was changed`, meshConfigName),
+		},
+		{
+			name:        "profile with a field of the wrong type",
+			profile:     invalidTypeProfile,
+			expectedErr: "Invalid changes to the MeshConfig spec: cannot unmarshal string into Go struct field",
+		},
+		{
+			name:        "profile with an invalid duration",
+			profile:     invalidDurationProfile,
+			expectedErr: `Invalid duration "1 day" for field certificate.serviceCertValidityDuration`,
+		},
+		{
+			name:        "empty profile",
+			profile:     emptyProfile,
+			expectedErr: fmt.Sprintf("Profile %s sets no field of the MeshConfig spec", emptyProfile),
+		},
+		{
+			name:        "missing profile",
+			profile:     filepath.Join(dir, "missing.yaml"),
+			expectedErr: fmt.Sprintf("Error reading profile %s", filepath.Join(dir, "missing.yaml")),
+		},
+		{
+			name:        "MeshConfig modified concurrently",
+			profile:     prodProfile,
+			conflict:    true,
+			expectedErr: fmt.Sprintf("MeshConfig %s was modified while the command ran, no field was changed, retry the command", meshConfigName),
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			meshConfigClient := fakeMeshConfigClient.NewSimpleClientset(newMeshConfig())
+			if tc.conflict {
+				meshConfigClient.PrependReactor("update", "meshconfigs", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, apierrors.NewConflict(schema.GroupResource{Resource: "meshconfigs"}, defaultMeshConfigName, fmt.Errorf("modified"))
+				})
+			}
+
+			out := new(bytes.Buffer)
+			cmd := &configSetCmd{
+				out:              out,
+				field:            tc.field,
+				value:            tc.value,
+				profile:          tc.profile,
+				meshConfigName:   defaultMeshConfigName,
+				clientSet:        fake.NewSimpleClientset(),
+				meshConfigClient: meshConfigClient,
+			}
+
+			err := cmd.run()
+			if tc.expectedErr != "" {
+				assert.NotNil(err)
+				assert.Contains(fmt.Sprint(err), tc.expectedErr)
+			} else {
+				assert.Nil(err)
+				assert.Equal(tc.expectedOut, out.String())
+			}
+
+			// The MeshConfig is left unchanged unless every field was changed
+			expectedSpec := newMeshConfig().Spec
+			if tc.expectedSpec != nil {
+				tc.expectedSpec(&expectedSpec)
+			}
+			meshConfig, err := meshConfigClient.ConfigV1alpha1().MeshConfigs(settings.Namespace()).Get(context.TODO(), defaultMeshConfigName, metav1.GetOptions{})
+			assert.Nil(err)
+			assert.Equal(expectedSpec, meshConfig.Spec)
+		})
+	}
+
+	t.Run("print the changes of a profile", func(t *testing.T) {
+		assert := tassert.New(t)
+
+		out := new(bytes.Buffer)
+		cmd := &configGetCmd{
+			out:              out,
+			profile:          prodProfile,
+			meshConfigName:   defaultMeshConfigName,
+			output:           configOutputYAML,
+			clientSet:        fake.NewSimpleClientset(),
+			meshConfigClient: fakeMeshConfigClient.NewSimpleClientset(newMeshConfig()),
+		}
+
+		assert.Nil(cmd.run())
+		assert.Contains(out.String(), fmt.Sprintf("The profile %s would change 5 field(s) of MeshConfig %s:\n", prodProfile, meshConfigName))
+		assert.Contains(out.String(), "    sidecar.logLevel: \"debug\" -> \"error\"\n")
+	})
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+const controllerCmdDescription = `
+This command consists of subcommands related to the operations
+of the OSM controller.
+`
+
+func newControllerCmd(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "controller",
+		Short: "osm-controller operations",
+		Long:  controllerCmdDescription,
+		Args:  cobra.NoArgs,
+	}
+	cmd.AddCommand(newControllerLogsCmd(out))
+
+	return cmd
+}
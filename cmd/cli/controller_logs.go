@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+const controllerLogsDescription = `
+This command prints the logs of the osm-controller pods of the mesh whose
+control plane runs in the namespace given with --osm-namespace.
+
+When the control plane runs multiple osm-controller replicas, each line is
+prefixed with the name of the pod it was logged by.
+`
+
+const controllerLogsExample = `
+# Print the logs of the osm-controller running in the 'osm-system' namespace
+osm controller logs
+
+# Print the logs of the last hour
+osm controller logs --since 1h
+
+# Print the logs of the previous osm-controller container, e.g. after it crashed
+osm controller logs --previous
+
+# Stream the logs of the osm-controller running in the 'osm-prod' namespace
+osm controller logs -f --osm-namespace osm-prod
+`
+
+type controllerLogsCmd struct {
+	out       io.Writer
+	clientSet kubernetes.Interface
+	since     time.Duration
+	previous  bool
+	follow    bool
+}
+
+func newControllerLogsCmd(out io.Writer) *cobra.Command {
+	logsCmd := &controllerLogsCmd{
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "logs",
+		Short: "print the logs of the osm-controller",
+		Long:  controllerLogsDescription,
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			config, err := settings.RESTClientGetter().ToRESTConfig()
+			if err != nil {
+				return errors.Errorf("Error fetching kubeconfig: %s", err)
+			}
+
+			clientset, err := kubernetes.NewForConfig(config)
+			if err != nil {
+				return errors.Errorf("Could not access Kubernetes cluster, check kubeconfig: %s", err)
+			}
+			logsCmd.clientSet = clientset
+			return logsCmd.run()
+		},
+		Example: controllerLogsExample,
+	}
+
+	f := cmd.Flags()
+	f.DurationVar(&logsCmd.since, "since", 0, "Only print the logs newer than a relative duration like 5s, 2m or 3h, all the logs are printed if unset")
+	f.BoolVarP(&logsCmd.previous, "previous", "p", false, "Print the logs of the previous instance of the osm-controller container, e.g. after it crashed")
+	f.BoolVarP(&logsCmd.follow, "follow", "f", false, "Stream the logs until interrupted with Ctrl+C")
+
+	return cmd
+}
+
+func (cmd *controllerLogsCmd) run() error {
+	if cmd.since < 0 {
+		return errors.Errorf("Invalid value %s for flag --since, must not be negative", cmd.since)
+	}
+	if cmd.previous && cmd.follow {
+		return errors.New("flags --previous and --follow are mutually exclusive")
+	}
+
+	osmNamespace := settings.Namespace()
+	pods, err := getControllerPods(cmd.clientSet, osmNamespace)
+	if err != nil {
+		return err
+	}
+	if len(pods) == 0 {
+		return errors.Errorf("No %s pods found in namespace %s", constants.OSMControllerName, osmNamespace)
+	}
+
+	// Lines are written whole by a single pod at a time, so that the logs of the replicas can be streamed concurrently
+	out := &lockedWriter{out: cmd.out}
+	logOptions := newPodLogOptions(constants.OSMControllerName, cmd.since, cmd.previous)
+	logOptions.Follow = cmd.follow
+	printPodLogs := func(pod corev1.Pod) error {
+		var prefix string
+		if len(pods) > 1 {
+			prefix = fmt.Sprintf("[%s] ", pod.Name)
+		}
+		stream, err := cmd.clientSet.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, logOptions).Stream(context.TODO())
+		if err != nil {
+			return errors.Errorf("Error fetching the logs of pod %s in namespace %s: %s", pod.Name, pod.Namespace, err)
+		}
+		defer stream.Close() //nolint: errcheck
+
+		if err := copyLogLines(out, prefix, stream); err != nil {
+			return errors.Errorf("Error reading the logs of pod %s in namespace %s: %s", pod.Name, pod.Namespace, err)
+		}
+		return nil
+	}
+
+	if !cmd.follow {
+		for _, pod := range pods {
+			if err := printPodLogs(pod); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	errs := make([]error, len(pods))
+	var wg sync.WaitGroup
+	for i := range pods {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = printPodLogs(pods[i])
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getControllerPods returns the osm-controller pods in the given namespace, sorted by name
+func getControllerPods(clientSet kubernetes.Interface, osmNamespace string) ([]corev1.Pod, error) {
+	labelSelector := metav1.LabelSelector{MatchLabels: map[string]string{"app": constants.OSMControllerName}}
+	pods, err := clientSet.CoreV1().Pods(osmNamespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: labels.Set(labelSelector.MatchLabels).String(),
+	})
+	if err != nil {
+		return nil, errors.Errorf("Error listing %s pods in namespace %s: %s", constants.OSMControllerName, osmNamespace, err)
+	}
+
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].Name < pods.Items[j].Name
+	})
+	return pods.Items, nil
+}
+
+// newPodLogOptions returns the options to get the logs of the given container newer than the given duration, or all
+// the logs when the duration is 0. With previous, the logs of the previous instance of the container are returned.
+func newPodLogOptions(containerName string, since time.Duration, previous bool) *corev1.PodLogOptions {
+	options := &corev1.PodLogOptions{
+		Container: containerName,
+		Previous:  previous,
+	}
+	if since > 0 {
+		// Round up so that the logs of a sub-second duration are not all returned
+		sinceSeconds := int64((since + time.Second - 1) / time.Second)
+		options.SinceSeconds = &sinceSeconds
+	}
+	return options
+}
+
+// lockedWriter serializes the writes to the underlying writer
+type lockedWriter struct {
+	sync.Mutex
+	out io.Writer
+}
+
+func (w *lockedWriter) Write(p []byte) (int, error) {
+	w.Lock()
+	defer w.Unlock()
+	return w.out.Write(p)
+}
+
+// copyLogLines copies the lines read from the given log stream to the given writer, prefixing each line with the
+// given prefix and writing each line with a single write
+func copyLogLines(out io.Writer, prefix string, stream io.Reader) error {
+	reader := bufio.NewReader(stream)
+	for {
+		line, err := reader.ReadString('\n')
+		if len(line) > 0 {
+			if line[len(line)-1] != '\n' {
+				line += "\n"
+			}
+			if _, writeErr := io.WriteString(out, prefix+line); writeErr != nil {
+				return writeErr
+			}
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+	"github.com/openservicemesh/osm/pkg/tests"
+)
+
+func TestControllerLogs(t *testing.T) {
+	controllerLabels := map[string]string{"app": constants.OSMControllerName}
+	controllerPod1 := tests.NewPodFixture(settings.Namespace(), "osm-controller-1", "", controllerLabels)
+	controllerPod2 := tests.NewPodFixture(settings.Namespace(), "osm-controller-2", "", controllerLabels)
+
+	testCases := []struct {
+		name        string
+		pods        []runtime.Object
+		since       time.Duration
+		previous    bool
+		follow      bool
+		expectedOut string
+		expectedErr string
+	}{
+		{
+			name:        "single replica",
+			pods:        []runtime.Object{&controllerPod1},
+			expectedOut: "fake logs\n",
+		},
+		{
+			name:        "multiple replicas are prefixed with the pod name",
+			pods:        []runtime.Object{&controllerPod2, &controllerPod1},
+			since:       time.Hour,
+			expectedOut: "[osm-controller-1] fake logs\n[osm-controller-2] fake logs\n",
+		},
+		{
+			name:        "logs of multiple replicas are streamed",
+			pods:        []runtime.Object{&controllerPod1, &controllerPod2},
+			follow:      true,
+			expectedOut: "[osm-controller-1] fake logs\n[osm-controller-2] fake logs\n",
+		},
+		{
+			name:        "no controller pods",
+			pods:        []runtime.Object{&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "bookstore", Namespace: settings.Namespace()}}},
+			expectedErr: fmt.Sprintf("No osm-controller pods found in namespace %s", settings.Namespace()),
+		},
+		{
+			name:        "negative duration",
+			since:       -time.Minute,
+			expectedErr: "Invalid value -1m0s for flag --since, must not be negative",
+		},
+		{
+			name:        "previous logs cannot be followed",
+			previous:    true,
+			follow:      true,
+			expectedErr: "flags --previous and --follow are mutually exclusive",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			out := new(bytes.Buffer)
+			cmd := &controllerLogsCmd{
+				out:       out,
+				clientSet: fake.NewSimpleClientset(tc.pods...),
+				since:     tc.since,
+				previous:  tc.previous,
+				follow:    tc.follow,
+			}
+			err := cmd.run()
+			if tc.expectedErr != "" {
+				assert.NotNil(err)
+				assert.Equal(tc.expectedErr, err.Error())
+				return
+			}
+			assert.Nil(err)
+
+			// Streamed logs are interleaved in any order, each line is written whole
+			lines := strings.SplitAfter(out.String(), "\n")
+			expectedLines := strings.SplitAfter(tc.expectedOut, "\n")
+			assert.ElementsMatch(expectedLines, lines)
+		})
+	}
+}
+
+func TestNewPodLogOptions(t *testing.T) {
+	int64Ptr := func(i int64) *int64 { return &i }
+
+	testCases := []struct {
+		name            string
+		since           time.Duration
+		previous        bool
+		expectedOptions *corev1.PodLogOptions
+	}{
+		{
+			name:            "all logs",
+			expectedOptions: &corev1.PodLogOptions{Container: "osm-controller"},
+		},
+		{
+			name:            "logs of the last hour",
+			since:           time.Hour,
+			expectedOptions: &corev1.PodLogOptions{Container: "osm-controller", SinceSeconds: int64Ptr(3600)},
+		},
+		{
+			name:            "sub-second duration is rounded up",
+			since:           1500 * time.Millisecond,
+			expectedOptions: &corev1.PodLogOptions{Container: "osm-controller", SinceSeconds: int64Ptr(2)},
+		},
+		{
+			name:            "logs of the previous container",
+			previous:        true,
+			expectedOptions: &corev1.PodLogOptions{Container: "osm-controller", Previous: true},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+			assert.Equal(tc.expectedOptions, newPodLogOptions("osm-controller", tc.since, tc.previous))
+		})
+	}
+}
+
+func TestCopyLogLines(t *testing.T) {
+	assert := tassert.New(t)
+
+	out := new(bytes.Buffer)
+	assert.Nil(copyLogLines(out, "[pod] ", strings.NewReader("line 1\nline 2\nunterminated")))
+	assert.Equal("[pod] line 1\n[pod] line 2\n[pod] unterminated\n", out.String())
+}
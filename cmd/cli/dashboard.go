@@ -6,8 +6,10 @@ import (
 	"io"
 	"os"
 	"os/signal"
+	"strings"
 
 	"github.com/pkg/browser"
+	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
 	"helm.sh/helm/v3/pkg/action"
 	corev1 "k8s.io/api/core/v1"
@@ -20,21 +22,59 @@ import (
 
 const openGrafanaDashboardDesc = `
 This command will perform a port redirection towards a running
-grafana instance running under the OSM namespace, and cast a
-generic browser-open towards localhost on the redirected port.
+dashboard of the control plane running under the OSM namespace,
+and cast a generic browser-open towards localhost on the redirected
+port.
+
+The dashboard is one of grafana, prometheus or jaeger, deployed by
+'osm install' with --deploy-grafana, --deploy-prometheus and
+--deploy-jaeger respectively, and defaults to grafana. The dashboard
+is looked up through its service: osm-grafana, osm-prometheus or
+jaeger.
+
+By default redirects through the web port of the dashboard, 3000 for
+grafana, 7070 for prometheus and 16686 for jaeger, unless manually
+overridden. This command blocks if port forwarding is successful until
+the process is interrupted with a signal from the OS.
+`
+
+const openGrafanaDashboardExample = `
+# Open the Grafana dashboard of the mesh running in the osm-system namespace
+osm dashboard
 
-By default redirects through port 3000 unless manually overridden.
-This command blocks if port forwarding is successful until the
-process is interrupted with a signal from the OS.
+# Open the Jaeger UI through the local port 8080
+osm dashboard jaeger --local-port 8080
 `
+
 const (
 	grafanaServiceName = "osm-grafana"
 	grafanaWebPort     = 3000
+
+	prometheusServiceName = "osm-prometheus"
+	prometheusWebPort     = 7070
+
+	jaegerServiceName = "jaeger"
+	jaegerWebPort     = 16686
 )
 
+// dashboard is a web UI of the control plane, reached through the pods of its service
+type dashboard struct {
+	name        string
+	serviceName string
+	webPort     uint16
+}
+
+// dashboards are the dashboards of the control plane that can be opened, the first one being the default
+var dashboards = []dashboard{
+	{name: "grafana", serviceName: grafanaServiceName, webPort: grafanaWebPort},
+	{name: "prometheus", serviceName: prometheusServiceName, webPort: prometheusWebPort},
+	{name: "jaeger", serviceName: jaegerServiceName, webPort: jaegerWebPort},
+}
+
 type dashboardCmd struct {
 	out         io.Writer
 	config      *action.Configuration
+	dashboard   dashboard
 	localPort   uint16
 	remotePort  uint16
 	openBrowser bool
@@ -48,63 +88,96 @@ func newDashboardCmd(config *action.Configuration, out io.Writer) *cobra.Command
 		sigintChan: make(chan os.Signal, 1),
 	}
 	cmd := &cobra.Command{
-		Use:   "dashboard",
-		Short: "open grafana dashboard through ssh redirection",
+		Use:   "dashboard [grafana|prometheus|jaeger]",
+		Short: "open a dashboard of the control plane through ssh redirection",
 		Long:  openGrafanaDashboardDesc,
+		Args:  cobra.MaximumNArgs(1),
 		RunE: func(_ *cobra.Command, args []string) error {
+			name := dashboards[0].name
+			if len(args) == 1 {
+				name = args[0]
+			}
+			var err error
+			if dash.dashboard, err = getDashboard(name); err != nil {
+				return err
+			}
+			if dash.remotePort == 0 {
+				dash.remotePort = dash.dashboard.webPort
+			}
+			if dash.localPort == 0 {
+				dash.localPort = dash.remotePort
+			}
 			return dash.run()
 		},
+		Example: openGrafanaDashboardExample,
 	}
-	cmd.Flags().Uint16VarP(&dash.localPort, "local-port", "p", grafanaWebPort, "Local port to use")
-	cmd.Flags().Uint16VarP(&dash.remotePort, "remote-port", "r", grafanaWebPort, "Remote port on Grafana")
+	cmd.Flags().Uint16VarP(&dash.localPort, "local-port", "p", 0, "Local port to use, the remote port if unset")
+	cmd.Flags().Uint16VarP(&dash.remotePort, "remote-port", "r", 0, "Remote port on the dashboard, the web port of the dashboard if unset")
 	cmd.Flags().BoolVarP(&dash.openBrowser, "open-browser", "b", true, "Triggers browser open, true by default")
 
 	return cmd
 }
 
-func (d *dashboardCmd) run() error {
-	var err error
-	fmt.Fprintf(d.out, "[+] Starting Dashboard forwarding\n")
-
-	conf, err := d.config.RESTClientGetter.ToRESTConfig()
-	if err != nil {
-		return annotateErrorMessageWithOsmNamespace("Failed to get REST config from Helm %s\n", err)
+// getDashboard returns the dashboard with the given name
+func getDashboard(name string) (dashboard, error) {
+	var names []string
+	for _, d := range dashboards {
+		if d.name == name {
+			return d, nil
+		}
+		names = append(names, d.name)
 	}
+	return dashboard{}, errors.Errorf("Invalid dashboard %q, expected one of: %s", name, strings.Join(names, ", "))
+}
 
-	// Get v1 interface to our cluster. Do or die trying
-	clientSet := kubernetes.NewForConfigOrDie(conf)
+// getDashboardPod returns the first running pod backing the service of the given dashboard in the given namespace
+func getDashboardPod(clientSet kubernetes.Interface, namespace string, d dashboard) (*corev1.Pod, error) {
 	v1ClientSet := clientSet.CoreV1()
 
-	// Get Grafana service data
-	svc, err := v1ClientSet.Services(settings.Namespace()).
-		Get(context.TODO(), grafanaServiceName, metav1.GetOptions{})
+	// Get the service data of the dashboard
+	svc, err := v1ClientSet.Services(namespace).
+		Get(context.TODO(), d.serviceName, metav1.GetOptions{})
 
 	if err != nil {
-		return annotateErrorMessageWithOsmNamespace("Failed to get OSM Grafana service data: %s", err)
+		return nil, annotateErrorMessageWithOsmNamespace("Failed to get OSM %s service data, make sure it is deployed with 'osm install --deploy-%s': %s", d.name, d.name, err)
 	}
 
 	// Select pod/s given the service data available
 	set := labels.Set(svc.Spec.Selector)
 	listOptions := metav1.ListOptions{LabelSelector: set.AsSelector().String()}
-	pods, err := v1ClientSet.Pods(settings.Namespace()).List(context.TODO(), listOptions)
+	pods, err := v1ClientSet.Pods(namespace).List(context.TODO(), listOptions)
 	if err != nil {
-		return annotateErrorMessageWithOsmNamespace("Error listing pods: %s", err)
+		return nil, annotateErrorMessageWithOsmNamespace("Error listing pods: %s", err)
 	}
 
 	// Will select first running Pod available
-	var grafanaPod *corev1.Pod
 	for _, pod := range pods.Items {
 		pod := pod // prevents aliasing address of loop variable which is the same in each iteration
-		if pod.Status.Phase == "Running" {
-			grafanaPod = &pod
-			break
+		if pod.Status.Phase == corev1.PodRunning {
+			return &pod, nil
 		}
 	}
-	if grafanaPod == nil {
-		return annotateErrorMessageWithOsmNamespace("No running Grafana pod available")
+	return nil, annotateErrorMessageWithOsmNamespace("No running %s pod available", d.name)
+}
+
+func (d *dashboardCmd) run() error {
+	var err error
+	fmt.Fprintf(d.out, "[+] Starting %s dashboard forwarding\n", d.dashboard.name)
+
+	conf, err := d.config.RESTClientGetter.ToRESTConfig()
+	if err != nil {
+		return annotateErrorMessageWithOsmNamespace("Failed to get REST config from Helm %s\n", err)
+	}
+
+	// Get v1 interface to our cluster. Do or die trying
+	clientSet := kubernetes.NewForConfigOrDie(conf)
+
+	dashboardPod, err := getDashboardPod(clientSet, settings.Namespace(), d.dashboard)
+	if err != nil {
+		return err
 	}
 
-	dialer, err := k8s.DialerToPod(conf, clientSet, grafanaPod.Name, grafanaPod.Namespace)
+	dialer, err := k8s.DialerToPod(conf, clientSet, dashboardPod.Name, dashboardPod.Namespace)
 	if err != nil {
 		return err
 	}
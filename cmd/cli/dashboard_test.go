@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/tests"
+)
+
+func TestGetDashboard(t *testing.T) {
+	testCases := []struct {
+		name              string
+		expectedService   string
+		expectedWebPort   uint16
+		expectedErrSubstr string
+	}{
+		{name: "grafana", expectedService: "osm-grafana", expectedWebPort: 3000},
+		{name: "prometheus", expectedService: "osm-prometheus", expectedWebPort: 7070},
+		{name: "jaeger", expectedService: "jaeger", expectedWebPort: 16686},
+		{name: "kibana", expectedErrSubstr: `Invalid dashboard "kibana", expected one of: grafana, prometheus, jaeger`},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			d, err := getDashboard(tc.name)
+			if tc.expectedErrSubstr != "" {
+				assert.NotNil(err)
+				assert.Contains(err.Error(), tc.expectedErrSubstr)
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(tc.expectedService, d.serviceName)
+			assert.Equal(tc.expectedWebPort, d.webPort)
+		})
+	}
+}
+
+func TestGetDashboardPod(t *testing.T) {
+	const namespace = "osm-system"
+	jaegerService := &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: jaegerServiceName, Namespace: namespace},
+		Spec:       corev1.ServiceSpec{Selector: map[string]string{"app": "jaeger"}},
+	}
+	jaegerLabels := map[string]string{"app": "jaeger"}
+	pendingPod := tests.NewPodFixture(namespace, "jaeger-pending", "", jaegerLabels)
+	pendingPod.Status.Phase = corev1.PodPending
+	runningPod := tests.NewPodFixture(namespace, "jaeger-running", "", jaegerLabels)
+	runningPod.Status.Phase = corev1.PodRunning
+
+	testCases := []struct {
+		name              string
+		objects           []runtime.Object
+		expectedPod       string
+		expectedErrSubstr string
+	}{
+		{
+			name:        "running pod of the service",
+			objects:     []runtime.Object{jaegerService, &pendingPod, &runningPod},
+			expectedPod: "jaeger-running",
+		},
+		{
+			name:              "service not deployed",
+			expectedErrSubstr: "Failed to get OSM jaeger service data, make sure it is deployed with 'osm install --deploy-jaeger'",
+		},
+		{
+			name:              "no running pod",
+			objects:           []runtime.Object{jaegerService, &pendingPod},
+			expectedErrSubstr: "No running jaeger pod available",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			d, err := getDashboard("jaeger")
+			assert.Nil(err)
+
+			pod, err := getDashboardPod(fake.NewSimpleClientset(tc.objects...), namespace, d)
+			if tc.expectedErrSubstr != "" {
+				assert.NotNil(err)
+				assert.Contains(err.Error(), tc.expectedErrSubstr)
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(tc.expectedPod, pod.Name)
+		})
+	}
+}
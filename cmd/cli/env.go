@@ -53,10 +53,11 @@ This command prints out all the environment information used by OSM
 
 func newEnvCmd(out io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "env",
-		Short: "osm client environment information",
-		Long:  envHelp,
-		Args:  cobra.NoArgs,
+		Use:         "env",
+		Short:       "osm client environment information",
+		Long:        envHelp,
+		Annotations: map[string]string{skipVersionCheckAnnotation: "true"},
+		Args:        cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
 			envVars := settings.EnvVars()
 
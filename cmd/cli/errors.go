@@ -3,3 +3,37 @@ package main
 import "github.com/pkg/errors"
 
 var errAlreadyExists = errors.Errorf("Meshes already exist in cluster. Cannot enforce single mesh cluster.")
+
+// defaultExitCode is the exit code of the CLI when a command fails with an error without a specific exit code
+const defaultExitCode = 1
+
+// exitCodeError is an error returned by a command to exit the CLI with a specific exit code
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e *exitCodeError) Error() string {
+	return e.err.Error()
+}
+
+// withExitCode returns the given error annotated with the given exit code, unless the error already has an exit code
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	var exitErr *exitCodeError
+	if errors.As(err, &exitErr) {
+		return err
+	}
+	return &exitCodeError{code: code, err: err}
+}
+
+// getExitCode returns the exit code of the CLI for the given error returned by a command
+func getExitCode(err error) int {
+	var exitErr *exitCodeError
+	if errors.As(err, &exitErr) {
+		return exitErr.code
+	}
+	return defaultExitCode
+}
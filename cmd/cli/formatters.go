@@ -1,8 +1,12 @@
 package main
 
 import (
+	"fmt"
 	"io"
+	"strings"
 	"text/tabwriter"
+
+	"github.com/pkg/errors"
 )
 
 const (
@@ -16,3 +20,84 @@ const (
 func newTabWriter(out io.Writer) *tabwriter.Writer {
 	return tabwriter.NewWriter(out, minwidth, tabwidth, padding, padchar, flags)
 }
+
+// tableColumn is a column of a table, selected with --columns by its name
+type tableColumn struct {
+	name   string
+	header string
+}
+
+// table holds rows of values to be printed with a selection of its columns
+type table struct {
+	columns []tableColumn
+	rows    [][]string
+}
+
+// newTable returns an empty table with the given columns
+func newTable(columns []tableColumn) *table {
+	return &table{columns: columns}
+}
+
+// addRow adds a row holding a value for each column of the table, in the order of the columns
+func (t *table) addRow(values ...string) {
+	t.rows = append(t.rows, values)
+}
+
+// write prints the selected columns of the table to the given writer, or every column if none are selected
+func (t *table) write(out io.Writer, selectedColumns []string) error {
+	indexes, err := getColumnIndexes(t.columns, selectedColumns)
+	if err != nil {
+		return err
+	}
+
+	w := newTabWriter(out)
+	values := make([]string, len(indexes))
+	for i, index := range indexes {
+		values[i] = t.columns[index].header
+	}
+	fmt.Fprintln(w, strings.Join(values, "\t"))
+	for _, row := range t.rows {
+		for i, index := range indexes {
+			values[i] = row[index]
+		}
+		fmt.Fprintln(w, strings.Join(values, "\t"))
+	}
+	return w.Flush()
+}
+
+// validateColumns returns an error if any of the columns selected with --columns is not one of the given columns
+func validateColumns(columns []tableColumn, selectedColumns []string) error {
+	_, err := getColumnIndexes(columns, selectedColumns)
+	return err
+}
+
+// getColumnIndexes returns the indexes of the selected columns among the given columns, or of every column if none are selected
+func getColumnIndexes(columns []tableColumn, selectedColumns []string) ([]int, error) {
+	if len(selectedColumns) == 0 {
+		indexes := make([]int, len(columns))
+		for i := range columns {
+			indexes[i] = i
+		}
+		return indexes, nil
+	}
+
+	var indexes []int
+	for _, selected := range selectedColumns {
+		index := -1
+		for i, column := range columns {
+			if strings.EqualFold(column.name, strings.TrimSpace(selected)) {
+				index = i
+				break
+			}
+		}
+		if index < 0 {
+			names := make([]string, len(columns))
+			for i, column := range columns {
+				names[i] = column.name
+			}
+			return nil, errors.Errorf("Invalid column %q for flag --columns, expected one of: %s", selected, strings.Join(names, ", "))
+		}
+		indexes = append(indexes, index)
+	}
+	return indexes, nil
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+const injectorCmdDescription = `
+This command consists of subcommands related to the operations
+of the OSM sidecar injector.
+`
+
+func newInjectorCmd(in io.Reader, out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "injector",
+		Short: "osm-injector operations",
+		Long:  injectorCmdDescription,
+		Args:  cobra.NoArgs,
+	}
+	cmd.AddCommand(newInjectorRenderCmd(in, out))
+
+	return cmd
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/chartutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openservicemesh/osm/pkg/cli"
+	"github.com/openservicemesh/osm/pkg/injector"
+)
+
+const injectorRenderDescription = `
+This command renders the sidecar injection of the OSM sidecar injector for the
+pod read from the given manifest, without access to a cluster. It prints the
+pod resulting from the injection, or the JSON patch returned by the injector
+with --output patch, for the injection to be reviewed before deploying the pod,
+e.g. in CI.
+
+The mesh config is read from the osm-config ConfigMap manifest given with
+--mesh-config-file, and defaults to the default mesh config when unset. The
+IP ranges excluded from outbound traffic interception can be overridden with
+--outbound-ip-range-exclusion-list. The namespace of the pod, which holds the
+annotations overriding the mesh config for its pods, can be read from the
+manifest given with --namespace-file.
+
+The pod is injected as if its namespace is part of the mesh, regardless of the
+sidecar injection annotations. The proxy UUID of the pod is random unless it is
+requested by the openservicemesh.io/proxy-uuid annotation of the pod.
+`
+
+const injectorRenderExample = `
+# Render the pod resulting from the injection of the pod in pod.yaml with the default mesh config
+osm injector render -f pod.yaml
+
+# Render the JSON patch for the pod in pod.yaml with the mesh config in osm-config.yaml
+osm injector render -f pod.yaml --mesh-config-file osm-config.yaml -o patch
+
+# Render the injection of a pod read from stdin, excluding 10.0.0.0/8 from outbound traffic interception
+kubectl get pod bookbuyer-5ccf77f46d-rc5mg -n bookbuyer -o yaml | osm injector render -f - --outbound-ip-range-exclusion-list 10.0.0.0/8
+`
+
+const (
+	// renderOutputPod is the output format of the command printing the pod resulting from the injection as YAML
+	renderOutputPod = "pod"
+
+	// renderOutputPatch is the output format of the command printing the JSON patch returned by the injector
+	renderOutputPatch = "patch"
+
+	// outboundIPRangeExclusionListKey is the key of the mesh config holding the IP ranges excluded from outbound traffic interception
+	outboundIPRangeExclusionListKey = "outbound_ip_range_exclusion_list"
+)
+
+type injectorRenderCmd struct {
+	in                           io.Reader
+	out                          io.Writer
+	filename                     string
+	meshConfigFile               string
+	namespaceFile                string
+	meshName                     string
+	sidecarImage                 string
+	initContainerImage           string
+	outboundIPRangeExclusionList []string
+	annotationPrefix             string
+	output                       string
+}
+
+func newInjectorRenderCmd(in io.Reader, out io.Writer) *cobra.Command {
+	renderCmd := &injectorRenderCmd{
+		in:  in,
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:         "render",
+		Short:       "render the sidecar injection of a pod offline",
+		Long:        injectorRenderDescription,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{skipVersionCheckAnnotation: "true"},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			return renderCmd.run()
+		},
+		Example: injectorRenderExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&renderCmd.filename, "filename", "f", "", "File containing the pod to inject, or - to read it from stdin")
+	f.StringVar(&renderCmd.meshConfigFile, "mesh-config-file", "", "File containing the osm-config ConfigMap of the mesh, the default mesh config is used if unset")
+	f.StringVar(&renderCmd.namespaceFile, "namespace-file", "", "File containing the namespace of the pod, holding the annotations overriding the mesh config for its pods")
+	f.StringVar(&renderCmd.meshName, "mesh-name", defaultMeshName, "Name of the mesh")
+	f.StringVar(&renderCmd.sidecarImage, "sidecar-image", "", "Image of the Envoy sidecar, unless overridden by the pod, defaults to the sidecar image of the OSM chart")
+	f.StringVar(&renderCmd.initContainerImage, "init-container-image", fmt.Sprintf("%s/init:%s", defaultContainerRegistry, defaultOsmImageTag), "Image of the init container")
+	f.StringSliceVar(&renderCmd.outboundIPRangeExclusionList, "outbound-ip-range-exclusion-list", nil, "IP ranges to exclude from outbound traffic interception, overriding the mesh config. Pass once per IP range or a single comma separated list of IP ranges of the form a.b.c.d/x or a:b::c/x")
+	f.StringVar(&renderCmd.annotationPrefix, "annotation-prefix", injector.DefaultAnnotationPrefix, "Prefix of the keys of the OSM annotations read by the sidecar injector")
+	f.StringVarP(&renderCmd.output, "output", "o", renderOutputPod, fmt.Sprintf("Output format, one of: %s, %s", renderOutputPod, renderOutputPatch))
+
+	return cmd
+}
+
+func (cmd *injectorRenderCmd) run() error {
+	if cmd.filename == "" {
+		return errors.New("flag --filename is required")
+	}
+	if cmd.output != renderOutputPod && cmd.output != renderOutputPatch {
+		return errors.Errorf("Invalid value %q for flag --output, expected one of: %s, %s", cmd.output, renderOutputPod, renderOutputPatch)
+	}
+	if cmd.annotationPrefix != "" {
+		if err := injector.ValidateAnnotationPrefix(cmd.annotationPrefix); err != nil {
+			return errors.Errorf("Invalid value for flag --annotation-prefix: %s", err)
+		}
+	}
+
+	if cmd.sidecarImage == "" {
+		sidecarImage, err := getChartSidecarImage()
+		if err != nil {
+			return err
+		}
+		cmd.sidecarImage = sidecarImage
+	}
+
+	var pod corev1.Pod
+	if err := cmd.readManifest(cmd.filename, "Pod", &pod); err != nil {
+		return err
+	}
+
+	meshConfig := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: osmConfigMapName}}
+	if cmd.meshConfigFile != "" {
+		if err := cmd.readManifest(cmd.meshConfigFile, "ConfigMap", meshConfig); err != nil {
+			return err
+		}
+	}
+	if len(cmd.outboundIPRangeExclusionList) > 0 {
+		if meshConfig.Data == nil {
+			meshConfig.Data = make(map[string]string)
+		}
+		meshConfig.Data[outboundIPRangeExclusionListKey] = strings.Join(cmd.outboundIPRangeExclusionList, ",")
+	}
+
+	namespace, err := cmd.getNamespace(pod.Namespace)
+	if err != nil {
+		return err
+	}
+
+	config := injector.Config{
+		SidecarImage:       cmd.sidecarImage,
+		InitContainerImage: cmd.initContainerImage,
+		AnnotationPrefix:   cmd.annotationPrefix,
+	}
+	patch, injectedPod, err := injector.Render(&pod, namespace, meshConfig, config, cmd.meshName, settings.Namespace())
+	if err != nil {
+		return errors.Errorf("Error rendering the sidecar injection of pod %s in namespace %s: %s", pod.Name, namespace.Name, err)
+	}
+
+	if cmd.output == renderOutputPatch {
+		var indented bytes.Buffer
+		if err := json.Indent(&indented, patch, "", "  "); err != nil {
+			return errors.Errorf("Error rendering JSON patch: %s", err)
+		}
+		fmt.Fprintln(cmd.out, indented.String())
+		return nil
+	}
+
+	podYAML, err := yaml.Marshal(injectedPod)
+	if err != nil {
+		return errors.Errorf("Error marshaling pod: %s", err)
+	}
+	fmt.Fprint(cmd.out, string(podYAML))
+	return nil
+}
+
+// getNamespace returns the namespace read from --namespace-file, or a namespace without annotations if unset. The
+// namespace defaults to the default namespace when neither the pod nor --namespace-file set it.
+func (cmd *injectorRenderCmd) getNamespace(podNamespace string) (*corev1.Namespace, error) {
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: podNamespace}}
+	if cmd.namespaceFile != "" {
+		if err := cmd.readManifest(cmd.namespaceFile, "Namespace", namespace); err != nil {
+			return nil, err
+		}
+		if podNamespace != "" && namespace.Name != podNamespace {
+			return nil, errors.Errorf("Namespace %s read from %s does not match the namespace %s of the pod", namespace.Name, cmd.namespaceFile, podNamespace)
+		}
+	}
+	if namespace.Name == "" {
+		namespace.Name = metav1.NamespaceDefault
+	}
+	return namespace, nil
+}
+
+// readManifest decodes the object of the given kind from the single YAML or JSON manifest read from the given file,
+// or from stdin if the file name is -
+func (cmd *injectorRenderCmd) readManifest(filename, kind string, obj interface{}) error {
+	in := cmd.in
+	if filename != stdinFileName {
+		fd, err := os.Open(filename)
+		if err != nil {
+			return errors.Errorf("Error opening file %s: %s", filename, err)
+		}
+		defer fd.Close() //nolint: errcheck, gosec
+		in = fd
+	}
+
+	manifests, err := readManifests(in)
+	if err != nil {
+		return errors.Errorf("Error reading %s from %s: %s", kind, filename, err)
+	}
+	if len(manifests) != 1 {
+		return errors.Errorf("Expected a single %s in %s, found %d manifests", kind, filename, len(manifests))
+	}
+	if manifests[0].typeMeta.Kind != kind {
+		return errors.Errorf("Expected a %s in %s, found kind %q", kind, filename, manifests[0].typeMeta.Kind)
+	}
+	if err := json.Unmarshal(manifests[0].raw, obj); err != nil {
+		return errors.Errorf("Error reading %s from %s: %s", kind, filename, err)
+	}
+	return nil
+}
+
+// getChartSidecarImage returns the Envoy image the OSM chart configures the sidecar injector with
+func getChartSidecarImage() (string, error) {
+	chart, err := cli.LoadChart(chartTGZSource)
+	if err != nil {
+		return "", errors.Errorf("Error loading the OSM chart: %s", err)
+	}
+	sidecarImage, err := chartutil.Values(chart.Values).PathValue("OpenServiceMesh.sidecarImage")
+	if err != nil {
+		return "", errors.Errorf("Error reading the sidecar image of the OSM chart, set it with --sidecar-image: %s", err)
+	}
+	image, ok := sidecarImage.(string)
+	if !ok || image == "" {
+		return "", errors.New("The OSM chart sets no sidecar image, set it with --sidecar-image")
+	}
+	return image, nil
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+	trequire "github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openservicemesh/osm/pkg/cli"
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+const testRenderPod = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: bookstore
+  namespace: bookstore
+  annotations:
+    openservicemesh.io/proxy-uuid: 0b4f7c3e-5a4e-4a8e-9f4b-2f1d3c6b7a90
+spec:
+  serviceAccountName: bookstore
+  containers:
+  - name: bookstore
+    image: bookstore
+`
+
+func TestInjectorRender(t *testing.T) {
+	// The sidecar image defaults to the one of the chart
+	chartSource, err := cli.GetChartSource(filepath.Join("testdata", "test-chart"))
+	trequire.Nil(t, err)
+	defaultChartSource := chartTGZSource
+	chartTGZSource = chartSource
+	defer func() { chartTGZSource = defaultChartSource }()
+
+	testCases := []struct {
+		name                         string
+		manifest                     string
+		output                       string
+		sidecarImage                 string
+		outboundIPRangeExclusionList []string
+		expectErr                    bool
+	}{
+		{
+			name:     "render the injected pod",
+			manifest: testRenderPod,
+			output:   renderOutputPod,
+		},
+		{
+			name:         "render the injected pod with a sidecar image",
+			manifest:     testRenderPod,
+			output:       renderOutputPod,
+			sidecarImage: "envoyproxy/envoy-alpine:test",
+		},
+		{
+			name:                         "render the injected pod with outbound IP range exclusions",
+			manifest:                     testRenderPod,
+			output:                       renderOutputPod,
+			outboundIPRangeExclusionList: []string{"10.0.0.0/8", "192.168.0.0/16"},
+		},
+		{
+			name:     "render the JSON patch",
+			manifest: testRenderPod,
+			output:   renderOutputPatch,
+		},
+		{
+			name:      "invalid output format",
+			manifest:  testRenderPod,
+			output:    "json",
+			expectErr: true,
+		},
+		{
+			name:      "several manifests",
+			manifest:  testRenderPod + "---\n" + testRenderPod,
+			output:    renderOutputPod,
+			expectErr: true,
+		},
+		{
+			name:      "manifest of another kind",
+			manifest:  strings.Replace(testRenderPod, "kind: Pod", "kind: Deployment", 1),
+			output:    renderOutputPod,
+			expectErr: true,
+		},
+		{
+			name:                         "invalid outbound IP range exclusion",
+			manifest:                     testRenderPod,
+			output:                       renderOutputPod,
+			outboundIPRangeExclusionList: []string{"10.0.0.1"},
+			expectErr:                    true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			out := new(bytes.Buffer)
+			cmd := &injectorRenderCmd{
+				in:                           strings.NewReader(tc.manifest),
+				out:                          out,
+				filename:                     stdinFileName,
+				meshName:                     defaultMeshName,
+				sidecarImage:                 tc.sidecarImage,
+				initContainerImage:           "openservicemesh/init:test",
+				outboundIPRangeExclusionList: tc.outboundIPRangeExclusionList,
+				output:                       tc.output,
+			}
+
+			err := cmd.run()
+			assert.Equal(tc.expectErr, err != nil)
+			if tc.expectErr {
+				return
+			}
+
+			if tc.output == renderOutputPatch {
+				assert.Contains(out.String(), `"path": "/spec/containers/1"`)
+				assert.Contains(out.String(), `"path": "/spec/initContainers"`)
+				return
+			}
+
+			var pod corev1.Pod
+			assert.Nil(yaml.Unmarshal(out.Bytes(), &pod))
+			assert.Len(pod.Spec.Containers, 2)
+			assert.Equal(constants.EnvoyContainerName, pod.Spec.Containers[1].Name)
+			expectedSidecarImage := tc.sidecarImage
+			if expectedSidecarImage == "" {
+				expectedSidecarImage = "test-sidecar-default"
+			}
+			assert.Equal(expectedSidecarImage, pod.Spec.Containers[1].Image)
+			assert.Len(pod.Spec.InitContainers, 1)
+			assert.Equal("openservicemesh/init:test", pod.Spec.InitContainers[0].Image)
+			for _, ipRange := range tc.outboundIPRangeExclusionList {
+				assert.Contains(pod.Spec.InitContainers[0].Args[1], ipRange)
+			}
+		})
+	}
+}
@@ -145,9 +145,10 @@ func newInstallCmd(config *helm.Configuration, out io.Writer) *cobra.Command {
 	}
 
 	cmd := &cobra.Command{
-		Use:   "install",
-		Short: "install osm control plane",
-		Long:  installDesc,
+		Use:         "install",
+		Short:       "install osm control plane",
+		Long:        installDesc,
+		Annotations: map[string]string{skipVersionCheckAnnotation: "true"},
 		RunE: func(_ *cobra.Command, args []string) error {
 			kubeconfig, err := settings.RESTClientGetter().ToRESTConfig()
 			if err != nil {
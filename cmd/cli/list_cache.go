@@ -0,0 +1,53 @@
+package main
+
+import (
+	"sync"
+)
+
+// listCacheKey identifies the list of the resources of a kind in a namespace
+type listCacheKey struct {
+	namespace string
+	kind      string
+}
+
+// listCache caches the resources listed within a single command invocation, so that repeated lookups of the resources
+// of a kind in a namespace reuse the result of a single list request and observe a consistent state of the cluster.
+// A nil listCache does not cache, every lookup lists the resources.
+type listCache struct {
+	sync.Mutex
+	entries map[listCacheKey]*listCacheEntry
+}
+
+type listCacheEntry struct {
+	once  sync.Once
+	items interface{}
+	err   error
+}
+
+func newListCache() *listCache {
+	return &listCache{
+		entries: make(map[listCacheKey]*listCacheEntry),
+	}
+}
+
+// get returns the resources of the given kind in the namespace, listed with the given function on the first lookup
+// and cached for the following lookups. Concurrent lookups of the same resources wait for a single list request.
+func (c *listCache) get(namespace, kind string, list func() (interface{}, error)) (interface{}, error) {
+	if c == nil {
+		return list()
+	}
+
+	key := listCacheKey{namespace: namespace, kind: kind}
+	c.Lock()
+	entry, ok := c.entries[key]
+	if !ok {
+		entry = &listCacheEntry{}
+		c.entries[key] = entry
+	}
+	c.Unlock()
+
+	entry.once.Do(func() {
+		entry.items, entry.err = list()
+	})
+	return entry.items, entry.err
+}
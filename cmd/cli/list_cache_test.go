@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	fakeAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned/fake"
+	tassert "github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestListCache(t *testing.T) {
+	assert := tassert.New(t)
+
+	var listCount int
+	list := func() (interface{}, error) {
+		listCount++
+		return []string{"item"}, nil
+	}
+
+	cache := newListCache()
+	for i := 0; i < 3; i++ {
+		items, err := cache.get("ns-1", trafficTargetKind, list)
+		assert.Nil(err)
+		assert.Equal([]string{"item"}, items)
+	}
+	assert.Equal(1, listCount)
+
+	// The resources of another kind or namespace are listed separately
+	_, _ = cache.get("ns-1", trafficSplitKind, list)
+	_, _ = cache.get("ns-2", trafficTargetKind, list)
+	assert.Equal(3, listCount)
+
+	// A nil cache lists the resources on every lookup
+	var noCache *listCache
+	_, _ = noCache.get("ns-1", trafficTargetKind, list)
+	_, _ = noCache.get("ns-1", trafficTargetKind, list)
+	assert.Equal(5, listCount)
+}
+
+func TestListTrafficTargetsCache(t *testing.T) {
+	testCases := []struct {
+		name              string
+		noCache           bool
+		expectedListCount int
+	}{
+		{
+			name:              "repeated lookups list the TrafficTargets once",
+			expectedListCount: 1,
+		},
+		{
+			name:              "repeated lookups list the TrafficTargets every time with --no-cache",
+			noCache:           true,
+			expectedListCount: 3,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			accessClient := fakeAccessClient.NewSimpleClientset(&smiAccess.TrafficTarget{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-1", Namespace: "ns-2"},
+			})
+			cmd := trafficPolicyCheckCmd{
+				noCache:         tc.noCache,
+				smiAccessClient: accessClient,
+			}
+			cmd.resetListCache()
+
+			for i := 0; i < 3; i++ {
+				trafficTargets, err := cmd.listTrafficTargets("ns-2")
+				assert.Nil(err)
+				assert.Len(trafficTargets, 1)
+			}
+
+			var listCount int
+			for _, action := range accessClient.Actions() {
+				if action.GetVerb() == "list" && action.GetResource().Resource == "traffictargets" {
+					listCount++
+				}
+			}
+			assert.Equal(tc.expectedListCount, listCount)
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+)
+
+// getMeshConfig returns the ConfigMap holding the configuration of the mesh whose control plane runs in the given
+// namespace. The name defaults to osm-config when empty. When the ConfigMap does not exist, the returned error lists
+// the mesh configs that exist in the namespace.
+func getMeshConfig(clientSet kubernetes.Interface, osmNamespace, name string) (*corev1.ConfigMap, error) {
+	if name == "" {
+		name = osmConfigMapName
+	}
+
+	configMap, err := clientSet.CoreV1().ConfigMaps(osmNamespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if err == nil {
+		return configMap, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, errors.Errorf("Error fetching mesh config %s/%s: %s", osmNamespace, name, err)
+	}
+
+	meshConfigNames, err := listMeshConfigNames(clientSet, osmNamespace)
+	if err != nil {
+		return nil, err
+	}
+	if len(meshConfigNames) == 0 {
+		return nil, errors.Errorf("Mesh config %s not found in namespace %s, no mesh configs exist in this namespace", name, osmNamespace)
+	}
+	return nil, errors.Errorf("Mesh config %s not found in namespace %s, use --mesh-config-name with one of the existing mesh configs: %s",
+		name, osmNamespace, strings.Join(meshConfigNames, ", "))
+}
+
+// getPermissiveTrafficPolicyMode returns whether the mesh whose control plane runs in the given namespace operates in
+// permissive traffic policy mode, according to its mesh config
+func getPermissiveTrafficPolicyMode(clientSet kubernetes.Interface, osmNamespace, meshConfigName string) (bool, error) {
+	configMap, err := getMeshConfig(clientSet, osmNamespace, meshConfigName)
+	if err != nil {
+		return false, err
+	}
+
+	configVal, err := configurator.GetBoolValueForKey(configMap, configurator.PermissiveTrafficPolicyModeKey)
+	if err != nil {
+		return false, errors.Errorf("Invalid value for key %q in %s/%s ConfigMap: %s", configurator.PermissiveTrafficPolicyModeKey, configMap.Namespace, configMap.Name, err)
+	}
+	return configVal, nil
+}
+
+// getEgressMode returns whether egress is enabled for the mesh whose control plane runs in the given namespace,
+// according to its mesh config
+func getEgressMode(clientSet kubernetes.Interface, osmNamespace, meshConfigName string) (bool, error) {
+	configMap, err := getMeshConfig(clientSet, osmNamespace, meshConfigName)
+	if err != nil {
+		return false, err
+	}
+
+	configVal, err := configurator.GetBoolValueForKey(configMap, configurator.EgressKey)
+	if err != nil {
+		return false, errors.Errorf("Invalid value for key %q in %s/%s ConfigMap: %s", configurator.EgressKey, configMap.Namespace, configMap.Name, err)
+	}
+	return configVal, nil
+}
+
+// listMeshConfigNames returns the sorted names of the ConfigMaps holding a mesh configuration in the given namespace
+func listMeshConfigNames(clientSet kubernetes.Interface, namespace string) ([]string, error) {
+	configMaps, err := clientSet.CoreV1().ConfigMaps(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, errors.Errorf("Error listing mesh configs in namespace %s: %s", namespace, err)
+	}
+
+	var names []string
+	for _, configMap := range configMaps.Items {
+		if _, ok := configMap.Data[configurator.PermissiveTrafficPolicyModeKey]; ok {
+			names = append(names, configMap.Name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// getMeshNamespace returns the namespace of the control plane of the given mesh, or the namespace set with the
+// --osm-namespace flag when no mesh name is given
+func getMeshNamespace(clientSet kubernetes.Interface, meshName string) (string, error) {
+	if meshName == "" {
+		return settings.Namespace(), nil
+	}
+
+	deployments, err := getControllerDeployments(clientSet)
+	if err != nil {
+		return "", errors.Errorf("Error listing the control planes of the meshes: %s", err)
+	}
+
+	var meshNames []string
+	for _, deployment := range deployments.Items {
+		if deployment.Labels["meshName"] == meshName {
+			return deployment.Namespace, nil
+		}
+		meshNames = append(meshNames, deployment.Labels["meshName"])
+	}
+	sort.Strings(meshNames)
+	if len(meshNames) == 0 {
+		return "", errors.Errorf("Mesh %s not found, no meshes are installed in the cluster", meshName)
+	}
+	return "", errors.Errorf("Mesh %s not found, existing meshes: %s", meshName, strings.Join(meshNames, ", "))
+}
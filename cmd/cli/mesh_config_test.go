@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+)
+
+func TestGetMeshConfig(t *testing.T) {
+	newConfigMap := func(namespace, name string, isMeshConfig bool) *corev1.ConfigMap {
+		configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+		if isMeshConfig {
+			configMap.Data = map[string]string{configurator.PermissiveTrafficPolicyModeKey: "false"}
+		}
+		return configMap
+	}
+
+	clientSet := fake.NewSimpleClientset(
+		newConfigMap("osm-system", osmConfigMapName, true),
+		newConfigMap("renamed", "osm-config-prod", true),
+		newConfigMap("renamed", "osm-config-staging", true),
+		newConfigMap("renamed", "fluentbit-configmap", false),
+	)
+
+	testCases := []struct {
+		name         string
+		namespace    string
+		configName   string
+		expectedName string
+		expectedErr  string
+	}{
+		{
+			name:         "default mesh config",
+			namespace:    "osm-system",
+			expectedName: osmConfigMapName,
+		},
+		{
+			name:         "renamed mesh config",
+			namespace:    "renamed",
+			configName:   "osm-config-staging",
+			expectedName: "osm-config-staging",
+		},
+		{
+			name:        "mesh config not found",
+			namespace:   "renamed",
+			expectedErr: "Mesh config osm-config not found in namespace renamed, use --mesh-config-name with one of the existing mesh configs: osm-config-prod, osm-config-staging",
+		},
+		{
+			name:        "no mesh configs in the namespace",
+			namespace:   "empty",
+			configName:  "osm-config-prod",
+			expectedErr: "Mesh config osm-config-prod not found in namespace empty, no mesh configs exist in this namespace",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			configMap, err := getMeshConfig(clientSet, tc.namespace, tc.configName)
+			if tc.expectedErr != "" {
+				assert.NotNil(err)
+				assert.Equal(tc.expectedErr, err.Error())
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(tc.expectedName, configMap.Name)
+		})
+	}
+}
+
+func TestGetMeshNamespace(t *testing.T) {
+	testCases := []struct {
+		name              string
+		meshName          string
+		deployments       []runtime.Object
+		expectedNamespace string
+		expectedErr       string
+	}{
+		{
+			name:              "namespace flag used without a mesh name",
+			expectedNamespace: settings.Namespace(),
+		},
+		{
+			name:              "namespace of the mesh",
+			meshName:          "prod",
+			deployments:       []runtime.Object{createDeploymentSpec("osm-staging", "staging"), createDeploymentSpec("osm-prod", "prod")},
+			expectedNamespace: "osm-prod",
+		},
+		{
+			name:        "mesh not found",
+			meshName:    "dev",
+			deployments: []runtime.Object{createDeploymentSpec("osm-staging", "staging"), createDeploymentSpec("osm-prod", "prod")},
+			expectedErr: "Mesh dev not found, existing meshes: prod, staging",
+		},
+		{
+			name:        "no meshes installed",
+			meshName:    "dev",
+			expectedErr: "Mesh dev not found, no meshes are installed in the cluster",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			namespace, err := getMeshNamespace(fake.NewSimpleClientset(tc.deployments...), tc.meshName)
+			if tc.expectedErr != "" {
+				assert.NotNil(err)
+				assert.Equal(tc.expectedErr, err.Error())
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(tc.expectedNamespace, namespace)
+		})
+	}
+}
@@ -90,10 +90,11 @@ func newMeshUpgradeCmd(config *helm.Configuration, out io.Writer) *cobra.Command
 	var chartPath string
 
 	cmd := &cobra.Command{
-		Use:     "upgrade",
-		Short:   "upgrade osm control plane configuration",
-		Long:    upgradeDesc,
-		Example: meshUpgradeExample,
+		Use:         "upgrade",
+		Short:       "upgrade osm control plane configuration",
+		Long:        upgradeDesc,
+		Annotations: map[string]string{skipVersionCheckAnnotation: "true"},
+		Example:     meshUpgradeExample,
 		RunE: func(cmd *cobra.Command, _ []string) error {
 			// By default, bool flags should remain unchanged unless explicitly set or unset.
 			f := cmd.Flags()
@@ -149,7 +150,7 @@ func newMeshUpgradeCmd(config *helm.Configuration, out io.Writer) *cobra.Command
 	f.StringVar(&upg.tracingAddress, "tracing-address", "", "Tracing server hostname")
 	f.Uint16Var(&upg.tracingPort, "tracing-port", 0, "Tracing server port")
 	f.StringVar(&upg.tracingEndpoint, "tracing-endpoint", "", "Tracing server endpoint")
-	f.StringSliceVar(&upg.outboundIPRangeExclusionList, "outbound-ip-range-exclusion-list", nil, "A global list of IP ranges to exclude from outbound traffic interception by the sidecar proxy. Pass once per IP range or a single comma separated list of IP ranges of the form a.b.c.d/x")
+	f.StringSliceVar(&upg.outboundIPRangeExclusionList, "outbound-ip-range-exclusion-list", nil, "A global list of IP ranges to exclude from outbound traffic interception by the sidecar proxy. Pass once per IP range or a single comma separated list of IPv4 or IPv6 IP ranges of the form a.b.c.d/x or a:b::c/x")
 	f.BoolVar(upg.enablePrivilegedInitContainer, "enable-privileged-init-container", defaultPrivilegedInitContainer, "Run init container in privileged mode")
 
 	return cmd
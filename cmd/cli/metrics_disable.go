@@ -13,17 +13,23 @@ import (
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/openservicemesh/osm/pkg/constants"
+	"github.com/openservicemesh/osm/pkg/injector"
 )
 
 const metricsDisableDescription = `
 This command will disable metrics scraping on all pods belonging to the given
 namespace or set of namespaces.
+
+The metrics annotation, and the osm-proxy-uuid label of the meshed pods, take
+the annotation prefix the sidecar injector of the mesh of each namespace is
+configured with, unless given with --annotation-prefix.
 `
 
 type metricsDisableCmd struct {
-	out        io.Writer
-	namespaces []string
-	clientSet  kubernetes.Interface
+	out              io.Writer
+	namespaces       []string
+	annotationPrefix string
+	clientSet        kubernetes.Interface
 }
 
 func newMetricsDisable(out io.Writer) *cobra.Command {
@@ -53,6 +59,7 @@ func newMetricsDisable(out io.Writer) *cobra.Command {
 
 	f := cmd.Flags()
 	f.StringSliceVar(&disableCmd.namespaces, "namespace", []string{}, "One or more namespaces to disable metrics on")
+	f.StringVar(&disableCmd.annotationPrefix, "annotation-prefix", "", "Prefix of the metrics annotation and the proxy UUID label, the prefix the sidecar injector of the mesh is configured with if unset")
 
 	return cmd
 }
@@ -79,6 +86,11 @@ func (cmd *metricsDisableCmd) run() error {
 				ns, constants.OSMKubeResourceMonitorAnnotation)
 		}
 
+		prefix, err := getAnnotationPrefix(cmd.clientSet, namespace.Labels[constants.OSMKubeResourceMonitorAnnotation], cmd.annotationPrefix)
+		if err != nil {
+			return err
+		}
+
 		// Patch the namespace to remove the metrics annotation.
 		patch := fmt.Sprintf(`
 {
@@ -87,7 +99,7 @@ func (cmd *metricsDisableCmd) run() error {
 			"%s": null
 		}
 	}
-}`, constants.MetricsAnnotation)
+}`, injector.ResolveAnnotation(prefix, constants.MetricsAnnotation))
 
 		_, err = cmd.clientSet.CoreV1().Namespaces().Patch(ctx, ns, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{}, "")
 		if err != nil {
@@ -95,7 +107,7 @@ func (cmd *metricsDisableCmd) run() error {
 		}
 
 		// Disable metrics on pods belonging to this namespace
-		if err := cmd.disableMetricsForPods(ns); err != nil {
+		if err := cmd.disableMetricsForPods(ns, injector.ProxyUUIDLabel(prefix)); err != nil {
 			return errors.Errorf("Failed to disable metrics for existing pod in namespace [%s]: %v", ns, err)
 		}
 
@@ -105,11 +117,12 @@ func (cmd *metricsDisableCmd) run() error {
 	return nil
 }
 
-// disableMetricsForPods disables metrics for existing pods in the given namespace
-func (cmd *metricsDisableCmd) disableMetricsForPods(namespace string) error {
+// disableMetricsForPods disables metrics for existing pods in the given namespace, the meshed pods being those with the
+// given proxy UUID label
+func (cmd *metricsDisableCmd) disableMetricsForPods(namespace, proxyUUIDLabel string) error {
 	listOptions := metav1.ListOptions{
 		// Matches on pods which are already a part of the mesh, which contain the Envoy ID label
-		LabelSelector: constants.EnvoyUniqueIDLabelName,
+		LabelSelector: proxyUUIDLabel,
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -13,6 +13,7 @@ import (
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/openservicemesh/osm/pkg/constants"
+	"github.com/openservicemesh/osm/pkg/injector"
 )
 
 const metricsEnableDescription = `
@@ -21,12 +22,17 @@ namespace or set of namespaces. Newly created pods belonging to namespaces that
 are enabled for metrics will be automatically enabled with metrics.
 
 The command does not deploy a metrics collection service such as Prometheus.
+
+The metrics annotation, and the osm-proxy-uuid label of the meshed pods, take
+the annotation prefix the sidecar injector of the mesh of each namespace is
+configured with, unless given with --annotation-prefix.
 `
 
 type metricsEnableCmd struct {
-	out        io.Writer
-	namespaces []string
-	clientSet  kubernetes.Interface
+	out              io.Writer
+	namespaces       []string
+	annotationPrefix string
+	clientSet        kubernetes.Interface
 }
 
 func newMetricsEnable(out io.Writer) *cobra.Command {
@@ -57,6 +63,7 @@ func newMetricsEnable(out io.Writer) *cobra.Command {
 	//add mesh name flag
 	f := cmd.Flags()
 	f.StringSliceVar(&enableCmd.namespaces, "namespace", []string{}, "One or more namespaces to enable metrics on")
+	f.StringVar(&enableCmd.annotationPrefix, "annotation-prefix", "", "Prefix of the metrics annotation and the proxy UUID label, the prefix the sidecar injector of the mesh is configured with if unset")
 
 	return cmd
 }
@@ -84,6 +91,11 @@ func (cmd *metricsEnableCmd) run() error {
 				ns, constants.OSMKubeResourceMonitorAnnotation)
 		}
 
+		prefix, err := getAnnotationPrefix(cmd.clientSet, namespace.Labels[constants.OSMKubeResourceMonitorAnnotation], cmd.annotationPrefix)
+		if err != nil {
+			return err
+		}
+
 		// Patch the namespace with metrics annotation.
 		// osm-controller uses this annotation to automatically enable new pods for metrics scraping.
 		patch := fmt.Sprintf(`
@@ -93,7 +105,7 @@ func (cmd *metricsEnableCmd) run() error {
 			"%s": "enabled"
 		}
 	}
-}`, constants.MetricsAnnotation)
+}`, injector.ResolveAnnotation(prefix, constants.MetricsAnnotation))
 
 		_, err = cmd.clientSet.CoreV1().Namespaces().Patch(ctx, ns, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{}, "")
 		if err != nil {
@@ -102,7 +114,7 @@ func (cmd *metricsEnableCmd) run() error {
 
 		// For existing pods in this namespace that are already part of the mesh, add the prometheus
 		// scraping annotations.
-		if err := cmd.enableMetricsForPods(ns); err != nil {
+		if err := cmd.enableMetricsForPods(ns, injector.ProxyUUIDLabel(prefix)); err != nil {
 			return errors.Errorf("Failed to enable metrics for existing pod in namespace [%s]: %v", ns, err)
 		}
 
@@ -112,11 +124,12 @@ func (cmd *metricsEnableCmd) run() error {
 	return nil
 }
 
-// enableMetricsForPods enables metrics for existing pods in the given namespace
-func (cmd *metricsEnableCmd) enableMetricsForPods(namespace string) error {
+// enableMetricsForPods enables metrics for existing pods in the given namespace, the meshed pods being those with the
+// given proxy UUID label
+func (cmd *metricsEnableCmd) enableMetricsForPods(namespace, proxyUUIDLabel string) error {
 	listOptions := metav1.ListOptions{
 		// Matches on pods which are already a part of the mesh, which contain the Envoy ID label
-		LabelSelector: constants.EnvoyUniqueIDLabelName,
+		LabelSelector: proxyUUIDLabel,
 	}
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -21,21 +21,6 @@ const (
 	testMesh = "osm"
 )
 
-func newNamespace(name string, annotations map[string]string) *corev1.Namespace {
-	ns := &corev1.Namespace{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:   name,
-			Labels: map[string]string{constants.OSMKubeResourceMonitorAnnotation: testMesh},
-		},
-	}
-
-	if annotations != nil {
-		ns.Annotations = annotations
-	}
-
-	return ns
-}
-
 func newMeshPod(name string, scrapingEnabled bool) *corev1.Pod {
 	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
@@ -144,6 +129,44 @@ func TestRun_MetricsEnable(t *testing.T) {
 	}
 }
 
+func TestRun_MetricsEnableAnnotationPrefix(t *testing.T) {
+	assert := tassert.New(t)
+	fakeClient := fake.NewSimpleClientset(newInjectorDeployment("osm-system", testMesh, "--annotation-prefix", "mesh.example.com"))
+
+	err := createFakeController(fakeClient)
+	assert.Nil(err)
+
+	_, err = fakeClient.CoreV1().Namespaces().Create(context.TODO(), newNamespace("ns-1", nil), metav1.CreateOptions{})
+	assert.Nil(err)
+	prefixedPod := newMeshPod("test-1", false)
+	prefixedPod.Labels = map[string]string{"mesh.example.com/" + constants.EnvoyUniqueIDLabelName: "test"}
+	_, err = fakeClient.CoreV1().Pods("ns-1").Create(context.TODO(), prefixedPod, metav1.CreateOptions{})
+	assert.Nil(err)
+	_, err = fakeClient.CoreV1().Pods("ns-1").Create(context.TODO(), newMeshPod("test-2", false), metav1.CreateOptions{})
+	assert.Nil(err)
+
+	cmd := &metricsEnableCmd{
+		out:        new(bytes.Buffer),
+		namespaces: []string{"ns-1"},
+		clientSet:  fakeClient,
+	}
+	err = cmd.run()
+	assert.Nil(err)
+
+	ns, err := fakeClient.CoreV1().Namespaces().Get(context.TODO(), "ns-1", metav1.GetOptions{})
+	assert.Nil(err)
+	assert.Equal("enabled", ns.Annotations["mesh.example.com/metrics"])
+	assert.NotContains(ns.Annotations, constants.MetricsAnnotation)
+
+	// Only the pod labeled with the prefixed proxy UUID label is meshed
+	pod, err := fakeClient.CoreV1().Pods("ns-1").Get(context.TODO(), "test-1", metav1.GetOptions{})
+	assert.Nil(err)
+	assert.Equal("true", pod.Annotations[constants.PrometheusScrapeAnnotation])
+	pod, err = fakeClient.CoreV1().Pods("ns-1").Get(context.TODO(), "test-2", metav1.GetOptions{})
+	assert.Nil(err)
+	assert.NotContains(pod.Annotations, constants.PrometheusScrapeAnnotation)
+}
+
 func TestRun_MetricsDisable(t *testing.T) {
 	assert := tassert.New(t)
 	fakeClient := fake.NewSimpleClientset()
@@ -4,15 +4,18 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/openservicemesh/osm/pkg/constants"
+	"github.com/openservicemesh/osm/pkg/injector"
 )
 
 const namespaceAddDescription = `
@@ -21,6 +24,13 @@ control plane with the given mesh name can observe resources within that namespa
 or set of namespaces. It also enables automatic sidecar injection for all pods
 created within the given namespace. Automatic sidecar injection can be disabled
 via the --disable-sidecar-injection flag.
+
+Adding a namespace already part of the mesh with the requested sidecar injection
+setting has no effect. A namespace part of another mesh must be removed from
+that mesh before it can be added.
+
+The sidecar injection annotation takes the annotation prefix the sidecar
+injector of the mesh is configured with, unless given with --annotation-prefix.
 `
 const namespaceAddExample = `
 # Add namespace 'test' to the mesh with automatic sidecar injection enabled.
@@ -43,6 +53,7 @@ type namespaceAddCmd struct {
 	namespaces              []string
 	meshName                string
 	disableSidecarInjection bool
+	annotationPrefix        string
 	clientSet               kubernetes.Interface
 }
 
@@ -79,11 +90,18 @@ func newNamespaceAdd(out io.Writer) *cobra.Command {
 
 	//add sidecar injection flag
 	f.BoolVar(&namespaceAdd.disableSidecarInjection, "disable-sidecar-injection", false, "Disable automatic sidecar injection")
+	f.StringVar(&namespaceAdd.annotationPrefix, "annotation-prefix", "", "Prefix of the sidecar injection annotation, the prefix the sidecar injector of the mesh is configured with if unset")
 
 	return cmd
 }
 
 func (a *namespaceAddCmd) run() error {
+	prefix, err := getAnnotationPrefix(a.clientSet, a.meshName, a.annotationPrefix)
+	if err != nil {
+		return err
+	}
+	sidecarInjectionAnnotation := injector.ResolveAnnotation(prefix, constants.SidecarInjectionAnnotation)
+
 	for _, ns := range a.namespaces {
 		ctx, cancel := context.WithCancel(context.Background())
 		defer cancel()
@@ -102,6 +120,21 @@ func (a *namespaceAddCmd) run() error {
 			continue
 		}
 
+		namespace, err := a.clientSet.CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{})
+		if err != nil {
+			return errors.Errorf("Could not add namespace [%s] to mesh [%s]: %v", ns, a.meshName, err)
+		}
+
+		if meshName, ok := namespace.Labels[constants.OSMKubeResourceMonitorAnnotation]; ok {
+			if meshName != a.meshName {
+				return errors.Errorf("Namespace [%s] already belongs to mesh [%s], remove it from mesh [%s] before adding it to mesh [%s]", ns, meshName, meshName, a.meshName)
+			}
+			if isSidecarInjectionEnabled(namespace, sidecarInjectionAnnotation) != a.disableSidecarInjection {
+				_, _ = fmt.Fprintf(a.out, "Namespace [%s] is already part of mesh [%s]\n", ns, a.meshName)
+				continue
+			}
+		}
+
 		var patch string
 		if a.disableSidecarInjection {
 			// Patch the namespace with monitoring label and disable sidecar injection if previously enabled.
@@ -115,7 +148,7 @@ func (a *namespaceAddCmd) run() error {
 			"%s": null
 		}
 	}
-}`, constants.OSMKubeResourceMonitorAnnotation, a.meshName, constants.SidecarInjectionAnnotation)
+}`, constants.OSMKubeResourceMonitorAnnotation, a.meshName, sidecarInjectionAnnotation)
 		} else {
 			// Patch the namespace with the monitoring label.
 			// Enable sidecar injection.
@@ -129,10 +162,10 @@ func (a *namespaceAddCmd) run() error {
 			"%s": "enabled"
 		}
 	}
-}`, constants.OSMKubeResourceMonitorAnnotation, a.meshName, constants.SidecarInjectionAnnotation)
+}`, constants.OSMKubeResourceMonitorAnnotation, a.meshName, sidecarInjectionAnnotation)
 		}
 
-		_, err := a.clientSet.CoreV1().Namespaces().Patch(ctx, ns, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{}, "")
+		_, err = a.clientSet.CoreV1().Namespaces().Patch(ctx, ns, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{}, "")
 		if err != nil {
 			return errors.Errorf("Could not add namespace [%s] to mesh [%s]: %v", ns, a.meshName, err)
 		}
@@ -142,3 +175,14 @@ func (a *namespaceAddCmd) run() error {
 
 	return nil
 }
+
+// isSidecarInjectionEnabled returns true if the given namespace is annotated with the given sidecar injection
+// annotation to enable automatic sidecar injection
+func isSidecarInjectionEnabled(namespace *corev1.Namespace, sidecarInjectionAnnotation string) bool {
+	switch strings.ToLower(namespace.Annotations[sidecarInjectionAnnotation]) {
+	case "enabled", "yes", "true":
+		return true
+	default:
+		return false
+	}
+}
@@ -16,11 +16,22 @@ import (
 
 const namespaceListDescription = `
 This command will list namespace information for all meshes. It is possible to filter by a given mesh.
+
+The columns of the table can be selected with the --columns flag, among:
+namespace, mesh, sidecar-injection.
 `
 
+// namespaceListColumns are the columns of the table of namespaces
+var namespaceListColumns = []tableColumn{
+	{name: "namespace", header: "NAMESPACE"},
+	{name: "mesh", header: "MESH"},
+	{name: "sidecar-injection", header: "SIDECAR-INJECTION"},
+}
+
 type namespaceListCmd struct {
 	out       io.Writer
 	meshName  string
+	columns   []string
 	clientSet kubernetes.Interface
 }
 
@@ -56,11 +67,16 @@ func newNamespaceList(out io.Writer) *cobra.Command {
 	//add mesh name flag
 	f := cmd.Flags()
 	f.StringVar(&namespaceList.meshName, "mesh-name", "", "Name of service mesh to list namespaces")
+	f.StringSliceVar(&namespaceList.columns, "columns", nil, "Comma separated list of the columns of the table, all the columns if unset")
 
 	return cmd
 }
 
 func (l *namespaceListCmd) run() error {
+	if err := validateColumns(namespaceListColumns, l.columns); err != nil {
+		return err
+	}
+
 	namespaces, err := l.selectNamespaces()
 	if err != nil {
 		return errors.Errorf("Could not list namespaces related to osm [%s]: %v", l.meshName, err)
@@ -76,8 +92,7 @@ func (l *namespaceListCmd) run() error {
 		return nil
 	}
 
-	w := newTabWriter(l.out)
-	fmt.Fprintln(w, "NAMESPACE\tMESH\tSIDECAR-INJECTION")
+	t := newTable(namespaceListColumns)
 	for _, ns := range namespaces.Items {
 		osmName := ns.ObjectMeta.Labels[constants.OSMKubeResourceMonitorAnnotation]
 		sidecarInjectionEnabled, ok := ns.ObjectMeta.Annotations[constants.SidecarInjectionAnnotation]
@@ -88,11 +103,9 @@ func (l *namespaceListCmd) run() error {
 			sidecarInjectionEnabled = "disabled (ignored)"
 		}
 
-		fmt.Fprintf(w, "%s\t%s\t%s\n", ns.Name, osmName, sidecarInjectionEnabled)
+		t.addRow(ns.Name, osmName, sidecarInjectionEnabled)
 	}
-	_ = w.Flush()
-
-	return nil
+	return t.write(l.out, l.columns)
 }
 
 func (l *namespaceListCmd) selectNamespaces() (*v1.NamespaceList, error) {
@@ -15,15 +15,29 @@ import (
 )
 
 const namespaceRemoveDescription = `
-This command will remove a namespace from the mesh. All
-services in this namespace will be removed from the mesh.
+This command will remove a namespace or set of namespaces from the mesh. All
+services in these namespaces will be removed from the mesh, and automatic
+sidecar injection will no longer be enabled for the pods created within them.
+Removing a namespace that does not belong to any mesh has no effect.
+`
+
+const namespaceRemoveExample = `
+# Remove namespace 'test' from the mesh.
+osm namespace remove test
+
+# Remove multiple namespaces (test, foo, bar, baz) from the mesh at the same time.
+osm namespace remove test foo bar baz
+
+# Specify which mesh (osm control plane) to remove the namespace from if multiple control planes
+are present or mesh name was overridden at install time
+osm namespace remove test --mesh-name=<my-mesh-name>
 `
 
 type namespaceRemoveCmd struct {
-	out       io.Writer
-	namespace string
-	meshName  string
-	clientSet kubernetes.Interface
+	out        io.Writer
+	namespaces []string
+	meshName   string
+	clientSet  kubernetes.Interface
 }
 
 func newNamespaceRemove(out io.Writer) *cobra.Command {
@@ -32,12 +46,12 @@ func newNamespaceRemove(out io.Writer) *cobra.Command {
 	}
 
 	cmd := &cobra.Command{
-		Use:   "remove <NAMESPACE>",
+		Use:   "remove NAMESPACE ...",
 		Short: "remove namespace from mesh",
 		Long:  namespaceRemoveDescription,
-		Args:  cobra.ExactArgs(1),
+		Args:  cobra.MinimumNArgs(1),
 		RunE: func(_ *cobra.Command, args []string) error {
-			namespaceRemove.namespace = args[0]
+			namespaceRemove.namespaces = args
 			config, err := settings.RESTClientGetter().ToRESTConfig()
 			if err != nil {
 				return errors.Errorf("Error fetching kubeconfig: %s", err)
@@ -50,6 +64,7 @@ func newNamespaceRemove(out io.Writer) *cobra.Command {
 			namespaceRemove.clientSet = clientset
 			return namespaceRemove.run()
 		},
+		Example: namespaceRemoveExample,
 	}
 
 	//add mesh name flag
@@ -60,22 +75,37 @@ func newNamespaceRemove(out io.Writer) *cobra.Command {
 }
 
 func (r *namespaceRemoveCmd) run() error {
+	for _, ns := range r.namespaces {
+		if err := r.removeNamespace(ns); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *namespaceRemoveCmd) removeNamespace(ns string) error {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	namespace, err := r.clientSet.CoreV1().Namespaces().Get(ctx, r.namespace, metav1.GetOptions{})
+	namespace, err := r.clientSet.CoreV1().Namespaces().Get(ctx, ns, metav1.GetOptions{})
 
 	if err != nil {
-		return errors.Errorf("Could not get namespace [%s]: %v", r.namespace, err)
+		return errors.Errorf("Could not get namespace [%s]: %v", ns, err)
 	}
 
 	val, exists := namespace.ObjectMeta.Labels[constants.OSMKubeResourceMonitorAnnotation]
-	if exists {
-		if val == r.meshName {
-			// Setting null for a key in a map removes only that specific key, which is the desired behavior.
-			// Even if the key does not exist, there will be no side effects with setting the key to null, which
-			// will result in the same behavior as if the key were present - the key being removed.
-			patch := fmt.Sprintf(`
+	if !exists {
+		fmt.Fprintf(r.out, "Namespace [%s] already does not belong to any mesh\n", ns)
+		return nil
+	}
+	if val != r.meshName {
+		return errors.Errorf("Namespace belongs to mesh [%s], not mesh [%s]. Please specify the correct mesh", val, r.meshName)
+	}
+
+	// Setting null for a key in a map removes only that specific key, which is the desired behavior.
+	// Even if the key does not exist, there will be no side effects with setting the key to null, which
+	// will result in the same behavior as if the key were present - the key being removed.
+	patch := fmt.Sprintf(`
 {
 	"metadata": {
 		"labels": {
@@ -87,20 +117,12 @@ func (r *namespaceRemoveCmd) run() error {
 	}
 }`, constants.OSMKubeResourceMonitorAnnotation, constants.SidecarInjectionAnnotation)
 
-			_, err = r.clientSet.CoreV1().Namespaces().Patch(ctx, r.namespace, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{}, "")
+	_, err = r.clientSet.CoreV1().Namespaces().Patch(ctx, ns, types.StrategicMergePatchType, []byte(patch), metav1.PatchOptions{}, "")
 
-			if err != nil {
-				return errors.Errorf("Could not remove namespace [%s] from mesh [%s]: %v", r.namespace, r.meshName, err)
-			}
-
-			fmt.Fprintf(r.out, "Namespace [%s] successfully removed from mesh [%s]\n", r.namespace, r.meshName)
-		} else {
-			return errors.Errorf("Namespace belongs to mesh [%s], not mesh [%s]. Please specify the correct mesh", val, r.meshName)
-		}
-	} else {
-		fmt.Fprintf(r.out, "Namespace [%s] already does not belong to any mesh\n", r.namespace)
-		return nil
+	if err != nil {
+		return errors.Errorf("Could not remove namespace [%s] from mesh [%s]: %v", ns, r.meshName, err)
 	}
 
+	fmt.Fprintf(r.out, "Namespace [%s] successfully removed from mesh [%s]\n", ns, r.meshName)
 	return nil
 }
@@ -73,6 +73,38 @@ var _ = Describe("Running the namespace add command", func() {
 			})
 		})
 
+		Context("given one namespace as an arg with a sidecar injector configured with an annotation prefix", func() {
+
+			BeforeEach(func() {
+				out = new(bytes.Buffer)
+				fakeClientSet = fake.NewSimpleClientset(newInjectorDeployment("osm-system", testMeshName, "--annotation-prefix", "mesh.example.com"))
+
+				nsSpec := createNamespaceSpec(testNamespace, "", false)
+				_, err = fakeClientSet.CoreV1().Namespaces().Create(context.TODO(), nsSpec, metav1.CreateOptions{})
+				Expect(err).To(BeNil())
+
+				namespaceAddCmd := &namespaceAddCmd{
+					out:        out,
+					meshName:   testMeshName,
+					namespaces: []string{testNamespace},
+					clientSet:  fakeClientSet,
+				}
+
+				err = namespaceAddCmd.run()
+			})
+
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should add an inject annotation with the annotation prefix to the namespace", func() {
+				ns, err := fakeClientSet.CoreV1().Namespaces().Get(context.TODO(), testNamespace, metav1.GetOptions{})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ns.Annotations["mesh.example.com/sidecar-injection"]).To(Equal("enabled"))
+				Expect(ns.Annotations).ToNot(HaveKey(constants.SidecarInjectionAnnotation))
+			})
+		})
+
 		Context("given one namespace as an arg with sidecar injection enabled", func() {
 
 			BeforeEach(func() {
@@ -204,6 +236,107 @@ var _ = Describe("Running the namespace add command", func() {
 			})
 		})
 
+		Context("given one namespace already part of the mesh with sidecar injection enabled", func() {
+			BeforeEach(func() {
+				out = new(bytes.Buffer)
+				fakeClientSet = fake.NewSimpleClientset()
+
+				nsSpec := createNamespaceSpec(testNamespace, testMeshName, true)
+				_, err = fakeClientSet.CoreV1().Namespaces().Create(context.TODO(), nsSpec, metav1.CreateOptions{})
+				Expect(err).To(BeNil())
+
+				namespaceAddCmd := &namespaceAddCmd{
+					out:        out,
+					meshName:   testMeshName,
+					namespaces: []string{testNamespace},
+					clientSet:  fakeClientSet,
+				}
+
+				err = namespaceAddCmd.run()
+			})
+
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should give a message saying the namespace is already part of the mesh", func() {
+				Expect(out.String()).To(Equal(fmt.Sprintf("Namespace [%s] is already part of mesh [%s]\n", testNamespace, testMeshName)))
+			})
+
+			It("should keep the inject annotation on the namespace", func() {
+				ns, err := fakeClientSet.CoreV1().Namespaces().Get(context.TODO(), testNamespace, metav1.GetOptions{})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ns.Annotations[constants.SidecarInjectionAnnotation]).To(Equal("enabled"))
+			})
+		})
+
+		Context("given one namespace already part of the mesh with sidecar injection disabled by the command", func() {
+			BeforeEach(func() {
+				out = new(bytes.Buffer)
+				fakeClientSet = fake.NewSimpleClientset()
+
+				nsSpec := createNamespaceSpec(testNamespace, testMeshName, true)
+				_, err = fakeClientSet.CoreV1().Namespaces().Create(context.TODO(), nsSpec, metav1.CreateOptions{})
+				Expect(err).To(BeNil())
+
+				namespaceAddCmd := &namespaceAddCmd{
+					out:                     out,
+					meshName:                testMeshName,
+					namespaces:              []string{testNamespace},
+					disableSidecarInjection: true,
+					clientSet:               fakeClientSet,
+				}
+
+				err = namespaceAddCmd.run()
+			})
+
+			It("should not error", func() {
+				Expect(err).NotTo(HaveOccurred())
+			})
+
+			It("should give a message confirming the successful install", func() {
+				Expect(out.String()).To(Equal(fmt.Sprintf("Namespace [%s] successfully added to mesh [%s]\n", testNamespace, testMeshName)))
+			})
+
+			It("should remove the inject annotation from the namespace", func() {
+				ns, err := fakeClientSet.CoreV1().Namespaces().Get(context.TODO(), testNamespace, metav1.GetOptions{})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ns.Labels[constants.OSMKubeResourceMonitorAnnotation]).To(Equal(testMeshName))
+				Expect(ns.Annotations[constants.SidecarInjectionAnnotation]).To(Equal(""))
+			})
+		})
+
+		Context("given one namespace part of another mesh", func() {
+			BeforeEach(func() {
+				out = new(bytes.Buffer)
+				fakeClientSet = fake.NewSimpleClientset()
+
+				nsSpec := createNamespaceSpec(testNamespace, incorrectMeshName, false)
+				_, err = fakeClientSet.CoreV1().Namespaces().Create(context.TODO(), nsSpec, metav1.CreateOptions{})
+				Expect(err).To(BeNil())
+
+				namespaceAddCmd := &namespaceAddCmd{
+					out:        out,
+					meshName:   testMeshName,
+					namespaces: []string{testNamespace},
+					clientSet:  fakeClientSet,
+				}
+
+				err = namespaceAddCmd.run()
+			})
+
+			It("should error", func() {
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal(fmt.Sprintf("Namespace [%s] already belongs to mesh [%s], remove it from mesh [%s] before adding it to mesh [%s]", testNamespace, incorrectMeshName, incorrectMeshName, testMeshName)))
+			})
+
+			It("should not change the mesh of the namespace", func() {
+				ns, err := fakeClientSet.CoreV1().Namespaces().Get(context.TODO(), testNamespace, metav1.GetOptions{})
+				Expect(err).ToNot(HaveOccurred())
+				Expect(ns.Labels[constants.OSMKubeResourceMonitorAnnotation]).To(Equal(incorrectMeshName))
+			})
+		})
+
 		Context("given one namespace with osm-controller installed in it as an arg", func() {
 			BeforeEach(func() {
 				out = new(bytes.Buffer)
@@ -282,10 +415,10 @@ var _ = Describe("Running the namespace remove command", func() {
 			Expect(err).To(BeNil())
 
 			namespaceRemoveCmd := &namespaceRemoveCmd{
-				out:       out,
-				meshName:  testMeshName,
-				namespace: testNamespace,
-				clientSet: fakeClientSet,
+				out:        out,
+				meshName:   testMeshName,
+				namespaces: []string{testNamespace},
+				clientSet:  fakeClientSet,
 			}
 
 			err = namespaceRemoveCmd.run()
@@ -322,10 +455,10 @@ var _ = Describe("Running the namespace remove command", func() {
 			Expect(err).ToNot(HaveOccurred())
 
 			namespaceRemoveCmd := &namespaceRemoveCmd{
-				out:       out,
-				meshName:  testMeshName,
-				namespace: testNamespace,
-				clientSet: fakeClientSet,
+				out:        out,
+				meshName:   testMeshName,
+				namespaces: []string{testNamespace},
+				clientSet:  fakeClientSet,
 			}
 
 			err = namespaceRemoveCmd.run()
@@ -352,6 +485,52 @@ var _ = Describe("Running the namespace remove command", func() {
 		})
 	})
 
+	Describe("with multiple namespaces, part of the mesh or not", func() {
+		var (
+			out            *bytes.Buffer
+			fakeClientSet  kubernetes.Interface
+			err            error
+			testNamespace2 = "namespace2"
+		)
+
+		BeforeEach(func() {
+			out = new(bytes.Buffer)
+			fakeClientSet = fake.NewSimpleClientset()
+
+			nsSpec := createNamespaceSpec(testNamespace, testMeshName, true)
+			_, err = fakeClientSet.CoreV1().Namespaces().Create(context.TODO(), nsSpec, metav1.CreateOptions{})
+			Expect(err).To(BeNil())
+
+			nsSpec2 := createNamespaceSpec(testNamespace2, "", false)
+			_, err = fakeClientSet.CoreV1().Namespaces().Create(context.TODO(), nsSpec2, metav1.CreateOptions{})
+			Expect(err).To(BeNil())
+
+			namespaceRemoveCmd := &namespaceRemoveCmd{
+				out:        out,
+				meshName:   testMeshName,
+				namespaces: []string{testNamespace, testNamespace2},
+				clientSet:  fakeClientSet,
+			}
+
+			err = namespaceRemoveCmd.run()
+		})
+
+		It("should not error", func() {
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should give a message for each namespace", func() {
+			Expect(out.String()).To(Equal(fmt.Sprintf("Namespace [%s] successfully removed from mesh [%s]\nNamespace [%s] already does not belong to any mesh\n", testNamespace, testMeshName, testNamespace2)))
+		})
+
+		It("should correctly remove the label and the inject annotation on the namespace part of the mesh", func() {
+			ns, err := fakeClientSet.CoreV1().Namespaces().Get(context.TODO(), testNamespace, metav1.GetOptions{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ns.Labels).ToNot(HaveKey(constants.OSMKubeResourceMonitorAnnotation))
+			Expect(ns.Annotations).ToNot(HaveKey(constants.SidecarInjectionAnnotation))
+		})
+	})
+
 	Describe("with pre-existing namespace and incorrect label", func() {
 		var (
 			out           *bytes.Buffer
@@ -368,10 +547,10 @@ var _ = Describe("Running the namespace remove command", func() {
 			Expect(err).To(BeNil())
 
 			namespaceRemoveCmd := &namespaceRemoveCmd{
-				out:       out,
-				meshName:  incorrectMeshName,
-				namespace: testNamespace,
-				clientSet: fakeClientSet,
+				out:        out,
+				meshName:   incorrectMeshName,
+				namespaces: []string{testNamespace},
+				clientSet:  fakeClientSet,
 			}
 
 			err = namespaceRemoveCmd.run()
@@ -400,10 +579,10 @@ var _ = Describe("Running the namespace remove command", func() {
 			Expect(err).To(BeNil())
 
 			namespaceRemoveCmd := &namespaceRemoveCmd{
-				out:       out,
-				meshName:  testMeshName,
-				namespace: testNamespace,
-				clientSet: fakeClientSet,
+				out:        out,
+				meshName:   testMeshName,
+				namespaces: []string{testNamespace},
+				clientSet:  fakeClientSet,
 			}
 
 			err = namespaceRemoveCmd.run()
@@ -430,10 +609,10 @@ var _ = Describe("Running the namespace remove command", func() {
 			fakeClientSet = fake.NewSimpleClientset()
 
 			namespaceRemoveCmd := &namespaceRemoveCmd{
-				out:       out,
-				meshName:  testMeshName,
-				namespace: testNamespace,
-				clientSet: fakeClientSet,
+				out:        out,
+				meshName:   testMeshName,
+				namespaces: []string{testNamespace},
+				clientSet:  fakeClientSet,
 			}
 
 			err = namespaceRemoveCmd.run()
@@ -3,6 +3,7 @@ package main
 
 import (
 	goflag "flag"
+	"fmt"
 	"io"
 	"os"
 
@@ -22,17 +23,28 @@ To install and configure OSM, run:
 
 var settings = cli.New()
 
+// checkVersion enables the version compatibility check between the CLI and the control plane
+var checkVersion bool
+
+// verbose enables the output of additional information about how the command is run
+var verbose bool
+
 func newRootCmd(config *action.Configuration, in io.Reader, out io.Writer, args []string) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:          "osm",
 		Short:        "Install and manage Open Service Mesh",
 		Long:         globalUsage,
 		SilenceUsage: true,
+		PersistentPreRun: func(cmd *cobra.Command, _ []string) {
+			rootPersistentPreRun(cmd)
+		},
 	}
 
 	cmd.PersistentFlags().AddGoFlagSet(goflag.CommandLine)
 	flags := cmd.PersistentFlags()
 	settings.AddFlags(flags)
+	flags.BoolVar(&checkVersion, "check-version", isVersionCheckEnabled(), fmt.Sprintf("check the CLI version is compatible with the OSM control plane version, defaults to the %s env var", checkVersionEnvVar))
+	flags.BoolVar(&verbose, "verbose", false, "print additional information, such as the resolved OSM namespace")
 
 	// Add subcommands here
 	cmd.AddCommand(
@@ -44,15 +56,59 @@ func newRootCmd(config *action.Configuration, in io.Reader, out io.Writer, args
 		newMetricsCmd(out),
 		newVersionCmd(out),
 		newProxyCmd(config, out),
-		newTrafficPolicyCmd(out),
+		newTrafficPolicyCmd(in, out),
 		newUninstallCmd(config, in, out),
+		newSupportBundleCmd(out),
+		newCheckCmd(out),
+		newConfigCmd(out),
+		newUpgradeCmd(out),
+		newControllerCmd(out),
+		newInjectorCmd(in, out),
+		newCleanupCmd(out),
 	)
+	chainPersistentPreRun(cmd, rootPersistentPreRun)
 
 	_ = flags.Parse(args)
 
 	return cmd
 }
 
+// rootPersistentPreRun runs before every command, whether or not the command or one of its parents defines its own
+// persistent pre-run hook
+func rootPersistentPreRun(cmd *cobra.Command) {
+	if settings.InsecureSkipTLSVerify() {
+		fmt.Fprintln(cmd.ErrOrStderr(), "WARNING: --insecure-skip-tls-verify is set, the certificate of the Kubernetes API server is not verified and the connections to it are insecure")
+	}
+	if verbose {
+		namespace, source := settings.ResolveNamespace()
+		fmt.Fprintf(cmd.ErrOrStderr(), "Using OSM namespace %s from %s\n", namespace, source)
+	}
+	if checkVersion {
+		warnOnVersionSkew(cmd)
+	}
+}
+
+// chainPersistentPreRun makes the persistent pre-run hooks of the subcommands of the given command run the given hook
+// first. Cobra only runs the persistent pre-run hook of the closest command defining one, so a subcommand defining its
+// own hook would otherwise skip the hook of the root command.
+func chainPersistentPreRun(cmd *cobra.Command, hook func(*cobra.Command)) {
+	for _, c := range cmd.Commands() {
+		if preRun := c.PersistentPreRun; preRun != nil {
+			c.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+				hook(cmd)
+				preRun(cmd, args)
+			}
+		}
+		if preRunE := c.PersistentPreRunE; preRunE != nil {
+			c.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+				hook(cmd)
+				return preRunE(cmd, args)
+			}
+		}
+		chainPersistentPreRun(c, hook)
+	}
+}
+
 func main() {
 	actionConfig := new(action.Configuration)
 	cmd := newRootCmd(actionConfig, os.Stdin, os.Stdout, os.Args[1:])
@@ -66,7 +122,7 @@ func main() {
 	})
 
 	if err := cmd.Execute(); err != nil {
-		os.Exit(1)
+		os.Exit(getExitCode(err))
 	}
 }
 
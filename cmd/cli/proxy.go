@@ -20,6 +20,18 @@ func newProxyCmd(config *action.Configuration, out io.Writer) *cobra.Command {
 		Args:  cobra.NoArgs,
 	}
 	cmd.AddCommand(newProxyGetCmd(config, out))
+	cmd.AddCommand(newProxyGetCertCmd(config, out))
+	cmd.AddCommand(newProxyGetConfigDumpCmd(config, out))
+	cmd.AddCommand(newProxyDiffConfigCmd(config, out))
+	cmd.AddCommand(newProxyDumpAllCmd(config, out))
+	cmd.AddCommand(newProxyGetEndpointsCmd(config, out))
+	cmd.AddCommand(newProxyGetStatsCmd(config, out))
+	cmd.AddCommand(newProxyResetCountersCmd(config, out))
+	cmd.AddCommand(newProxyRotateBootstrapCmd(config, out))
+	cmd.AddCommand(newProxySetLogLevelCmd(config, out))
+	cmd.AddCommand(newProxyVerifyIdentityCmd(config, out))
+	cmd.AddCommand(newProxyListCmd(out))
+	cmd.AddCommand(newProxyTopCmd(out))
 
 	return cmd
 }
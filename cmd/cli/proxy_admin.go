@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/pflag"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+	k8s "github.com/openservicemesh/osm/pkg/kubernetes"
+)
+
+// defaultProxyAdminTimeout is the default time to wait for the port forwarding to the Envoy admin port and for the
+// response of the proxy to an admin request
+const defaultProxyAdminTimeout = 15 * time.Second
+
+// getRunningMeshedPod returns the given pod if it is a part of a mesh and is running
+func getRunningMeshedPod(clientSet kubernetes.Interface, namespace, podName string) (*corev1.Pod, error) {
+	pod, err := clientSet.CoreV1().Pods(namespace).Get(context.TODO(), podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, annotateErrMsgWithPodNamespaceMsg("Could not find pod %s in namespace %s", podName, namespace)
+	}
+	if !isMeshedPod(*pod) {
+		return nil, annotateErrMsgWithPodNamespaceMsg("Pod %s in namespace %s is not a part of a mesh", podName, namespace)
+	}
+	if pod.Status.Phase != corev1.PodRunning {
+		return nil, annotateErrMsgWithPodNamespaceMsg("Pod %s in namespace %s is not running", podName, namespace)
+	}
+	return pod, nil
+}
+
+// addProxyAdminTimeoutFlag adds the flag with the given name bounding the time to set up the port forwarding to the
+// Envoy admin port and to get the response of the admin request, shared by the commands making proxy admin requests
+func addProxyAdminTimeoutFlag(f *pflag.FlagSet, timeout *time.Duration, name string) {
+	f.DurationVar(timeout, name, defaultProxyAdminTimeout, "Time to wait for the port forwarding to the Envoy admin port and for the response of the proxy, 0 to wait indefinitely")
+}
+
+// proxyAdminRequest forwards 'localPort' to the Envoy admin port of the given pod and returns the body of the
+// response to the admin request made with the given HTTP method and query. Both the port forwarding setup and the
+// request are bounded by the given timeout.
+func proxyAdminRequest(config *rest.Config, clientSet kubernetes.Interface, namespace, podName string, localPort uint16, timeout time.Duration, method, query string) ([]byte, error) {
+	dialer, err := k8s.DialerToPod(config, clientSet, podName, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	portForwarder, err := k8s.NewPortForwarder(dialer, fmt.Sprintf("%d:%d", localPort, constants.EnvoyAdminPort))
+	if err != nil {
+		return nil, errors.Errorf("Error setting up port forwarding: %s", err)
+	}
+
+	var body []byte
+	err = portForwarder.StartWithTimeout(timeout, func(pf *k8s.PortForwarder) error {
+		defer pf.Stop()
+		body, err = doProxyAdminRequest(fmt.Sprintf("http://localhost:%d/%s", localPort, query), method, timeout)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return body, nil
+}
+
+// doProxyAdminRequest returns the body of the response to the admin request made with the given HTTP method to the
+// given url, failing if the response is not received within the given timeout
+func doProxyAdminRequest(url, method string, timeout time.Duration) ([]byte, error) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return nil, errors.Errorf("Error creating request for url %s: %s", url, err)
+	}
+
+	client := &http.Client{Timeout: timeout}
+	resp, err := client.Do(req)
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return nil, errors.Errorf("Timed out after %s waiting for the response of the proxy to url %s", timeout, url)
+	}
+	if err != nil {
+		return nil, errors.Errorf("Error fetching url %s: %s", url, err)
+	}
+	defer resp.Body.Close() //nolint: errcheck
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+		return nil, errors.Errorf("Timed out after %s waiting for the response of the proxy to url %s", timeout, url)
+	}
+	if err != nil {
+		return nil, errors.Errorf("Error reading response from url %s: %s", url, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("Error fetching url %s: %s: %s", url, resp.Status, string(body))
+	}
+	return body, nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	tassert "github.com/stretchr/testify/assert"
+)
+
+func TestDoProxyAdminRequest(t *testing.T) {
+	unblock := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/config_dump":
+			_, _ = w.Write([]byte("config"))
+		case "/hang":
+			// A wedged proxy never responds
+			<-unblock
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	// Unblock the pending requests before closing the server, which waits for them to complete
+	defer close(unblock)
+
+	testCases := []struct {
+		name         string
+		query        string
+		timeout      time.Duration
+		expectedBody string
+		expectedErr  string
+	}{
+		{
+			name:         "response received",
+			query:        "config_dump",
+			timeout:      defaultProxyAdminTimeout,
+			expectedBody: "config",
+		},
+		{
+			name:        "error response",
+			query:       "unknown",
+			timeout:     defaultProxyAdminTimeout,
+			expectedErr: "404 Not Found",
+		},
+		{
+			name:        "proxy never responds",
+			query:       "hang",
+			timeout:     50 * time.Millisecond,
+			expectedErr: fmt.Sprintf("Timed out after 50ms waiting for the response of the proxy to url %s/hang", server.URL),
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			body, err := doProxyAdminRequest(fmt.Sprintf("%s/%s", server.URL, tc.query), http.MethodGet, tc.timeout)
+			if tc.expectedErr != "" {
+				assert.NotNil(err)
+				assert.Contains(err.Error(), tc.expectedErr)
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(tc.expectedBody, string(body))
+		})
+	}
+}
@@ -0,0 +1,225 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/action"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+const diffConfigCmdDescription = `
+This command compares the configurations of the Envoy proxy sidecars of two
+meshed pods, e.g. a misbehaving replica and a healthy replica of the same
+workload, and prints a unified diff of the differences.
+
+The pods are given as <namespace/pod>, or as <pod> for the default namespace.
+The xDS resources of the selected types are retrieved from the /config_dump
+endpoint of the Envoy admin interface of each proxy, and normalized before
+being compared, so that only semantic differences are printed:
+  - fields that change with every update of a resource, such as the time of
+    the last update and the version of the xDS resources, are removed
+  - lists are sorted, so the order in which the resources are listed by the
+    proxies is ignored
+  - object keys are sorted
+
+The types of xDS resources compared are selected with --type, among:
+listeners, routes, clusters, endpoints.
+`
+
+const diffConfigCmdExample = `
+# Compare the configs of the proxies of pods 'bookstore-v1-5ccf77f46d-rc5mg' and 'bookstore-v1-5ccf77f46d-xk2lp' in the 'bookstore' namespace
+osm proxy diff-config bookstore/bookstore-v1-5ccf77f46d-rc5mg bookstore/bookstore-v1-5ccf77f46d-xk2lp
+
+# Compare the endpoints known to the proxies of both pods
+osm proxy diff-config bookstore/bookstore-v1-5ccf77f46d-rc5mg bookstore/bookstore-v1-5ccf77f46d-xk2lp --type endpoints
+`
+
+// defaultDiffConfigTypes are the types of xDS resources compared by default. Endpoints are left out as they differ as
+// soon as the health of an endpoint is seen differently by the proxies.
+var defaultDiffConfigTypes = []string{"listeners", "routes", "clusters"}
+
+// volatileConfigDumpFields are the fields of a config dump changing with every update of a resource, regardless of its
+// config, which are removed before comparing config dumps
+var volatileConfigDumpFields = map[string]bool{
+	"last_updated":        true,
+	"version_info":        true,
+	"last_update_attempt": true,
+}
+
+type proxyDiffConfigCmd struct {
+	out       io.Writer
+	config    *rest.Config
+	clientSet kubernetes.Interface
+	pods      [2]string
+	types     []string
+	localPort uint16
+	timeout   time.Duration
+}
+
+func newProxyDiffConfigCmd(config *action.Configuration, out io.Writer) *cobra.Command {
+	diffConfigCmd := &proxyDiffConfigCmd{
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "diff-config POD_A POD_B",
+		Short: "compare the configs of the proxies of two pods",
+		Long:  diffConfigCmdDescription,
+		Args:  cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			diffConfigCmd.pods = [2]string{args[0], args[1]}
+			conf, err := config.RESTClientGetter.ToRESTConfig()
+			if err != nil {
+				return errors.Errorf("Error fetching kubeconfig: %s", err)
+			}
+			diffConfigCmd.config = conf
+
+			clientset, err := kubernetes.NewForConfig(conf)
+			if err != nil {
+				return errors.Errorf("Could not access Kubernetes cluster, check kubeconfig: %s", err)
+			}
+			diffConfigCmd.clientSet = clientset
+			return diffConfigCmd.run()
+		},
+		Example: diffConfigCmdExample,
+	}
+
+	f := cmd.Flags()
+	f.StringSliceVar(&diffConfigCmd.types, "type", defaultDiffConfigTypes, fmt.Sprintf("Types of xDS resources to compare, any of: %s", strings.Join(configDumpTypes, ", ")))
+	f.Uint16VarP(&diffConfigCmd.localPort, "local-port", "p", constants.EnvoyAdminPort, "Local port to use for port forwarding")
+	addProxyAdminTimeoutFlag(f, &diffConfigCmd.timeout, "timeout")
+
+	return cmd
+}
+
+func (cmd *proxyDiffConfigCmd) run() error {
+	types, err := parseConfigDumpTypes(cmd.types)
+	if err != nil {
+		return err
+	}
+	if len(types) == 0 {
+		return errors.Errorf("Flag --type must select at least one of: %s", strings.Join(configDumpTypes, ", "))
+	}
+
+	var configDumps [2][]byte
+	for i, namespacedPod := range cmd.pods {
+		namespace, podName, err := unmarshalNamespacedPod(namespacedPod)
+		if err != nil {
+			return err
+		}
+		if _, err := getRunningMeshedPod(cmd.clientSet, namespace, podName); err != nil {
+			return err
+		}
+
+		getQuery := func(query string) ([]byte, error) {
+			return proxyAdminRequest(cmd.config, cmd.clientSet, namespace, podName, cmd.localPort, cmd.timeout, http.MethodGet, query)
+		}
+		configDumps[i], err = getConfigDumpByType(types, getQuery)
+		if err != nil {
+			return annotateErrMsgWithPodNamespaceMsg("Error retrieving proxy config dump for pod %s in namespace %s: %s", podName, namespace, err)
+		}
+	}
+
+	diff, err := diffConfigDumps(configDumps[0], configDumps[1], cmd.pods[0], cmd.pods[1])
+	if err != nil {
+		return err
+	}
+	if diff == "" {
+		fmt.Fprintf(cmd.out, "The %s configs of the proxies of pods %s and %s are identical\n", strings.Join(types, ", "), cmd.pods[0], cmd.pods[1])
+		return nil
+	}
+	fmt.Fprint(cmd.out, diff)
+	return nil
+}
+
+// diffConfigDumps returns the unified diff of the given config dumps once normalized, labeled with the given names,
+// or an empty string when the normalized config dumps are identical
+func diffConfigDumps(configDumpA, configDumpB []byte, nameA, nameB string) (string, error) {
+	normalizedA, err := normalizeConfigDump(configDumpA)
+	if err != nil {
+		return "", errors.Errorf("Error normalizing config dump of %s: %s", nameA, err)
+	}
+	normalizedB, err := normalizeConfigDump(configDumpB)
+	if err != nil {
+		return "", errors.Errorf("Error normalizing config dump of %s: %s", nameB, err)
+	}
+	if normalizedA == normalizedB {
+		return "", nil
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(normalizedA),
+		B:        difflib.SplitLines(normalizedB),
+		FromFile: nameA,
+		ToFile:   nameB,
+		Context:  3,
+	})
+}
+
+// normalizeConfigDump returns the given JSON config dump pretty-printed with its volatile fields removed and its
+// lists sorted, object keys being sorted when marshaling
+func normalizeConfigDump(configDump []byte) (string, error) {
+	var config interface{}
+	if err := json.Unmarshal(configDump, &config); err != nil {
+		return "", errors.Errorf("Error unmarshaling config dump: %s", err)
+	}
+
+	normalized, err := json.MarshalIndent(normalizeConfigValue(config), "", "  ")
+	if err != nil {
+		return "", errors.Errorf("Error marshaling config dump: %s", err)
+	}
+	return string(normalized) + "\n", nil
+}
+
+// normalizeConfigValue returns the given unmarshaled JSON value with the volatile fields of its objects removed and
+// its lists sorted by the JSON representation of their normalized elements, recursively
+func normalizeConfigValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		normalized := make(map[string]interface{}, len(v))
+		for key, field := range v {
+			if volatileConfigDumpFields[key] {
+				continue
+			}
+			normalized[key] = normalizeConfigValue(field)
+		}
+		return normalized
+
+	case []interface{}:
+		type sortableElement struct {
+			value interface{}
+			key   string
+		}
+		elements := make([]sortableElement, 0, len(v))
+		for _, element := range v {
+			normalized := normalizeConfigValue(element)
+			// Marshaling values unmarshaled from JSON can't fail, and maps are marshaled with sorted keys
+			key, _ := json.Marshal(normalized)
+			elements = append(elements, sortableElement{value: normalized, key: string(key)})
+		}
+		sort.SliceStable(elements, func(i, j int) bool {
+			return elements[i].key < elements[j].key
+		})
+
+		normalized := make([]interface{}, 0, len(elements))
+		for _, element := range elements {
+			normalized = append(normalized, element.value)
+		}
+		return normalized
+
+	default:
+		return v
+	}
+}
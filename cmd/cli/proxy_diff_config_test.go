@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+)
+
+func TestNormalizeConfigDump(t *testing.T) {
+	testCases := []struct {
+		name       string
+		configDump string
+		expected   string
+		expectErr  bool
+	}{
+		{
+			name:       "volatile fields are removed",
+			configDump: `{"clusters": [{"version_info": "3", "last_updated": "2021-01-01T00:00:00Z", "cluster": {"name": "bookstore/bookstore"}}]}`,
+			expected: `{
+  "clusters": [
+    {
+      "cluster": {
+        "name": "bookstore/bookstore"
+      }
+    }
+  ]
+}
+`,
+			expectErr: false,
+		},
+		{
+			name:       "lists are sorted and object keys are sorted",
+			configDump: `{"routes": [{"name": "rds-outbound", "domains": ["b", "a"]}, {"domains": ["c"], "name": "rds-inbound"}]}`,
+			expected: `{
+  "routes": [
+    {
+      "domains": [
+        "a",
+        "b"
+      ],
+      "name": "rds-outbound"
+    },
+    {
+      "domains": [
+        "c"
+      ],
+      "name": "rds-inbound"
+    }
+  ]
+}
+`,
+			expectErr: false,
+		},
+		{
+			name:       "invalid config dump",
+			configDump: `{"clusters": [`,
+			expected:   "",
+			expectErr:  true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			normalized, err := normalizeConfigDump([]byte(tc.configDump))
+			assert.Equal(tc.expectErr, err != nil)
+			assert.Equal(tc.expected, normalized)
+		})
+	}
+}
+
+func TestDiffConfigDumps(t *testing.T) {
+	testCases := []struct {
+		name              string
+		configDumpA       string
+		configDumpB       string
+		expectedDiffLines []string
+		expectErr         bool
+	}{
+		{
+			name:              "config dumps differing by volatile fields and order only",
+			configDumpA:       `{"clusters": [{"version_info": "1", "cluster": {"name": "a"}}, {"version_info": "1", "cluster": {"name": "b"}}]}`,
+			configDumpB:       `{"clusters": [{"version_info": "7", "cluster": {"name": "b"}}, {"version_info": "7", "cluster": {"name": "a"}}]}`,
+			expectedDiffLines: nil,
+			expectErr:         false,
+		},
+		{
+			name:        "config dumps differing by a cluster",
+			configDumpA: `{"clusters": [{"cluster": {"name": "a"}}, {"cluster": {"name": "b"}}]}`,
+			configDumpB: `{"clusters": [{"cluster": {"name": "a"}}]}`,
+			expectedDiffLines: []string{
+				"--- bookstore/pod-a",
+				"+++ bookstore/pod-b",
+				`-        "name": "b"`,
+			},
+			expectErr: false,
+		},
+		{
+			name:              "invalid config dump",
+			configDumpA:       `{}`,
+			configDumpB:       `not json`,
+			expectedDiffLines: nil,
+			expectErr:         true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			diff, err := diffConfigDumps([]byte(tc.configDumpA), []byte(tc.configDumpB), "bookstore/pod-a", "bookstore/pod-b")
+			assert.Equal(tc.expectErr, err != nil)
+			if tc.expectedDiffLines == nil {
+				assert.Empty(diff)
+			}
+			for _, line := range tc.expectedDiffLines {
+				assert.Contains(diff, line)
+			}
+		})
+	}
+}
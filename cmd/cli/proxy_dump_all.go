@@ -0,0 +1,210 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/action"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+const dumpAllCmdDescription = `
+This command writes the config dump of the Envoy proxy sidecar of every
+running meshed pod to a directory, e.g. to capture a snapshot of the whole
+mesh during an incident and analyze it offline.
+
+The proxies of the monitored namespaces are dumped, or the proxies of the
+namespace given with --namespace. The config dumps are fetched from the
+/config_dump endpoint of the Envoy admin interface of up to --concurrency
+proxies at a time, and written to one file per pod:
+  DIR/NAMESPACE/POD_PROXY-UUID.json
+
+Each concurrent fetch forwards its own local port, from --local-port to
+--local-port + --concurrency - 1, which must be free. Private keys, passwords
+and other secrets present in the config dumps are redacted.
+
+A proxy whose config dump can't be fetched or written does not stop the dump
+of the other proxies: the failures are reported once every proxy is dumped.
+`
+
+const dumpAllCmdExample = `
+# Write the config dumps of the proxies of all the monitored namespaces to the directory 'mesh-snapshot'
+osm proxy dump-all --out mesh-snapshot
+
+# Write the config dumps of the proxies of the 'bookstore' namespace, fetching 16 config dumps at a time
+osm proxy dump-all --out mesh-snapshot -n bookstore --concurrency 16
+`
+
+// defaultDumpAllConcurrency is the default number of config dumps fetched concurrently
+const defaultDumpAllConcurrency = 8
+
+type proxyDumpAllCmd struct {
+	out         io.Writer
+	config      *rest.Config
+	clientSet   kubernetes.Interface
+	namespace   string
+	outDir      string
+	concurrency int
+	localPort   uint16
+	timeout     time.Duration
+
+	// getConfigDump returns the config dump of the proxy of the given pod, forwarding the given local port
+	getConfigDump func(pod *corev1.Pod, localPort uint16) ([]byte, error)
+}
+
+// proxyDumpResult is the outcome of the dump of the config of the proxy of a pod
+type proxyDumpResult struct {
+	path string
+	err  error
+}
+
+func newProxyDumpAllCmd(config *action.Configuration, out io.Writer) *cobra.Command {
+	dumpAllCmd := &proxyDumpAllCmd{
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "dump-all --out DIR",
+		Short: "write the config dumps of all the proxies to a directory",
+		Long:  dumpAllCmdDescription,
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if dumpAllCmd.outDir == "" {
+				return errors.New("flag --out is required")
+			}
+			conf, err := config.RESTClientGetter.ToRESTConfig()
+			if err != nil {
+				return errors.Errorf("Error fetching kubeconfig: %s", err)
+			}
+			dumpAllCmd.config = conf
+
+			clientset, err := kubernetes.NewForConfig(conf)
+			if err != nil {
+				return errors.Errorf("Could not access Kubernetes cluster, check kubeconfig: %s", err)
+			}
+			dumpAllCmd.clientSet = clientset
+			dumpAllCmd.getConfigDump = func(pod *corev1.Pod, localPort uint16) ([]byte, error) {
+				return proxyAdminRequest(dumpAllCmd.config, dumpAllCmd.clientSet, pod.Namespace, pod.Name, localPort, dumpAllCmd.timeout, http.MethodGet, configDumpQuery)
+			}
+			return dumpAllCmd.run()
+		},
+		Example: dumpAllCmdExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&dumpAllCmd.outDir, "out", "o", "", "Directory the config dumps are written to, created if it does not exist")
+	f.StringVarP(&dumpAllCmd.namespace, "namespace", "n", "", "Namespace of the pods whose proxy config is dumped, all the monitored namespaces if unset")
+	f.IntVar(&dumpAllCmd.concurrency, "concurrency", defaultDumpAllConcurrency, "Number of config dumps fetched concurrently")
+	f.Uint16VarP(&dumpAllCmd.localPort, "local-port", "p", constants.EnvoyAdminPort, "First local port to use for port forwarding, each concurrent fetch using the next port")
+	addProxyAdminTimeoutFlag(f, &dumpAllCmd.timeout, "timeout")
+
+	return cmd
+}
+
+func (cmd *proxyDumpAllCmd) run() error {
+	if cmd.concurrency < 1 {
+		return errors.Errorf("Invalid value %d for flag --concurrency, must be at least 1", cmd.concurrency)
+	}
+	if int(cmd.localPort)+cmd.concurrency-1 > math.MaxUint16 {
+		return errors.Errorf("Invalid value %d for flag --local-port, the %d local ports from it must be valid ports", cmd.localPort, cmd.concurrency)
+	}
+
+	meshedPods, err := listMeshedPods(cmd.clientSet, cmd.namespace)
+	if err != nil {
+		return err
+	}
+
+	var pods []corev1.Pod
+	for _, pod := range meshedPods {
+		if pod.Status.Phase != corev1.PodRunning {
+			fmt.Fprintf(cmd.out, "Skipping pod %s/%s, which is not running\n", pod.Namespace, pod.Name)
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	if len(pods) == 0 {
+		fmt.Fprintln(cmd.out, "No running meshed pods found")
+		return nil
+	}
+
+	results := cmd.dumpProxies(pods)
+
+	var dumped int
+	var failed []string
+	for i, result := range results {
+		if result.err != nil {
+			failed = append(failed, fmt.Sprintf("%s/%s: %s", pods[i].Namespace, pods[i].Name, result.err))
+			continue
+		}
+		fmt.Fprintf(cmd.out, "Wrote the config dump of the proxy for pod %s/%s to %s\n", pods[i].Namespace, pods[i].Name, result.path)
+		dumped++
+	}
+	fmt.Fprintf(cmd.out, "Dumped the config of %d of %d proxies to %s\n", dumped, len(pods), cmd.outDir)
+
+	if len(failed) > 0 {
+		return errors.Errorf("Error dumping the config of %d proxies:\n%s", len(failed), strings.Join(failed, "\n"))
+	}
+	return nil
+}
+
+// dumpProxies dumps the config of the proxies of the given pods with --concurrency workers, each forwarding its own
+// local port, and returns their results in the order of the pods
+func (cmd *proxyDumpAllCmd) dumpProxies(pods []corev1.Pod) []proxyDumpResult {
+	results := make([]proxyDumpResult, len(pods))
+	podIndexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < cmd.concurrency && worker < len(pods); worker++ {
+		wg.Add(1)
+		localPort := cmd.localPort + uint16(worker)
+		go func() {
+			defer wg.Done()
+			for i := range podIndexes {
+				results[i] = cmd.dumpProxy(&pods[i], localPort)
+			}
+		}()
+	}
+	for i := range pods {
+		podIndexes <- i
+	}
+	close(podIndexes)
+	wg.Wait()
+
+	return results
+}
+
+// dumpProxy writes the redacted config dump of the proxy of the given pod to its file in the output directory
+func (cmd *proxyDumpAllCmd) dumpProxy(pod *corev1.Pod, localPort uint16) proxyDumpResult {
+	configDump, err := cmd.getConfigDump(pod, localPort)
+	if err != nil {
+		return proxyDumpResult{err: err}
+	}
+	redacted, err := redactConfigDump(configDump)
+	if err != nil {
+		return proxyDumpResult{err: err}
+	}
+
+	dir := filepath.Join(cmd.outDir, pod.Namespace)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return proxyDumpResult{err: errors.Errorf("Error creating directory %s: %s", dir, err)}
+	}
+	// Pod names can't contain underscores, so the name of the file can't be mistaken for the name of another pod
+	path := filepath.Join(dir, fmt.Sprintf("%s_%s.json", pod.Name, pod.Labels[constants.EnvoyUniqueIDLabelName]))
+	if err := ioutil.WriteFile(path, redacted, 0600); err != nil {
+		return proxyDumpResult{err: errors.Errorf("Error writing file %s: %s", path, err)}
+	}
+	return proxyDumpResult{path: path}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/pkg/errors"
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestProxyDumpAll(t *testing.T) {
+	pendingPod := newTestPod("bookstore", "bookstore-v2", "", true)
+	pendingPod.Status.Phase = corev1.PodPending
+	objects := []runtime.Object{
+		newNamespace("bookstore", nil),
+		newNamespace("bookbuyer", nil),
+		newTestPod("bookstore", "bookstore-v1", "", true),
+		pendingPod,
+		newTestPod("bookstore", "bookstore-v3", "", true),
+		newTestPod("bookstore", "unmeshed", "", false),
+		newTestPod("bookbuyer", "bookbuyer", "", true),
+	}
+
+	testCases := []struct {
+		name              string
+		namespace         string
+		concurrency       int
+		localPort         uint16
+		failingPods       map[string]bool
+		expectedFiles     []string
+		expectedOutSubstr string
+		expectedErr       string
+	}{
+		{
+			name:              "proxies of all the monitored namespaces are dumped",
+			concurrency:       2,
+			localPort:         15000,
+			expectedFiles:     []string{"bookbuyer/bookbuyer_bookbuyer-uuid.json", "bookstore/bookstore-v1_bookstore-v1-uuid.json", "bookstore/bookstore-v3_bookstore-v3-uuid.json"},
+			expectedOutSubstr: "Dumped the config of 3 of 3 proxies to ",
+		},
+		{
+			name:              "proxies of a namespace are dumped",
+			namespace:         "bookbuyer",
+			concurrency:       8,
+			localPort:         15000,
+			expectedFiles:     []string{"bookbuyer/bookbuyer_bookbuyer-uuid.json"},
+			expectedOutSubstr: "Dumped the config of 1 of 1 proxies to ",
+		},
+		{
+			name:              "pods that are not running are skipped",
+			namespace:         "bookstore",
+			concurrency:       1,
+			localPort:         15000,
+			expectedFiles:     []string{"bookstore/bookstore-v1_bookstore-v1-uuid.json", "bookstore/bookstore-v3_bookstore-v3-uuid.json"},
+			expectedOutSubstr: "Skipping pod bookstore/bookstore-v2, which is not running",
+		},
+		{
+			name:              "failure to dump a proxy does not stop the dump of the others",
+			concurrency:       2,
+			localPort:         15000,
+			failingPods:       map[string]bool{"bookstore/bookstore-v1": true},
+			expectedFiles:     []string{"bookbuyer/bookbuyer_bookbuyer-uuid.json", "bookstore/bookstore-v3_bookstore-v3-uuid.json"},
+			expectedOutSubstr: "Dumped the config of 2 of 3 proxies to ",
+			expectedErr:       "Error dumping the config of 1 proxies:\nbookstore/bookstore-v1: admin request failed",
+		},
+		{
+			name:        "invalid concurrency",
+			concurrency: 0,
+			localPort:   15000,
+			expectedErr: "Invalid value 0 for flag --concurrency, must be at least 1",
+		},
+		{
+			name:        "local ports overflowing",
+			concurrency: 2,
+			localPort:   65535,
+			expectedErr: "Invalid value 65535 for flag --local-port, the 2 local ports from it must be valid ports",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			dir, err := ioutil.TempDir(os.TempDir(), "osm-test")
+			assert.Nil(err)
+			defer os.RemoveAll(dir) //nolint: errcheck
+
+			var mu sync.Mutex
+			usedPorts := map[uint16]bool{}
+			out := new(bytes.Buffer)
+			cmd := &proxyDumpAllCmd{
+				out:         out,
+				clientSet:   fake.NewSimpleClientset(objects...),
+				namespace:   tc.namespace,
+				outDir:      dir,
+				concurrency: tc.concurrency,
+				localPort:   tc.localPort,
+				getConfigDump: func(pod *corev1.Pod, localPort uint16) ([]byte, error) {
+					mu.Lock()
+					usedPorts[localPort] = true
+					mu.Unlock()
+					if tc.failingPods[pod.Namespace+"/"+pod.Name] {
+						return nil, errors.New("admin request failed")
+					}
+					return []byte(fmt.Sprintf(`{"pod": %q, "private_key": "secret"}`, pod.Name)), nil
+				},
+			}
+
+			err = cmd.run()
+			if tc.expectedErr != "" {
+				assert.NotNil(err)
+				assert.Contains(err.Error(), tc.expectedErr)
+			} else {
+				assert.Nil(err)
+			}
+			assert.Contains(out.String(), tc.expectedOutSubstr)
+
+			var files []string
+			assert.Nil(filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+				if err != nil || info.IsDir() {
+					return err
+				}
+				rel, err := filepath.Rel(dir, path)
+				if err != nil {
+					return err
+				}
+				files = append(files, filepath.ToSlash(rel))
+
+				data, err := ioutil.ReadFile(path) //#nosec G304
+				if err != nil {
+					return err
+				}
+				assert.Contains(string(data), redactedValue)
+				assert.NotContains(string(data), "secret")
+				return nil
+			}))
+			assert.ElementsMatch(tc.expectedFiles, files)
+
+			for port := range usedPorts {
+				assert.True(port >= tc.localPort && int(port) < int(tc.localPort)+tc.concurrency)
+			}
+		})
+	}
+}
@@ -1,11 +1,10 @@
 package main
 
 import (
-	"context"
-	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -16,7 +15,6 @@ import (
 	"k8s.io/client-go/rest"
 
 	"github.com/openservicemesh/osm/pkg/constants"
-	k8s "github.com/openservicemesh/osm/pkg/kubernetes"
 )
 
 const getCmdDescription = `
@@ -42,6 +40,7 @@ type proxyGetCmd struct {
 	namespace  string
 	pod        string
 	localPort  uint16
+	timeout    time.Duration
 	outFile    string
 	sigintChan chan os.Signal
 }
@@ -81,62 +80,35 @@ func newProxyGetCmd(config *action.Configuration, out io.Writer) *cobra.Command
 	f.StringVarP(&getCmd.namespace, "namespace", "n", metav1.NamespaceDefault, "Namespace of pod")
 	f.StringVarP(&getCmd.outFile, "file", "f", "", "File to write output to")
 	f.Uint16VarP(&getCmd.localPort, "local-port", "p", constants.EnvoyAdminPort, "Local port to use for port forwarding")
+	addProxyAdminTimeoutFlag(f, &getCmd.timeout, "timeout")
 
 	return cmd
 }
 
 func (cmd *proxyGetCmd) run() error {
 	// Check if the pod belongs to a mesh
-	pod, err := cmd.clientSet.CoreV1().Pods(cmd.namespace).Get(context.TODO(), cmd.pod, metav1.GetOptions{})
-	if err != nil {
-		return annotateErrMsgWithPodNamespaceMsg("Could not find pod %s in namespace %s", cmd.pod, cmd.namespace)
-	}
-	if !isMeshedPod(*pod) {
-		return annotateErrMsgWithPodNamespaceMsg("Pod %s in namespace %s is not a part of a mesh", cmd.pod, cmd.namespace)
-	}
-	if pod.Status.Phase != corev1.PodRunning {
-		return annotateErrMsgWithPodNamespaceMsg("Pod %s in namespace %s is not running", cmd.pod, cmd.namespace)
-	}
-
-	dialer, err := k8s.DialerToPod(cmd.config, cmd.clientSet, cmd.pod, cmd.namespace)
-	if err != nil {
+	if _, err := getRunningMeshedPod(cmd.clientSet, cmd.namespace, cmd.pod); err != nil {
 		return err
 	}
 
-	portForwarder, err := k8s.NewPortForwarder(dialer, fmt.Sprintf("%d:%d", cmd.localPort, constants.EnvoyAdminPort))
+	body, err := proxyAdminRequest(cmd.config, cmd.clientSet, cmd.namespace, cmd.pod, cmd.localPort, cmd.timeout, http.MethodGet, cmd.query)
 	if err != nil {
-		return errors.Errorf("Error setting up port forwarding: %s", err)
+		return annotateErrMsgWithPodNamespaceMsg("Error retrieving proxy config for pod %s in namespace %s: %s", cmd.pod, cmd.namespace, err)
 	}
 
-	err = portForwarder.Start(func(pf *k8s.PortForwarder) error {
-		defer pf.Stop()
-		url := fmt.Sprintf("http://localhost:%d/%s", cmd.localPort, cmd.query)
-
-		// #nosec G107: Potential HTTP request made with variable url
-		resp, err := http.Get(url)
+	out := cmd.out // By default, output is written to stdout
+	if cmd.outFile != "" {
+		fd, err := os.Create(cmd.outFile)
 		if err != nil {
-			return errors.Errorf("Error fetching url %s: %s", url, err)
+			return errors.Errorf("Error opening file %s: %s", cmd.outFile, err)
 		}
-
-		out := cmd.out // By default, output is written to stdout
-		if cmd.outFile != "" {
-			fd, err := os.Create(cmd.outFile)
-			if err != nil {
-				return errors.Errorf("Error opening file %s: %s", cmd.outFile, err)
-			}
-			defer fd.Close() //nolint: errcheck, gosec
-			out = fd         // write output to file
-		}
-
-		if _, err := io.Copy(out, resp.Body); err != nil {
-			return errors.Errorf("Error rendering HTTP response: %s", err)
-		}
-		return nil
-	})
-	if err != nil {
-		return annotateErrMsgWithPodNamespaceMsg("Error retrieving proxy config for pod %s in namespace %s: %s", cmd.pod, cmd.namespace, err)
+		defer fd.Close() //nolint: errcheck, gosec
+		out = fd         // write output to file
 	}
 
+	if _, err := out.Write(body); err != nil {
+		return errors.Errorf("Error rendering HTTP response: %s", err)
+	}
 	return nil
 }
 
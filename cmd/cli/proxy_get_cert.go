@@ -0,0 +1,203 @@
+package main
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/action"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+const getCertCmdDescription = `
+This command will print the certificates served by the Envoy proxy sidecar
+of the given pod, along with their expiration time.
+
+The certificates are read from the secrets the proxy received via SDS, which
+are available on the Envoy admin interface. A warning is printed for every
+certificate expiring within the configured expiry window.
+`
+
+const getCertCmdExample = `
+# Get the certificates of the proxy for the given pod 'bookbuyer-5ccf77f46d-rc5mg' in the 'bookbuyer' namespace
+osm proxy get-cert bookbuyer-5ccf77f46d-rc5mg -n bookbuyer
+
+# Warn if a certificate of the proxy expires within the next 2 hours
+osm proxy get-cert bookbuyer-5ccf77f46d-rc5mg -n bookbuyer --expiry-window 2h
+`
+
+// secretsConfigDumpQuery is the Envoy admin query returning the secrets received via SDS
+const secretsConfigDumpQuery = "config_dump?resource=dynamic_active_secrets"
+
+type proxyGetCertCmd struct {
+	out          io.Writer
+	config       *rest.Config
+	clientSet    kubernetes.Interface
+	namespace    string
+	pod          string
+	localPort    uint16
+	timeout      time.Duration
+	expiryWindow time.Duration
+}
+
+// proxyCertificate is a certificate of the chain of a secret served by a proxy
+type proxyCertificate struct {
+	secretName  string
+	certificate *x509.Certificate
+}
+
+// secretsConfigDump is the subset of the Envoy SecretsConfigDump containing the certificate chains of the active secrets
+type secretsConfigDump struct {
+	Configs []struct {
+		DynamicActiveSecrets []struct {
+			Name   string `json:"name"`
+			Secret struct {
+				TLSCertificate *struct {
+					CertificateChain struct {
+						InlineBytes []byte `json:"inline_bytes"`
+					} `json:"certificate_chain"`
+				} `json:"tls_certificate"`
+			} `json:"secret"`
+		} `json:"dynamic_active_secrets"`
+	} `json:"configs"`
+}
+
+func newProxyGetCertCmd(config *action.Configuration, out io.Writer) *cobra.Command {
+	getCertCmd := &proxyGetCertCmd{
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "get-cert POD",
+		Short: "get the certificates served by a proxy",
+		Long:  getCertCmdDescription,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			getCertCmd.pod = args[0]
+			conf, err := config.RESTClientGetter.ToRESTConfig()
+			if err != nil {
+				return errors.Errorf("Error fetching kubeconfig: %s", err)
+			}
+			getCertCmd.config = conf
+
+			clientset, err := kubernetes.NewForConfig(conf)
+			if err != nil {
+				return errors.Errorf("Could not access Kubernetes cluster, check kubeconfig: %s", err)
+			}
+			getCertCmd.clientSet = clientset
+			return getCertCmd.run()
+		},
+		Example: getCertCmdExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&getCertCmd.namespace, "namespace", "n", metav1.NamespaceDefault, "Namespace of pod")
+	f.Uint16VarP(&getCertCmd.localPort, "local-port", "p", constants.EnvoyAdminPort, "Local port to use for port forwarding")
+	addProxyAdminTimeoutFlag(f, &getCertCmd.timeout, "timeout")
+	f.DurationVar(&getCertCmd.expiryWindow, "expiry-window", time.Hour, "Print a warning for certificates expiring within this duration")
+
+	return cmd
+}
+
+func (cmd *proxyGetCertCmd) run() error {
+	if _, err := getRunningMeshedPod(cmd.clientSet, cmd.namespace, cmd.pod); err != nil {
+		return err
+	}
+
+	configDump, err := proxyAdminRequest(cmd.config, cmd.clientSet, cmd.namespace, cmd.pod, cmd.localPort, cmd.timeout, http.MethodGet, secretsConfigDumpQuery)
+	if err != nil {
+		return annotateErrMsgWithPodNamespaceMsg("Error retrieving proxy certificates for pod %s in namespace %s: %s", cmd.pod, cmd.namespace, err)
+	}
+
+	certs, err := parseProxyCertificates(configDump)
+	if err != nil {
+		return err
+	}
+	if len(certs) == 0 {
+		return annotateErrMsgWithPodNamespaceMsg("No certificates found for the proxy of pod %s in namespace %s", cmd.pod, cmd.namespace)
+	}
+
+	printProxyCertificates(cmd.out, certs, time.Now(), cmd.expiryWindow)
+	return nil
+}
+
+// parseProxyCertificates returns the certificates of the chains of the secrets in the given Envoy secrets config dump
+func parseProxyCertificates(configDump []byte) ([]proxyCertificate, error) {
+	var dump secretsConfigDump
+	if err := json.Unmarshal(configDump, &dump); err != nil {
+		return nil, errors.Errorf("Error parsing proxy secrets config dump: %s", err)
+	}
+
+	var certs []proxyCertificate
+	for _, config := range dump.Configs {
+		for _, secret := range config.DynamicActiveSecrets {
+			if secret.Secret.TLSCertificate == nil {
+				// Not a certificate chain, e.g. a validation context
+				continue
+			}
+
+			chain := secret.Secret.TLSCertificate.CertificateChain.InlineBytes
+			for {
+				var block *pem.Block
+				block, chain = pem.Decode(chain)
+				if block == nil {
+					break
+				}
+				if block.Type != "CERTIFICATE" {
+					continue
+				}
+
+				cert, err := x509.ParseCertificate(block.Bytes)
+				if err != nil {
+					return nil, errors.Errorf("Error parsing certificate of secret %s: %s", secret.Name, err)
+				}
+				certs = append(certs, proxyCertificate{secretName: secret.Name, certificate: cert})
+			}
+		}
+	}
+	return certs, nil
+}
+
+// printProxyCertificates prints the details of the given certificates, warning about those expiring within 'expiryWindow' of 'now'
+func printProxyCertificates(out io.Writer, certs []proxyCertificate, now time.Time, expiryWindow time.Duration) {
+	for _, c := range certs {
+		cert := c.certificate
+		fmt.Fprintf(out, "Secret:      %s\n", c.secretName)
+		fmt.Fprintf(out, "Subject:     %s\n", cert.Subject)
+		fmt.Fprintf(out, "SAN:         %s\n", strings.Join(getSubjectAltNames(cert), ", "))
+		fmt.Fprintf(out, "Issuer:      %s\n", cert.Issuer)
+		fmt.Fprintf(out, "Valid until: %s\n", cert.NotAfter.UTC().Format(time.RFC3339))
+
+		if remaining := cert.NotAfter.Sub(now); remaining <= 0 {
+			fmt.Fprintf(out, "[!] Warning: certificate expired %s ago\n", (-remaining).Round(time.Second))
+		} else if remaining <= expiryWindow {
+			fmt.Fprintf(out, "[!] Warning: certificate expires in %s\n", remaining.Round(time.Second))
+		}
+		fmt.Fprintln(out)
+	}
+}
+
+// getSubjectAltNames returns the DNS, URI, IP and email subject alternative names of the given certificate
+func getSubjectAltNames(cert *x509.Certificate) []string {
+	var sans []string
+	sans = append(sans, cert.DNSNames...)
+	for _, uri := range cert.URIs {
+		sans = append(sans, uri.String())
+	}
+	for _, ip := range cert.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	sans = append(sans, cert.EmailAddresses...)
+	return sans
+}
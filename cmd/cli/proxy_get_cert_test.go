@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	tassert "github.com/stretchr/testify/assert"
+)
+
+func newTestCertificatePEM(t *testing.T, commonName string, notAfter time.Time) []byte {
+	return newTestCertificatePEMWithValidity(t, commonName, notAfter.Add(-24*time.Hour), notAfter)
+}
+
+func newTestCertificatePEMWithValidity(t *testing.T, commonName string, notBefore, notAfter time.Time) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName, Organization: []string{"Open Service Mesh"}},
+		DNSNames:     []string{commonName},
+		NotBefore:    notBefore,
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestParseAndPrintProxyCertificates(t *testing.T) {
+	assert := tassert.New(t)
+
+	now := time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC)
+	certPEM := newTestCertificatePEM(t, "bookstore.bookstore.cluster.local", now.Add(30*time.Minute))
+
+	// Envoy renders bytes fields base64 encoded, which encoding/json does for []byte
+	configDump, err := json.Marshal(map[string]interface{}{
+		"configs": []interface{}{
+			map[string]interface{}{
+				"dynamic_active_secrets": []interface{}{
+					map[string]interface{}{
+						"name": "service-cert:bookstore/bookstore",
+						"secret": map[string]interface{}{
+							"tls_certificate": map[string]interface{}{
+								"certificate_chain": map[string]interface{}{"inline_bytes": certPEM},
+								"private_key":       map[string]interface{}{"inline_bytes": []byte("[redacted]")},
+							},
+						},
+					},
+					map[string]interface{}{
+						"name": "root-cert-for-mtls-inbound:bookstore/bookstore",
+						"secret": map[string]interface{}{
+							"validation_context": map[string]interface{}{
+								"trusted_ca": map[string]interface{}{"inline_bytes": certPEM},
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+	assert.Nil(err)
+
+	certs, err := parseProxyCertificates(configDump)
+	assert.Nil(err)
+	assert.Len(certs, 1)
+	assert.Equal("service-cert:bookstore/bookstore", certs[0].secretName)
+
+	out := new(bytes.Buffer)
+	printProxyCertificates(out, certs, now, time.Hour)
+	assert.Contains(out.String(), "Subject:     CN=bookstore.bookstore.cluster.local,O=Open Service Mesh")
+	assert.Contains(out.String(), "SAN:         bookstore.bookstore.cluster.local")
+	assert.Contains(out.String(), "Valid until: 2021-01-01T00:30:00Z")
+	assert.Contains(out.String(), "[!] Warning: certificate expires in 30m0s")
+
+	out.Reset()
+	printProxyCertificates(out, certs, now, 10*time.Minute)
+	assert.NotContains(out.String(), "Warning")
+
+	out.Reset()
+	printProxyCertificates(out, certs, now.Add(time.Hour), 10*time.Minute)
+	assert.Contains(out.String(), "[!] Warning: certificate expired 30m0s ago")
+
+	_, err = parseProxyCertificates([]byte("not json"))
+	assert.NotNil(err)
+}
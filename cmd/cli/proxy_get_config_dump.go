@@ -0,0 +1,208 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/action"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+const getConfigDumpCmdDescription = `
+This command will print the configuration of the Envoy proxy sidecar of the
+given pod, as returned by the /config_dump endpoint of the Envoy admin interface.
+
+The full config dump is printed unless the types of xDS resources to print are
+selected with --type, in which case only the static and dynamic resources of
+the selected types are requested from the proxy and printed as a JSON object
+keyed by type. Multiple types can be selected, among: listeners, routes,
+clusters, endpoints.
+`
+
+const getConfigDumpCmdExample = `
+# Get the full config dump of the proxy for the given pod 'bookbuyer-5ccf77f46d-rc5mg' in the 'bookbuyer' namespace
+osm proxy get-config-dump bookbuyer-5ccf77f46d-rc5mg -n bookbuyer
+
+# Get the listeners of the proxy
+osm proxy get-config-dump bookbuyer-5ccf77f46d-rc5mg -n bookbuyer --type listeners
+
+# Get the clusters and endpoints of the proxy and output to file 'clusters.json'
+osm proxy get-config-dump bookbuyer-5ccf77f46d-rc5mg -n bookbuyer --type clusters,endpoints -f clusters.json
+`
+
+// configDumpQuery is the Envoy admin query returning the full config dump of the proxy
+const configDumpQuery = "config_dump"
+
+// configDumpEndpointsType is the config dump type of the endpoints, whose resources are only dumped by Envoy when
+// explicitly requested
+const configDumpEndpointsType = "endpoints"
+
+// configDumpTypes is the list of xDS resource types selectable with --type, in the order they are listed in errors
+var configDumpTypes = []string{"listeners", "routes", "clusters", configDumpEndpointsType}
+
+// configDumpResources maps each xDS resource type selectable with --type to the config dump resources returned for it
+var configDumpResources = map[string][]string{
+	"listeners":             {"static_listeners", "dynamic_listeners"},
+	"routes":                {"static_route_configs", "dynamic_route_configs"},
+	"clusters":              {"static_clusters", "dynamic_active_clusters"},
+	configDumpEndpointsType: {"static_endpoint_configs", "dynamic_endpoint_configs"},
+}
+
+type proxyGetConfigDumpCmd struct {
+	out       io.Writer
+	config    *rest.Config
+	clientSet kubernetes.Interface
+	namespace string
+	pod       string
+	types     []string
+	outFile   string
+	localPort uint16
+	timeout   time.Duration
+}
+
+func newProxyGetConfigDumpCmd(config *action.Configuration, out io.Writer) *cobra.Command {
+	getConfigDumpCmd := &proxyGetConfigDumpCmd{
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "get-config-dump POD",
+		Short: "get the config dump of a proxy",
+		Long:  getConfigDumpCmdDescription,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			getConfigDumpCmd.pod = args[0]
+			conf, err := config.RESTClientGetter.ToRESTConfig()
+			if err != nil {
+				return errors.Errorf("Error fetching kubeconfig: %s", err)
+			}
+			getConfigDumpCmd.config = conf
+
+			clientset, err := kubernetes.NewForConfig(conf)
+			if err != nil {
+				return errors.Errorf("Could not access Kubernetes cluster, check kubeconfig: %s", err)
+			}
+			getConfigDumpCmd.clientSet = clientset
+			return getConfigDumpCmd.run()
+		},
+		Example: getConfigDumpCmdExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&getConfigDumpCmd.namespace, "namespace", "n", metav1.NamespaceDefault, "Namespace of pod")
+	f.StringSliceVar(&getConfigDumpCmd.types, "type", nil, fmt.Sprintf("Types of xDS resources to print, any of: %s. The full config dump is printed if unset", strings.Join(configDumpTypes, ", ")))
+	f.StringVarP(&getConfigDumpCmd.outFile, "file", "f", "", "File to write output to")
+	f.Uint16VarP(&getConfigDumpCmd.localPort, "local-port", "p", constants.EnvoyAdminPort, "Local port to use for port forwarding")
+	addProxyAdminTimeoutFlag(f, &getConfigDumpCmd.timeout, "timeout")
+
+	return cmd
+}
+
+func (cmd *proxyGetConfigDumpCmd) run() error {
+	types, err := parseConfigDumpTypes(cmd.types)
+	if err != nil {
+		return err
+	}
+
+	if _, err := getRunningMeshedPod(cmd.clientSet, cmd.namespace, cmd.pod); err != nil {
+		return err
+	}
+
+	getQuery := func(query string) ([]byte, error) {
+		return proxyAdminRequest(cmd.config, cmd.clientSet, cmd.namespace, cmd.pod, cmd.localPort, cmd.timeout, http.MethodGet, query)
+	}
+
+	var configDump []byte
+	if len(types) == 0 {
+		configDump, err = getQuery(configDumpQuery)
+	} else {
+		configDump, err = getConfigDumpByType(types, getQuery)
+	}
+	if err != nil {
+		return annotateErrMsgWithPodNamespaceMsg("Error retrieving proxy config dump for pod %s in namespace %s: %s", cmd.pod, cmd.namespace, err)
+	}
+
+	out := cmd.out // By default, output is written to stdout
+	if cmd.outFile != "" {
+		fd, err := os.Create(cmd.outFile)
+		if err != nil {
+			return errors.Errorf("Error opening file %s: %s", cmd.outFile, err)
+		}
+		defer fd.Close() //nolint: errcheck, gosec
+		out = fd         // write output to file
+	}
+
+	if _, err := out.Write(configDump); err != nil {
+		return errors.Errorf("Error rendering config dump: %s", err)
+	}
+	return nil
+}
+
+// parseConfigDumpTypes returns the xDS resource types selected with --type, listed once in the order they are selected
+func parseConfigDumpTypes(selectedTypes []string) ([]string, error) {
+	var types []string
+	seen := make(map[string]bool)
+	for _, selected := range selectedTypes {
+		configDumpType := strings.ToLower(strings.TrimSpace(selected))
+		if _, ok := configDumpResources[configDumpType]; !ok {
+			return nil, errors.Errorf("Invalid type %q for flag --type, expected any of: %s", selected, strings.Join(configDumpTypes, ", "))
+		}
+		if seen[configDumpType] {
+			continue
+		}
+		seen[configDumpType] = true
+		types = append(types, configDumpType)
+	}
+	return types, nil
+}
+
+// getConfigDumpResourceQuery returns the Envoy admin query returning the config dump of the given resource
+func getConfigDumpResourceQuery(configDumpType, resource string) string {
+	query := fmt.Sprintf("%s?resource=%s", configDumpQuery, resource)
+	if configDumpType == configDumpEndpointsType {
+		// Endpoints are only part of the config dump when explicitly included
+		query += "&include_eds"
+	}
+	return query
+}
+
+// getConfigDumpByType returns the pretty-printed JSON object holding the configs of each of the given xDS resource
+// types, each resource being requested from the proxy with the given function
+func getConfigDumpByType(types []string, getQuery func(query string) ([]byte, error)) ([]byte, error) {
+	configsByType := make(map[string][]json.RawMessage)
+	for _, configDumpType := range types {
+		// Types without any resource are printed as an empty list
+		configsByType[configDumpType] = []json.RawMessage{}
+		for _, resource := range configDumpResources[configDumpType] {
+			body, err := getQuery(getConfigDumpResourceQuery(configDumpType, resource))
+			if err != nil {
+				return nil, err
+			}
+
+			var resourceDump struct {
+				Configs []json.RawMessage `json:"configs"`
+			}
+			if err := json.Unmarshal(body, &resourceDump); err != nil {
+				return nil, errors.Errorf("Error unmarshaling config dump of resource %s: %s", resource, err)
+			}
+			configsByType[configDumpType] = append(configsByType[configDumpType], resourceDump.Configs...)
+		}
+	}
+
+	configDump, err := json.MarshalIndent(configsByType, "", "  ")
+	if err != nil {
+		return nil, errors.Errorf("Error marshaling config dump: %s", err)
+	}
+	return append(configDump, '\n'), nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	tassert "github.com/stretchr/testify/assert"
+)
+
+func TestParseConfigDumpTypes(t *testing.T) {
+	testCases := []struct {
+		name          string
+		selectedTypes []string
+		expected      []string
+		expectErr     bool
+	}{
+		{
+			name:          "no type selected",
+			selectedTypes: nil,
+			expected:      nil,
+			expectErr:     false,
+		},
+		{
+			name:          "multiple types selected",
+			selectedTypes: []string{"clusters", " Endpoints", "listeners"},
+			expected:      []string{"clusters", "endpoints", "listeners"},
+			expectErr:     false,
+		},
+		{
+			name:          "type selected more than once",
+			selectedTypes: []string{"routes", "clusters", "routes"},
+			expected:      []string{"routes", "clusters"},
+			expectErr:     false,
+		},
+		{
+			name:          "invalid type",
+			selectedTypes: []string{"listeners", "secrets"},
+			expected:      nil,
+			expectErr:     true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			types, err := parseConfigDumpTypes(tc.selectedTypes)
+			assert.Equal(tc.expectErr, err != nil)
+			assert.Equal(tc.expected, types)
+		})
+	}
+}
+
+func TestGetConfigDumpByType(t *testing.T) {
+	testResourceDumps := map[string]string{
+		"config_dump?resource=static_listeners":                     `{}`,
+		"config_dump?resource=dynamic_listeners":                    `{"configs": [{"name": "outbound-listener"}, {"name": "inbound-listener"}]}`,
+		"config_dump?resource=static_clusters":                      `{"configs": [{"cluster": {"name": "osm-controller"}}]}`,
+		"config_dump?resource=dynamic_active_clusters":              `{"configs": [{"cluster": {"name": "bookstore/bookstore"}}]}`,
+		"config_dump?resource=static_endpoint_configs&include_eds":  `{}`,
+		"config_dump?resource=dynamic_endpoint_configs&include_eds": `{}`,
+	}
+
+	testCases := []struct {
+		name      string
+		types     []string
+		expected  string
+		expectErr bool
+	}{
+		{
+			name:  "listeners",
+			types: []string{"listeners"},
+			expected: `{
+  "listeners": [
+    {
+      "name": "outbound-listener"
+    },
+    {
+      "name": "inbound-listener"
+    }
+  ]
+}
+`,
+		},
+		{
+			name:  "static and dynamic clusters",
+			types: []string{"clusters"},
+			expected: `{
+  "clusters": [
+    {
+      "cluster": {
+        "name": "osm-controller"
+      }
+    },
+    {
+      "cluster": {
+        "name": "bookstore/bookstore"
+      }
+    }
+  ]
+}
+`,
+		},
+		{
+			name:  "multiple types without any endpoint",
+			types: []string{"endpoints", "listeners"},
+			expected: `{
+  "endpoints": [],
+  "listeners": [
+    {
+      "name": "outbound-listener"
+    },
+    {
+      "name": "inbound-listener"
+    }
+  ]
+}
+`,
+		},
+		{
+			name:      "error requesting the routes",
+			types:     []string{"routes"},
+			expectErr: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			getQuery := func(query string) ([]byte, error) {
+				resourceDump, ok := testResourceDumps[query]
+				if !ok {
+					return nil, errors.Errorf("unexpected query %s", query)
+				}
+				return []byte(resourceDump), nil
+			}
+
+			configDump, err := getConfigDumpByType(tc.types, getQuery)
+			assert.Equal(tc.expectErr, err != nil)
+			if !tc.expectErr {
+				assert.Equal(tc.expected, string(configDump))
+			}
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/action"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+	"github.com/openservicemesh/osm/pkg/service"
+)
+
+const getEndpointsCmdDescription = `
+This command will print the endpoints the Envoy proxy sidecar of the given pod
+currently resolves the given destination service to, along with their health
+status, as returned by the /clusters endpoint of the Envoy admin interface.
+
+This is the view of the proxy, which can differ from the endpoints of the
+service in Kubernetes, e.g. when the proxy has not received the latest
+configuration or when endpoints are ejected by outlier detection. It helps to
+debug why the pod cannot reach the service.
+
+The service is given as <namespace/service>, or as <service> for a service in
+the namespace of the pod.
+`
+
+const getEndpointsCmdExample = `
+# Get the endpoints of the 'bookstore' service in the 'bookstore' namespace as seen by the proxy of the pod 'bookbuyer-5ccf77f46d-rc5mg' in the 'bookbuyer' namespace
+osm proxy get-endpoints bookbuyer-5ccf77f46d-rc5mg bookstore/bookstore -n bookbuyer
+
+# Get the endpoints as JSON
+osm proxy get-endpoints bookbuyer-5ccf77f46d-rc5mg bookstore/bookstore -n bookbuyer -o json
+`
+
+// clustersQuery is the Envoy admin query returning the clusters of the proxy along with their endpoints
+const clustersQuery = "clusters?format=json"
+
+// proxyEndpointColumns are the columns of the table of endpoints of a cluster
+var proxyEndpointColumns = []tableColumn{
+	{name: "address", header: "ADDRESS"},
+	{name: "port", header: "PORT"},
+	{name: "health", header: "HEALTH"},
+	{name: "weight", header: "WEIGHT"},
+}
+
+type proxyGetEndpointsCmd struct {
+	out       io.Writer
+	config    *rest.Config
+	clientSet kubernetes.Interface
+	namespace string
+	pod       string
+	service   string
+	output    string
+	localPort uint16
+	timeout   time.Duration
+}
+
+// proxyEndpoint is an endpoint of a cluster of a proxy
+type proxyEndpoint struct {
+	Address string `json:"address"`
+	Port    uint32 `json:"port"`
+	Health  string `json:"health"`
+	Weight  uint32 `json:"weight"`
+}
+
+// envoyClusters is the response of the Envoy admin /clusters endpoint in JSON format
+type envoyClusters struct {
+	ClusterStatuses []struct {
+		Name         string `json:"name"`
+		HostStatuses []struct {
+			Address struct {
+				SocketAddress struct {
+					Address   string `json:"address"`
+					PortValue uint32 `json:"port_value"`
+				} `json:"socket_address"`
+			} `json:"address"`
+			HealthStatus envoyHostHealthStatus `json:"health_status"`
+			Weight       uint32                `json:"weight"`
+		} `json:"host_statuses"`
+	} `json:"cluster_statuses"`
+}
+
+// envoyHostHealthStatus is the health status of an endpoint returned by the Envoy admin /clusters endpoint
+type envoyHostHealthStatus struct {
+	EDSHealthStatus           string `json:"eds_health_status"`
+	FailedActiveHealthCheck   bool   `json:"failed_active_health_check"`
+	FailedOutlierCheck        bool   `json:"failed_outlier_check"`
+	FailedActiveDegradedCheck bool   `json:"failed_active_degraded_check"`
+	PendingDynamicRemoval     bool   `json:"pending_dynamic_removal"`
+}
+
+func newProxyGetEndpointsCmd(config *action.Configuration, out io.Writer) *cobra.Command {
+	getEndpointsCmd := &proxyGetEndpointsCmd{
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "get-endpoints POD SERVICE",
+		Short: "get the endpoints of a service as seen by a proxy",
+		Long:  getEndpointsCmdDescription,
+		Args:  cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			getEndpointsCmd.pod = args[0]
+			getEndpointsCmd.service = args[1]
+			conf, err := config.RESTClientGetter.ToRESTConfig()
+			if err != nil {
+				return errors.Errorf("Error fetching kubeconfig: %s", err)
+			}
+			getEndpointsCmd.config = conf
+
+			clientset, err := kubernetes.NewForConfig(conf)
+			if err != nil {
+				return errors.Errorf("Could not access Kubernetes cluster, check kubeconfig: %s", err)
+			}
+			getEndpointsCmd.clientSet = clientset
+			return getEndpointsCmd.run()
+		},
+		Example: getEndpointsCmdExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&getEndpointsCmd.namespace, "namespace", "n", metav1.NamespaceDefault, "Namespace of pod")
+	f.StringVarP(&getEndpointsCmd.output, "output", "o", "", "Output format, one of: json. A table is printed if unset")
+	f.Uint16VarP(&getEndpointsCmd.localPort, "local-port", "p", constants.EnvoyAdminPort, "Local port to use for port forwarding")
+	addProxyAdminTimeoutFlag(f, &getEndpointsCmd.timeout, "timeout")
+
+	return cmd
+}
+
+func (cmd *proxyGetEndpointsCmd) run() error {
+	if cmd.output != "" && cmd.output != outputFormatJSON {
+		return errors.Errorf("Invalid value %q for flag --output, expected: %s", cmd.output, outputFormatJSON)
+	}
+	meshService, err := parseMeshService(cmd.service, cmd.namespace)
+	if err != nil {
+		return err
+	}
+
+	if _, err := getRunningMeshedPod(cmd.clientSet, cmd.namespace, cmd.pod); err != nil {
+		return err
+	}
+
+	clusters, err := proxyAdminRequest(cmd.config, cmd.clientSet, cmd.namespace, cmd.pod, cmd.localPort, cmd.timeout, http.MethodGet, clustersQuery)
+	if err != nil {
+		return annotateErrMsgWithPodNamespaceMsg("Error retrieving proxy clusters for pod %s in namespace %s: %s", cmd.pod, cmd.namespace, err)
+	}
+
+	endpoints, serviceClusters, err := getClusterEndpoints(clusters, meshService.String())
+	if err != nil {
+		return err
+	}
+	if endpoints == nil {
+		return cmd.clusterNotFoundError(meshService, serviceClusters)
+	}
+
+	return printProxyEndpoints(cmd.out, endpoints, cmd.output)
+}
+
+// clusterNotFoundError returns the error reporting that the proxy has no cluster for the given service, along with
+// whether the service exists in Kubernetes and the service clusters the proxy has
+func (cmd *proxyGetEndpointsCmd) clusterNotFoundError(meshService service.MeshService, serviceClusters []string) error {
+	msg := fmt.Sprintf("Cluster %s not found in the config of the proxy of pod %s in namespace %s", meshService, cmd.pod, cmd.namespace)
+
+	_, err := cmd.clientSet.CoreV1().Services(meshService.Namespace).Get(context.TODO(), meshService.Name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		msg += fmt.Sprintf(", service %s does not exist", meshService)
+	case err == nil:
+		msg += ", check that the namespace of the service is part of the mesh and that a traffic policy allows the pod to access the service"
+	}
+
+	if len(serviceClusters) == 0 {
+		return errors.New(msg + "\nThe proxy has no service clusters")
+	}
+	return errors.Errorf("%s\nService clusters of the proxy: %s", msg, strings.Join(serviceClusters, ", "))
+}
+
+// parseMeshService returns the service given as <namespace/service>, or as <service> in the given default namespace
+func parseMeshService(namespacedService, defaultNamespace string) (service.MeshService, error) {
+	meshService := service.MeshService{Namespace: defaultNamespace, Name: namespacedService}
+	if chunks := strings.Split(namespacedService, namespaceSeparator); len(chunks) == 2 {
+		meshService = service.MeshService{Namespace: chunks[0], Name: chunks[1]}
+	} else if len(chunks) > 2 {
+		return service.MeshService{}, errors.Errorf("Service should be of the form <namespace/service>, or <service> for the namespace of the pod, got: %s", namespacedService)
+	}
+
+	if errs := validation.IsDNS1123Label(meshService.Namespace); len(errs) > 0 {
+		return service.MeshService{}, errors.Errorf("Invalid namespace %q in %s: %s", meshService.Namespace, namespacedService, strings.Join(errs, "; "))
+	}
+	if errs := validation.IsDNS1123Label(meshService.Name); len(errs) > 0 {
+		return service.MeshService{}, errors.Errorf("Invalid service name %q in %s: %s", meshService.Name, namespacedService, strings.Join(errs, "; "))
+	}
+	return meshService, nil
+}
+
+// getClusterEndpoints returns the endpoints of the cluster with the given name among the given Envoy clusters, sorted
+// by address and port. When the cluster is not found, the returned endpoints are nil and the sorted names of the
+// service clusters of the proxy are returned instead, excluding the local clusters of the services of the pod.
+func getClusterEndpoints(clustersJSON []byte, clusterName string) ([]proxyEndpoint, []string, error) {
+	var clusters envoyClusters
+	if err := json.Unmarshal(clustersJSON, &clusters); err != nil {
+		return nil, nil, errors.Errorf("Error parsing proxy clusters: %s", err)
+	}
+
+	var serviceClusters []string
+	for _, cluster := range clusters.ClusterStatuses {
+		if cluster.Name != clusterName {
+			if strings.Contains(cluster.Name, namespaceSeparator) && !strings.HasSuffix(cluster.Name, "-local") {
+				serviceClusters = append(serviceClusters, cluster.Name)
+			}
+			continue
+		}
+
+		endpoints := []proxyEndpoint{}
+		for _, host := range cluster.HostStatuses {
+			endpoints = append(endpoints, proxyEndpoint{
+				Address: host.Address.SocketAddress.Address,
+				Port:    host.Address.SocketAddress.PortValue,
+				Health:  getEndpointHealth(host.HealthStatus),
+				Weight:  host.Weight,
+			})
+		}
+		sort.Slice(endpoints, func(i, j int) bool {
+			if endpoints[i].Address != endpoints[j].Address {
+				return endpoints[i].Address < endpoints[j].Address
+			}
+			return endpoints[i].Port < endpoints[j].Port
+		})
+		return endpoints, nil, nil
+	}
+
+	sort.Strings(serviceClusters)
+	return nil, serviceClusters, nil
+}
+
+// getEndpointHealth returns the health of an endpoint given its Envoy health status. The failed health checks take
+// precedence over the health status received through EDS.
+func getEndpointHealth(status envoyHostHealthStatus) string {
+	var health string
+	switch {
+	case status.FailedActiveHealthCheck:
+		health = "UNHEALTHY (failed active health check)"
+	case status.FailedOutlierCheck:
+		health = "UNHEALTHY (ejected by outlier detection)"
+	case status.FailedActiveDegradedCheck:
+		health = "DEGRADED (failed active health check)"
+	case status.EDSHealthStatus == "":
+		health = "UNKNOWN"
+	default:
+		health = status.EDSHealthStatus
+	}
+	if status.PendingDynamicRemoval {
+		health += " (pending removal)"
+	}
+	return health
+}
+
+// printProxyEndpoints prints the given endpoints in the given output format
+func printProxyEndpoints(out io.Writer, endpoints []proxyEndpoint, output string) error {
+	if output == outputFormatJSON {
+		endpointsJSON, err := json.MarshalIndent(endpoints, "", "  ")
+		if err != nil {
+			return errors.Errorf("Error marshaling proxy endpoints: %s", err)
+		}
+		fmt.Fprintln(out, string(endpointsJSON))
+		return nil
+	}
+
+	if len(endpoints) == 0 {
+		fmt.Fprintln(out, "No endpoints found, the proxy has no endpoints for the service")
+		return nil
+	}
+
+	t := newTable(proxyEndpointColumns)
+	for _, endpoint := range endpoints {
+		t.addRow(endpoint.Address, strconv.FormatUint(uint64(endpoint.Port), 10), endpoint.Health, strconv.FormatUint(uint64(endpoint.Weight), 10))
+	}
+	return t.write(out, nil)
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/service"
+)
+
+const testProxyClusters = `{
+  "cluster_statuses": [
+    {
+      "name": "bookbuyer/bookbuyer-local",
+      "host_statuses": [
+        {
+          "address": {"socket_address": {"address": "127.0.0.1", "port_value": 14001}},
+          "health_status": {"eds_health_status": "HEALTHY"},
+          "weight": 1
+        }
+      ]
+    },
+    {
+      "name": "bookstore/bookstore",
+      "host_statuses": [
+        {
+          "address": {"socket_address": {"address": "10.0.0.12", "port_value": 14001}},
+          "health_status": {"eds_health_status": "HEALTHY", "failed_outlier_check": true},
+          "weight": 1
+        },
+        {
+          "address": {"socket_address": {"address": "10.0.0.11", "port_value": 14001}},
+          "health_status": {"eds_health_status": "HEALTHY"},
+          "weight": 1
+        }
+      ]
+    },
+    {
+      "name": "bookstore/bookstore-v2",
+      "host_statuses": []
+    },
+    {
+      "name": "passthrough-outbound",
+      "host_statuses": []
+    }
+  ]
+}`
+
+func TestGetClusterEndpoints(t *testing.T) {
+	testCases := []struct {
+		name                    string
+		clusterName             string
+		expectedEndpoints       []proxyEndpoint
+		expectedServiceClusters []string
+		expectErr               bool
+	}{
+		{
+			name:        "cluster with endpoints",
+			clusterName: "bookstore/bookstore",
+			expectedEndpoints: []proxyEndpoint{
+				{Address: "10.0.0.11", Port: 14001, Health: "HEALTHY", Weight: 1},
+				{Address: "10.0.0.12", Port: 14001, Health: "UNHEALTHY (ejected by outlier detection)", Weight: 1},
+			},
+		},
+		{
+			name:              "cluster without endpoints",
+			clusterName:       "bookstore/bookstore-v2",
+			expectedEndpoints: []proxyEndpoint{},
+		},
+		{
+			name:                    "cluster not found lists the service clusters",
+			clusterName:             "bookstore/bookstore-v1",
+			expectedEndpoints:       nil,
+			expectedServiceClusters: []string{"bookstore/bookstore", "bookstore/bookstore-v2"},
+		},
+		{
+			name:        "invalid clusters",
+			clusterName: "",
+			expectErr:   true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			clusters := []byte(testProxyClusters)
+			if tc.expectErr {
+				clusters = []byte("cluster_statuses")
+			}
+			endpoints, serviceClusters, err := getClusterEndpoints(clusters, tc.clusterName)
+			assert.Equal(tc.expectErr, err != nil)
+			assert.Equal(tc.expectedEndpoints, endpoints)
+			assert.Equal(tc.expectedServiceClusters, serviceClusters)
+		})
+	}
+}
+
+func TestGetEndpointHealth(t *testing.T) {
+	testCases := []struct {
+		status   envoyHostHealthStatus
+		expected string
+	}{
+		{
+			status:   envoyHostHealthStatus{EDSHealthStatus: "HEALTHY"},
+			expected: "HEALTHY",
+		},
+		{
+			status:   envoyHostHealthStatus{EDSHealthStatus: "DRAINING", PendingDynamicRemoval: true},
+			expected: "DRAINING (pending removal)",
+		},
+		{
+			status:   envoyHostHealthStatus{EDSHealthStatus: "HEALTHY", FailedActiveHealthCheck: true, FailedOutlierCheck: true},
+			expected: "UNHEALTHY (failed active health check)",
+		},
+		{
+			status:   envoyHostHealthStatus{},
+			expected: "UNKNOWN",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.expected), func(t *testing.T) {
+			tassert.Equal(t, tc.expected, getEndpointHealth(tc.status))
+		})
+	}
+}
+
+func TestParseMeshService(t *testing.T) {
+	testCases := []struct {
+		namespacedService string
+		expected          service.MeshService
+		expectErr         bool
+	}{
+		{
+			namespacedService: "bookstore/bookstore-v1",
+			expected:          service.MeshService{Namespace: "bookstore", Name: "bookstore-v1"},
+		},
+		{
+			namespacedService: "bookstore",
+			expected:          service.MeshService{Namespace: "bookbuyer", Name: "bookstore"},
+		},
+		{
+			namespacedService: "bookstore/bookstore/v1",
+			expectErr:         true,
+		},
+		{
+			namespacedService: "Bookstore/bookstore",
+			expectErr:         true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.namespacedService), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			actual, err := parseMeshService(tc.namespacedService, "bookbuyer")
+			assert.Equal(tc.expectErr, err != nil)
+			assert.Equal(tc.expected, actual)
+		})
+	}
+}
+
+func TestPrintProxyEndpoints(t *testing.T) {
+	assert := tassert.New(t)
+
+	endpoints := []proxyEndpoint{{Address: "10.0.0.11", Port: 14001, Health: "HEALTHY", Weight: 1}}
+
+	out := new(bytes.Buffer)
+	assert.Nil(printProxyEndpoints(out, endpoints, ""))
+	assert.Contains(out.String(), "ADDRESS")
+	assert.Contains(out.String(), "10.0.0.11")
+	assert.Contains(out.String(), "HEALTHY")
+
+	out.Reset()
+	assert.Nil(printProxyEndpoints(out, []proxyEndpoint{}, ""))
+	assert.Equal("No endpoints found, the proxy has no endpoints for the service\n", out.String())
+
+	out.Reset()
+	assert.Nil(printProxyEndpoints(out, []proxyEndpoint{}, outputFormatJSON))
+	assert.Equal("[]\n", out.String())
+}
+
+func TestProxyGetEndpointsClusterNotFound(t *testing.T) {
+	testCases := []struct {
+		name            string
+		services        []*corev1.Service
+		serviceClusters []string
+		expectedErr     string
+	}{
+		{
+			name:            "service does not exist",
+			serviceClusters: []string{"bookstore/bookstore"},
+			expectedErr: "Cluster bookstore/bookstore-v1 not found in the config of the proxy of pod bookbuyer in namespace bookbuyer, service bookstore/bookstore-v1 does not exist\n" +
+				"Service clusters of the proxy: bookstore/bookstore",
+		},
+		{
+			name:     "service exists",
+			services: []*corev1.Service{{ObjectMeta: metav1.ObjectMeta{Name: "bookstore-v1", Namespace: "bookstore"}}},
+			expectedErr: "Cluster bookstore/bookstore-v1 not found in the config of the proxy of pod bookbuyer in namespace bookbuyer, " +
+				"check that the namespace of the service is part of the mesh and that a traffic policy allows the pod to access the service\n" +
+				"The proxy has no service clusters",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			clientSet := fake.NewSimpleClientset()
+			for _, svc := range tc.services {
+				assert.Nil(clientSet.Tracker().Add(svc))
+			}
+			cmd := &proxyGetEndpointsCmd{
+				clientSet: clientSet,
+				namespace: "bookbuyer",
+				pod:       "bookbuyer",
+			}
+
+			err := cmd.clusterNotFoundError(service.MeshService{Namespace: "bookstore", Name: "bookstore-v1"}, tc.serviceClusters)
+			assert.EqualError(err, tc.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/action"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+const getStatsCmdDescription = `
+This command will print the statistics of the Envoy proxy sidecar of the given
+pod, such as the counters of the TLS handshakes or the number of requests sent
+to each cluster.
+
+The statistics are read from the /stats endpoint of the Envoy admin interface
+and can be filtered with a regular expression matched against their names.
+Refer to https://www.envoyproxy.io/docs/envoy/latest/configuration/observability/statistics
+for the statistics emitted by Envoy.
+`
+
+const getStatsCmdExample = `
+# Get all the stats of the proxy for the given pod 'bookbuyer-5ccf77f46d-rc5mg' in the 'bookbuyer' namespace
+osm proxy get-stats bookbuyer-5ccf77f46d-rc5mg -n bookbuyer
+
+# Get the TLS handshake stats of the proxy
+osm proxy get-stats bookbuyer-5ccf77f46d-rc5mg -n bookbuyer --filter 'ssl\.handshake'
+
+# Get the request counts of the clusters of the proxy as JSON
+osm proxy get-stats bookbuyer-5ccf77f46d-rc5mg -n bookbuyer --filter '^cluster\..*\.upstream_rq_total$' -o json
+`
+
+// statsQuery is the Envoy admin query returning the stats of the proxy
+const statsQuery = "stats"
+
+type proxyGetStatsCmd struct {
+	out       io.Writer
+	config    *rest.Config
+	clientSet kubernetes.Interface
+	namespace string
+	pod       string
+	filter    string
+	output    string
+	localPort uint16
+	timeout   time.Duration
+}
+
+// proxyStat is a statistic of a proxy. The value of counters and gauges is an integer, the value of histograms is
+// the summary of their quantiles rendered by Envoy.
+type proxyStat struct {
+	Name  string      `json:"name"`
+	Value interface{} `json:"value"`
+}
+
+func newProxyGetStatsCmd(config *action.Configuration, out io.Writer) *cobra.Command {
+	getStatsCmd := &proxyGetStatsCmd{
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "get-stats POD",
+		Short: "get the stats of a proxy",
+		Long:  getStatsCmdDescription,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			getStatsCmd.pod = args[0]
+			conf, err := config.RESTClientGetter.ToRESTConfig()
+			if err != nil {
+				return errors.Errorf("Error fetching kubeconfig: %s", err)
+			}
+			getStatsCmd.config = conf
+
+			clientset, err := kubernetes.NewForConfig(conf)
+			if err != nil {
+				return errors.Errorf("Could not access Kubernetes cluster, check kubeconfig: %s", err)
+			}
+			getStatsCmd.clientSet = clientset
+			return getStatsCmd.run()
+		},
+		Example: getStatsCmdExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&getStatsCmd.namespace, "namespace", "n", metav1.NamespaceDefault, "Namespace of pod")
+	f.StringVar(&getStatsCmd.filter, "filter", "", "Regular expression matched against the names of the stats, all the stats are printed if unset")
+	f.StringVarP(&getStatsCmd.output, "output", "o", "", "Output format, one of: json. The stats are printed as 'name: value' lines if unset")
+	f.Uint16VarP(&getStatsCmd.localPort, "local-port", "p", constants.EnvoyAdminPort, "Local port to use for port forwarding")
+	addProxyAdminTimeoutFlag(f, &getStatsCmd.timeout, "timeout")
+
+	return cmd
+}
+
+func (cmd *proxyGetStatsCmd) run() error {
+	if cmd.output != "" && cmd.output != outputFormatJSON {
+		return errors.Errorf("Invalid value %q for flag --output, expected: %s", cmd.output, outputFormatJSON)
+	}
+	filter, err := regexp.Compile(cmd.filter)
+	if err != nil {
+		return errors.Errorf("Invalid regular expression %q for flag --filter: %s", cmd.filter, err)
+	}
+
+	if _, err := getRunningMeshedPod(cmd.clientSet, cmd.namespace, cmd.pod); err != nil {
+		return err
+	}
+
+	stats, err := proxyAdminRequest(cmd.config, cmd.clientSet, cmd.namespace, cmd.pod, cmd.localPort, cmd.timeout, http.MethodGet, statsQuery)
+	if err != nil {
+		return annotateErrMsgWithPodNamespaceMsg("Error retrieving proxy stats for pod %s in namespace %s: %s", cmd.pod, cmd.namespace, err)
+	}
+
+	return printProxyStats(cmd.out, parseProxyStats(stats, filter), cmd.output)
+}
+
+// parseProxyStats returns the stats whose name matches the given filter, in the order of the given Envoy stats
+// rendered as 'name: value' lines
+func parseProxyStats(stats []byte, filter *regexp.Regexp) []proxyStat {
+	var matched []proxyStat
+	scanner := bufio.NewScanner(bytes.NewReader(stats))
+	for scanner.Scan() {
+		nameValue := strings.SplitN(scanner.Text(), ":", 2)
+		if len(nameValue) != 2 {
+			continue
+		}
+		name := strings.TrimSpace(nameValue[0])
+		if !filter.MatchString(name) {
+			continue
+		}
+
+		stat := proxyStat{Name: name, Value: strings.TrimSpace(nameValue[1])}
+		if value, err := strconv.ParseInt(stat.Value.(string), 10, 64); err == nil {
+			stat.Value = value
+		}
+		matched = append(matched, stat)
+	}
+	return matched
+}
+
+// printProxyStats prints the given stats in the given output format
+func printProxyStats(out io.Writer, stats []proxyStat, output string) error {
+	if output == outputFormatJSON {
+		if stats == nil {
+			stats = []proxyStat{}
+		}
+		statsJSON, err := json.MarshalIndent(stats, "", "  ")
+		if err != nil {
+			return errors.Errorf("Error marshaling proxy stats: %s", err)
+		}
+		fmt.Fprintln(out, string(statsJSON))
+		return nil
+	}
+
+	if len(stats) == 0 {
+		fmt.Fprintln(out, "No matching stats found")
+		return nil
+	}
+	for _, stat := range stats {
+		fmt.Fprintf(out, "%s: %v\n", stat.Name, stat.Value)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+)
+
+const testProxyStats = `cluster.bookstore/bookstore.upstream_rq_200: 42
+cluster.bookstore/bookstore.upstream_rq_total: 45
+cluster.bookstore/bookstore.upstream_cx_active: 2
+listener.0.0.0.0_15003.ssl.handshake: 7
+cluster.bookstore/bookstore.ssl.handshake: 3
+cluster.bookstore/bookstore.upstream_rq_time: P0(nan,1.0) P25(nan,2.05) P50(nan,3.05)
+`
+
+func TestProxyGetStats(t *testing.T) {
+	testCases := []struct {
+		name     string
+		filter   string
+		output   string
+		expected string
+	}{
+		{
+			name:   "all stats",
+			filter: "",
+			expected: "cluster.bookstore/bookstore.upstream_rq_200: 42\n" +
+				"cluster.bookstore/bookstore.upstream_rq_total: 45\n" +
+				"cluster.bookstore/bookstore.upstream_cx_active: 2\n" +
+				"listener.0.0.0.0_15003.ssl.handshake: 7\n" +
+				"cluster.bookstore/bookstore.ssl.handshake: 3\n" +
+				"cluster.bookstore/bookstore.upstream_rq_time: P0(nan,1.0) P25(nan,2.05) P50(nan,3.05)\n",
+		},
+		{
+			name:   "filtered stats",
+			filter: `ssl\.handshake`,
+			expected: "listener.0.0.0.0_15003.ssl.handshake: 7\n" +
+				"cluster.bookstore/bookstore.ssl.handshake: 3\n",
+		},
+		{
+			name:     "no matching stats",
+			filter:   "^http\\.",
+			expected: "No matching stats found\n",
+		},
+		{
+			name:   "filtered stats as JSON",
+			filter: `upstream_rq_t`,
+			output: outputFormatJSON,
+			expected: `[
+  {
+    "name": "cluster.bookstore/bookstore.upstream_rq_total",
+    "value": 45
+  },
+  {
+    "name": "cluster.bookstore/bookstore.upstream_rq_time",
+    "value": "P0(nan,1.0) P25(nan,2.05) P50(nan,3.05)"
+  }
+]
+`,
+		},
+		{
+			name:     "no matching stats as JSON",
+			filter:   "^http\\.",
+			output:   outputFormatJSON,
+			expected: "[]\n",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			out := new(bytes.Buffer)
+			stats := parseProxyStats([]byte(testProxyStats), regexp.MustCompile(tc.filter))
+			assert.Nil(printProxyStats(out, stats, tc.output))
+			assert.Equal(tc.expected, out.String())
+		})
+	}
+}
+
+func TestProxyGetStatsInvalidFlags(t *testing.T) {
+	testCases := []struct {
+		name        string
+		filter      string
+		output      string
+		expectedErr string
+	}{
+		{
+			name:        "invalid output format",
+			output:      "yaml",
+			expectedErr: "Invalid value \"yaml\" for flag --output, expected: json",
+		},
+		{
+			name:        "invalid filter",
+			filter:      "ssl.(handshake",
+			expectedErr: "Invalid regular expression \"ssl.(handshake\" for flag --filter",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			cmd := &proxyGetStatsCmd{
+				out:    new(bytes.Buffer),
+				filter: tc.filter,
+				output: tc.output,
+			}
+			err := cmd.run()
+			assert.NotNil(err)
+			assert.Contains(err.Error(), tc.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+const proxyListDescription = `
+This command lists the Envoy sidecar proxies of the pods in the namespaces
+monitored by any mesh, along with their proxy UUID, the image of the sidecar
+and whether the sidecar is ready.
+
+The proxies of a single namespace can be listed with the --namespace flag.
+
+The columns of the table can be selected with the --columns flag, among:
+namespace, pod, proxy-uuid, envoy-image, ready.
+`
+
+const proxyListExample = `
+# List the sidecar proxies in all monitored namespaces
+osm proxy list
+
+# List the sidecar proxies in the 'bookbuyer' namespace as JSON
+osm proxy list -n bookbuyer -o json
+
+# List the pods and readiness of the sidecar proxies in all monitored namespaces
+osm proxy list --columns namespace,pod,ready
+`
+
+const outputFormatJSON = "json"
+
+// proxyListColumns are the columns of the table of sidecar proxies
+var proxyListColumns = []tableColumn{
+	{name: "namespace", header: "NAMESPACE"},
+	{name: "pod", header: "POD"},
+	{name: "proxy-uuid", header: "PROXY-UUID"},
+	{name: "envoy-image", header: "ENVOY-IMAGE"},
+	{name: "ready", header: "READY"},
+}
+
+type proxyListCmd struct {
+	out       io.Writer
+	namespace string
+	output    string
+	columns   []string
+	clientSet kubernetes.Interface
+}
+
+// proxyInfo describes the Envoy sidecar proxy of a pod
+type proxyInfo struct {
+	Namespace  string `json:"namespace"`
+	Pod        string `json:"pod"`
+	ProxyUUID  string `json:"proxyUUID"`
+	EnvoyImage string `json:"envoyImage"`
+	Ready      bool   `json:"ready"`
+}
+
+func newProxyListCmd(out io.Writer) *cobra.Command {
+	listCmd := &proxyListCmd{
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "list sidecar proxies",
+		Long:  proxyListDescription,
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			config, err := settings.RESTClientGetter().ToRESTConfig()
+			if err != nil {
+				return errors.Errorf("Error fetching kubeconfig: %s", err)
+			}
+
+			clientset, err := kubernetes.NewForConfig(config)
+			if err != nil {
+				return errors.Errorf("Could not access Kubernetes cluster, check kubeconfig: %s", err)
+			}
+			listCmd.clientSet = clientset
+			return listCmd.run()
+		},
+		Example: proxyListExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&listCmd.namespace, "namespace", "n", "", "Namespace of the pods, all the monitored namespaces if unset")
+	f.StringVarP(&listCmd.output, "output", "o", "", "Output format, one of: json. A table is printed if unset")
+	f.StringSliceVar(&listCmd.columns, "columns", nil, "Comma separated list of the columns of the table, all the columns if unset")
+
+	return cmd
+}
+
+func (l *proxyListCmd) run() error {
+	if l.output != "" && l.output != outputFormatJSON {
+		return errors.Errorf("Invalid value %q for flag --output, expected: %s", l.output, outputFormatJSON)
+	}
+	if err := validateColumns(proxyListColumns, l.columns); err != nil {
+		return err
+	}
+
+	proxies, err := l.listProxies()
+	if err != nil {
+		return err
+	}
+
+	if l.output == outputFormatJSON {
+		if proxies == nil {
+			proxies = []proxyInfo{}
+		}
+		proxiesJSON, err := json.MarshalIndent(proxies, "", "  ")
+		if err != nil {
+			return errors.Errorf("Error marshaling proxies: %s", err)
+		}
+		fmt.Fprintln(l.out, string(proxiesJSON))
+		return nil
+	}
+
+	if len(proxies) == 0 {
+		fmt.Fprintln(l.out, "No sidecar proxies found")
+		return nil
+	}
+
+	t := newTable(proxyListColumns)
+	for _, proxy := range proxies {
+		t.addRow(proxy.Namespace, proxy.Pod, proxy.ProxyUUID, proxy.EnvoyImage, strconv.FormatBool(proxy.Ready))
+	}
+	return t.write(l.out, l.columns)
+}
+
+// listProxies returns the sidecar proxies of the pods in the namespace given with --namespace, or in all the monitored
+// namespaces, sorted by namespace and pod name
+func (l *proxyListCmd) listProxies() ([]proxyInfo, error) {
+	pods, err := listMeshedPods(l.clientSet, l.namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	var proxies []proxyInfo
+	for _, pod := range pods {
+		proxies = append(proxies, getProxyInfo(pod))
+	}
+	return proxies, nil
+}
+
+// listMeshedPods returns the pods with a sidecar proxy in the given namespace, or in all the monitored namespaces if
+// empty, sorted by namespace and pod name
+func listMeshedPods(clientSet kubernetes.Interface, namespace string) ([]corev1.Pod, error) {
+	namespaces := []string{namespace}
+	if namespace == "" {
+		monitoredNamespaces, err := clientSet.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{
+			LabelSelector: constants.OSMKubeResourceMonitorAnnotation,
+		})
+		if err != nil {
+			return nil, errors.Errorf("Error listing monitored namespaces: %s", err)
+		}
+		namespaces = nil
+		for _, ns := range monitoredNamespaces.Items {
+			namespaces = append(namespaces, ns.Name)
+		}
+	}
+
+	var meshedPods []corev1.Pod
+	for _, namespace := range namespaces {
+		pods, err := clientSet.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+			LabelSelector: constants.EnvoyUniqueIDLabelName,
+		})
+		if err != nil {
+			return nil, errors.Errorf("Error listing pods in namespace %s: %s", namespace, err)
+		}
+		meshedPods = append(meshedPods, pods.Items...)
+	}
+
+	sort.Slice(meshedPods, func(i, j int) bool {
+		if meshedPods[i].Namespace != meshedPods[j].Namespace {
+			return meshedPods[i].Namespace < meshedPods[j].Namespace
+		}
+		return meshedPods[i].Name < meshedPods[j].Name
+	})
+	return meshedPods, nil
+}
+
+// getProxyInfo returns the description of the sidecar proxy of the given pod
+func getProxyInfo(pod corev1.Pod) proxyInfo {
+	proxy := proxyInfo{
+		Namespace: pod.Namespace,
+		Pod:       pod.Name,
+		ProxyUUID: pod.Labels[constants.EnvoyUniqueIDLabelName],
+	}
+	for _, container := range pod.Spec.Containers {
+		if container.Name == constants.EnvoyContainerName {
+			proxy.EnvoyImage = container.Image
+		}
+	}
+	for _, status := range pod.Status.ContainerStatuses {
+		if status.Name == constants.EnvoyContainerName {
+			proxy.Ready = status.Ready
+		}
+	}
+	return proxy
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+func TestProxyList(t *testing.T) {
+	var proxyPods []runtime.Object
+	for _, proxy := range []struct {
+		namespace, name, uuid string
+		ready                 bool
+	}{
+		{namespace: "bookstore", name: "bookstore-v2", uuid: "2b2b2b2b-2b2b-2b2b-2b2b-2b2b2b2b2b2b"},
+		{namespace: "bookstore", name: "bookstore-v1", uuid: "1a1a1a1a-1a1a-1a1a-1a1a-1a1a1a1a1a1a", ready: true},
+		{namespace: "bookbuyer", name: "bookbuyer", uuid: "0c0c0c0c-0c0c-0c0c-0c0c-0c0c0c0c0c0c", ready: true},
+		{namespace: "unmonitored", name: "leftover", uuid: "3d3d3d3d-3d3d-3d3d-3d3d-3d3d3d3d3d3d", ready: true},
+	} {
+		pod := newTestPod(proxy.namespace, proxy.name, "", true)
+		pod.Labels[constants.EnvoyUniqueIDLabelName] = proxy.uuid
+		pod.Spec.Containers = []corev1.Container{
+			{Name: "app", Image: "app:v1"},
+			{Name: constants.EnvoyContainerName, Image: "envoyproxy/envoy-alpine:v1.17.2"},
+		}
+		pod.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: constants.EnvoyContainerName, Ready: proxy.ready}}
+		proxyPods = append(proxyPods, pod)
+	}
+	unmonitoredNamespace := newNamespace("unmonitored", nil)
+	unmonitoredNamespace.Labels = nil
+
+	client := fake.NewSimpleClientset(append([]runtime.Object{
+		newNamespace("bookbuyer", nil),
+		newNamespace("bookstore", nil),
+		unmonitoredNamespace,
+		newTestPod("bookbuyer", "unmeshed", "", false),
+	}, proxyPods...)...)
+
+	testCases := []struct {
+		name        string
+		namespace   string
+		output      string
+		columns     []string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name: "proxies in all monitored namespaces",
+			expected: "NAMESPACE   POD            PROXY-UUID                             ENVOY-IMAGE                       READY\n" +
+				"bookbuyer   bookbuyer      0c0c0c0c-0c0c-0c0c-0c0c-0c0c0c0c0c0c   envoyproxy/envoy-alpine:v1.17.2   true\n" +
+				"bookstore   bookstore-v1   1a1a1a1a-1a1a-1a1a-1a1a-1a1a1a1a1a1a   envoyproxy/envoy-alpine:v1.17.2   true\n" +
+				"bookstore   bookstore-v2   2b2b2b2b-2b2b-2b2b-2b2b-2b2b2b2b2b2b   envoyproxy/envoy-alpine:v1.17.2   false\n",
+		},
+		{
+			name:      "proxies in a namespace",
+			namespace: "unmonitored",
+			expected: "NAMESPACE     POD        PROXY-UUID                             ENVOY-IMAGE                       READY\n" +
+				"unmonitored   leftover   3d3d3d3d-3d3d-3d3d-3d3d-3d3d3d3d3d3d   envoyproxy/envoy-alpine:v1.17.2   true\n",
+		},
+		{
+			name:      "no proxies in a namespace",
+			namespace: "empty",
+			expected:  "No sidecar proxies found\n",
+		},
+		{
+			name:      "proxies as JSON",
+			namespace: "bookbuyer",
+			output:    outputFormatJSON,
+			expected: `[
+  {
+    "namespace": "bookbuyer",
+    "pod": "bookbuyer",
+    "proxyUUID": "0c0c0c0c-0c0c-0c0c-0c0c-0c0c0c0c0c0c",
+    "envoyImage": "envoyproxy/envoy-alpine:v1.17.2",
+    "ready": true
+  }
+]
+`,
+		},
+		{
+			name:      "no proxies as JSON",
+			namespace: "empty",
+			output:    outputFormatJSON,
+			expected:  "[]\n",
+		},
+		{
+			name:      "selected columns",
+			namespace: "bookstore",
+			columns:   []string{"pod", "ready"},
+			expected: "POD            READY\n" +
+				"bookstore-v1   true\n" +
+				"bookstore-v2   false\n",
+		},
+		{
+			name:        "invalid column",
+			columns:     []string{"pod", "node"},
+			expectedErr: "Invalid column \"node\" for flag --columns, expected one of: namespace, pod, proxy-uuid, envoy-image, ready",
+		},
+		{
+			name:        "invalid output format",
+			output:      "yaml",
+			expectedErr: "Invalid value \"yaml\" for flag --output, expected: json",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			out := new(bytes.Buffer)
+			cmd := &proxyListCmd{
+				out:       out,
+				namespace: tc.namespace,
+				output:    tc.output,
+				columns:   tc.columns,
+				clientSet: client,
+			}
+
+			err := cmd.run()
+			if tc.expectedErr != "" {
+				assert.NotNil(err)
+				assert.Equal(tc.expectedErr, err.Error())
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(tc.expected, out.String())
+		})
+	}
+}
@@ -0,0 +1,183 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/action"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+const resetCountersCmdDescription = `
+This command resets the counters of the Envoy proxy sidecar of the given pod to
+zero, e.g. to get a clean baseline of the proxy stats before a load test.
+
+Only the counters are reset: the gauges and histograms of the proxy, its
+configuration and its connections are left untouched, and the pod is not
+restarted. The reset is made through the /reset_counters endpoint of the Envoy
+admin interface.
+
+With --all, the counters of the proxies of all the running meshed pods are
+reset, in the namespace given with --namespace or in all the monitored
+namespaces when --namespace is not set. The proxies are only listed unless
+--yes is set.
+`
+
+const resetCountersCmdExample = `
+# Reset the counters of the proxy for the given pod 'bookbuyer-5ccf77f46d-rc5mg' in the 'bookbuyer' namespace
+osm proxy reset-counters bookbuyer-5ccf77f46d-rc5mg -n bookbuyer
+
+# List the proxies whose counters would be reset in all the monitored namespaces
+osm proxy reset-counters --all
+
+# Reset the counters of the proxies of all the meshed pods in the 'bookstore' namespace
+osm proxy reset-counters --all -n bookstore --yes
+`
+
+// resetCountersQuery is the Envoy admin query resetting the counters of the proxy
+const resetCountersQuery = "reset_counters"
+
+type proxyResetCountersCmd struct {
+	out       io.Writer
+	config    *rest.Config
+	clientSet kubernetes.Interface
+	namespace string
+	pod       string
+	all       bool
+	yes       bool
+	localPort uint16
+	timeout   time.Duration
+
+	// resetCounters resets the counters of the proxy of the given pod
+	resetCounters func(pod *corev1.Pod) error
+}
+
+func newProxyResetCountersCmd(config *action.Configuration, out io.Writer) *cobra.Command {
+	resetCmd := &proxyResetCountersCmd{
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "reset-counters [POD]",
+		Short: "reset the counters of a proxy",
+		Long:  resetCountersCmdDescription,
+		Args: func(_ *cobra.Command, args []string) error {
+			if resetCmd.all && len(args) > 0 {
+				return errors.New("POD cannot be set along with --all")
+			}
+			if !resetCmd.all && len(args) != 1 {
+				return errors.Errorf("Expected POD, or --all, got %d argument(s)", len(args))
+			}
+			return nil
+		},
+		RunE: func(c *cobra.Command, args []string) error {
+			if len(args) == 1 {
+				resetCmd.pod = args[0]
+			}
+			if resetCmd.all && !c.Flags().Changed("namespace") {
+				// The proxies of all the monitored namespaces are reset unless a namespace is given
+				resetCmd.namespace = ""
+			}
+			conf, err := config.RESTClientGetter.ToRESTConfig()
+			if err != nil {
+				return errors.Errorf("Error fetching kubeconfig: %s", err)
+			}
+			resetCmd.config = conf
+
+			clientset, err := kubernetes.NewForConfig(conf)
+			if err != nil {
+				return errors.Errorf("Could not access Kubernetes cluster, check kubeconfig: %s", err)
+			}
+			resetCmd.clientSet = clientset
+			resetCmd.resetCounters = func(pod *corev1.Pod) error {
+				_, err := proxyAdminRequest(resetCmd.config, resetCmd.clientSet, pod.Namespace, pod.Name, resetCmd.localPort, resetCmd.timeout, http.MethodPost, resetCountersQuery)
+				return err
+			}
+			return resetCmd.run()
+		},
+		Example: resetCountersCmdExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&resetCmd.namespace, "namespace", "n", metav1.NamespaceDefault, "Namespace of pod, or of the pods whose proxy counters are reset with --all")
+	f.BoolVar(&resetCmd.all, "all", false, "Reset the counters of the proxies of all the running meshed pods")
+	f.BoolVarP(&resetCmd.yes, "yes", "y", false, "Reset the counters of the proxies listed with --all, which are only listed otherwise")
+	f.Uint16VarP(&resetCmd.localPort, "local-port", "p", constants.EnvoyAdminPort, "Local port to use for port forwarding")
+	addProxyAdminTimeoutFlag(f, &resetCmd.timeout, "timeout")
+
+	return cmd
+}
+
+func (cmd *proxyResetCountersCmd) run() error {
+	if cmd.all {
+		return cmd.resetAllCounters()
+	}
+
+	pod, err := getRunningMeshedPod(cmd.clientSet, cmd.namespace, cmd.pod)
+	if err != nil {
+		return err
+	}
+	if err := cmd.resetCounters(pod); err != nil {
+		return annotateErrMsgWithPodNamespaceMsg("Error resetting the counters of the proxy for pod %s in namespace %s: %s", cmd.pod, cmd.namespace, err)
+	}
+
+	fmt.Fprintf(cmd.out, "Reset the counters of the proxy for pod %s in namespace %s\n", cmd.pod, cmd.namespace)
+	return nil
+}
+
+// resetAllCounters resets the counters of the proxies of all the running meshed pods, once confirmed with --yes
+func (cmd *proxyResetCountersCmd) resetAllCounters() error {
+	meshedPods, err := listMeshedPods(cmd.clientSet, cmd.namespace)
+	if err != nil {
+		return err
+	}
+
+	var pods []corev1.Pod
+	for _, pod := range meshedPods {
+		if pod.Status.Phase != corev1.PodRunning {
+			fmt.Fprintf(cmd.out, "Skipping pod %s/%s, which is not running\n", pod.Namespace, pod.Name)
+			continue
+		}
+		pods = append(pods, pod)
+	}
+	if len(pods) == 0 {
+		fmt.Fprintln(cmd.out, "No running meshed pods found")
+		return nil
+	}
+
+	if !cmd.yes {
+		fmt.Fprintf(cmd.out, "Found %d proxies whose counters would be reset:\n", len(pods))
+		for _, pod := range pods {
+			fmt.Fprintf(cmd.out, "    %s/%s\n", pod.Namespace, pod.Name)
+		}
+		fmt.Fprintln(cmd.out, "No counters were reset, use the flag --yes to reset the counters of these proxies")
+		return nil
+	}
+
+	var reset int
+	var failed []string
+	for i := range pods {
+		pod := &pods[i]
+		if err := cmd.resetCounters(pod); err != nil {
+			failed = append(failed, fmt.Sprintf("%s/%s: %s", pod.Namespace, pod.Name, err))
+			continue
+		}
+		reset++
+	}
+	fmt.Fprintf(cmd.out, "Reset the counters of %d of %d proxies\n", reset, len(pods))
+
+	if len(failed) > 0 {
+		return errors.Errorf("Error resetting the counters of %d proxies:\n%s", len(failed), strings.Join(failed, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestProxyResetCounters(t *testing.T) {
+	pendingPod := newTestPod("bookstore", "bookstore-v2", "", true)
+	pendingPod.Status.Phase = corev1.PodPending
+	objects := []runtime.Object{
+		newNamespace("bookstore", nil),
+		newNamespace("bookbuyer", nil),
+		newTestPod("bookstore", "bookstore-v1", "", true),
+		pendingPod,
+		newTestPod("bookstore", "unmeshed", "", false),
+		newTestPod("bookbuyer", "bookbuyer", "", true),
+	}
+
+	testCases := []struct {
+		name           string
+		namespace      string
+		pod            string
+		all            bool
+		yes            bool
+		failingPods    map[string]bool
+		expectedReset  []string
+		expectedOutput string
+		expectedErr    string
+	}{
+		{
+			name:           "counters of a meshed pod are reset",
+			namespace:      "bookstore",
+			pod:            "bookstore-v1",
+			expectedReset:  []string{"bookstore/bookstore-v1"},
+			expectedOutput: "Reset the counters of the proxy for pod bookstore-v1 in namespace bookstore\n",
+		},
+		{
+			name:        "pod is not meshed",
+			namespace:   "bookstore",
+			pod:         "unmeshed",
+			expectedErr: "Pod unmeshed in namespace bookstore is not a part of a mesh",
+		},
+		{
+			name:        "pod is not running",
+			namespace:   "bookstore",
+			pod:         "bookstore-v2",
+			expectedErr: "Pod bookstore-v2 in namespace bookstore is not running",
+		},
+		{
+			name:        "admin endpoint returns an error",
+			namespace:   "bookstore",
+			pod:         "bookstore-v1",
+			failingPods: map[string]bool{"bookstore/bookstore-v1": true},
+			expectedErr: "Error resetting the counters of the proxy for pod bookstore-v1 in namespace bookstore: Error fetching url http://localhost:15000/reset_counters: 503 Service Unavailable",
+		},
+		{
+			name: "proxies of all the monitored namespaces are only listed without --yes",
+			all:  true,
+			expectedOutput: "Skipping pod bookstore/bookstore-v2, which is not running\n" +
+				"Found 2 proxies whose counters would be reset:\n" +
+				"    bookbuyer/bookbuyer\n" +
+				"    bookstore/bookstore-v1\n" +
+				"No counters were reset, use the flag --yes to reset the counters of these proxies\n",
+		},
+		{
+			name:          "counters of the proxies of all the monitored namespaces are reset",
+			all:           true,
+			yes:           true,
+			expectedReset: []string{"bookbuyer/bookbuyer", "bookstore/bookstore-v1"},
+			expectedOutput: "Skipping pod bookstore/bookstore-v2, which is not running\n" +
+				"Reset the counters of 2 of 2 proxies\n",
+		},
+		{
+			name:           "counters of the proxies of a namespace are reset",
+			namespace:      "bookbuyer",
+			all:            true,
+			yes:            true,
+			expectedReset:  []string{"bookbuyer/bookbuyer"},
+			expectedOutput: "Reset the counters of 1 of 1 proxies\n",
+		},
+		{
+			name:          "counters of the other proxies are reset when a proxy fails",
+			all:           true,
+			yes:           true,
+			failingPods:   map[string]bool{"bookbuyer/bookbuyer": true},
+			expectedReset: []string{"bookstore/bookstore-v1"},
+			expectedOutput: "Skipping pod bookstore/bookstore-v2, which is not running\n" +
+				"Reset the counters of 1 of 2 proxies\n",
+			expectedErr: "Error resetting the counters of 1 proxies:\nbookbuyer/bookbuyer: Error fetching url http://localhost:15000/reset_counters: 503 Service Unavailable",
+		},
+		{
+			name:           "no running meshed pods",
+			namespace:      "osm-system",
+			all:            true,
+			yes:            true,
+			expectedOutput: "No running meshed pods found\n",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+			out := new(bytes.Buffer)
+			var reset []string
+
+			cmd := &proxyResetCountersCmd{
+				out:       out,
+				clientSet: fake.NewSimpleClientset(objects...),
+				namespace: tc.namespace,
+				pod:       tc.pod,
+				all:       tc.all,
+				yes:       tc.yes,
+				resetCounters: func(pod *corev1.Pod) error {
+					name := pod.Namespace + namespaceSeparator + pod.Name
+					if tc.failingPods[name] {
+						return errors.New("Error fetching url http://localhost:15000/reset_counters: 503 Service Unavailable")
+					}
+					reset = append(reset, name)
+					return nil
+				},
+			}
+
+			err := cmd.run()
+			if tc.expectedErr != "" {
+				assert.NotNil(err)
+				assert.Contains(err.Error(), tc.expectedErr)
+			} else {
+				assert.Nil(err)
+			}
+			assert.Equal(tc.expectedReset, reset)
+			assert.Equal(tc.expectedOutput, out.String())
+		})
+	}
+}
@@ -0,0 +1,327 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/action"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+	k8s "github.com/openservicemesh/osm/pkg/kubernetes"
+)
+
+const rotateBootstrapCmdDescription = `
+This command regenerates the bootstrap config of the Envoy proxy sidecar of
+the given pod and restarts the proxy to load it, without restarting the pod.
+
+The bootstrap config secret of the proxy is located using the osm-proxy-uuid
+label of the pod. The OSM sidecar injector issues a new certificate for the
+proxy to connect to the control plane and updates the secret. Once the updated
+bootstrap config is mounted in the pod, the proxy container is restarted.
+
+The request to the sidecar injector is port forwarded to the injector pod and
+authenticated with the bearer token of the kubeconfig, e.g. a token or one
+returned by an exec credential plugin. The user must be allowed to update the
+secrets of the namespace of the pod, which must be monitored by the mesh.
+`
+
+const rotateBootstrapCmdExample = `
+# Regenerate the bootstrap config of the proxy for the given pod 'bookbuyer-5ccf77f46d-rc5mg' in the 'bookbuyer' namespace
+osm proxy rotate-bootstrap bookbuyer-5ccf77f46d-rc5mg -n bookbuyer
+`
+
+const (
+	// injectorServiceName is the name of the service of the OSM sidecar injector
+	injectorServiceName = "osm-injector"
+
+	// injectorLabel is the value of the app label of the pods of the OSM sidecar injector
+	injectorLabel = "osm-injector"
+
+	// envoyBootstrapConfigKey is the key of the bootstrap config in the bootstrap config secret
+	envoyBootstrapConfigKey = "bootstrap.yaml"
+
+	// envoyConfigPathArg is the arg of the Envoy sidecar followed by the path of its bootstrap config file
+	envoyConfigPathArg = "--config-path"
+
+	// bootstrapMountPollInterval is the interval at which the bootstrap config mounted in the Envoy sidecar is checked
+	bootstrapMountPollInterval = 5 * time.Second
+)
+
+type proxyRotateBootstrapCmd struct {
+	out               io.Writer
+	config            *rest.Config
+	clientSet         kubernetes.Interface
+	namespace         string
+	pod               string
+	meshName          string
+	localPort         uint16
+	injectorLocalPort uint16
+	adminTimeout      time.Duration
+	timeout           time.Duration
+	pollInterval      time.Duration
+
+	// rotateBootstrap requests the given OSM sidecar injector pod to regenerate the bootstrap config of the proxy of
+	// the given pod
+	rotateBootstrap func(injectorPod, pod *corev1.Pod) ([]byte, error)
+	// readMountedBootstrap returns the bootstrap config mounted in the Envoy sidecar of the given pod
+	readMountedBootstrap func(pod *corev1.Pod) ([]byte, error)
+	// restartProxy restarts the Envoy sidecar of the given pod
+	restartProxy func(pod *corev1.Pod) error
+}
+
+func newProxyRotateBootstrapCmd(config *action.Configuration, out io.Writer) *cobra.Command {
+	rotateCmd := &proxyRotateBootstrapCmd{
+		out:          out,
+		pollInterval: bootstrapMountPollInterval,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "rotate-bootstrap POD",
+		Short: "regenerate the bootstrap config of a proxy",
+		Long:  rotateBootstrapCmdDescription,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			rotateCmd.pod = args[0]
+			conf, err := config.RESTClientGetter.ToRESTConfig()
+			if err != nil {
+				return errors.Errorf("Error fetching kubeconfig: %s", err)
+			}
+			rotateCmd.config = conf
+
+			clientset, err := kubernetes.NewForConfig(conf)
+			if err != nil {
+				return errors.Errorf("Could not access Kubernetes cluster, check kubeconfig: %s", err)
+			}
+			rotateCmd.clientSet = clientset
+			rotateCmd.rotateBootstrap = rotateCmd.requestBootstrapRotation
+			rotateCmd.readMountedBootstrap = func(pod *corev1.Pod) ([]byte, error) {
+				return execInContainer(rotateCmd.config, rotateCmd.clientSet, pod, constants.EnvoyContainerName, []string{"cat", getPodEnvoyConfigPath(pod)})
+			}
+			rotateCmd.restartProxy = func(pod *corev1.Pod) error {
+				_, err := proxyAdminRequest(rotateCmd.config, rotateCmd.clientSet, pod.Namespace, pod.Name, rotateCmd.localPort, rotateCmd.adminTimeout, http.MethodPost, "quitquitquit")
+				return err
+			}
+			return rotateCmd.run()
+		},
+		Example: rotateBootstrapCmdExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&rotateCmd.namespace, "namespace", "n", metav1.NamespaceDefault, "Namespace of pod")
+	f.StringVar(&rotateCmd.meshName, "mesh-name", defaultMeshName, "Name of the mesh whose sidecar injector regenerates the bootstrap config")
+	f.Uint16VarP(&rotateCmd.localPort, "local-port", "p", constants.EnvoyAdminPort, "Local port to use for port forwarding")
+	f.Uint16Var(&rotateCmd.injectorLocalPort, "injector-local-port", constants.InjectorWebhookPort, "Local port to use for port forwarding to the sidecar injector")
+	f.DurationVar(&rotateCmd.timeout, "timeout", 3*time.Minute, "Time to wait for the regenerated bootstrap config to be mounted in the pod")
+	addProxyAdminTimeoutFlag(f, &rotateCmd.adminTimeout, "admin-timeout")
+
+	return cmd
+}
+
+func (cmd *proxyRotateBootstrapCmd) run() error {
+	pod, err := getRunningMeshedPod(cmd.clientSet, cmd.namespace, cmd.pod)
+	if err != nil {
+		return err
+	}
+
+	secretName := constants.EnvoyBootstrapConfigSecretPrefix + pod.Labels[constants.EnvoyUniqueIDLabelName]
+	if _, err := cmd.clientSet.CoreV1().Secrets(cmd.namespace).Get(context.TODO(), secretName, metav1.GetOptions{}); apierrors.IsNotFound(err) {
+		return annotateErrMsgWithPodNamespaceMsg("Bootstrap config secret %s of pod %s in namespace %s not found", secretName, cmd.pod, cmd.namespace)
+	} else if err != nil {
+		return errors.Errorf("Error fetching bootstrap config secret %s in namespace %s: %s", secretName, cmd.namespace, err)
+	}
+
+	injectorPod, err := getRunningInjectorPod(cmd.clientSet)
+	if err != nil {
+		return errors.Errorf("Could not reach the OSM sidecar injector in namespace %s to regenerate the bootstrap config of pod %s in namespace %s, make sure the OSM control plane is running: %s",
+			settings.Namespace(), cmd.pod, cmd.namespace, err)
+	}
+
+	response, err := cmd.rotateBootstrap(injectorPod, pod)
+	if err != nil {
+		return errors.Errorf("Error regenerating the bootstrap config of pod %s in namespace %s: %s", cmd.pod, cmd.namespace, err)
+	}
+	fmt.Fprint(cmd.out, string(response))
+
+	secret, err := cmd.clientSet.CoreV1().Secrets(cmd.namespace).Get(context.TODO(), secretName, metav1.GetOptions{})
+	if err != nil {
+		return errors.Errorf("Error fetching bootstrap config secret %s in namespace %s: %s", secretName, cmd.namespace, err)
+	}
+	bootstrap := secret.Data[envoyBootstrapConfigKey]
+
+	// The kubelet periodically syncs the secret volumes of the pod, wait for the regenerated bootstrap config to be
+	// mounted before restarting the proxy so it is loaded on startup
+	fmt.Fprintf(cmd.out, "Waiting for the regenerated bootstrap config to be mounted in pod %s in namespace %s\n", cmd.pod, cmd.namespace)
+	err = wait.PollImmediate(cmd.pollInterval, cmd.timeout, func() (bool, error) {
+		mounted, err := cmd.readMountedBootstrap(pod)
+		if err != nil {
+			return false, errors.Errorf("Error reading the bootstrap config mounted in pod %s in namespace %s: %s", cmd.pod, cmd.namespace, err)
+		}
+		return bytes.Equal(mounted, bootstrap), nil
+	})
+	if err == wait.ErrWaitTimeout {
+		return errors.Errorf("Timed out after %s waiting for the regenerated bootstrap config to be mounted in pod %s in namespace %s, restart the pod to load it",
+			cmd.timeout, cmd.pod, cmd.namespace)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := cmd.restartProxy(pod); err != nil {
+		return errors.Errorf("Error restarting the proxy of pod %s in namespace %s: %s", cmd.pod, cmd.namespace, err)
+	}
+	fmt.Fprintf(cmd.out, "Restarted the proxy of pod %s in namespace %s to load the regenerated bootstrap config\n", cmd.pod, cmd.namespace)
+	return nil
+}
+
+// requestBootstrapRotation requests the given OSM sidecar injector pod to regenerate the bootstrap config of the
+// proxy of the given pod, and returns the response of the injector. The request is port forwarded to the injector pod,
+// as the Kubernetes API server proxy to the injector service does not forward the credentials of the user. The
+// certificate of the injector is verified with the CA bundle of the MutatingWebhookConfiguration of the mesh.
+func (cmd *proxyRotateBootstrapCmd) requestBootstrapRotation(injectorPod, pod *corev1.Pod) ([]byte, error) {
+	caBundle, err := getInjectorCABundle(cmd.clientSet, cmd.meshName)
+	if err != nil {
+		return nil, err
+	}
+	rootCAs := x509.NewCertPool()
+	if !rootCAs.AppendCertsFromPEM(caBundle) {
+		return nil, errors.Errorf("Invalid CA bundle in MutatingWebhookConfiguration %s-%s", webhookConfigNamePrefix, cmd.meshName)
+	}
+	transport, err := rest.HTTPWrappersForConfig(cmd.config, &http.Transport{
+		TLSClientConfig: &tls.Config{
+			RootCAs:    rootCAs,
+			ServerName: fmt.Sprintf("%s.%s.svc", injectorServiceName, settings.Namespace()),
+			MinVersion: tls.VersionTLS12,
+		},
+	})
+	if err != nil {
+		return nil, errors.Errorf("Error setting up the credentials of the request: %s", err)
+	}
+
+	dialer, err := k8s.DialerToPod(cmd.config, cmd.clientSet, injectorPod.Name, injectorPod.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	portForwarder, err := k8s.NewPortForwarder(dialer, fmt.Sprintf("%d:%d", cmd.injectorLocalPort, constants.InjectorWebhookPort))
+	if err != nil {
+		return nil, errors.Errorf("Error setting up port forwarding: %s", err)
+	}
+
+	var body []byte
+	err = portForwarder.StartWithTimeout(cmd.adminTimeout, func(pf *k8s.PortForwarder) error {
+		defer pf.Stop()
+		query := url.Values{"namespace": []string{pod.Namespace}, "pod": []string{pod.Name}}
+		req, err := http.NewRequest(http.MethodPost, fmt.Sprintf("https://localhost:%d%s?%s", cmd.injectorLocalPort, constants.InjectorBootstrapRotationPath, query.Encode()), nil)
+		if err != nil {
+			return errors.Errorf("Error creating bootstrap rotation request: %s", err)
+		}
+		resp, err := (&http.Client{Transport: transport, Timeout: cmd.adminTimeout}).Do(req)
+		if err != nil {
+			return errors.Errorf("Error requesting the bootstrap rotation: %s", err)
+		}
+		defer resp.Body.Close() //nolint: errcheck
+		body, err = ioutil.ReadAll(resp.Body)
+		if err != nil {
+			return errors.Errorf("Error reading the bootstrap rotation response: %s", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return errors.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return body, nil
+}
+
+// getRunningInjectorPod returns a running pod of the OSM sidecar injector in the OSM namespace
+func getRunningInjectorPod(clientSet kubernetes.Interface) (*corev1.Pod, error) {
+	labelSelector := metav1.LabelSelector{MatchLabels: map[string]string{"app": injectorLabel}}
+	pods, err := clientSet.CoreV1().Pods(settings.Namespace()).List(context.TODO(), metav1.ListOptions{LabelSelector: labels.Set(labelSelector.MatchLabels).String()})
+	if err != nil {
+		return nil, errors.Errorf("Error listing the sidecar injector pods: %s", err)
+	}
+	for i := range pods.Items {
+		if pods.Items[i].Status.Phase == corev1.PodRunning {
+			return &pods.Items[i], nil
+		}
+	}
+	return nil, errors.Errorf("No running sidecar injector pod")
+}
+
+// getInjectorCABundle returns the CA bundle of the sidecar injector webhook of the given mesh
+func getInjectorCABundle(clientSet kubernetes.Interface, meshName string) ([]byte, error) {
+	webhookConfigName := fmt.Sprintf("%s-%s", webhookConfigNamePrefix, meshName)
+	webhookConfig, err := clientSet.AdmissionregistrationV1().MutatingWebhookConfigurations().Get(context.TODO(), webhookConfigName, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Errorf("Error fetching MutatingWebhookConfiguration %s: %s", webhookConfigName, err)
+	}
+	for _, webhook := range webhookConfig.Webhooks {
+		if webhook.Name == sidecarInjectorWebhookName && len(webhook.ClientConfig.CABundle) > 0 {
+			return webhook.ClientConfig.CABundle, nil
+		}
+	}
+	return nil, errors.Errorf("MutatingWebhookConfiguration %s has no CA bundle for webhook %s", webhookConfigName, sidecarInjectorWebhookName)
+}
+
+// execInContainer runs the given command in the given container of the pod and returns its standard output
+func execInContainer(config *rest.Config, clientSet kubernetes.Interface, pod *corev1.Pod, container string, command []string) ([]byte, error) {
+	req := clientSet.CoreV1().RESTClient().Post().
+		Namespace(pod.Namespace).
+		Resource("pods").
+		Name(pod.Name).
+		SubResource("exec").
+		VersionedParams(&corev1.PodExecOptions{
+			Container: container,
+			Command:   command,
+			Stdout:    true,
+			Stderr:    true,
+		}, scheme.ParameterCodec)
+
+	executor, err := remotecommand.NewSPDYExecutor(config, http.MethodPost, req.URL())
+	if err != nil {
+		return nil, errors.Errorf("Error creating executor for container %s: %s", container, err)
+	}
+
+	var stdout, stderr bytes.Buffer
+	if err := executor.Stream(remotecommand.StreamOptions{Stdout: &stdout, Stderr: &stderr}); err != nil {
+		return nil, errors.Errorf("Error running %v in container %s: %s: %s", command, container, err, stderr.String())
+	}
+	return stdout.Bytes(), nil
+}
+
+// getPodEnvoyConfigPath returns the path of the bootstrap config file passed to the Envoy sidecar of the given pod,
+// which depends on the Envoy config path configured when the pod was injected, or the default path if not found
+func getPodEnvoyConfigPath(pod *corev1.Pod) string {
+	for _, container := range pod.Spec.Containers {
+		if container.Name != constants.EnvoyContainerName {
+			continue
+		}
+		for i, arg := range container.Args {
+			if arg == envoyConfigPathArg && i+1 < len(container.Args) {
+				return container.Args[i+1]
+			}
+		}
+	}
+	return constants.EnvoyConfigPath
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	tassert "github.com/stretchr/testify/assert"
+	admissionregv1 "k8s.io/api/admissionregistration/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+func TestProxyRotateBootstrap(t *testing.T) {
+	const (
+		namespace = "bookstore"
+		proxyUUID = "0b4f7c3e-5a4e-4a8e-9f4b-2f1d3c6b7a90"
+	)
+	secretName := constants.EnvoyBootstrapConfigSecretPrefix + proxyUUID
+
+	meshedPod := newTestPod(namespace, "meshed", "meshed", true)
+	meshedPod.Labels[constants.EnvoyUniqueIDLabelName] = proxyUUID
+	unmeshedPod := newTestPod(namespace, "unmeshed", "unmeshed", false)
+	injectorPod := newTestPod(settings.Namespace(), "osm-injector", "osm", false)
+	injectorPod.Labels = map[string]string{"app": injectorLabel}
+	bootstrapSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: namespace},
+		Data:       map[string][]byte{"bootstrap.yaml": []byte("old bootstrap")},
+	}
+
+	testCases := []struct {
+		name              string
+		pod               string
+		objects           []runtime.Object
+		rotateErr         error
+		mountedBootstraps []string
+		expectedErr       string
+		expectedOutput    string
+		expectRestart     bool
+	}{
+		{
+			name:              "bootstrap config is regenerated and the proxy restarted once it is mounted",
+			pod:               "meshed",
+			objects:           []runtime.Object{meshedPod, bootstrapSecret, injectorPod},
+			mountedBootstraps: []string{"old bootstrap", "new bootstrap"},
+			expectedOutput: fmt.Sprintf("Regenerated bootstrap config secret %s/%s\n", namespace, secretName) +
+				"Waiting for the regenerated bootstrap config to be mounted in pod meshed in namespace bookstore\n" +
+				"Restarted the proxy of pod meshed in namespace bookstore to load the regenerated bootstrap config\n",
+			expectRestart: true,
+		},
+		{
+			name:        "pod is not meshed",
+			pod:         "unmeshed",
+			objects:     []runtime.Object{unmeshedPod, bootstrapSecret},
+			expectedErr: "Pod unmeshed in namespace bookstore is not a part of a mesh",
+		},
+		{
+			name:        "bootstrap config secret does not exist",
+			pod:         "meshed",
+			objects:     []runtime.Object{meshedPod},
+			expectedErr: fmt.Sprintf("Bootstrap config secret %s of pod meshed in namespace bookstore not found", secretName),
+		},
+		{
+			name:        "sidecar injector is not reachable",
+			pod:         "meshed",
+			objects:     []runtime.Object{meshedPod, bootstrapSecret},
+			expectedErr: "Could not reach the OSM sidecar injector",
+		},
+		{
+			name:        "sidecar injector fails to regenerate the bootstrap config",
+			pod:         "meshed",
+			objects:     []runtime.Object{meshedPod, bootstrapSecret, injectorPod},
+			rotateErr:   errors.New("500 Internal Server Error: error issuing certificate"),
+			expectedErr: "Error regenerating the bootstrap config of pod meshed in namespace bookstore",
+		},
+		{
+			name:              "regenerated bootstrap config is not mounted in time",
+			pod:               "meshed",
+			objects:           []runtime.Object{meshedPod, bootstrapSecret, injectorPod},
+			mountedBootstraps: []string{"old bootstrap"},
+			expectedErr:       "Timed out after 50ms waiting for the regenerated bootstrap config to be mounted",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+			clientSet := fake.NewSimpleClientset(tc.objects...)
+			out := new(bytes.Buffer)
+			restarted := false
+			mountReads := 0
+
+			cmd := &proxyRotateBootstrapCmd{
+				out:          out,
+				clientSet:    clientSet,
+				namespace:    namespace,
+				pod:          tc.pod,
+				timeout:      50 * time.Millisecond,
+				pollInterval: 10 * time.Millisecond,
+				rotateBootstrap: func(injector, pod *corev1.Pod) ([]byte, error) {
+					if tc.rotateErr != nil {
+						return nil, tc.rotateErr
+					}
+					secret := bootstrapSecret.DeepCopy()
+					secret.Data["bootstrap.yaml"] = []byte("new bootstrap")
+					if _, err := clientSet.CoreV1().Secrets(namespace).Update(context.TODO(), secret, metav1.UpdateOptions{}); err != nil {
+						return nil, err
+					}
+					return []byte(fmt.Sprintf("Regenerated bootstrap config secret %s/%s\n", namespace, secretName)), nil
+				},
+				readMountedBootstrap: func(pod *corev1.Pod) ([]byte, error) {
+					mounted := tc.mountedBootstraps[len(tc.mountedBootstraps)-1]
+					if mountReads < len(tc.mountedBootstraps) {
+						mounted = tc.mountedBootstraps[mountReads]
+					}
+					mountReads++
+					return []byte(mounted), nil
+				},
+				restartProxy: func(pod *corev1.Pod) error {
+					restarted = true
+					return nil
+				},
+			}
+
+			err := cmd.run()
+			if tc.expectedErr != "" {
+				assert.NotNil(err)
+				assert.Contains(err.Error(), tc.expectedErr)
+			} else {
+				assert.Nil(err)
+				assert.Equal(tc.expectedOutput, out.String())
+			}
+			assert.Equal(tc.expectRestart, restarted)
+		})
+	}
+}
+
+func TestGetInjectorCABundle(t *testing.T) {
+	assert := tassert.New(t)
+
+	webhookConfig := &admissionregv1.MutatingWebhookConfiguration{
+		ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("%s-%s", webhookConfigNamePrefix, defaultMeshName)},
+		Webhooks: []admissionregv1.MutatingWebhook{
+			{Name: sidecarInjectorWebhookName, ClientConfig: admissionregv1.WebhookClientConfig{CABundle: []byte("ca")}},
+		},
+	}
+
+	caBundle, err := getInjectorCABundle(fake.NewSimpleClientset(webhookConfig), defaultMeshName)
+	assert.Nil(err)
+	assert.Equal([]byte("ca"), caBundle)
+
+	_, err = getInjectorCABundle(fake.NewSimpleClientset(webhookConfig), "other-mesh")
+	assert.NotNil(err)
+
+	webhookConfig.Webhooks[0].ClientConfig.CABundle = nil
+	_, err = getInjectorCABundle(fake.NewSimpleClientset(webhookConfig), defaultMeshName)
+	assert.NotNil(err)
+}
+
+func TestGetPodEnvoyConfigPath(t *testing.T) {
+	assert := tassert.New(t)
+
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{Name: "bookstore", Args: []string{"--config-path", "/app/config.yaml"}},
+				{Name: constants.EnvoyContainerName, Args: []string{"--log-level", "error", "--config-path", "/config/envoy.yaml"}},
+			},
+		},
+	}
+	assert.Equal("/config/envoy.yaml", getPodEnvoyConfigPath(pod))
+
+	pod.Spec.Containers[1].Args = []string{"--log-level", "error"}
+	assert.Equal(constants.EnvoyConfigPath, getPodEnvoyConfigPath(pod))
+}
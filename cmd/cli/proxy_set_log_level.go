@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/action"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+const setLogLevelCmdDescription = `
+This command changes the log level of the Envoy proxy sidecar of the given
+pod at runtime, without restarting the pod or the proxy.
+
+The log level of all the loggers of the proxy is changed, or only the log
+level of the logger given with --logger. The levels of the loggers active
+once the change is applied are printed.
+
+The change is made through the /logging endpoint of the Envoy admin interface
+and is not persisted: the log level configured for the mesh is restored when
+the proxy restarts.
+`
+
+const setLogLevelCmdExample = `
+# Set the log level of the proxy for the given pod 'bookbuyer-5ccf77f46d-rc5mg' in the 'bookbuyer' namespace to debug
+osm proxy set-log-level bookbuyer-5ccf77f46d-rc5mg debug -n bookbuyer
+
+# Only set the log level of the 'http' logger of the proxy to trace
+osm proxy set-log-level bookbuyer-5ccf77f46d-rc5mg trace -n bookbuyer --logger http
+`
+
+// loggingQuery is the Envoy admin query changing the log levels of the proxy
+const loggingQuery = "logging"
+
+type proxySetLogLevelCmd struct {
+	out       io.Writer
+	config    *rest.Config
+	clientSet kubernetes.Interface
+	namespace string
+	pod       string
+	level     string
+	logger    string
+	localPort uint16
+	timeout   time.Duration
+}
+
+// proxyLogger is a logger of a proxy along with its active log level
+type proxyLogger struct {
+	name  string
+	level string
+}
+
+func newProxySetLogLevelCmd(config *action.Configuration, out io.Writer) *cobra.Command {
+	setLogLevelCmd := &proxySetLogLevelCmd{
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "set-log-level POD LEVEL",
+		Short: "set the log level of a proxy",
+		Long:  setLogLevelCmdDescription,
+		Args:  cobra.ExactArgs(2),
+		RunE: func(_ *cobra.Command, args []string) error {
+			setLogLevelCmd.pod = args[0]
+			setLogLevelCmd.level = args[1]
+			conf, err := config.RESTClientGetter.ToRESTConfig()
+			if err != nil {
+				return errors.Errorf("Error fetching kubeconfig: %s", err)
+			}
+			setLogLevelCmd.config = conf
+
+			clientset, err := kubernetes.NewForConfig(conf)
+			if err != nil {
+				return errors.Errorf("Could not access Kubernetes cluster, check kubeconfig: %s", err)
+			}
+			setLogLevelCmd.clientSet = clientset
+			return setLogLevelCmd.run()
+		},
+		Example: setLogLevelCmdExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&setLogLevelCmd.namespace, "namespace", "n", metav1.NamespaceDefault, "Namespace of pod")
+	f.StringVar(&setLogLevelCmd.logger, "logger", "", "Name of the logger whose log level is set, the log level of all the loggers is set if unset")
+	f.Uint16VarP(&setLogLevelCmd.localPort, "local-port", "p", constants.EnvoyAdminPort, "Local port to use for port forwarding")
+	addProxyAdminTimeoutFlag(f, &setLogLevelCmd.timeout, "timeout")
+
+	return cmd
+}
+
+func (cmd *proxySetLogLevelCmd) run() error {
+	query, err := getLoggingQuery(cmd.level, cmd.logger)
+	if err != nil {
+		return err
+	}
+
+	if _, err := getRunningMeshedPod(cmd.clientSet, cmd.namespace, cmd.pod); err != nil {
+		return err
+	}
+
+	activeLoggers, err := proxyAdminRequest(cmd.config, cmd.clientSet, cmd.namespace, cmd.pod, cmd.localPort, cmd.timeout, http.MethodPost, query)
+	if err != nil {
+		return annotateErrMsgWithPodNamespaceMsg("Error setting the log level of the proxy for pod %s in namespace %s: %s", cmd.pod, cmd.namespace, err)
+	}
+
+	if cmd.logger != "" {
+		fmt.Fprintf(cmd.out, "Log level of logger %s of the proxy for pod %s in namespace %s set to %s\n", cmd.logger, cmd.pod, cmd.namespace, cmd.level)
+	} else {
+		fmt.Fprintf(cmd.out, "Log level of the proxy for pod %s in namespace %s set to %s\n", cmd.pod, cmd.namespace, cmd.level)
+	}
+	printProxyLoggers(cmd.out, parseProxyLoggers(activeLoggers))
+	return nil
+}
+
+// getLoggingQuery validates the given log level and logger name, and returns the Envoy admin query setting the log
+// level of the given logger, or of all the loggers when no logger is given
+func getLoggingQuery(level, logger string) (string, error) {
+	validLevel := false
+	for _, validEnvoyLogLevel := range configurator.ValidEnvoyLogLevels {
+		validLevel = validLevel || level == validEnvoyLogLevel
+	}
+	if !validLevel {
+		return "", errors.Errorf("Invalid log level %q, expected one of: %s", level, strings.Join(configurator.ValidEnvoyLogLevels, ", "))
+	}
+
+	if logger == "" {
+		return fmt.Sprintf("%s?level=%s", loggingQuery, level), nil
+	}
+	if strings.ContainsAny(logger, " =&?") {
+		return "", errors.Errorf("Invalid value %q for flag --logger, must be the name of a logger of the proxy", logger)
+	}
+	return fmt.Sprintf("%s?%s=%s", loggingQuery, url.QueryEscape(logger), level), nil
+}
+
+// parseProxyLoggers returns the loggers listed in the given response of the Envoy /logging endpoint, rendered as
+// 'name: level' lines following the 'active loggers:' header
+func parseProxyLoggers(activeLoggers []byte) []proxyLogger {
+	var loggers []proxyLogger
+	scanner := bufio.NewScanner(bytes.NewReader(activeLoggers))
+	for scanner.Scan() {
+		nameLevel := strings.SplitN(scanner.Text(), ":", 2)
+		if len(nameLevel) != 2 {
+			continue
+		}
+		name, level := strings.TrimSpace(nameLevel[0]), strings.TrimSpace(nameLevel[1])
+		if name == "" || level == "" {
+			continue
+		}
+		loggers = append(loggers, proxyLogger{name: name, level: level})
+	}
+	return loggers
+}
+
+// printProxyLoggers prints the given loggers along with their active log level
+func printProxyLoggers(out io.Writer, loggers []proxyLogger) {
+	if len(loggers) == 0 {
+		return
+	}
+	fmt.Fprintln(out, "Active log levels:")
+	w := newTabWriter(out)
+	fmt.Fprintln(w, "LOGGER\tLEVEL\t")
+	for _, logger := range loggers {
+		fmt.Fprintf(w, "%s\t%s\t\n", logger.name, logger.level)
+	}
+	_ = w.Flush()
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestGetLoggingQuery(t *testing.T) {
+	testCases := []struct {
+		name          string
+		level         string
+		logger        string
+		expectedQuery string
+		expectedErr   string
+	}{
+		{
+			name:          "all loggers",
+			level:         "debug",
+			expectedQuery: "logging?level=debug",
+		},
+		{
+			name:          "single logger",
+			level:         "trace",
+			logger:        "http",
+			expectedQuery: "logging?http=trace",
+		},
+		{
+			name:        "invalid level",
+			level:       "verbose",
+			expectedErr: `Invalid log level "verbose", expected one of: trace, debug, info, warning, warn, error, critical, off`,
+		},
+		{
+			name:        "uppercase level",
+			level:       "DEBUG",
+			expectedErr: `Invalid log level "DEBUG"`,
+		},
+		{
+			name:        "invalid logger",
+			level:       "debug",
+			logger:      "http&level=off",
+			expectedErr: `Invalid value "http&level=off" for flag --logger, must be the name of a logger of the proxy`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			query, err := getLoggingQuery(tc.level, tc.logger)
+			if tc.expectedErr != "" {
+				assert.NotNil(err)
+				assert.Contains(err.Error(), tc.expectedErr)
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(tc.expectedQuery, query)
+		})
+	}
+}
+
+func TestProxySetLogLevelValidation(t *testing.T) {
+	testCases := []struct {
+		name        string
+		pod         *corev1.Pod
+		level       string
+		expectedErr string
+	}{
+		{
+			name:        "invalid level is rejected before looking up the pod",
+			level:       "verbose",
+			expectedErr: `Invalid log level "verbose"`,
+		},
+		{
+			name:        "pod not found",
+			level:       "debug",
+			expectedErr: "Could not find pod bookbuyer in namespace default",
+		},
+		{
+			name:        "pod not part of a mesh",
+			pod:         &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "bookbuyer", Namespace: metav1.NamespaceDefault}},
+			level:       "debug",
+			expectedErr: "Pod bookbuyer in namespace default is not a part of a mesh",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			clientSet := fake.NewSimpleClientset()
+			if tc.pod != nil {
+				clientSet = fake.NewSimpleClientset(tc.pod)
+			}
+			cmd := &proxySetLogLevelCmd{
+				out:       new(bytes.Buffer),
+				clientSet: clientSet,
+				namespace: metav1.NamespaceDefault,
+				pod:       "bookbuyer",
+				level:     tc.level,
+			}
+			err := cmd.run()
+			assert.NotNil(err)
+			assert.Contains(err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestPrintProxyLoggers(t *testing.T) {
+	assert := tassert.New(t)
+
+	activeLoggers := "active loggers:\n  admin: debug\n  http: trace\n  upstream: debug\n"
+	out := new(bytes.Buffer)
+	printProxyLoggers(out, parseProxyLoggers([]byte(activeLoggers)))
+	assert.Equal("Active log levels:\n"+
+		"LOGGER     LEVEL   \n"+
+		"admin      debug   \n"+
+		"http       trace   \n"+
+		"upstream   debug   \n", out.String())
+}
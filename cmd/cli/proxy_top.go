@@ -0,0 +1,344 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+const proxyTopDescription = `
+This command lists the Envoy sidecar proxies of the pods in the namespaces
+monitored by any mesh along with their current CPU and memory usage, read from
+the metrics API (metrics.k8s.io) served by metrics-server, sorted by decreasing
+usage.
+
+The usage is compared to the CPU and memory limits of the sidecar container, and
+the proxies whose usage of either resource reaches the percentage of its limit
+given with --limit-threshold are flagged as near their limit.
+
+When the metrics API is not available in the cluster, e.g. because
+metrics-server is not installed, the proxies are listed with their limits only.
+
+The proxies of a single namespace can be listed with the --namespace flag.
+
+The columns of the table can be selected with the --columns flag, among:
+namespace, pod, cpu, cpu-limit, memory, memory-limit, near-limit.
+`
+
+const proxyTopExample = `
+# List the sidecar proxies in all monitored namespaces by decreasing CPU usage
+osm proxy top
+
+# List the sidecar proxies in the 'bookstore' namespace by decreasing memory usage
+osm proxy top -n bookstore --sort-by memory
+
+# Flag the sidecar proxies using at least 90% of their CPU or memory limit
+osm proxy top --limit-threshold 90
+`
+
+const (
+	// metricsAPIPath is the path of the resource metrics API served by metrics-server
+	metricsAPIPath = "/apis/metrics.k8s.io/v1beta1"
+
+	// proxyTopSortByCPU and proxyTopSortByMemory are the supported --sort-by values
+	proxyTopSortByCPU    = "cpu"
+	proxyTopSortByMemory = "memory"
+
+	// defaultProxyTopLimitThreshold is the default percentage of its limits from which a proxy is flagged as near its limit
+	defaultProxyTopLimitThreshold = 80
+
+	bytesPerMebibyte = 1024 * 1024
+)
+
+// proxyTopColumns are the columns of the table of the resource usage of the sidecar proxies
+var proxyTopColumns = []tableColumn{
+	{name: "namespace", header: "NAMESPACE"},
+	{name: "pod", header: "POD"},
+	{name: "cpu", header: "CPU"},
+	{name: "cpu-limit", header: "CPU-LIMIT"},
+	{name: "memory", header: "MEMORY"},
+	{name: "memory-limit", header: "MEMORY-LIMIT"},
+	{name: "near-limit", header: "NEAR-LIMIT"},
+}
+
+type proxyTopCmd struct {
+	out            io.Writer
+	namespace      string
+	output         string
+	sortBy         string
+	limitThreshold int
+	columns        []string
+	clientSet      kubernetes.Interface
+
+	// getPodMetrics returns the metrics of the meshed pods in the given namespace read from the metrics API
+	getPodMetrics func(namespace string) ([]podMetrics, error)
+}
+
+// podMetricsList is a list of the metrics of pods served by the metrics API
+type podMetricsList struct {
+	Items []podMetrics `json:"items"`
+}
+
+// podMetrics holds the resource usage of the containers of a pod served by the metrics API
+type podMetrics struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	Containers        []containerMetrics `json:"containers"`
+}
+
+// containerMetrics holds the resource usage of a container served by the metrics API
+type containerMetrics struct {
+	Name  string              `json:"name"`
+	Usage corev1.ResourceList `json:"usage"`
+}
+
+// proxyUsage describes the resource usage and limits of the sidecar proxy of a pod, the values are unset when unknown
+type proxyUsage struct {
+	Namespace          string `json:"namespace"`
+	Pod                string `json:"pod"`
+	CPUMillicores      *int64 `json:"cpuMillicores,omitempty"`
+	CPULimitMillicores *int64 `json:"cpuLimitMillicores,omitempty"`
+	MemoryBytes        *int64 `json:"memoryBytes,omitempty"`
+	MemoryLimitBytes   *int64 `json:"memoryLimitBytes,omitempty"`
+	NearLimit          bool   `json:"nearLimit"`
+}
+
+func newProxyTopCmd(out io.Writer) *cobra.Command {
+	topCmd := &proxyTopCmd{
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "top",
+		Short: "list the resource usage of sidecar proxies",
+		Long:  proxyTopDescription,
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			config, err := settings.RESTClientGetter().ToRESTConfig()
+			if err != nil {
+				return errors.Errorf("Error fetching kubeconfig: %s", err)
+			}
+
+			clientset, err := kubernetes.NewForConfig(config)
+			if err != nil {
+				return errors.Errorf("Could not access Kubernetes cluster, check kubeconfig: %s", err)
+			}
+			topCmd.clientSet = clientset
+			topCmd.getPodMetrics = func(namespace string) ([]podMetrics, error) {
+				return getPodMetrics(clientset, namespace)
+			}
+			return topCmd.run()
+		},
+		Example: proxyTopExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&topCmd.namespace, "namespace", "n", "", "Namespace of the pods, all the monitored namespaces if unset")
+	f.StringVarP(&topCmd.output, "output", "o", "", "Output format, one of: json. A table is printed if unset")
+	f.StringVar(&topCmd.sortBy, "sort-by", proxyTopSortByCPU, "Resource whose usage the proxies are sorted by, one of: cpu, memory")
+	f.IntVar(&topCmd.limitThreshold, "limit-threshold", defaultProxyTopLimitThreshold, "Percentage of the CPU or memory limit of a proxy from which it is flagged as near its limit")
+	f.StringSliceVar(&topCmd.columns, "columns", nil, "Comma separated list of the columns of the table, all the columns if unset")
+
+	return cmd
+}
+
+func (t *proxyTopCmd) run() error {
+	if t.output != "" && t.output != outputFormatJSON {
+		return errors.Errorf("Invalid value %q for flag --output, expected: %s", t.output, outputFormatJSON)
+	}
+	switch t.sortBy {
+	case proxyTopSortByCPU, proxyTopSortByMemory:
+	default:
+		return errors.Errorf("Invalid value %q for flag --sort-by, expected one of: %s, %s", t.sortBy, proxyTopSortByCPU, proxyTopSortByMemory)
+	}
+	if t.limitThreshold <= 0 || t.limitThreshold > 100 {
+		return errors.Errorf("Invalid value %d for flag --limit-threshold, expected a percentage between 1 and 100", t.limitThreshold)
+	}
+	if err := validateColumns(proxyTopColumns, t.columns); err != nil {
+		return err
+	}
+
+	pods, err := listMeshedPods(t.clientSet, t.namespace)
+	if err != nil {
+		return err
+	}
+
+	usages, metricsAvailable, err := t.getProxyUsages(pods)
+	if err != nil {
+		return err
+	}
+
+	if t.output == outputFormatJSON {
+		if usages == nil {
+			usages = []proxyUsage{}
+		}
+		usagesJSON, err := json.MarshalIndent(usages, "", "  ")
+		if err != nil {
+			return errors.Errorf("Error marshaling the resource usage of the proxies: %s", err)
+		}
+		fmt.Fprintln(t.out, string(usagesJSON))
+		return nil
+	}
+
+	if len(usages) == 0 {
+		fmt.Fprintln(t.out, "No sidecar proxies found")
+		return nil
+	}
+	if !metricsAvailable {
+		fmt.Fprintf(t.out, "Warning: the metrics API (%s) is not available, install metrics-server to report the resource usage of the proxies\n\n", metricsAPIPath)
+	}
+
+	table := newTable(proxyTopColumns)
+	for _, usage := range usages {
+		table.addRow(usage.Namespace, usage.Pod,
+			formatMillicores(usage.CPUMillicores), formatMillicores(usage.CPULimitMillicores),
+			formatMebibytes(usage.MemoryBytes), formatMebibytes(usage.MemoryLimitBytes),
+			strconv.FormatBool(usage.NearLimit))
+	}
+	return table.write(t.out, t.columns)
+}
+
+// getProxyUsages returns the resource usage of the sidecar proxies of the given pods sorted by decreasing usage of the
+// resource given with --sort-by, along with whether the metrics API is available. When it is not, the usage of the
+// proxies is unset.
+func (t *proxyTopCmd) getProxyUsages(pods []corev1.Pod) ([]proxyUsage, bool, error) {
+	metricsAvailable := true
+	fetchedNamespaces := make(map[string]bool)
+	metrics := make(map[string]podMetrics)
+	for _, pod := range pods {
+		if fetchedNamespaces[pod.Namespace] || !metricsAvailable {
+			continue
+		}
+		fetchedNamespaces[pod.Namespace] = true
+
+		namespaceMetrics, err := t.getPodMetrics(pod.Namespace)
+		if apierrors.IsNotFound(err) || apierrors.IsServiceUnavailable(err) {
+			metricsAvailable = false
+			continue
+		}
+		if err != nil {
+			return nil, false, errors.Errorf("Error fetching the metrics of the pods in namespace %s: %s", pod.Namespace, err)
+		}
+		for _, m := range namespaceMetrics {
+			metrics[m.Namespace+namespaceSeparator+m.Name] = m
+		}
+	}
+
+	var usages []proxyUsage
+	for _, pod := range pods {
+		var metricsOfPod *podMetrics
+		if m, ok := metrics[pod.Namespace+namespaceSeparator+pod.Name]; ok && metricsAvailable {
+			metricsOfPod = &m
+		}
+		usages = append(usages, getProxyUsage(pod, metricsOfPod, t.limitThreshold))
+	}
+
+	usageOf := func(usage proxyUsage) *int64 {
+		if t.sortBy == proxyTopSortByMemory {
+			return usage.MemoryBytes
+		}
+		return usage.CPUMillicores
+	}
+	// The pods are sorted by namespace and name, which is preserved among the proxies with the same usage
+	sort.SliceStable(usages, func(i, j int) bool {
+		a, b := usageOf(usages[i]), usageOf(usages[j])
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return *a > *b
+	})
+	return usages, metricsAvailable, nil
+}
+
+// getProxyUsage returns the resource usage of the sidecar proxy of the given pod, given its metrics if known, where the
+// proxy is near its limit when its usage of CPU or memory reaches the given percentage of the limit
+func getProxyUsage(pod corev1.Pod, metrics *podMetrics, limitThreshold int) proxyUsage {
+	usage := proxyUsage{
+		Namespace: pod.Namespace,
+		Pod:       pod.Name,
+	}
+	for _, container := range pod.Spec.Containers {
+		if container.Name != constants.EnvoyContainerName {
+			continue
+		}
+		if limit, ok := container.Resources.Limits[corev1.ResourceCPU]; ok {
+			usage.CPULimitMillicores = int64Ptr(limit.MilliValue())
+		}
+		if limit, ok := container.Resources.Limits[corev1.ResourceMemory]; ok {
+			usage.MemoryLimitBytes = int64Ptr(limit.Value())
+		}
+	}
+	if metrics != nil {
+		for _, container := range metrics.Containers {
+			if container.Name != constants.EnvoyContainerName {
+				continue
+			}
+			if cpu, ok := container.Usage[corev1.ResourceCPU]; ok {
+				usage.CPUMillicores = int64Ptr(cpu.MilliValue())
+			}
+			if memory, ok := container.Usage[corev1.ResourceMemory]; ok {
+				usage.MemoryBytes = int64Ptr(memory.Value())
+			}
+		}
+	}
+	usage.NearLimit = isNearLimit(usage.CPUMillicores, usage.CPULimitMillicores, limitThreshold) ||
+		isNearLimit(usage.MemoryBytes, usage.MemoryLimitBytes, limitThreshold)
+	return usage
+}
+
+// isNearLimit returns whether the given usage reaches the given percentage of the given limit, both being known
+func isNearLimit(usage, limit *int64, threshold int) bool {
+	if usage == nil || limit == nil || *limit == 0 {
+		return false
+	}
+	return *usage*100 >= *limit*int64(threshold)
+}
+
+// getPodMetrics returns the metrics of the meshed pods in the given namespace read from the metrics API. A NotFound or
+// ServiceUnavailable error is returned when the metrics API is not served in the cluster.
+func getPodMetrics(clientSet kubernetes.Interface, namespace string) ([]podMetrics, error) {
+	data, err := clientSet.Discovery().RESTClient().Get().
+		AbsPath(metricsAPIPath, "namespaces", namespace, "pods").
+		Param("labelSelector", constants.EnvoyUniqueIDLabelName).
+		DoRaw(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+
+	var metrics podMetricsList
+	if err := json.Unmarshal(data, &metrics); err != nil {
+		return nil, errors.Errorf("Error decoding the metrics of the pods in namespace %s: %s", namespace, err)
+	}
+	return metrics.Items, nil
+}
+
+// formatMillicores returns the given CPU millicores as printed by 'kubectl top', or - when unknown
+func formatMillicores(millicores *int64) string {
+	if millicores == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%dm", *millicores)
+}
+
+// formatMebibytes returns the given bytes in mebibytes as printed by 'kubectl top', or - when unknown
+func formatMebibytes(bytes *int64) string {
+	if bytes == nil {
+		return "-"
+	}
+	return fmt.Sprintf("%dMi", *bytes/bytesPerMebibyte)
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+func TestProxyTop(t *testing.T) {
+	newPodMetrics := func(namespace, name, cpu, memory string) podMetrics {
+		return podMetrics{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Containers: []containerMetrics{
+				{Name: "app", Usage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("1Gi")}},
+				{Name: constants.EnvoyContainerName, Usage: corev1.ResourceList{corev1.ResourceCPU: resource.MustParse(cpu), corev1.ResourceMemory: resource.MustParse(memory)}},
+			},
+		}
+	}
+
+	var pods []runtime.Object
+	for _, limits := range []struct{ name, cpu, memory string }{
+		{name: "bookstore-v1", cpu: "100m", memory: "128Mi"},
+		{name: "bookstore-v2"},
+		{name: "bookstore-v3", cpu: "1", memory: "512Mi"},
+	} {
+		sidecar := corev1.Container{Name: constants.EnvoyContainerName}
+		if limits.cpu != "" {
+			sidecar.Resources.Limits = corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse(limits.cpu),
+				corev1.ResourceMemory: resource.MustParse(limits.memory),
+			}
+		}
+		pod := newTestPod("bookstore", limits.name, "", true)
+		pod.Spec.Containers = []corev1.Container{{Name: "app"}, sidecar}
+		pods = append(pods, pod)
+	}
+	client := fake.NewSimpleClientset(pods...)
+	metrics := []podMetrics{
+		newPodMetrics("bookstore", "bookstore-v1", "90m", "64Mi"),
+		newPodMetrics("bookstore", "bookstore-v2", "20m", "256Mi"),
+		newPodMetrics("bookstore", "bookstore-v3", "200m", "480Mi"),
+	}
+
+	testCases := []struct {
+		name        string
+		sortBy      string
+		metricsErr  error
+		columns     []string
+		expected    string
+		expectedErr string
+	}{
+		{
+			name:   "proxies sorted by CPU usage",
+			sortBy: proxyTopSortByCPU,
+			expected: "NAMESPACE   POD            CPU    CPU-LIMIT   MEMORY   MEMORY-LIMIT   NEAR-LIMIT\n" +
+				"bookstore   bookstore-v3   200m   1000m       480Mi    512Mi          true\n" +
+				"bookstore   bookstore-v1   90m    100m        64Mi     128Mi          true\n" +
+				"bookstore   bookstore-v2   20m    -           256Mi    -              false\n",
+		},
+		{
+			name:    "proxies sorted by memory usage",
+			sortBy:  proxyTopSortByMemory,
+			columns: []string{"pod", "memory"},
+			expected: "POD            MEMORY\n" +
+				"bookstore-v3   480Mi\n" +
+				"bookstore-v2   256Mi\n" +
+				"bookstore-v1   64Mi\n",
+		},
+		{
+			name:       "metrics API not available",
+			sortBy:     proxyTopSortByCPU,
+			metricsErr: apierrors.NewNotFound(schema.GroupResource{Group: "metrics.k8s.io", Resource: "pods"}, ""),
+			expected: "Warning: the metrics API (/apis/metrics.k8s.io/v1beta1) is not available, install metrics-server to report the resource usage of the proxies\n\n" +
+				"NAMESPACE   POD            CPU   CPU-LIMIT   MEMORY   MEMORY-LIMIT   NEAR-LIMIT\n" +
+				"bookstore   bookstore-v1   -     100m        -        128Mi          false\n" +
+				"bookstore   bookstore-v2   -     -           -        -              false\n" +
+				"bookstore   bookstore-v3   -     1000m       -        512Mi          false\n",
+		},
+		{
+			name:        "error fetching the metrics",
+			sortBy:      proxyTopSortByCPU,
+			metricsErr:  errors.New("connection refused"),
+			expectedErr: "Error fetching the metrics of the pods in namespace bookstore: connection refused",
+		},
+		{
+			name:        "invalid sort order",
+			sortBy:      "disk",
+			expectedErr: `Invalid value "disk" for flag --sort-by, expected one of: cpu, memory`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			out := new(bytes.Buffer)
+			cmd := &proxyTopCmd{
+				out:            out,
+				namespace:      "bookstore",
+				sortBy:         tc.sortBy,
+				limitThreshold: defaultProxyTopLimitThreshold,
+				columns:        tc.columns,
+				clientSet:      client,
+				getPodMetrics: func(namespace string) ([]podMetrics, error) {
+					return metrics, tc.metricsErr
+				},
+			}
+
+			err := cmd.run()
+			if tc.expectedErr != "" {
+				assert.EqualError(err, tc.expectedErr)
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(tc.expected, out.String())
+		})
+	}
+
+	t.Run("JSON output", func(t *testing.T) {
+		assert := tassert.New(t)
+
+		out := new(bytes.Buffer)
+		cmd := &proxyTopCmd{
+			out:            out,
+			namespace:      "bookstore",
+			output:         outputFormatJSON,
+			sortBy:         proxyTopSortByCPU,
+			limitThreshold: 95,
+			clientSet:      client,
+			getPodMetrics: func(namespace string) ([]podMetrics, error) {
+				return metrics, nil
+			},
+		}
+		assert.Nil(cmd.run())
+
+		var usages []proxyUsage
+		assert.Nil(json.Unmarshal(out.Bytes(), &usages))
+		assert.Len(usages, 3)
+		assert.Equal("bookstore-v3", usages[0].Pod)
+		assert.Equal(int64(200), *usages[0].CPUMillicores)
+		assert.Equal(int64(1000), *usages[0].CPULimitMillicores)
+		assert.False(usages[0].NearLimit)
+		assert.Equal("bookstore-v2", usages[2].Pod)
+		assert.Nil(usages[2].CPULimitMillicores)
+		assert.Nil(usages[2].MemoryLimitBytes)
+	})
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	"helm.sh/helm/v3/pkg/action"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+	"github.com/openservicemesh/osm/pkg/envoy"
+	"github.com/openservicemesh/osm/pkg/identity"
+)
+
+const verifyIdentityCmdDescription = `
+This command verifies that the certificate served by the Envoy proxy sidecar
+of the given pod was issued for the identity of the pod.
+
+OSM issues the certificate served by a proxy for the identity derived from the
+service account and namespace of its pod, in the form
+<service-account>.<namespace>.cluster.local, and sets this identity as the
+subject alternative name of the certificate. The certificate is read from the
+service certificate secret the proxy received via SDS, which is available on
+the Envoy admin interface.
+
+A mismatch between the subject alternative names of the certificate and the
+identity of the pod usually indicates a stale or misissued certificate, in
+which case the command exits with a non-zero code.
+`
+
+const verifyIdentityCmdExample = `
+# Verify the identity of the proxy for the given pod 'bookbuyer-5ccf77f46d-rc5mg' in the 'bookbuyer' namespace
+osm proxy verify-identity bookbuyer-5ccf77f46d-rc5mg -n bookbuyer
+`
+
+type proxyVerifyIdentityCmd struct {
+	out       io.Writer
+	config    *rest.Config
+	clientSet kubernetes.Interface
+	namespace string
+	pod       string
+	localPort uint16
+	timeout   time.Duration
+}
+
+func newProxyVerifyIdentityCmd(config *action.Configuration, out io.Writer) *cobra.Command {
+	verifyIdentityCmd := &proxyVerifyIdentityCmd{
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "verify-identity POD",
+		Short: "verify the identity of the certificate served by a proxy",
+		Long:  verifyIdentityCmdDescription,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			verifyIdentityCmd.pod = args[0]
+			conf, err := config.RESTClientGetter.ToRESTConfig()
+			if err != nil {
+				return errors.Errorf("Error fetching kubeconfig: %s", err)
+			}
+			verifyIdentityCmd.config = conf
+
+			clientset, err := kubernetes.NewForConfig(conf)
+			if err != nil {
+				return errors.Errorf("Could not access Kubernetes cluster, check kubeconfig: %s", err)
+			}
+			verifyIdentityCmd.clientSet = clientset
+			return verifyIdentityCmd.run()
+		},
+		Example: verifyIdentityCmdExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&verifyIdentityCmd.namespace, "namespace", "n", metav1.NamespaceDefault, "Namespace of pod")
+	f.Uint16VarP(&verifyIdentityCmd.localPort, "local-port", "p", constants.EnvoyAdminPort, "Local port to use for port forwarding")
+	addProxyAdminTimeoutFlag(f, &verifyIdentityCmd.timeout, "timeout")
+
+	return cmd
+}
+
+func (cmd *proxyVerifyIdentityCmd) run() error {
+	pod, err := getRunningMeshedPod(cmd.clientSet, cmd.namespace, cmd.pod)
+	if err != nil {
+		return err
+	}
+
+	configDump, err := proxyAdminRequest(cmd.config, cmd.clientSet, cmd.namespace, cmd.pod, cmd.localPort, cmd.timeout, http.MethodGet, secretsConfigDumpQuery)
+	if err != nil {
+		return annotateErrMsgWithPodNamespaceMsg("Error retrieving proxy certificates for pod %s in namespace %s: %s", cmd.pod, cmd.namespace, err)
+	}
+
+	certs, err := parseProxyCertificates(configDump)
+	if err != nil {
+		return err
+	}
+
+	return verifyProxyIdentity(cmd.out, pod, certs)
+}
+
+// getPodServiceAccount returns the service account the given pod runs as
+func getPodServiceAccount(pod *corev1.Pod) identity.K8sServiceAccount {
+	serviceAccount := pod.Spec.ServiceAccountName
+	if serviceAccount == "" {
+		// Pods that do not set a service account run as the default service account of their namespace
+		serviceAccount = "default"
+	}
+	return identity.K8sServiceAccount{Namespace: pod.Namespace, Name: serviceAccount}
+}
+
+// verifyProxyIdentity prints whether the service certificate among the given certificates served by the proxy of the
+// given pod was issued for the identity of the pod, and returns an error if it was not
+func verifyProxyIdentity(out io.Writer, pod *corev1.Pod, certs []proxyCertificate) error {
+	serviceAccount := getPodServiceAccount(pod)
+	expectedIdentity := serviceAccount.ToServiceIdentity().String()
+	secretName := envoy.SDSCert{Name: serviceAccount.String(), CertType: envoy.ServiceCertType}.String()
+
+	// The first certificate of the chain of a secret is the certificate served by the proxy
+	var served *proxyCertificate
+	for i := range certs {
+		if certs[i].secretName == secretName {
+			served = &certs[i]
+			break
+		}
+	}
+	if served == nil {
+		return errors.Errorf("No certificate found in secret %s for the proxy of pod %s in namespace %s, the proxy may not have been issued a certificate for the service account of the pod",
+			secretName, pod.Name, pod.Namespace)
+	}
+
+	sans := getSubjectAltNames(served.certificate)
+	fmt.Fprintf(out, "Pod:               %s/%s\n", pod.Namespace, pod.Name)
+	fmt.Fprintf(out, "Service account:   %s\n", serviceAccount)
+	fmt.Fprintf(out, "Expected identity: %s\n", expectedIdentity)
+	fmt.Fprintf(out, "Secret:            %s\n", served.secretName)
+	fmt.Fprintf(out, "SAN:               %s\n\n", strings.Join(sans, ", "))
+
+	for _, san := range sans {
+		if san == expectedIdentity {
+			fmt.Fprintf(out, "[+] The certificate served by the proxy of pod %s/%s matches the identity %s\n", pod.Namespace, pod.Name, expectedIdentity)
+			return nil
+		}
+	}
+
+	fmt.Fprintf(out, "[!] MISMATCH: the certificate served by the proxy of pod %s/%s was not issued for the identity %s\n", pod.Namespace, pod.Name, expectedIdentity)
+	return annotateErrorMessageWithActionableMessage(
+		"Note: The certificate may be stale or misissued. Restart the pod for its proxy to be issued a new certificate.",
+		"Certificate served by the proxy of pod %s in namespace %s does not match the identity %s", pod.Name, pod.Namespace, expectedIdentity)
+}
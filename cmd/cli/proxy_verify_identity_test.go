@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"testing"
+	"time"
+
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestVerifyProxyIdentity(t *testing.T) {
+	newProxyCertificate := func(secretName, commonName string) proxyCertificate {
+		block, _ := pem.Decode(newTestCertificatePEM(t, commonName, time.Now().Add(time.Hour)))
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return proxyCertificate{secretName: secretName, certificate: cert}
+	}
+
+	testCases := []struct {
+		name           string
+		serviceAccount string
+		certs          []proxyCertificate
+		expectedErr    string
+		expectedOut    string
+	}{
+		{
+			name:           "identity matches",
+			serviceAccount: "bookstore",
+			certs: []proxyCertificate{
+				newProxyCertificate("service-cert:bookstore/bookstore", "bookstore.bookstore.cluster.local"),
+			},
+			expectedOut: "[+] The certificate served by the proxy of pod bookstore/bookstore-v1 matches the identity bookstore.bookstore.cluster.local",
+		},
+		{
+			name: "default service account",
+			certs: []proxyCertificate{
+				newProxyCertificate("service-cert:bookstore/default", "default.bookstore.cluster.local"),
+			},
+			expectedOut: "matches the identity default.bookstore.cluster.local",
+		},
+		{
+			name:           "identity mismatch",
+			serviceAccount: "bookstore",
+			certs: []proxyCertificate{
+				newProxyCertificate("service-cert:bookstore/bookstore", "bookbuyer.bookbuyer.cluster.local"),
+			},
+			expectedErr: "Certificate served by the proxy of pod bookstore-v1 in namespace bookstore does not match the identity bookstore.bookstore.cluster.local",
+			expectedOut: "[!] MISMATCH: the certificate served by the proxy of pod bookstore/bookstore-v1 was not issued for the identity bookstore.bookstore.cluster.local",
+		},
+		{
+			name:           "no service certificate for the service account",
+			serviceAccount: "bookstore",
+			certs: []proxyCertificate{
+				newProxyCertificate("service-cert:bookstore/bookstore-v2", "bookstore-v2.bookstore.cluster.local"),
+			},
+			expectedErr: "No certificate found in secret service-cert:bookstore/bookstore for the proxy of pod bookstore-v1 in namespace bookstore",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			pod := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "bookstore-v1", Namespace: "bookstore"},
+				Spec:       corev1.PodSpec{ServiceAccountName: tc.serviceAccount},
+			}
+			out := new(bytes.Buffer)
+			err := verifyProxyIdentity(out, pod, tc.certs)
+			if tc.expectedErr == "" {
+				assert.Nil(err)
+			} else {
+				assert.NotNil(err)
+				assert.Contains(err.Error(), tc.expectedErr)
+			}
+			assert.Contains(out.String(), tc.expectedOut)
+		})
+	}
+}
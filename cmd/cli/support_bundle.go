@@ -0,0 +1,363 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	smiAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned"
+	smiSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned"
+	smiSplitClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/split/clientset/versioned"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/openservicemesh/osm/pkg/cli"
+	"github.com/openservicemesh/osm/pkg/constants"
+	meshConfigClient "github.com/openservicemesh/osm/pkg/gen/client/config/clientset/versioned"
+)
+
+const supportBundleDescription = `
+This command collects diagnostics about the mesh into a gzipped tarball that
+can be attached to bug reports.
+
+The bundle contains:
+  - the osm-config ConfigMap and the MeshConfig resources of the OSM namespace
+  - the namespaces monitored by a mesh
+  - the SMI TrafficTarget, HTTPRouteGroup, TCPRoute and TrafficSplit resources
+  - the logs of the osm-controller pods, limited to the logs newer than --since
+    when set
+  - the Envoy config dump of every running meshed pod in the monitored namespaces
+
+Kubernetes secrets are never collected, and private keys, passwords and other
+secrets present in the Envoy config dumps are redacted. Diagnostics that cannot
+be collected are listed in the errors.txt file of the bundle.
+`
+
+const supportBundleExample = `
+# Collect diagnostics about the mesh whose control plane runs in the 'osm-system' namespace
+osm support-bundle --out bundle.tar.gz --osm-namespace osm-system
+
+# Only collect the logs of the last hour
+osm support-bundle --out bundle.tar.gz --since 1h
+`
+
+const (
+	defaultSupportBundleFile = "osm-support-bundle.tar.gz"
+
+	// redactedValue replaces the value of the secret fields of the Envoy config dumps
+	redactedValue = "[redacted]"
+)
+
+// redactedConfigDumpFields are the fields of an Envoy config dump whose value is redacted
+var redactedConfigDumpFields = map[string]bool{
+	"private_key":          true,
+	"private_key_provider": true,
+	"password":             true,
+	"session_ticket_keys":  true,
+	"hmac_secret":          true,
+}
+
+type supportBundleCmd struct {
+	out              io.Writer
+	outFile          string
+	localPort        uint16
+	timeout          time.Duration
+	since            time.Duration
+	clientSet        kubernetes.Interface
+	meshConfigClient meshConfigClient.Interface
+	smiAccessClient  smiAccessClient.Interface
+	smiSpecClient    smiSpecClient.Interface
+	smiSplitClient   smiSplitClient.Interface
+
+	// getConfigDump returns the Envoy config dump of the proxy of the given pod
+	getConfigDump func(pod *corev1.Pod) ([]byte, error)
+}
+
+// supportBundleWriter writes the files of a support bundle, recording the diagnostics that could not be collected
+type supportBundleWriter struct {
+	tarWriter *tar.Writer
+	modTime   time.Time
+	errs      []string
+}
+
+func newSupportBundleCmd(out io.Writer) *cobra.Command {
+	bundleCmd := &supportBundleCmd{
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "support-bundle",
+		Short: "collect diagnostics about the mesh",
+		Long:  supportBundleDescription,
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			clients, err := cli.NewClients(settings)
+			if err != nil {
+				return err
+			}
+			bundleCmd.clientSet = clients.KubeClient
+			bundleCmd.meshConfigClient = clients.ConfigClient
+			bundleCmd.smiAccessClient = clients.SMIAccessClient
+			bundleCmd.smiSpecClient = clients.SMISpecClient
+			bundleCmd.smiSplitClient = clients.SMISplitClient
+
+			bundleCmd.getConfigDump = func(pod *corev1.Pod) ([]byte, error) {
+				return proxyAdminRequest(clients.RESTConfig, clients.KubeClient, pod.Namespace, pod.Name, bundleCmd.localPort, bundleCmd.timeout, http.MethodGet, "config_dump")
+			}
+			return bundleCmd.run()
+		},
+		Example: supportBundleExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&bundleCmd.outFile, "out", "o", defaultSupportBundleFile, "Path of the gzipped tarball the support bundle is written to")
+	f.Uint16VarP(&bundleCmd.localPort, "local-port", "p", constants.EnvoyAdminPort, "Local port to use for port forwarding")
+	addProxyAdminTimeoutFlag(f, &bundleCmd.timeout, "timeout")
+	f.DurationVar(&bundleCmd.since, "since", 0, "Only collect the logs newer than a relative duration like 5s, 2m or 3h, all the logs are collected if unset")
+
+	return cmd
+}
+
+func (cmd *supportBundleCmd) run() error {
+	if cmd.since < 0 {
+		return errors.Errorf("Invalid value %s for flag --since, must not be negative", cmd.since)
+	}
+
+	file, err := os.Create(cmd.outFile)
+	if err != nil {
+		return errors.Errorf("Error creating support bundle %s: %s", cmd.outFile, err)
+	}
+	defer file.Close() //nolint: errcheck
+
+	if err := cmd.writeBundle(file); err != nil {
+		return errors.Errorf("Error writing support bundle %s: %s", cmd.outFile, err)
+	}
+	if err := file.Close(); err != nil {
+		return errors.Errorf("Error writing support bundle %s: %s", cmd.outFile, err)
+	}
+
+	fmt.Fprintf(cmd.out, "Support bundle written to %s\n", cmd.outFile)
+	return nil
+}
+
+// writeBundle writes the gzipped tarball of the support bundle to the given writer
+func (cmd *supportBundleCmd) writeBundle(out io.Writer) error {
+	gzipWriter := gzip.NewWriter(out)
+	bundle := &supportBundleWriter{
+		tarWriter: tar.NewWriter(gzipWriter),
+		modTime:   time.Now(),
+	}
+
+	osmNamespace := settings.Namespace()
+	cmd.collectMeshConfig(bundle, osmNamespace)
+	namespaces := cmd.collectMonitoredNamespaces(bundle)
+	cmd.collectSMIPolicies(bundle)
+	cmd.collectControllerLogs(bundle, osmNamespace)
+	cmd.collectConfigDumps(bundle, namespaces)
+
+	if len(bundle.errs) > 0 {
+		fmt.Fprintf(cmd.out, "[!] %d diagnostic(s) could not be collected, see errors.txt in the support bundle\n", len(bundle.errs))
+		bundle.writeFile("errors.txt", []byte(strings.Join(bundle.errs, "\n")+"\n"))
+	}
+
+	if err := bundle.tarWriter.Close(); err != nil {
+		return err
+	}
+	return gzipWriter.Close()
+}
+
+// collectMeshConfig collects the osm-config ConfigMap and the MeshConfig resources of the OSM namespace
+func (cmd *supportBundleCmd) collectMeshConfig(bundle *supportBundleWriter, osmNamespace string) {
+	configMap, err := cmd.clientSet.CoreV1().ConfigMaps(osmNamespace).Get(context.TODO(), osmConfigMapName, metav1.GetOptions{})
+	bundle.writeYAML("mesh/osm-config.yaml", configMap, err)
+
+	meshConfigs, err := cmd.meshConfigClient.ConfigV1alpha1().MeshConfigs(osmNamespace).List(context.TODO(), metav1.ListOptions{})
+	bundle.writeYAML("mesh/meshconfigs.yaml", meshConfigs, err)
+}
+
+// collectMonitoredNamespaces collects the namespaces monitored by a mesh and returns their names
+func (cmd *supportBundleCmd) collectMonitoredNamespaces(bundle *supportBundleWriter) []string {
+	namespaces, err := cmd.clientSet.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{
+		LabelSelector: constants.OSMKubeResourceMonitorAnnotation,
+	})
+	bundle.writeYAML("mesh/namespaces.yaml", namespaces, err)
+	if err != nil {
+		return nil
+	}
+
+	var names []string
+	for _, ns := range namespaces.Items {
+		names = append(names, ns.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// collectSMIPolicies collects the SMI policies of all namespaces
+func (cmd *supportBundleCmd) collectSMIPolicies(bundle *supportBundleWriter) {
+	trafficTargets, err := cmd.smiAccessClient.AccessV1alpha3().TrafficTargets(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	bundle.writeYAML("smi/traffictargets.yaml", trafficTargets, err)
+
+	httpRouteGroups, err := cmd.smiSpecClient.SpecsV1alpha4().HTTPRouteGroups(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	bundle.writeYAML("smi/httproutegroups.yaml", httpRouteGroups, err)
+
+	tcpRoutes, err := cmd.smiSpecClient.SpecsV1alpha4().TCPRoutes(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	bundle.writeYAML("smi/tcproutes.yaml", tcpRoutes, err)
+
+	trafficSplits, err := cmd.smiSplitClient.SplitV1alpha2().TrafficSplits(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	bundle.writeYAML("smi/trafficsplits.yaml", trafficSplits, err)
+}
+
+// collectControllerLogs collects the logs of every container of the osm-controller pods
+func (cmd *supportBundleCmd) collectControllerLogs(bundle *supportBundleWriter, osmNamespace string) {
+	pods, err := getControllerPods(cmd.clientSet, osmNamespace)
+	if err != nil {
+		bundle.addError("controller logs", err)
+		return
+	}
+
+	for _, pod := range pods {
+		for _, container := range pod.Spec.Containers {
+			name := path.Join("controller", pod.Name, container.Name+".log")
+			logs, err := cmd.getContainerLogs(pod.Namespace, pod.Name, container.Name)
+			if err != nil {
+				bundle.addError(name, err)
+				continue
+			}
+			bundle.writeFile(name, logs)
+		}
+	}
+}
+
+// getContainerLogs returns the logs of the given container
+func (cmd *supportBundleCmd) getContainerLogs(namespace, podName, containerName string) ([]byte, error) {
+	stream, err := cmd.clientSet.CoreV1().Pods(namespace).GetLogs(podName, newPodLogOptions(containerName, cmd.since, false)).Stream(context.TODO())
+	if err != nil {
+		return nil, err
+	}
+	defer stream.Close() //nolint: errcheck
+
+	return ioutil.ReadAll(stream)
+}
+
+// collectConfigDumps collects the redacted Envoy config dumps of the running meshed pods in the given namespaces
+func (cmd *supportBundleCmd) collectConfigDumps(bundle *supportBundleWriter, namespaces []string) {
+	for _, namespace := range namespaces {
+		pods, err := cmd.clientSet.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			bundle.addError(path.Join("envoy", namespace), err)
+			continue
+		}
+
+		for i := range pods.Items {
+			pod := &pods.Items[i]
+			if !isMeshedPod(*pod) || pod.Status.Phase != corev1.PodRunning {
+				continue
+			}
+
+			name := path.Join("envoy", pod.Namespace, pod.Name, "config_dump.json")
+			configDump, err := cmd.getConfigDump(pod)
+			if err != nil {
+				bundle.addError(name, err)
+				continue
+			}
+			redacted, err := redactConfigDump(configDump)
+			if err != nil {
+				bundle.addError(name, err)
+				continue
+			}
+			bundle.writeFile(name, redacted)
+		}
+	}
+}
+
+// redactConfigDump returns the given Envoy config dump with the value of the secret fields redacted
+func redactConfigDump(configDump []byte) ([]byte, error) {
+	var dump interface{}
+	if err := json.Unmarshal(configDump, &dump); err != nil {
+		return nil, errors.Errorf("Error parsing Envoy config dump: %s", err)
+	}
+	return json.MarshalIndent(redactFields(dump), "", "  ")
+}
+
+// redactFields recursively replaces the value of the fields listed in redactedConfigDumpFields
+func redactFields(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			if redactedConfigDumpFields[key] {
+				v[key] = redactedValue
+				continue
+			}
+			v[key] = redactFields(field)
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = redactFields(v[i])
+		}
+	}
+	return value
+}
+
+// writeYAML writes the YAML representation of the given Kubernetes object, or records the error that occurred fetching it
+func (b *supportBundleWriter) writeYAML(name string, obj interface{}, fetchErr error) {
+	if fetchErr != nil {
+		b.addError(name, fetchErr)
+		return
+	}
+
+	// Kubernetes objects are only annotated with JSON tags, so convert the JSON representation to YAML
+	// using a MapSlice to preserve the order of the fields
+	objJSON, err := json.Marshal(obj)
+	if err != nil {
+		b.addError(name, err)
+		return
+	}
+	var objMap yaml.MapSlice
+	if err := yaml.Unmarshal(objJSON, &objMap); err != nil {
+		b.addError(name, err)
+		return
+	}
+	objYAML, err := yaml.Marshal(objMap)
+	if err != nil {
+		b.addError(name, err)
+		return
+	}
+	b.writeFile(name, objYAML)
+}
+
+// writeFile writes a file with the given name and content to the bundle
+func (b *supportBundleWriter) writeFile(name string, content []byte) {
+	header := &tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(content)),
+		ModTime: b.modTime,
+	}
+	if err := b.tarWriter.WriteHeader(header); err != nil {
+		b.addError(name, err)
+		return
+	}
+	if _, err := b.tarWriter.Write(content); err != nil {
+		b.addError(name, err)
+	}
+}
+
+// addError records a diagnostic of the bundle that could not be collected
+func (b *supportBundleWriter) addError(name string, err error) {
+	b.errs = append(b.errs, fmt.Sprintf("%s: %s", name, err))
+}
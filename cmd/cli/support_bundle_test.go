@@ -0,0 +1,146 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"github.com/pkg/errors"
+	fakeAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned/fake"
+	fakeSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned/fake"
+	fakeSplitClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/split/clientset/versioned/fake"
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+	fakeMeshConfigClient "github.com/openservicemesh/osm/pkg/gen/client/config/clientset/versioned/fake"
+)
+
+func TestRedactConfigDump(t *testing.T) {
+	assert := tassert.New(t)
+
+	configDump := []byte(`{"configs":[{"dynamic_active_secrets":[{"name":"service-cert","secret":{"tls_certificate":` +
+		`{"certificate_chain":{"inline_bytes":"Y2VydA=="},"private_key":{"inline_bytes":"a2V5"}}}}]},` +
+		`{"bootstrap":{"node":{"id":"proxy"}},"password":"secret"}]}`)
+
+	redacted, err := redactConfigDump(configDump)
+	assert.Nil(err)
+	assert.JSONEq(`{"configs":[{"dynamic_active_secrets":[{"name":"service-cert","secret":{"tls_certificate":`+
+		`{"certificate_chain":{"inline_bytes":"Y2VydA=="},"private_key":"[redacted]"}}}]},`+
+		`{"bootstrap":{"node":{"id":"proxy"}},"password":"[redacted]"}]}`, string(redacted))
+
+	_, err = redactConfigDump([]byte("not json"))
+	assert.NotNil(err)
+}
+
+func TestWriteSupportBundle(t *testing.T) {
+	assert := tassert.New(t)
+
+	osmNamespace := settings.Namespace()
+	pendingPod := newTestPod("bookstore", "bookstore-pending", "", true)
+	pendingPod.Status.Phase = corev1.PodPending
+
+	clientSet := fake.NewSimpleClientset(
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: osmConfigMapName, Namespace: osmNamespace},
+			Data:       map[string]string{"permissive_traffic_policy_mode": "true"},
+		},
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   "bookstore",
+				Labels: map[string]string{constants.OSMKubeResourceMonitorAnnotation: "osm"},
+			},
+		},
+		&corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{Name: "unmonitored"},
+		},
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "osm-controller-1",
+				Namespace: osmNamespace,
+				Labels:    map[string]string{"app": constants.OSMControllerName},
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: constants.OSMControllerName}}},
+		},
+		newTestPod("bookstore", "bookstore-1", "", true),
+		newTestPod("bookstore", "bookstore-2", "", true),
+		pendingPod,
+		newTestPod("bookstore", "unmeshed", "", false),
+	)
+
+	out := new(bytes.Buffer)
+	cmd := &supportBundleCmd{
+		out:              out,
+		clientSet:        clientSet,
+		meshConfigClient: fakeMeshConfigClient.NewSimpleClientset(),
+		smiAccessClient:  fakeAccessClient.NewSimpleClientset(),
+		smiSpecClient:    fakeSpecClient.NewSimpleClientset(),
+		smiSplitClient:   fakeSplitClient.NewSimpleClientset(),
+		getConfigDump: func(pod *corev1.Pod) ([]byte, error) {
+			if pod.Name == "bookstore-2" {
+				return nil, errors.New("connection refused")
+			}
+			return []byte(`{"configs":[{"private_key":{"inline_bytes":"a2V5"}}]}`), nil
+		},
+	}
+
+	bundle := new(bytes.Buffer)
+	assert.Nil(cmd.writeBundle(bundle))
+
+	files := readSupportBundle(t, bundle)
+	var names []string
+	for name := range files {
+		names = append(names, name)
+	}
+	assert.ElementsMatch([]string{
+		"mesh/osm-config.yaml",
+		"mesh/meshconfigs.yaml",
+		"mesh/namespaces.yaml",
+		"smi/traffictargets.yaml",
+		"smi/httproutegroups.yaml",
+		"smi/tcproutes.yaml",
+		"smi/trafficsplits.yaml",
+		"controller/osm-controller-1/osm-controller.log",
+		"envoy/bookstore/bookstore-1/config_dump.json",
+		"errors.txt",
+	}, names)
+
+	assert.Contains(files["mesh/osm-config.yaml"], "permissive_traffic_policy_mode: \"true\"")
+	assert.Contains(files["mesh/namespaces.yaml"], "name: bookstore")
+	assert.NotContains(files["mesh/namespaces.yaml"], "unmonitored")
+	assert.Equal("fake logs", files["controller/osm-controller-1/osm-controller.log"])
+	assert.JSONEq(`{"configs":[{"private_key":"[redacted]"}]}`, files["envoy/bookstore/bookstore-1/config_dump.json"])
+	assert.Equal("envoy/bookstore/bookstore-2/config_dump.json: connection refused\n", files["errors.txt"])
+	assert.Equal("[!] 1 diagnostic(s) could not be collected, see errors.txt in the support bundle\n", out.String())
+}
+
+// readSupportBundle returns the content of the files of the given gzipped tarball
+func readSupportBundle(t *testing.T, bundle io.Reader) map[string]string {
+	gzipReader, err := gzip.NewReader(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tarReader := tar.NewReader(gzipReader)
+
+	files := make(map[string]string)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		content, err := ioutil.ReadAll(tarReader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		files[header.Name] = string(content)
+	}
+	return files
+}
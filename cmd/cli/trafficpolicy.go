@@ -11,14 +11,21 @@ This command consists of subcommands related to traffic policies
 associated with osm.
 `
 
-func newTrafficPolicyCmd(out io.Writer) *cobra.Command {
+func newTrafficPolicyCmd(in io.Reader, out io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "policy",
 		Short: "manage and check traffic policies",
 		Long:  trafficPolicyDescription,
 		Args:  cobra.NoArgs,
 	}
-	cmd.AddCommand(newTrafficPolicyCheck(out))
+	cmd.AddCommand(newTrafficPolicyCheck(in, out))
+	cmd.AddCommand(newTrafficPolicyCheckIdentityCmd(out))
+	cmd.AddCommand(newTrafficPolicyDiffCmd(in, out))
+	cmd.AddCommand(newTrafficPolicyCompareNetworkPolicyCmd(in, out))
+	cmd.AddCommand(newTrafficPolicyExportGraphCmd(out))
+	cmd.AddCommand(newTrafficPolicyExplainCmd(out))
+	cmd.AddCommand(newTrafficPolicyListRoutesCmd(out))
+	cmd.AddCommand(newTrafficPolicyListRouteGroupsCmd(out))
 
 	return cmd
 }
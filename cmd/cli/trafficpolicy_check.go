@@ -4,23 +4,176 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/http"
+	"os"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
 	smiAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned"
+	smiSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned"
+	smiSplitClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/split/clientset/versioned"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
 
-	"github.com/openservicemesh/osm/pkg/configurator"
+	"github.com/openservicemesh/osm/pkg/cli"
+	"github.com/openservicemesh/osm/pkg/constants"
+	policyClient "github.com/openservicemesh/osm/pkg/gen/client/policy/clientset/versioned"
+	"github.com/openservicemesh/osm/pkg/smi"
 )
 
 const trafficPolicyCheckDescription = `
 This command will check whether a given source pod is allowed to communicate
 (send traffic) to a given destination pod by an SMI TrafficTarget policy or
 in lieu of the mesh operating in permissive traffic policy mode.
+
+When allowed by SMI TrafficTarget policies, the routes referenced by their
+rules are listed: TCPRoute rules allow traffic over L4 on the listed ports,
+while HTTPRouteGroup rules allow traffic over L7 for the listed HTTP matches.
+A warning is printed for each allowing policy referencing routes or service
+accounts that do not exist.
+
+If the destination pod is a backend of a service split by an SMI TrafficSplit,
+the weighted backends of the split are listed along with whether the source
+pod is allowed to communicate to each backend.
+
+The destination can also be a service, in which case the source pod is checked
+against the service accounts of the meshed pods backing the service. The
+destination is looked up as a service when no pod is found with its name, or
+when --destination-kind is set to 'service'.
+
+With --from-file, up to --concurrency pairs are checked concurrently. The
+results are printed in the order of the pairs in the file, followed by a table
+of the results whose columns can be selected with --columns, among: src, dst,
+allowed, reason, targets.
+
+The SMI policies and services listed by the command are cached for the
+duration of a check, so that every lookup observes the same state of the
+cluster. With --watch, the cache is discarded every time the check is re-run.
+Caching can be disabled with --no-cache.
+
+With --watch, the watch on the SMI TrafficTarget policies is resumed when the
+API server closes it. When the API server no longer holds the changes since the
+last observed state, the policies are re-listed and the check is re-run, since
+changes may have been missed. The command gives up after repeated failures to
+watch the policies.
+
+By default, only the SMI TrafficTarget policies defined in the namespace of the
+destination are considered. With --all-namespaces, the policies defined in
+every namespace are scanned and matched by the namespace of their destination
+service account, so that policies defined in another namespace than the
+destination are found. Listing the policies of every namespace is slower on
+clusters with many policies, and requires the permission to list TrafficTarget
+policies cluster-wide.
+
+With --explain-deny, when the source pod is not allowed to communicate to the
+destination, the SMI TrafficTarget policy that would allow it is printed along
+with a stub HTTPRouteGroup policy allowing every request, to be reviewed and
+applied with 'kubectl apply'. The policies are named after the source and
+destination service accounts and defined in the destination namespace.
+
+In permissive traffic policy mode, every meshed pod is allowed to communicate
+with each other. With --require-smi, the SMI TrafficTarget policies are also
+evaluated in permissive mode, and a warning is printed when they would not allow
+the source pod to communicate to the destination, to find the missing policies
+before disabling permissive mode, along with the SMI policies that would allow
+the traffic with --explain-deny. The check still reports the traffic as allowed.
+
+With --show-stats, when the SMI TrafficTarget policies allow the source pod to
+communicate to a destination pod, the HTTP requests and TCP connections received
+by the proxy of the destination pod since it started are printed, read from the
+stats of the proxy through port forwarding, to tell whether the allowed traffic
+is actually flowing. The proxy does not record stats per SMI route, so the
+stats cover the traffic received from every source over every route. A warning
+is printed when the stats can't be retrieved, without changing the outcome of
+the check.
+
+With --allow-non-meshed-destination, a destination pod that is not a part of a
+mesh is checked instead of being rejected as invalid input. The traffic to such
+a pod leaves the mesh, so it is allowed when egress is enabled mesh-wide, or
+when a policy.openservicemesh.io Egress policy applying to the source pod lists
+an IP range including an IP address of the destination pod.
+
+Before checking a pair, the command verifies that the invoking user is allowed
+to get the pods of the source and destination namespaces, to list the SMI
+TrafficTarget policies of the destination namespace, or of all the namespaces
+with --all-namespaces, and to get the mesh config, along with listing the
+Egress policies of all the namespaces with --allow-non-meshed-destination,
+and lists the missing RBAC permissions if any. The verification can be skipped
+with --skip-rbac-check.
+
+With --trace, each step of the evaluation of the policies is printed, prefixed
+with [trace]: whether permissive mode is enabled, the number of SMI
+TrafficTarget policies listed, whether the destination and each source of every
+policy match the destination and source pods, and whether the routes referenced
+by the allowing policies exist.
+
+With --from-snapshot, the check runs offline against the resources read from
+the YAML or JSON manifests in the given directory and its subdirectories, e.g.
+exported with 'kubectl get -o yaml', instead of the cluster: the pods, services
+and namespaces, the SMI policies, the Egress policies, the ConfigMap holding the
+configuration of the mesh and the osm-controller deployments. Lists of
+resources are read item by item, and resources of other kinds are ignored. The
+verification of the RBAC permissions is skipped, and --watch, --show-stats and
+--as are not supported. With --snapshot-out, the resources read by a check of
+the cluster are written to the given directory beforehand, a file per kind of
+resource, so that the check can be reproduced offline.
+
+With --header, the check simulates an HTTP request with the given headers: the
+source pod is only allowed to communicate to the destination when a rule of an
+allowing SMI TrafficTarget policy allows the request, i.e. a TCPRoute rule, or
+an HTTPRouteGroup rule with a match whose header regexes match the whole values
+of the headers of the request. A header of a match that is absent from the
+request does not match. The methods and path regexes of the matches are not
+evaluated, 'osm policy explain' evaluates a whole request.
+
+With --wait, e.g. in CI after applying policies, the check is re-run every 2
+seconds until the source pod is allowed to communicate to the destination, or
+until the given duration elapses, since policies take time to propagate. The
+command succeeds as soon as the traffic is allowed, and only the output of the
+last check is printed. The pods must exist when the command starts.
+
+With --ignore-target, the given SMI TrafficTarget policies are ignored as if
+they were deleted, to find out whether the pods would still be allowed to
+communicate without them before deleting them. A policy is given by name,
+matching the policies of that name in every namespace, or as namespace/name.
+The ignored policies are printed before the outcome of the check.
+
+With --by-ip, the source and destination pods are given by IP address, e.g.
+taken from a flow log, and resolved to the pods having these IPs in their
+status among the pods of every namespace, which requires the permission to
+list pods cluster-wide. Pods that have terminated are skipped since their IPs
+can be reused. An IP that belongs to no running pod, or to multiple running
+pods, e.g. pods on the host network of a node, is reported as invalid input.
+With --from-file, the pairs list IP addresses instead of pods.
+
+With --as, and optionally --as-group, the requests to the Kubernetes API server
+are made impersonating the given user and groups, e.g. a service account, so
+that the check, including the verification of the RBAC permissions, runs with
+the permissions of that identity. The invoking user must be allowed to
+impersonate it.
+
+The command exits with the following codes, to be used as a gate in automation:
+  0: the source pod is allowed to communicate to the destination
+  1: unexpected error
+  2: invalid input, e.g. a pod or namespace that does not exist or a pod that
+     is not a part of a mesh
+  3: the source pod is not allowed to communicate to the destination
+  4: error communicating with the Kubernetes API server, or missing RBAC
+     permissions
+  5: with --wait, the source pod is still not allowed to communicate to the
+     destination once the given duration has elapsed
+With --from-file, the exit code reflects the most severe outcome among the
+checked pairs, where API errors take precedence over invalid input, which
+takes precedence over denied traffic.
 `
 
 const trafficPolicyCheckExample = `
@@ -30,186 +183,724 @@ osm policy check-pods bookbuyer/bookbuyer-client bookstore/bookstore-server
 # If the pod belongs to the default namespace, the namespace can be omitted with the flags
 # To check if pod 'bookbuyer-client' in the 'default' namespace can send traffic to pod 'bookstore-server' in the 'default' namespace
 osm policy check-pods bookbuyer-client bookstore-server
+
+# To keep checking if pod 'bookbuyer-client' in the 'bookbuyer' namespace can send traffic to pod 'bookstore-server' in the 'bookstore' namespace
+# as the SMI TrafficTarget policies in the 'bookstore' namespace change, until interrupted with Ctrl+C
+osm policy check-pods bookbuyer/bookbuyer-client bookstore/bookstore-server --watch
+
+# To check if pod 'bookbuyer-client' in the 'bookbuyer' namespace can send traffic to the pods backing service 'bookstore' in the 'bookstore' namespace
+osm policy check-pods bookbuyer/bookbuyer-client bookstore/bookstore --destination-kind service
+
+# To also consider the SMI TrafficTarget policies defined in other namespaces than the 'bookstore' namespace
+osm policy check-pods bookbuyer/bookbuyer-client bookstore/bookstore-server --all-namespaces
+
+# To print the SMI policies that would allow pod 'bookbuyer-client' in the 'bookbuyer' namespace to send traffic to pod 'bookstore-server'
+# in the 'bookstore' namespace if it is not allowed
+osm policy check-pods bookbuyer/bookbuyer-client bookstore/bookstore-server --explain-deny
+
+# To also print the traffic received by the proxy of pod 'bookstore-server' if the traffic is allowed
+osm policy check-pods bookbuyer/bookbuyer-client bookstore/bookstore-server --show-stats
+
+# To print each step of the evaluation of the policies deciding whether the traffic is allowed
+osm policy check-pods bookbuyer/bookbuyer-client bookstore/bookstore-server --trace
+
+# To also verify that SMI TrafficTarget policies allow the traffic when the mesh operates in permissive traffic policy mode
+osm policy check-pods bookbuyer/bookbuyer-client bookstore/bookstore-server --require-smi
+
+# To check if pod 'bookbuyer-client' in the 'bookbuyer' namespace can send traffic to pod 'legacy-db' in the 'legacy' namespace,
+# which is not a part of a mesh, as egress traffic
+osm policy check-pods bookbuyer/bookbuyer-client legacy/legacy-db --allow-non-meshed-destination
+
+# To check if pod 'bookbuyer-client' in the 'bookbuyer' namespace can send requests with the header 'authorization: Bearer token'
+# to pod 'bookstore-server' in the 'bookstore' namespace
+osm policy check-pods bookbuyer/bookbuyer-client bookstore/bookstore-server --header "authorization=Bearer token"
+
+# To wait up to 30 seconds for pod 'bookbuyer-client' in the 'bookbuyer' namespace to be allowed to send traffic to pod 'bookstore-server'
+# in the 'bookstore' namespace, e.g. after applying SMI policies
+osm policy check-pods bookbuyer/bookbuyer-client bookstore/bookstore-server --wait 30s
+
+# To check if pod 'bookbuyer-client' in the 'bookbuyer' namespace would still be allowed to send traffic to pod 'bookstore-server'
+# in the 'bookstore' namespace if the SMI TrafficTarget policy 'bookstore-all' in the 'bookstore' namespace were deleted
+osm policy check-pods bookbuyer/bookbuyer-client bookstore/bookstore-server --ignore-target bookstore/bookstore-all
+
+# To check if the pod having the IP address 10.1.2.3 can send traffic to the pod having the IP address 10.1.4.5
+osm policy check-pods --by-ip 10.1.2.3 10.1.4.5
+
+# To check the pods of the mesh named 'prod', whose configuration is held in the ConfigMap 'osm-config-prod'
+osm policy check-pods bookbuyer/bookbuyer-client bookstore/bookstore-server --mesh-name prod --mesh-config-name osm-config-prod
+
+# To check the pods with the RBAC permissions of the service account 'auditor' in the 'audit' namespace
+osm policy check-pods bookbuyer/bookbuyer-client bookstore/bookstore-server --as system:serviceaccount:audit:auditor
+
+# To save the resources read by the check of the pods to the directory 'snapshot', then check the pods offline against the saved resources
+osm policy check-pods bookbuyer/bookbuyer-client bookstore/bookstore-server --snapshot-out snapshot
+osm policy check-pods bookbuyer/bookbuyer-client bookstore/bookstore-server --from-snapshot snapshot
+
+# To check every 'SOURCE_POD DESTINATION_POD' pair listed one per line in the file 'pairs.txt'
+osm policy check-pods --from-file pairs.txt
+
+# To check the 'SOURCE_POD DESTINATION_POD' pairs piped through stdin
+echo "bookbuyer/bookbuyer-client bookstore/bookstore-server" | osm policy check-pods --from-file -
+
+# To list the pod pairs listed in the file 'pairs.txt' along with whether they are allowed and the allowing SMI TrafficTarget policies
+osm policy check-pods --from-file pairs.txt --columns src,dst,allowed,targets
 `
 
 const (
 	namespaceSeparator = "/"
 	osmConfigMapName   = "osm-config"
 	serviceAccountKind = "ServiceAccount"
+
+	// destinationKindPod and destinationKindService are the supported --destination-kind values
+	destinationKindPod     = "pod"
+	destinationKindService = "service"
+
+	// checkExitCodeInvalidInput is the exit code when the arguments are invalid or do not refer to meshed pods
+	checkExitCodeInvalidInput = 2
+
+	// checkExitCodeTrafficDenied is the exit code when the source pod is not allowed to communicate to the destination
+	checkExitCodeTrafficDenied = 3
+
+	// checkExitCodeAPIError is the exit code when the Kubernetes API server could not be queried
+	checkExitCodeAPIError = 4
+
+	// maxNamespaceSuggestionDistance is the maximum number of edits between a namespace that does not exist
+	// and the existing namespaces suggested in its place
+	maxNamespaceSuggestionDistance = 3
 )
 
 type trafficPolicyCheckCmd struct {
 	out             io.Writer
 	sourcePod       string
 	destinationPod  string
+	destinationKind string
+	watch           bool
+	noCache         bool
+	skipRBACCheck   bool
+	allNamespaces   bool
+	explainDeny     bool
+	allowNonMeshed  bool
+	requireSMI      bool
+	showStats       bool
+	trace           bool
+	headers         []string
+	statsLocalPort  uint16
+	statsTimeout    time.Duration
+	fromFile        string
+	fromSnapshot    string
+	snapshotOut     string
+	byIP            bool
+	ignoredTargets  []string
+	wait            time.Duration
+	pollInterval    time.Duration
+	concurrency     int
+	columns         []string
+	meshName        string
+	meshConfigName  string
+	osmNamespace    string
+	asUser          string
+	asGroups        []string
+	in              io.Reader
 	clientSet       kubernetes.Interface
 	smiAccessClient smiAccessClient.Interface
+	smiSpecClient   smiSpecClient.Interface
+	smiSplitClient  smiSplitClient.Interface
+	policyClient    policyClient.Interface
+	sigintChan      chan os.Signal
+
+	// getProxyStats returns the stats of the proxy of the given pod, rendered as 'name: value' lines
+	getProxyStats func(pod *corev1.Pod) ([]byte, error)
+
+	// requestHeaders are the headers given with --header by lowercase name, it is nil when no headers are given
+	requestHeaders map[string]string
+
+	// listCache caches the resources listed by the checks of this invocation, it is nil with --no-cache
+	listCache *listCache
+
+	// checkResult records the outcome of the check of a pair with --from-file, it is nil otherwise
+	checkResult *podPairCheckResult
 }
 
-func newTrafficPolicyCheck(out io.Writer) *cobra.Command {
+func newTrafficPolicyCheck(in io.Reader, out io.Writer) *cobra.Command {
 	trafficPolicyCheckCmd := &trafficPolicyCheckCmd{
-		out: out,
+		in:           in,
+		out:          out,
+		sigintChan:   make(chan os.Signal, 1),
+		pollInterval: waitPollInterval,
 	}
 
 	cmd := &cobra.Command{
 		Use:   "check-pods SOURCE_POD DESTINATION_POD",
 		Short: "check-pods traffic policy",
 		Long:  trafficPolicyCheckDescription,
-		Args:  cobra.ExactArgs(2),
+		Args: func(cmd *cobra.Command, args []string) error {
+			if trafficPolicyCheckCmd.wait > 0 && trafficPolicyCheckCmd.watch {
+				return withExitCode(checkExitCodeInvalidInput, errors.New("flags --wait and --watch are mutually exclusive"))
+			}
+			if trafficPolicyCheckCmd.fromFile != "" {
+				if trafficPolicyCheckCmd.watch {
+					return withExitCode(checkExitCodeInvalidInput, errors.New("flags --from-file and --watch are mutually exclusive"))
+				}
+				if trafficPolicyCheckCmd.wait > 0 {
+					return withExitCode(checkExitCodeInvalidInput, errors.New("flags --from-file and --wait are mutually exclusive"))
+				}
+				return withExitCode(checkExitCodeInvalidInput, cobra.NoArgs(cmd, args))
+			}
+			return withExitCode(checkExitCodeInvalidInput, cobra.ExactArgs(2)(cmd, args))
+		},
 		RunE: func(_ *cobra.Command, args []string) error {
-			trafficPolicyCheckCmd.sourcePod = args[0]
-			trafficPolicyCheckCmd.destinationPod = args[1]
+			if len(args) == 2 {
+				trafficPolicyCheckCmd.sourcePod = args[0]
+				trafficPolicyCheckCmd.destinationPod = args[1]
+			}
+			if len(trafficPolicyCheckCmd.asGroups) > 0 && trafficPolicyCheckCmd.asUser == "" {
+				return withExitCode(checkExitCodeInvalidInput, errors.New("flag --as-group requires flag --as"))
+			}
 
-			config, err := settings.RESTClientGetter().ToRESTConfig()
-			if err != nil {
-				return errors.Errorf("Error fetching kubeconfig: %s", err)
+			if trafficPolicyCheckCmd.fromSnapshot != "" {
+				for _, flag := range []struct {
+					name string
+					set  bool
+				}{
+					{"--watch", trafficPolicyCheckCmd.watch},
+					{"--show-stats", trafficPolicyCheckCmd.showStats},
+					{"--as", trafficPolicyCheckCmd.asUser != ""},
+					{"--snapshot-out", trafficPolicyCheckCmd.snapshotOut != ""},
+				} {
+					if flag.set {
+						return withExitCode(checkExitCodeInvalidInput, errors.Errorf("flags --from-snapshot and %s are mutually exclusive", flag.name))
+					}
+				}
+				if err := trafficPolicyCheckCmd.useSnapshot(trafficPolicyCheckCmd.fromSnapshot); err != nil {
+					return withExitCode(checkExitCodeInvalidInput, err)
+				}
+				// The snapshot holds no RBAC policies to verify the permissions against
+				trafficPolicyCheckCmd.skipRBACCheck = true
+				return trafficPolicyCheckCmd.run()
+			}
+			if trafficPolicyCheckCmd.snapshotOut != "" && trafficPolicyCheckCmd.fromFile != "" {
+				return withExitCode(checkExitCodeInvalidInput, errors.New("flags --snapshot-out and --from-file are mutually exclusive"))
 			}
 
-			clientset, err := kubernetes.NewForConfig(config)
+			config, err := settings.RESTClientGetter().ToRESTConfig()
 			if err != nil {
-				return errors.Errorf("Could not access Kubernetes cluster, check kubeconfig: %s", err)
+				return withExitCode(checkExitCodeAPIError, errors.Errorf("Error fetching kubeconfig: %s", err))
 			}
-			trafficPolicyCheckCmd.clientSet = clientset
+			trafficPolicyCheckCmd.impersonate(config)
 
-			accessCliemt, err := smiAccessClient.NewForConfig(config)
+			clients, err := cli.NewClientsForConfig(config)
 			if err != nil {
-				return errors.Errorf("Could not initialize SMI Access client: %s", err)
+				return withExitCode(checkExitCodeAPIError, err)
+			}
+			trafficPolicyCheckCmd.clientSet = clients.KubeClient
+			trafficPolicyCheckCmd.smiAccessClient = clients.SMIAccessClient
+			trafficPolicyCheckCmd.smiSpecClient = clients.SMISpecClient
+			trafficPolicyCheckCmd.smiSplitClient = clients.SMISplitClient
+			trafficPolicyCheckCmd.policyClient = clients.PolicyClient
+
+			// Pairs checked concurrently with --from-file forward the same local port, so the stats are retrieved
+			// one proxy at a time
+			var statsLock sync.Mutex
+			trafficPolicyCheckCmd.getProxyStats = func(pod *corev1.Pod) ([]byte, error) {
+				statsLock.Lock()
+				defer statsLock.Unlock()
+				return proxyAdminRequest(clients.RESTConfig, clients.KubeClient, pod.Namespace, pod.Name, trafficPolicyCheckCmd.statsLocalPort, trafficPolicyCheckCmd.statsTimeout, http.MethodGet, statsQuery)
 			}
-			trafficPolicyCheckCmd.smiAccessClient = accessCliemt
 
 			return trafficPolicyCheckCmd.run()
 		},
 		Example: trafficPolicyCheckExample,
 	}
 
+	f := cmd.Flags()
+	f.BoolVarP(&trafficPolicyCheckCmd.watch, "watch", "w", false, "Watch SMI TrafficTarget policies in the destination namespace, or in all namespaces with --all-namespaces, and re-run the check when they change")
+	f.DurationVar(&trafficPolicyCheckCmd.wait, "wait", 0, "Re-run the check until the source pod is allowed to communicate to the destination or the given duration elapses, e.g. 30s")
+	f.StringVarP(&trafficPolicyCheckCmd.fromFile, "from-file", "f", "", "Check the 'SOURCE_POD DESTINATION_POD' pairs listed one per line in the given file, or in stdin if set to -")
+	f.BoolVar(&trafficPolicyCheckCmd.noCache, "no-cache", false, "List the SMI policies and services every time they are looked up instead of once per check")
+	f.BoolVar(&trafficPolicyCheckCmd.skipRBACCheck, "skip-rbac-check", false, "Skip the verification of the RBAC permissions required to check the pods")
+	f.BoolVarP(&trafficPolicyCheckCmd.allNamespaces, "all-namespaces", "A", false, "Scan the SMI TrafficTarget policies of all the namespaces instead of the destination namespace only, slower on clusters with many policies")
+	f.BoolVar(&trafficPolicyCheckCmd.explainDeny, "explain-deny", false, "Print the SMI TrafficTarget and HTTPRouteGroup policies that would allow the source pod to communicate to the destination when it is not allowed")
+	f.BoolVar(&trafficPolicyCheckCmd.requireSMI, "require-smi", false, "In permissive traffic policy mode, warn when the SMI TrafficTarget policies would not allow the source pod to communicate to the destination")
+	f.BoolVar(&trafficPolicyCheckCmd.showStats, "show-stats", false, "When the SMI TrafficTarget policies allow the source pod to communicate to the destination pod, print the traffic received by the proxy of the destination pod")
+	f.StringArrayVarP(&trafficPolicyCheckCmd.headers, "header", "H", nil, "HTTP header of the request in the form 'name: value' or 'name=value' that the allowing SMI routes must match, can be repeated")
+	f.BoolVar(&trafficPolicyCheckCmd.trace, "trace", false, "Print each step of the evaluation of the policies deciding whether the source pod is allowed to communicate to the destination pod")
+	f.Uint16Var(&trafficPolicyCheckCmd.statsLocalPort, "stats-local-port", constants.EnvoyAdminPort, "Local port to use for port forwarding to the proxy of the destination pod with --show-stats")
+	addProxyAdminTimeoutFlag(f, &trafficPolicyCheckCmd.statsTimeout, "stats-timeout")
+	f.BoolVar(&trafficPolicyCheckCmd.allowNonMeshed, "allow-non-meshed-destination", false, "Check a destination pod that is not a part of a mesh against the egress configuration of the mesh instead of rejecting it")
+	f.StringArrayVar(&trafficPolicyCheckCmd.ignoredTargets, "ignore-target", nil, "Name, or namespace/name, of an SMI TrafficTarget policy to ignore as if it were deleted, can be repeated")
+	f.BoolVar(&trafficPolicyCheckCmd.byIP, "by-ip", false, "Specify the source and destination pods by IP address, resolved to the running pods having these IPs")
+	f.StringVar(&trafficPolicyCheckCmd.fromSnapshot, "from-snapshot", "", "Check the pods against the resources read from the YAML or JSON manifests in the given directory instead of the cluster")
+	f.StringVar(&trafficPolicyCheckCmd.snapshotOut, "snapshot-out", "", "Write the resources read by the check to the given directory, to be checked offline with --from-snapshot")
+	f.IntVar(&trafficPolicyCheckCmd.concurrency, "concurrency", defaultCheckConcurrency, "Number of pod pairs checked concurrently with --from-file")
+	f.StringSliceVar(&trafficPolicyCheckCmd.columns, "columns", defaultCheckResultColumns, "Comma separated list of the columns of the table of results printed with --from-file")
+	f.StringVar(&trafficPolicyCheckCmd.destinationKind, "destination-kind", "", "Kind of the destination, one of: pod, service. If unset, the destination is looked up as a service when no pod is found")
+	f.StringVar(&trafficPolicyCheckCmd.meshName, "mesh-name", "", "Name of the mesh whose configuration is checked, the mesh running in the namespace given with --osm-namespace if unset")
+	f.StringVar(&trafficPolicyCheckCmd.meshConfigName, "mesh-config-name", osmConfigMapName, "Name of the ConfigMap holding the configuration of the mesh")
+	f.StringVar(&trafficPolicyCheckCmd.asUser, "as", "", "Username to impersonate for the requests to the Kubernetes API server, e.g. system:serviceaccount:NAMESPACE:NAME for a service account")
+	f.StringSliceVar(&trafficPolicyCheckCmd.asGroups, "as-group", nil, "Group to impersonate for the requests to the Kubernetes API server along with --as, can be repeated to impersonate multiple groups")
+
 	return cmd
 }
 
+// impersonate sets the user and groups given with --as and --as-group as the identity impersonated by the requests
+// made with the given REST config, so that the check runs with the RBAC permissions of that identity
+func (cmd *trafficPolicyCheckCmd) impersonate(config *rest.Config) {
+	if cmd.asUser == "" {
+		return
+	}
+	config.Impersonate = rest.ImpersonationConfig{
+		UserName: cmd.asUser,
+		Groups:   cmd.asGroups,
+	}
+}
+
 func (cmd *trafficPolicyCheckCmd) run() error {
+	switch cmd.destinationKind {
+	case "", destinationKindPod, destinationKindService:
+	default:
+		return withExitCode(checkExitCodeInvalidInput, errors.Errorf("Invalid value %q for flag --destination-kind, expected one of: %s, %s",
+			cmd.destinationKind, destinationKindPod, destinationKindService))
+	}
+	if cmd.byIP && cmd.destinationKind == destinationKindService {
+		return withExitCode(checkExitCodeInvalidInput, errors.Errorf("flag --by-ip requires the destination to be a pod, got --destination-kind %s", cmd.destinationKind))
+	}
+
+	if _, err := cmd.getOSMNamespace(); err != nil {
+		return withExitCode(checkExitCodeInvalidInput, err)
+	}
+
+	if err := validateIgnoredTargets(cmd.ignoredTargets); err != nil {
+		return withExitCode(checkExitCodeInvalidInput, err)
+	}
+
+	if len(cmd.headers) > 0 {
+		requestHeaders, err := parseRequestHeaders(cmd.headers)
+		if err != nil {
+			return withExitCode(checkExitCodeInvalidInput, err)
+		}
+		cmd.requestHeaders = requestHeaders
+	}
+
+	cmd.resetListCache()
+	cmd.printIgnoredTargets()
+
+	if cmd.fromFile != "" {
+		return cmd.runBatch()
+	}
+
+	if cmd.byIP {
+		sourcePod, destinationPod, err := cmd.resolvePodIPs(cmd.sourcePod, cmd.destinationPod)
+		if err != nil {
+			return err
+		}
+		cmd.sourcePod, cmd.destinationPod = sourcePod, destinationPod
+	}
+
+	if cmd.snapshotOut != "" {
+		if err := cmd.writeSnapshot(cmd.snapshotOut, cmd.sourcePod, cmd.destinationPod); err != nil {
+			return err
+		}
+	}
+
+	dstNs, check, err := cmd.getTrafficPolicyCheck(cmd.sourcePod, cmd.destinationPod)
+	if err != nil {
+		return err
+	}
+
+	if cmd.watch {
+		return withExitCode(checkExitCodeAPIError, cmd.watchTrafficPolicy(cmd.getTrafficTargetsNamespace(dstNs), check))
+	}
+	if cmd.wait > 0 {
+		return cmd.waitForAllowedTraffic(check)
+	}
+	allowed, err := check()
+	if err != nil {
+		return withExitCode(checkExitCodeAPIError, err)
+	}
+	if !allowed {
+		return withExitCode(checkExitCodeTrafficDenied, errors.Errorf("Pod %s is not allowed to communicate to %s", cmd.sourcePod, cmd.destinationPod))
+	}
+	return nil
+}
+
+// getTrafficPolicyCheck validates the given source pod and destination arguments, and returns the namespace of the
+// destination along with the function checking whether the source pod is allowed to communicate to the destination
+func (cmd *trafficPolicyCheckCmd) getTrafficPolicyCheck(sourcePod, destination string) (string, func() (bool, error), error) {
 	// Validate input for options
-	srcNs, srcPodName, err := unmarshalNamespacedPod(cmd.sourcePod)
+	srcNs, srcPodName, err := unmarshalNamespacedPod(sourcePod)
 	if err != nil {
-		return errors.Errorf("Invalid argument specified for the source pod [%s/%s]: %s", srcNs, srcPodName, err)
+		return "", nil, withExitCode(checkExitCodeInvalidInput, errors.Errorf("Invalid argument specified for the source pod [%s/%s]: %s", srcNs, srcPodName, err))
 	}
 
-	dstNs, dstPodName, err := unmarshalNamespacedPod(cmd.destinationPod)
+	dstNs, dstName, err := unmarshalNamespacedPod(destination)
 	if err != nil {
-		return errors.Errorf("Invalid argument specified for the destination pod [%s/%s]: %s", dstNs, dstPodName, err)
+		return "", nil, withExitCode(checkExitCodeInvalidInput, errors.Errorf("Invalid argument specified for the destination [%s/%s]: %s", dstNs, dstName, err))
+	}
+
+	if !cmd.skipRBACCheck {
+		if err := cmd.checkRBACPermissions(srcNs, dstNs); err != nil {
+			return "", nil, err
+		}
+	}
+
+	if err := cmd.validateNamespace(srcNs); err != nil {
+		return "", nil, err
+	}
+	if err := cmd.validateNamespace(dstNs); err != nil {
+		return "", nil, err
 	}
 
 	srcPod, err := cmd.getMeshedPod(srcNs, srcPodName)
 	if err != nil {
-		return err
+		return "", nil, err
 	}
-	dstPod, err := cmd.getMeshedPod(dstNs, dstPodName)
+
+	dstService, err := cmd.getDestinationService(dstNs, dstName)
 	if err != nil {
-		return err
+		return "", nil, err
+	}
+	if dstService != nil {
+		return dstNs, func() (bool, error) { return cmd.checkServiceTrafficPolicy(srcPod, dstService) }, nil
 	}
 
-	return cmd.checkTrafficPolicy(srcPod, dstPod)
+	getDestinationPod := cmd.getMeshedPod
+	if cmd.allowNonMeshed {
+		getDestinationPod = cmd.getPod
+	}
+	dstPod, err := getDestinationPod(dstNs, dstName)
+	if err != nil {
+		return "", nil, err
+	}
+	if !isMeshedPod(*dstPod) {
+		return dstNs, func() (bool, error) { return cmd.checkEgressTrafficPolicy(srcPod, dstPod) }, nil
+	}
+	return dstNs, func() (bool, error) { return cmd.checkTrafficPolicy(srcPod, dstPod) }, nil
 }
 
-func (cmd *trafficPolicyCheckCmd) checkTrafficPolicy(srcPod, dstPod *corev1.Pod) error {
-	osmNamespace := settings.Namespace()
+// checkTrafficPolicy prints whether 'srcPod' is allowed to communicate to 'dstPod' and returns the decision
+func (cmd *trafficPolicyCheckCmd) checkTrafficPolicy(srcPod, dstPod *corev1.Pod) (bool, error) {
+	osmNamespace, err := cmd.getOSMNamespace()
+	if err != nil {
+		return false, err
+	}
 
 	// Check if permissive mode is enabled, in which case every meshed pod is allowed to communicate with each other
-	if permissiveMode, err := cmd.isPermissiveModeEnabled(); err != nil {
-		return errors.Errorf("Error checking if permissive mode is enabled: %s", err)
-	} else if permissiveMode {
+	permissiveMode, err := cmd.isPermissiveModeEnabled()
+	if err != nil {
+		return false, errors.Errorf("Error checking if permissive mode is enabled: %s", err)
+	}
+	cmd.tracef("Permissive traffic policy mode of mesh config '%s/%s': %t", osmNamespace, cmd.meshConfigName, permissiveMode)
+	if permissiveMode {
 		fmt.Fprintf(cmd.out, "[+] Permissive mode enabled for mesh operated by osm-controller running in '%s' namespace\n\n "+
 			"[+] Pod '%s/%s' is allowed to communicate to pod '%s/%s'\n",
 			osmNamespace, srcPod.Namespace, srcPod.Name, dstPod.Namespace, dstPod.Name)
-		return nil
+		cmd.checkResult.record(true, nil)
+		if cmd.requireSMI {
+			if err := cmd.checkRequiredTrafficTargets(srcPod, dstPod); err != nil {
+				return false, err
+			}
+		}
+		return true, cmd.checkTrafficSplits(srcPod, dstPod, true, nil)
 	}
 
 	// SMI traffic policy mode
 	fmt.Fprintf(cmd.out, "[+] SMI traffic policy mode enabled for mesh operated by osm-controller running in %s namespace\n\n", osmNamespace)
-	trafficTargets, err := cmd.smiAccessClient.AccessV1alpha3().TrafficTargets(dstPod.Namespace).List(context.TODO(), metav1.ListOptions{})
+	trafficTargetsNamespace := cmd.getTrafficTargetsNamespace(dstPod.Namespace)
+	trafficTargets, err := cmd.listTrafficTargets(trafficTargetsNamespace)
 	if err != nil {
-		return errors.Errorf("Error listing SMI TrafficTarget policies: %s", err)
+		return false, err
+	}
+	cmd.tracef("Listed %d SMI TrafficTarget policies in %s", len(trafficTargets), describeNamespace(trafficTargetsNamespace))
+	cmd.traceTrafficTargets(trafficTargets, srcPod, dstPod.Namespace, dstPod.Spec.ServiceAccountName)
+
+	allowingTrafficTargets := getAllowingTrafficTargets(trafficTargets, srcPod, dstPod.Namespace, dstPod.Spec.ServiceAccountName)
+	cmd.tracef("%d SMI TrafficTarget policies allow pod '%s/%s' to communicate to pod '%s/%s'",
+		len(allowingTrafficTargets), srcPod.Namespace, srcPod.Name, dstPod.Namespace, dstPod.Name)
+	deniedByHeaders := false
+	if len(allowingTrafficTargets) > 0 {
+		if allowingTrafficTargets, err = cmd.filterTrafficTargetsByHeaders(allowingTrafficTargets); err != nil {
+			return false, err
+		}
+		deniedByHeaders = len(allowingTrafficTargets) == 0
+	}
+	for _, trafficTarget := range allowingTrafficTargets {
+		fmt.Fprintf(cmd.out, "[+] Pod '%s/%s' is allowed to communicate to pod '%s/%s' via the SMI TrafficTarget policy %q:\n",
+			srcPod.Namespace, srcPod.Name, dstPod.Namespace, dstPod.Name, trafficTarget.Name)
+		if err := cmd.printTrafficTarget(trafficTarget); err != nil {
+			return false, err
+		}
+		cmd.printMatchingSource(trafficTarget, srcPod)
+	}
+
+	cmd.checkResult.record(false, allowingTrafficTargets)
+	allowed := len(allowingTrafficTargets) > 0
+	if allowed {
+		cmd.warnDanglingReferences(allowingTrafficTargets)
+		if err := cmd.printAllowedRoutes(allowingTrafficTargets); err != nil {
+			return false, err
+		}
+		if cmd.showStats {
+			cmd.printDestinationStats(dstPod)
+		}
+	} else if deniedByHeaders {
+		fmt.Fprintf(cmd.out, "[+] Pod '%s/%s' is not allowed to communicate to pod '%s/%s' with the headers of the request, no SMI route matches them\n",
+			srcPod.Namespace, srcPod.Name, dstPod.Namespace, dstPod.Name)
+	} else {
+		fmt.Fprintf(cmd.out, "[+] Pod '%s/%s' is not allowed to communicate to pod '%s/%s', missing SMI TrafficTarget policy\n",
+			srcPod.Namespace, srcPod.Name, dstPod.Namespace, dstPod.Name)
+		if cmd.explainDeny {
+			if err := cmd.printDenyRemediation(srcPod, dstPod.Namespace, dstPod.Spec.ServiceAccountName); err != nil {
+				return false, err
+			}
+		}
+	}
+
+	return allowed, cmd.checkTrafficSplits(srcPod, dstPod, false, trafficTargets)
+}
+
+// checkRequiredTrafficTargets prints whether the SMI TrafficTarget policies would allow 'srcPod' to communicate to
+// 'dstPod' if permissive mode was disabled, and warns when they would not
+func (cmd *trafficPolicyCheckCmd) checkRequiredTrafficTargets(srcPod, dstPod *corev1.Pod) error {
+	trafficTargets, err := cmd.listTrafficTargets(cmd.getTrafficTargetsNamespace(dstPod.Namespace))
+	if err != nil {
+		return err
 	}
 
-	var foundTrafficTarget bool
-	for _, trafficTarget := range trafficTargets.Items {
+	allowingTrafficTargets := getAllowingTrafficTargets(trafficTargets, srcPod, dstPod.Namespace, dstPod.Spec.ServiceAccountName)
+	if len(allowingTrafficTargets) == 0 {
+		fmt.Fprintf(cmd.out, "\n[!] Warning: Pod '%s/%s' would not be allowed to communicate to pod '%s/%s' with permissive mode disabled, missing SMI TrafficTarget policy\n",
+			srcPod.Namespace, srcPod.Name, dstPod.Namespace, dstPod.Name)
+		if cmd.explainDeny {
+			return cmd.printDenyRemediation(srcPod, dstPod.Namespace, dstPod.Spec.ServiceAccountName)
+		}
+		return nil
+	}
+
+	for _, trafficTarget := range allowingTrafficTargets {
+		fmt.Fprintf(cmd.out, "\n[+] Pod '%s/%s' would also be allowed to communicate to pod '%s/%s' with permissive mode disabled, via the SMI TrafficTarget policy %q\n",
+			srcPod.Namespace, srcPod.Name, dstPod.Namespace, dstPod.Name, trafficTarget.Name)
+	}
+	return nil
+}
+
+// warnDanglingReferences prints a warning for each of the given TrafficTargets referencing routes or service accounts
+// that do not exist, which the validating webhook rejects when validateTrafficTargetReferences is enabled in the chart.
+// The service accounts are not part of snapshots, so the references are not checked with --from-snapshot.
+func (cmd *trafficPolicyCheckCmd) warnDanglingReferences(trafficTargets []smiAccess.TrafficTarget) {
+	if cmd.fromSnapshot != "" {
+		return
+	}
+	for i := range trafficTargets {
+		if err := smi.ValidateTrafficTargetReferences(&trafficTargets[i], cmd.clientSet, cmd.smiSpecClient); err != nil {
+			fmt.Fprintf(cmd.out, "[!] Warning: %s\n", err)
+		}
+	}
+}
+
+// printTrafficTarget prints the given TrafficTarget as YAML
+func (cmd *trafficPolicyCheckCmd) printTrafficTarget(trafficTarget smiAccess.TrafficTarget) error {
+	trafficTargetPolicy, err := yaml.Marshal(&trafficTarget)
+	if err != nil {
+		return errors.Errorf("Failed to marshal TrafficTarget %s: %s", trafficTarget.Name, err)
+	}
+	fmt.Fprintf(cmd.out, "---\n%s\n---\n", string(trafficTargetPolicy))
+	return nil
+}
+
+// getAllowingTrafficTargets returns the TrafficTargets allowing 'srcPod' to send traffic to the given destination service account
+func getAllowingTrafficTargets(trafficTargets []smiAccess.TrafficTarget, srcPod *corev1.Pod, dstNamespace, dstServiceAccount string) []smiAccess.TrafficTarget {
+	var allowingTrafficTargets []smiAccess.TrafficTarget
+	for _, trafficTarget := range trafficTargets {
 		spec := trafficTarget.Spec
 		if spec.Destination.Kind != serviceAccountKind {
 			continue
 		}
 
-		// Map traffic targets to the given pods
-		if spec.Destination.Name == dstPod.Spec.ServiceAccountName && spec.Destination.Namespace == dstPod.Namespace {
-			// The TrafficTarget destination is associated to 'dstPod'
+		// Map traffic targets to the given destination
+		if spec.Destination.Name != dstServiceAccount || spec.Destination.Namespace != dstNamespace {
+			continue
+		}
 
-			// Check if 'srcPod` is an allowed source to this destination
-			for _, source := range spec.Sources {
-				if source.Kind != serviceAccountKind {
-					continue
-				}
+		// Check if 'srcPod` is an allowed source to this destination
+		if getMatchingSourceIndex(trafficTarget, srcPod) >= 0 {
+			allowingTrafficTargets = append(allowingTrafficTargets, trafficTarget)
+		}
+	}
+	return allowingTrafficTargets
+}
+
+// getMatchingSourceIndex returns the index of the first source of the given TrafficTarget matching the service
+// account of 'srcPod', or -1 if none matches
+func getMatchingSourceIndex(trafficTarget smiAccess.TrafficTarget, srcPod *corev1.Pod) int {
+	for i, source := range trafficTarget.Spec.Sources {
+		if source.Kind != serviceAccountKind {
+			continue
+		}
+		if source.Name == srcPod.Spec.ServiceAccountName && source.Namespace == srcPod.Namespace {
+			return i
+		}
+	}
+	return -1
+}
 
-				if source.Name == srcPod.Spec.ServiceAccountName && source.Namespace == srcPod.Namespace {
-					fmt.Fprintf(cmd.out, "[+] Pod '%s/%s' is allowed to communicate to pod '%s/%s' via the SMI TrafficTarget policy %q:\n",
-						srcPod.Namespace, srcPod.Name, dstPod.Namespace, dstPod.Name, trafficTarget.Name)
-					foundTrafficTarget = true
+// printMatchingSource prints the source of the given TrafficTarget granting access to 'srcPod', to tell which entry
+// allows the traffic among the sources of the TrafficTarget
+func (cmd *trafficPolicyCheckCmd) printMatchingSource(trafficTarget smiAccess.TrafficTarget, srcPod *corev1.Pod) {
+	i := getMatchingSourceIndex(trafficTarget, srcPod)
+	if i < 0 {
+		return
+	}
+	source := trafficTarget.Spec.Sources[i]
+	fmt.Fprintf(cmd.out, "[+] Access is granted by source %d of %d of the SMI TrafficTarget policy %q: %s '%s/%s'\n",
+		i+1, len(trafficTarget.Spec.Sources), trafficTarget.Name, source.Kind, source.Namespace, source.Name)
+}
 
-					target := trafficTarget // avoids gosec G601: Implicit memory aliasing in for loop
-					trafficTargetPolicy, err := yaml.Marshal(&target)
-					if err != nil {
-						return errors.Errorf("Failed to marshal TrafficTarget %s: %s", trafficTarget.Name, err)
-					}
-					fmt.Fprintf(cmd.out, "---\n%s\n---\n", string(trafficTargetPolicy))
-				}
-			}
+// validateNamespace returns an error suggesting similarly named namespaces and listing the meshed namespaces
+// if the given namespace does not exist
+func (cmd *trafficPolicyCheckCmd) validateNamespace(namespace string) error {
+	_, err := cmd.clientSet.CoreV1().Namespaces().Get(context.TODO(), namespace, metav1.GetOptions{})
+	if err == nil {
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return withExitCode(checkExitCodeAPIError, errors.Errorf("Error fetching namespace %s: %s", namespace, err))
+	}
+
+	namespaces, err := cmd.clientSet.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return withExitCode(checkExitCodeInvalidInput, errors.Errorf("Namespace %s does not exist", namespace))
+	}
+
+	var existingNamespaces, meshedNamespaces []string
+	for _, ns := range namespaces.Items {
+		existingNamespaces = append(existingNamespaces, ns.Name)
+		if _, ok := ns.Labels[constants.OSMKubeResourceMonitorAnnotation]; ok {
+			meshedNamespaces = append(meshedNamespaces, ns.Name)
 		}
 	}
 
-	if !foundTrafficTarget {
-		fmt.Fprintf(cmd.out, "[+] Pod '%s/%s' is not allowed to communicate to pod '%s/%s', missing SMI TrafficTarget policy\n",
-			srcPod.Namespace, srcPod.Name, dstPod.Namespace, dstPod.Name)
+	var notes []string
+	if suggestions := getClosestMatches(namespace, existingNamespaces, maxNamespaceSuggestionDistance); len(suggestions) > 0 {
+		notes = append(notes, fmt.Sprintf("Did you mean: %s?", strings.Join(suggestions, ", ")))
+	}
+	if len(meshedNamespaces) > 0 {
+		notes = append(notes, fmt.Sprintf("Meshed namespaces: %s", strings.Join(meshedNamespaces, ", ")))
+	} else {
+		notes = append(notes, "No namespaces are part of a mesh")
 	}
 
-	return nil
+	return withExitCode(checkExitCodeInvalidInput, annotateErrorMessageWithActionableMessage(strings.Join(notes, "\n"), "Namespace %s does not exist", namespace))
 }
 
 func (cmd *trafficPolicyCheckCmd) getMeshedPod(namespace, podName string) (*corev1.Pod, error) {
-	// Validate the pods
-	pod, err := cmd.clientSet.CoreV1().Pods(namespace).Get(context.TODO(), podName, metav1.GetOptions{})
+	pod, err := cmd.getPod(namespace, podName)
 	if err != nil {
-		return nil, errors.Errorf("Could not find pod %s in namespace %s", podName, namespace)
+		return nil, err
 	}
 	if !isMeshedPod(*pod) {
-		return nil, errors.Errorf("Pod %s in namespace %s is not a part of a mesh", podName, namespace)
+		return nil, withExitCode(checkExitCodeInvalidInput, errors.Errorf("Pod %s in namespace %s is not a part of a mesh", podName, namespace))
 	}
 	return pod, nil
 }
 
-func (cmd *trafficPolicyCheckCmd) isPermissiveModeEnabled() (bool, error) {
-	osmNamespace := settings.Namespace()
-	configMap, err := cmd.clientSet.CoreV1().ConfigMaps(osmNamespace).Get(context.TODO(), osmConfigMapName, metav1.GetOptions{})
+// getPod returns the given pod, whether it is a part of a mesh or not
+func (cmd *trafficPolicyCheckCmd) getPod(namespace, podName string) (*corev1.Pod, error) {
+	pod, err := cmd.clientSet.CoreV1().Pods(namespace).Get(context.TODO(), podName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, withExitCode(checkExitCodeInvalidInput, errors.Errorf("Could not find pod %s in namespace %s", podName, namespace))
+	}
 	if err != nil {
-		return false, errors.Errorf("Error checking if permissive mode is enabled: %s", err)
+		return nil, withExitCode(checkExitCodeAPIError, errors.Errorf("Error fetching pod %s in namespace %s: %s", podName, namespace, err))
 	}
+	return pod, nil
+}
 
-	configVal, err := configurator.GetBoolValueForKey(configMap, configurator.PermissiveTrafficPolicyModeKey)
+// resetListCache discards the resources listed so far, unless caching is disabled with --no-cache
+func (cmd *trafficPolicyCheckCmd) resetListCache() {
+	if !cmd.noCache {
+		cmd.listCache = newListCache()
+	}
+}
+
+// listTrafficTargets returns the SMI TrafficTargets in the given namespace, or in every namespace when empty
+func (cmd *trafficPolicyCheckCmd) listTrafficTargets(namespace string) ([]smiAccess.TrafficTarget, error) {
+	trafficTargets, err := cmd.listCache.get(namespace, trafficTargetKind, func() (interface{}, error) {
+		trafficTargets, err := cmd.smiAccessClient.AccessV1alpha3().TrafficTargets(namespace).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return nil, errors.Errorf("Error listing SMI TrafficTarget policies: %s", err)
+		}
+		return trafficTargets.Items, nil
+	})
 	if err != nil {
-		return false, errors.Errorf("Invalid value for key %q in %s/%s ConfigMap: %s", configurator.PermissiveTrafficPolicyModeKey, configMap.Namespace, configMap.Name, err)
+		return nil, err
+	}
+	return cmd.filterIgnoredTargets(trafficTargets.([]smiAccess.TrafficTarget)), nil
+}
+
+// getTrafficTargetsNamespace returns the namespace whose SMI TrafficTargets are scanned for the policies applying to
+// a destination in the given namespace, which is every namespace with --all-namespaces
+func (cmd *trafficPolicyCheckCmd) getTrafficTargetsNamespace(dstNamespace string) string {
+	if cmd.allNamespaces {
+		return metav1.NamespaceAll
 	}
-	return configVal, nil
+	return dstNamespace
 }
 
-func unmarshalNamespacedPod(namespacedPod string) (namespace string, podName string, err error) {
+// describeNamespace returns a description of the given namespace, which is every namespace when empty
+func describeNamespace(namespace string) string {
+	if namespace == metav1.NamespaceAll {
+		return "all namespaces"
+	}
+	return fmt.Sprintf("namespace %s", namespace)
+}
+
+// getOSMNamespace returns the namespace of the control plane of the mesh given with --mesh-name, or the namespace
+// given with --osm-namespace when no mesh name is set
+func (cmd *trafficPolicyCheckCmd) getOSMNamespace() (string, error) {
+	if cmd.osmNamespace == "" {
+		osmNamespace, err := getMeshNamespace(cmd.clientSet, cmd.meshName)
+		if err != nil {
+			return "", err
+		}
+		cmd.osmNamespace = osmNamespace
+	}
+	return cmd.osmNamespace, nil
+}
+
+func (cmd *trafficPolicyCheckCmd) isPermissiveModeEnabled() (bool, error) {
+	osmNamespace, err := cmd.getOSMNamespace()
+	if err != nil {
+		return false, err
+	}
+	return getPermissiveTrafficPolicyMode(cmd.clientSet, osmNamespace, cmd.meshConfigName)
+}
+
+// unmarshalNamespacedPod returns the namespace and name of the given <namespace/pod> or <pod> identifier, where the
+// namespace defaults to the default namespace. The namespace must be a valid DNS-1123 label and the pod name a valid
+// DNS-1123 subdomain, as required by Kubernetes.
+func unmarshalNamespacedPod(namespacedPod string) (string, string, error) {
 	if namespacedPod == "" {
-		err = errors.Errorf("Pod name should be of the form <namespace/pod>, or <pod> for default namespace, cannot be empty")
-		return
+		return "", "", errors.Errorf("Pod name should be of the form <namespace/pod>, or <pod> for default namespace, cannot be empty")
 	}
+
+	var namespace, podName string
 	chunks := strings.Split(namespacedPod, namespaceSeparator)
-	if len(chunks) == 1 {
+	switch len(chunks) {
+	case 1:
 		namespace = metav1.NamespaceDefault
 		podName = chunks[0]
-	} else if len(chunks) == 2 {
+	case 2:
 		namespace = chunks[0]
 		podName = chunks[1]
-	} else {
-		err = errors.Errorf("Pod name should be of the form <namespace/pod>, or <pod> for default namespace, got: %s", namespacedPod)
+	default:
+		return "", "", errors.Errorf("Pod name should be of the form <namespace/pod>, or <pod> for default namespace, got: %s", namespacedPod)
+	}
+
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return "", "", errors.Errorf("Invalid namespace %q in %s: %s", namespace, namespacedPod, strings.Join(errs, "; "))
+	}
+	if errs := validation.IsDNS1123Subdomain(podName); len(errs) > 0 {
+		return "", "", errors.Errorf("Invalid pod name %q in %s: %s", podName, namespacedPod, strings.Join(errs, "; "))
 	}
-	return
+	return namespace, podName, nil
 }
@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+)
+
+const (
+	// stdinFileName is the --from-file value used to read the pod pairs from stdin
+	stdinFileName = "-"
+
+	// defaultCheckConcurrency is the default number of pod pairs checked concurrently with --from-file
+	defaultCheckConcurrency = 8
+
+	// permissiveModeReason, allowedReason and deniedReason are the reasons listed in the results of --from-file
+	permissiveModeReason = "permissive traffic policy mode"
+	allowedReason        = "allowed by SMI TrafficTarget policy"
+	deniedReason         = "missing SMI TrafficTarget policy"
+)
+
+// checkResultColumns are the columns of the table of results printed with --from-file
+var checkResultColumns = []tableColumn{
+	{name: "src", header: "SOURCE"},
+	{name: "dst", header: "DESTINATION"},
+	{name: "allowed", header: "ALLOWED"},
+	{name: "reason", header: "REASON"},
+	{name: "targets", header: "TRAFFIC-TARGETS"},
+}
+
+// defaultCheckResultColumns are the columns of the table of results printed with --from-file when --columns is unset
+var defaultCheckResultColumns = []string{"src", "dst", "allowed", "reason"}
+
+// podPairCheckResult is the outcome of the check of a pod pair, along with the output of the check
+type podPairCheckResult struct {
+	allowed        bool
+	permissiveMode bool
+	trafficTargets []string
+	err            error
+	output         string
+
+	// egressReason is the reason of the decision when the destination is not a part of a mesh
+	egressReason string
+}
+
+// record records that the pair was checked in permissive mode or against the given allowing TrafficTargets
+func (r *podPairCheckResult) record(permissiveMode bool, trafficTargets []smiAccess.TrafficTarget) {
+	if r == nil {
+		return
+	}
+	r.permissiveMode = permissiveMode
+	for _, trafficTarget := range trafficTargets {
+		r.trafficTargets = append(r.trafficTargets, trafficTarget.Name)
+	}
+}
+
+// recordEgress records that the pair was checked as egress traffic, with the given reason of the decision
+func (r *podPairCheckResult) recordEgress(reason string) {
+	if r == nil {
+		return
+	}
+	r.egressReason = reason
+}
+
+// getReason returns the reason why the pair is allowed or not to communicate, or why it could not be checked
+func (r *podPairCheckResult) getReason() string {
+	switch {
+	case r.err != nil:
+		// Only the first line of the error is listed, to keep a row per pair
+		return strings.SplitN(r.err.Error(), "\n", 2)[0]
+	case r.egressReason != "":
+		return r.egressReason
+	case r.permissiveMode:
+		return permissiveModeReason
+	case r.allowed:
+		return allowedReason
+	default:
+		return deniedReason
+	}
+}
+
+// runBatch checks every 'SOURCE_POD DESTINATION_POD' pair listed in the --from-file input. Empty lines and lines
+// starting with '#' are ignored. The results are then printed as a table with the columns given with --columns. An error
+// is returned if any pair is not allowed to communicate or could not be checked, with the exit code of the most severe
+// outcome among the pairs.
+func (cmd *trafficPolicyCheckCmd) runBatch() error {
+	in := cmd.in
+	if cmd.fromFile != stdinFileName {
+		fd, err := os.Open(cmd.fromFile)
+		if err != nil {
+			return withExitCode(checkExitCodeInvalidInput, errors.Errorf("Error opening file %s: %s", cmd.fromFile, err))
+		}
+		defer fd.Close() //nolint: errcheck, gosec
+		in = fd
+	}
+
+	if cmd.concurrency < 1 {
+		return withExitCode(checkExitCodeInvalidInput, errors.Errorf("Invalid value %d for flag --concurrency, must be at least 1", cmd.concurrency))
+	}
+
+	if err := validateColumns(checkResultColumns, cmd.columns); err != nil {
+		return withExitCode(checkExitCodeInvalidInput, err)
+	}
+
+	pairs, err := readPodPairs(in)
+	if err != nil {
+		return withExitCode(checkExitCodeInvalidInput, err)
+	}
+
+	results := cmd.checkPodPairs(pairs)
+
+	// The results are printed in the order of the pairs in the input, regardless of the order in which they were checked
+	var allowedCount, deniedCount, failedCount int
+	failedExitCode := checkExitCodeInvalidInput
+	resultTable := newTable(checkResultColumns)
+	for i, pair := range pairs {
+		addCheckResultRow(resultTable, pair, results[i])
+
+		fmt.Fprintf(cmd.out, "[%d/%d] Checking pod '%s' -> pod '%s'\n", i+1, len(pairs), pair[0], pair[1])
+		fmt.Fprint(cmd.out, results[i].output)
+
+		if err := results[i].err; err != nil {
+			fmt.Fprintf(cmd.out, "[!] Error checking pod '%s' -> pod '%s': %s\n\n", pair[0], pair[1], err)
+			failedCount++
+			if getExitCode(err) == checkExitCodeAPIError {
+				failedExitCode = checkExitCodeAPIError
+			}
+			continue
+		}
+		if results[i].allowed {
+			allowedCount++
+		} else {
+			deniedCount++
+		}
+		fmt.Fprintln(cmd.out)
+	}
+
+	if err := resultTable.write(cmd.out, cmd.columns); err != nil {
+		return err
+	}
+	fmt.Fprintln(cmd.out)
+	fmt.Fprintf(cmd.out, "[+] Checked %d pod pair(s): %d allowed, %d denied, %d failed\n", len(pairs), allowedCount, deniedCount, failedCount)
+
+	if deniedCount > 0 || failedCount > 0 {
+		exitCode := checkExitCodeTrafficDenied
+		if failedCount > 0 {
+			exitCode = failedExitCode
+		}
+		return withExitCode(exitCode, errors.Errorf("%d of %d pod pair(s) are not allowed to communicate or could not be checked", deniedCount+failedCount, len(pairs)))
+	}
+	return nil
+}
+
+// checkPodPairs checks the given pod pairs with --concurrency workers and returns their results in the order of the pairs
+func (cmd *trafficPolicyCheckCmd) checkPodPairs(pairs [][2]string) []podPairCheckResult {
+	results := make([]podPairCheckResult, len(pairs))
+	pairIndexes := make(chan int)
+	var wg sync.WaitGroup
+	for worker := 0; worker < cmd.concurrency && worker < len(pairs); worker++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range pairIndexes {
+				results[i] = cmd.checkPodPair(pairs[i])
+			}
+		}()
+	}
+	for i := range pairs {
+		pairIndexes <- i
+	}
+	close(pairIndexes)
+	wg.Wait()
+
+	return results
+}
+
+// checkPodPair checks whether the source pod of the given pair is allowed to communicate to its destination. The output
+// of the check is buffered in the result so that the output of pairs checked concurrently does not interleave.
+func (cmd *trafficPolicyCheckCmd) checkPodPair(pair [2]string) podPairCheckResult {
+	var result podPairCheckResult
+	out := new(bytes.Buffer)
+	pairCmd := *cmd
+	pairCmd.out = out
+	pairCmd.checkResult = &result
+
+	sourcePod, destination := pair[0], pair[1]
+	var err error
+	if cmd.byIP {
+		sourcePod, destination, err = pairCmd.resolvePodIPs(sourcePod, destination)
+	}
+	var check func() (bool, error)
+	if err == nil {
+		_, check, err = pairCmd.getTrafficPolicyCheck(sourcePod, destination)
+	}
+	if err == nil {
+		result.allowed, err = check()
+		err = withExitCode(checkExitCodeAPIError, err)
+	}
+	result.err = err
+	result.output = out.String()
+	return result
+}
+
+// addCheckResultRow adds the result of the check of the given pair to the table of results
+func addCheckResultRow(t *table, pair [2]string, result podPairCheckResult) {
+	allowed := "-"
+	if result.err == nil {
+		allowed = strconv.FormatBool(result.allowed)
+	}
+	trafficTargets := "-"
+	if len(result.trafficTargets) > 0 {
+		trafficTargets = strings.Join(result.trafficTargets, ",")
+	}
+	t.addRow(pair[0], pair[1], allowed, result.getReason(), trafficTargets)
+}
+
+// readPodPairs returns the 'SOURCE_POD DESTINATION_POD' pairs read from the given input
+func readPodPairs(in io.Reader) ([][2]string, error) {
+	var pairs [][2]string
+	scanner := bufio.NewScanner(in)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			return nil, errors.Errorf("Invalid pod pair on line %d, expected 'SOURCE_POD DESTINATION_POD', got: %s", lineNum, line)
+		}
+		pairs = append(pairs, [2]string{fields[0], fields[1]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Errorf("Error reading pod pairs: %s", err)
+	}
+	if len(pairs) == 0 {
+		return nil, errors.New("No pod pairs to check")
+	}
+	return pairs, nil
+}
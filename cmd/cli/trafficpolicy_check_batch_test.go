@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	fakeAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned/fake"
+	fakeSplitClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/split/clientset/versioned/fake"
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+func TestReadPodPairs(t *testing.T) {
+	testCases := []struct {
+		input         string
+		expectedPairs [][2]string
+		expectError   bool
+	}{
+		{
+			input:         "ns-1/pod-1 ns-2/pod-2\n",
+			expectedPairs: [][2]string{{"ns-1/pod-1", "ns-2/pod-2"}},
+		},
+		{
+			input:         "# comment\n\n  ns-1/pod-1\tns-2/pod-2  \npod-3 pod-4",
+			expectedPairs: [][2]string{{"ns-1/pod-1", "ns-2/pod-2"}, {"pod-3", "pod-4"}},
+		},
+		{
+			input:       "ns-1/pod-1\n",
+			expectError: true,
+		},
+		{
+			input:       "ns-1/pod-1 ns-2/pod-2 ns-3/pod-3\n",
+			expectError: true,
+		},
+		{
+			input:       "# only comments\n",
+			expectError: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing testcase %d", i), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			pairs, err := readPodPairs(strings.NewReader(tc.input))
+			assert.Equal(tc.expectError, err != nil)
+			assert.Equal(tc.expectedPairs, pairs)
+		})
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	assert := tassert.New(t)
+
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-1"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-2"}},
+		newTestPod("ns-1", "pod-1", "sa-1", true),
+		newTestPod("ns-2", "pod-2", "sa-2", true),
+		newTestPod("ns-2", "pod-3", "sa-3", true),
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace: settings.Namespace(),
+				Name:      osmConfigMapName,
+			},
+			Data: map[string]string{
+				configurator.PermissiveTrafficPolicyModeKey: "false",
+			},
+		},
+	)
+	accessClient := fakeAccessClient.NewSimpleClientset(&smiAccess.TrafficTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1", Namespace: "ns-2"},
+		Spec: smiAccess.TrafficTargetSpec{
+			Destination: smiAccess.IdentityBindingSubject{Kind: "ServiceAccount", Name: "sa-2", Namespace: "ns-2"},
+			Sources:     []smiAccess.IdentityBindingSubject{{Kind: "ServiceAccount", Name: "sa-1", Namespace: "ns-1"}},
+		},
+	})
+
+	testCases := []struct {
+		name              string
+		input             string
+		concurrency       int
+		expectError       bool
+		expectedExitCode  int
+		expectedOutSubstr string
+	}{
+		{
+			name:              "all pairs allowed",
+			input:             "ns-1/pod-1 ns-2/pod-2\n",
+			concurrency:       defaultCheckConcurrency,
+			expectError:       false,
+			expectedOutSubstr: "Checked 1 pod pair(s): 1 allowed, 0 denied, 0 failed",
+		},
+		{
+			name:              "denied pairs",
+			input:             "ns-1/pod-1 ns-2/pod-2\nns-1/pod-1 ns-2/pod-3\n",
+			concurrency:       defaultCheckConcurrency,
+			expectError:       true,
+			expectedExitCode:  checkExitCodeTrafficDenied,
+			expectedOutSubstr: "Checked 2 pod pair(s): 1 allowed, 1 denied, 0 failed",
+		},
+		{
+			name:              "denied and failed pairs",
+			input:             "ns-1/pod-1 ns-2/pod-2\nns-1/pod-1 ns-2/pod-3\nns-1/pod-1 ns-2/pod-404\n",
+			concurrency:       defaultCheckConcurrency,
+			expectError:       true,
+			expectedExitCode:  checkExitCodeInvalidInput,
+			expectedOutSubstr: "Checked 3 pod pair(s): 1 allowed, 1 denied, 1 failed",
+		},
+		{
+			name:              "pairs checked serially",
+			input:             "ns-1/pod-1 ns-2/pod-2\nns-1/pod-1 ns-2/pod-3\n",
+			concurrency:       1,
+			expectError:       true,
+			expectedExitCode:  checkExitCodeTrafficDenied,
+			expectedOutSubstr: "Checked 2 pod pair(s): 1 allowed, 1 denied, 0 failed",
+		},
+		{
+			name:              "invalid concurrency",
+			input:             "ns-1/pod-1 ns-2/pod-2\n",
+			concurrency:       0,
+			expectError:       true,
+			expectedExitCode:  checkExitCodeInvalidInput,
+			expectedOutSubstr: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing %s", tc.name), func(t *testing.T) {
+			out := new(bytes.Buffer)
+			cmd := trafficPolicyCheckCmd{
+				in:              strings.NewReader(tc.input),
+				out:             out,
+				fromFile:        stdinFileName,
+				concurrency:     tc.concurrency,
+				clientSet:       fakeClient,
+				smiAccessClient: accessClient,
+				smiSplitClient:  fakeSplitClient.NewSimpleClientset(),
+				skipRBACCheck:   true,
+			}
+
+			err := cmd.run()
+			assert.Equal(tc.expectError, err != nil)
+			if tc.expectError {
+				assert.Equal(tc.expectedExitCode, getExitCode(err))
+			}
+			assert.Contains(out.String(), tc.expectedOutSubstr)
+		})
+	}
+}
+
+func TestRunBatchColumns(t *testing.T) {
+
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-1"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-2"}},
+		newTestPod("ns-1", "pod-1", "sa-1", true),
+		newTestPod("ns-2", "pod-2", "sa-2", true),
+		newTestPod("ns-2", "pod-3", "sa-3", true),
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: settings.Namespace(), Name: osmConfigMapName},
+			Data:       map[string]string{configurator.PermissiveTrafficPolicyModeKey: "false"},
+		},
+	)
+	accessClient := fakeAccessClient.NewSimpleClientset(&smiAccess.TrafficTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1", Namespace: "ns-2"},
+		Spec: smiAccess.TrafficTargetSpec{
+			Destination: smiAccess.IdentityBindingSubject{Kind: "ServiceAccount", Name: "sa-2", Namespace: "ns-2"},
+			Sources:     []smiAccess.IdentityBindingSubject{{Kind: "ServiceAccount", Name: "sa-1", Namespace: "ns-1"}},
+		},
+	})
+	input := "ns-1/pod-1 ns-2/pod-2\nns-1/pod-1 ns-2/pod-3\nns-1/pod-1 ns-2/pod-404\n"
+
+	testCases := []struct {
+		name              string
+		columns           []string
+		expectError       bool
+		expectedExitCode  int
+		expectedOutSubstr string
+	}{
+		{
+			name:             "default columns",
+			columns:          defaultCheckResultColumns,
+			expectError:      true,
+			expectedExitCode: checkExitCodeInvalidInput,
+			expectedOutSubstr: "SOURCE       DESTINATION    ALLOWED   REASON\n" +
+				"ns-1/pod-1   ns-2/pod-2     true      allowed by SMI TrafficTarget policy\n" +
+				"ns-1/pod-1   ns-2/pod-3     false     missing SMI TrafficTarget policy\n" +
+				"ns-1/pod-1   ns-2/pod-404   -         Could not find pod or service pod-404 in namespace ns-2\n",
+		},
+		{
+			name:             "selected columns",
+			columns:          []string{"dst", "targets"},
+			expectError:      true,
+			expectedExitCode: checkExitCodeInvalidInput,
+			expectedOutSubstr: "DESTINATION    TRAFFIC-TARGETS\n" +
+				"ns-2/pod-2     test-1\n" +
+				"ns-2/pod-3     -\n" +
+				"ns-2/pod-404   -\n",
+		},
+		{
+			name:              "invalid column",
+			columns:           []string{"src", "verdict"},
+			expectError:       true,
+			expectedExitCode:  checkExitCodeInvalidInput,
+			expectedOutSubstr: "",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing %s", tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			out := new(bytes.Buffer)
+			cmd := trafficPolicyCheckCmd{
+				in:              strings.NewReader(input),
+				out:             out,
+				fromFile:        stdinFileName,
+				concurrency:     defaultCheckConcurrency,
+				columns:         tc.columns,
+				clientSet:       fakeClient,
+				smiAccessClient: accessClient,
+				smiSplitClient:  fakeSplitClient.NewSimpleClientset(),
+				skipRBACCheck:   true,
+			}
+
+			err := cmd.run()
+			assert.Equal(tc.expectError, err != nil)
+			if tc.expectError {
+				assert.Equal(tc.expectedExitCode, getExitCode(err))
+			}
+			assert.Contains(out.String(), tc.expectedOutSubstr)
+		})
+	}
+}
+
+func TestRunBatchConcurrently(t *testing.T) {
+	assert := tassert.New(t)
+
+	var objects []runtime.Object
+	var input strings.Builder
+	objects = append(objects,
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-1"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-2"}},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: settings.Namespace(), Name: osmConfigMapName},
+			Data:       map[string]string{configurator.PermissiveTrafficPolicyModeKey: "false"},
+		},
+	)
+	const pairCount = 20
+	for i := 0; i < pairCount; i++ {
+		objects = append(objects,
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("src-%d", i), Namespace: "ns-1", Labels: map[string]string{constants.EnvoyUniqueIDLabelName: "test"}},
+				Spec:       corev1.PodSpec{ServiceAccountName: "sa-1"},
+			},
+			&corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("dst-%d", i), Namespace: "ns-2", Labels: map[string]string{constants.EnvoyUniqueIDLabelName: "test"}},
+				Spec:       corev1.PodSpec{ServiceAccountName: "sa-2"},
+			},
+		)
+		fmt.Fprintf(&input, "ns-1/src-%d ns-2/dst-%d\n", i, i)
+	}
+	accessClient := fakeAccessClient.NewSimpleClientset(&smiAccess.TrafficTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1", Namespace: "ns-2"},
+		Spec: smiAccess.TrafficTargetSpec{
+			Destination: smiAccess.IdentityBindingSubject{Kind: "ServiceAccount", Name: "sa-2", Namespace: "ns-2"},
+			Sources:     []smiAccess.IdentityBindingSubject{{Kind: "ServiceAccount", Name: "sa-1", Namespace: "ns-1"}},
+		},
+	})
+
+	out := new(bytes.Buffer)
+	cmd := trafficPolicyCheckCmd{
+		in:              strings.NewReader(input.String()),
+		out:             out,
+		fromFile:        stdinFileName,
+		concurrency:     4,
+		clientSet:       fake.NewSimpleClientset(objects...),
+		smiAccessClient: accessClient,
+		smiSplitClient:  fakeSplitClient.NewSimpleClientset(),
+		skipRBACCheck:   true,
+	}
+	assert.Nil(cmd.run())
+
+	// The output of each pair follows its header, in the order of the input
+	sections := strings.Split(out.String(), "Checking pod ")
+	assert.Len(sections, pairCount+1)
+	for i, section := range sections[1:] {
+		assert.True(strings.HasPrefix(section, fmt.Sprintf("'ns-1/src-%d' -> pod 'ns-2/dst-%d'\n", i, i)))
+		assert.Contains(section, fmt.Sprintf("Pod 'ns-1/src-%d' is allowed to communicate to pod 'ns-2/dst-%d'", i, i))
+	}
+
+	// The TrafficTargets of the destination namespace are listed once for all the pairs
+	var listCount int
+	for _, action := range accessClient.Actions() {
+		if action.GetVerb() == "list" && action.GetResource().Resource == "traffictargets" {
+			listCount++
+		}
+	}
+	assert.Equal(1, listCount)
+}
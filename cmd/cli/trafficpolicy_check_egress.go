@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	policyV1alpha1 "github.com/openservicemesh/osm/pkg/apis/policy/v1alpha1"
+)
+
+const (
+	// egressKind is the kind of the Egress policies of the policy.openservicemesh.io API group
+	egressKind = "Egress"
+
+	// egressEnabledReason, egressAllowedReason and egressDeniedReason are the reasons listed in the results of
+	// --from-file for the pairs whose destination is not a part of a mesh
+	egressEnabledReason = "egress enabled"
+	egressAllowedReason = "allowed by Egress policy"
+	egressDeniedReason  = "non-meshed destination, egress not allowed"
+)
+
+// checkEgressTrafficPolicy prints whether 'srcPod' is allowed to communicate to 'dstPod', which is not a part of a
+// mesh, and returns the decision. The traffic to a non-meshed pod leaves the mesh, so it is allowed when egress is
+// enabled mesh-wide, or when an Egress policy applying to the source pod lists an IP range of the destination pod.
+func (cmd *trafficPolicyCheckCmd) checkEgressTrafficPolicy(srcPod, dstPod *corev1.Pod) (bool, error) {
+	osmNamespace, err := cmd.getOSMNamespace()
+	if err != nil {
+		return false, err
+	}
+
+	fmt.Fprintf(cmd.out, "[!] Pod '%s/%s' is not a part of a mesh, the traffic to it is checked as egress traffic\n\n", dstPod.Namespace, dstPod.Name)
+
+	if egress, err := getEgressMode(cmd.clientSet, osmNamespace, cmd.meshConfigName); err != nil {
+		return false, errors.Errorf("Error checking if egress is enabled: %s", err)
+	} else if egress {
+		fmt.Fprintf(cmd.out, "[+] Egress enabled for mesh operated by osm-controller running in '%s' namespace\n\n "+
+			"[+] Pod '%s/%s' is allowed to communicate to non-meshed pod '%s/%s'\n",
+			osmNamespace, srcPod.Namespace, srcPod.Name, dstPod.Namespace, dstPod.Name)
+		cmd.checkResult.recordEgress(egressEnabledReason)
+		return true, nil
+	}
+
+	egressPolicies, err := cmd.listEgressPolicies()
+	if err != nil {
+		return false, err
+	}
+
+	allowingEgressPolicies := getAllowingEgressPolicies(egressPolicies, srcPod, dstPod)
+	for _, egressPolicy := range allowingEgressPolicies {
+		fmt.Fprintf(cmd.out, "[+] Pod '%s/%s' is allowed to communicate to non-meshed pod '%s/%s' via the Egress policy %q:\n",
+			srcPod.Namespace, srcPod.Name, dstPod.Namespace, dstPod.Name, egressPolicy.Name)
+		egressPolicyYAML, err := yaml.Marshal(&egressPolicy)
+		if err != nil {
+			return false, errors.Errorf("Failed to marshal Egress policy %s: %s", egressPolicy.Name, err)
+		}
+		fmt.Fprintf(cmd.out, "---\n%s\n---\n", string(egressPolicyYAML))
+	}
+
+	allowed := len(allowingEgressPolicies) > 0
+	if allowed {
+		cmd.checkResult.recordEgress(egressAllowedReason)
+	} else {
+		cmd.checkResult.recordEgress(egressDeniedReason)
+		reason := "no Egress policy applying to the source pod lists the IP addresses of the destination pod: " + strings.Join(getPodIPs(dstPod), ", ")
+		if len(getPodIPs(dstPod)) == 0 {
+			reason = "the destination pod has no IP address an Egress policy could allow"
+		}
+		fmt.Fprintf(cmd.out, "[+] Pod '%s/%s' is not allowed to communicate to non-meshed pod '%s/%s', egress is disabled and %s\n",
+			srcPod.Namespace, srcPod.Name, dstPod.Namespace, dstPod.Name, reason)
+	}
+	return allowed, nil
+}
+
+// listEgressPolicies returns the Egress policies of every namespace, since the policies applying to a source pod can
+// be defined in any namespace
+func (cmd *trafficPolicyCheckCmd) listEgressPolicies() ([]policyV1alpha1.Egress, error) {
+	egressPolicies, err := cmd.listCache.get(metav1.NamespaceAll, egressKind, func() (interface{}, error) {
+		egressPolicies, err := cmd.policyClient.PolicyV1alpha1().Egresses(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return nil, errors.Errorf("Error listing Egress policies: %s", err)
+		}
+		return egressPolicies.Items, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return egressPolicies.([]policyV1alpha1.Egress), nil
+}
+
+// getAllowingEgressPolicies returns the Egress policies applying to the service account of 'srcPod' and listing an IP
+// range that includes an IP address of 'dstPod'
+func getAllowingEgressPolicies(egressPolicies []policyV1alpha1.Egress, srcPod, dstPod *corev1.Pod) []policyV1alpha1.Egress {
+	dstIPs := getPodIPs(dstPod)
+
+	var allowingEgressPolicies []policyV1alpha1.Egress
+	for _, egressPolicy := range egressPolicies {
+		if !isEgressPolicySource(egressPolicy, srcPod) {
+			continue
+		}
+
+		for _, ipRange := range egressPolicy.Spec.IPAddresses {
+			if containsAnyIP(ipRange, dstIPs) {
+				allowingEgressPolicies = append(allowingEgressPolicies, egressPolicy)
+				break
+			}
+		}
+	}
+	return allowingEgressPolicies
+}
+
+// isEgressPolicySource returns whether the given Egress policy applies to the service account of the given pod
+func isEgressPolicySource(egressPolicy policyV1alpha1.Egress, pod *corev1.Pod) bool {
+	for _, source := range egressPolicy.Spec.Sources {
+		if source.Kind == serviceAccountKind && source.Name == pod.Spec.ServiceAccountName && source.Namespace == pod.Namespace {
+			return true
+		}
+	}
+	return false
+}
+
+// containsAnyIP returns whether the given IP range, or single IP address, includes any of the given IP addresses
+func containsAnyIP(ipRange string, ips []string) bool {
+	_, ipNet, err := net.ParseCIDR(ipRange)
+	for _, ip := range ips {
+		parsedIP := net.ParseIP(ip)
+		if parsedIP == nil {
+			continue
+		}
+		if err == nil && ipNet.Contains(parsedIP) {
+			return true
+		}
+		if err != nil && parsedIP.Equal(net.ParseIP(ipRange)) {
+			return true
+		}
+	}
+	return false
+}
+
+// getPodIPs returns the IP addresses of the given pod
+func getPodIPs(pod *corev1.Pod) []string {
+	var ips []string
+	for _, podIP := range pod.Status.PodIPs {
+		ips = append(ips, podIP.IP)
+	}
+	if len(ips) == 0 && pod.Status.PodIP != "" {
+		ips = append(ips, pod.Status.PodIP)
+	}
+	return ips
+}
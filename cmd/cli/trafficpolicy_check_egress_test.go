@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	fakeAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned/fake"
+	fakeSplitClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/split/clientset/versioned/fake"
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	policyV1alpha1 "github.com/openservicemesh/osm/pkg/apis/policy/v1alpha1"
+	"github.com/openservicemesh/osm/pkg/configurator"
+	"github.com/openservicemesh/osm/pkg/constants"
+	fakePolicyClient "github.com/openservicemesh/osm/pkg/gen/client/policy/clientset/versioned/fake"
+)
+
+func TestCheckEgressTrafficPolicy(t *testing.T) {
+	srcPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod-1",
+			Namespace: "ns-1",
+			Labels:    map[string]string{constants.EnvoyUniqueIDLabelName: "test"},
+		},
+		Spec: corev1.PodSpec{ServiceAccountName: "sa-1"},
+	}
+	dstPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "legacy-db", Namespace: "legacy"},
+		Status: corev1.PodStatus{
+			PodIP:  "10.1.2.3",
+			PodIPs: []corev1.PodIP{{IP: "10.1.2.3"}},
+		},
+	}
+	newEgressPolicy := func(name, sourceServiceAccount string, ipAddresses ...string) *policyV1alpha1.Egress {
+		return &policyV1alpha1.Egress{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns-1"},
+			Spec: policyV1alpha1.EgressSpec{
+				Sources:     []policyV1alpha1.SourceSpec{{Kind: serviceAccountKind, Name: sourceServiceAccount, Namespace: "ns-1"}},
+				IPAddresses: ipAddresses,
+				Ports:       []policyV1alpha1.PortSpec{{Number: 5432, Protocol: "tcp"}},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name              string
+		egress            string
+		egressPolicies    []runtime.Object
+		expectAllowed     bool
+		expectedReason    string
+		expectedOutSubstr string
+	}{
+		{
+			name:              "egress enabled mesh-wide",
+			egress:            "true",
+			expectAllowed:     true,
+			expectedReason:    egressEnabledReason,
+			expectedOutSubstr: "[+] Pod 'ns-1/pod-1' is allowed to communicate to non-meshed pod 'legacy/legacy-db'",
+		},
+		{
+			name:              "egress policy listing an IP range of the destination pod",
+			egress:            "false",
+			egressPolicies:    []runtime.Object{newEgressPolicy("legacy-db", "sa-1", "10.0.0.0/8")},
+			expectAllowed:     true,
+			expectedReason:    egressAllowedReason,
+			expectedOutSubstr: `is allowed to communicate to non-meshed pod 'legacy/legacy-db' via the Egress policy "legacy-db"`,
+		},
+		{
+			name:              "egress policy listing the IP address of the destination pod",
+			egress:            "false",
+			egressPolicies:    []runtime.Object{newEgressPolicy("legacy-db", "sa-1", "10.1.2.3")},
+			expectAllowed:     true,
+			expectedReason:    egressAllowedReason,
+			expectedOutSubstr: `via the Egress policy "legacy-db"`,
+		},
+		{
+			name:   "egress policies not matching the source pod or the destination IP",
+			egress: "false",
+			egressPolicies: []runtime.Object{
+				newEgressPolicy("other-source", "sa-2", "10.0.0.0/8"),
+				newEgressPolicy("other-range", "sa-1", "192.168.0.0/16"),
+			},
+			expectAllowed:     false,
+			expectedReason:    egressDeniedReason,
+			expectedOutSubstr: "egress is disabled and no Egress policy applying to the source pod lists the IP addresses of the destination pod: 10.1.2.3",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			out := new(bytes.Buffer)
+			result := &podPairCheckResult{}
+			cmd := trafficPolicyCheckCmd{
+				out: out,
+				clientSet: fake.NewSimpleClientset(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: settings.Namespace(), Name: osmConfigMapName},
+					Data: map[string]string{
+						configurator.PermissiveTrafficPolicyModeKey: "false",
+						configurator.EgressKey:                      tc.egress,
+					},
+				}),
+				policyClient:   fakePolicyClient.NewSimpleClientset(tc.egressPolicies...),
+				meshConfigName: osmConfigMapName,
+				checkResult:    result,
+			}
+
+			allowed, err := cmd.checkEgressTrafficPolicy(srcPod, dstPod)
+			assert.Nil(err)
+			assert.Equal(tc.expectAllowed, allowed)
+			assert.Equal(tc.expectedReason, result.getReason())
+			assert.Contains(out.String(), "[!] Pod 'legacy/legacy-db' is not a part of a mesh")
+			assert.Contains(out.String(), tc.expectedOutSubstr)
+		})
+	}
+}
+
+func TestTrafficPolicyCheckNonMeshedDestination(t *testing.T) {
+	testCases := []struct {
+		name             string
+		allowNonMeshed   bool
+		egress           string
+		expectedExitCode int
+	}{
+		{
+			name:             "non-meshed destination is invalid input by default",
+			allowNonMeshed:   false,
+			egress:           "true",
+			expectedExitCode: checkExitCodeInvalidInput,
+		},
+		{
+			name:             "non-meshed destination allowed by egress",
+			allowNonMeshed:   true,
+			egress:           "true",
+			expectedExitCode: 0,
+		},
+		{
+			name:             "non-meshed destination not allowed without egress",
+			allowNonMeshed:   true,
+			egress:           "false",
+			expectedExitCode: checkExitCodeTrafficDenied,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			fakeClient := fake.NewSimpleClientset(
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-1"}},
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "legacy"}},
+				&corev1.Pod{
+					ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "ns-1", Labels: map[string]string{constants.EnvoyUniqueIDLabelName: "test"}},
+					Spec:       corev1.PodSpec{ServiceAccountName: "sa-1"},
+				},
+				&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "legacy-db", Namespace: "legacy"}},
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: settings.Namespace(), Name: osmConfigMapName},
+					Data: map[string]string{
+						configurator.PermissiveTrafficPolicyModeKey: "false",
+						configurator.EgressKey:                      tc.egress,
+					},
+				},
+			)
+
+			cmd := trafficPolicyCheckCmd{
+				out:             new(bytes.Buffer),
+				sourcePod:       "ns-1/pod-1",
+				destinationPod:  "legacy/legacy-db",
+				allowNonMeshed:  tc.allowNonMeshed,
+				clientSet:       fakeClient,
+				smiAccessClient: fakeAccessClient.NewSimpleClientset(),
+				smiSplitClient:  fakeSplitClient.NewSimpleClientset(),
+				policyClient:    fakePolicyClient.NewSimpleClientset(),
+				meshConfigName:  osmConfigMapName,
+				skipRBACCheck:   true,
+			}
+
+			err := cmd.run()
+			if tc.expectedExitCode == 0 {
+				assert.Nil(err)
+				return
+			}
+			assert.NotNil(err)
+			assert.Equal(tc.expectedExitCode, getExitCode(err))
+		})
+	}
+}
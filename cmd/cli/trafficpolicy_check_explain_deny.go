@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiSpecs "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/specs/v1alpha4"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// remediationMatchName is the name of the match of the stub HTTPRouteGroup printed with --explain-deny
+	remediationMatchName = "all"
+
+	// remediationPathRegex is the path regex of the match of the stub HTTPRouteGroup printed with --explain-deny
+	remediationPathRegex = ".*"
+
+	// remediationMethod is the method of the match of the stub HTTPRouteGroup printed with --explain-deny
+	remediationMethod = "*"
+)
+
+// printDenyRemediation prints the SMI TrafficTarget and stub HTTPRouteGroup policies that would allow 'srcPod' to
+// communicate to the given destination service account, for the user to review and apply with kubectl
+func (cmd *trafficPolicyCheckCmd) printDenyRemediation(srcPod *corev1.Pod, dstNamespace, dstServiceAccount string) error {
+	routeGroup, trafficTarget := getRemediationPolicies(srcPod.Namespace, srcPod.Spec.ServiceAccountName, dstNamespace, dstServiceAccount)
+
+	fmt.Fprintf(cmd.out, "[+] The following SMI policies would allow service account '%s/%s' to communicate to service account '%s/%s'. "+
+		"The HTTPRouteGroup allows every request, restrict its matches to the requests to allow before applying them with 'kubectl apply -f':\n",
+		srcPod.Namespace, srcPod.Spec.ServiceAccountName, dstNamespace, dstServiceAccount)
+	for _, policy := range []interface{}{routeGroup, trafficTarget} {
+		policyYAML, err := yaml.Marshal(policy)
+		if err != nil {
+			return errors.Errorf("Error marshaling remediation policy: %s", err)
+		}
+		fmt.Fprintf(cmd.out, "---\n%s", policyYAML)
+	}
+	fmt.Fprintln(cmd.out)
+	return nil
+}
+
+// getRemediationPolicies returns a stub HTTPRouteGroup allowing every HTTP request, and the TrafficTarget allowing the
+// given source service account to send these requests to the given destination service account. Both policies are
+// defined in the namespace of the destination.
+func getRemediationPolicies(srcNamespace, srcServiceAccount, dstNamespace, dstServiceAccount string) (*smiSpecs.HTTPRouteGroup, *smiAccess.TrafficTarget) {
+	name := fmt.Sprintf("%s-to-%s", srcServiceAccount, dstServiceAccount)
+
+	routeGroup := &smiSpecs.HTTPRouteGroup{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: smiSpecs.SchemeGroupVersion.String(),
+			Kind:       httpRouteGroupKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: dstNamespace,
+		},
+		Spec: smiSpecs.HTTPRouteGroupSpec{
+			Matches: []smiSpecs.HTTPMatch{{
+				Name:      remediationMatchName,
+				PathRegex: remediationPathRegex,
+				Methods:   []string{remediationMethod},
+			}},
+		},
+	}
+
+	trafficTarget := &smiAccess.TrafficTarget{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: smiAccess.SchemeGroupVersion.String(),
+			Kind:       trafficTargetKind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: dstNamespace,
+		},
+		Spec: smiAccess.TrafficTargetSpec{
+			Destination: smiAccess.IdentityBindingSubject{
+				Kind:      serviceAccountKind,
+				Name:      dstServiceAccount,
+				Namespace: dstNamespace,
+			},
+			Sources: []smiAccess.IdentityBindingSubject{{
+				Kind:      serviceAccountKind,
+				Name:      srcServiceAccount,
+				Namespace: srcNamespace,
+			}},
+			Rules: []smiAccess.TrafficTargetRule{{
+				Kind:    httpRouteGroupKind,
+				Name:    name,
+				Matches: []string{remediationMatchName},
+			}},
+		},
+	}
+
+	return routeGroup, trafficTarget
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiSpecs "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/specs/v1alpha4"
+	fakeAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned/fake"
+	fakeSplitClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/split/clientset/versioned/fake"
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+)
+
+func TestGetRemediationPolicies(t *testing.T) {
+	assert := tassert.New(t)
+
+	routeGroup, trafficTarget := getRemediationPolicies("bookbuyer", "bookbuyer", "bookstore", "bookstore-v2")
+
+	assert.Equal("specs.smi-spec.io/v1alpha4", routeGroup.APIVersion)
+	assert.Equal(httpRouteGroupKind, routeGroup.Kind)
+	assert.Equal("bookbuyer-to-bookstore-v2", routeGroup.Name)
+	assert.Equal("bookstore", routeGroup.Namespace)
+	assert.Equal([]smiSpecs.HTTPMatch{{Name: "all", PathRegex: ".*", Methods: []string{"*"}}}, routeGroup.Spec.Matches)
+
+	assert.Equal("access.smi-spec.io/v1alpha3", trafficTarget.APIVersion)
+	assert.Equal(trafficTargetKind, trafficTarget.Kind)
+	assert.Equal("bookbuyer-to-bookstore-v2", trafficTarget.Name)
+	assert.Equal("bookstore", trafficTarget.Namespace)
+	assert.Equal(smiAccess.TrafficTargetSpec{
+		Destination: smiAccess.IdentityBindingSubject{Kind: serviceAccountKind, Name: "bookstore-v2", Namespace: "bookstore"},
+		Sources:     []smiAccess.IdentityBindingSubject{{Kind: serviceAccountKind, Name: "bookbuyer", Namespace: "bookbuyer"}},
+		Rules:       []smiAccess.TrafficTargetRule{{Kind: httpRouteGroupKind, Name: "bookbuyer-to-bookstore-v2", Matches: []string{"all"}}},
+	}, trafficTarget.Spec)
+}
+
+func TestCheckTrafficPolicyExplainDeny(t *testing.T) {
+
+	fakeClient := fake.NewSimpleClientset(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-1"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-2"}},
+		newTestPod("ns-1", "pod-1", "sa-1", true),
+		newTestPod("ns-2", "pod-2", "sa-2", true),
+		newTestPod("ns-2", "pod-3", "sa-3", true),
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: settings.Namespace(), Name: osmConfigMapName},
+			Data:       map[string]string{configurator.PermissiveTrafficPolicyModeKey: "false"},
+		},
+	)
+	accessClient := fakeAccessClient.NewSimpleClientset(&smiAccess.TrafficTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1", Namespace: "ns-2"},
+		Spec: smiAccess.TrafficTargetSpec{
+			Destination: smiAccess.IdentityBindingSubject{Kind: "ServiceAccount", Name: "sa-2", Namespace: "ns-2"},
+			Sources:     []smiAccess.IdentityBindingSubject{{Kind: "ServiceAccount", Name: "sa-1", Namespace: "ns-1"}},
+		},
+	})
+
+	newCmd := func(out *bytes.Buffer, destination string, explainDeny bool) *trafficPolicyCheckCmd {
+		return &trafficPolicyCheckCmd{
+			out:             out,
+			sourcePod:       "ns-1/pod-1",
+			destinationPod:  destination,
+			explainDeny:     explainDeny,
+			clientSet:       fakeClient,
+			smiAccessClient: accessClient,
+			smiSplitClient:  fakeSplitClient.NewSimpleClientset(),
+			skipRBACCheck:   true,
+		}
+	}
+
+	t.Run("remediation printed on deny", func(t *testing.T) {
+		assert := tassert.New(t)
+
+		out := new(bytes.Buffer)
+		err := newCmd(out, "ns-2/pod-3", true).run()
+		assert.NotNil(err)
+		assert.Equal(checkExitCodeTrafficDenied, getExitCode(err))
+
+		output := out.String()
+		assert.Contains(output, "The following SMI policies would allow service account 'ns-1/sa-1' to communicate to service account 'ns-2/sa-3'")
+
+		// The printed policies are the remediation policies of the pair, ready to be applied
+		documents := strings.Split(output, "---\n")
+		assert.Len(documents, 3)
+		expectedRouteGroup, expectedTrafficTarget := getRemediationPolicies("ns-1", "sa-1", "ns-2", "sa-3")
+		var routeGroup smiSpecs.HTTPRouteGroup
+		assert.Nil(yaml.Unmarshal([]byte(documents[1]), &routeGroup))
+		assert.Equal(*expectedRouteGroup, routeGroup)
+		var trafficTarget smiAccess.TrafficTarget
+		assert.Nil(yaml.Unmarshal([]byte(documents[2]), &trafficTarget))
+		assert.Equal(*expectedTrafficTarget, trafficTarget)
+	})
+
+	t.Run("remediation not printed without --explain-deny", func(t *testing.T) {
+		assert := tassert.New(t)
+
+		out := new(bytes.Buffer)
+		err := newCmd(out, "ns-2/pod-3", false).run()
+		assert.NotNil(err)
+		assert.NotContains(out.String(), "The following SMI policies")
+		assert.NotContains(out.String(), "---\n")
+	})
+
+	t.Run("remediation not printed on allow", func(t *testing.T) {
+		assert := tassert.New(t)
+
+		out := new(bytes.Buffer)
+		assert.Nil(newCmd(out, "ns-2/pod-2", true).run())
+		assert.NotContains(out.String(), "The following SMI policies")
+	})
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+)
+
+// parseRequestHeaders returns the HTTP headers given as 'name: value' or 'name=value' with --header, by lowercase name
+// since header names are case insensitive. Header names can contain neither ':' nor '=', so the first of them
+// separates the name from the value.
+func parseRequestHeaders(headers []string) (map[string]string, error) {
+	parsedHeaders := make(map[string]string)
+	for _, header := range headers {
+		separator := strings.IndexAny(header, ":=")
+		if separator < 0 || strings.TrimSpace(header[:separator]) == "" {
+			return nil, errors.Errorf("Invalid value %q for flag --header, expected 'name: value' or 'name=value'", header)
+		}
+		parsedHeaders[strings.ToLower(strings.TrimSpace(header[:separator]))] = strings.TrimSpace(header[separator+1:])
+	}
+	return parsedHeaders, nil
+}
+
+// getHeadersMismatch returns why the given request headers do not satisfy the header regexes of an HTTP match, or an
+// empty string if they do. A header of the match that is absent from the request does not match, and each regex must
+// match the whole value of the header, as enforced by the sidecars.
+func getHeadersMismatch(matchHeaders map[string]string, requestHeaders map[string]string) string {
+	var headerNames []string
+	for name := range matchHeaders {
+		headerNames = append(headerNames, name)
+	}
+	sort.Strings(headerNames)
+
+	for _, name := range headerNames {
+		headerRegex := matchHeaders[name]
+		value, ok := requestHeaders[strings.ToLower(name)]
+		if !ok {
+			return fmt.Sprintf("header %s is missing from the request", name)
+		}
+		matched, err := matchesWholeString(headerRegex, value)
+		if err != nil {
+			return fmt.Sprintf("invalid regex %q for header %s: %s", headerRegex, name, err)
+		}
+		if !matched {
+			return fmt.Sprintf("value %q of header %s does not match the regex %q", value, name, headerRegex)
+		}
+	}
+	return ""
+}
+
+// filterTrafficTargetsByHeaders returns the given TrafficTargets with a rule allowing a request with the headers given
+// with --header, or the given TrafficTargets when no headers are given. A TCPRoute rule allows the request whatever
+// its headers, while an HTTPRouteGroup rule allows it if the headers satisfy one of its matches.
+func (cmd *trafficPolicyCheckCmd) filterTrafficTargetsByHeaders(trafficTargets []smiAccess.TrafficTarget) ([]smiAccess.TrafficTarget, error) {
+	if cmd.requestHeaders == nil {
+		return trafficTargets, nil
+	}
+
+	var filteredTrafficTargets []smiAccess.TrafficTarget
+	for _, trafficTarget := range trafficTargets {
+		allowed, err := cmd.allowsRequestHeaders(trafficTarget)
+		if err != nil {
+			return nil, err
+		}
+		if !allowed {
+			fmt.Fprintf(cmd.out, "[-] No route of the SMI TrafficTarget policy %q matches the headers of the request\n", trafficTarget.Name)
+			continue
+		}
+		filteredTrafficTargets = append(filteredTrafficTargets, trafficTarget)
+	}
+	return filteredTrafficTargets, nil
+}
+
+// allowsRequestHeaders returns whether a rule of the given TrafficTarget allows a request with the headers given with
+// --header
+func (cmd *trafficPolicyCheckCmd) allowsRequestHeaders(trafficTarget smiAccess.TrafficTarget) (bool, error) {
+	for _, rule := range trafficTarget.Spec.Rules {
+		switch rule.Kind {
+		case tcpRouteKind:
+			_, found, err := cmd.describeTCPRoute(trafficTarget.Namespace, rule.Name)
+			if err != nil {
+				return false, err
+			}
+			cmd.tracef("TCPRoute %s of SMI TrafficTarget policy %q exists: %t, TCP routes do not match headers", rule.Name, trafficTarget.Name, found)
+			if found {
+				return true, nil
+			}
+
+		case httpRouteGroupKind:
+			routeGroup, err := cmd.getHTTPRouteGroup(trafficTarget.Namespace, rule.Name)
+			if err != nil {
+				return false, err
+			}
+			if routeGroup == nil {
+				cmd.tracef("HTTPRouteGroup %s of SMI TrafficTarget policy %q not found", rule.Name, trafficTarget.Name)
+				continue
+			}
+			for _, resolved := range resolveHTTPRouteMatches(routeGroup, rule.Matches) {
+				if resolved.match == nil {
+					continue
+				}
+				if mismatch := getHeadersMismatch(resolved.match.Headers, cmd.requestHeaders); mismatch != "" {
+					cmd.tracef("Match %q of HTTPRouteGroup %s does not match the headers of the request: %s", resolved.name, rule.Name, mismatch)
+					continue
+				}
+				cmd.tracef("Match %q of HTTPRouteGroup %s matches the headers of the request", resolved.name, rule.Name)
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiSpecs "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/specs/v1alpha4"
+	fakeAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned/fake"
+	fakeSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned/fake"
+	fakeSplitClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/split/clientset/versioned/fake"
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+	"github.com/openservicemesh/osm/pkg/identity"
+)
+
+func TestParseRequestHeaders(t *testing.T) {
+	testCases := []struct {
+		name            string
+		headers         []string
+		expectedHeaders map[string]string
+		expectErr       bool
+	}{
+		{
+			name:            "colon separated header",
+			headers:         []string{"User-Agent: curl/7.68.0"},
+			expectedHeaders: map[string]string{"user-agent": "curl/7.68.0"},
+			expectErr:       false,
+		},
+		{
+			name:            "equal separated header whose value holds separators",
+			headers:         []string{"Authorization=Bearer a:b=="},
+			expectedHeaders: map[string]string{"authorization": "Bearer a:b=="},
+			expectErr:       false,
+		},
+		{
+			name:            "empty value",
+			headers:         []string{"x-debug="},
+			expectedHeaders: map[string]string{"x-debug": ""},
+			expectErr:       false,
+		},
+		{
+			name:            "missing separator",
+			headers:         []string{"user-agent"},
+			expectedHeaders: nil,
+			expectErr:       true,
+		},
+		{
+			name:            "missing name",
+			headers:         []string{"=value"},
+			expectedHeaders: nil,
+			expectErr:       true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			headers, err := parseRequestHeaders(tc.headers)
+			assert.Equal(tc.expectErr, err != nil)
+			assert.Equal(tc.expectedHeaders, headers)
+		})
+	}
+}
+
+func TestGetHeadersMismatch(t *testing.T) {
+	matchHeaders := map[string]string{"Authorization": "Bearer .+", "x-tenant": "team-[a-z]+"}
+
+	testCases := []struct {
+		name             string
+		requestHeaders   map[string]string
+		expectedMismatch string
+	}{
+		{
+			name:             "all headers match",
+			requestHeaders:   map[string]string{"authorization": "Bearer token", "x-tenant": "team-books", "x-other": "ignored"},
+			expectedMismatch: "",
+		},
+		{
+			name:             "absent header",
+			requestHeaders:   map[string]string{"authorization": "Bearer token"},
+			expectedMismatch: "header x-tenant is missing from the request",
+		},
+		{
+			name:             "header value partially matching",
+			requestHeaders:   map[string]string{"authorization": "Bearer token", "x-tenant": "team-books-2"},
+			expectedMismatch: `value "team-books-2" of header x-tenant does not match the regex "team-[a-z]+"`,
+		},
+		{
+			name:             "no request headers",
+			requestHeaders:   map[string]string{},
+			expectedMismatch: "header Authorization is missing from the request",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+			assert.Equal(tc.expectedMismatch, getHeadersMismatch(matchHeaders, tc.requestHeaders))
+		})
+	}
+}
+
+func TestCheckTrafficPolicyHeaders(t *testing.T) {
+	srcPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "ns-1"},
+		Spec:       corev1.PodSpec{ServiceAccountName: "sa-1"},
+	}
+	dstPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-2", Namespace: "ns-2"},
+		Spec:       corev1.PodSpec{ServiceAccountName: "sa-2"},
+	}
+	source := identity.K8sServiceAccount{Namespace: "ns-1", Name: "sa-1"}
+	destination := identity.K8sServiceAccount{Namespace: "ns-2", Name: "sa-2"}
+	httpRouteGroup := &smiSpecs.HTTPRouteGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "routes", Namespace: "ns-2"},
+		Spec: smiSpecs.HTTPRouteGroupSpec{
+			Matches: []smiSpecs.HTTPMatch{
+				{Name: "admin", PathRegex: "/admin", Headers: map[string]string{"authorization": "Bearer admin-.*"}},
+				{Name: "tenant", PathRegex: "/books", Headers: map[string]string{"x-tenant": "books"}},
+			},
+		},
+	}
+	tcpRoute := &smiSpecs.TCPRoute{ObjectMeta: metav1.ObjectMeta{Name: "tcp", Namespace: "ns-2"}}
+
+	testCases := []struct {
+		name               string
+		trafficTarget      *smiAccess.TrafficTarget
+		headers            map[string]string
+		expectedAllowed    bool
+		expectedOutSubstrs []string
+	}{
+		{
+			name:            "headers matching a match of the HTTPRouteGroup",
+			trafficTarget:   newTestTrafficTarget("tt", source, destination, smiAccess.TrafficTargetRule{Kind: httpRouteGroupKind, Name: "routes"}),
+			headers:         map[string]string{"authorization": "Bearer admin-token"},
+			expectedAllowed: true,
+			expectedOutSubstrs: []string{
+				`[+] Pod 'ns-1/pod-1' is allowed to communicate to pod 'ns-2/pod-2' via the SMI TrafficTarget policy "tt"`,
+			},
+		},
+		{
+			name:            "headers not matching the matches referenced by the rule",
+			trafficTarget:   newTestTrafficTarget("tt", source, destination, smiAccess.TrafficTargetRule{Kind: httpRouteGroupKind, Name: "routes", Matches: []string{"tenant"}}),
+			headers:         map[string]string{"authorization": "Bearer admin-token", "x-tenant": "movies"},
+			expectedAllowed: false,
+			expectedOutSubstrs: []string{
+				`[-] No route of the SMI TrafficTarget policy "tt" matches the headers of the request`,
+				"[+] Pod 'ns-1/pod-1' is not allowed to communicate to pod 'ns-2/pod-2' with the headers of the request, no SMI route matches them",
+			},
+		},
+		{
+			name:            "absent header",
+			trafficTarget:   newTestTrafficTarget("tt", source, destination, smiAccess.TrafficTargetRule{Kind: httpRouteGroupKind, Name: "routes"}),
+			headers:         map[string]string{},
+			expectedAllowed: false,
+			expectedOutSubstrs: []string{
+				`[-] No route of the SMI TrafficTarget policy "tt" matches the headers of the request`,
+			},
+		},
+		{
+			name: "TCPRoute rule allowing the request whatever its headers",
+			trafficTarget: newTestTrafficTarget("tt", source, destination,
+				smiAccess.TrafficTargetRule{Kind: httpRouteGroupKind, Name: "routes"},
+				smiAccess.TrafficTargetRule{Kind: tcpRouteKind, Name: "tcp"},
+			),
+			headers:         map[string]string{},
+			expectedAllowed: true,
+		},
+		{
+			name:            "headers not evaluated without --header",
+			trafficTarget:   newTestTrafficTarget("tt", source, destination, smiAccess.TrafficTargetRule{Kind: httpRouteGroupKind, Name: "routes"}),
+			headers:         nil,
+			expectedAllowed: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			out := new(bytes.Buffer)
+			cmd := trafficPolicyCheckCmd{
+				out: out,
+				clientSet: fake.NewSimpleClientset(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: settings.Namespace(), Name: osmConfigMapName},
+					Data:       map[string]string{configurator.PermissiveTrafficPolicyModeKey: "false"},
+				}),
+				smiAccessClient: fakeAccessClient.NewSimpleClientset(tc.trafficTarget),
+				smiSpecClient:   fakeSpecClient.NewSimpleClientset(httpRouteGroup, tcpRoute),
+				smiSplitClient:  fakeSplitClient.NewSimpleClientset(),
+				meshConfigName:  osmConfigMapName,
+				requestHeaders:  tc.headers,
+			}
+
+			allowed, err := cmd.checkTrafficPolicy(srcPod, dstPod)
+			assert.Nil(err)
+			assert.Equal(tc.expectedAllowed, allowed)
+			for _, substr := range tc.expectedOutSubstrs {
+				assert.Contains(out.String(), substr)
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	smiAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned"
+	smiSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/openservicemesh/osm/pkg/cli"
+	"github.com/openservicemesh/osm/pkg/identity"
+)
+
+const trafficPolicyCheckIdentityDescription = `
+This command checks whether a given source identity is allowed to communicate
+(send traffic) to a given destination identity by an SMI TrafficTarget policy
+or in lieu of the mesh operating in permissive traffic policy mode.
+
+Unlike 'osm policy check-pods', no pod is looked up: the identities are given
+as SPIFFE IDs naming the service accounts of the workloads, and the SMI
+policies are evaluated against these service accounts directly. This allows
+checking the policies before the pods of the workloads are deployed.
+
+An identity is a SPIFFE ID of the form:
+  spiffe://cluster.local/ns/NAMESPACE/sa/SERVICE_ACCOUNT
+where the trust domain must be the trust domain of the mesh, cluster.local.
+
+When allowed by SMI TrafficTarget policies, the routes referenced by their
+rules are listed. With --explain-deny, when the source identity is not allowed
+to communicate to the destination, the SMI policies that would allow it are
+printed.
+
+By default, only the SMI TrafficTarget policies defined in the namespace of the
+destination are considered. With --all-namespaces, the policies defined in
+every namespace are scanned.
+
+The command exits with the same codes as 'osm policy check-pods':
+  0: the source identity is allowed to communicate to the destination
+  1: unexpected error
+  2: invalid input, e.g. an identity that is not a valid SPIFFE ID
+  3: the source identity is not allowed to communicate to the destination
+  4: error communicating with the Kubernetes API server
+`
+
+const trafficPolicyCheckIdentityExample = `
+# To check if service account 'bookbuyer' in the 'bookbuyer' namespace can send traffic to service account 'bookstore' in the 'bookstore' namespace
+osm policy check-identity spiffe://cluster.local/ns/bookbuyer/sa/bookbuyer spiffe://cluster.local/ns/bookstore/sa/bookstore
+
+# To print the SMI policies that would allow the traffic if it is not allowed
+osm policy check-identity spiffe://cluster.local/ns/bookbuyer/sa/bookbuyer spiffe://cluster.local/ns/bookstore/sa/bookstore --explain-deny
+`
+
+const (
+	// spiffeIDScheme is the scheme of a SPIFFE ID
+	spiffeIDScheme = "spiffe://"
+
+	// spiffeIDNamespaceSegment and spiffeIDServiceAccountSegment precede the namespace and the service account in
+	// the path of a SPIFFE ID identifying a Kubernetes service account
+	spiffeIDNamespaceSegment      = "ns"
+	spiffeIDServiceAccountSegment = "sa"
+)
+
+type trafficPolicyCheckIdentityCmd struct {
+	out                 io.Writer
+	sourceIdentity      string
+	destinationIdentity string
+	allNamespaces       bool
+	explainDeny         bool
+	meshName            string
+	meshConfigName      string
+	clientSet           kubernetes.Interface
+	smiAccessClient     smiAccessClient.Interface
+	smiSpecClient       smiSpecClient.Interface
+}
+
+func newTrafficPolicyCheckIdentityCmd(out io.Writer) *cobra.Command {
+	checkIdentityCmd := &trafficPolicyCheckIdentityCmd{
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "check-identity SOURCE_IDENTITY DESTINATION_IDENTITY",
+		Short: "check whether an identity is allowed to communicate to another identity",
+		Long:  trafficPolicyCheckIdentityDescription,
+		Args: func(cmd *cobra.Command, args []string) error {
+			return withExitCode(checkExitCodeInvalidInput, cobra.ExactArgs(2)(cmd, args))
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			checkIdentityCmd.sourceIdentity = args[0]
+			checkIdentityCmd.destinationIdentity = args[1]
+
+			clients, err := cli.NewClients(settings)
+			if err != nil {
+				return withExitCode(checkExitCodeAPIError, err)
+			}
+			checkIdentityCmd.clientSet = clients.KubeClient
+			checkIdentityCmd.smiAccessClient = clients.SMIAccessClient
+			checkIdentityCmd.smiSpecClient = clients.SMISpecClient
+
+			return checkIdentityCmd.run()
+		},
+		Example: trafficPolicyCheckIdentityExample,
+	}
+
+	f := cmd.Flags()
+	f.BoolVarP(&checkIdentityCmd.allNamespaces, "all-namespaces", "A", false, "Scan the SMI TrafficTarget policies of all the namespaces instead of the destination namespace only")
+	f.BoolVar(&checkIdentityCmd.explainDeny, "explain-deny", false, "Print the SMI TrafficTarget and HTTPRouteGroup policies that would allow the source identity to communicate to the destination when it is not allowed")
+	f.StringVar(&checkIdentityCmd.meshName, "mesh-name", "", "Name of the mesh whose configuration is checked, the mesh running in the namespace given with --osm-namespace if unset")
+	f.StringVar(&checkIdentityCmd.meshConfigName, "mesh-config-name", osmConfigMapName, "Name of the ConfigMap holding the configuration of the mesh")
+
+	return cmd
+}
+
+func (cmd *trafficPolicyCheckIdentityCmd) run() error {
+	src, err := parseSPIFFEIdentity(cmd.sourceIdentity)
+	if err != nil {
+		return withExitCode(checkExitCodeInvalidInput, errors.Errorf("Invalid argument specified for the source identity: %s", err))
+	}
+	dst, err := parseSPIFFEIdentity(cmd.destinationIdentity)
+	if err != nil {
+		return withExitCode(checkExitCodeInvalidInput, errors.Errorf("Invalid argument specified for the destination identity: %s", err))
+	}
+
+	// The lookups of the mesh and of the policies are shared with 'osm policy check-pods'
+	checkCmd := &trafficPolicyCheckCmd{
+		out:             cmd.out,
+		allNamespaces:   cmd.allNamespaces,
+		meshName:        cmd.meshName,
+		meshConfigName:  cmd.meshConfigName,
+		clientSet:       cmd.clientSet,
+		smiAccessClient: cmd.smiAccessClient,
+		smiSpecClient:   cmd.smiSpecClient,
+		listCache:       newListCache(),
+	}
+	osmNamespace, err := checkCmd.getOSMNamespace()
+	if err != nil {
+		return withExitCode(checkExitCodeInvalidInput, err)
+	}
+
+	permissiveMode, err := checkCmd.isPermissiveModeEnabled()
+	if err != nil {
+		return withExitCode(checkExitCodeAPIError, errors.Errorf("Error checking if permissive mode is enabled: %s", err))
+	}
+	if permissiveMode {
+		fmt.Fprintf(cmd.out, "[+] Permissive mode enabled for mesh operated by osm-controller running in '%s' namespace\n\n "+
+			"[+] Service account '%s' is allowed to communicate to service account '%s'\n", osmNamespace, src, dst)
+		return nil
+	}
+
+	fmt.Fprintf(cmd.out, "[+] SMI traffic policy mode enabled for mesh operated by osm-controller running in %s namespace\n\n", osmNamespace)
+	trafficTargets, err := checkCmd.listTrafficTargets(checkCmd.getTrafficTargetsNamespace(dst.Namespace))
+	if err != nil {
+		return withExitCode(checkExitCodeAPIError, err)
+	}
+
+	srcPod := newServiceAccountPod(src)
+	allowingTrafficTargets := getAllowingTrafficTargets(trafficTargets, srcPod, dst.Namespace, dst.Name)
+	if len(allowingTrafficTargets) == 0 {
+		fmt.Fprintf(cmd.out, "[+] Service account '%s' is not allowed to communicate to service account '%s', missing SMI TrafficTarget policy\n", src, dst)
+		if cmd.explainDeny {
+			if err := checkCmd.printDenyRemediation(srcPod, dst.Namespace, dst.Name); err != nil {
+				return err
+			}
+		}
+		return withExitCode(checkExitCodeTrafficDenied, errors.Errorf("Identity %s is not allowed to communicate to %s", cmd.sourceIdentity, cmd.destinationIdentity))
+	}
+
+	for _, trafficTarget := range allowingTrafficTargets {
+		fmt.Fprintf(cmd.out, "[+] Service account '%s' is allowed to communicate to service account '%s' via the SMI TrafficTarget policy %q:\n",
+			src, dst, trafficTarget.Name)
+		if err := checkCmd.printTrafficTarget(trafficTarget); err != nil {
+			return err
+		}
+		checkCmd.printMatchingSource(trafficTarget, srcPod)
+	}
+	if err := checkCmd.printAllowedRoutes(allowingTrafficTargets); err != nil {
+		return withExitCode(checkExitCodeAPIError, err)
+	}
+	return nil
+}
+
+// parseSPIFFEIdentity returns the service account identified by the given SPIFFE ID, of the form
+// spiffe://<trust-domain>/ns/<namespace>/sa/<service-account>, whose trust domain must be the trust domain of the mesh
+func parseSPIFFEIdentity(spiffeID string) (identity.K8sServiceAccount, error) {
+	chunks := strings.Split(strings.TrimPrefix(spiffeID, spiffeIDScheme), "/")
+	if !strings.HasPrefix(spiffeID, spiffeIDScheme) || len(chunks) != 5 ||
+		chunks[1] != spiffeIDNamespaceSegment || chunks[3] != spiffeIDServiceAccountSegment {
+		return identity.K8sServiceAccount{}, errors.Errorf("Identity should be a SPIFFE ID of the form %s%s/%s/<namespace>/%s/<service-account>, got: %s",
+			spiffeIDScheme, identity.ClusterLocalTrustDomain, spiffeIDNamespaceSegment, spiffeIDServiceAccountSegment, spiffeID)
+	}
+
+	trustDomain, namespace, serviceAccount := chunks[0], chunks[2], chunks[4]
+	if trustDomain != identity.ClusterLocalTrustDomain {
+		return identity.K8sServiceAccount{}, errors.Errorf("Invalid trust domain %q in %s, the trust domain of the mesh is %s", trustDomain, spiffeID, identity.ClusterLocalTrustDomain)
+	}
+	if errs := validation.IsDNS1123Label(namespace); len(errs) > 0 {
+		return identity.K8sServiceAccount{}, errors.Errorf("Invalid namespace %q in %s: %s", namespace, spiffeID, strings.Join(errs, "; "))
+	}
+	if errs := validation.IsDNS1123Subdomain(serviceAccount); len(errs) > 0 {
+		return identity.K8sServiceAccount{}, errors.Errorf("Invalid service account %q in %s: %s", serviceAccount, spiffeID, strings.Join(errs, "; "))
+	}
+
+	return identity.K8sServiceAccount{Namespace: namespace, Name: serviceAccount}, nil
+}
+
+// newServiceAccountPod returns a pod, that does not exist, running with the given service account, to match the SMI
+// policies against a service account since they are matched against the namespace and service account of pods
+func newServiceAccountPod(serviceAccount identity.K8sServiceAccount) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Namespace: serviceAccount.Namespace},
+		Spec:       corev1.PodSpec{ServiceAccountName: serviceAccount.Name},
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	fakeAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned/fake"
+	fakeSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned/fake"
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+	"github.com/openservicemesh/osm/pkg/identity"
+)
+
+func TestParseSPIFFEIdentity(t *testing.T) {
+	testCases := []struct {
+		spiffeID        string
+		expectedAccount identity.K8sServiceAccount
+		expectedErr     string
+	}{
+		{
+			spiffeID:        "spiffe://cluster.local/ns/bookstore/sa/bookstore-v1",
+			expectedAccount: identity.K8sServiceAccount{Namespace: "bookstore", Name: "bookstore-v1"},
+		},
+		{
+			spiffeID:    "bookstore/bookstore-v1",
+			expectedErr: "Identity should be a SPIFFE ID of the form spiffe://cluster.local/ns/<namespace>/sa/<service-account>",
+		},
+		{
+			spiffeID:    "spiffe://cluster.local/sa/bookstore-v1/ns/bookstore",
+			expectedErr: "Identity should be a SPIFFE ID of the form",
+		},
+		{
+			spiffeID:    "spiffe://cluster.local/ns/bookstore/sa/bookstore-v1/extra",
+			expectedErr: "Identity should be a SPIFFE ID of the form",
+		},
+		{
+			spiffeID:    "spiffe://example.org/ns/bookstore/sa/bookstore-v1",
+			expectedErr: `Invalid trust domain "example.org" in spiffe://example.org/ns/bookstore/sa/bookstore-v1, the trust domain of the mesh is cluster.local`,
+		},
+		{
+			spiffeID:    "spiffe://cluster.local/ns/Bookstore/sa/bookstore-v1",
+			expectedErr: `Invalid namespace "Bookstore"`,
+		},
+		{
+			spiffeID:    "spiffe://cluster.local/ns/bookstore/sa/",
+			expectedErr: `Invalid service account ""`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.spiffeID), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			serviceAccount, err := parseSPIFFEIdentity(tc.spiffeID)
+			if tc.expectedErr != "" {
+				assert.NotNil(err)
+				assert.Contains(err.Error(), tc.expectedErr)
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(tc.expectedAccount, serviceAccount)
+		})
+	}
+}
+
+func TestTrafficPolicyCheckIdentity(t *testing.T) {
+	trafficTarget := &smiAccess.TrafficTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "bookbuyer-to-bookstore", Namespace: "bookstore"},
+		Spec: smiAccess.TrafficTargetSpec{
+			Destination: smiAccess.IdentityBindingSubject{Kind: serviceAccountKind, Name: "bookstore", Namespace: "bookstore"},
+			Sources:     []smiAccess.IdentityBindingSubject{{Kind: serviceAccountKind, Name: "bookbuyer", Namespace: "bookbuyer"}},
+		},
+	}
+
+	testCases := []struct {
+		name                string
+		sourceIdentity      string
+		destinationIdentity string
+		permissiveMode      bool
+		explainDeny         bool
+		trafficTargets      []runtime.Object
+		expectedOutSubstr   string
+		expectedExitCode    int
+	}{
+		{
+			name:                "allowed in permissive mode without pods nor policies",
+			sourceIdentity:      "spiffe://cluster.local/ns/bookbuyer/sa/bookbuyer",
+			destinationIdentity: "spiffe://cluster.local/ns/bookstore/sa/bookstore",
+			permissiveMode:      true,
+			expectedOutSubstr:   "[+] Service account 'bookbuyer/bookbuyer' is allowed to communicate to service account 'bookstore/bookstore'\n",
+		},
+		{
+			name:                "allowed by an SMI TrafficTarget policy",
+			sourceIdentity:      "spiffe://cluster.local/ns/bookbuyer/sa/bookbuyer",
+			destinationIdentity: "spiffe://cluster.local/ns/bookstore/sa/bookstore",
+			trafficTargets:      []runtime.Object{trafficTarget},
+			expectedOutSubstr:   `[+] Service account 'bookbuyer/bookbuyer' is allowed to communicate to service account 'bookstore/bookstore' via the SMI TrafficTarget policy "bookbuyer-to-bookstore"`,
+		},
+		{
+			name:                "denied without an SMI TrafficTarget policy",
+			sourceIdentity:      "spiffe://cluster.local/ns/bookthief/sa/bookthief",
+			destinationIdentity: "spiffe://cluster.local/ns/bookstore/sa/bookstore",
+			trafficTargets:      []runtime.Object{trafficTarget},
+			expectedOutSubstr:   "[+] Service account 'bookthief/bookthief' is not allowed to communicate to service account 'bookstore/bookstore', missing SMI TrafficTarget policy",
+			expectedExitCode:    checkExitCodeTrafficDenied,
+		},
+		{
+			name:                "remediation printed when denied with --explain-deny",
+			sourceIdentity:      "spiffe://cluster.local/ns/bookthief/sa/bookthief",
+			destinationIdentity: "spiffe://cluster.local/ns/bookstore/sa/bookstore",
+			explainDeny:         true,
+			expectedOutSubstr:   "The following SMI policies would allow service account 'bookthief/bookthief' to communicate to service account 'bookstore/bookstore'",
+			expectedExitCode:    checkExitCodeTrafficDenied,
+		},
+		{
+			name:                "invalid identity",
+			sourceIdentity:      "bookbuyer/bookbuyer",
+			destinationIdentity: "spiffe://cluster.local/ns/bookstore/sa/bookstore",
+			expectedExitCode:    checkExitCodeInvalidInput,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			out := new(bytes.Buffer)
+			cmd := &trafficPolicyCheckIdentityCmd{
+				out:                 out,
+				sourceIdentity:      tc.sourceIdentity,
+				destinationIdentity: tc.destinationIdentity,
+				explainDeny:         tc.explainDeny,
+				meshConfigName:      osmConfigMapName,
+				clientSet: fake.NewSimpleClientset(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: settings.Namespace(), Name: osmConfigMapName},
+					Data:       map[string]string{configurator.PermissiveTrafficPolicyModeKey: fmt.Sprintf("%t", tc.permissiveMode)},
+				}),
+				smiAccessClient: fakeAccessClient.NewSimpleClientset(tc.trafficTargets...),
+				smiSpecClient:   fakeSpecClient.NewSimpleClientset(),
+			}
+
+			err := cmd.run()
+			if tc.expectedExitCode != 0 {
+				assert.NotNil(err)
+				assert.Equal(tc.expectedExitCode, getExitCode(err))
+			} else {
+				assert.Nil(err)
+			}
+			assert.Contains(out.String(), tc.expectedOutSubstr)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+)
+
+// validateIgnoredTargets returns an error if a TrafficTarget given with --ignore-target is neither 'name' nor
+// 'namespace/name'
+func validateIgnoredTargets(ignoredTargets []string) error {
+	for _, ignoredTarget := range ignoredTargets {
+		parts := strings.Split(ignoredTarget, namespaceSeparator)
+		if len(parts) > 2 {
+			return errors.Errorf("Invalid value %q for flag --ignore-target, expected 'name' or 'namespace/name'", ignoredTarget)
+		}
+		for _, part := range parts {
+			if part == "" {
+				return errors.Errorf("Invalid value %q for flag --ignore-target, expected 'name' or 'namespace/name'", ignoredTarget)
+			}
+		}
+	}
+	return nil
+}
+
+// isIgnoredTarget returns whether the given TrafficTarget is ignored with --ignore-target, either by name in any
+// namespace or by namespaced name
+func (cmd *trafficPolicyCheckCmd) isIgnoredTarget(trafficTarget smiAccess.TrafficTarget) bool {
+	for _, ignoredTarget := range cmd.ignoredTargets {
+		if ignoredTarget == trafficTarget.Name || ignoredTarget == trafficTarget.Namespace+namespaceSeparator+trafficTarget.Name {
+			return true
+		}
+	}
+	return false
+}
+
+// filterIgnoredTargets returns the given TrafficTargets without the ones ignored with --ignore-target. The given slice
+// is not modified since it may be cached.
+func (cmd *trafficPolicyCheckCmd) filterIgnoredTargets(trafficTargets []smiAccess.TrafficTarget) []smiAccess.TrafficTarget {
+	if len(cmd.ignoredTargets) == 0 {
+		return trafficTargets
+	}
+
+	var filteredTrafficTargets []smiAccess.TrafficTarget
+	for _, trafficTarget := range trafficTargets {
+		if cmd.isIgnoredTarget(trafficTarget) {
+			cmd.tracef("SMI TrafficTarget policy '%s/%s' ignored with --ignore-target", trafficTarget.Namespace, trafficTarget.Name)
+			continue
+		}
+		filteredTrafficTargets = append(filteredTrafficTargets, trafficTarget)
+	}
+	return filteredTrafficTargets
+}
+
+// printIgnoredTargets prints the TrafficTargets ignored with --ignore-target, so that the outcome of the check is not
+// mistaken for the outcome with every policy of the cluster
+func (cmd *trafficPolicyCheckCmd) printIgnoredTargets() {
+	if len(cmd.ignoredTargets) == 0 {
+		return
+	}
+	fmt.Fprintf(cmd.out, "[!] Ignoring the SMI TrafficTarget policies %s, as if they were deleted\n\n", strings.Join(cmd.ignoredTargets, ", "))
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	fakeAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned/fake"
+	fakeSplitClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/split/clientset/versioned/fake"
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+	"github.com/openservicemesh/osm/pkg/identity"
+)
+
+func TestCheckTrafficPolicyIgnoreTarget(t *testing.T) {
+	source := identity.K8sServiceAccount{Namespace: "ns-1", Name: "sa-1"}
+	destination := identity.K8sServiceAccount{Namespace: "ns-2", Name: "sa-2"}
+
+	testCases := []struct {
+		name               string
+		ignoredTargets     []string
+		expectedErr        string
+		expectedOutSubstrs []string
+	}{
+		{
+			name:           "no ignored target",
+			ignoredTargets: nil,
+		},
+		{
+			name:           "one of the allowing targets ignored by name",
+			ignoredTargets: []string{"broad"},
+			expectedOutSubstrs: []string{
+				"[!] Ignoring the SMI TrafficTarget policies broad, as if they were deleted",
+				`via the SMI TrafficTarget policy "narrow"`,
+			},
+		},
+		{
+			name:           "every allowing target ignored",
+			ignoredTargets: []string{"ns-2/broad", "narrow"},
+			expectedErr:    "Pod ns-1/pod-1 is not allowed to communicate to ns-2/pod-2",
+			expectedOutSubstrs: []string{
+				"[!] Ignoring the SMI TrafficTarget policies ns-2/broad, narrow, as if they were deleted",
+				"missing SMI TrafficTarget policy",
+			},
+		},
+		{
+			name:           "target of another namespace ignored",
+			ignoredTargets: []string{"ns-1/broad", "ns-1/narrow"},
+		},
+		{
+			name:           "invalid ignored target",
+			ignoredTargets: []string{"ns-2/"},
+			expectedErr:    `Invalid value "ns-2/" for flag --ignore-target, expected 'name' or 'namespace/name'`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			out := new(bytes.Buffer)
+			cmd := trafficPolicyCheckCmd{
+				out:            out,
+				sourcePod:      "ns-1/pod-1",
+				destinationPod: "ns-2/pod-2",
+				ignoredTargets: tc.ignoredTargets,
+				clientSet: fake.NewSimpleClientset(
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-1"}},
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-2"}},
+					newTestPod("ns-1", "pod-1", "sa-1", true),
+					newTestPod("ns-2", "pod-2", "sa-2", true),
+					&corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{Namespace: settings.Namespace(), Name: osmConfigMapName},
+						Data:       map[string]string{configurator.PermissiveTrafficPolicyModeKey: "false"},
+					},
+				),
+				smiAccessClient: fakeAccessClient.NewSimpleClientset(newTestTrafficTarget("broad", source, destination), newTestTrafficTarget("narrow", source, destination)),
+				smiSplitClient:  fakeSplitClient.NewSimpleClientset(),
+				meshConfigName:  osmConfigMapName,
+				skipRBACCheck:   true,
+			}
+
+			err := cmd.run()
+			if tc.expectedErr != "" {
+				assert.EqualError(err, tc.expectedErr)
+			} else {
+				assert.Nil(err)
+			}
+			for _, substr := range tc.expectedOutSubstrs {
+				assert.Contains(out.String(), substr)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// podKind is the kind of the pods listed to resolve the IPs given with --by-ip
+const podKind = "Pod"
+
+// resolvePodIPs returns the 'namespace/name' of the pods having the source and destination IPs given with --by-ip
+func (cmd *trafficPolicyCheckCmd) resolvePodIPs(sourceIP, destinationIP string) (string, string, error) {
+	sourcePod, err := cmd.resolvePodIP(sourceIP)
+	if err != nil {
+		return "", "", err
+	}
+	destinationPod, err := cmd.resolvePodIP(destinationIP)
+	if err != nil {
+		return "", "", err
+	}
+	return sourcePod, destinationPod, nil
+}
+
+// resolvePodIP returns the 'namespace/name' of the pod having the given IP in its status. Pods that have terminated
+// are skipped since their IP can be reused by another pod, and an IP shared by multiple running pods, e.g. pods on the
+// host network of a node, is reported as ambiguous.
+func (cmd *trafficPolicyCheckCmd) resolvePodIP(ip string) (string, error) {
+	parsedIP := net.ParseIP(ip)
+	if parsedIP == nil {
+		return "", withExitCode(checkExitCodeInvalidInput, errors.Errorf("Invalid IP address %q specified with --by-ip", ip))
+	}
+
+	pods, err := cmd.listPods()
+	if err != nil {
+		return "", withExitCode(checkExitCodeAPIError, err)
+	}
+
+	var matchingPods []string
+	for i := range pods {
+		pod := &pods[i]
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, podIP := range getPodIPs(pod) {
+			if parsedIP.Equal(net.ParseIP(podIP)) {
+				matchingPods = append(matchingPods, pod.Namespace+namespaceSeparator+pod.Name)
+				break
+			}
+		}
+	}
+	sort.Strings(matchingPods)
+
+	switch len(matchingPods) {
+	case 0:
+		return "", withExitCode(checkExitCodeInvalidInput, errors.Errorf("No running pod has the IP address %s", ip))
+	case 1:
+		fmt.Fprintf(cmd.out, "[+] IP address %s resolves to pod '%s'\n", ip, matchingPods[0])
+		return matchingPods[0], nil
+	default:
+		return "", withExitCode(checkExitCodeInvalidInput, errors.Errorf("IP address %s is ambiguous, it belongs to the pods %s, specify the pods by name instead",
+			ip, strings.Join(matchingPods, ", ")))
+	}
+}
+
+// listPods returns the pods of every namespace
+func (cmd *trafficPolicyCheckCmd) listPods() ([]corev1.Pod, error) {
+	pods, err := cmd.listCache.get(metav1.NamespaceAll, podKind, func() (interface{}, error) {
+		pods, err := cmd.clientSet.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return nil, errors.Errorf("Error listing pods: %s", err)
+		}
+		return pods.Items, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pods.([]corev1.Pod), nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestResolvePodIP(t *testing.T) {
+	bookbuyer := newTestPod("bookbuyer", "bookbuyer-1", "", false)
+	bookbuyer.Status.PodIPs = []corev1.PodIP{{IP: "10.1.2.3"}}
+	bookstore := newTestPod("bookstore", "bookstore-1", "", false)
+	bookstore.Status.PodIPs = []corev1.PodIP{{IP: "10.1.4.5"}, {IP: "fd00::45"}}
+	completedBookstore := newTestPod("bookstore", "bookstore-0", "", false)
+	completedBookstore.Status.Phase = corev1.PodSucceeded
+	completedBookstore.Status.PodIPs = []corev1.PodIP{{IP: "10.1.4.5"}}
+	nodeExporter := newTestPod("kube-system", "node-exporter-1", "", false)
+	nodeExporter.Status.PodIPs = []corev1.PodIP{{IP: "192.168.0.10"}}
+	nodeAgent := newTestPod("monitoring", "node-agent-1", "", false)
+	nodeAgent.Status.PodIPs = []corev1.PodIP{{IP: "192.168.0.10"}}
+	legacy := newTestPod("legacy", "legacy-1", "", false)
+	legacy.Status.Phase = corev1.PodPending
+	legacy.Status.PodIP = "10.1.6.7"
+	fakeClient := fake.NewSimpleClientset(bookbuyer, bookstore, completedBookstore, nodeExporter, nodeAgent, legacy)
+
+	testCases := []struct {
+		name             string
+		ip               string
+		expectedPod      string
+		expectedErr      string
+		expectedExitCode int
+	}{
+		{
+			name:        "IP of a running pod",
+			ip:          "10.1.2.3",
+			expectedPod: "bookbuyer/bookbuyer-1",
+		},
+		{
+			name:        "IP reused by a running pod after another pod terminated",
+			ip:          "10.1.4.5",
+			expectedPod: "bookstore/bookstore-1",
+		},
+		{
+			name:        "IPv6 address in a non canonical form",
+			ip:          "fd00:0::45",
+			expectedPod: "bookstore/bookstore-1",
+		},
+		{
+			name:        "IP only set in the legacy podIP field",
+			ip:          "10.1.6.7",
+			expectedPod: "legacy/legacy-1",
+		},
+		{
+			name:             "IP shared by multiple pods",
+			ip:               "192.168.0.10",
+			expectedErr:      "IP address 192.168.0.10 is ambiguous, it belongs to the pods kube-system/node-exporter-1, monitoring/node-agent-1, specify the pods by name instead",
+			expectedExitCode: checkExitCodeInvalidInput,
+		},
+		{
+			name:             "IP of no pod",
+			ip:               "10.9.9.9",
+			expectedErr:      "No running pod has the IP address 10.9.9.9",
+			expectedExitCode: checkExitCodeInvalidInput,
+		},
+		{
+			name:             "invalid IP",
+			ip:               "bookstore/bookstore-1",
+			expectedErr:      `Invalid IP address "bookstore/bookstore-1" specified with --by-ip`,
+			expectedExitCode: checkExitCodeInvalidInput,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			out := new(bytes.Buffer)
+			cmd := trafficPolicyCheckCmd{
+				out:       out,
+				clientSet: fakeClient,
+			}
+
+			pod, err := cmd.resolvePodIP(tc.ip)
+			if tc.expectedErr != "" {
+				assert.EqualError(err, tc.expectedErr)
+				assert.Equal(tc.expectedExitCode, getExitCode(err))
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(tc.expectedPod, pod)
+			assert.Equal(fmt.Sprintf("[+] IP address %s resolves to pod '%s'\n", tc.ip, tc.expectedPod), out.String())
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	policyV1alpha1 "github.com/openservicemesh/osm/pkg/apis/policy/v1alpha1"
+)
+
+// rbacCheckKind is the kind under which the access reviews of the RBAC preflight are cached
+const rbacCheckKind = "SelfSubjectAccessReview"
+
+// rbacPermission is a permission on a resource required to check a traffic policy
+type rbacPermission struct {
+	verb      string
+	group     string
+	resource  string
+	name      string
+	namespace string
+}
+
+func (p rbacPermission) String() string {
+	resource := p.resource
+	if p.group != "" {
+		resource = fmt.Sprintf("%s.%s", resource, p.group)
+	}
+	if p.name != "" {
+		resource = fmt.Sprintf("%s/%s", resource, p.name)
+	}
+	return fmt.Sprintf("you need RBAC '%s' on '%s' in %s", p.verb, resource, describeNamespace(p.namespace))
+}
+
+// checkRBACPermissions verifies that the invoking user is allowed to read the pods of the given namespaces, the SMI
+// TrafficTarget policies of the destination namespace, or of every namespace with --all-namespaces, the mesh config, and the
+// Egress policies with --allow-non-meshed-destination, and returns an error listing the missing
+// permissions instead of letting the check fail on a Forbidden error
+func (cmd *trafficPolicyCheckCmd) checkRBACPermissions(srcNs, dstNs string) error {
+	osmNamespace, err := cmd.getOSMNamespace()
+	if err != nil {
+		return withExitCode(checkExitCodeInvalidInput, err)
+	}
+
+	permissions := []rbacPermission{
+		{verb: "get", resource: "pods", namespace: srcNs},
+		{verb: "get", resource: "pods", namespace: dstNs},
+		{verb: "list", group: smiAccess.SchemeGroupVersion.Group, resource: "traffictargets", namespace: cmd.getTrafficTargetsNamespace(dstNs)},
+		{verb: "get", resource: "configmaps", name: cmd.meshConfigName, namespace: osmNamespace},
+	}
+	if cmd.allowNonMeshed {
+		permissions = append(permissions, rbacPermission{verb: "list", group: policyV1alpha1.SchemeGroupVersion.Group, resource: "egresses", namespace: metav1.NamespaceAll})
+	}
+
+	var missing []string
+	checked := make(map[rbacPermission]bool)
+	for _, permission := range permissions {
+		if checked[permission] {
+			continue
+		}
+		checked[permission] = true
+
+		allowed, err := cmd.isAllowed(permission)
+		if err != nil {
+			return withExitCode(checkExitCodeAPIError, annotateErrorMessageWithActionableMessage(
+				"Note: Use the flag --skip-rbac-check to skip the verification of the RBAC permissions.",
+				"Error verifying the RBAC permissions required to check the traffic policy: %s", err))
+		}
+		if !allowed {
+			missing = append(missing, "  "+permission.String())
+		}
+	}
+
+	if len(missing) > 0 {
+		return withExitCode(checkExitCodeAPIError, annotateErrorMessageWithActionableMessage(
+			"Note: Ask a cluster administrator to grant these permissions, or use the flag --skip-rbac-check to skip this verification.",
+			"Missing RBAC permissions to check the traffic policy:\n%s", strings.Join(missing, "\n")))
+	}
+	return nil
+}
+
+// isAllowed returns whether the invoking user has the given permission, reviewed once per invocation unless caching
+// is disabled with --no-cache
+func (cmd *trafficPolicyCheckCmd) isAllowed(permission rbacPermission) (bool, error) {
+	allowed, err := cmd.listCache.get(permission.namespace, rbacCheckKind+" "+permission.String(), func() (interface{}, error) {
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace: permission.namespace,
+					Verb:      permission.verb,
+					Group:     permission.group,
+					Resource:  permission.resource,
+					Name:      permission.name,
+				},
+			},
+		}
+		review, err := cmd.clientSet.AuthorizationV1().SelfSubjectAccessReviews().Create(context.TODO(), review, metav1.CreateOptions{})
+		if err != nil {
+			return nil, errors.Errorf("Error creating SelfSubjectAccessReview: %s", err)
+		}
+		return review.Status.Allowed, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return allowed.(bool), nil
+}
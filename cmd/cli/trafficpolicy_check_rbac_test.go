@@ -0,0 +1,164 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	tassert "github.com/stretchr/testify/assert"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newAccessReviewClientSet returns a fake clientset whose access reviews deny the given permissions, and a pointer to
+// the number of access reviews created
+func newAccessReviewClientSet(denied map[rbacPermission]bool, reviewErr error, objects ...runtime.Object) (*fake.Clientset, *int) {
+	var reviewCount int
+	clientSet := fake.NewSimpleClientset(objects...)
+	clientSet.PrependReactor("create", "selfsubjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		reviewCount++
+		if reviewErr != nil {
+			return true, nil, reviewErr
+		}
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SelfSubjectAccessReview)
+		attributes := review.Spec.ResourceAttributes
+		permission := rbacPermission{
+			verb:      attributes.Verb,
+			group:     attributes.Group,
+			resource:  attributes.Resource,
+			name:      attributes.Name,
+			namespace: attributes.Namespace,
+		}
+		review.Status.Allowed = !denied[permission]
+		return true, review, nil
+	})
+	return clientSet, &reviewCount
+}
+
+func TestCheckRBACPermissions(t *testing.T) {
+	osmNamespace := settings.Namespace()
+
+	testCases := []struct {
+		name                string
+		srcNs               string
+		dstNs               string
+		allNamespaces       bool
+		denied              map[rbacPermission]bool
+		reviewErr           error
+		expectedReviewCount int
+		expectedErr         string
+	}{
+		{
+			name:                "all permissions granted",
+			srcNs:               "ns-1",
+			dstNs:               "ns-2",
+			expectedReviewCount: 4,
+		},
+		{
+			name:                "pods of a single namespace are reviewed once",
+			srcNs:               "ns-1",
+			dstNs:               "ns-1",
+			expectedReviewCount: 3,
+		},
+		{
+			name:          "traffic targets of all the namespaces",
+			srcNs:         "ns-1",
+			dstNs:         "ns-2",
+			allNamespaces: true,
+			denied: map[rbacPermission]bool{
+				{verb: "list", group: "access.smi-spec.io", resource: "traffictargets"}: true,
+			},
+			expectedReviewCount: 4,
+			expectedErr: "Missing RBAC permissions to check the traffic policy:\n" +
+				"  you need RBAC 'list' on 'traffictargets.access.smi-spec.io' in all namespaces\n\n" +
+				"Note: Ask a cluster administrator to grant these permissions, or use the flag --skip-rbac-check to skip this verification.",
+		},
+		{
+			name:  "missing permissions",
+			srcNs: "ns-1",
+			dstNs: "ns-2",
+			denied: map[rbacPermission]bool{
+				{verb: "get", resource: "pods", namespace: "ns-1"}:                                         true,
+				{verb: "list", group: "access.smi-spec.io", resource: "traffictargets", namespace: "ns-2"}: true,
+				{verb: "get", resource: "configmaps", name: osmConfigMapName, namespace: osmNamespace}:     true,
+			},
+			expectedReviewCount: 4,
+			expectedErr: "Missing RBAC permissions to check the traffic policy:\n" +
+				"  you need RBAC 'get' on 'pods' in namespace ns-1\n" +
+				"  you need RBAC 'list' on 'traffictargets.access.smi-spec.io' in namespace ns-2\n" +
+				fmt.Sprintf("  you need RBAC 'get' on 'configmaps/osm-config' in namespace %s\n\n", osmNamespace) +
+				"Note: Ask a cluster administrator to grant these permissions, or use the flag --skip-rbac-check to skip this verification.",
+		},
+		{
+			name:                "access reviews cannot be created",
+			srcNs:               "ns-1",
+			dstNs:               "ns-2",
+			reviewErr:           errors.New("connection refused"),
+			expectedReviewCount: 1,
+			expectedErr: "Error verifying the RBAC permissions required to check the traffic policy: Error creating SelfSubjectAccessReview: connection refused\n\n" +
+				"Note: Use the flag --skip-rbac-check to skip the verification of the RBAC permissions.",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			clientSet, reviewCount := newAccessReviewClientSet(tc.denied, tc.reviewErr)
+			cmd := trafficPolicyCheckCmd{
+				clientSet:      clientSet,
+				meshConfigName: osmConfigMapName,
+				allNamespaces:  tc.allNamespaces,
+			}
+			cmd.resetListCache()
+
+			err := cmd.checkRBACPermissions(tc.srcNs, tc.dstNs)
+			assert.Equal(tc.expectedReviewCount, *reviewCount)
+			if tc.expectedErr == "" {
+				assert.Nil(err)
+				return
+			}
+			assert.NotNil(err)
+			assert.Equal(tc.expectedErr, err.Error())
+			assert.Equal(checkExitCodeAPIError, getExitCode(err))
+		})
+	}
+}
+
+func TestTrafficPolicyCheckSkipRBACCheck(t *testing.T) {
+	denied := map[rbacPermission]bool{
+		{verb: "get", resource: "pods", namespace: "ns-1"}: true,
+	}
+
+	for _, skipRBACCheck := range []bool{false, true} {
+		t.Run(fmt.Sprintf("Testing with skipRBACCheck=%t", skipRBACCheck), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			clientSet, reviewCount := newAccessReviewClientSet(denied, nil,
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-1"}},
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-2"}},
+				newTestPod("ns-1", "pod-1", "", true),
+				newTestPod("ns-2", "pod-2", "", true),
+			)
+			cmd := trafficPolicyCheckCmd{
+				clientSet:       clientSet,
+				meshConfigName:  osmConfigMapName,
+				destinationKind: destinationKindPod,
+				skipRBACCheck:   skipRBACCheck,
+			}
+
+			_, _, err := cmd.getTrafficPolicyCheck("ns-1/pod-1", "ns-2/pod-2")
+			if skipRBACCheck {
+				assert.Nil(err)
+				assert.Zero(*reviewCount)
+			} else {
+				assert.NotNil(err)
+				assert.Contains(err.Error(), "you need RBAC 'get' on 'pods' in namespace ns-1")
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiSpecs "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/specs/v1alpha4"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	tcpRouteKind       = "TCPRoute"
+	httpRouteGroupKind = "HTTPRouteGroup"
+)
+
+// printAllowedRoutes prints the L4 TCP routes and L7 HTTP routes referenced by the rules of the given TrafficTargets,
+// followed by a summary of the layers over which traffic is allowed. Nothing is printed if no rule is referenced.
+func (cmd *trafficPolicyCheckCmd) printAllowedRoutes(trafficTargets []smiAccess.TrafficTarget) error {
+	hasRules := false
+	for _, trafficTarget := range trafficTargets {
+		hasRules = hasRules || len(trafficTarget.Spec.Rules) > 0
+	}
+	if !hasRules {
+		cmd.tracef("No rules in the allowing SMI TrafficTarget policies, no route to resolve")
+		return nil
+	}
+
+	var allowsTCP, allowsHTTP bool
+	// The resolution of the routes is traced once the table of routes is printed
+	var routeTraces []string
+	fmt.Fprintln(cmd.out, "[+] Traffic is allowed over the following routes:")
+	w := newTabWriter(cmd.out)
+	fmt.Fprintln(w, "TRAFFIC TARGET\tROUTE\tLAYER\tALLOWED\t")
+	for _, trafficTarget := range trafficTargets {
+		for _, rule := range trafficTarget.Spec.Rules {
+			route := fmt.Sprintf("%s/%s", rule.Kind, rule.Name)
+
+			switch rule.Kind {
+			case tcpRouteKind:
+				allowed, found, err := cmd.describeTCPRoute(trafficTarget.Namespace, rule.Name)
+				if err != nil {
+					return err
+				}
+				allowsTCP = allowsTCP || found
+				routeTraces = append(routeTraces, describeRouteResolution(trafficTarget, route, found))
+				fmt.Fprintf(w, "%s\t%s\tL4\t%s\t\n", trafficTarget.Name, route, allowed)
+
+			case httpRouteGroupKind:
+				allowedMatches, found, err := cmd.describeHTTPRouteMatches(trafficTarget.Namespace, rule.Name, rule.Matches)
+				if err != nil {
+					return err
+				}
+				allowsHTTP = allowsHTTP || found
+				routeTraces = append(routeTraces, describeRouteResolution(trafficTarget, route, found))
+				for _, allowed := range allowedMatches {
+					fmt.Fprintf(w, "%s\t%s\tL7\t%s\t\n", trafficTarget.Name, route, allowed)
+				}
+
+			default:
+				fmt.Fprintf(w, "%s\t%s\t-\tnone (unsupported rule kind)\t\n", trafficTarget.Name, route)
+				routeTraces = append(routeTraces, fmt.Sprintf("Route %s of SMI TrafficTarget policy '%s/%s': unsupported rule kind, not resolved", route, trafficTarget.Namespace, trafficTarget.Name))
+			}
+		}
+	}
+	_ = w.Flush()
+	for _, routeTrace := range routeTraces {
+		cmd.tracef("%s", routeTrace)
+	}
+
+	switch {
+	case allowsTCP && allowsHTTP:
+		fmt.Fprintln(cmd.out, "[+] Traffic is allowed over L4 TCP routes and over specific L7 HTTP routes")
+	case allowsTCP:
+		fmt.Fprintln(cmd.out, "[+] Traffic is allowed over L4 TCP routes only")
+	case allowsHTTP:
+		fmt.Fprintln(cmd.out, "[+] Traffic is allowed over specific L7 HTTP routes only")
+	default:
+		fmt.Fprintln(cmd.out, "[!] None of the routes referenced by the SMI TrafficTarget policies exist, traffic will be denied")
+	}
+
+	return nil
+}
+
+// describeTCPRoute returns a description of the ports allowed by the given TCPRoute and whether the TCPRoute exists
+func (cmd *trafficPolicyCheckCmd) describeTCPRoute(namespace, name string) (string, bool, error) {
+	tcpRoute, err := cmd.smiSpecClient.SpecsV1alpha4().TCPRoutes(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return "none (TCPRoute not found)", false, nil
+	}
+	if err != nil {
+		return "", false, errors.Errorf("Error fetching SMI TCPRoute %s/%s: %s", namespace, name, err)
+	}
+
+	if len(tcpRoute.Spec.Matches.Ports) == 0 {
+		return "all TCP ports", true, nil
+	}
+	var ports []string
+	for _, port := range tcpRoute.Spec.Matches.Ports {
+		ports = append(ports, fmt.Sprintf("%d", port))
+	}
+	return fmt.Sprintf("TCP ports %s", strings.Join(ports, ", ")), true, nil
+}
+
+// describeHTTPRouteMatches returns a description of the given matches of the HTTPRouteGroup, or of all its matches if
+// none are given, and whether any of these matches exists
+func (cmd *trafficPolicyCheckCmd) describeHTTPRouteMatches(namespace, name string, matchNames []string) ([]string, bool, error) {
+	routeGroup, err := cmd.getHTTPRouteGroup(namespace, name)
+	if err != nil {
+		return nil, false, err
+	}
+	if routeGroup == nil {
+		return []string{"none (HTTPRouteGroup not found)"}, false, nil
+	}
+
+	resolvedMatches := resolveHTTPRouteMatches(routeGroup, matchNames)
+	if len(resolvedMatches) == 0 {
+		return []string{"none (HTTPRouteGroup has no matches)"}, false, nil
+	}
+
+	var descriptions []string
+	found := false
+	for _, resolved := range resolvedMatches {
+		if resolved.match == nil {
+			descriptions = append(descriptions, fmt.Sprintf("none (match %q not found)", resolved.name))
+			continue
+		}
+		descriptions = append(descriptions, describeHTTPMatch(*resolved.match))
+		found = true
+	}
+	return descriptions, found, nil
+}
+
+// getHTTPRouteGroup returns the given HTTPRouteGroup, or nil if it does not exist
+func (cmd *trafficPolicyCheckCmd) getHTTPRouteGroup(namespace, name string) (*smiSpecs.HTTPRouteGroup, error) {
+	routeGroup, err := cmd.smiSpecClient.SpecsV1alpha4().HTTPRouteGroups(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Errorf("Error fetching SMI HTTPRouteGroup %s/%s: %s", namespace, name, err)
+	}
+	return routeGroup, nil
+}
+
+// resolvedHTTPMatch is a match referenced by a rule of a TrafficTarget, whose match is nil when the HTTPRouteGroup
+// has no match with the referenced name
+type resolvedHTTPMatch struct {
+	name  string
+	match *smiSpecs.HTTPMatch
+}
+
+// resolveHTTPRouteMatches returns the given matches of the HTTPRouteGroup, or all its matches if none are given, in
+// the order they are referenced
+func resolveHTTPRouteMatches(routeGroup *smiSpecs.HTTPRouteGroup, matchNames []string) []resolvedHTTPMatch {
+	matches := make(map[string]*smiSpecs.HTTPMatch)
+	for i := range routeGroup.Spec.Matches {
+		matches[routeGroup.Spec.Matches[i].Name] = &routeGroup.Spec.Matches[i]
+	}
+	if len(matchNames) == 0 {
+		for _, match := range routeGroup.Spec.Matches {
+			matchNames = append(matchNames, match.Name)
+		}
+	}
+
+	resolvedMatches := make([]resolvedHTTPMatch, 0, len(matchNames))
+	for _, matchName := range matchNames {
+		resolvedMatches = append(resolvedMatches, resolvedHTTPMatch{name: matchName, match: matches[matchName]})
+	}
+	return resolvedMatches
+}
+
+// describeHTTPMatch returns a description of the HTTP requests allowed by the given match
+func describeHTTPMatch(match smiSpecs.HTTPMatch) string {
+	return fmt.Sprintf("%s: %s %s", match.Name, describeMethods(match.Methods), describePathRegex(match.PathRegex))
+}
+
+// describeMethods returns a description of the given methods of an HTTP match, where no methods allow every method
+func describeMethods(methods []string) string {
+	if len(methods) == 0 {
+		return "*"
+	}
+	return strings.Join(methods, ",")
+}
+
+// describePathRegex returns a description of the given path regex of an HTTP match, where no regex allows every path
+func describePathRegex(pathRegex string) string {
+	if pathRegex == "" {
+		return ".*"
+	}
+	return pathRegex
+}
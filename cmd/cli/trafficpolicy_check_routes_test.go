@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiSpecs "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/specs/v1alpha4"
+	fakeSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned/fake"
+	tassert "github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openservicemesh/osm/pkg/identity"
+)
+
+func TestPrintAllowedRoutes(t *testing.T) {
+	tcpRoute := &smiSpecs.TCPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "postgres", Namespace: "ns-2"},
+		Spec: smiSpecs.TCPRouteSpec{
+			Matches: smiSpecs.TCPMatch{Name: "postgres", Ports: []int{5432, 5433}},
+		},
+	}
+	allPortsTCPRoute := &smiSpecs.TCPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "all-ports", Namespace: "ns-2"},
+	}
+	httpRouteGroup := &smiSpecs.HTTPRouteGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "bookstore", Namespace: "ns-2"},
+		Spec: smiSpecs.HTTPRouteGroupSpec{
+			Matches: []smiSpecs.HTTPMatch{
+				{Name: "buy-books", Methods: []string{"GET", "POST"}, PathRegex: "/buy"},
+				{Name: "any"},
+			},
+		},
+	}
+
+	source := identity.K8sServiceAccount{Namespace: "ns-1", Name: "sa-1"}
+	destination := identity.K8sServiceAccount{Namespace: "ns-2", Name: "sa-2"}
+
+	testCases := []struct {
+		name           string
+		trafficTarget  *smiAccess.TrafficTarget
+		expectedOutput []string
+	}{
+		{
+			name:          "TCP routes only",
+			trafficTarget: newTestTrafficTarget("tt", source, destination, smiAccess.TrafficTargetRule{Kind: tcpRouteKind, Name: "postgres"}, smiAccess.TrafficTargetRule{Kind: tcpRouteKind, Name: "all-ports"}),
+			expectedOutput: []string{
+				"TCPRoute/postgres    L4      TCP ports 5432, 5433",
+				"TCPRoute/all-ports   L4      all TCP ports",
+				"[+] Traffic is allowed over L4 TCP routes only",
+			},
+		},
+		{
+			name:          "HTTP routes only, restricted to a match",
+			trafficTarget: newTestTrafficTarget("tt", source, destination, smiAccess.TrafficTargetRule{Kind: httpRouteGroupKind, Name: "bookstore", Matches: []string{"buy-books"}}),
+			expectedOutput: []string{
+				"HTTPRouteGroup/bookstore   L7      buy-books: GET,POST /buy",
+				"[+] Traffic is allowed over specific L7 HTTP routes only",
+			},
+		},
+		{
+			name: "TCP and HTTP routes",
+			trafficTarget: newTestTrafficTarget("tt", source, destination,
+				smiAccess.TrafficTargetRule{Kind: httpRouteGroupKind, Name: "bookstore"},
+				smiAccess.TrafficTargetRule{Kind: tcpRouteKind, Name: "postgres"},
+			),
+			expectedOutput: []string{
+				"buy-books: GET,POST /buy",
+				"any: * .*",
+				"TCP ports 5432, 5433",
+				"[+] Traffic is allowed over L4 TCP routes and over specific L7 HTTP routes",
+			},
+		},
+		{
+			name: "missing routes and matches",
+			trafficTarget: newTestTrafficTarget("tt", source, destination,
+				smiAccess.TrafficTargetRule{Kind: tcpRouteKind, Name: "missing"},
+				smiAccess.TrafficTargetRule{Kind: httpRouteGroupKind, Name: "bookstore", Matches: []string{"sell-books"}},
+			),
+			expectedOutput: []string{
+				"none (TCPRoute not found)",
+				`none (match "sell-books" not found)`,
+				"[!] None of the routes referenced by the SMI TrafficTarget policies exist, traffic will be denied",
+			},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+			out := new(bytes.Buffer)
+			cmd := trafficPolicyCheckCmd{
+				out:           out,
+				smiSpecClient: fakeSpecClient.NewSimpleClientset(tcpRoute, allPortsTCPRoute, httpRouteGroup),
+			}
+
+			err := cmd.printAllowedRoutes([]smiAccess.TrafficTarget{*tc.trafficTarget})
+			assert.Nil(err)
+			for _, expected := range tc.expectedOutput {
+				assert.Contains(out.String(), expected)
+			}
+		})
+	}
+}
+
+func TestPrintAllowedRoutesWithoutRules(t *testing.T) {
+	assert := tassert.New(t)
+	out := new(bytes.Buffer)
+	cmd := trafficPolicyCheckCmd{out: out}
+
+	err := cmd.printAllowedRoutes([]smiAccess.TrafficTarget{{ObjectMeta: metav1.ObjectMeta{Name: "tt"}}})
+	assert.Nil(err)
+	assert.Empty(out.String())
+}
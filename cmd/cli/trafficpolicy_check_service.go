@@ -0,0 +1,135 @@
+package main
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// getDestinationService returns the service with the given name to check traffic policies against, or nil if the
+// destination should be checked as a pod. Unless --destination-kind is set, the destination is looked up as a service
+// only when no pod exists with the given name.
+func (cmd *trafficPolicyCheckCmd) getDestinationService(namespace, name string) (*corev1.Service, error) {
+	switch cmd.destinationKind {
+	case destinationKindPod:
+		return nil, nil
+
+	case destinationKindService:
+		svc, err := cmd.clientSet.CoreV1().Services(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil, withExitCode(checkExitCodeInvalidInput, errors.Errorf("Could not find service %s in namespace %s", name, namespace))
+		}
+		if err != nil {
+			return nil, withExitCode(checkExitCodeAPIError, errors.Errorf("Error fetching service %s in namespace %s: %s", name, namespace, err))
+		}
+		return svc, nil
+
+	default:
+		if _, err := cmd.clientSet.CoreV1().Pods(namespace).Get(context.TODO(), name, metav1.GetOptions{}); !apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		svc, err := cmd.clientSet.CoreV1().Services(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return nil, withExitCode(checkExitCodeInvalidInput, errors.Errorf("Could not find pod or service %s in namespace %s", name, namespace))
+		}
+		if err != nil {
+			return nil, withExitCode(checkExitCodeAPIError, errors.Errorf("Error fetching service %s in namespace %s: %s", name, namespace, err))
+		}
+		return svc, nil
+	}
+}
+
+// checkServiceTrafficPolicy prints whether 'srcPod' is allowed to communicate to each service account of the meshed
+// pods backing 'dstService', and returns whether it is allowed to communicate to all of them
+func (cmd *trafficPolicyCheckCmd) checkServiceTrafficPolicy(srcPod *corev1.Pod, dstService *corev1.Service) (bool, error) {
+	osmNamespace, err := cmd.getOSMNamespace()
+	if err != nil {
+		return false, err
+	}
+	dstServices := map[string]bool{dstService.Name: true}
+	dstDescription := fmt.Sprintf("Service '%s/%s'", dstService.Namespace, dstService.Name)
+
+	serviceAccounts, err := cmd.getServiceAccountsForService(dstService.Namespace, dstService.Name)
+	if err != nil {
+		return false, err
+	}
+	if len(serviceAccounts) == 0 {
+		return false, withExitCode(checkExitCodeInvalidInput,
+			errors.Errorf("Service %s in namespace %s is not backed by any pod that is a part of a mesh", dstService.Name, dstService.Namespace))
+	}
+
+	// Check if permissive mode is enabled, in which case every meshed pod is allowed to communicate with each other
+	if permissiveMode, err := cmd.isPermissiveModeEnabled(); err != nil {
+		return false, errors.Errorf("Error checking if permissive mode is enabled: %s", err)
+	} else if permissiveMode {
+		fmt.Fprintf(cmd.out, "[+] Permissive mode enabled for mesh operated by osm-controller running in '%s' namespace\n\n "+
+			"[+] Pod '%s/%s' is allowed to communicate to service '%s/%s'\n",
+			osmNamespace, srcPod.Namespace, srcPod.Name, dstService.Namespace, dstService.Name)
+		cmd.checkResult.record(true, nil)
+		return true, cmd.checkServicesTrafficSplits(srcPod, dstService.Namespace, dstServices, dstDescription, true, nil)
+	}
+
+	// SMI traffic policy mode
+	fmt.Fprintf(cmd.out, "[+] SMI traffic policy mode enabled for mesh operated by osm-controller running in %s namespace\n\n", osmNamespace)
+	trafficTargets, err := cmd.listTrafficTargets(cmd.getTrafficTargetsNamespace(dstService.Namespace))
+	if err != nil {
+		return false, err
+	}
+
+	// A service may be backed by pods running as different service accounts, each of which is checked separately
+	allowed := true
+	var allowingTrafficTargets []smiAccess.TrafficTarget
+	printedTrafficTargets := make(map[string]bool)
+	for _, serviceAccount := range serviceAccounts {
+		serviceAccountTrafficTargets := getAllowingTrafficTargets(trafficTargets, srcPod, dstService.Namespace, serviceAccount)
+		if len(serviceAccountTrafficTargets) > 0 {
+			if serviceAccountTrafficTargets, err = cmd.filterTrafficTargetsByHeaders(serviceAccountTrafficTargets); err != nil {
+				return false, err
+			}
+			if len(serviceAccountTrafficTargets) == 0 {
+				allowed = false
+				fmt.Fprintf(cmd.out, "[+] Pod '%s/%s' is not allowed to communicate to service account '%s/%s' backing service '%s/%s' with the headers of the request, no SMI route matches them\n",
+					srcPod.Namespace, srcPod.Name, dstService.Namespace, serviceAccount, dstService.Namespace, dstService.Name)
+				continue
+			}
+		}
+		if len(serviceAccountTrafficTargets) == 0 {
+			allowed = false
+			fmt.Fprintf(cmd.out, "[+] Pod '%s/%s' is not allowed to communicate to service account '%s/%s' backing service '%s/%s', missing SMI TrafficTarget policy\n",
+				srcPod.Namespace, srcPod.Name, dstService.Namespace, serviceAccount, dstService.Namespace, dstService.Name)
+			if cmd.explainDeny {
+				if err := cmd.printDenyRemediation(srcPod, dstService.Namespace, serviceAccount); err != nil {
+					return false, err
+				}
+			}
+			continue
+		}
+
+		for _, trafficTarget := range serviceAccountTrafficTargets {
+			fmt.Fprintf(cmd.out, "[+] Pod '%s/%s' is allowed to communicate to service account '%s/%s' backing service '%s/%s' via the SMI TrafficTarget policy %q:\n",
+				srcPod.Namespace, srcPod.Name, dstService.Namespace, serviceAccount, dstService.Namespace, dstService.Name, trafficTarget.Name)
+			if err := cmd.printTrafficTarget(trafficTarget); err != nil {
+				return false, err
+			}
+			cmd.printMatchingSource(trafficTarget, srcPod)
+			if !printedTrafficTargets[trafficTarget.Name] {
+				printedTrafficTargets[trafficTarget.Name] = true
+				allowingTrafficTargets = append(allowingTrafficTargets, trafficTarget)
+			}
+		}
+	}
+
+	cmd.checkResult.record(false, allowingTrafficTargets)
+	if len(allowingTrafficTargets) > 0 {
+		if err := cmd.printAllowedRoutes(allowingTrafficTargets); err != nil {
+			return false, err
+		}
+	}
+
+	return allowed, cmd.checkServicesTrafficSplits(srcPod, dstService.Namespace, dstServices, dstDescription, false, trafficTargets)
+}
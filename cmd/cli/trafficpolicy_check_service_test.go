@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	fakeAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned/fake"
+	fakeSplitClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/split/clientset/versioned/fake"
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+	"github.com/openservicemesh/osm/pkg/identity"
+)
+
+func TestCheckServiceTrafficPolicy(t *testing.T) {
+	newService := func(name, namespace string, selector map[string]string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+			Spec:       corev1.ServiceSpec{Selector: selector},
+		}
+	}
+	source := identity.K8sServiceAccount{Namespace: "ns-1", Name: "sa-1"}
+	destination := identity.K8sServiceAccount{Namespace: "ns-2", Name: "sa-2"}
+	newConfigMap := func(permissiveMode string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: settings.Namespace(), Name: osmConfigMapName},
+			Data:       map[string]string{configurator.PermissiveTrafficPolicyModeKey: permissiveMode},
+		}
+	}
+
+	testCases := []struct {
+		name             string
+		destination      string
+		destinationKind  string
+		permissiveMode   string
+		trafficTargets   []*smiAccess.TrafficTarget
+		expectedAllowed  bool
+		expectedErr      string
+		expectedOutputs  []string
+		unexpectedOutput string
+	}{
+		{
+			name:            "service backed by a single allowed service account",
+			destination:     "ns-2/bookstore",
+			permissiveMode:  "false",
+			trafficTargets:  []*smiAccess.TrafficTarget{newTestTrafficTarget("test-1", source, destination)},
+			expectedAllowed: true,
+			expectedOutputs: []string{
+				"[+] Pod 'ns-1/pod-1' is allowed to communicate to service account 'ns-2/sa-2' backing service 'ns-2/bookstore' via the SMI TrafficTarget policy \"test-1\"",
+			},
+		},
+		{
+			name:            "service backed by mixed service accounts reports each",
+			destination:     "ns-2/mixed",
+			permissiveMode:  "false",
+			trafficTargets:  []*smiAccess.TrafficTarget{newTestTrafficTarget("test-1", source, destination)},
+			expectedAllowed: false,
+			expectedOutputs: []string{
+				"[+] Pod 'ns-1/pod-1' is allowed to communicate to service account 'ns-2/sa-2' backing service 'ns-2/mixed' via the SMI TrafficTarget policy \"test-1\"",
+				"[+] Pod 'ns-1/pod-1' is not allowed to communicate to service account 'ns-2/sa-3' backing service 'ns-2/mixed', missing SMI TrafficTarget policy",
+			},
+		},
+		{
+			name:            "service in permissive mode",
+			destination:     "ns-2/bookstore",
+			permissiveMode:  "true",
+			expectedAllowed: true,
+			expectedOutputs: []string{"[+] Pod 'ns-1/pod-1' is allowed to communicate to service 'ns-2/bookstore'"},
+		},
+		{
+			name:             "pod takes precedence over a service with the same name",
+			destination:      "ns-2/pod-2",
+			permissiveMode:   "false",
+			trafficTargets:   []*smiAccess.TrafficTarget{newTestTrafficTarget("test-1", source, destination)},
+			expectedAllowed:  true,
+			expectedOutputs:  []string{"[+] Pod 'ns-1/pod-1' is allowed to communicate to pod 'ns-2/pod-2'"},
+			unexpectedOutput: "backing service",
+		},
+		{
+			name:            "service is checked when requested with --destination-kind",
+			destination:     "ns-2/pod-2",
+			destinationKind: destinationKindService,
+			permissiveMode:  "false",
+			trafficTargets:  []*smiAccess.TrafficTarget{newTestTrafficTarget("test-1", source, destination)},
+			expectedAllowed: true,
+			expectedOutputs: []string{"backing service 'ns-2/pod-2'"},
+		},
+		{
+			name:            "service is not looked up with --destination-kind pod",
+			destination:     "ns-2/bookstore",
+			destinationKind: destinationKindPod,
+			permissiveMode:  "false",
+			expectedErr:     "Could not find pod bookstore in namespace ns-2",
+		},
+		{
+			name:           "neither a pod nor a service",
+			destination:    "ns-2/unknown",
+			permissiveMode: "false",
+			expectedErr:    "Could not find pod or service unknown in namespace ns-2",
+		},
+		{
+			name:           "service without meshed pods",
+			destination:    "ns-2/unmeshed",
+			permissiveMode: "false",
+			expectedErr:    "Service unmeshed in namespace ns-2 is not backed by any pod that is a part of a mesh",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			pod2 := newTestPod("ns-2", "pod-2", "sa-2", true)
+			pod2.Labels["app"] = "bookstore"
+			pod2.Labels["version"] = "v1"
+			pod3 := newTestPod("ns-2", "pod-3", "sa-3", true)
+			pod3.Labels["app"] = "bookstore"
+			pod3.Labels["version"] = "v2"
+			unmeshedPod := newTestPod("ns-2", "unmeshed-1", "sa-4", false)
+			unmeshedPod.Labels = map[string]string{"app": "unmeshed"}
+			fakeClient := fake.NewSimpleClientset(
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-1"}},
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-2"}},
+				newTestPod("ns-1", "pod-1", "sa-1", true),
+				pod2,
+				pod3,
+				unmeshedPod,
+				newService("bookstore", "ns-2", map[string]string{"app": "bookstore", "version": "v1"}),
+				newService("mixed", "ns-2", map[string]string{"app": "bookstore"}),
+				newService("pod-2", "ns-2", map[string]string{"app": "bookstore", "version": "v1"}),
+				newService("unmeshed", "ns-2", map[string]string{"app": "unmeshed"}),
+				newConfigMap(tc.permissiveMode),
+			)
+			accessClient := fakeAccessClient.NewSimpleClientset()
+			for _, trafficTarget := range tc.trafficTargets {
+				assert.Nil(accessClient.Tracker().Add(trafficTarget))
+			}
+
+			out := new(bytes.Buffer)
+			cmd := trafficPolicyCheckCmd{
+				out:             out,
+				destinationKind: tc.destinationKind,
+				clientSet:       fakeClient,
+				smiAccessClient: accessClient,
+				smiSplitClient:  fakeSplitClient.NewSimpleClientset(),
+				skipRBACCheck:   true,
+			}
+
+			_, check, err := cmd.getTrafficPolicyCheck("ns-1/pod-1", tc.destination)
+			if err == nil {
+				var allowed bool
+				allowed, err = check()
+				assert.Equal(tc.expectedAllowed, allowed)
+			}
+			if tc.expectedErr != "" {
+				assert.NotNil(err)
+				assert.Contains(err.Error(), tc.expectedErr)
+				return
+			}
+			assert.Nil(err)
+			for _, expectedOutput := range tc.expectedOutputs {
+				assert.Contains(out.String(), expectedOutput)
+			}
+			if tc.unexpectedOutput != "" {
+				assert.NotContains(out.String(), tc.unexpectedOutput)
+			}
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	fakeAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned/fake"
+	smiAccessScheme "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned/scheme"
+	fakeSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned/fake"
+	smiSpecScheme "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned/scheme"
+	fakeSplitClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/split/clientset/versioned/fake"
+	smiSplitScheme "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/split/clientset/versioned/scheme"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/kubernetes/fake"
+	kubeScheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/yaml"
+
+	fakePolicyClient "github.com/openservicemesh/osm/pkg/gen/client/policy/clientset/versioned/fake"
+	policyScheme "github.com/openservicemesh/osm/pkg/gen/client/policy/clientset/versioned/scheme"
+)
+
+// listKind is the kind of the lists of resources, e.g. as exported by 'kubectl get -o yaml'
+const listKind = "List"
+
+// snapshotScheme holds the kinds of the resources read from and written to the snapshots of a cluster
+var snapshotScheme = newSnapshotScheme()
+
+func newSnapshotScheme() *runtime.Scheme {
+	scheme := runtime.NewScheme()
+	for _, addToScheme := range []func(*runtime.Scheme) error{
+		kubeScheme.AddToScheme,
+		smiAccessScheme.AddToScheme,
+		smiSpecScheme.AddToScheme,
+		smiSplitScheme.AddToScheme,
+		policyScheme.AddToScheme,
+	} {
+		// Adding the generated types to a new scheme can't fail
+		_ = addToScheme(scheme)
+	}
+	return scheme
+}
+
+// snapshot holds the resources of a snapshot of a cluster, by the clientset serving them
+type snapshot struct {
+	kubeObjects      []runtime.Object
+	smiAccessObjects []runtime.Object
+	smiSpecObjects   []runtime.Object
+	smiSplitObjects  []runtime.Object
+	policyObjects    []runtime.Object
+}
+
+// useSnapshot sets the clients of the command to fake clientsets serving the resources of the snapshot in the given
+// directory, so that the checks run entirely against the snapshot
+func (cmd *trafficPolicyCheckCmd) useSnapshot(dir string) error {
+	s, err := readSnapshot(dir)
+	if err != nil {
+		return err
+	}
+	cmd.clientSet = fake.NewSimpleClientset(s.kubeObjects...)
+	cmd.smiAccessClient = fakeAccessClient.NewSimpleClientset(s.smiAccessObjects...)
+	cmd.smiSpecClient = fakeSpecClient.NewSimpleClientset(s.smiSpecObjects...)
+	cmd.smiSplitClient = fakeSplitClient.NewSimpleClientset(s.smiSplitObjects...)
+	cmd.policyClient = fakePolicyClient.NewSimpleClientset(s.policyObjects...)
+	return nil
+}
+
+// readSnapshot returns the resources read from the YAML or JSON manifests in the given directory and its
+// subdirectories. Lists of resources are read item by item, and resources of kinds unknown to the checks are ignored.
+func readSnapshot(dir string) (*snapshot, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, errors.Errorf("Error reading snapshot: %s", err)
+	}
+	if !info.IsDir() {
+		return nil, errors.Errorf("Error reading snapshot: %s is not a directory", dir)
+	}
+
+	s := &snapshot{}
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".yaml", ".yml", ".json":
+		default:
+			return nil
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		f, err := os.Open(filepath.Clean(path))
+		if err != nil {
+			return err
+		}
+		//nolint: errcheck
+		//#nosec G307
+		defer f.Close()
+		if err := s.read(f); err != nil {
+			return errors.Errorf("%s: %s", path, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Errorf("Error reading snapshot: %s", err)
+	}
+	return s, nil
+}
+
+// read adds the resources read from the given YAML or JSON manifests to the snapshot
+func (s *snapshot) read(in io.Reader) error {
+	manifests, err := readManifests(in)
+	if err != nil {
+		return err
+	}
+	for _, m := range manifests {
+		if err := s.add(m.raw); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// add adds the resource, or the items of the list of resources, of the given JSON manifest to the snapshot
+func (s *snapshot) add(raw []byte) error {
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return err
+	}
+	if typeMeta.Kind == "" && typeMeta.APIVersion == "" {
+		return nil
+	}
+
+	if typeMeta.Kind == listKind || strings.HasSuffix(typeMeta.Kind, listKind) {
+		var list struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		if err := json.Unmarshal(raw, &list); err != nil {
+			return err
+		}
+		for _, item := range list.Items {
+			if err := s.add(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	obj, gvk, err := serializer.NewCodecFactory(snapshotScheme).UniversalDeserializer().Decode(raw, nil, nil)
+	if runtime.IsNotRegisteredError(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Errorf("Error decoding %s %s: %s", typeMeta.APIVersion, typeMeta.Kind, err)
+	}
+
+	switch {
+	case smiAccessScheme.Scheme.Recognizes(*gvk):
+		s.smiAccessObjects = append(s.smiAccessObjects, obj)
+	case smiSpecScheme.Scheme.Recognizes(*gvk):
+		s.smiSpecObjects = append(s.smiSpecObjects, obj)
+	case smiSplitScheme.Scheme.Recognizes(*gvk):
+		s.smiSplitObjects = append(s.smiSplitObjects, obj)
+	case policyScheme.Scheme.Recognizes(*gvk):
+		s.policyObjects = append(s.policyObjects, obj)
+	default:
+		s.kubeObjects = append(s.kubeObjects, obj)
+	}
+	return nil
+}
+
+// writeSnapshot writes to the given directory a snapshot of the resources read by the check of the given source pod
+// against the given destination, to be checked offline with --from-snapshot. A file is written per kind of resource.
+func (cmd *trafficPolicyCheckCmd) writeSnapshot(dir, sourcePod, destination string) error {
+	srcNamespace, _, err := unmarshalNamespacedPod(sourcePod)
+	if err != nil {
+		return withExitCode(checkExitCodeInvalidInput, errors.Errorf("Invalid argument specified for the source pod: %s", err))
+	}
+	dstNamespace, _, err := unmarshalNamespacedPod(destination)
+	if err != nil {
+		return withExitCode(checkExitCodeInvalidInput, errors.Errorf("Invalid argument specified for the destination: %s", err))
+	}
+	osmNamespace, err := cmd.getOSMNamespace()
+	if err != nil {
+		return withExitCode(checkExitCodeInvalidInput, err)
+	}
+
+	namespaces := []string{srcNamespace}
+	if dstNamespace != srcNamespace {
+		namespaces = append(namespaces, dstNamespace)
+	}
+	policyNamespaces := []string{dstNamespace}
+	if cmd.allNamespaces {
+		policyNamespaces = []string{metav1.NamespaceAll}
+	}
+
+	snapshotFiles := map[string]func() ([]runtime.Object, error){
+		"namespaces": func() ([]runtime.Object, error) {
+			list, err := cmd.clientSet.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+			return listItems(list, err)
+		},
+		"configmaps": func() ([]runtime.Object, error) {
+			meshConfig, err := getMeshConfig(cmd.clientSet, osmNamespace, cmd.meshConfigName)
+			if err != nil {
+				return nil, err
+			}
+			return []runtime.Object{meshConfig}, nil
+		},
+		"deployments": func() ([]runtime.Object, error) {
+			list, err := getControllerDeployments(cmd.clientSet)
+			return listItems(list, err)
+		},
+		"pods": func() ([]runtime.Object, error) {
+			return listItemsInNamespaces(namespaces, func(namespace string) (runtime.Object, error) {
+				return cmd.clientSet.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+			})
+		},
+		"services": func() ([]runtime.Object, error) {
+			return listItemsInNamespaces(namespaces, func(namespace string) (runtime.Object, error) {
+				return cmd.clientSet.CoreV1().Services(namespace).List(context.TODO(), metav1.ListOptions{})
+			})
+		},
+		"traffictargets": func() ([]runtime.Object, error) {
+			return listItemsInNamespaces(policyNamespaces, func(namespace string) (runtime.Object, error) {
+				return cmd.smiAccessClient.AccessV1alpha3().TrafficTargets(namespace).List(context.TODO(), metav1.ListOptions{})
+			})
+		},
+		"httproutegroups": func() ([]runtime.Object, error) {
+			return listItemsInNamespaces(policyNamespaces, func(namespace string) (runtime.Object, error) {
+				return cmd.smiSpecClient.SpecsV1alpha4().HTTPRouteGroups(namespace).List(context.TODO(), metav1.ListOptions{})
+			})
+		},
+		"tcproutes": func() ([]runtime.Object, error) {
+			return listItemsInNamespaces(policyNamespaces, func(namespace string) (runtime.Object, error) {
+				return cmd.smiSpecClient.SpecsV1alpha4().TCPRoutes(namespace).List(context.TODO(), metav1.ListOptions{})
+			})
+		},
+		"trafficsplits": func() ([]runtime.Object, error) {
+			return listItemsInNamespaces(policyNamespaces, func(namespace string) (runtime.Object, error) {
+				return cmd.smiSplitClient.SplitV1alpha2().TrafficSplits(namespace).List(context.TODO(), metav1.ListOptions{})
+			})
+		},
+		"egresses": func() ([]runtime.Object, error) {
+			list, err := cmd.policyClient.PolicyV1alpha1().Egresses(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+			if apierrors.IsNotFound(err) {
+				// The Egress policy API is not installed in the cluster
+				return nil, nil
+			}
+			return listItems(list, err)
+		},
+	}
+
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return errors.Errorf("Error writing snapshot: %s", err)
+	}
+	var names []string
+	for name := range snapshotFiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		objects, err := snapshotFiles[name]()
+		if err != nil {
+			return withExitCode(checkExitCodeAPIError, errors.Errorf("Error writing snapshot of %s: %s", name, err))
+		}
+		if err := writeSnapshotFile(filepath.Join(dir, name+".yaml"), objects); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// listItems returns the items of the given list of resources returned with the given error
+func listItems(list runtime.Object, err error) ([]runtime.Object, error) {
+	if err != nil {
+		return nil, err
+	}
+	return meta.ExtractList(list)
+}
+
+// listItemsInNamespaces returns the items of the lists of resources returned by the given function for each of the
+// given namespaces
+func listItemsInNamespaces(namespaces []string, list func(namespace string) (runtime.Object, error)) ([]runtime.Object, error) {
+	var objects []runtime.Object
+	for _, namespace := range namespaces {
+		items, err := listItems(list(namespace))
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, items...)
+	}
+	return objects, nil
+}
+
+// writeSnapshotFile writes the given resources to the given file as YAML documents, along with their kind, which is
+// not set on the resources returned by the typed clients
+func writeSnapshotFile(path string, objects []runtime.Object) error {
+	var buf bytes.Buffer
+	for _, obj := range objects {
+		gvks, _, err := snapshotScheme.ObjectKinds(obj)
+		if err != nil {
+			return errors.Errorf("Error writing snapshot: %s", err)
+		}
+		obj = obj.DeepCopyObject()
+		obj.GetObjectKind().SetGroupVersionKind(gvks[0])
+
+		manifest, err := yaml.Marshal(obj)
+		if err != nil {
+			return errors.Errorf("Error writing snapshot: %s", err)
+		}
+		buf.WriteString("---\n")
+		buf.Write(manifest)
+	}
+	if err := ioutil.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		return errors.Errorf("Error writing snapshot: %s", err)
+	}
+	return nil
+}
@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiSpecs "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/specs/v1alpha4"
+	fakeAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned/fake"
+	fakeSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned/fake"
+	fakeSplitClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/split/clientset/versioned/fake"
+	tassert "github.com/stretchr/testify/assert"
+	trequire "github.com/stretchr/testify/require"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+	fakePolicyClient "github.com/openservicemesh/osm/pkg/gen/client/policy/clientset/versioned/fake"
+)
+
+const snapshotPodsManifest = `
+apiVersion: v1
+kind: List
+items:
+- apiVersion: v1
+  kind: Namespace
+  metadata:
+    name: bookbuyer
+- apiVersion: v1
+  kind: Namespace
+  metadata:
+    name: bookstore
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: bookbuyer-client
+    namespace: bookbuyer
+    labels:
+      osm-proxy-uuid: bookbuyer
+  spec:
+    serviceAccountName: bookbuyer
+- apiVersion: v1
+  kind: Pod
+  metadata:
+    name: bookstore-server
+    namespace: bookstore
+    labels:
+      osm-proxy-uuid: bookstore
+  spec:
+    serviceAccountName: bookstore
+`
+
+const snapshotPoliciesManifest = `
+apiVersion: access.smi-spec.io/v1alpha3
+kind: TrafficTarget
+metadata:
+  name: bookstore
+  namespace: bookstore
+spec:
+  destination:
+    kind: ServiceAccount
+    name: bookstore
+    namespace: bookstore
+  sources:
+  - kind: ServiceAccount
+    name: bookbuyer
+    namespace: bookbuyer
+  rules:
+  - kind: HTTPRouteGroup
+    name: bookstore-routes
+    matches:
+    - buy-books
+---
+apiVersion: specs.smi-spec.io/v1alpha4
+kind: HTTPRouteGroup
+metadata:
+  name: bookstore-routes
+  namespace: bookstore
+spec:
+  matches:
+  - name: buy-books
+    methods:
+    - GET
+    pathRegex: /books
+---
+apiVersion: example.com/v1
+kind: Unknown
+metadata:
+  name: ignored
+`
+
+func TestTrafficPolicyCheckFromSnapshot(t *testing.T) {
+	meshConfigManifest := fmt.Sprintf(`{"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "%s", "namespace": "%s"}, "data": {"%s": "false"}}`,
+		osmConfigMapName, settings.Namespace(), configurator.PermissiveTrafficPolicyModeKey)
+
+	testCases := []struct {
+		name             string
+		files            map[string]string
+		sourcePod        string
+		destinationPod   string
+		expectedExitCode int
+	}{
+		{
+			name: "allowed by the SMI policies of the snapshot",
+			files: map[string]string{
+				"pods.yaml":           snapshotPodsManifest,
+				"policies/smi.yml":    snapshotPoliciesManifest,
+				"meshconfig.json":     meshConfigManifest,
+				"README.md":           "not a manifest",
+				"policies/empty.yaml": "---\n",
+			},
+			sourcePod:        "bookbuyer/bookbuyer-client",
+			destinationPod:   "bookstore/bookstore-server",
+			expectedExitCode: 0,
+		},
+		{
+			name: "denied without the SMI policies",
+			files: map[string]string{
+				"pods.yaml":       snapshotPodsManifest,
+				"meshconfig.json": meshConfigManifest,
+			},
+			sourcePod:        "bookbuyer/bookbuyer-client",
+			destinationPod:   "bookstore/bookstore-server",
+			expectedExitCode: checkExitCodeTrafficDenied,
+		},
+		{
+			name: "pod missing from the snapshot",
+			files: map[string]string{
+				"pods.yaml":       snapshotPodsManifest,
+				"meshconfig.json": meshConfigManifest,
+			},
+			sourcePod:        "bookbuyer/bookbuyer-missing",
+			destinationPod:   "bookstore/bookstore-server",
+			expectedExitCode: checkExitCodeInvalidInput,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			dir := newTestSnapshotDir(t, tc.files)
+			defer os.RemoveAll(dir) //nolint: errcheck
+
+			cmd := &trafficPolicyCheckCmd{
+				out:            new(bytes.Buffer),
+				sourcePod:      tc.sourcePod,
+				destinationPod: tc.destinationPod,
+				meshConfigName: osmConfigMapName,
+				skipRBACCheck:  true,
+			}
+			trequire.Nil(t, cmd.useSnapshot(dir))
+
+			err := cmd.run()
+			if tc.expectedExitCode == 0 {
+				assert.Nil(err)
+			} else {
+				assert.Equal(tc.expectedExitCode, getExitCode(err))
+			}
+		})
+	}
+}
+
+func TestReadSnapshotErrors(t *testing.T) {
+	testCases := []struct {
+		name  string
+		files map[string]string
+		path  string
+	}{
+		{
+			name:  "directory not found",
+			files: nil,
+			path:  "missing",
+		},
+		{
+			name:  "not a directory",
+			files: map[string]string{"pods.yaml": snapshotPodsManifest},
+			path:  "pods.yaml",
+		},
+		{
+			name:  "invalid manifest",
+			files: map[string]string{"pods.yaml": "apiVersion: v1\nkind: Pod\nspec: invalid\n"},
+			path:  "",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			dir := newTestSnapshotDir(t, tc.files)
+			defer os.RemoveAll(dir) //nolint: errcheck
+
+			_, err := readSnapshot(filepath.Join(dir, tc.path))
+			assert.NotNil(err)
+		})
+	}
+}
+
+func TestWriteSnapshot(t *testing.T) {
+	assert := tassert.New(t)
+
+	dir, err := ioutil.TempDir(os.TempDir(), "osm-test")
+	assert.Nil(err)
+	defer os.RemoveAll(dir) //nolint: errcheck
+
+	liveCmd := &trafficPolicyCheckCmd{
+		out:            new(bytes.Buffer),
+		meshConfigName: osmConfigMapName,
+		clientSet: fake.NewSimpleClientset(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "bookbuyer"}},
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "bookstore"}},
+			&corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: osmConfigMapName, Namespace: settings.Namespace()},
+				Data:       map[string]string{configurator.PermissiveTrafficPolicyModeKey: "false"},
+			},
+			newTestPod("bookbuyer", "bookbuyer-client", "bookbuyer", true),
+			newTestPod("bookstore", "bookstore-server", "bookstore", true),
+			newTestPod("bookthief", "bookthief-client", "bookthief", true),
+		),
+		smiAccessClient: fakeAccessClient.NewSimpleClientset(&smiAccess.TrafficTarget{
+			ObjectMeta: metav1.ObjectMeta{Name: "bookstore", Namespace: "bookstore"},
+			Spec: smiAccess.TrafficTargetSpec{
+				Destination: smiAccess.IdentityBindingSubject{Kind: serviceAccountKind, Name: "bookstore", Namespace: "bookstore"},
+				Sources:     []smiAccess.IdentityBindingSubject{{Kind: serviceAccountKind, Name: "bookbuyer", Namespace: "bookbuyer"}},
+				Rules:       []smiAccess.TrafficTargetRule{{Kind: httpRouteGroupKind, Name: "bookstore-routes"}},
+			},
+		}),
+		smiSpecClient: fakeSpecClient.NewSimpleClientset(&smiSpecs.HTTPRouteGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "bookstore-routes", Namespace: "bookstore"},
+			Spec:       smiSpecs.HTTPRouteGroupSpec{Matches: []smiSpecs.HTTPMatch{{Name: "all", PathRegex: ".*"}}},
+		}),
+		smiSplitClient: fakeSplitClient.NewSimpleClientset(),
+		policyClient:   fakePolicyClient.NewSimpleClientset(),
+	}
+	assert.Nil(liveCmd.writeSnapshot(dir, "bookbuyer/bookbuyer-client", "bookstore/bookstore-server"))
+
+	s, err := readSnapshot(dir)
+	assert.Nil(err)
+	// The pods of the namespaces that are neither the source nor the destination namespace are not written
+	assert.Len(s.kubeObjects, 5)
+	assert.Len(s.smiAccessObjects, 1)
+	assert.Len(s.smiSpecObjects, 1)
+
+	offlineCmd := &trafficPolicyCheckCmd{
+		out:            new(bytes.Buffer),
+		sourcePod:      "bookbuyer/bookbuyer-client",
+		destinationPod: "bookstore/bookstore-server",
+		meshConfigName: osmConfigMapName,
+		skipRBACCheck:  true,
+	}
+	trequire.Nil(t, offlineCmd.useSnapshot(dir))
+	assert.Nil(offlineCmd.run())
+}
+
+// newTestSnapshotDir returns a new temporary directory holding the given files, by path relative to the directory
+func newTestSnapshotDir(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir(os.TempDir(), "osm-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for path, content := range files {
+		path = filepath.Join(dir, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0750); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
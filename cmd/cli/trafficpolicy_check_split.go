@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiSplit "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/split/v1alpha2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+
+	k8s "github.com/openservicemesh/osm/pkg/kubernetes"
+)
+
+const (
+	trafficSplitKind = "TrafficSplit"
+	serviceKind      = "Service"
+)
+
+// checkTrafficSplits prints the weighted backends of the SMI TrafficSplits applicable to the services of 'dstPod',
+// along with whether 'srcPod' is allowed to communicate to each backend.
+// 'trafficTargets' are the TrafficTargets in the destination namespace, they are not used in permissive mode.
+func (cmd *trafficPolicyCheckCmd) checkTrafficSplits(srcPod, dstPod *corev1.Pod, permissiveMode bool, trafficTargets []smiAccess.TrafficTarget) error {
+	dstServices, err := cmd.getPodServices(dstPod)
+	if err != nil {
+		return err
+	}
+	return cmd.checkServicesTrafficSplits(srcPod, dstPod.Namespace, dstServices, fmt.Sprintf("Pod '%s/%s'", dstPod.Namespace, dstPod.Name), permissiveMode, trafficTargets)
+}
+
+// checkServicesTrafficSplits prints the weighted backends of the SMI TrafficSplits applicable to the given services in
+// the destination namespace, along with whether 'srcPod' is allowed to communicate to each backend.
+// 'dstDescription' describes the destination behind the services in the output.
+func (cmd *trafficPolicyCheckCmd) checkServicesTrafficSplits(srcPod *corev1.Pod, dstNamespace string, dstServices map[string]bool, dstDescription string,
+	permissiveMode bool, trafficTargets []smiAccess.TrafficTarget) error {
+	if len(dstServices) == 0 {
+		return nil
+	}
+
+	trafficSplits, err := cmd.listTrafficSplits(dstNamespace)
+	if err != nil {
+		return err
+	}
+
+	for _, trafficSplit := range trafficSplits {
+		if !isTrafficSplitForServices(trafficSplit, dstServices) {
+			continue
+		}
+
+		fmt.Fprintf(cmd.out, "\n[+] %s is behind the SMI TrafficSplit %q for service '%s/%s', traffic is split across the following backends:\n",
+			dstDescription, trafficSplit.Name, trafficSplit.Namespace, k8s.GetServiceFromHostname(trafficSplit.Spec.Service))
+
+		w := newTabWriter(cmd.out)
+		fmt.Fprintln(w, "BACKEND\tWEIGHT\tALLOWED\t")
+		for _, backend := range trafficSplit.Spec.Backends {
+			allowed, err := cmd.getBackendAllowedStatus(srcPod, trafficSplit.Namespace, backend.Service, permissiveMode, trafficTargets)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(w, "%s/%s\t%d\t%s\t\n", trafficSplit.Namespace, backend.Service, backend.Weight, allowed)
+		}
+		_ = w.Flush()
+	}
+
+	return nil
+}
+
+// getPodServices returns the names of the services in the pod's namespace selecting the given pod
+func (cmd *trafficPolicyCheckCmd) getPodServices(pod *corev1.Pod) (map[string]bool, error) {
+	services, err := cmd.listServices(pod.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	podServices := make(map[string]bool)
+	for _, svc := range services {
+		if len(svc.Spec.Selector) == 0 {
+			continue
+		}
+		if labels.SelectorFromSet(svc.Spec.Selector).Matches(labels.Set(pod.Labels)) {
+			podServices[svc.Name] = true
+		}
+	}
+	return podServices, nil
+}
+
+// listTrafficSplits returns the SMI TrafficSplits in the given namespace
+func (cmd *trafficPolicyCheckCmd) listTrafficSplits(namespace string) ([]smiSplit.TrafficSplit, error) {
+	trafficSplits, err := cmd.listCache.get(namespace, trafficSplitKind, func() (interface{}, error) {
+		trafficSplits, err := cmd.smiSplitClient.SplitV1alpha2().TrafficSplits(namespace).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return nil, errors.Errorf("Error listing SMI TrafficSplit policies: %s", err)
+		}
+		return trafficSplits.Items, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return trafficSplits.([]smiSplit.TrafficSplit), nil
+}
+
+// listServices returns the services in the given namespace
+func (cmd *trafficPolicyCheckCmd) listServices(namespace string) ([]corev1.Service, error) {
+	services, err := cmd.listCache.get(namespace, serviceKind, func() (interface{}, error) {
+		services, err := cmd.clientSet.CoreV1().Services(namespace).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return nil, errors.Errorf("Error listing services in namespace %s: %s", namespace, err)
+		}
+		return services.Items, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return services.([]corev1.Service), nil
+}
+
+// isTrafficSplitForServices returns true if the root service or one of the backends of the TrafficSplit is one of the given services
+func isTrafficSplitForServices(trafficSplit smiSplit.TrafficSplit, services map[string]bool) bool {
+	if services[k8s.GetServiceFromHostname(trafficSplit.Spec.Service)] {
+		return true
+	}
+	for _, backend := range trafficSplit.Spec.Backends {
+		if services[backend.Service] {
+			return true
+		}
+	}
+	return false
+}
+
+// getBackendAllowedStatus returns a description of whether 'srcPod' is allowed to communicate to the pods backing the given service
+func (cmd *trafficPolicyCheckCmd) getBackendAllowedStatus(srcPod *corev1.Pod, namespace, service string, permissiveMode bool, trafficTargets []smiAccess.TrafficTarget) (string, error) {
+	if permissiveMode {
+		return "yes (permissive mode)", nil
+	}
+
+	serviceAccounts, err := cmd.getServiceAccountsForService(namespace, service)
+	if err != nil {
+		return "", err
+	}
+	if len(serviceAccounts) == 0 {
+		return "unknown (no meshed pods backing the service)", nil
+	}
+
+	var allowingTrafficTargetNames, deniedServiceAccounts []string
+	for _, serviceAccount := range serviceAccounts {
+		allowingTrafficTargets := getAllowingTrafficTargets(trafficTargets, srcPod, namespace, serviceAccount)
+		if len(allowingTrafficTargets) == 0 {
+			deniedServiceAccounts = append(deniedServiceAccounts, serviceAccount)
+			continue
+		}
+		for _, trafficTarget := range allowingTrafficTargets {
+			allowingTrafficTargetNames = append(allowingTrafficTargetNames, trafficTarget.Name)
+		}
+	}
+
+	if len(deniedServiceAccounts) > 0 {
+		return fmt.Sprintf("no (missing SMI TrafficTarget policy for service account(s) %s)", strings.Join(deniedServiceAccounts, ", ")), nil
+	}
+	return fmt.Sprintf("yes (SMI TrafficTarget policy %s)", strings.Join(allowingTrafficTargetNames, ", ")), nil
+}
+
+// getServiceAccountsForService returns the sorted service accounts of the meshed pods backing the given service
+func (cmd *trafficPolicyCheckCmd) getServiceAccountsForService(namespace, service string) ([]string, error) {
+	svc, err := cmd.clientSet.CoreV1().Services(namespace).Get(context.TODO(), service, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.Errorf("Could not find service %s in namespace %s: %s", service, namespace, err)
+	}
+	if len(svc.Spec.Selector) == 0 {
+		return nil, nil
+	}
+
+	pods, err := cmd.clientSet.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{
+		LabelSelector: labels.SelectorFromSet(svc.Spec.Selector).String(),
+	})
+	if err != nil {
+		return nil, errors.Errorf("Error listing pods for service %s in namespace %s: %s", service, namespace, err)
+	}
+
+	serviceAccountSet := make(map[string]bool)
+	for _, pod := range pods.Items {
+		if isMeshedPod(pod) {
+			serviceAccountSet[pod.Spec.ServiceAccountName] = true
+		}
+	}
+
+	var serviceAccounts []string
+	for serviceAccount := range serviceAccountSet {
+		serviceAccounts = append(serviceAccounts, serviceAccount)
+	}
+	sort.Strings(serviceAccounts)
+	return serviceAccounts, nil
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing"
+
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiSplit "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/split/v1alpha2"
+	fakeSplitClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/split/clientset/versioned/fake"
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestCheckTrafficSplits(t *testing.T) {
+	newService := func(name, app string) *corev1.Service {
+		return &corev1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "ns-2",
+			},
+			Spec: corev1.ServiceSpec{
+				Selector: map[string]string{"app": app},
+			},
+		}
+	}
+
+	srcPod := newTestPod("ns-1", "pod-1", "sa-1", false)
+	dstPodV1 := newTestPod("ns-2", "pod-v1", "sa-v1", true)
+	dstPodV1.Labels["app"] = "bookstore-v1"
+	dstPodV2 := newTestPod("ns-2", "pod-v2", "sa-v2", true)
+	dstPodV2.Labels["app"] = "bookstore-v2"
+	otherPod := newTestPod("ns-2", "pod-other", "sa-other", true)
+	otherPod.Labels["app"] = "other"
+
+	trafficSplit := &smiSplit.TrafficSplit{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "split-1",
+			Namespace: "ns-2",
+		},
+		Spec: smiSplit.TrafficSplitSpec{
+			Service: "bookstore.ns-2",
+			Backends: []smiSplit.TrafficSplitBackend{
+				{Service: "bookstore-v1", Weight: 90},
+				{Service: "bookstore-v2", Weight: 10},
+			},
+		},
+	}
+
+	trafficTargets := []smiAccess.TrafficTarget{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-v1",
+				Namespace: "ns-2",
+			},
+			Spec: smiAccess.TrafficTargetSpec{
+				Destination: smiAccess.IdentityBindingSubject{
+					Kind:      "ServiceAccount",
+					Name:      "sa-v1",
+					Namespace: "ns-2",
+				},
+				Sources: []smiAccess.IdentityBindingSubject{{
+					Kind:      "ServiceAccount",
+					Name:      "sa-1",
+					Namespace: "ns-1",
+				}},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name                string
+		dstPod              *corev1.Pod
+		permissiveMode      bool
+		expectedOutSubstrs  []string
+		unexpectedOutSubstr string
+	}{
+		{
+			name:   "backends are independently allowed by TrafficTargets",
+			dstPod: dstPodV1,
+			expectedOutSubstrs: []string{
+				`behind the SMI TrafficSplit "split-1" for service 'ns-2/bookstore'`,
+				"ns-2/bookstore-v1   90       yes (SMI TrafficTarget policy test-v1)",
+				"ns-2/bookstore-v2   10       no (missing SMI TrafficTarget policy for service account(s) sa-v2)",
+			},
+		},
+		{
+			name:           "all backends are allowed in permissive mode",
+			dstPod:         dstPodV2,
+			permissiveMode: true,
+			expectedOutSubstrs: []string{
+				`behind the SMI TrafficSplit "split-1" for service 'ns-2/bookstore'`,
+				"ns-2/bookstore-v1   90       yes (permissive mode)",
+				"ns-2/bookstore-v2   10       yes (permissive mode)",
+			},
+		},
+		{
+			name:                "pod not behind a TrafficSplit",
+			dstPod:              otherPod,
+			unexpectedOutSubstr: "TrafficSplit",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing %s", tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			fakeClient := fake.NewSimpleClientset(dstPodV1, dstPodV2,
+				newService("bookstore", "bookstore-v1"), newService("bookstore-v1", "bookstore-v1"), newService("bookstore-v2", "bookstore-v2"))
+			out := new(bytes.Buffer)
+			cmd := trafficPolicyCheckCmd{
+				clientSet:      fakeClient,
+				smiSplitClient: fakeSplitClient.NewSimpleClientset(trafficSplit),
+				out:            out,
+			}
+
+			_, err := fakeClient.CoreV1().Pods(tc.dstPod.Namespace).Get(context.TODO(), tc.dstPod.Name, metav1.GetOptions{})
+			if err != nil {
+				_, err = fakeClient.CoreV1().Pods(tc.dstPod.Namespace).Create(context.TODO(), tc.dstPod, metav1.CreateOptions{})
+				assert.Nil(err)
+			}
+
+			err = cmd.checkTrafficSplits(srcPod, tc.dstPod, tc.permissiveMode, trafficTargets)
+			assert.Nil(err)
+			for _, substr := range tc.expectedOutSubstrs {
+				assert.Contains(out.String(), substr)
+			}
+			if tc.unexpectedOutSubstr != "" {
+				assert.NotContains(out.String(), tc.unexpectedOutSubstr)
+			}
+		})
+	}
+}
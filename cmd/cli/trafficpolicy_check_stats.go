@@ -0,0 +1,66 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// inboundTrafficStatsFilter matches the stats of the local clusters of a proxy, named '<namespace>/<service>-local',
+// counting the HTTP requests and the TCP connections received by the proxy for its services
+var inboundTrafficStatsFilter = regexp.MustCompile(`^(cluster\.[^.]+-local\.(upstream_rq_total|upstream_rq_5xx)|tcp\.inbound-mesh-tcp-proxy\.[^.]+-local\.downstream_cx_total)$`)
+
+// inboundTrafficStats is the traffic received by a proxy for the services of its pod since the proxy started
+type inboundTrafficStats struct {
+	httpRequests     int64
+	httpServerErrors int64
+	tcpConnections   int64
+}
+
+// printDestinationStats prints the HTTP requests and TCP connections received by the proxy of 'dstPod' since it
+// started, to tell whether the allowed traffic is actually flowing. The proxy does not record stats per SMI route, so
+// the stats cover the traffic received from every source over every route. A warning is printed instead of failing the
+// check when the stats of the proxy can't be retrieved.
+func (cmd *trafficPolicyCheckCmd) printDestinationStats(dstPod *corev1.Pod) {
+	stats, err := cmd.getProxyStats(dstPod)
+	if err != nil {
+		fmt.Fprintf(cmd.out, "[!] Could not retrieve the stats of the proxy of pod '%s/%s': %s\n", dstPod.Namespace, dstPod.Name, err)
+		return
+	}
+	traffic := parseInboundTrafficStats(stats)
+
+	fmt.Fprintf(cmd.out, "[+] Traffic received by the proxy of pod '%s/%s' from all sources since it started:\n", dstPod.Namespace, dstPod.Name)
+	if traffic.httpRequests > 0 {
+		successRate := float64(traffic.httpRequests-traffic.httpServerErrors) / float64(traffic.httpRequests) * 100
+		fmt.Fprintf(cmd.out, "    L7 HTTP: %d requests, %.1f%% success rate (non-5xx responses)\n", traffic.httpRequests, successRate)
+	} else {
+		fmt.Fprintln(cmd.out, "    L7 HTTP: no requests")
+	}
+	if traffic.tcpConnections > 0 {
+		fmt.Fprintf(cmd.out, "    L4 TCP: %d connections\n", traffic.tcpConnections)
+	} else {
+		fmt.Fprintln(cmd.out, "    L4 TCP: no connections")
+	}
+}
+
+// parseInboundTrafficStats sums the stats of the local clusters among the given Envoy stats rendered as 'name: value'
+// lines
+func parseInboundTrafficStats(stats []byte) inboundTrafficStats {
+	var traffic inboundTrafficStats
+	for _, stat := range parseProxyStats(stats, inboundTrafficStatsFilter) {
+		value, ok := stat.Value.(int64)
+		if !ok {
+			continue
+		}
+		switch submatches := inboundTrafficStatsFilter.FindStringSubmatch(stat.Name); submatches[2] {
+		case "upstream_rq_total":
+			traffic.httpRequests += value
+		case "upstream_rq_5xx":
+			traffic.httpServerErrors += value
+		default:
+			traffic.tcpConnections += value
+		}
+	}
+	return traffic
+}
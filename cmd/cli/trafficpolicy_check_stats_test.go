@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	fakeAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned/fake"
+	fakeSplitClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/split/clientset/versioned/fake"
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+)
+
+const testInboundProxyStats = `cluster.ns-2/svc-2-local.upstream_rq_total: 200
+cluster.ns-2/svc-2-local.upstream_rq_5xx: 4
+cluster.ns-2/svc-3-local.upstream_rq_total: 50
+cluster.ns-2/svc-3-local.upstream_rq_5xx: 1
+cluster.ns-1/svc-1.upstream_rq_total: 1000
+cluster.ns-1/svc-1.upstream_rq_5xx: 10
+tcp.inbound-mesh-tcp-proxy.ns-2/svc-2-local.downstream_cx_total: 7
+tcp.outbound-mesh-tcp-proxy.ns-1/svc-1.downstream_cx_total: 30
+cluster.ns-2/svc-2-local.upstream_rq_time: P0(nan,1.0) P25(nan,2.5)
+`
+
+func TestParseInboundTrafficStats(t *testing.T) {
+	testCases := []struct {
+		name     string
+		stats    string
+		expected inboundTrafficStats
+	}{
+		{
+			name:     "stats of the local clusters are summed",
+			stats:    testInboundProxyStats,
+			expected: inboundTrafficStats{httpRequests: 250, httpServerErrors: 5, tcpConnections: 7},
+		},
+		{
+			name:     "no stats",
+			stats:    "",
+			expected: inboundTrafficStats{},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+			assert.Equal(tc.expected, parseInboundTrafficStats([]byte(tc.stats)))
+		})
+	}
+}
+
+func TestCheckTrafficPolicyShowStats(t *testing.T) {
+	srcPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "ns-1"},
+		Spec:       corev1.PodSpec{ServiceAccountName: "sa-1"},
+	}
+	dstPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-2", Namespace: "ns-2"},
+		Spec:       corev1.PodSpec{ServiceAccountName: "sa-2"},
+	}
+	trafficTarget := &smiAccess.TrafficTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1", Namespace: "ns-2"},
+		Spec: smiAccess.TrafficTargetSpec{
+			Destination: smiAccess.IdentityBindingSubject{Kind: serviceAccountKind, Name: "sa-2", Namespace: "ns-2"},
+			Sources:     []smiAccess.IdentityBindingSubject{{Kind: serviceAccountKind, Name: "sa-1", Namespace: "ns-1"}},
+		},
+	}
+
+	testCases := []struct {
+		name                 string
+		showStats            bool
+		trafficTargets       []runtime.Object
+		stats                string
+		statsErr             error
+		expectedAllowed      bool
+		expectedStatsQueried bool
+		expectedOutSubstrs   []string
+	}{
+		{
+			name:                 "stats are not queried without --show-stats",
+			showStats:            false,
+			trafficTargets:       []runtime.Object{trafficTarget},
+			expectedAllowed:      true,
+			expectedStatsQueried: false,
+		},
+		{
+			name:                 "stats are not queried when the traffic is denied",
+			showStats:            true,
+			expectedAllowed:      false,
+			expectedStatsQueried: false,
+		},
+		{
+			name:                 "stats of the destination proxy are printed when the traffic is allowed",
+			showStats:            true,
+			trafficTargets:       []runtime.Object{trafficTarget},
+			stats:                testInboundProxyStats,
+			expectedAllowed:      true,
+			expectedStatsQueried: true,
+			expectedOutSubstrs: []string{
+				"[+] Traffic received by the proxy of pod 'ns-2/pod-2' from all sources since it started:",
+				"L7 HTTP: 250 requests, 98.0% success rate (non-5xx responses)",
+				"L4 TCP: 7 connections",
+			},
+		},
+		{
+			name:                 "no traffic received by the destination proxy",
+			showStats:            true,
+			trafficTargets:       []runtime.Object{trafficTarget},
+			stats:                "",
+			expectedAllowed:      true,
+			expectedStatsQueried: true,
+			expectedOutSubstrs:   []string{"L7 HTTP: no requests", "L4 TCP: no connections"},
+		},
+		{
+			name:                 "traffic is still allowed when the stats can't be retrieved",
+			showStats:            true,
+			trafficTargets:       []runtime.Object{trafficTarget},
+			statsErr:             errors.New("connection refused"),
+			expectedAllowed:      true,
+			expectedStatsQueried: true,
+			expectedOutSubstrs:   []string{"[!] Could not retrieve the stats of the proxy of pod 'ns-2/pod-2': connection refused"},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			statsQueried := false
+			out := new(bytes.Buffer)
+			cmd := trafficPolicyCheckCmd{
+				out: out,
+				clientSet: fake.NewSimpleClientset(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: settings.Namespace(), Name: osmConfigMapName},
+					Data:       map[string]string{configurator.PermissiveTrafficPolicyModeKey: "false"},
+				}),
+				smiAccessClient: fakeAccessClient.NewSimpleClientset(tc.trafficTargets...),
+				smiSplitClient:  fakeSplitClient.NewSimpleClientset(),
+				meshConfigName:  osmConfigMapName,
+				showStats:       tc.showStats,
+				getProxyStats: func(pod *corev1.Pod) ([]byte, error) {
+					statsQueried = true
+					assert.Equal(dstPod, pod)
+					return []byte(tc.stats), tc.statsErr
+				},
+			}
+
+			allowed, err := cmd.checkTrafficPolicy(srcPod, dstPod)
+			assert.Nil(err)
+			assert.Equal(tc.expectedAllowed, allowed)
+			assert.Equal(tc.expectedStatsQueried, statsQueried)
+			for _, substr := range tc.expectedOutSubstrs {
+				assert.Contains(out.String(), substr)
+			}
+		})
+	}
+}
@@ -4,17 +4,30 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
 	"testing"
 
+	"github.com/pkg/errors"
 	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiSpecs "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/specs/v1alpha4"
 	fakeAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned/fake"
+	fakeSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned/fake"
+	fakeSplitClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/split/clientset/versioned/fake"
 	tassert "github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
 
+	"github.com/openservicemesh/osm/pkg/cli"
 	"github.com/openservicemesh/osm/pkg/configurator"
 	"github.com/openservicemesh/osm/pkg/constants"
+	"github.com/openservicemesh/osm/pkg/identity"
 )
 
 func TestUnmarshalNamespacedPod(t *testing.T) {
@@ -28,8 +41,11 @@ func TestUnmarshalNamespacedPod(t *testing.T) {
 	}{
 		{"foo/bar", "foo", "bar", false},
 		{"foo", metav1.NamespaceDefault, "foo", false},
+		{"foo/bar-5ccf77f46d.rc5mg", "foo", "bar-5ccf77f46d.rc5mg", false},
 		{"", "", "", true},
 		{"foo/bar/baz", "", "", true},
+		{"/bar", "", "", true},
+		{"foo/", "", "", true},
 	}
 
 	for _, tc := range testCases {
@@ -42,6 +58,125 @@ func TestUnmarshalNamespacedPod(t *testing.T) {
 	}
 }
 
+func TestUnmarshalNamespacedPodInvalidNames(t *testing.T) {
+	testCases := []struct {
+		name          string
+		namespacedPod string
+		expectedErr   string
+	}{
+		{
+			name:          "uppercase pod name",
+			namespacedPod: "foo/Bar",
+			expectedErr:   `Invalid pod name "Bar" in foo/Bar: a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters`,
+		},
+		{
+			name:          "uppercase namespace",
+			namespacedPod: "Foo/bar",
+			expectedErr:   `Invalid namespace "Foo" in Foo/bar: a lowercase RFC 1123 label must consist of lower case alphanumeric characters`,
+		},
+		{
+			name:          "underscore in pod name",
+			namespacedPod: "Foo_Bar",
+			expectedErr:   `Invalid pod name "Foo_Bar" in Foo_Bar: a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters`,
+		},
+		{
+			name:          "underscore in namespace",
+			namespacedPod: "foo_bar/baz",
+			expectedErr:   `Invalid namespace "foo_bar" in foo_bar/baz: a lowercase RFC 1123 label must consist of lower case alphanumeric characters`,
+		},
+		{
+			name:          "leading dash in pod name",
+			namespacedPod: "foo/-bar",
+			expectedErr:   `Invalid pod name "-bar" in foo/-bar: a lowercase RFC 1123 subdomain must consist of lower case alphanumeric characters, '-' or '.', and must start and end with an alphanumeric character`,
+		},
+		{
+			name:          "leading dash in namespace",
+			namespacedPod: "-foo/bar",
+			expectedErr:   `Invalid namespace "-foo" in -foo/bar: a lowercase RFC 1123 label must consist of lower case alphanumeric characters or '-', and must start and end with an alphanumeric character`,
+		},
+		{
+			name:          "dot in namespace",
+			namespacedPod: "foo.bar/baz",
+			expectedErr:   `Invalid namespace "foo.bar" in foo.bar/baz: a lowercase RFC 1123 label must consist of lower case alphanumeric characters`,
+		},
+		{
+			name:          "over-length pod name",
+			namespacedPod: "foo/" + strings.Repeat("a", 254),
+			expectedErr:   fmt.Sprintf(`Invalid pod name "%s" in foo/%s: must be no more than 253 characters`, strings.Repeat("a", 254), strings.Repeat("a", 254)),
+		},
+		{
+			name:          "over-length namespace",
+			namespacedPod: strings.Repeat("a", 64) + "/bar",
+			expectedErr:   fmt.Sprintf(`Invalid namespace "%s" in %s/bar: must be no more than 63 characters`, strings.Repeat("a", 64), strings.Repeat("a", 64)),
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			namespace, podName, err := unmarshalNamespacedPod(tc.namespacedPod)
+			assert.Empty(namespace)
+			assert.Empty(podName)
+			assert.NotNil(err)
+			assert.Contains(err.Error(), tc.expectedErr)
+		})
+	}
+}
+
+func TestValidateNamespace(t *testing.T) {
+	testCases := []struct {
+		name               string
+		namespace          string
+		namespaces         []runtime.Object
+		expectError        bool
+		expectedErrSubstrs []string
+	}{
+		{
+			name:       "namespace exists",
+			namespace:  "bookstore",
+			namespaces: []runtime.Object{newNamespace("bookstore", nil)},
+		},
+		{
+			name:        "namespace is a typo of an existing namespace",
+			namespace:   "bookstor",
+			namespaces:  []runtime.Object{newNamespace("bookstore", nil), newNamespace("bookbuyer", nil), &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}}},
+			expectError: true,
+			expectedErrSubstrs: []string{
+				"Namespace bookstor does not exist",
+				"Did you mean: bookstore?",
+				"Meshed namespaces: bookbuyer, bookstore",
+			},
+		},
+		{
+			name:        "namespace does not resemble any namespace",
+			namespace:   "foo",
+			namespaces:  []runtime.Object{&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "bookstore"}}},
+			expectError: true,
+			expectedErrSubstrs: []string{
+				"Namespace foo does not exist",
+				"No namespaces are part of a mesh",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing %s", tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+			cmd := trafficPolicyCheckCmd{
+				clientSet: fake.NewSimpleClientset(tc.namespaces...),
+				out:       new(bytes.Buffer),
+			}
+
+			err := cmd.validateNamespace(tc.namespace)
+			assert.Equal(tc.expectError, err != nil)
+			for _, substr := range tc.expectedErrSubstrs {
+				assert.Contains(err.Error(), substr)
+			}
+		})
+	}
+}
+
 func TestIsPermissiveModeEnabled(t *testing.T) {
 	assert := tassert.New(t)
 	fakeClient := fake.NewSimpleClientset()
@@ -133,6 +268,7 @@ func TestCheckTrafficPolicy(t *testing.T) {
 	cmd := trafficPolicyCheckCmd{
 		clientSet:       fakeClient,
 		smiAccessClient: fakeAccessClient,
+		smiSplitClient:  fakeSplitClient.NewSimpleClientset(),
 		out:             out,
 	}
 
@@ -142,6 +278,7 @@ func TestCheckTrafficPolicy(t *testing.T) {
 		trafficTarget     smiAccess.TrafficTarget
 		configMap         corev1.ConfigMap
 		expectError       bool
+		expectAllowed     bool
 		expectedOutSubstr string
 	}{
 		// first test case: source and destination are allowed by SMI TrafficTarget
@@ -198,6 +335,7 @@ func TestCheckTrafficPolicy(t *testing.T) {
 				},
 			},
 			false,
+			true,
 			"is allowed to communicate",
 		},
 		// second test case: source and destination are not allowed by SMI TrafficTarget
@@ -254,6 +392,7 @@ func TestCheckTrafficPolicy(t *testing.T) {
 				},
 			},
 			false,
+			false,
 			"is not allowed to communicate",
 		},
 
@@ -311,6 +450,7 @@ func TestCheckTrafficPolicy(t *testing.T) {
 				},
 			},
 			false,
+			true,
 			"is allowed to communicate",
 		},
 	}
@@ -356,8 +496,9 @@ func TestCheckTrafficPolicy(t *testing.T) {
 			_, err = fakeAccessClient.AccessV1alpha3().TrafficTargets(tc.trafficTarget.Namespace).Create(context.TODO(), &tc.trafficTarget, metav1.CreateOptions{})
 			assert.Nil(err)
 
-			err = cmd.checkTrafficPolicy(&tc.srcPod, &tc.dstPod)
+			allowed, err := cmd.checkTrafficPolicy(&tc.srcPod, &tc.dstPod)
 			assert.Equal(err != nil, tc.expectError)
+			assert.Equal(tc.expectAllowed, allowed)
 			assert.Contains(out.String(), tc.expectedOutSubstr)
 
 			// delete the ConfigMap for the next test case using the same ConfigMap
@@ -370,3 +511,468 @@ func TestCheckTrafficPolicy(t *testing.T) {
 		})
 	}
 }
+
+func TestTrafficPolicyCheckExitCodes(t *testing.T) {
+	testCases := []struct {
+		name             string
+		destination      string
+		destinationKind  string
+		listTargetsErr   error
+		expectedExitCode int
+	}{
+		{
+			name:             "traffic allowed",
+			destination:      "ns-2/pod-2",
+			expectedExitCode: 0,
+		},
+		{
+			name:             "traffic denied",
+			destination:      "ns-2/pod-3",
+			expectedExitCode: checkExitCodeTrafficDenied,
+		},
+		{
+			name:             "destination namespace does not exist",
+			destination:      "ns-404/pod-2",
+			expectedExitCode: checkExitCodeInvalidInput,
+		},
+		{
+			name:             "destination pod does not exist",
+			destination:      "ns-2/pod-404",
+			expectedExitCode: checkExitCodeInvalidInput,
+		},
+		{
+			name:             "destination pod is not meshed",
+			destination:      "ns-2/unmeshed",
+			expectedExitCode: checkExitCodeInvalidInput,
+		},
+		{
+			name:             "invalid destination kind",
+			destination:      "ns-2/pod-2",
+			destinationKind:  "deployment",
+			expectedExitCode: checkExitCodeInvalidInput,
+		},
+		{
+			name:             "error listing SMI TrafficTarget policies",
+			destination:      "ns-2/pod-2",
+			listTargetsErr:   errors.New("connection refused"),
+			expectedExitCode: checkExitCodeAPIError,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			fakeClient := fake.NewSimpleClientset(
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-1"}},
+				&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-2"}},
+				newTestPod("ns-1", "pod-1", "sa-1", true),
+				newTestPod("ns-2", "pod-2", "sa-2", true),
+				newTestPod("ns-2", "pod-3", "sa-3", true),
+				newTestPod("ns-2", "unmeshed", "sa-2", false),
+				&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: settings.Namespace(), Name: osmConfigMapName},
+					Data:       map[string]string{configurator.PermissiveTrafficPolicyModeKey: "false"},
+				},
+			)
+			accessClient := fakeAccessClient.NewSimpleClientset(&smiAccess.TrafficTarget{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-1", Namespace: "ns-2"},
+				Spec: smiAccess.TrafficTargetSpec{
+					Destination: smiAccess.IdentityBindingSubject{Kind: "ServiceAccount", Name: "sa-2", Namespace: "ns-2"},
+					Sources:     []smiAccess.IdentityBindingSubject{{Kind: "ServiceAccount", Name: "sa-1", Namespace: "ns-1"}},
+				},
+			})
+			if tc.listTargetsErr != nil {
+				accessClient.PrependReactor("list", "traffictargets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+					return true, nil, tc.listTargetsErr
+				})
+			}
+
+			cmd := trafficPolicyCheckCmd{
+				out:             new(bytes.Buffer),
+				sourcePod:       "ns-1/pod-1",
+				destinationPod:  tc.destination,
+				destinationKind: tc.destinationKind,
+				clientSet:       fakeClient,
+				smiAccessClient: accessClient,
+				smiSplitClient:  fakeSplitClient.NewSimpleClientset(),
+				skipRBACCheck:   true,
+			}
+
+			err := cmd.run()
+			if tc.expectedExitCode == 0 {
+				assert.Nil(err)
+				return
+			}
+			assert.NotNil(err)
+			assert.Equal(tc.expectedExitCode, getExitCode(err))
+		})
+	}
+}
+
+func TestCheckTrafficPolicyAllNamespaces(t *testing.T) {
+	srcPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "ns-1"},
+		Spec:       corev1.PodSpec{ServiceAccountName: "sa-1"},
+	}
+	dstPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-2", Namespace: "ns-b"},
+		Spec:       corev1.PodSpec{ServiceAccountName: "sa-2"},
+	}
+	trafficTargets := []runtime.Object{
+		// TrafficTarget defined in namespace ns-a governing the destination in namespace ns-b
+		&smiAccess.TrafficTarget{
+			ObjectMeta: metav1.ObjectMeta{Name: "cross-namespace", Namespace: "ns-a"},
+			Spec: smiAccess.TrafficTargetSpec{
+				Destination: smiAccess.IdentityBindingSubject{Kind: serviceAccountKind, Name: "sa-2", Namespace: "ns-b"},
+				Sources:     []smiAccess.IdentityBindingSubject{{Kind: serviceAccountKind, Name: "sa-1", Namespace: "ns-1"}},
+			},
+		},
+		// TrafficTarget defined in namespace ns-a governing a destination with the same name in namespace ns-a
+		&smiAccess.TrafficTarget{
+			ObjectMeta: metav1.ObjectMeta{Name: "same-namespace", Namespace: "ns-a"},
+			Spec: smiAccess.TrafficTargetSpec{
+				Destination: smiAccess.IdentityBindingSubject{Kind: serviceAccountKind, Name: "sa-2", Namespace: "ns-a"},
+				Sources:     []smiAccess.IdentityBindingSubject{{Kind: serviceAccountKind, Name: "sa-1", Namespace: "ns-1"}},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name              string
+		allNamespaces     bool
+		expectAllowed     bool
+		expectedOutSubstr string
+	}{
+		{
+			name:              "only the destination namespace is scanned",
+			allNamespaces:     false,
+			expectAllowed:     false,
+			expectedOutSubstr: "is not allowed to communicate to pod 'ns-b/pod-2', missing SMI TrafficTarget policy",
+		},
+		{
+			name:              "all the namespaces are scanned",
+			allNamespaces:     true,
+			expectAllowed:     true,
+			expectedOutSubstr: `is allowed to communicate to pod 'ns-b/pod-2' via the SMI TrafficTarget policy "cross-namespace"`,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			out := new(bytes.Buffer)
+			cmd := trafficPolicyCheckCmd{
+				out: out,
+				clientSet: fake.NewSimpleClientset(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: settings.Namespace(), Name: osmConfigMapName},
+					Data:       map[string]string{configurator.PermissiveTrafficPolicyModeKey: "false"},
+				}),
+				smiAccessClient: fakeAccessClient.NewSimpleClientset(trafficTargets...),
+				smiSplitClient:  fakeSplitClient.NewSimpleClientset(),
+				meshConfigName:  osmConfigMapName,
+				allNamespaces:   tc.allNamespaces,
+			}
+
+			allowed, err := cmd.checkTrafficPolicy(srcPod, dstPod)
+			assert.Nil(err)
+			assert.Equal(tc.expectAllowed, allowed)
+			assert.Contains(out.String(), tc.expectedOutSubstr)
+			assert.NotContains(out.String(), "same-namespace")
+		})
+	}
+}
+
+func TestCheckTrafficPolicyDanglingReferences(t *testing.T) {
+	srcPod := newTestPod("ns-1", "pod-1", "sa-1", true)
+	dstPod := newTestPod("ns-2", "pod-2", "sa-2", true)
+	source := identity.K8sServiceAccount{Namespace: "ns-1", Name: "sa-1"}
+	destination := identity.K8sServiceAccount{Namespace: "ns-2", Name: "sa-2"}
+	rule := smiAccess.TrafficTargetRule{Kind: tcpRouteKind, Name: "tcp"}
+
+	testCases := []struct {
+		name                 string
+		trafficTarget        *smiAccess.TrafficTarget
+		objects              []runtime.Object
+		expectedOutSubstr    string
+		notExpectedOutSubstr string
+	}{
+		{
+			name:          "references of the allowing TrafficTarget exist",
+			trafficTarget: newTestTrafficTarget("test-1", source, destination, rule),
+			objects: []runtime.Object{
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "sa-1"}},
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "ns-2", Name: "sa-2"}},
+			},
+			notExpectedOutSubstr: "Warning",
+		},
+		{
+			name:          "allowing TrafficTarget references a service account that does not exist",
+			trafficTarget: newTestTrafficTarget("test-1", source, destination, rule),
+			objects: []runtime.Object{
+				&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Namespace: "ns-1", Name: "sa-1"}},
+			},
+			expectedOutSubstr: "[!] Warning: TrafficTarget ns-2/test-1 references resources that do not exist: ServiceAccount ns-2/sa-2",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			out := new(bytes.Buffer)
+			cmd := trafficPolicyCheckCmd{
+				out: out,
+				clientSet: fake.NewSimpleClientset(append(tc.objects, &corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: settings.Namespace(), Name: osmConfigMapName},
+					Data:       map[string]string{configurator.PermissiveTrafficPolicyModeKey: "false"},
+				})...),
+				smiAccessClient: fakeAccessClient.NewSimpleClientset(tc.trafficTarget),
+				smiSpecClient: fakeSpecClient.NewSimpleClientset(&smiSpecs.TCPRoute{
+					ObjectMeta: metav1.ObjectMeta{Namespace: "ns-2", Name: "tcp"},
+				}),
+				smiSplitClient: fakeSplitClient.NewSimpleClientset(),
+				meshConfigName: osmConfigMapName,
+			}
+
+			allowed, err := cmd.checkTrafficPolicy(srcPod, dstPod)
+			assert.Nil(err)
+			assert.True(allowed)
+			assert.Contains(out.String(), tc.expectedOutSubstr)
+			if tc.notExpectedOutSubstr != "" {
+				assert.NotContains(out.String(), tc.notExpectedOutSubstr)
+			}
+		})
+	}
+}
+
+func TestCheckTrafficPolicyRequireSMI(t *testing.T) {
+	srcPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "ns-1"},
+		Spec:       corev1.PodSpec{ServiceAccountName: "sa-1"},
+	}
+	dstPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-2", Namespace: "ns-2"},
+		Spec:       corev1.PodSpec{ServiceAccountName: "sa-2"},
+	}
+	trafficTarget := &smiAccess.TrafficTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1", Namespace: "ns-2"},
+		Spec: smiAccess.TrafficTargetSpec{
+			Destination: smiAccess.IdentityBindingSubject{Kind: serviceAccountKind, Name: "sa-2", Namespace: "ns-2"},
+			Sources:     []smiAccess.IdentityBindingSubject{{Kind: serviceAccountKind, Name: "sa-1", Namespace: "ns-1"}},
+		},
+	}
+
+	testCases := []struct {
+		name                 string
+		requireSMI           bool
+		explainDeny          bool
+		trafficTargets       []runtime.Object
+		expectedOutSubstr    string
+		notExpectedOutSubstr string
+	}{
+		{
+			name:                 "SMI policies are not evaluated without --require-smi",
+			requireSMI:           false,
+			expectedOutSubstr:    "[+] Permissive mode enabled",
+			notExpectedOutSubstr: "permissive mode disabled",
+		},
+		{
+			name:                 "warning when no SMI TrafficTarget policy would allow the pair",
+			requireSMI:           true,
+			expectedOutSubstr:    "[!] Warning: Pod 'ns-1/pod-1' would not be allowed to communicate to pod 'ns-2/pod-2' with permissive mode disabled, missing SMI TrafficTarget policy",
+			notExpectedOutSubstr: "The following SMI policies would allow",
+		},
+		{
+			name:              "remediation printed along with the warning with --explain-deny",
+			requireSMI:        true,
+			explainDeny:       true,
+			expectedOutSubstr: "The following SMI policies would allow service account 'ns-1/sa-1' to communicate to service account 'ns-2/sa-2'",
+		},
+		{
+			name:                 "SMI TrafficTarget policy would allow the pair",
+			requireSMI:           true,
+			trafficTargets:       []runtime.Object{trafficTarget},
+			expectedOutSubstr:    `[+] Pod 'ns-1/pod-1' would also be allowed to communicate to pod 'ns-2/pod-2' with permissive mode disabled, via the SMI TrafficTarget policy "test-1"`,
+			notExpectedOutSubstr: "Warning",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			out := new(bytes.Buffer)
+			cmd := trafficPolicyCheckCmd{
+				out: out,
+				clientSet: fake.NewSimpleClientset(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: settings.Namespace(), Name: osmConfigMapName},
+					Data:       map[string]string{configurator.PermissiveTrafficPolicyModeKey: "true"},
+				}),
+				smiAccessClient: fakeAccessClient.NewSimpleClientset(tc.trafficTargets...),
+				smiSplitClient:  fakeSplitClient.NewSimpleClientset(),
+				meshConfigName:  osmConfigMapName,
+				requireSMI:      tc.requireSMI,
+				explainDeny:     tc.explainDeny,
+			}
+
+			allowed, err := cmd.checkTrafficPolicy(srcPod, dstPod)
+			assert.Nil(err)
+			assert.True(allowed)
+			assert.Contains(out.String(), "[+] Permissive mode enabled")
+			assert.Contains(out.String(), tc.expectedOutSubstr)
+			if tc.notExpectedOutSubstr != "" {
+				assert.NotContains(out.String(), tc.notExpectedOutSubstr)
+			}
+		})
+	}
+}
+
+func TestTrafficPolicyCheckImpersonation(t *testing.T) {
+	testCases := []struct {
+		name           string
+		asUser         string
+		asGroups       []string
+		expectedUser   string
+		expectedGroups []string
+	}{
+		{
+			name:           "no impersonation",
+			expectedUser:   "",
+			expectedGroups: nil,
+		},
+		{
+			name:           "impersonate a service account",
+			asUser:         "system:serviceaccount:audit:auditor",
+			expectedUser:   "system:serviceaccount:audit:auditor",
+			expectedGroups: nil,
+		},
+		{
+			name:           "impersonate a user and groups",
+			asUser:         "alice",
+			asGroups:       []string{"auditors", "system:authenticated"},
+			expectedUser:   "alice",
+			expectedGroups: []string{"auditors", "system:authenticated"},
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			var lock sync.Mutex
+			var requests []*http.Request
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				lock.Lock()
+				defer lock.Unlock()
+				requests = append(requests, r)
+				w.WriteHeader(http.StatusNotFound)
+			}))
+			defer server.Close()
+
+			cmd := &trafficPolicyCheckCmd{
+				asUser:   tc.asUser,
+				asGroups: tc.asGroups,
+			}
+			config := &rest.Config{Host: server.URL}
+			cmd.impersonate(config)
+
+			clients, err := cli.NewClientsForConfig(config)
+			assert.Nil(err)
+
+			// The requests fail since the server does not serve any resource, only their headers are checked
+			_, _ = clients.KubeClient.CoreV1().Pods("bookstore").Get(context.TODO(), "bookstore", metav1.GetOptions{})
+			_, _ = clients.SMIAccessClient.AccessV1alpha3().TrafficTargets("bookstore").List(context.TODO(), metav1.ListOptions{})
+			_, _ = clients.SMISpecClient.SpecsV1alpha4().HTTPRouteGroups("bookstore").List(context.TODO(), metav1.ListOptions{})
+
+			assert.Len(requests, 3)
+			for _, r := range requests {
+				assert.Equal(tc.expectedUser, r.Header.Get("Impersonate-User"), r.URL.Path)
+				assert.Equal(tc.expectedGroups, r.Header["Impersonate-Group"], r.URL.Path)
+			}
+		})
+	}
+}
+
+func TestCheckTrafficPolicyMultipleSources(t *testing.T) {
+	dstPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-2", Namespace: "ns-2"},
+		Spec:       corev1.PodSpec{ServiceAccountName: "sa-2"},
+	}
+	trafficTarget := &smiAccess.TrafficTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "broad-target", Namespace: "ns-2"},
+		Spec: smiAccess.TrafficTargetSpec{
+			Destination: smiAccess.IdentityBindingSubject{Kind: serviceAccountKind, Name: "sa-2", Namespace: "ns-2"},
+			Sources: []smiAccess.IdentityBindingSubject{
+				{Kind: serviceAccountKind, Name: "sa-1", Namespace: "ns-0"},
+				{Kind: "Group", Name: "sa-1", Namespace: "ns-1"},
+				{Kind: serviceAccountKind, Name: "sa-3", Namespace: "ns-1"},
+				{Kind: serviceAccountKind, Name: "sa-1", Namespace: "ns-1"},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name                 string
+		srcPod               *corev1.Pod
+		expectedAllowed      bool
+		expectedOutSubstr    string
+		notExpectedOutSubstr string
+	}{
+		{
+			name: "source matching the last of several sources",
+			srcPod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "ns-1"},
+				Spec:       corev1.PodSpec{ServiceAccountName: "sa-1"},
+			},
+			expectedAllowed:   true,
+			expectedOutSubstr: `[+] Access is granted by source 4 of 4 of the SMI TrafficTarget policy "broad-target": ServiceAccount 'ns-1/sa-1'`,
+		},
+		{
+			name: "source matching the first of several sources",
+			srcPod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod-0", Namespace: "ns-0"},
+				Spec:       corev1.PodSpec{ServiceAccountName: "sa-1"},
+			},
+			expectedAllowed:   true,
+			expectedOutSubstr: `[+] Access is granted by source 1 of 4 of the SMI TrafficTarget policy "broad-target": ServiceAccount 'ns-0/sa-1'`,
+		},
+		{
+			name: "source matching none of the sources",
+			srcPod: &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod-4", Namespace: "ns-1"},
+				Spec:       corev1.PodSpec{ServiceAccountName: "sa-4"},
+			},
+			expectedAllowed:      false,
+			expectedOutSubstr:    "missing SMI TrafficTarget policy",
+			notExpectedOutSubstr: "Access is granted by source",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			out := new(bytes.Buffer)
+			cmd := trafficPolicyCheckCmd{
+				out: out,
+				clientSet: fake.NewSimpleClientset(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: settings.Namespace(), Name: osmConfigMapName},
+					Data:       map[string]string{configurator.PermissiveTrafficPolicyModeKey: "false"},
+				}),
+				smiAccessClient: fakeAccessClient.NewSimpleClientset(trafficTarget),
+				smiSplitClient:  fakeSplitClient.NewSimpleClientset(),
+				meshConfigName:  osmConfigMapName,
+			}
+
+			allowed, err := cmd.checkTrafficPolicy(tc.srcPod, dstPod)
+			assert.Nil(err)
+			assert.Equal(tc.expectedAllowed, allowed)
+			assert.Contains(out.String(), tc.expectedOutSubstr)
+			if tc.notExpectedOutSubstr != "" {
+				assert.NotContains(out.String(), tc.notExpectedOutSubstr)
+			}
+		})
+	}
+}
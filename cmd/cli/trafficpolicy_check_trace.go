@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// tracef prints a step of the evaluation of the policies with --trace, and is a no-op otherwise
+func (cmd *trafficPolicyCheckCmd) tracef(format string, args ...interface{}) {
+	if !cmd.trace {
+		return
+	}
+	fmt.Fprintf(cmd.out, "[trace] "+format+"\n", args...)
+}
+
+// traceTrafficTargets prints with --trace whether each of the given TrafficTargets matches the given destination
+// service account, and whether each of its sources matches the service account of 'srcPod'. It mirrors the decisions
+// of getAllowingTrafficTargets.
+func (cmd *trafficPolicyCheckCmd) traceTrafficTargets(trafficTargets []smiAccess.TrafficTarget, srcPod *corev1.Pod, dstNamespace, dstServiceAccount string) {
+	if !cmd.trace {
+		return
+	}
+
+	for _, trafficTarget := range trafficTargets {
+		dst := trafficTarget.Spec.Destination
+		switch {
+		case dst.Kind != serviceAccountKind:
+			cmd.tracef("SMI TrafficTarget policy '%s/%s': destination kind %q is not %s, skipped",
+				trafficTarget.Namespace, trafficTarget.Name, dst.Kind, serviceAccountKind)
+			continue
+		case dst.Name != dstServiceAccount || dst.Namespace != dstNamespace:
+			cmd.tracef("SMI TrafficTarget policy '%s/%s': destination %s '%s/%s' does not match '%s/%s', skipped",
+				trafficTarget.Namespace, trafficTarget.Name, dst.Kind, dst.Namespace, dst.Name, dstNamespace, dstServiceAccount)
+			continue
+		}
+		cmd.tracef("SMI TrafficTarget policy '%s/%s': destination %s '%s/%s' matches",
+			trafficTarget.Namespace, trafficTarget.Name, dst.Kind, dst.Namespace, dst.Name)
+
+		matched := false
+		for i, source := range trafficTarget.Spec.Sources {
+			var result string
+			switch {
+			case matched:
+				result = "not evaluated, a previous source matches"
+			case source.Kind != serviceAccountKind:
+				result = fmt.Sprintf("kind is not %s, skipped", serviceAccountKind)
+			case source.Name != srcPod.Spec.ServiceAccountName || source.Namespace != srcPod.Namespace:
+				result = fmt.Sprintf("does not match '%s/%s'", srcPod.Namespace, srcPod.Spec.ServiceAccountName)
+			default:
+				result = "matches"
+				matched = true
+			}
+			cmd.tracef("  source %d of %d %s '%s/%s': %s", i+1, len(trafficTarget.Spec.Sources), source.Kind, source.Namespace, source.Name, result)
+		}
+		if !matched {
+			cmd.tracef("  no source matches service account '%s/%s' of pod '%s/%s'",
+				srcPod.Namespace, srcPod.Spec.ServiceAccountName, srcPod.Namespace, srcPod.Name)
+		}
+	}
+}
+
+// describeRouteResolution returns the trace of the resolution of the given route referenced by a rule of the given
+// TrafficTarget
+func describeRouteResolution(trafficTarget smiAccess.TrafficTarget, route string, found bool) string {
+	result := "found"
+	if !found {
+		result = "not found"
+	}
+	return fmt.Sprintf("Route %s of SMI TrafficTarget policy '%s/%s': %s in namespace %s", route, trafficTarget.Namespace, trafficTarget.Name, result, trafficTarget.Namespace)
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	fakeAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned/fake"
+	fakeSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned/fake"
+	fakeSplitClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/split/clientset/versioned/fake"
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+)
+
+func TestCheckTrafficPolicyTrace(t *testing.T) {
+	srcPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "ns-1"},
+		Spec:       corev1.PodSpec{ServiceAccountName: "sa-1"},
+	}
+	dstPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-2", Namespace: "ns-2"},
+		Spec:       corev1.PodSpec{ServiceAccountName: "sa-2"},
+	}
+	allowingTrafficTarget := &smiAccess.TrafficTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "allowing", Namespace: "ns-2"},
+		Spec: smiAccess.TrafficTargetSpec{
+			Destination: smiAccess.IdentityBindingSubject{Kind: serviceAccountKind, Name: "sa-2", Namespace: "ns-2"},
+			Sources: []smiAccess.IdentityBindingSubject{
+				{Kind: serviceAccountKind, Name: "sa-3", Namespace: "ns-1"},
+				{Kind: serviceAccountKind, Name: "sa-1", Namespace: "ns-1"},
+				{Kind: serviceAccountKind, Name: "sa-4", Namespace: "ns-1"},
+			},
+			Rules: []smiAccess.TrafficTargetRule{{Kind: httpRouteGroupKind, Name: "missing-routes", Matches: []string{"all"}}},
+		},
+	}
+	otherTrafficTarget := &smiAccess.TrafficTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "ns-2"},
+		Spec: smiAccess.TrafficTargetSpec{
+			Destination: smiAccess.IdentityBindingSubject{Kind: serviceAccountKind, Name: "sa-5", Namespace: "ns-2"},
+			Sources:     []smiAccess.IdentityBindingSubject{{Kind: serviceAccountKind, Name: "sa-1", Namespace: "ns-1"}},
+		},
+	}
+
+	testCases := []struct {
+		name               string
+		trace              bool
+		permissiveMode     string
+		expectedAllowed    bool
+		expectedOutSubstrs []string
+	}{
+		{
+			name:            "evaluation steps are traced in SMI traffic policy mode",
+			trace:           true,
+			permissiveMode:  "false",
+			expectedAllowed: true,
+			expectedOutSubstrs: []string{
+				fmt.Sprintf("[trace] Permissive traffic policy mode of mesh config '%s/%s': false", settings.Namespace(), osmConfigMapName),
+				"[trace] Listed 2 SMI TrafficTarget policies in namespace ns-2",
+				"[trace] SMI TrafficTarget policy 'ns-2/allowing': destination ServiceAccount 'ns-2/sa-2' matches",
+				"[trace]   source 1 of 3 ServiceAccount 'ns-1/sa-3': does not match 'ns-1/sa-1'",
+				"[trace]   source 2 of 3 ServiceAccount 'ns-1/sa-1': matches",
+				"[trace]   source 3 of 3 ServiceAccount 'ns-1/sa-4': not evaluated, a previous source matches",
+				"[trace] SMI TrafficTarget policy 'ns-2/other': destination ServiceAccount 'ns-2/sa-5' does not match 'ns-2/sa-2', skipped",
+				"[trace] 1 SMI TrafficTarget policies allow pod 'ns-1/pod-1' to communicate to pod 'ns-2/pod-2'",
+				"[trace] Route HTTPRouteGroup/missing-routes of SMI TrafficTarget policy 'ns-2/allowing': not found in namespace ns-2",
+			},
+		},
+		{
+			name:            "permissive mode is traced",
+			trace:           true,
+			permissiveMode:  "true",
+			expectedAllowed: true,
+			expectedOutSubstrs: []string{
+				fmt.Sprintf("[trace] Permissive traffic policy mode of mesh config '%s/%s': true", settings.Namespace(), osmConfigMapName),
+			},
+		},
+		{
+			name:               "nothing is traced without --trace",
+			trace:              false,
+			permissiveMode:     "false",
+			expectedAllowed:    true,
+			expectedOutSubstrs: nil,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			out := new(bytes.Buffer)
+			cmd := trafficPolicyCheckCmd{
+				out: out,
+				clientSet: fake.NewSimpleClientset(&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: settings.Namespace(), Name: osmConfigMapName},
+					Data:       map[string]string{configurator.PermissiveTrafficPolicyModeKey: tc.permissiveMode},
+				}),
+				smiAccessClient: fakeAccessClient.NewSimpleClientset(allowingTrafficTarget, otherTrafficTarget),
+				smiSpecClient:   fakeSpecClient.NewSimpleClientset(),
+				smiSplitClient:  fakeSplitClient.NewSimpleClientset(),
+				meshConfigName:  osmConfigMapName,
+				trace:           tc.trace,
+			}
+
+			allowed, err := cmd.checkTrafficPolicy(srcPod, dstPod)
+			assert.Nil(err)
+			assert.Equal(tc.expectedAllowed, allowed)
+			for _, substr := range tc.expectedOutSubstrs {
+				assert.Contains(out.String(), substr)
+			}
+			if !tc.trace {
+				assert.NotContains(out.String(), "[trace]")
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+const (
+	// checkExitCodeWaitTimeout is the exit code when the source pod is still not allowed to communicate to the
+	// destination once the duration given with --wait has elapsed
+	checkExitCodeWaitTimeout = 5
+
+	// waitPollInterval is the interval at which the check is re-run with --wait
+	waitPollInterval = 2 * time.Second
+)
+
+// waitForAllowedTraffic re-runs the given traffic policy check every poll interval until the source pod is allowed to
+// communicate to the destination, or the duration given with --wait elapses. The resources listed by a check are
+// discarded before the next one so that newly applied policies are observed. Only the output of the last check is
+// printed, along with the number of checks run.
+func (cmd *trafficPolicyCheckCmd) waitForAllowedTraffic(check func() (bool, error)) error {
+	fmt.Fprintf(cmd.out, "Waiting up to %s for pod %s to be allowed to communicate to %s\n\n", cmd.wait, cmd.sourcePod, cmd.destinationPod)
+
+	out := cmd.out
+	defer func() { cmd.out = out }()
+
+	var lastOutput *bytes.Buffer
+	attempts := 0
+	err := wait.PollImmediate(cmd.pollInterval, cmd.wait, func() (bool, error) {
+		attempts++
+		if attempts > 1 {
+			cmd.resetListCache()
+		}
+		lastOutput = new(bytes.Buffer)
+		cmd.out = lastOutput
+
+		allowed, err := check()
+		if err != nil {
+			return false, withExitCode(checkExitCodeAPIError, err)
+		}
+		return allowed, nil
+	})
+	if lastOutput != nil {
+		_, _ = io.Copy(out, lastOutput)
+	}
+
+	if err == wait.ErrWaitTimeout {
+		return withExitCode(checkExitCodeWaitTimeout, errors.Errorf("Pod %s is still not allowed to communicate to %s after waiting %s (%d check(s))",
+			cmd.sourcePod, cmd.destinationPod, cmd.wait, attempts))
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "\n[+] Pod %s is allowed to communicate to %s after %d check(s)\n", cmd.sourcePod, cmd.destinationPod, attempts)
+	return nil
+}
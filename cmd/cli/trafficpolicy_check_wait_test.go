@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+	"time"
+
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	fakeAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned/fake"
+	fakeSplitClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/split/clientset/versioned/fake"
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+)
+
+func TestCheckTrafficPolicyWait(t *testing.T) {
+	trafficTarget := &smiAccess.TrafficTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1", Namespace: "ns-2"},
+		Spec: smiAccess.TrafficTargetSpec{
+			Destination: smiAccess.IdentityBindingSubject{Kind: serviceAccountKind, Name: "sa-2", Namespace: "ns-2"},
+			Sources:     []smiAccess.IdentityBindingSubject{{Kind: serviceAccountKind, Name: "sa-1", Namespace: "ns-1"}},
+		},
+	}
+
+	testCases := []struct {
+		name             string
+		appliedAfter     int
+		expectedChecks   int
+		expectedErr      string
+		expectedExitCode int
+	}{
+		{
+			name:           "traffic allowed on the first check",
+			appliedAfter:   0,
+			expectedChecks: 1,
+		},
+		{
+			name:           "traffic allowed once the TrafficTarget is applied",
+			appliedAfter:   2,
+			expectedChecks: 3,
+		},
+		{
+			name:             "traffic still denied when the wait elapses",
+			appliedAfter:     -1,
+			expectedErr:      "Pod ns-1/pod-1 is still not allowed to communicate to ns-2/pod-2 after waiting 50ms",
+			expectedExitCode: checkExitCodeWaitTimeout,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			// The TrafficTarget is listed once it has been listed tc.appliedAfter times without it, never if negative
+			accessClient := fakeAccessClient.NewSimpleClientset(trafficTarget)
+			checks := 0
+			accessClient.PrependReactor("list", "traffictargets", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				checks++
+				if tc.appliedAfter < 0 || checks <= tc.appliedAfter {
+					return true, &smiAccess.TrafficTargetList{}, nil
+				}
+				return false, nil, nil
+			})
+
+			out := new(bytes.Buffer)
+			cmd := trafficPolicyCheckCmd{
+				out:            out,
+				sourcePod:      "ns-1/pod-1",
+				destinationPod: "ns-2/pod-2",
+				wait:           50 * time.Millisecond,
+				pollInterval:   time.Millisecond,
+				clientSet: fake.NewSimpleClientset(
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-1"}},
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-2"}},
+					newTestPod("ns-1", "pod-1", "sa-1", true),
+					newTestPod("ns-2", "pod-2", "sa-2", true),
+					&corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{Namespace: settings.Namespace(), Name: osmConfigMapName},
+						Data:       map[string]string{configurator.PermissiveTrafficPolicyModeKey: "false"},
+					},
+				),
+				smiAccessClient: accessClient,
+				smiSplitClient:  fakeSplitClient.NewSimpleClientset(),
+				meshConfigName:  osmConfigMapName,
+				skipRBACCheck:   true,
+			}
+
+			err := cmd.run()
+			if tc.expectedErr != "" {
+				assert.NotNil(err)
+				assert.Contains(err.Error(), tc.expectedErr)
+				assert.Equal(tc.expectedExitCode, getExitCode(err))
+				assert.Contains(out.String(), "[+] Pod 'ns-1/pod-1' is not allowed to communicate to pod 'ns-2/pod-2'")
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(tc.expectedChecks, checks)
+			assert.Contains(out.String(), fmt.Sprintf("[+] Pod ns-1/pod-1 is allowed to communicate to ns-2/pod-2 after %d check(s)", tc.expectedChecks))
+		})
+	}
+}
@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/pkg/errors"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// clearScreen is the ANSI escape sequence moving the cursor to the top left corner and clearing the terminal
+const clearScreen = "\033[H\033[2J"
+
+const (
+	// maxWatchRetries is the number of consecutive failures to watch SMI TrafficTarget policies after which the watch
+	// gives up
+	maxWatchRetries = 5
+
+	// maxWatchRetryDelay is the maximum time to wait before retrying to watch SMI TrafficTarget policies after a
+	// failure, the delay doubling with each consecutive failure
+	maxWatchRetryDelay = 30 * time.Second
+)
+
+// watchRetryInitialDelay is the time to wait before retrying to watch SMI TrafficTarget policies after a first failure
+var watchRetryInitialDelay = time.Second
+
+// watchOutcome is the reason why the events of a watch stopped being handled
+type watchOutcome int
+
+const (
+	// watchClosed is the outcome of a watch closed by the API server, e.g. on timeout, which is resumed from the last
+	// observed resource version
+	watchClosed watchOutcome = iota
+
+	// watchExpired is the outcome of a watch whose resource version is too old, which requires a re-list
+	watchExpired
+
+	// watchFailed is the outcome of a watch interrupted by an error, which is retried after a delay
+	watchFailed
+
+	// watchInterrupted is the outcome of a watch interrupted by SIGINT, or by an error of the check, which ends the
+	// command
+	watchInterrupted
+)
+
+// watchTrafficPolicy runs the given traffic policy check and re-runs it every time an SMI TrafficTarget in the given
+// namespace, or in any namespace when empty, changes, until SIGINT is received. The watch is resumed when the API
+// server closes it, and the TrafficTargets are re-listed when the resource version of the watch has expired, in which
+// case the check is re-run since changes may have been missed. Only the resource version of the TrafficTargets is
+// kept between events, so that memory does not grow with the number of policies in the cluster.
+func (cmd *trafficPolicyCheckCmd) watchTrafficPolicy(namespace string, check func() (bool, error)) error {
+	signal.Notify(cmd.sigintChan, os.Interrupt)
+	defer signal.Stop(cmd.sigintChan)
+
+	// Start watching from the current state so that existing TrafficTargets are not reported as changes
+	resourceVersion, err := cmd.getTrafficTargetsResourceVersion(namespace)
+	if err != nil {
+		return err
+	}
+
+	if _, err := check(); err != nil {
+		return err
+	}
+
+	failures := 0
+	for {
+		var outcome watchOutcome
+		var watchErr error
+
+		watcher, err := cmd.smiAccessClient.AccessV1alpha3().TrafficTargets(namespace).Watch(context.TODO(), metav1.ListOptions{
+			ResourceVersion:     resourceVersion,
+			AllowWatchBookmarks: true,
+		})
+		switch {
+		case isResourceVersionExpired(err):
+			outcome = watchExpired
+		case err != nil:
+			outcome, watchErr = watchFailed, err
+		default:
+			failures = 0
+			resourceVersion, outcome, watchErr = cmd.handleTrafficTargetEvents(watcher, resourceVersion, check)
+			watcher.Stop()
+		}
+
+		switch outcome {
+		case watchInterrupted:
+			return watchErr
+		case watchExpired:
+			// Events were missed, re-list to resume watching from the current state, and re-run the check on it
+			if resourceVersion, err = cmd.getTrafficTargetsResourceVersion(namespace); err != nil {
+				return err
+			}
+			if err := cmd.recheck(check); err != nil {
+				return err
+			}
+		case watchFailed:
+			failures++
+			if failures > maxWatchRetries {
+				return errors.Errorf("Error watching SMI TrafficTarget policies in %s, giving up after %d retries: %s", describeNamespace(namespace), maxWatchRetries, watchErr)
+			}
+			if interrupted := cmd.waitWatchRetry(failures); interrupted {
+				return nil
+			}
+		}
+	}
+}
+
+// handleTrafficTargetEvents re-runs the given check for every change to the SMI TrafficTargets received from the
+// given watcher, until the watch stops. It returns the last resource version observed along with why the watch
+// stopped, and the error of the check or of the watch if any.
+func (cmd *trafficPolicyCheckCmd) handleTrafficTargetEvents(watcher watch.Interface, resourceVersion string, check func() (bool, error)) (string, watchOutcome, error) {
+	for {
+		select {
+		case <-cmd.sigintChan:
+			return resourceVersion, watchInterrupted, nil
+
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return resourceVersion, watchClosed, nil
+			}
+
+			switch event.Type {
+			case watch.Added, watch.Modified, watch.Deleted:
+				resourceVersion = getResourceVersion(event.Object, resourceVersion)
+				if err := cmd.recheck(check); err != nil {
+					return resourceVersion, watchInterrupted, err
+				}
+			case watch.Bookmark:
+				resourceVersion = getResourceVersion(event.Object, resourceVersion)
+			case watch.Error:
+				err := apierrors.FromObject(event.Object)
+				if isResourceVersionExpired(err) {
+					return resourceVersion, watchExpired, nil
+				}
+				return resourceVersion, watchFailed, err
+			}
+		}
+	}
+}
+
+// recheck clears the screen and re-runs the given check on the current state of the cluster
+func (cmd *trafficPolicyCheckCmd) recheck(check func() (bool, error)) error {
+	fmt.Fprint(cmd.out, clearScreen)
+	// Each re-run of the check reads the current state of the cluster
+	cmd.resetListCache()
+	_, err := check()
+	return err
+}
+
+// getTrafficTargetsResourceVersion returns the current resource version of the SMI TrafficTargets in the given
+// namespace, or in any namespace when empty. A single TrafficTarget is requested, since only the resource version
+// of the list is needed to start watching.
+func (cmd *trafficPolicyCheckCmd) getTrafficTargetsResourceVersion(namespace string) (string, error) {
+	trafficTargets, err := cmd.smiAccessClient.AccessV1alpha3().TrafficTargets(namespace).List(context.TODO(), metav1.ListOptions{Limit: 1})
+	if err != nil {
+		return "", errors.Errorf("Error listing SMI TrafficTarget policies in %s: %s", describeNamespace(namespace), err)
+	}
+	return trafficTargets.ResourceVersion, nil
+}
+
+// waitWatchRetry waits before retrying to watch after the given number of consecutive failures, and returns whether
+// SIGINT was received in the meantime
+func (cmd *trafficPolicyCheckCmd) waitWatchRetry(failures int) bool {
+	delay := watchRetryInitialDelay << (failures - 1)
+	if delay > maxWatchRetryDelay {
+		delay = maxWatchRetryDelay
+	}
+	select {
+	case <-cmd.sigintChan:
+		return true
+	case <-time.After(delay):
+		return false
+	}
+}
+
+// isResourceVersionExpired returns whether the given error is returned by the API server for a watch whose resource
+// version is too old
+func isResourceVersionExpired(err error) bool {
+	return err != nil && (apierrors.IsResourceExpired(err) || apierrors.IsGone(err))
+}
+
+// getResourceVersion returns the resource version of the given object received from a watch, or the given resource
+// version if the object has none
+func getResourceVersion(obj runtime.Object, resourceVersion string) string {
+	accessor, err := meta.Accessor(obj)
+	if err != nil || accessor.GetResourceVersion() == "" {
+		return resourceVersion
+	}
+	return accessor.GetResourceVersion()
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	fakeAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned/fake"
+	fakeSplitClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/split/clientset/versioned/fake"
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+)
+
+// syncBuffer is a bytes.Buffer safe for concurrent use
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestWatchTrafficPolicy(t *testing.T) {
+	assert := tassert.New(t)
+
+	srcPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "ns-1"},
+		Spec:       corev1.PodSpec{ServiceAccountName: "sa-1"},
+	}
+	dstPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-2", Namespace: "ns-2"},
+		Spec:       corev1.PodSpec{ServiceAccountName: "sa-2"},
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: settings.Namespace(),
+			Name:      osmConfigMapName,
+		},
+		Data: map[string]string{
+			configurator.PermissiveTrafficPolicyModeKey: "false",
+		},
+	}
+
+	accessClient := fakeAccessClient.NewSimpleClientset()
+	out := &syncBuffer{}
+	cmd := trafficPolicyCheckCmd{
+		out:             out,
+		clientSet:       fake.NewSimpleClientset(configMap),
+		smiAccessClient: accessClient,
+		smiSplitClient:  fakeSplitClient.NewSimpleClientset(),
+		sigintChan:      make(chan os.Signal, 1),
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- cmd.watchTrafficPolicy(dstPod.Namespace, func() (bool, error) { return cmd.checkTrafficPolicy(srcPod, dstPod) })
+	}()
+
+	assert.Eventually(func() bool {
+		return strings.Contains(out.String(), "is not allowed to communicate")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	trafficTarget := &smiAccess.TrafficTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1", Namespace: "ns-2"},
+		Spec: smiAccess.TrafficTargetSpec{
+			Destination: smiAccess.IdentityBindingSubject{Kind: "ServiceAccount", Name: "sa-2", Namespace: "ns-2"},
+			Sources:     []smiAccess.IdentityBindingSubject{{Kind: "ServiceAccount", Name: "sa-1", Namespace: "ns-1"}},
+		},
+	}
+	_, err := accessClient.AccessV1alpha3().TrafficTargets("ns-2").Create(context.TODO(), trafficTarget, metav1.CreateOptions{})
+	assert.Nil(err)
+
+	// The check is re-run after clearing the screen when the TrafficTarget is added
+	assert.Eventually(func() bool {
+		return strings.Contains(out.String(), clearScreen+"[+] SMI traffic policy mode enabled") &&
+			strings.Contains(out.String(), "is allowed to communicate")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cmd.sigintChan <- os.Interrupt
+	select {
+	case err := <-done:
+		assert.Nil(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch did not exit on SIGINT")
+	}
+}
+
+// newWatchTestCmd returns a traffic policy check command for the given access client, denying the traffic of
+// pod-1 in ns-1 to pod-2 in ns-2 until a TrafficTarget allows it, along with the check to watch
+func newWatchTestCmd(accessClient *fakeAccessClient.Clientset, out *syncBuffer) (*trafficPolicyCheckCmd, func() (bool, error)) {
+	srcPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-1", Namespace: "ns-1"},
+		Spec:       corev1.PodSpec{ServiceAccountName: "sa-1"},
+	}
+	dstPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-2", Namespace: "ns-2"},
+		Spec:       corev1.PodSpec{ServiceAccountName: "sa-2"},
+	}
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: settings.Namespace(),
+			Name:      osmConfigMapName,
+		},
+		Data: map[string]string{
+			configurator.PermissiveTrafficPolicyModeKey: "false",
+		},
+	}
+
+	cmd := &trafficPolicyCheckCmd{
+		out:             out,
+		clientSet:       fake.NewSimpleClientset(configMap),
+		smiAccessClient: accessClient,
+		smiSplitClient:  fakeSplitClient.NewSimpleClientset(),
+		sigintChan:      make(chan os.Signal, 1),
+	}
+	return cmd, func() (bool, error) { return cmd.checkTrafficPolicy(srcPod, dstPod) }
+}
+
+func TestWatchTrafficPolicyResourceVersionExpired(t *testing.T) {
+	assert := tassert.New(t)
+
+	accessClient := fakeAccessClient.NewSimpleClientset()
+
+	// The first watch fails with 410 Gone, the next ones watch the TrafficTargets of the fake clientset
+	var watches int32
+	accessClient.PrependWatchReactor("traffictargets", func(k8stesting.Action) (bool, watch.Interface, error) {
+		if atomic.AddInt32(&watches, 1) > 1 {
+			return false, nil, nil
+		}
+		watcher := watch.NewFakeWithChanSize(1, false)
+		watcher.Error(&metav1.Status{
+			Status:  metav1.StatusFailure,
+			Code:    410,
+			Reason:  metav1.StatusReasonExpired,
+			Message: "too old resource version",
+		})
+		return true, watcher, nil
+	})
+
+	out := &syncBuffer{}
+	cmd, check := newWatchTestCmd(accessClient, out)
+
+	done := make(chan error)
+	go func() {
+		done <- cmd.watchTrafficPolicy("ns-2", check)
+	}()
+
+	// The TrafficTargets are re-listed and the check is re-run, since changes may have been missed
+	assert.Eventually(func() bool {
+		return strings.Contains(out.String(), clearScreen+"[+] SMI traffic policy mode enabled")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The watch is resumed after the re-list
+	trafficTarget := &smiAccess.TrafficTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-1", Namespace: "ns-2"},
+		Spec: smiAccess.TrafficTargetSpec{
+			Destination: smiAccess.IdentityBindingSubject{Kind: "ServiceAccount", Name: "sa-2", Namespace: "ns-2"},
+			Sources:     []smiAccess.IdentityBindingSubject{{Kind: "ServiceAccount", Name: "sa-1", Namespace: "ns-1"}},
+		},
+	}
+	assert.Eventually(func() bool { return atomic.LoadInt32(&watches) > 1 }, 5*time.Second, 10*time.Millisecond)
+	_, err := accessClient.AccessV1alpha3().TrafficTargets("ns-2").Create(context.TODO(), trafficTarget, metav1.CreateOptions{})
+	assert.Nil(err)
+
+	assert.Eventually(func() bool {
+		return strings.Contains(out.String(), "is allowed to communicate")
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cmd.sigintChan <- os.Interrupt
+	select {
+	case err := <-done:
+		assert.Nil(err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch did not exit on SIGINT")
+	}
+}
+
+func TestWatchTrafficPolicyGivesUp(t *testing.T) {
+	assert := tassert.New(t)
+
+	initialDelay := watchRetryInitialDelay
+	watchRetryInitialDelay = time.Millisecond
+	defer func() { watchRetryInitialDelay = initialDelay }()
+
+	accessClient := fakeAccessClient.NewSimpleClientset()
+	watches := 0
+	accessClient.PrependWatchReactor("traffictargets", func(k8stesting.Action) (bool, watch.Interface, error) {
+		watches++
+		return true, nil, errors.New("connection refused")
+	})
+
+	cmd, check := newWatchTestCmd(accessClient, &syncBuffer{})
+	err := cmd.watchTrafficPolicy("ns-2", check)
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "giving up after 5 retries: connection refused")
+	assert.Equal(maxWatchRetries+1, watches)
+}
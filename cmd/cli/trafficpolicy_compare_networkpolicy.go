@@ -0,0 +1,465 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/openservicemesh/osm/pkg/cli"
+	"github.com/openservicemesh/osm/pkg/identity"
+)
+
+const trafficPolicyCompareNetworkPolicyDescription = `
+This command compares the ingress and egress rules of the Kubernetes
+NetworkPolicies defined in a file with the SMI TrafficTarget policies applied
+in the cluster, to find the gaps and over-permissions of the SMI policies when
+migrating from NetworkPolicies to SMI.
+
+NetworkPolicies select pods, while SMI TrafficTarget policies select service
+accounts. The pods selected by each NetworkPolicy and by the peers of its rules
+are looked up in the cluster and resolved to their service accounts, and the
+traffic between these service accounts is compared:
+  - the ingress rules are compared for the traffic from the service accounts
+    of the peers to the service accounts of the selected pods
+  - the egress rules are compared for the traffic from the service accounts of
+    the selected pods to the service accounts of the peers
+A rule without peers allows the traffic from or to every service account. The
+peers given as IP blocks can't be resolved to service accounts, they are listed
+and left out of the comparison. The ports of the rules are not compared.
+
+For each pair of service accounts allowed by the NetworkPolicy or by the SMI
+policies, a table lists whether each allows the traffic, and whether the SMI
+policies are more or less permissive than the NetworkPolicy. When the mesh
+operates in permissive traffic policy mode, the SMI policies allow every pair.
+`
+
+const trafficPolicyCompareNetworkPolicyExample = `
+# Compare the NetworkPolicies defined in np.yaml with the SMI TrafficTarget policies applied in the cluster
+osm policy compare-networkpolicy -f np.yaml
+
+# Compare the NetworkPolicies of the 'bookstore' namespace read from stdin
+kubectl get networkpolicies -n bookstore -o yaml | osm policy compare-networkpolicy -f -
+`
+
+const (
+	networkPolicyKind = "NetworkPolicy"
+
+	// networkPolicyDirectionIngress and networkPolicyDirectionEgress are the directions of the compared traffic
+	networkPolicyDirectionIngress = "ingress"
+	networkPolicyDirectionEgress  = "egress"
+)
+
+type trafficPolicyCompareNetworkPolicyCmd struct {
+	out             io.Writer
+	in              io.Reader
+	filename        string
+	meshName        string
+	meshConfigName  string
+	clientSet       kubernetes.Interface
+	smiAccessClient smiAccessClient.Interface
+}
+
+// networkPolicyComparison is the comparison of the traffic allowed between two service accounts by a NetworkPolicy
+// and by the SMI policies
+type networkPolicyComparison struct {
+	direction            string
+	source               identity.K8sServiceAccount
+	destination          identity.K8sServiceAccount
+	networkPolicyAllowed bool
+	smiAllowed           bool
+}
+
+// describe returns whether the SMI policies are as permissive as the NetworkPolicy for the compared traffic
+func (c networkPolicyComparison) describe() string {
+	switch {
+	case c.smiAllowed == c.networkPolicyAllowed:
+		return "same"
+	case c.smiAllowed:
+		return "SMI more permissive"
+	default:
+		return "SMI less permissive"
+	}
+}
+
+// networkPolicyPeers are the service accounts resolved from the peers of the rules of a NetworkPolicy
+type networkPolicyPeers struct {
+	// all is true when a rule allows the traffic from or to every service account
+	all             bool
+	serviceAccounts map[identity.K8sServiceAccount]bool
+	// ipBlocks are the CIDRs of the peers given as IP blocks, which can't be resolved to service accounts
+	ipBlocks []string
+}
+
+func (p networkPolicyPeers) allows(serviceAccount identity.K8sServiceAccount) bool {
+	return p.all || p.serviceAccounts[serviceAccount]
+}
+
+func newTrafficPolicyCompareNetworkPolicyCmd(in io.Reader, out io.Writer) *cobra.Command {
+	compareCmd := &trafficPolicyCompareNetworkPolicyCmd{
+		in:  in,
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "compare-networkpolicy -f FILENAME",
+		Short: "compare NetworkPolicies in a file with the SMI policies of the cluster",
+		Long:  trafficPolicyCompareNetworkPolicyDescription,
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if compareCmd.filename == "" {
+				return errors.New("flag --filename is required")
+			}
+
+			clients, err := cli.NewClients(settings)
+			if err != nil {
+				return err
+			}
+			compareCmd.clientSet = clients.KubeClient
+			compareCmd.smiAccessClient = clients.SMIAccessClient
+
+			return compareCmd.run()
+		},
+		Example: trafficPolicyCompareNetworkPolicyExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&compareCmd.filename, "filename", "f", "", "File containing the NetworkPolicies to compare, or - to read them from stdin")
+	f.StringVar(&compareCmd.meshName, "mesh-name", "", "Name of the mesh whose configuration is checked, the mesh running in the namespace given with --osm-namespace if unset")
+	f.StringVar(&compareCmd.meshConfigName, "mesh-config-name", osmConfigMapName, "Name of the ConfigMap holding the configuration of the mesh")
+
+	return cmd
+}
+
+func (cmd *trafficPolicyCompareNetworkPolicyCmd) run() error {
+	in := cmd.in
+	if cmd.filename != stdinFileName {
+		fd, err := os.Open(cmd.filename)
+		if err != nil {
+			return errors.Errorf("Error opening file %s: %s", cmd.filename, err)
+		}
+		defer fd.Close() //nolint: errcheck, gosec
+		in = fd
+	}
+
+	networkPolicies, err := readNetworkPolicies(in)
+	if err != nil {
+		return err
+	}
+
+	// The lookups of the mesh and of the policies are shared with 'osm policy check-pods'
+	checkCmd := &trafficPolicyCheckCmd{
+		out:             cmd.out,
+		meshName:        cmd.meshName,
+		meshConfigName:  cmd.meshConfigName,
+		clientSet:       cmd.clientSet,
+		smiAccessClient: cmd.smiAccessClient,
+		listCache:       newListCache(),
+	}
+	permissiveMode, err := checkCmd.isPermissiveModeEnabled()
+	if err != nil {
+		return errors.Errorf("Error checking if permissive mode is enabled: %s", err)
+	}
+	if permissiveMode {
+		fmt.Fprintln(cmd.out, "[+] Permissive mode enabled, the SMI policies allow every pair of service accounts")
+	}
+
+	pods, err := cmd.clientSet.CoreV1().Pods(metav1.NamespaceAll).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return errors.Errorf("Error listing pods: %s", err)
+	}
+	namespaces, err := cmd.clientSet.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return errors.Errorf("Error listing namespaces: %s", err)
+	}
+	trafficTargets, err := checkCmd.listTrafficTargets(metav1.NamespaceAll)
+	if err != nil {
+		return err
+	}
+
+	// The pairs of service accounts are evaluated against the index of the TrafficTargets rather than scanning them all
+	index := newTrafficPolicyIndex(trafficTargets, nil)
+
+	var morePermissive, lessPermissive int
+	for _, networkPolicy := range networkPolicies {
+		comparisons, err := cmd.compareNetworkPolicy(networkPolicy, pods.Items, namespaces.Items, trafficTargets, index, permissiveMode)
+		if err != nil {
+			return err
+		}
+		for _, comparison := range comparisons {
+			if comparison.smiAllowed && !comparison.networkPolicyAllowed {
+				morePermissive++
+			} else if !comparison.smiAllowed && comparison.networkPolicyAllowed {
+				lessPermissive++
+			}
+		}
+	}
+
+	fmt.Fprintf(cmd.out, "[+] The SMI policies are more permissive for %d pair(s) of service accounts, and less permissive for %d pair(s)\n",
+		morePermissive, lessPermissive)
+	return nil
+}
+
+// compareNetworkPolicy prints the comparison of the traffic allowed by the given NetworkPolicy with the traffic allowed
+// by the SMI policies, and returns the comparisons
+func (cmd *trafficPolicyCompareNetworkPolicyCmd) compareNetworkPolicy(networkPolicy networkingv1.NetworkPolicy, pods []corev1.Pod,
+	namespaces []corev1.Namespace, trafficTargets []smiAccess.TrafficTarget, index *trafficPolicyIndex, permissiveMode bool) ([]networkPolicyComparison, error) {
+	name := networkPolicy.Namespace + namespaceSeparator + networkPolicy.Name
+	selected, err := selectServiceAccounts(pods, []corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: networkPolicy.Namespace}}}, nil, &networkPolicy.Spec.PodSelector)
+	if err != nil {
+		return nil, errors.Errorf("Invalid pod selector of NetworkPolicy %s: %s", name, err)
+	}
+	if len(selected) == 0 {
+		fmt.Fprintf(cmd.out, "\n[+] NetworkPolicy %s selects no pods, nothing to compare\n", name)
+		return nil, nil
+	}
+	selectedServiceAccounts := sortServiceAccounts(selected)
+	fmt.Fprintf(cmd.out, "\n[+] NetworkPolicy %s selects the pods of service account(s): %s\n", name, joinServiceAccounts(selectedServiceAccounts))
+
+	// Every service account running a pod or named by an SMI policy is a candidate peer
+	candidates := make(map[identity.K8sServiceAccount]bool)
+	for i := range pods {
+		candidates[getPodServiceAccount(&pods[i])] = true
+	}
+	for _, trafficTarget := range trafficTargets {
+		candidates[identity.K8sServiceAccount{Namespace: trafficTarget.Spec.Destination.Namespace, Name: trafficTarget.Spec.Destination.Name}] = true
+		for _, source := range trafficTarget.Spec.Sources {
+			candidates[identity.K8sServiceAccount{Namespace: source.Namespace, Name: source.Name}] = true
+		}
+	}
+	candidateServiceAccounts := sortServiceAccounts(candidates)
+
+	var comparisons []networkPolicyComparison
+	var ipBlocks []string
+	for _, direction := range getNetworkPolicyDirections(networkPolicy) {
+		peers, err := getNetworkPolicyPeers(networkPolicy, direction, pods, namespaces)
+		if err != nil {
+			return nil, errors.Errorf("Invalid %s rule of NetworkPolicy %s: %s", direction, name, err)
+		}
+		ipBlocks = append(ipBlocks, peers.ipBlocks...)
+
+		for _, selectedServiceAccount := range selectedServiceAccounts {
+			for _, peer := range candidateServiceAccounts {
+				comparison := networkPolicyComparison{
+					direction:            direction,
+					source:               peer,
+					destination:          selectedServiceAccount,
+					networkPolicyAllowed: peers.allows(peer),
+				}
+				if direction == networkPolicyDirectionEgress {
+					comparison.source, comparison.destination = selectedServiceAccount, peer
+				}
+				comparison.smiAllowed = permissiveMode || index.allows(comparison.source, comparison.destination)
+				if comparison.networkPolicyAllowed || comparison.smiAllowed {
+					comparisons = append(comparisons, comparison)
+				}
+			}
+		}
+	}
+
+	for _, ipBlock := range ipBlocks {
+		fmt.Fprintf(cmd.out, "[!] Peer with IP block %s can't be resolved to service accounts, it is not compared\n", ipBlock)
+	}
+	if len(comparisons) == 0 {
+		fmt.Fprintln(cmd.out, "[+] Neither the NetworkPolicy nor the SMI policies allow traffic for the selected pods")
+		return nil, nil
+	}
+
+	w := newTabWriter(cmd.out)
+	fmt.Fprintln(w, "DIRECTION\tSOURCE\tDESTINATION\tNETWORKPOLICY\tSMI\tCOMPARISON\t")
+	for _, comparison := range comparisons {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t\n", comparison.direction, comparison.source, comparison.destination,
+			describeAllowed(comparison.networkPolicyAllowed), describeAllowed(comparison.smiAllowed), comparison.describe())
+	}
+	return comparisons, w.Flush()
+}
+
+// getNetworkPolicyDirections returns the directions of the traffic the given NetworkPolicy applies to, following the
+// defaults of Kubernetes when its policy types are not set: ingress always, and egress when it has egress rules
+func getNetworkPolicyDirections(networkPolicy networkingv1.NetworkPolicy) []string {
+	if len(networkPolicy.Spec.PolicyTypes) == 0 {
+		directions := []string{networkPolicyDirectionIngress}
+		if len(networkPolicy.Spec.Egress) > 0 {
+			directions = append(directions, networkPolicyDirectionEgress)
+		}
+		return directions
+	}
+
+	var directions []string
+	for _, policyType := range networkPolicy.Spec.PolicyTypes {
+		switch policyType {
+		case networkingv1.PolicyTypeIngress:
+			directions = append(directions, networkPolicyDirectionIngress)
+		case networkingv1.PolicyTypeEgress:
+			directions = append(directions, networkPolicyDirectionEgress)
+		}
+	}
+	return directions
+}
+
+// getNetworkPolicyPeers returns the service accounts allowed by the rules of the given NetworkPolicy in the given
+// direction. A NetworkPolicy without rules in a direction it applies to denies all the traffic in that direction.
+func getNetworkPolicyPeers(networkPolicy networkingv1.NetworkPolicy, direction string, pods []corev1.Pod, namespaces []corev1.Namespace) (networkPolicyPeers, error) {
+	var rules [][]networkingv1.NetworkPolicyPeer
+	if direction == networkPolicyDirectionIngress {
+		for _, rule := range networkPolicy.Spec.Ingress {
+			rules = append(rules, rule.From)
+		}
+	} else {
+		for _, rule := range networkPolicy.Spec.Egress {
+			rules = append(rules, rule.To)
+		}
+	}
+
+	peers := networkPolicyPeers{serviceAccounts: make(map[identity.K8sServiceAccount]bool)}
+	for _, rulePeers := range rules {
+		if len(rulePeers) == 0 {
+			peers.all = true
+			continue
+		}
+		for _, peer := range rulePeers {
+			if peer.IPBlock != nil {
+				peers.ipBlocks = append(peers.ipBlocks, peer.IPBlock.CIDR)
+				continue
+			}
+
+			// A peer without namespace selector selects pods in the namespace of the NetworkPolicy
+			peerNamespaces := []corev1.Namespace{{ObjectMeta: metav1.ObjectMeta{Name: networkPolicy.Namespace}}}
+			if peer.NamespaceSelector != nil {
+				peerNamespaces = namespaces
+			}
+			serviceAccounts, err := selectServiceAccounts(pods, peerNamespaces, peer.NamespaceSelector, peer.PodSelector)
+			if err != nil {
+				return networkPolicyPeers{}, err
+			}
+			for serviceAccount := range serviceAccounts {
+				peers.serviceAccounts[serviceAccount] = true
+			}
+		}
+	}
+	return peers, nil
+}
+
+// selectServiceAccounts returns the service accounts of the pods matching the given pod selector, in the given
+// namespaces matching the given namespace selector. A nil selector selects every pod, or every namespace.
+func selectServiceAccounts(pods []corev1.Pod, namespaces []corev1.Namespace, namespaceSelector, podSelector *metav1.LabelSelector) (map[identity.K8sServiceAccount]bool, error) {
+	namespaceLabelSelector, podLabelSelector := labels.Everything(), labels.Everything()
+	var err error
+	if namespaceSelector != nil {
+		if namespaceLabelSelector, err = metav1.LabelSelectorAsSelector(namespaceSelector); err != nil {
+			return nil, err
+		}
+	}
+	if podSelector != nil {
+		if podLabelSelector, err = metav1.LabelSelectorAsSelector(podSelector); err != nil {
+			return nil, err
+		}
+	}
+
+	selectedNamespaces := make(map[string]bool)
+	for _, namespace := range namespaces {
+		if namespaceLabelSelector.Matches(labels.Set(namespace.Labels)) {
+			selectedNamespaces[namespace.Name] = true
+		}
+	}
+
+	serviceAccounts := make(map[identity.K8sServiceAccount]bool)
+	for i := range pods {
+		pod := &pods[i]
+		if selectedNamespaces[pod.Namespace] && podLabelSelector.Matches(labels.Set(pod.Labels)) {
+			serviceAccounts[getPodServiceAccount(pod)] = true
+		}
+	}
+	return serviceAccounts, nil
+}
+
+// sortServiceAccounts returns the given service accounts sorted by namespace and name
+func sortServiceAccounts(serviceAccounts map[identity.K8sServiceAccount]bool) []identity.K8sServiceAccount {
+	sorted := make([]identity.K8sServiceAccount, 0, len(serviceAccounts))
+	for serviceAccount := range serviceAccounts {
+		sorted = append(sorted, serviceAccount)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].String() < sorted[j].String()
+	})
+	return sorted
+}
+
+// joinServiceAccounts returns the given service accounts as a comma separated list
+func joinServiceAccounts(serviceAccounts []identity.K8sServiceAccount) string {
+	names := make([]string, 0, len(serviceAccounts))
+	for _, serviceAccount := range serviceAccounts {
+		names = append(names, serviceAccount.String())
+	}
+	return strings.Join(names, ", ")
+}
+
+// describeAllowed returns whether traffic is allowed or denied
+func describeAllowed(allowed bool) string {
+	if allowed {
+		return "allowed"
+	}
+	return "denied"
+}
+
+// readNetworkPolicies returns the NetworkPolicies read from the given YAML or JSON manifests, including the items of
+// lists of NetworkPolicies. Empty and null documents are ignored, any other kind of resource is rejected.
+func readNetworkPolicies(in io.Reader) ([]networkingv1.NetworkPolicy, error) {
+	manifests, err := readManifests(in)
+	if err != nil {
+		return nil, errors.Errorf("Error reading NetworkPolicies: %s", err)
+	}
+
+	var networkPolicies []networkingv1.NetworkPolicy
+	for _, m := range manifests {
+		var items []networkingv1.NetworkPolicy
+		switch m.typeMeta.Kind {
+		case "":
+			if m.typeMeta.APIVersion == "" {
+				continue
+			}
+			return nil, errors.Errorf("Invalid resource of API version %s without a kind, expected a %s", m.typeMeta.APIVersion, networkPolicyKind)
+		case networkPolicyKind:
+			var networkPolicy networkingv1.NetworkPolicy
+			if err := json.Unmarshal(m.raw, &networkPolicy); err != nil {
+				return nil, errors.Errorf("Error reading %s: %s", networkPolicyKind, err)
+			}
+			items = append(items, networkPolicy)
+		case networkPolicyKind + "List", "List":
+			var list networkingv1.NetworkPolicyList
+			if err := json.Unmarshal(m.raw, &list); err != nil {
+				return nil, errors.Errorf("Error reading %s list: %s", networkPolicyKind, err)
+			}
+			items = append(items, list.Items...)
+		default:
+			return nil, errors.Errorf("Unsupported resource of kind %s, expected a %s", m.typeMeta.Kind, networkPolicyKind)
+		}
+
+		for _, networkPolicy := range items {
+			if networkPolicy.Kind != "" && networkPolicy.Kind != networkPolicyKind {
+				return nil, errors.Errorf("Unsupported resource of kind %s, expected a %s", networkPolicy.Kind, networkPolicyKind)
+			}
+			if networkPolicy.Name == "" {
+				return nil, errors.Errorf("Invalid %s without a name", networkPolicyKind)
+			}
+			if networkPolicy.Namespace == "" {
+				networkPolicy.Namespace = metav1.NamespaceDefault
+			}
+			networkPolicies = append(networkPolicies, networkPolicy)
+		}
+	}
+	return networkPolicies, nil
+}
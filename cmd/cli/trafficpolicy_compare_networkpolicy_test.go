@@ -0,0 +1,219 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	fakeAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned/fake"
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+	"github.com/openservicemesh/osm/pkg/identity"
+)
+
+func TestTrafficPolicyCompareNetworkPolicy(t *testing.T) {
+	var objects []runtime.Object
+	for _, app := range []struct{ name, team string }{
+		{name: "bookstore", team: "store"},
+		{name: "bookbuyer", team: "buyer"},
+		{name: "bookthief", team: "thief"},
+	} {
+		namespace := newNamespace(app.name, nil)
+		namespace.Labels["team"] = app.team
+		pod := newTestPod(app.name, app.name, app.name, false)
+		pod.Labels = map[string]string{"app": app.name}
+		objects = append(objects, namespace, pod)
+	}
+	trafficTarget := newTestTrafficTarget("bookbuyer-to-bookstore",
+		identity.K8sServiceAccount{Namespace: "bookbuyer", Name: "bookbuyer"},
+		identity.K8sServiceAccount{Namespace: "bookstore", Name: "bookstore"},
+	)
+
+	testCases := []struct {
+		name                 string
+		networkPolicies      string
+		permissiveMode       bool
+		expectedOutSubstrs   []string
+		notExpectedOutSubstr string
+		expectedErr          string
+	}{
+		{
+			name: "ingress rule matching the SMI policies",
+			networkPolicies: `
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: allow-bookbuyer
+  namespace: bookstore
+spec:
+  podSelector:
+    matchLabels:
+      app: bookstore
+  ingress:
+  - from:
+    - namespaceSelector:
+        matchLabels:
+          team: buyer
+`,
+			expectedOutSubstrs: []string{
+				"[+] NetworkPolicy bookstore/allow-bookbuyer selects the pods of service account(s): bookstore/bookstore",
+				"same",
+				"[+] The SMI policies are more permissive for 0 pair(s) of service accounts, and less permissive for 0 pair(s)",
+			},
+			notExpectedOutSubstr: "bookthief",
+		},
+		{
+			name: "ingress rule allowing another source than the SMI policies",
+			networkPolicies: `
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: allow-bookthief
+  namespace: bookstore
+spec:
+  podSelector: {}
+  ingress:
+  - from:
+    - namespaceSelector:
+        matchLabels:
+          team: thief
+      podSelector:
+        matchLabels:
+          app: bookthief
+`,
+			expectedOutSubstrs: []string{
+				"SMI more permissive",
+				"SMI less permissive",
+				"[+] The SMI policies are more permissive for 1 pair(s) of service accounts, and less permissive for 1 pair(s)",
+			},
+		},
+		{
+			name: "ingress rule without peers allowing every source, after an empty document",
+			networkPolicies: `
+---
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: allow-all
+  namespace: bookstore
+spec:
+  podSelector: {}
+  ingress:
+  - {}
+`,
+			expectedOutSubstrs: []string{
+				"[+] The SMI policies are more permissive for 0 pair(s) of service accounts, and less permissive for 2 pair(s)",
+			},
+		},
+		{
+			name: "egress rule with an IP block",
+			networkPolicies: `
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: egress
+  namespace: bookbuyer
+spec:
+  podSelector: {}
+  policyTypes:
+  - Egress
+  egress:
+  - to:
+    - ipBlock:
+        cidr: 10.0.0.0/8
+`,
+			expectedOutSubstrs: []string{
+				"[!] Peer with IP block 10.0.0.0/8 can't be resolved to service accounts, it is not compared",
+				"egress",
+				"[+] The SMI policies are more permissive for 1 pair(s) of service accounts, and less permissive for 0 pair(s)",
+			},
+			notExpectedOutSubstr: "ingress",
+		},
+		{
+			name: "every pair allowed by the SMI policies in permissive mode",
+			networkPolicies: `
+apiVersion: networking.k8s.io/v1
+kind: NetworkPolicy
+metadata:
+  name: deny-all
+  namespace: bookstore
+spec:
+  podSelector: {}
+`,
+			permissiveMode: true,
+			expectedOutSubstrs: []string{
+				"[+] Permissive mode enabled, the SMI policies allow every pair of service accounts",
+				"[+] The SMI policies are more permissive for 3 pair(s) of service accounts, and less permissive for 0 pair(s)",
+			},
+		},
+		{
+			name: "NetworkPolicies of a list, one selecting no pods",
+			networkPolicies: `
+apiVersion: v1
+kind: List
+items:
+- apiVersion: networking.k8s.io/v1
+  kind: NetworkPolicy
+  metadata:
+    name: none
+    namespace: bookstore
+  spec:
+    podSelector:
+      matchLabels:
+        app: bookwarehouse
+`,
+			expectedOutSubstrs: []string{
+				"[+] NetworkPolicy bookstore/none selects no pods, nothing to compare",
+			},
+		},
+		{
+			name: "unsupported kind of resource",
+			networkPolicies: `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: bookstore
+`,
+			expectedErr: "Unsupported resource of kind Pod, expected a NetworkPolicy",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			out := new(bytes.Buffer)
+			cmd := &trafficPolicyCompareNetworkPolicyCmd{
+				out:            out,
+				in:             strings.NewReader(tc.networkPolicies),
+				filename:       stdinFileName,
+				meshConfigName: osmConfigMapName,
+				clientSet: fake.NewSimpleClientset(append([]runtime.Object{&corev1.ConfigMap{
+					ObjectMeta: metav1.ObjectMeta{Namespace: settings.Namespace(), Name: osmConfigMapName},
+					Data:       map[string]string{configurator.PermissiveTrafficPolicyModeKey: fmt.Sprintf("%t", tc.permissiveMode)},
+				}}, objects...)...),
+				smiAccessClient: fakeAccessClient.NewSimpleClientset(trafficTarget),
+			}
+
+			err := cmd.run()
+			if tc.expectedErr != "" {
+				assert.NotNil(err)
+				assert.Contains(err.Error(), tc.expectedErr)
+				return
+			}
+			assert.Nil(err)
+			for _, substr := range tc.expectedOutSubstrs {
+				assert.Contains(out.String(), substr)
+			}
+			if tc.notExpectedOutSubstr != "" {
+				assert.NotContains(out.String(), tc.notExpectedOutSubstr)
+			}
+		})
+	}
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/pmezard/go-difflib/difflib"
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiSpecs "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/specs/v1alpha4"
+	smiAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned"
+	smiSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/openservicemesh/osm/pkg/cli"
+)
+
+const trafficPolicyDiffDescription = `
+This command compares the SMI TrafficTarget and HTTPRouteGroup policies
+defined in a file with the policies applied in the cluster, and prints
+a unified diff of the spec of each policy that differs.
+
+Policies are identified by their kind, namespace and name. Policies
+without a namespace are compared with the policies in the 'default'
+namespace. Only the spec of the policies is compared, metadata such as
+labels, annotations and fields managed by the API server is ignored.
+
+The command exits with one of the following codes:
+  0: the policies applied in the cluster match the file
+  1: at least one policy differs from the file or is missing in the cluster
+  2: the policies could not be compared, e.g. the file is invalid or the
+     cluster could not be reached
+`
+
+const trafficPolicyDiffExample = `
+# Compare the policies defined in policies.yaml with the policies applied in the cluster
+osm policy diff -f policies.yaml
+
+# Compare the policies read from stdin
+kustomize build ./policies | osm policy diff -f -
+`
+
+const (
+	// diffExitCodeDrift is the exit code of the diff command when policies differ from the cluster
+	diffExitCodeDrift = 1
+
+	// diffExitCodeError is the exit code of the diff command when the policies could not be compared
+	diffExitCodeError = 2
+
+	trafficTargetKind = "TrafficTarget"
+)
+
+type trafficPolicyDiffCmd struct {
+	out             io.Writer
+	in              io.Reader
+	filename        string
+	smiAccessClient smiAccessClient.Interface
+	smiSpecClient   smiSpecClient.Interface
+}
+
+// smiPolicy is an SMI policy read from a manifest, identified by its kind, namespace and name
+type smiPolicy struct {
+	kind      string
+	namespace string
+	name      string
+	spec      interface{}
+}
+
+func (p smiPolicy) String() string {
+	return fmt.Sprintf("%s %s/%s", p.kind, p.namespace, p.name)
+}
+
+func newTrafficPolicyDiffCmd(in io.Reader, out io.Writer) *cobra.Command {
+	diffCmd := &trafficPolicyDiffCmd{
+		in:  in,
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "diff -f FILENAME",
+		Short: "compare SMI policies in a file with the cluster",
+		Long:  trafficPolicyDiffDescription,
+		Args: func(cmd *cobra.Command, args []string) error {
+			return withExitCode(diffExitCodeError, cobra.NoArgs(cmd, args))
+		},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			if diffCmd.filename == "" {
+				return withExitCode(diffExitCodeError, errors.New("flag --filename is required"))
+			}
+
+			clients, err := cli.NewClients(settings)
+			if err != nil {
+				return withExitCode(diffExitCodeError, err)
+			}
+			diffCmd.smiAccessClient = clients.SMIAccessClient
+			diffCmd.smiSpecClient = clients.SMISpecClient
+
+			return diffCmd.run()
+		},
+		Example: trafficPolicyDiffExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&diffCmd.filename, "filename", "f", "", "File containing the SMI TrafficTarget and HTTPRouteGroup policies to compare, or - to read them from stdin")
+
+	return cmd
+}
+
+func (cmd *trafficPolicyDiffCmd) run() error {
+	in := cmd.in
+	if cmd.filename != stdinFileName {
+		fd, err := os.Open(cmd.filename)
+		if err != nil {
+			return withExitCode(diffExitCodeError, errors.Errorf("Error opening file %s: %s", cmd.filename, err))
+		}
+		defer fd.Close() //nolint: errcheck, gosec
+		in = fd
+	}
+
+	policies, err := readSMIPolicies(in)
+	if err != nil {
+		return withExitCode(diffExitCodeError, err)
+	}
+
+	var driftCount int
+	for _, policy := range policies {
+		diff, err := cmd.diffPolicy(policy)
+		if err != nil {
+			return withExitCode(diffExitCodeError, err)
+		}
+		if diff != "" {
+			driftCount++
+			fmt.Fprint(cmd.out, diff)
+		}
+	}
+
+	if driftCount > 0 {
+		return withExitCode(diffExitCodeDrift, errors.Errorf("%d of %d SMI policies differ from the cluster", driftCount, len(policies)))
+	}
+	fmt.Fprintf(cmd.out, "[+] All %d SMI policies match the cluster\n", len(policies))
+	return nil
+}
+
+// diffPolicy returns the unified diff between the spec of the given policy applied in the cluster and its spec
+// read from the manifest, or an empty string if they are identical
+func (cmd *trafficPolicyDiffCmd) diffPolicy(policy smiPolicy) (string, error) {
+	var live interface{}
+	var err error
+	switch policy.kind {
+	case trafficTargetKind:
+		var trafficTarget *smiAccess.TrafficTarget
+		trafficTarget, err = cmd.smiAccessClient.AccessV1alpha3().TrafficTargets(policy.namespace).Get(context.TODO(), policy.name, metav1.GetOptions{})
+		if err == nil {
+			live = trafficTarget.Spec
+		}
+	case httpRouteGroupKind:
+		var routeGroup *smiSpecs.HTTPRouteGroup
+		routeGroup, err = cmd.smiSpecClient.SpecsV1alpha4().HTTPRouteGroups(policy.namespace).Get(context.TODO(), policy.name, metav1.GetOptions{})
+		if err == nil {
+			live = routeGroup.Spec
+		}
+	}
+
+	liveFile := fmt.Sprintf("live/%s", policy)
+	if apierrors.IsNotFound(err) {
+		liveFile = fmt.Sprintf("live/%s (not found)", policy)
+	} else if err != nil {
+		return "", errors.Errorf("Error fetching SMI %s: %s", policy, err)
+	}
+
+	liveYAML, err := specToYAML(live)
+	if err != nil {
+		return "", err
+	}
+	desiredYAML, err := specToYAML(policy.spec)
+	if err != nil {
+		return "", err
+	}
+	if liveYAML == desiredYAML {
+		return "", nil
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(liveYAML),
+		B:        difflib.SplitLines(desiredYAML),
+		FromFile: liveFile,
+		ToFile:   fmt.Sprintf("desired/%s", policy),
+		Context:  3,
+	})
+}
+
+// specToYAML returns the YAML representation of the given policy spec, or an empty string for a missing spec
+func specToYAML(spec interface{}) (string, error) {
+	if spec == nil {
+		return "", nil
+	}
+	specYAML, err := yaml.Marshal(spec)
+	if err != nil {
+		return "", errors.Errorf("Error marshaling policy spec: %s", err)
+	}
+	return string(specYAML), nil
+}
+
+// readSMIPolicies returns the SMI TrafficTarget and HTTPRouteGroup policies read from the given YAML or JSON
+// manifests. Empty documents are ignored, any other kind of resource is rejected.
+func readSMIPolicies(in io.Reader) ([]smiPolicy, error) {
+	manifests, err := readManifests(in)
+	if err != nil {
+		return nil, errors.Errorf("Error reading SMI policies: %s", err)
+	}
+
+	var policies []smiPolicy
+	for _, m := range manifests {
+		if m.typeMeta.Kind == "" && m.typeMeta.APIVersion == "" {
+			continue
+		}
+
+		policy, err := decodeSMIPolicy(m.typeMeta, m.raw)
+		if err != nil {
+			return nil, err
+		}
+		if policy.name == "" {
+			return nil, errors.Errorf("Invalid %s without a name", policy.kind)
+		}
+		if policy.namespace == "" {
+			policy.namespace = metav1.NamespaceDefault
+		}
+		policies = append(policies, policy)
+	}
+
+	if len(policies) == 0 {
+		return nil, errors.New("No SMI policies to compare")
+	}
+	return policies, nil
+}
+
+func decodeSMIPolicy(typeMeta metav1.TypeMeta, raw []byte) (smiPolicy, error) {
+	switch {
+	case typeMeta.Kind == trafficTargetKind && typeMeta.APIVersion == smiAccess.SchemeGroupVersion.String():
+		var trafficTarget smiAccess.TrafficTarget
+		if err := json.Unmarshal(raw, &trafficTarget); err != nil {
+			return smiPolicy{}, errors.Errorf("Error reading SMI %s: %s", trafficTargetKind, err)
+		}
+		return smiPolicy{kind: trafficTargetKind, namespace: trafficTarget.Namespace, name: trafficTarget.Name, spec: trafficTarget.Spec}, nil
+
+	case typeMeta.Kind == httpRouteGroupKind && typeMeta.APIVersion == smiSpecs.SchemeGroupVersion.String():
+		var routeGroup smiSpecs.HTTPRouteGroup
+		if err := json.Unmarshal(raw, &routeGroup); err != nil {
+			return smiPolicy{}, errors.Errorf("Error reading SMI %s: %s", httpRouteGroupKind, err)
+		}
+		return smiPolicy{kind: httpRouteGroupKind, namespace: routeGroup.Namespace, name: routeGroup.Name, spec: routeGroup.Spec}, nil
+
+	default:
+		return smiPolicy{}, errors.Errorf("Unsupported resource %s %s, expected %s %s or %s %s", typeMeta.APIVersion, typeMeta.Kind,
+			smiAccess.SchemeGroupVersion, trafficTargetKind, smiSpecs.SchemeGroupVersion, httpRouteGroupKind)
+	}
+}
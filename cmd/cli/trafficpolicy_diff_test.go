@@ -0,0 +1,176 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiSpecs "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/specs/v1alpha4"
+	fakeAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned/fake"
+	fakeSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned/fake"
+	tassert "github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openservicemesh/osm/pkg/identity"
+)
+
+const desiredPolicies = `
+apiVersion: access.smi-spec.io/v1alpha3
+kind: TrafficTarget
+metadata:
+  name: bookstore
+  namespace: bookstore
+spec:
+  destination:
+    kind: ServiceAccount
+    name: bookstore
+    namespace: bookstore
+  rules:
+  - kind: HTTPRouteGroup
+    name: bookstore-service-routes
+    matches:
+    - buy-a-book
+  sources:
+  - kind: ServiceAccount
+    name: bookbuyer
+    namespace: bookbuyer
+---
+apiVersion: specs.smi-spec.io/v1alpha4
+kind: HTTPRouteGroup
+metadata:
+  name: bookstore-service-routes
+spec:
+  matches:
+  - name: buy-a-book
+    pathRegex: .*a-book.*new
+    methods:
+    - GET
+---
+`
+
+func TestTrafficPolicyDiff(t *testing.T) {
+	newTrafficTarget := func(sourceServiceAccount string) *smiAccess.TrafficTarget {
+		trafficTarget := newTestTrafficTarget("bookstore",
+			identity.K8sServiceAccount{Namespace: "bookbuyer", Name: sourceServiceAccount},
+			identity.K8sServiceAccount{Namespace: "bookstore", Name: "bookstore"},
+			smiAccess.TrafficTargetRule{Kind: httpRouteGroupKind, Name: "bookstore-service-routes", Matches: []string{"buy-a-book"}},
+		)
+		trafficTarget.ResourceVersion = "42"
+		trafficTarget.Annotations = map[string]string{"kubectl.kubernetes.io/last-applied-configuration": "{}"}
+		return trafficTarget
+	}
+	newHTTPRouteGroup := func(namespace string) *smiSpecs.HTTPRouteGroup {
+		return &smiSpecs.HTTPRouteGroup{
+			ObjectMeta: metav1.ObjectMeta{Name: "bookstore-service-routes", Namespace: namespace},
+			Spec: smiSpecs.HTTPRouteGroupSpec{
+				Matches: []smiSpecs.HTTPMatch{{Name: "buy-a-book", PathRegex: ".*a-book.*new", Methods: []string{"GET"}}},
+			},
+		}
+	}
+
+	testCases := []struct {
+		name             string
+		manifests        string
+		trafficTargets   []*smiAccess.TrafficTarget
+		routeGroups      []*smiSpecs.HTTPRouteGroup
+		expectedExitCode int
+		expectedErr      string
+		expectedOutputs  []string
+	}{
+		{
+			name:             "policies match the cluster",
+			manifests:        desiredPolicies,
+			trafficTargets:   []*smiAccess.TrafficTarget{newTrafficTarget("bookbuyer")},
+			routeGroups:      []*smiSpecs.HTTPRouteGroup{newHTTPRouteGroup("default")},
+			expectedExitCode: 0,
+			expectedOutputs:  []string{"[+] All 2 SMI policies match the cluster"},
+		},
+		{
+			name:             "spec of a policy differs from the cluster",
+			manifests:        desiredPolicies,
+			trafficTargets:   []*smiAccess.TrafficTarget{newTrafficTarget("bookthief")},
+			routeGroups:      []*smiSpecs.HTTPRouteGroup{newHTTPRouteGroup("default")},
+			expectedExitCode: diffExitCodeDrift,
+			expectedErr:      "1 of 2 SMI policies differ from the cluster",
+			expectedOutputs: []string{
+				"--- live/TrafficTarget bookstore/bookstore\n+++ desired/TrafficTarget bookstore/bookstore\n",
+				"-  name: bookthief\n",
+				"+  name: bookbuyer\n",
+			},
+		},
+		{
+			name:             "policy is missing in the cluster",
+			manifests:        desiredPolicies,
+			trafficTargets:   []*smiAccess.TrafficTarget{newTrafficTarget("bookbuyer")},
+			routeGroups:      []*smiSpecs.HTTPRouteGroup{newHTTPRouteGroup("bookstore")},
+			expectedExitCode: diffExitCodeDrift,
+			expectedErr:      "1 of 2 SMI policies differ from the cluster",
+			expectedOutputs: []string{
+				"--- live/HTTPRouteGroup default/bookstore-service-routes (not found)\n+++ desired/HTTPRouteGroup default/bookstore-service-routes\n",
+				"+- methods:\n",
+			},
+		},
+		{
+			name:             "unsupported resource",
+			manifests:        "apiVersion: v1\nkind: Service\nmetadata:\n  name: bookstore\n",
+			expectedExitCode: diffExitCodeError,
+			expectedErr:      "Unsupported resource v1 Service",
+		},
+		{
+			name:             "unsupported policy version",
+			manifests:        "apiVersion: access.smi-spec.io/v1alpha2\nkind: TrafficTarget\nmetadata:\n  name: bookstore\n",
+			expectedExitCode: diffExitCodeError,
+			expectedErr:      "Unsupported resource access.smi-spec.io/v1alpha2 TrafficTarget",
+		},
+		{
+			name:             "policy without a name",
+			manifests:        "apiVersion: specs.smi-spec.io/v1alpha4\nkind: HTTPRouteGroup\nspec: {}\n",
+			expectedExitCode: diffExitCodeError,
+			expectedErr:      "Invalid HTTPRouteGroup without a name",
+		},
+		{
+			name:             "no policies",
+			manifests:        "---\n# nothing to compare\n",
+			expectedExitCode: diffExitCodeError,
+			expectedErr:      "No SMI policies to compare",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			accessClient := fakeAccessClient.NewSimpleClientset()
+			for _, trafficTarget := range tc.trafficTargets {
+				assert.Nil(accessClient.Tracker().Add(trafficTarget))
+			}
+			specClient := fakeSpecClient.NewSimpleClientset()
+			for _, routeGroup := range tc.routeGroups {
+				assert.Nil(specClient.Tracker().Add(routeGroup))
+			}
+
+			out := new(bytes.Buffer)
+			cmd := trafficPolicyDiffCmd{
+				out:             out,
+				in:              strings.NewReader(tc.manifests),
+				filename:        stdinFileName,
+				smiAccessClient: accessClient,
+				smiSpecClient:   specClient,
+			}
+
+			err := cmd.run()
+			if tc.expectedErr != "" {
+				assert.NotNil(err)
+				assert.Contains(err.Error(), tc.expectedErr)
+				assert.Equal(tc.expectedExitCode, getExitCode(err))
+			} else {
+				assert.Nil(err)
+			}
+			for _, expectedOutput := range tc.expectedOutputs {
+				assert.Contains(out.String(), expectedOutput)
+			}
+		})
+	}
+}
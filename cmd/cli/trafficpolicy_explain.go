@@ -0,0 +1,387 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiSpecs "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/specs/v1alpha4"
+	smiAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned"
+	smiSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/openservicemesh/osm/pkg/cli"
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+const trafficPolicyExplainDescription = `
+This command explains whether a specific request sent by a given source pod
+to a given destination pod would be allowed by the SMI TrafficTarget policies
+of the mesh, and which rule allows or denies it.
+
+The request is evaluated against every rule of the TrafficTarget policies
+allowing the source pod to communicate to the destination pod:
+  - a TCPRoute rule allows the request if it matches the port of the request,
+    or if it does not restrict the ports
+  - an HTTPRouteGroup rule allows the request if one of its matches allows
+    the method of the request, if its path regex matches the whole path of
+    the request and if the regex of each of its headers matches the whole
+    value of the corresponding request header, as enforced by the sidecars,
+    where a header absent from the request does not match
+
+The query string of the path is ignored, as it is by the sidecars when
+matching the path regex of a route.
+
+When the mesh operates in permissive traffic policy mode, every request is
+allowed.
+
+The command exits with the same codes as 'osm policy check-pods':
+  0: the request is allowed
+  1: unexpected error
+  2: invalid input, e.g. a pod that does not exist or an invalid header
+  3: the request is denied
+  4: error communicating with the Kubernetes API server
+`
+
+const trafficPolicyExplainExample = `
+# Explain whether a GET request to /books on port 14001, sent by pod 'bookbuyer-client' in the 'bookbuyer' namespace
+# to pod 'bookstore-server' in the 'bookstore' namespace, is allowed
+osm policy explain bookbuyer/bookbuyer-client bookstore/bookstore-server --port 14001 --path /books
+
+# Explain whether a POST request with the header 'user-agent: curl' is allowed
+osm policy explain bookbuyer/bookbuyer-client bookstore/bookstore-server --port 14001 --method POST --path /buy -H "user-agent: curl"
+
+# Explain whether a GET request to /admin with an authorization header is allowed
+osm policy explain bookbuyer/bookbuyer-client bookstore/bookstore-server --port 14001 --path /admin --header "authorization=Bearer token"
+`
+
+type trafficPolicyExplainCmd struct {
+	out             io.Writer
+	sourcePod       string
+	destinationPod  string
+	port            int
+	method          string
+	path            string
+	headers         []string
+	meshName        string
+	meshConfigName  string
+	clientSet       kubernetes.Interface
+	smiAccessClient smiAccessClient.Interface
+	smiSpecClient   smiSpecClient.Interface
+}
+
+// explainedRequest is the request whose traffic policy is explained
+type explainedRequest struct {
+	port    int
+	method  string
+	path    string
+	headers map[string]string
+}
+
+func (r explainedRequest) String() string {
+	return fmt.Sprintf("%s %s on port %d", r.method, r.path, r.port)
+}
+
+// ruleVerdict is the result of the evaluation of a request against a rule of a TrafficTarget
+type ruleVerdict struct {
+	allowed bool
+	reason  string
+}
+
+func newTrafficPolicyExplainCmd(out io.Writer) *cobra.Command {
+	explainCmd := &trafficPolicyExplainCmd{
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "explain SOURCE_POD DESTINATION_POD",
+		Short: "explain whether a specific request is allowed by the traffic policies",
+		Long:  trafficPolicyExplainDescription,
+		Args: func(cmd *cobra.Command, args []string) error {
+			return withExitCode(checkExitCodeInvalidInput, cobra.ExactArgs(2)(cmd, args))
+		},
+		RunE: func(_ *cobra.Command, args []string) error {
+			explainCmd.sourcePod = args[0]
+			explainCmd.destinationPod = args[1]
+
+			clients, err := cli.NewClients(settings)
+			if err != nil {
+				return withExitCode(checkExitCodeAPIError, err)
+			}
+			explainCmd.clientSet = clients.KubeClient
+			explainCmd.smiAccessClient = clients.SMIAccessClient
+			explainCmd.smiSpecClient = clients.SMISpecClient
+
+			return explainCmd.run()
+		},
+		Example: trafficPolicyExplainExample,
+	}
+
+	f := cmd.Flags()
+	f.IntVar(&explainCmd.port, "port", 0, "Destination port of the request (required)")
+	f.StringVar(&explainCmd.method, "method", http.MethodGet, "HTTP method of the request")
+	f.StringVar(&explainCmd.path, "path", "/", "HTTP path of the request")
+	f.StringArrayVarP(&explainCmd.headers, "header", "H", nil, "HTTP header of the request in the form 'name: value' or 'name=value', can be repeated")
+	f.StringVar(&explainCmd.meshName, "mesh-name", "", "Name of the mesh whose configuration is checked, the mesh running in the namespace given with --osm-namespace if unset")
+	f.StringVar(&explainCmd.meshConfigName, "mesh-config-name", osmConfigMapName, "Name of the ConfigMap holding the configuration of the mesh")
+
+	return cmd
+}
+
+func (cmd *trafficPolicyExplainCmd) run() error {
+	request, err := cmd.getRequest()
+	if err != nil {
+		return withExitCode(checkExitCodeInvalidInput, err)
+	}
+
+	// The lookups of the pods and of the mesh are shared with 'osm policy check-pods'
+	checkCmd := &trafficPolicyCheckCmd{
+		out:             cmd.out,
+		meshName:        cmd.meshName,
+		meshConfigName:  cmd.meshConfigName,
+		clientSet:       cmd.clientSet,
+		smiAccessClient: cmd.smiAccessClient,
+		smiSpecClient:   cmd.smiSpecClient,
+	}
+	osmNamespace, err := checkCmd.getOSMNamespace()
+	if err != nil {
+		return withExitCode(checkExitCodeInvalidInput, err)
+	}
+
+	srcPod, err := cmd.getPod(checkCmd, cmd.sourcePod, "source")
+	if err != nil {
+		return err
+	}
+	dstPod, err := cmd.getPod(checkCmd, cmd.destinationPod, "destination")
+	if err != nil {
+		return err
+	}
+	src := srcPod.Namespace + namespaceSeparator + srcPod.Name
+	dst := dstPod.Namespace + namespaceSeparator + dstPod.Name
+
+	permissiveMode, err := checkCmd.isPermissiveModeEnabled()
+	if err != nil {
+		return withExitCode(checkExitCodeAPIError, errors.Errorf("Error checking if permissive mode is enabled: %s", err))
+	}
+	if permissiveMode {
+		fmt.Fprintf(cmd.out, "[+] Permissive mode enabled for mesh operated by osm-controller running in '%s' namespace\n\n", osmNamespace)
+		fmt.Fprintf(cmd.out, "[+] Request %s from pod '%s' to pod '%s' is allowed\n", request, src, dst)
+		return nil
+	}
+
+	fmt.Fprintf(cmd.out, "[+] SMI traffic policy mode enabled for mesh operated by osm-controller running in %s namespace\n\n", osmNamespace)
+	trafficTargets, err := checkCmd.listTrafficTargets(dstPod.Namespace)
+	if err != nil {
+		return withExitCode(checkExitCodeAPIError, err)
+	}
+
+	allowingTrafficTargets := getAllowingTrafficTargets(trafficTargets, srcPod, dstPod.Namespace, dstPod.Spec.ServiceAccountName)
+	if len(allowingTrafficTargets) == 0 {
+		fmt.Fprintf(cmd.out, "[-] Request %s from pod '%s' to pod '%s' is denied, missing SMI TrafficTarget policy\n", request, src, dst)
+		return withExitCode(checkExitCodeTrafficDenied, errors.Errorf("Request %s from pod %s to pod %s is denied", request, src, dst))
+	}
+
+	var allowingRules []string
+	for _, trafficTarget := range allowingTrafficTargets {
+		fmt.Fprintf(cmd.out, "[+] Evaluating the rules of the SMI TrafficTarget policy %q:\n", trafficTarget.Name)
+		if len(trafficTarget.Spec.Rules) == 0 {
+			fmt.Fprintln(cmd.out, "    [-] The policy has no rules")
+		}
+		for _, rule := range trafficTarget.Spec.Rules {
+			verdicts, err := cmd.evaluateRule(trafficTarget, rule, request)
+			if err != nil {
+				return withExitCode(checkExitCodeAPIError, err)
+			}
+			for _, verdict := range verdicts {
+				if verdict.allowed {
+					fmt.Fprintf(cmd.out, "    [+] %s: allows the request\n", verdict.reason)
+					allowingRules = append(allowingRules, fmt.Sprintf("%s (TrafficTarget %s)", verdict.reason, trafficTarget.Name))
+				} else {
+					fmt.Fprintf(cmd.out, "    [-] %s\n", verdict.reason)
+				}
+			}
+		}
+	}
+	fmt.Fprintln(cmd.out)
+
+	if len(allowingRules) == 0 {
+		fmt.Fprintf(cmd.out, "[-] Request %s from pod '%s' to pod '%s' is denied, no rule matches the request\n", request, src, dst)
+		return withExitCode(checkExitCodeTrafficDenied, errors.Errorf("Request %s from pod %s to pod %s is denied", request, src, dst))
+	}
+	fmt.Fprintf(cmd.out, "[+] Request %s from pod '%s' to pod '%s' is allowed by:\n", request, src, dst)
+	for _, rule := range allowingRules {
+		fmt.Fprintf(cmd.out, "    %s\n", rule)
+	}
+	return nil
+}
+
+// getRequest validates the flags describing the request and returns the request
+func (cmd *trafficPolicyExplainCmd) getRequest() (explainedRequest, error) {
+	if cmd.port < 1 || cmd.port > 65535 {
+		return explainedRequest{}, errors.Errorf("Invalid value %d for flag --port, must be between 1 and 65535", cmd.port)
+	}
+	if cmd.method == "" {
+		return explainedRequest{}, errors.New("flag --method cannot be empty")
+	}
+	if !strings.HasPrefix(cmd.path, "/") {
+		return explainedRequest{}, errors.Errorf("Invalid value %q for flag --path, must start with /", cmd.path)
+	}
+
+	headers, err := parseRequestHeaders(cmd.headers)
+	if err != nil {
+		return explainedRequest{}, err
+	}
+
+	return explainedRequest{
+		port:    cmd.port,
+		method:  strings.ToUpper(cmd.method),
+		path:    cmd.path,
+		headers: headers,
+	}, nil
+}
+
+// getPod returns the meshed pod identified by the given <namespace/pod> or <pod> argument
+func (cmd *trafficPolicyExplainCmd) getPod(checkCmd *trafficPolicyCheckCmd, namespacedPod, role string) (*corev1.Pod, error) {
+	namespace, podName, err := unmarshalNamespacedPod(namespacedPod)
+	if err != nil {
+		return nil, withExitCode(checkExitCodeInvalidInput, errors.Errorf("Invalid argument specified for the %s pod: %s", role, err))
+	}
+	if err := checkCmd.validateNamespace(namespace); err != nil {
+		return nil, err
+	}
+	return checkCmd.getMeshedPod(namespace, podName)
+}
+
+// evaluateRule returns the verdicts of the given rule of the TrafficTarget for the request, one per HTTP match
+// referenced by an HTTPRouteGroup rule
+func (cmd *trafficPolicyExplainCmd) evaluateRule(trafficTarget smiAccess.TrafficTarget, rule smiAccess.TrafficTargetRule, request explainedRequest) ([]ruleVerdict, error) {
+	route := fmt.Sprintf("%s %s/%s", rule.Kind, trafficTarget.Namespace, rule.Name)
+
+	switch rule.Kind {
+	case tcpRouteKind:
+		tcpRoute, err := cmd.smiSpecClient.SpecsV1alpha4().TCPRoutes(trafficTarget.Namespace).Get(context.TODO(), rule.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return []ruleVerdict{{reason: fmt.Sprintf("%s: not found", route)}}, nil
+		}
+		if err != nil {
+			return nil, errors.Errorf("Error fetching SMI TCPRoute %s/%s: %s", trafficTarget.Namespace, rule.Name, err)
+		}
+		return []ruleVerdict{evaluateTCPMatch(route, tcpRoute.Spec.Matches, request)}, nil
+
+	case httpRouteGroupKind:
+		routeGroup, err := cmd.smiSpecClient.SpecsV1alpha4().HTTPRouteGroups(trafficTarget.Namespace).Get(context.TODO(), rule.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return []ruleVerdict{{reason: fmt.Sprintf("%s: not found", route)}}, nil
+		}
+		if err != nil {
+			return nil, errors.Errorf("Error fetching SMI HTTPRouteGroup %s/%s: %s", trafficTarget.Namespace, rule.Name, err)
+		}
+
+		matches := make(map[string]smiSpecs.HTTPMatch)
+		for _, match := range routeGroup.Spec.Matches {
+			matches[match.Name] = match
+		}
+		matchNames := rule.Matches
+		if len(matchNames) == 0 {
+			for _, match := range routeGroup.Spec.Matches {
+				matchNames = append(matchNames, match.Name)
+			}
+		}
+		if len(matchNames) == 0 {
+			return []ruleVerdict{{reason: fmt.Sprintf("%s: has no matches", route)}}, nil
+		}
+
+		var verdicts []ruleVerdict
+		for _, matchName := range matchNames {
+			match, ok := matches[matchName]
+			if !ok {
+				verdicts = append(verdicts, ruleVerdict{reason: fmt.Sprintf("%s, match %q: not found", route, matchName)})
+				continue
+			}
+			verdicts = append(verdicts, evaluateHTTPMatch(fmt.Sprintf("%s, match %q", route, matchName), match, request))
+		}
+		return verdicts, nil
+
+	default:
+		return []ruleVerdict{{reason: fmt.Sprintf("%s: unsupported rule kind", route)}}, nil
+	}
+}
+
+// evaluateTCPMatch returns whether the given TCP match of a TCPRoute allows the port of the request
+func evaluateTCPMatch(route string, match smiSpecs.TCPMatch, request explainedRequest) ruleVerdict {
+	if len(match.Ports) == 0 {
+		return ruleVerdict{allowed: true, reason: fmt.Sprintf("%s, all TCP ports", route)}
+	}
+
+	var ports []string
+	for _, port := range match.Ports {
+		if port == request.port {
+			return ruleVerdict{allowed: true, reason: fmt.Sprintf("%s, TCP port %d", route, port)}
+		}
+		ports = append(ports, fmt.Sprintf("%d", port))
+	}
+	return ruleVerdict{reason: fmt.Sprintf("%s: port %d is not one of the allowed TCP ports %s", route, request.port, strings.Join(ports, ", "))}
+}
+
+// evaluateHTTPMatch returns whether the given HTTP match of an HTTPRouteGroup allows the request. The path and header
+// regexes must match the whole path and header values, and the query string of the path is ignored.
+func evaluateHTTPMatch(route string, match smiSpecs.HTTPMatch, request explainedRequest) ruleVerdict {
+	if !isHTTPMethodAllowed(match.Methods, request.method) {
+		return ruleVerdict{reason: fmt.Sprintf("%s: method %s is not one of the allowed methods %s", route, request.method, strings.Join(match.Methods, ","))}
+	}
+
+	pathRegex := match.PathRegex
+	if pathRegex == "" {
+		pathRegex = constants.RegexMatchAll
+	}
+	path := strings.SplitN(request.path, "?", 2)[0]
+	matched, err := matchesWholeString(pathRegex, path)
+	if err != nil {
+		return ruleVerdict{reason: fmt.Sprintf("%s: invalid path regex %q: %s", route, pathRegex, err)}
+	}
+	if !matched {
+		return ruleVerdict{reason: fmt.Sprintf("%s: path %s does not match the path regex %q", route, path, pathRegex)}
+	}
+
+	if mismatch := getHeadersMismatch(match.Headers, request.headers); mismatch != "" {
+		return ruleVerdict{reason: fmt.Sprintf("%s: %s", route, mismatch)}
+	}
+
+	return ruleVerdict{allowed: true, reason: route}
+}
+
+// isHTTPMethodAllowed returns whether the given method is one of the allowed methods, where no methods or the
+// wildcard method allow every method
+func isHTTPMethodAllowed(allowedMethods []string, method string) bool {
+	if len(allowedMethods) == 0 {
+		return true
+	}
+	for _, allowed := range allowedMethods {
+		if allowed == constants.WildcardHTTPMethod || strings.EqualFold(allowed, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesWholeString returns whether the given RE2 regex matches the whole string, as the regex matchers of Envoy do
+func matchesWholeString(regex, s string) (bool, error) {
+	// Compile the regex as is first, so that errors refer to the given regex
+	if _, err := regexp.Compile(regex); err != nil {
+		return false, err
+	}
+	re, err := regexp.Compile(`^(?:` + regex + `)$`)
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(s), nil
+}
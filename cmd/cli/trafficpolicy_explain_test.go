@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiSpecs "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/specs/v1alpha4"
+	fakeAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned/fake"
+	fakeSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned/fake"
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+)
+
+func TestTrafficPolicyExplain(t *testing.T) {
+	newMeshConfig := func(permissive string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: settings.Namespace(), Name: osmConfigMapName},
+			Data:       map[string]string{configurator.PermissiveTrafficPolicyModeKey: permissive},
+		}
+	}
+	trafficTarget := &smiAccess.TrafficTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: "bookstore", Namespace: "ns-2"},
+		Spec: smiAccess.TrafficTargetSpec{
+			Destination: smiAccess.IdentityBindingSubject{Kind: serviceAccountKind, Name: "sa-2", Namespace: "ns-2"},
+			Sources:     []smiAccess.IdentityBindingSubject{{Kind: serviceAccountKind, Name: "sa-1", Namespace: "ns-1"}},
+			Rules: []smiAccess.TrafficTargetRule{
+				{Kind: httpRouteGroupKind, Name: "bookstore-routes", Matches: []string{"buy-books", "restock"}},
+				{Kind: tcpRouteKind, Name: "metrics"},
+			},
+		},
+	}
+	httpRouteGroup := &smiSpecs.HTTPRouteGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "bookstore-routes", Namespace: "ns-2"},
+		Spec: smiSpecs.HTTPRouteGroupSpec{
+			Matches: []smiSpecs.HTTPMatch{
+				{Name: "buy-books", Methods: []string{"GET"}, PathRegex: "/books/[0-9]+"},
+				{Name: "restock", Methods: []string{"POST"}, PathRegex: "/restock", Headers: map[string]string{"user-agent": "restock-.*"}},
+				{Name: "unreferenced"},
+			},
+		},
+	}
+	tcpRoute := &smiSpecs.TCPRoute{
+		ObjectMeta: metav1.ObjectMeta{Name: "metrics", Namespace: "ns-2"},
+		Spec:       smiSpecs.TCPRouteSpec{Matches: smiSpecs.TCPMatch{Ports: []int{9091}}},
+	}
+
+	testCases := []struct {
+		name             string
+		permissive       string
+		sourcePod        string
+		port             int
+		method           string
+		path             string
+		headers          []string
+		expectedExitCode int
+		expectedOut      []string
+	}{
+		{
+			name:      "request allowed by an HTTP match with a regex path",
+			sourcePod: "ns-1/pod-1",
+			port:      14001,
+			method:    "get",
+			path:      "/books/42?format=json",
+			expectedOut: []string{
+				`    [+] HTTPRouteGroup ns-2/bookstore-routes, match "buy-books": allows the request`,
+				`    [-] HTTPRouteGroup ns-2/bookstore-routes, match "restock": method GET is not one of the allowed methods POST`,
+				"    [-] TCPRoute ns-2/metrics: port 14001 is not one of the allowed TCP ports 9091",
+				"[+] Request GET /books/42?format=json on port 14001 from pod 'ns-1/pod-1' to pod 'ns-2/pod-2' is allowed by:",
+				`    HTTPRouteGroup ns-2/bookstore-routes, match "buy-books" (TrafficTarget bookstore)`,
+			},
+		},
+		{
+			name:             "path regex must match the whole path",
+			sourcePod:        "ns-1/pod-1",
+			port:             14001,
+			method:           "GET",
+			path:             "/books/42/reviews",
+			expectedExitCode: checkExitCodeTrafficDenied,
+			expectedOut: []string{
+				`    [-] HTTPRouteGroup ns-2/bookstore-routes, match "buy-books": path /books/42/reviews does not match the path regex "/books/[0-9]+"`,
+				"[-] Request GET /books/42/reviews on port 14001 from pod 'ns-1/pod-1' to pod 'ns-2/pod-2' is denied, no rule matches the request",
+			},
+		},
+		{
+			name:             "missing header",
+			sourcePod:        "ns-1/pod-1",
+			port:             14001,
+			method:           "POST",
+			path:             "/restock",
+			expectedExitCode: checkExitCodeTrafficDenied,
+			expectedOut: []string{
+				`    [-] HTTPRouteGroup ns-2/bookstore-routes, match "restock": header user-agent is missing from the request`,
+			},
+		},
+		{
+			name:             "header value not matching",
+			sourcePod:        "ns-1/pod-1",
+			port:             14001,
+			method:           "POST",
+			path:             "/restock",
+			headers:          []string{"User-Agent: curl/7.68.0"},
+			expectedExitCode: checkExitCodeTrafficDenied,
+			expectedOut: []string{
+				`    [-] HTTPRouteGroup ns-2/bookstore-routes, match "restock": value "curl/7.68.0" of header user-agent does not match the regex "restock-.*"`,
+			},
+		},
+		{
+			name:      "request allowed by an HTTP match with headers",
+			sourcePod: "ns-1/pod-1",
+			port:      14001,
+			method:    "POST",
+			path:      "/restock",
+			headers:   []string{"User-Agent: restock-job"},
+			expectedOut: []string{
+				`    [+] HTTPRouteGroup ns-2/bookstore-routes, match "restock": allows the request`,
+			},
+		},
+		{
+			name:      "request allowed by an HTTP match with headers given as name=value",
+			sourcePod: "ns-1/pod-1",
+			port:      14001,
+			method:    "POST",
+			path:      "/restock",
+			headers:   []string{"user-agent=restock-job"},
+			expectedOut: []string{
+				`    [+] HTTPRouteGroup ns-2/bookstore-routes, match "restock": allows the request`,
+			},
+		},
+		{
+			name:      "request allowed by a TCP route",
+			sourcePod: "ns-1/pod-1",
+			port:      9091,
+			method:    "GET",
+			path:      "/metrics",
+			expectedOut: []string{
+				"    [+] TCPRoute ns-2/metrics, TCP port 9091: allows the request",
+				"    TCPRoute ns-2/metrics, TCP port 9091 (TrafficTarget bookstore)",
+			},
+		},
+		{
+			name:             "source not allowed by any TrafficTarget",
+			sourcePod:        "ns-1/pod-3",
+			port:             14001,
+			method:           "GET",
+			path:             "/books/42",
+			expectedExitCode: checkExitCodeTrafficDenied,
+			expectedOut: []string{
+				"[-] Request GET /books/42 on port 14001 from pod 'ns-1/pod-3' to pod 'ns-2/pod-2' is denied, missing SMI TrafficTarget policy",
+			},
+		},
+		{
+			name:       "permissive traffic policy mode",
+			permissive: "true",
+			sourcePod:  "ns-1/pod-3",
+			port:       14001,
+			method:     "DELETE",
+			path:       "/books/42",
+			expectedOut: []string{
+				"[+] Request DELETE /books/42 on port 14001 from pod 'ns-1/pod-3' to pod 'ns-2/pod-2' is allowed",
+			},
+		},
+		{
+			name:             "invalid port",
+			sourcePod:        "ns-1/pod-1",
+			method:           "GET",
+			path:             "/",
+			expectedExitCode: checkExitCodeInvalidInput,
+		},
+		{
+			name:             "invalid header",
+			sourcePod:        "ns-1/pod-1",
+			port:             14001,
+			method:           "GET",
+			path:             "/",
+			headers:          []string{"user-agent"},
+			expectedExitCode: checkExitCodeInvalidInput,
+		},
+		{
+			name:             "source pod not found",
+			sourcePod:        "ns-1/pod-4",
+			port:             14001,
+			method:           "GET",
+			path:             "/",
+			expectedExitCode: checkExitCodeInvalidInput,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			permissive := tc.permissive
+			if permissive == "" {
+				permissive = "false"
+			}
+
+			out := new(bytes.Buffer)
+			cmd := &trafficPolicyExplainCmd{
+				out:            out,
+				sourcePod:      tc.sourcePod,
+				destinationPod: "ns-2/pod-2",
+				port:           tc.port,
+				method:         tc.method,
+				path:           tc.path,
+				headers:        tc.headers,
+				meshConfigName: osmConfigMapName,
+				clientSet: fake.NewSimpleClientset(
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-1"}},
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-2"}},
+					newTestPod("ns-1", "pod-1", "sa-1", true),
+					newTestPod("ns-2", "pod-2", "sa-2", true),
+					newTestPod("ns-1", "pod-3", "sa-3", true),
+					newMeshConfig(permissive),
+				),
+				smiAccessClient: fakeAccessClient.NewSimpleClientset(trafficTarget),
+				smiSpecClient:   fakeSpecClient.NewSimpleClientset(httpRouteGroup, tcpRoute),
+			}
+
+			err := cmd.run()
+			if tc.expectedExitCode == 0 {
+				assert.Nil(err)
+			} else {
+				assert.NotNil(err)
+				assert.Equal(tc.expectedExitCode, getExitCode(err))
+			}
+			for _, expected := range tc.expectedOut {
+				assert.Contains(out.String(), expected)
+			}
+		})
+	}
+}
+
+func TestEvaluateHTTPMatch(t *testing.T) {
+	request := explainedRequest{
+		port:    80,
+		method:  "GET",
+		path:    "/api/v1/books",
+		headers: map[string]string{"x-version": "v2"},
+	}
+
+	testCases := []struct {
+		name            string
+		match           smiSpecs.HTTPMatch
+		expectedAllowed bool
+		expectedReason  string
+	}{
+		{
+			name:            "match without restrictions",
+			match:           smiSpecs.HTTPMatch{Name: "any"},
+			expectedAllowed: true,
+			expectedReason:  "route",
+		},
+		{
+			name:            "wildcard method and regex path",
+			match:           smiSpecs.HTTPMatch{Name: "api", Methods: []string{"*"}, PathRegex: "/api/v[0-9]+/.*"},
+			expectedAllowed: true,
+			expectedReason:  "route",
+		},
+		{
+			name:           "regex path matching a prefix only",
+			match:          smiSpecs.HTTPMatch{Name: "api", PathRegex: "/api"},
+			expectedReason: `route: path /api/v1/books does not match the path regex "/api"`,
+		},
+		{
+			name:            "alternation is anchored as a whole",
+			match:           smiSpecs.HTTPMatch{Name: "api", PathRegex: "/foo|/api/v1/books"},
+			expectedAllowed: true,
+			expectedReason:  "route",
+		},
+		{
+			name:           "invalid path regex",
+			match:          smiSpecs.HTTPMatch{Name: "api", PathRegex: "/api/(v1"},
+			expectedReason: `route: invalid path regex "/api/(v1": error parsing regexp: missing closing ): ` + "`/api/(v1`",
+		},
+		{
+			name:            "header regex",
+			match:           smiSpecs.HTTPMatch{Name: "api", Headers: map[string]string{"X-Version": "v[2-3]"}},
+			expectedAllowed: true,
+			expectedReason:  "route",
+		},
+		{
+			name:           "method not allowed",
+			match:          smiSpecs.HTTPMatch{Name: "api", Methods: []string{"PUT", "POST"}},
+			expectedReason: "route: method GET is not one of the allowed methods PUT,POST",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			verdict := evaluateHTTPMatch("route", tc.match, request)
+			assert.Equal(tc.expectedAllowed, verdict.allowed)
+			assert.Equal(tc.expectedReason, verdict.reason)
+		})
+	}
+}
@@ -0,0 +1,308 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiSpecs "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/specs/v1alpha4"
+	smiAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned"
+	smiSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/openservicemesh/osm/pkg/cli"
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+const trafficPolicyExportGraphDescription = `
+This command exports the graph of the communications allowed in the mesh, to
+be consumed by visualization tools.
+
+The nodes of the graph are the service accounts of the meshed pods and the
+service accounts referenced by the SMI TrafficTarget policies of the monitored
+namespaces. Each directed edge is a flow allowed from a source service account
+to a destination service account by a TrafficTarget, along with the routes
+referenced by the rules of the TrafficTarget. A rule referencing all the
+matches of an HTTPRouteGroup lists the names of the matches of the group.
+
+When the mesh operates in permissive traffic policy mode, every meshed
+identity is allowed to communicate with each other: the graph is fully
+connected among the service accounts of the meshed pods and its 'permissive'
+field is set to true.
+
+With -o dot, the graph is written in the Graphviz DOT language instead, to be
+rendered with e.g. 'dot -Tpng'. The service accounts are grouped by namespace,
+each edge is labeled with the routes referenced by its TrafficTarget, and the
+edges allowed by permissive traffic policy mode are dashed.
+`
+
+const trafficPolicyExportGraphExample = `
+# Export the graph of the mesh whose control plane runs in the 'osm-system' namespace
+osm policy export-graph -o json
+
+# Render the graph of the mesh as a PNG image with Graphviz
+osm policy export-graph -o dot | dot -Tpng -o mesh.png
+
+# Export the graph of the mesh named 'prod', whose configuration is held in the ConfigMap 'osm-config-prod'
+osm policy export-graph -o json --mesh-name prod --mesh-config-name osm-config-prod
+`
+
+type trafficPolicyExportGraphCmd struct {
+	out             io.Writer
+	output          string
+	meshName        string
+	meshConfigName  string
+	clientSet       kubernetes.Interface
+	smiAccessClient smiAccessClient.Interface
+	smiSpecClient   smiSpecClient.Interface
+}
+
+// meshGraph is the graph of the communications allowed in a mesh
+type meshGraph struct {
+	// Permissive is true when the mesh operates in permissive traffic policy mode
+	Permissive bool            `json:"permissive"`
+	Nodes      []meshGraphNode `json:"nodes"`
+	Edges      []meshGraphEdge `json:"edges"`
+}
+
+// meshGraphNode is a service account in the mesh, identified by its namespaced name
+type meshGraphNode struct {
+	ID        string `json:"id"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// meshGraphEdge is a communication allowed from a source node to a destination node. The TrafficTarget allowing the
+// communication and the routes referenced by its rules are not set in permissive traffic policy mode.
+type meshGraphEdge struct {
+	Source        string           `json:"source"`
+	Destination   string           `json:"destination"`
+	TrafficTarget string           `json:"trafficTarget,omitempty"`
+	Routes        []meshGraphRoute `json:"routes,omitempty"`
+}
+
+// meshGraphRoute is a route referenced by a rule of a TrafficTarget
+type meshGraphRoute struct {
+	Kind      string   `json:"kind"`
+	Namespace string   `json:"namespace"`
+	Name      string   `json:"name"`
+	Matches   []string `json:"matches,omitempty"`
+}
+
+func newTrafficPolicyExportGraphCmd(out io.Writer) *cobra.Command {
+	exportGraphCmd := &trafficPolicyExportGraphCmd{
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "export-graph",
+		Short: "export the graph of the communications allowed in the mesh",
+		Long:  trafficPolicyExportGraphDescription,
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			clients, err := cli.NewClients(settings)
+			if err != nil {
+				return err
+			}
+			exportGraphCmd.clientSet = clients.KubeClient
+			exportGraphCmd.smiAccessClient = clients.SMIAccessClient
+			exportGraphCmd.smiSpecClient = clients.SMISpecClient
+
+			return exportGraphCmd.run()
+		},
+		Example: trafficPolicyExportGraphExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&exportGraphCmd.output, "output", "o", outputFormatJSON, "Output format, one of: json, dot")
+	f.StringVar(&exportGraphCmd.meshName, "mesh-name", "", "Name of the mesh to export, the mesh running in the namespace given with --osm-namespace if unset")
+	f.StringVar(&exportGraphCmd.meshConfigName, "mesh-config-name", osmConfigMapName, "Name of the ConfigMap holding the configuration of the mesh")
+
+	return cmd
+}
+
+func (cmd *trafficPolicyExportGraphCmd) run() error {
+	switch cmd.output {
+	case outputFormatJSON, outputFormatDOT:
+	default:
+		return errors.Errorf("Invalid value %q for flag --output, expected one of: %s, %s", cmd.output, outputFormatJSON, outputFormatDOT)
+	}
+
+	graph, err := cmd.getMeshGraph()
+	if err != nil {
+		return err
+	}
+
+	if cmd.output == outputFormatDOT {
+		writeMeshGraphDOT(cmd.out, graph)
+		return nil
+	}
+
+	graphJSON, err := json.MarshalIndent(graph, "", "  ")
+	if err != nil {
+		return errors.Errorf("Error marshaling mesh graph: %s", err)
+	}
+	fmt.Fprintln(cmd.out, string(graphJSON))
+	return nil
+}
+
+// getMeshGraph returns the graph of the communications allowed among the monitored namespaces of the mesh
+func (cmd *trafficPolicyExportGraphCmd) getMeshGraph() (*meshGraph, error) {
+	osmNamespace, err := getMeshNamespace(cmd.clientSet, cmd.meshName)
+	if err != nil {
+		return nil, err
+	}
+	permissiveMode, err := getPermissiveTrafficPolicyMode(cmd.clientSet, osmNamespace, cmd.meshConfigName)
+	if err != nil {
+		return nil, errors.Errorf("Error checking if permissive mode is enabled: %s", err)
+	}
+
+	namespaces, err := cmd.listMonitoredNamespaces()
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &meshGraph{
+		Permissive: permissiveMode,
+		Nodes:      []meshGraphNode{},
+		Edges:      []meshGraphEdge{},
+	}
+	nodes := make(map[string]meshGraphNode)
+	addNode := func(namespace, name string) string {
+		node := meshGraphNode{ID: namespace + namespaceSeparator + name, Namespace: namespace, Name: name}
+		nodes[node.ID] = node
+		return node.ID
+	}
+
+	for _, namespace := range namespaces {
+		pods, err := cmd.clientSet.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return nil, errors.Errorf("Error listing pods in namespace %s: %s", namespace, err)
+		}
+		for _, pod := range pods.Items {
+			if isMeshedPod(pod) {
+				addNode(pod.Namespace, pod.Spec.ServiceAccountName)
+			}
+		}
+	}
+
+	if permissiveMode {
+		// Every meshed identity is allowed to communicate with each other
+		for source := range nodes {
+			for destination := range nodes {
+				if source != destination {
+					graph.Edges = append(graph.Edges, meshGraphEdge{Source: source, Destination: destination})
+				}
+			}
+		}
+	} else {
+		index, err := cmd.getTrafficPolicyIndex(namespaces)
+		if err != nil {
+			return nil, err
+		}
+
+		// The edges are evaluated against the index, which resolves the sources and route groups of each
+		// TrafficTarget once however many edges it allows
+		for destination, indexedTrafficTargets := range index.destinations {
+			destinationID := addNode(destination.Namespace, destination.Name)
+			for _, indexed := range indexedTrafficTargets {
+				trafficTarget := indexed.trafficTarget
+				routes := getMeshGraphRoutes(indexed)
+				for _, source := range trafficTarget.Spec.Sources {
+					if source.Kind != serviceAccountKind {
+						continue
+					}
+					graph.Edges = append(graph.Edges, meshGraphEdge{
+						Source:        addNode(source.Namespace, source.Name),
+						Destination:   destinationID,
+						TrafficTarget: trafficTarget.Namespace + namespaceSeparator + trafficTarget.Name,
+						Routes:        routes,
+					})
+				}
+			}
+		}
+	}
+
+	for _, node := range nodes {
+		graph.Nodes = append(graph.Nodes, node)
+	}
+	sort.Slice(graph.Nodes, func(i, j int) bool {
+		return graph.Nodes[i].ID < graph.Nodes[j].ID
+	})
+	sort.SliceStable(graph.Edges, func(i, j int) bool {
+		a, b := graph.Edges[i], graph.Edges[j]
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		if a.Destination != b.Destination {
+			return a.Destination < b.Destination
+		}
+		return a.TrafficTarget < b.TrafficTarget
+	})
+
+	return graph, nil
+}
+
+// getTrafficPolicyIndex returns the index of the SMI TrafficTargets of the given namespaces, whose rules are resolved
+// among the HTTPRouteGroups of these namespaces. The policies are listed once per namespace.
+func (cmd *trafficPolicyExportGraphCmd) getTrafficPolicyIndex(namespaces []string) (*trafficPolicyIndex, error) {
+	var trafficTargets []smiAccess.TrafficTarget
+	var routeGroups []smiSpecs.HTTPRouteGroup
+	for _, namespace := range namespaces {
+		namespaceTrafficTargets, err := cmd.smiAccessClient.AccessV1alpha3().TrafficTargets(namespace).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return nil, errors.Errorf("Error listing SMI TrafficTarget policies in namespace %s: %s", namespace, err)
+		}
+		trafficTargets = append(trafficTargets, namespaceTrafficTargets.Items...)
+
+		namespaceRouteGroups, err := cmd.smiSpecClient.SpecsV1alpha4().HTTPRouteGroups(namespace).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return nil, errors.Errorf("Error listing SMI HTTPRouteGroups in namespace %s: %s", namespace, err)
+		}
+		routeGroups = append(routeGroups, namespaceRouteGroups.Items...)
+	}
+	return newTrafficPolicyIndex(trafficTargets, routeGroups), nil
+}
+
+// getMeshGraphRoutes returns the routes referenced by the rules of the given TrafficTarget. The matches of a rule
+// referencing all the matches of an existing HTTPRouteGroup are resolved to the names of these matches.
+func getMeshGraphRoutes(indexed *indexedTrafficTarget) []meshGraphRoute {
+	var routes []meshGraphRoute
+	for _, rule := range indexed.trafficTarget.Spec.Rules {
+		matches := rule.Matches
+		if len(matches) == 0 {
+			for _, resolved := range indexed.resolveHTTPRouteMatches(rule) {
+				matches = append(matches, resolved.name)
+			}
+		}
+		routes = append(routes, meshGraphRoute{Kind: rule.Kind, Namespace: indexed.trafficTarget.Namespace, Name: rule.Name, Matches: matches})
+	}
+	return routes
+}
+
+// listMonitoredNamespaces returns the sorted names of the namespaces monitored by the mesh given with --mesh-name, or
+// by any mesh when no mesh name is set
+func (cmd *trafficPolicyExportGraphCmd) listMonitoredNamespaces() ([]string, error) {
+	selector := constants.OSMKubeResourceMonitorAnnotation
+	if cmd.meshName != "" {
+		selector = fmt.Sprintf("%s=%s", selector, cmd.meshName)
+	}
+
+	namespaces, err := cmd.clientSet.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, errors.Errorf("Error listing the monitored namespaces: %s", err)
+	}
+
+	var names []string
+	for _, namespace := range namespaces.Items {
+		names = append(names, namespace.Name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
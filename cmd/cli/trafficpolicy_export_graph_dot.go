@@ -0,0 +1,77 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// outputFormatDOT is the output format of the mesh graph in the Graphviz DOT language
+const outputFormatDOT = "dot"
+
+// writeMeshGraphDOT writes the given graph in the Graphviz DOT language, to be rendered with e.g. 'dot -Tpng'. The
+// service accounts are grouped by namespace in clusters, and each edge is labeled with the routes allowed by its
+// TrafficTarget. The edges allowed by permissive traffic policy mode are dashed.
+func writeMeshGraphDOT(out io.Writer, graph *meshGraph) {
+	fmt.Fprintln(out, "digraph mesh {")
+	fmt.Fprintln(out, "  rankdir=LR;")
+	fmt.Fprintln(out, "  node [shape=box];")
+
+	for i := 0; i < len(graph.Nodes); {
+		namespace := graph.Nodes[i].Namespace
+		fmt.Fprintf(out, "\n  subgraph %s {\n", dotQuote("cluster_"+namespace))
+		fmt.Fprintf(out, "    label=%s;\n", dotQuote(namespace))
+		// The nodes are sorted by ID, so the nodes of a namespace are consecutive
+		for ; i < len(graph.Nodes) && graph.Nodes[i].Namespace == namespace; i++ {
+			fmt.Fprintf(out, "    %s [label=%s];\n", dotQuote(graph.Nodes[i].ID), dotQuote(graph.Nodes[i].Name))
+		}
+		fmt.Fprintln(out, "  }")
+	}
+
+	if len(graph.Edges) > 0 {
+		fmt.Fprintln(out)
+	}
+	for _, edge := range graph.Edges {
+		var attributes []string
+		if graph.Permissive {
+			attributes = append(attributes, "style=dashed")
+		}
+		if len(edge.Routes) > 0 {
+			var routes []string
+			for _, route := range edge.Routes {
+				routes = append(routes, getMeshGraphRouteLabel(route))
+			}
+			// Escaped before joining, so that the line breaks are interpreted by Graphviz
+			attributes = append(attributes, fmt.Sprintf(`label="%s"`, strings.Join(routes, `\n`)))
+		}
+
+		fmt.Fprintf(out, "  %s -> %s", dotQuote(edge.Source), dotQuote(edge.Destination))
+		if len(attributes) > 0 {
+			fmt.Fprintf(out, " [%s]", strings.Join(attributes, ", "))
+		}
+		fmt.Fprintln(out, ";")
+	}
+
+	fmt.Fprintln(out, "}")
+}
+
+// getMeshGraphRouteLabel returns the escaped label of the given route in the DOT language, i.e. its kind and name
+// followed by the matches it is restricted to if any
+func getMeshGraphRouteLabel(route meshGraphRoute) string {
+	label := route.Kind + " " + route.Name
+	if len(route.Matches) > 0 {
+		label += " (" + strings.Join(route.Matches, ", ") + ")"
+	}
+	return dotEscape(label)
+}
+
+// dotQuote returns the given string as a quoted ID of the DOT language
+func dotQuote(s string) string {
+	return `"` + dotEscape(s) + `"`
+}
+
+// dotEscape escapes the backslashes and double quotes of the given string to be used in a quoted ID of the DOT
+// language
+func dotEscape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	fakeAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned/fake"
+	fakeSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned/fake"
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+	"github.com/openservicemesh/osm/pkg/identity"
+)
+
+func TestTrafficPolicyExportGraphDOT(t *testing.T) {
+	bookbuyer := identity.K8sServiceAccount{Namespace: "bookbuyer", Name: "bookbuyer"}
+
+	testCases := []struct {
+		name       string
+		permissive string
+		goldenFile string
+	}{
+		{
+			name:       "SMI traffic policy mode",
+			permissive: "false",
+			goldenFile: "smi.dot",
+		},
+		{
+			name:       "permissive traffic policy mode",
+			permissive: "true",
+			goldenFile: "permissive.dot",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			out := new(bytes.Buffer)
+			cmd := trafficPolicyExportGraphCmd{
+				out:    out,
+				output: outputFormatDOT,
+				clientSet: fake.NewSimpleClientset(
+					newNamespace("bookbuyer", nil),
+					newNamespace("bookstore", nil),
+					newTestPod("bookbuyer", "bookbuyer", "bookbuyer", true),
+					newTestPod("bookstore", "bookstore-v1", "bookstore-v1", true),
+					newTestPod("bookstore", "bookstore-v2", "bookstore-v2", true),
+					&corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{Namespace: settings.Namespace(), Name: osmConfigMapName},
+						Data:       map[string]string{configurator.PermissiveTrafficPolicyModeKey: tc.permissive},
+					},
+				),
+				smiAccessClient: fakeAccessClient.NewSimpleClientset(
+					newTestTrafficTarget("bookstore-v1", bookbuyer, identity.K8sServiceAccount{Namespace: "bookstore", Name: "bookstore-v1"},
+						smiAccess.TrafficTargetRule{Kind: httpRouteGroupKind, Name: "bookstore-routes", Matches: []string{"buy-a-book", "books-bought"}},
+						smiAccess.TrafficTargetRule{Kind: tcpRouteKind, Name: "bookstore-tcp"},
+					),
+					newTestTrafficTarget("bookstore-v2", bookbuyer, identity.K8sServiceAccount{Namespace: "bookstore", Name: "bookstore-v2"},
+						smiAccess.TrafficTargetRule{Kind: httpRouteGroupKind, Name: "bookstore-routes"},
+					),
+				),
+				smiSpecClient: fakeSpecClient.NewSimpleClientset(),
+			}
+			assert.Nil(cmd.run())
+
+			expected, err := ioutil.ReadFile(filepath.Join("testdata", "export-graph", tc.goldenFile))
+			assert.Nil(err)
+			assert.Equal(string(expected), out.String())
+		})
+	}
+}
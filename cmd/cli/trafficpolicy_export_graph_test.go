@@ -0,0 +1,144 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	fakeAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned/fake"
+	fakeSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned/fake"
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+	"github.com/openservicemesh/osm/pkg/identity"
+)
+
+func TestTrafficPolicyExportGraph(t *testing.T) {
+	getBooks := smiAccess.TrafficTargetRule{Kind: httpRouteGroupKind, Name: "routes", Matches: []string{"get-books"}}
+	sa1 := identity.K8sServiceAccount{Namespace: "ns-1", Name: "sa-1"}
+	test1 := newTestTrafficTarget("test-1", sa1, identity.K8sServiceAccount{Namespace: "ns-2", Name: "sa-2"}, getBooks)
+	test1.Spec.Sources = append(test1.Spec.Sources, smiAccess.IdentityBindingSubject{Kind: "Group", Name: "admins", Namespace: "ns-1"})
+	test2 := newTestTrafficTarget("test-2", sa1, identity.K8sServiceAccount{Namespace: "ns-3", Name: "sa-5"}, getBooks)
+
+	nodes := []meshGraphNode{
+		{ID: "ns-1/sa-1", Namespace: "ns-1", Name: "sa-1"},
+		{ID: "ns-2/sa-2", Namespace: "ns-2", Name: "sa-2"},
+		{ID: "ns-2/sa-3", Namespace: "ns-2", Name: "sa-3"},
+	}
+
+	testCases := []struct {
+		name          string
+		permissive    string
+		expectedGraph *meshGraph
+		expectedErr   string
+	}{
+		{
+			name:       "SMI traffic policy mode",
+			permissive: "false",
+			expectedGraph: &meshGraph{
+				Permissive: false,
+				Nodes:      nodes,
+				Edges: []meshGraphEdge{
+					{
+						Source:        "ns-1/sa-1",
+						Destination:   "ns-2/sa-2",
+						TrafficTarget: "ns-2/test-1",
+						Routes:        []meshGraphRoute{{Kind: httpRouteGroupKind, Namespace: "ns-2", Name: "routes", Matches: []string{"get-books"}}},
+					},
+				},
+			},
+		},
+		{
+			name:       "permissive traffic policy mode",
+			permissive: "true",
+			expectedGraph: &meshGraph{
+				Permissive: true,
+				Nodes:      nodes,
+				Edges: []meshGraphEdge{
+					{Source: "ns-1/sa-1", Destination: "ns-2/sa-2"},
+					{Source: "ns-1/sa-1", Destination: "ns-2/sa-3"},
+					{Source: "ns-2/sa-2", Destination: "ns-1/sa-1"},
+					{Source: "ns-2/sa-2", Destination: "ns-2/sa-3"},
+					{Source: "ns-2/sa-3", Destination: "ns-1/sa-1"},
+					{Source: "ns-2/sa-3", Destination: "ns-2/sa-2"},
+				},
+			},
+		},
+		{
+			name:        "invalid permissive traffic policy mode",
+			permissive:  "invalid-value",
+			expectedErr: "Error checking if permissive mode is enabled",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			cmd := trafficPolicyExportGraphCmd{
+				out: new(bytes.Buffer),
+				clientSet: fake.NewSimpleClientset(
+					newNamespace("ns-1", nil),
+					newNamespace("ns-2", nil),
+					&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "ns-3"}},
+					newTestPod("ns-1", "pod-1", "sa-1", true),
+					newTestPod("ns-2", "pod-2", "sa-2", true),
+					newTestPod("ns-2", "pod-3", "sa-3", true),
+					newTestPod("ns-2", "unmeshed", "sa-4", false),
+					newTestPod("ns-3", "pod-5", "sa-5", true),
+					&corev1.ConfigMap{
+						ObjectMeta: metav1.ObjectMeta{Namespace: settings.Namespace(), Name: osmConfigMapName},
+						Data:       map[string]string{configurator.PermissiveTrafficPolicyModeKey: tc.permissive},
+					},
+				),
+				smiAccessClient: fakeAccessClient.NewSimpleClientset(
+					test1,
+					// TrafficTargets of the namespaces that are not monitored are not part of the graph
+					test2,
+				),
+				smiSpecClient: fakeSpecClient.NewSimpleClientset(),
+			}
+
+			graph, err := cmd.getMeshGraph()
+			if tc.expectedErr != "" {
+				assert.NotNil(err)
+				assert.Contains(err.Error(), tc.expectedErr)
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(tc.expectedGraph, graph)
+		})
+	}
+}
+
+func TestTrafficPolicyExportGraphOutput(t *testing.T) {
+	assert := tassert.New(t)
+
+	out := new(bytes.Buffer)
+	cmd := trafficPolicyExportGraphCmd{
+		out:    out,
+		output: outputFormatJSON,
+		clientSet: fake.NewSimpleClientset(&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: settings.Namespace(), Name: osmConfigMapName},
+			Data:       map[string]string{configurator.PermissiveTrafficPolicyModeKey: "false"},
+		}),
+		smiAccessClient: fakeAccessClient.NewSimpleClientset(),
+		smiSpecClient:   fakeSpecClient.NewSimpleClientset(),
+	}
+	assert.Nil(cmd.run())
+	assert.Equal(`{
+  "permissive": false,
+  "nodes": [],
+  "edges": []
+}
+`, out.String())
+
+	cmd.output = "yaml"
+	err := cmd.run()
+	assert.NotNil(err)
+	assert.Equal("Invalid value \"yaml\" for flag --output, expected one of: json, dot", err.Error())
+}
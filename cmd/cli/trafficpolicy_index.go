@@ -0,0 +1,98 @@
+package main
+
+import (
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiSpecs "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/specs/v1alpha4"
+
+	"github.com/openservicemesh/osm/pkg/identity"
+)
+
+// trafficPolicyIndex indexes SMI TrafficTargets by destination service account, along with their sources and the
+// HTTPRouteGroups referenced by their rules. It is built once from the listed policies, so that mesh-wide analyses
+// evaluate the traffic allowed between every pair of service accounts with lookups, instead of scanning every
+// TrafficTarget and resolving its HTTPRouteGroups again for each pair.
+type trafficPolicyIndex struct {
+	destinations map[identity.K8sServiceAccount][]*indexedTrafficTarget
+}
+
+// indexedTrafficTarget is a TrafficTarget whose sources and HTTPRouteGroups are resolved
+type indexedTrafficTarget struct {
+	trafficTarget smiAccess.TrafficTarget
+	sources       map[identity.K8sServiceAccount]bool
+
+	// routeGroups maps the names of the HTTPRouteGroups referenced by the rules to the HTTPRouteGroups in the
+	// namespace of the TrafficTarget, or to nil when they do not exist
+	routeGroups map[string]*smiSpecs.HTTPRouteGroup
+}
+
+// newTrafficPolicyIndex returns the index of the given TrafficTargets, whose HTTPRouteGroup rules are resolved among
+// the given HTTPRouteGroups. TrafficTargets whose destination is not a service account are not indexed.
+func newTrafficPolicyIndex(trafficTargets []smiAccess.TrafficTarget, routeGroups []smiSpecs.HTTPRouteGroup) *trafficPolicyIndex {
+	namespacedRouteGroups := make(map[string]*smiSpecs.HTTPRouteGroup, len(routeGroups))
+	for i := range routeGroups {
+		namespacedRouteGroups[routeGroups[i].Namespace+namespaceSeparator+routeGroups[i].Name] = &routeGroups[i]
+	}
+
+	index := &trafficPolicyIndex{
+		destinations: make(map[identity.K8sServiceAccount][]*indexedTrafficTarget),
+	}
+	for _, trafficTarget := range trafficTargets {
+		spec := trafficTarget.Spec
+		if spec.Destination.Kind != serviceAccountKind {
+			continue
+		}
+
+		indexed := &indexedTrafficTarget{
+			trafficTarget: trafficTarget,
+			sources:       make(map[identity.K8sServiceAccount]bool, len(spec.Sources)),
+			routeGroups:   make(map[string]*smiSpecs.HTTPRouteGroup),
+		}
+		for _, source := range spec.Sources {
+			if source.Kind == serviceAccountKind {
+				indexed.sources[identity.K8sServiceAccount{Namespace: source.Namespace, Name: source.Name}] = true
+			}
+		}
+		for _, rule := range spec.Rules {
+			if rule.Kind == httpRouteGroupKind {
+				indexed.routeGroups[rule.Name] = namespacedRouteGroups[trafficTarget.Namespace+namespaceSeparator+rule.Name]
+			}
+		}
+
+		destination := identity.K8sServiceAccount{Namespace: spec.Destination.Namespace, Name: spec.Destination.Name}
+		index.destinations[destination] = append(index.destinations[destination], indexed)
+	}
+	return index
+}
+
+// getAllowingTrafficTargets returns the indexed TrafficTargets allowing the source service account to send traffic to
+// the destination service account, in the order they were indexed
+func (index *trafficPolicyIndex) getAllowingTrafficTargets(source, destination identity.K8sServiceAccount) []*indexedTrafficTarget {
+	var allowing []*indexedTrafficTarget
+	for _, indexed := range index.destinations[destination] {
+		if indexed.sources[source] {
+			allowing = append(allowing, indexed)
+		}
+	}
+	return allowing
+}
+
+// allows returns whether a TrafficTarget allows the source service account to send traffic to the destination
+// service account
+func (index *trafficPolicyIndex) allows(source, destination identity.K8sServiceAccount) bool {
+	for _, indexed := range index.destinations[destination] {
+		if indexed.sources[source] {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveHTTPRouteMatches returns the matches of the HTTPRouteGroup referenced by the given rule of the TrafficTarget,
+// following resolveHTTPRouteMatches, or nil if the HTTPRouteGroup does not exist
+func (indexed *indexedTrafficTarget) resolveHTTPRouteMatches(rule smiAccess.TrafficTargetRule) []resolvedHTTPMatch {
+	routeGroup := indexed.routeGroups[rule.Name]
+	if rule.Kind != httpRouteGroupKind || routeGroup == nil {
+		return nil
+	}
+	return resolveHTTPRouteMatches(routeGroup, rule.Matches)
+}
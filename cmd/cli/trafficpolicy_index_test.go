@@ -0,0 +1,132 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiSpecs "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/specs/v1alpha4"
+	tassert "github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openservicemesh/osm/pkg/identity"
+)
+
+func TestTrafficPolicyIndex(t *testing.T) {
+	trafficTargets := []smiAccess.TrafficTarget{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "bookstore", Namespace: "bookstore"},
+			Spec: smiAccess.TrafficTargetSpec{
+				Destination: smiAccess.IdentityBindingSubject{Kind: serviceAccountKind, Name: "bookstore", Namespace: "bookstore"},
+				Sources: []smiAccess.IdentityBindingSubject{
+					{Kind: serviceAccountKind, Name: "bookbuyer", Namespace: "bookbuyer"},
+					{Kind: "Group", Name: "bookthief", Namespace: "bookthief"},
+				},
+				Rules: []smiAccess.TrafficTargetRule{
+					{Kind: httpRouteGroupKind, Name: "bookstore-routes"},
+					{Kind: httpRouteGroupKind, Name: "bookstore-routes", Matches: []string{"buy-a-book"}},
+					{Kind: httpRouteGroupKind, Name: "missing-routes"},
+					{Kind: tcpRouteKind, Name: "bookstore-tcp"},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "group", Namespace: "bookstore"},
+			Spec: smiAccess.TrafficTargetSpec{
+				Destination: smiAccess.IdentityBindingSubject{Kind: "Group", Name: "bookstore", Namespace: "bookstore"},
+				Sources:     []smiAccess.IdentityBindingSubject{{Kind: serviceAccountKind, Name: "bookthief", Namespace: "bookthief"}},
+			},
+		},
+	}
+	routeGroups := []smiSpecs.HTTPRouteGroup{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "bookstore-routes", Namespace: "bookstore"},
+			Spec: smiSpecs.HTTPRouteGroupSpec{
+				Matches: []smiSpecs.HTTPMatch{{Name: "buy-a-book"}, {Name: "books-bought"}},
+			},
+		},
+		// HTTPRouteGroups are resolved in the namespace of the TrafficTarget
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "missing-routes", Namespace: "bookbuyer"},
+		},
+	}
+
+	bookbuyer := identity.K8sServiceAccount{Namespace: "bookbuyer", Name: "bookbuyer"}
+	bookthief := identity.K8sServiceAccount{Namespace: "bookthief", Name: "bookthief"}
+	bookstore := identity.K8sServiceAccount{Namespace: "bookstore", Name: "bookstore"}
+
+	assert := tassert.New(t)
+	index := newTrafficPolicyIndex(trafficTargets, routeGroups)
+
+	assert.True(index.allows(bookbuyer, bookstore))
+	assert.False(index.allows(bookstore, bookbuyer))
+	// Sources and destinations that are not service accounts are not indexed
+	assert.False(index.allows(bookthief, bookstore))
+
+	allowing := index.getAllowingTrafficTargets(bookbuyer, bookstore)
+	assert.Len(allowing, 1)
+	assert.Equal("bookstore", allowing[0].trafficTarget.Name)
+	assert.Empty(index.getAllowingTrafficTargets(bookthief, bookstore))
+
+	assert.Equal([]meshGraphRoute{
+		{Kind: httpRouteGroupKind, Namespace: "bookstore", Name: "bookstore-routes", Matches: []string{"buy-a-book", "books-bought"}},
+		{Kind: httpRouteGroupKind, Namespace: "bookstore", Name: "bookstore-routes", Matches: []string{"buy-a-book"}},
+		{Kind: httpRouteGroupKind, Namespace: "bookstore", Name: "missing-routes"},
+		{Kind: tcpRouteKind, Namespace: "bookstore", Name: "bookstore-tcp"},
+	}, getMeshGraphRoutes(allowing[0]))
+}
+
+// newBenchmarkTrafficTargets returns a TrafficTarget per service account of the given number, each allowing every
+// other service account, to benchmark the evaluation of every pair of service accounts
+func newBenchmarkTrafficTargets(serviceAccounts int) ([]smiAccess.TrafficTarget, []identity.K8sServiceAccount) {
+	var identities []identity.K8sServiceAccount
+	var sources []smiAccess.IdentityBindingSubject
+	for i := 0; i < serviceAccounts; i++ {
+		serviceAccount := identity.K8sServiceAccount{Namespace: fmt.Sprintf("ns-%d", i), Name: fmt.Sprintf("sa-%d", i)}
+		identities = append(identities, serviceAccount)
+		sources = append(sources, smiAccess.IdentityBindingSubject{Kind: serviceAccountKind, Namespace: serviceAccount.Namespace, Name: serviceAccount.Name})
+	}
+
+	var trafficTargets []smiAccess.TrafficTarget
+	for i, serviceAccount := range identities {
+		trafficTargets = append(trafficTargets, smiAccess.TrafficTarget{
+			ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("tt-%d", i), Namespace: serviceAccount.Namespace},
+			Spec: smiAccess.TrafficTargetSpec{
+				Destination: smiAccess.IdentityBindingSubject{Kind: serviceAccountKind, Namespace: serviceAccount.Namespace, Name: serviceAccount.Name},
+				Sources:     sources,
+			},
+		})
+	}
+	return trafficTargets, identities
+}
+
+// BenchmarkEvaluatePairsScan and BenchmarkEvaluatePairsIndex compare the evaluation of every pair of 200 service
+// accounts against 200 TrafficTargets by scanning the TrafficTargets for each pair, and by looking the pair up in the
+// index, which includes building it. Run them with:
+//
+//	go test ./cmd/cli -run '^$' -bench EvaluatePairs
+func BenchmarkEvaluatePairsScan(b *testing.B) {
+	trafficTargets, identities := newBenchmarkTrafficTargets(200)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for _, source := range identities {
+			srcPod := newServiceAccountPod(source)
+			for _, destination := range identities {
+				_ = getAllowingTrafficTargets(trafficTargets, srcPod, destination.Namespace, destination.Name)
+			}
+		}
+	}
+}
+
+func BenchmarkEvaluatePairsIndex(b *testing.B) {
+	trafficTargets, identities := newBenchmarkTrafficTargets(200)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		index := newTrafficPolicyIndex(trafficTargets, nil)
+		for _, source := range identities {
+			for _, destination := range identities {
+				_ = index.allows(source, destination)
+			}
+		}
+	}
+}
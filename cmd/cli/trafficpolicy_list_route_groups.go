@@ -0,0 +1,304 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiSpecs "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/specs/v1alpha4"
+	smiAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned"
+	smiSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openservicemesh/osm/pkg/cli"
+)
+
+const trafficPolicyListRouteGroupsDescription = `
+This command lists the SMI HTTPRouteGroups along with the SMI TrafficTarget
+policies referencing them, to help cleaning up unused route groups.
+
+A TrafficTarget references the HTTPRouteGroups named by its rules, in its own
+namespace, and optionally a subset of their matches. A route group referenced
+by no TrafficTarget is reported as orphaned, and a reference to a route group
+or to a match that does not exist is reported as dangling.
+
+The route groups of a single namespace can be listed with the --namespace
+flag, otherwise the route groups of every namespace are listed.
+`
+
+const trafficPolicyListRouteGroupsExample = `
+# List the HTTPRouteGroups of every namespace and the TrafficTargets referencing them
+osm policy list-route-groups
+
+# List the HTTPRouteGroups of the 'bookstore' namespace as JSON
+osm policy list-route-groups -n bookstore -o json
+`
+
+type trafficPolicyListRouteGroupsCmd struct {
+	out             io.Writer
+	namespace       string
+	output          string
+	smiAccessClient smiAccessClient.Interface
+	smiSpecClient   smiSpecClient.Interface
+}
+
+// routeGroupReferences are the HTTPRouteGroups along with the TrafficTargets referencing them
+type routeGroupReferences struct {
+	RouteGroups        []routeGroupInfo              `json:"routeGroups"`
+	DanglingReferences []danglingRouteGroupReference `json:"danglingReferences"`
+}
+
+// routeGroupInfo is an HTTPRouteGroup and the TrafficTargets referencing it, sorted by name
+type routeGroupInfo struct {
+	Namespace      string                `json:"namespace"`
+	Name           string                `json:"name"`
+	Matches        []string              `json:"matches"`
+	TrafficTargets []routeGroupReference `json:"trafficTargets"`
+	// Orphaned is true when the HTTPRouteGroup is referenced by no TrafficTarget
+	Orphaned bool `json:"orphaned"`
+}
+
+// routeGroupReference is a rule of a TrafficTarget referencing an HTTPRouteGroup, restricted to the given matches if
+// any
+type routeGroupReference struct {
+	TrafficTarget string   `json:"trafficTarget"`
+	Matches       []string `json:"matches,omitempty"`
+}
+
+// danglingRouteGroupReference is a rule of a TrafficTarget referencing an HTTPRouteGroup that does not exist, or a
+// match that the HTTPRouteGroup does not have when the match is set
+type danglingRouteGroupReference struct {
+	Namespace      string `json:"namespace"`
+	TrafficTarget  string `json:"trafficTarget"`
+	HTTPRouteGroup string `json:"httpRouteGroup"`
+	Match          string `json:"match,omitempty"`
+}
+
+func newTrafficPolicyListRouteGroupsCmd(out io.Writer) *cobra.Command {
+	listRouteGroupsCmd := &trafficPolicyListRouteGroupsCmd{
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "list-route-groups",
+		Short: "list the HTTPRouteGroups and the TrafficTargets referencing them",
+		Long:  trafficPolicyListRouteGroupsDescription,
+		Args:  cobra.NoArgs,
+		RunE: func(_ *cobra.Command, _ []string) error {
+			clients, err := cli.NewClients(settings)
+			if err != nil {
+				return err
+			}
+			listRouteGroupsCmd.smiAccessClient = clients.SMIAccessClient
+			listRouteGroupsCmd.smiSpecClient = clients.SMISpecClient
+
+			return listRouteGroupsCmd.run()
+		},
+		Example: trafficPolicyListRouteGroupsExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVarP(&listRouteGroupsCmd.namespace, "namespace", "n", metav1.NamespaceAll, "Namespace of the HTTPRouteGroups, all the namespaces if unset")
+	f.StringVarP(&listRouteGroupsCmd.output, "output", "o", "", "Output format, one of: json. A table is printed if unset")
+
+	return cmd
+}
+
+func (cmd *trafficPolicyListRouteGroupsCmd) run() error {
+	if cmd.output != "" && cmd.output != outputFormatJSON {
+		return errors.Errorf("Invalid value %q for flag --output, expected: %s", cmd.output, outputFormatJSON)
+	}
+
+	// The lookups of the policies are shared with 'osm policy check-pods'
+	checkCmd := &trafficPolicyCheckCmd{
+		out:             cmd.out,
+		smiAccessClient: cmd.smiAccessClient,
+		smiSpecClient:   cmd.smiSpecClient,
+	}
+
+	routeGroups, err := checkCmd.listHTTPRouteGroups(cmd.namespace)
+	if err != nil {
+		return err
+	}
+	trafficTargets, err := checkCmd.listTrafficTargets(cmd.namespace)
+	if err != nil {
+		return err
+	}
+
+	return printRouteGroupReferences(cmd.out, getRouteGroupReferences(routeGroups, trafficTargets), cmd.output)
+}
+
+// listHTTPRouteGroups returns the SMI HTTPRouteGroups in the given namespace, or in every namespace when empty
+func (cmd *trafficPolicyCheckCmd) listHTTPRouteGroups(namespace string) ([]smiSpecs.HTTPRouteGroup, error) {
+	routeGroups, err := cmd.listCache.get(namespace, httpRouteGroupKind, func() (interface{}, error) {
+		routeGroups, err := cmd.smiSpecClient.SpecsV1alpha4().HTTPRouteGroups(namespace).List(context.TODO(), metav1.ListOptions{})
+		if err != nil {
+			return nil, errors.Errorf("Error listing SMI HTTPRouteGroups: %s", err)
+		}
+		return routeGroups.Items, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return routeGroups.([]smiSpecs.HTTPRouteGroup), nil
+}
+
+// getRouteGroupReferences returns the given HTTPRouteGroups sorted by namespace and name, each with the given
+// TrafficTargets referencing it, along with the references of the TrafficTargets that do not resolve
+func getRouteGroupReferences(routeGroups []smiSpecs.HTTPRouteGroup, trafficTargets []smiAccess.TrafficTarget) routeGroupReferences {
+	references := routeGroupReferences{
+		RouteGroups:        []routeGroupInfo{},
+		DanglingReferences: []danglingRouteGroupReference{},
+	}
+
+	routeGroups = append([]smiSpecs.HTTPRouteGroup(nil), routeGroups...)
+	sort.Slice(routeGroups, func(i, j int) bool {
+		if routeGroups[i].Namespace != routeGroups[j].Namespace {
+			return routeGroups[i].Namespace < routeGroups[j].Namespace
+		}
+		return routeGroups[i].Name < routeGroups[j].Name
+	})
+	indexes := make(map[string]int)
+	for i := range routeGroups {
+		routeGroup := &routeGroups[i]
+		info := routeGroupInfo{
+			Namespace:      routeGroup.Namespace,
+			Name:           routeGroup.Name,
+			Matches:        []string{},
+			TrafficTargets: []routeGroupReference{},
+		}
+		for _, match := range routeGroup.Spec.Matches {
+			info.Matches = append(info.Matches, match.Name)
+		}
+		indexes[routeGroup.Namespace+namespaceSeparator+routeGroup.Name] = i
+		references.RouteGroups = append(references.RouteGroups, info)
+	}
+
+	trafficTargets = append([]smiAccess.TrafficTarget(nil), trafficTargets...)
+	sort.Slice(trafficTargets, func(i, j int) bool {
+		if trafficTargets[i].Namespace != trafficTargets[j].Namespace {
+			return trafficTargets[i].Namespace < trafficTargets[j].Namespace
+		}
+		return trafficTargets[i].Name < trafficTargets[j].Name
+	})
+	for _, trafficTarget := range trafficTargets {
+		for _, rule := range trafficTarget.Spec.Rules {
+			if rule.Kind != httpRouteGroupKind {
+				continue
+			}
+
+			index, ok := indexes[trafficTarget.Namespace+namespaceSeparator+rule.Name]
+			if !ok {
+				references.DanglingReferences = append(references.DanglingReferences, danglingRouteGroupReference{
+					Namespace:      trafficTarget.Namespace,
+					TrafficTarget:  trafficTarget.Name,
+					HTTPRouteGroup: rule.Name,
+				})
+				continue
+			}
+
+			info := &references.RouteGroups[index]
+			info.TrafficTargets = append(info.TrafficTargets, routeGroupReference{TrafficTarget: trafficTarget.Name, Matches: rule.Matches})
+			for _, resolved := range resolveHTTPRouteMatches(&routeGroups[index], rule.Matches) {
+				if resolved.match == nil {
+					references.DanglingReferences = append(references.DanglingReferences, danglingRouteGroupReference{
+						Namespace:      trafficTarget.Namespace,
+						TrafficTarget:  trafficTarget.Name,
+						HTTPRouteGroup: rule.Name,
+						Match:          resolved.name,
+					})
+				}
+			}
+		}
+	}
+
+	for i := range references.RouteGroups {
+		references.RouteGroups[i].Orphaned = len(references.RouteGroups[i].TrafficTargets) == 0
+	}
+	return references
+}
+
+// printRouteGroupReferences prints the given route group references in the given output format
+func printRouteGroupReferences(out io.Writer, references routeGroupReferences, output string) error {
+	if output == outputFormatJSON {
+		referencesJSON, err := json.MarshalIndent(references, "", "  ")
+		if err != nil {
+			return errors.Errorf("Error marshaling route groups: %s", err)
+		}
+		fmt.Fprintln(out, string(referencesJSON))
+		return nil
+	}
+
+	if len(references.RouteGroups) == 0 {
+		fmt.Fprintln(out, "No SMI HTTPRouteGroups found")
+	} else {
+		w := newTabWriter(out)
+		fmt.Fprintln(w, "NAMESPACE\tHTTP ROUTE GROUP\tMATCHES\tTRAFFIC TARGETS\t")
+		for _, info := range references.RouteGroups {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t\n", info.Namespace, info.Name, describeList(info.Matches), describeRouteGroupReferences(info.TrafficTargets))
+		}
+		_ = w.Flush()
+	}
+
+	var orphaned []string
+	for _, info := range references.RouteGroups {
+		if info.Orphaned {
+			orphaned = append(orphaned, info.Namespace+namespaceSeparator+info.Name)
+		}
+	}
+	if len(orphaned) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "[!] The following HTTPRouteGroups are not referenced by any SMI TrafficTarget policy:")
+		for _, routeGroup := range orphaned {
+			fmt.Fprintf(out, "    %s\n", routeGroup)
+		}
+	}
+
+	if len(references.DanglingReferences) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "[!] The following routes referenced by SMI TrafficTarget policies were not found:")
+		for _, reference := range references.DanglingReferences {
+			fmt.Fprintf(out, "    %s\n", reference)
+		}
+	}
+	return nil
+}
+
+// String returns a description of the dangling reference
+func (r danglingRouteGroupReference) String() string {
+	if r.Match != "" {
+		return fmt.Sprintf("Match %q of HTTPRouteGroup %s/%s referenced by SMI TrafficTarget policy %q: not found", r.Match, r.Namespace, r.HTTPRouteGroup, r.TrafficTarget)
+	}
+	return fmt.Sprintf("HTTPRouteGroup %s/%s referenced by SMI TrafficTarget policy %q: not found", r.Namespace, r.HTTPRouteGroup, r.TrafficTarget)
+}
+
+// describeRouteGroupReferences returns a description of the TrafficTargets referencing a route group, along with the
+// matches they are restricted to if any
+func describeRouteGroupReferences(references []routeGroupReference) string {
+	var descriptions []string
+	for _, reference := range references {
+		description := reference.TrafficTarget
+		if len(reference.Matches) > 0 {
+			description += " (" + strings.Join(reference.Matches, ",") + ")"
+		}
+		descriptions = append(descriptions, description)
+	}
+	if len(descriptions) == 0 {
+		return "-"
+	}
+	return strings.Join(descriptions, ", ")
+}
+
+// describeList returns the given values joined by commas, or '-' when there are none
+func describeList(values []string) string {
+	if len(values) == 0 {
+		return "-"
+	}
+	return strings.Join(values, ",")
+}
@@ -0,0 +1,163 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiSpecs "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/specs/v1alpha4"
+	fakeAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned/fake"
+	fakeSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned/fake"
+	tassert "github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openservicemesh/osm/pkg/identity"
+)
+
+func TestTrafficPolicyListRouteGroups(t *testing.T) {
+	newRouteGroup := func(namespace, name string, matches ...string) *smiSpecs.HTTPRouteGroup {
+		routeGroup := &smiSpecs.HTTPRouteGroup{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+		for _, match := range matches {
+			routeGroup.Spec.Matches = append(routeGroup.Spec.Matches, smiSpecs.HTTPMatch{Name: match})
+		}
+		return routeGroup
+	}
+	bookbuyer := identity.K8sServiceAccount{Namespace: "bookbuyer", Name: "bookbuyer"}
+	bookstore := identity.K8sServiceAccount{Namespace: "bookstore", Name: "bookstore"}
+	bookwarehouse := identity.K8sServiceAccount{Namespace: "bookwarehouse", Name: "bookwarehouse"}
+	routeGroups := []*smiSpecs.HTTPRouteGroup{
+		newRouteGroup("bookstore", "bookstore-routes", "buy-books", "restock"),
+		newRouteGroup("bookstore", "legacy-routes", "sell-books"),
+		newRouteGroup("bookwarehouse", "warehouse-routes"),
+	}
+	trafficTargets := []*smiAccess.TrafficTarget{
+		newTestTrafficTarget("restockers", bookbuyer, bookstore,
+			smiAccess.TrafficTargetRule{Kind: httpRouteGroupKind, Name: "bookstore-routes"},
+			smiAccess.TrafficTargetRule{Kind: httpRouteGroupKind, Name: "missing-routes"},
+		),
+		newTestTrafficTarget("buyers", bookbuyer, bookstore,
+			smiAccess.TrafficTargetRule{Kind: httpRouteGroupKind, Name: "bookstore-routes", Matches: []string{"buy-books", "missing-match"}},
+			smiAccess.TrafficTargetRule{Kind: tcpRouteKind, Name: "legacy-routes"},
+		),
+		// HTTPRouteGroups are referenced in the namespace of the TrafficTarget
+		newTestTrafficTarget("other-namespace", bookbuyer, bookwarehouse,
+			smiAccess.TrafficTargetRule{Kind: httpRouteGroupKind, Name: "legacy-routes"},
+		),
+	}
+
+	testCases := []struct {
+		name          string
+		namespace     string
+		output        string
+		expectErr     bool
+		expectedOut   []string
+		unexpectedOut []string
+	}{
+		{
+			name:      "route groups of every namespace",
+			namespace: metav1.NamespaceAll,
+			expectedOut: []string{
+				"NAMESPACE",
+				"buyers (buy-books,missing-match), restockers",
+				"[!] The following HTTPRouteGroups are not referenced by any SMI TrafficTarget policy:\n    bookstore/legacy-routes\n    bookwarehouse/warehouse-routes\n",
+				`    Match "missing-match" of HTTPRouteGroup bookstore/bookstore-routes referenced by SMI TrafficTarget policy "buyers": not found`,
+				`    HTTPRouteGroup bookstore/missing-routes referenced by SMI TrafficTarget policy "restockers": not found`,
+				`    HTTPRouteGroup bookwarehouse/legacy-routes referenced by SMI TrafficTarget policy "other-namespace": not found`,
+			},
+		},
+		{
+			name:      "route groups of a namespace",
+			namespace: "bookwarehouse",
+			expectedOut: []string{
+				"[!] The following HTTPRouteGroups are not referenced by any SMI TrafficTarget policy:\n    bookwarehouse/warehouse-routes\n",
+			},
+			unexpectedOut: []string{"bookstore-routes", "restockers"},
+		},
+		{
+			name:      "no route groups",
+			namespace: "bookbuyer",
+			expectedOut: []string{
+				"No SMI HTTPRouteGroups found\n",
+			},
+			unexpectedOut: []string{"[!]"},
+		},
+		{
+			name:      "invalid output",
+			namespace: metav1.NamespaceAll,
+			output:    "yaml",
+			expectErr: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			out := new(bytes.Buffer)
+			cmd := newTestListRouteGroupsCmd(out, tc.namespace, routeGroups, trafficTargets)
+			cmd.output = tc.output
+
+			err := cmd.run()
+			assert.Equal(tc.expectErr, err != nil)
+			for _, expected := range tc.expectedOut {
+				assert.Contains(out.String(), expected)
+			}
+			for _, unexpected := range tc.unexpectedOut {
+				assert.NotContains(out.String(), unexpected)
+			}
+		})
+	}
+
+	t.Run("JSON output", func(t *testing.T) {
+		assert := tassert.New(t)
+
+		out := new(bytes.Buffer)
+		cmd := newTestListRouteGroupsCmd(out, "bookstore", routeGroups, trafficTargets)
+		cmd.output = outputFormatJSON
+		assert.Nil(cmd.run())
+
+		var references routeGroupReferences
+		assert.Nil(json.Unmarshal(out.Bytes(), &references))
+		assert.Equal([]routeGroupInfo{
+			{
+				Namespace: "bookstore",
+				Name:      "bookstore-routes",
+				Matches:   []string{"buy-books", "restock"},
+				TrafficTargets: []routeGroupReference{
+					{TrafficTarget: "buyers", Matches: []string{"buy-books", "missing-match"}},
+					{TrafficTarget: "restockers"},
+				},
+			},
+			{
+				Namespace:      "bookstore",
+				Name:           "legacy-routes",
+				Matches:        []string{"sell-books"},
+				TrafficTargets: []routeGroupReference{},
+				Orphaned:       true,
+			},
+		}, references.RouteGroups)
+		assert.Equal([]danglingRouteGroupReference{
+			{Namespace: "bookstore", TrafficTarget: "buyers", HTTPRouteGroup: "bookstore-routes", Match: "missing-match"},
+			{Namespace: "bookstore", TrafficTarget: "restockers", HTTPRouteGroup: "missing-routes"},
+		}, references.DanglingReferences)
+	})
+}
+
+func newTestListRouteGroupsCmd(out *bytes.Buffer, namespace string, routeGroups []*smiSpecs.HTTPRouteGroup, trafficTargets []*smiAccess.TrafficTarget) *trafficPolicyListRouteGroupsCmd {
+	accessClient := fakeAccessClient.NewSimpleClientset()
+	for _, trafficTarget := range trafficTargets {
+		_ = accessClient.Tracker().Add(trafficTarget)
+	}
+	specClient := fakeSpecClient.NewSimpleClientset()
+	for _, routeGroup := range routeGroups {
+		_ = specClient.Tracker().Add(routeGroup)
+	}
+	return &trafficPolicyListRouteGroupsCmd{
+		out:             out,
+		namespace:       namespace,
+		smiAccessClient: accessClient,
+		smiSpecClient:   specClient,
+	}
+}
@@ -0,0 +1,349 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned"
+	smiSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned"
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/openservicemesh/osm/pkg/cli"
+)
+
+const trafficPolicyListRoutesDescription = `
+This command lists the HTTP routes of a destination that sources are allowed
+to call, grouped by the service account of the allowed sources. It is the
+destination-centric counterpart of 'osm policy check-pods', telling the owner
+of a service which sources can reach it and over which routes.
+
+The destination is given as <namespace/pod>, or as <pod> for the default
+namespace, and its routes are those allowed to its service account. With
+--destination-kind serviceaccount, the destination is given as
+<namespace/serviceaccount> instead.
+
+The routes are the matches of the HTTPRouteGroups referenced by the rules of
+the SMI TrafficTarget policies whose destination is the service account of the
+destination, where a rule referencing no match allows every match of its
+HTTPRouteGroup. TCPRoute rules are not listed, use 'osm policy check-pods' to
+list the TCP ports allowed to a source. Routes referenced by the policies but
+not found are reported separately.
+
+When the mesh operates in permissive traffic policy mode, every source is
+allowed to call every route of the destination, and no routes are listed.
+`
+
+const trafficPolicyListRoutesExample = `
+# List the HTTP routes of pod 'bookstore-v1-5ccf77f46d-rc5mg' in the 'bookstore' namespace allowed to each source
+osm policy list-routes bookstore/bookstore-v1-5ccf77f46d-rc5mg
+
+# List the HTTP routes allowed to service account 'bookstore' in the 'bookstore' namespace, as JSON
+osm policy list-routes bookstore/bookstore --destination-kind serviceaccount -o json
+`
+
+// destinationKindServiceAccount is the --destination-kind value listing the routes allowed to a service account
+const destinationKindServiceAccount = "serviceaccount"
+
+type trafficPolicyListRoutesCmd struct {
+	out             io.Writer
+	destination     string
+	destinationKind string
+	output          string
+	meshName        string
+	meshConfigName  string
+	clientSet       kubernetes.Interface
+	smiAccessClient smiAccessClient.Interface
+	smiSpecClient   smiSpecClient.Interface
+}
+
+// destinationRoutes are the HTTP routes of a destination service account allowed to its sources
+type destinationRoutes struct {
+	Namespace        string         `json:"namespace"`
+	ServiceAccount   string         `json:"serviceAccount"`
+	PermissiveMode   bool           `json:"permissiveMode"`
+	Sources          []sourceRoutes `json:"sources"`
+	UnresolvedRoutes []string       `json:"unresolvedRoutes,omitempty"`
+}
+
+// sourceRoutes are the HTTP routes a source service account is allowed to call
+type sourceRoutes struct {
+	Namespace      string         `json:"namespace"`
+	ServiceAccount string         `json:"serviceAccount"`
+	Routes         []allowedRoute `json:"routes"`
+}
+
+// allowedRoute is a match of an HTTPRouteGroup allowed by a TrafficTarget
+type allowedRoute struct {
+	TrafficTarget  string            `json:"trafficTarget"`
+	HTTPRouteGroup string            `json:"httpRouteGroup"`
+	Match          string            `json:"match"`
+	Methods        []string          `json:"methods,omitempty"`
+	PathRegex      string            `json:"pathRegex,omitempty"`
+	Headers        map[string]string `json:"headers,omitempty"`
+}
+
+func newTrafficPolicyListRoutesCmd(out io.Writer) *cobra.Command {
+	listRoutesCmd := &trafficPolicyListRoutesCmd{
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:   "list-routes DESTINATION",
+		Short: "list the HTTP routes of a destination allowed to each source",
+		Long:  trafficPolicyListRoutesDescription,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(_ *cobra.Command, args []string) error {
+			listRoutesCmd.destination = args[0]
+
+			clients, err := cli.NewClients(settings)
+			if err != nil {
+				return err
+			}
+			listRoutesCmd.clientSet = clients.KubeClient
+			listRoutesCmd.smiAccessClient = clients.SMIAccessClient
+			listRoutesCmd.smiSpecClient = clients.SMISpecClient
+
+			return listRoutesCmd.run()
+		},
+		Example: trafficPolicyListRoutesExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&listRoutesCmd.destinationKind, "destination-kind", destinationKindPod, fmt.Sprintf("Kind of the destination, one of: %s, %s", destinationKindPod, destinationKindServiceAccount))
+	f.StringVarP(&listRoutesCmd.output, "output", "o", "", "Output format, one of: json. The routes are printed as tables if unset")
+	f.StringVar(&listRoutesCmd.meshName, "mesh-name", "", "Name of the mesh whose configuration is checked, the mesh running in the namespace given with --osm-namespace if unset")
+	f.StringVar(&listRoutesCmd.meshConfigName, "mesh-config-name", osmConfigMapName, "Name of the ConfigMap holding the configuration of the mesh")
+
+	return cmd
+}
+
+func (cmd *trafficPolicyListRoutesCmd) run() error {
+	if cmd.output != "" && cmd.output != outputFormatJSON {
+		return errors.Errorf("Invalid value %q for flag --output, expected: %s", cmd.output, outputFormatJSON)
+	}
+
+	// The lookups of the pods, of the mesh and of the policies are shared with 'osm policy check-pods'
+	checkCmd := &trafficPolicyCheckCmd{
+		out:             cmd.out,
+		meshName:        cmd.meshName,
+		meshConfigName:  cmd.meshConfigName,
+		clientSet:       cmd.clientSet,
+		smiAccessClient: cmd.smiAccessClient,
+		smiSpecClient:   cmd.smiSpecClient,
+	}
+
+	namespace, serviceAccount, err := cmd.getDestinationServiceAccount(checkCmd)
+	if err != nil {
+		return err
+	}
+
+	permissiveMode, err := checkCmd.isPermissiveModeEnabled()
+	if err != nil {
+		return errors.Errorf("Error checking if permissive mode is enabled: %s", err)
+	}
+
+	routes := destinationRoutes{
+		Namespace:      namespace,
+		ServiceAccount: serviceAccount,
+		PermissiveMode: permissiveMode,
+		Sources:        []sourceRoutes{},
+	}
+	if !permissiveMode {
+		trafficTargets, err := checkCmd.listTrafficTargets(namespace)
+		if err != nil {
+			return err
+		}
+		if err := checkCmd.getDestinationRoutes(trafficTargets, &routes); err != nil {
+			return err
+		}
+	}
+
+	return printDestinationRoutes(cmd.out, routes, cmd.output)
+}
+
+// getDestinationServiceAccount returns the namespace and name of the service account of the destination
+func (cmd *trafficPolicyListRoutesCmd) getDestinationServiceAccount(checkCmd *trafficPolicyCheckCmd) (string, string, error) {
+	namespace, name, err := unmarshalNamespacedPod(cmd.destination)
+	if err != nil {
+		return "", "", errors.Errorf("Invalid argument specified for the destination: %s", err)
+	}
+
+	switch cmd.destinationKind {
+	case destinationKindPod:
+		if err := checkCmd.validateNamespace(namespace); err != nil {
+			return "", "", err
+		}
+		pod, err := checkCmd.getMeshedPod(namespace, name)
+		if err != nil {
+			return "", "", err
+		}
+		return namespace, pod.Spec.ServiceAccountName, nil
+
+	case destinationKindServiceAccount:
+		if err := checkCmd.validateNamespace(namespace); err != nil {
+			return "", "", err
+		}
+		_, err := cmd.clientSet.CoreV1().ServiceAccounts(namespace).Get(context.TODO(), name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			return "", "", errors.Errorf("Could not find service account %s in namespace %s", name, namespace)
+		}
+		if err != nil {
+			return "", "", errors.Errorf("Error fetching service account %s in namespace %s: %s", name, namespace, err)
+		}
+		return namespace, name, nil
+
+	default:
+		return "", "", errors.Errorf("Invalid value %q for flag --destination-kind, expected one of: %s, %s",
+			cmd.destinationKind, destinationKindPod, destinationKindServiceAccount)
+	}
+}
+
+// getDestinationRoutes adds to the given routes the HTTP routes allowed to each source by the TrafficTargets whose
+// destination is the service account of the routes. The sources are sorted by namespace and name, and their routes
+// by the name of the TrafficTarget allowing them.
+func (cmd *trafficPolicyCheckCmd) getDestinationRoutes(trafficTargets []smiAccess.TrafficTarget, routes *destinationRoutes) error {
+	trafficTargets = append([]smiAccess.TrafficTarget(nil), trafficTargets...)
+	sort.SliceStable(trafficTargets, func(i, j int) bool {
+		return trafficTargets[i].Name < trafficTargets[j].Name
+	})
+
+	sources := make(map[string]*sourceRoutes)
+	for _, trafficTarget := range trafficTargets {
+		dst := trafficTarget.Spec.Destination
+		if dst.Kind != serviceAccountKind || dst.Name != routes.ServiceAccount || dst.Namespace != routes.Namespace {
+			continue
+		}
+
+		allowedRoutes, unresolvedRoutes, err := cmd.getAllowedHTTPRoutes(trafficTarget)
+		if err != nil {
+			return err
+		}
+		routes.UnresolvedRoutes = append(routes.UnresolvedRoutes, unresolvedRoutes...)
+		if len(allowedRoutes) == 0 {
+			continue
+		}
+
+		for _, source := range trafficTarget.Spec.Sources {
+			if source.Kind != serviceAccountKind {
+				continue
+			}
+			key := source.Namespace + namespaceSeparator + source.Name
+			if _, ok := sources[key]; !ok {
+				sources[key] = &sourceRoutes{Namespace: source.Namespace, ServiceAccount: source.Name}
+			}
+			sources[key].Routes = append(sources[key].Routes, allowedRoutes...)
+		}
+	}
+
+	var keys []string
+	for key := range sources {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		routes.Sources = append(routes.Sources, *sources[key])
+	}
+	return nil
+}
+
+// getAllowedHTTPRoutes returns the matches of the HTTPRouteGroups referenced by the rules of the given TrafficTarget,
+// along with a description of the referenced HTTPRouteGroups and matches that are not found
+func (cmd *trafficPolicyCheckCmd) getAllowedHTTPRoutes(trafficTarget smiAccess.TrafficTarget) ([]allowedRoute, []string, error) {
+	var allowedRoutes []allowedRoute
+	var unresolvedRoutes []string
+	for _, rule := range trafficTarget.Spec.Rules {
+		if rule.Kind != httpRouteGroupKind {
+			continue
+		}
+
+		routeGroup, err := cmd.getHTTPRouteGroup(trafficTarget.Namespace, rule.Name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if routeGroup == nil {
+			unresolvedRoutes = append(unresolvedRoutes, fmt.Sprintf("HTTPRouteGroup %s referenced by SMI TrafficTarget policy %q: not found", rule.Name, trafficTarget.Name))
+			continue
+		}
+
+		for _, resolved := range resolveHTTPRouteMatches(routeGroup, rule.Matches) {
+			if resolved.match == nil {
+				unresolvedRoutes = append(unresolvedRoutes, fmt.Sprintf("Match %q of HTTPRouteGroup %s referenced by SMI TrafficTarget policy %q: not found", resolved.name, rule.Name, trafficTarget.Name))
+				continue
+			}
+			allowedRoutes = append(allowedRoutes, allowedRoute{
+				TrafficTarget:  trafficTarget.Name,
+				HTTPRouteGroup: rule.Name,
+				Match:          resolved.name,
+				Methods:        resolved.match.Methods,
+				PathRegex:      resolved.match.PathRegex,
+				Headers:        resolved.match.Headers,
+			})
+		}
+	}
+	return allowedRoutes, unresolvedRoutes, nil
+}
+
+// printDestinationRoutes prints the given routes in the given output format
+func printDestinationRoutes(out io.Writer, routes destinationRoutes, output string) error {
+	if output == outputFormatJSON {
+		routesJSON, err := json.MarshalIndent(routes, "", "  ")
+		if err != nil {
+			return errors.Errorf("Error marshaling routes: %s", err)
+		}
+		fmt.Fprintln(out, string(routesJSON))
+		return nil
+	}
+
+	destination := fmt.Sprintf("'%s/%s'", routes.Namespace, routes.ServiceAccount)
+	if routes.PermissiveMode {
+		fmt.Fprintf(out, "[+] Permissive mode enabled, every source is allowed to call every route of service account %s\n", destination)
+		return nil
+	}
+	if len(routes.Sources) == 0 {
+		fmt.Fprintf(out, "[-] No source is allowed to call HTTP routes of service account %s\n", destination)
+	}
+
+	for i, source := range routes.Sources {
+		if i > 0 {
+			fmt.Fprintln(out)
+		}
+		fmt.Fprintf(out, "[+] Service account '%s/%s' is allowed to call the following routes of service account %s:\n", source.Namespace, source.ServiceAccount, destination)
+		w := newTabWriter(out)
+		fmt.Fprintln(w, "TRAFFIC TARGET\tHTTP ROUTE GROUP\tMATCH\tMETHODS\tPATH REGEX\tHEADERS\t")
+		for _, route := range source.Routes {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t\n", route.TrafficTarget, route.HTTPRouteGroup, route.Match, describeMethods(route.Methods), describePathRegex(route.PathRegex), describeHeaders(route.Headers))
+		}
+		_ = w.Flush()
+	}
+
+	if len(routes.UnresolvedRoutes) > 0 {
+		fmt.Fprintln(out)
+		fmt.Fprintln(out, "[!] The following routes referenced by SMI TrafficTarget policies were not found:")
+		for _, route := range routes.UnresolvedRoutes {
+			fmt.Fprintf(out, "    %s\n", route)
+		}
+	}
+	return nil
+}
+
+// describeHeaders returns a description of the given headers of an HTTP match, sorted by name
+func describeHeaders(headers map[string]string) string {
+	if len(headers) == 0 {
+		return "-"
+	}
+	var descriptions []string
+	for name, regex := range headers {
+		descriptions = append(descriptions, fmt.Sprintf("%s=%s", name, regex))
+	}
+	sort.Strings(descriptions)
+	return strings.Join(descriptions, ",")
+}
@@ -0,0 +1,228 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiSpecs "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/specs/v1alpha4"
+	fakeAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned/fake"
+	fakeSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned/fake"
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+func TestTrafficPolicyListRoutes(t *testing.T) {
+	newMeshConfig := func(permissive string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: settings.Namespace(), Name: osmConfigMapName},
+			Data:       map[string]string{configurator.PermissiveTrafficPolicyModeKey: permissive},
+		}
+	}
+	dstPod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "bookstore-1",
+			Namespace: "bookstore",
+			Labels:    map[string]string{constants.EnvoyUniqueIDLabelName: "test"},
+		},
+		Spec: corev1.PodSpec{ServiceAccountName: "bookstore"},
+	}
+	trafficTargets := []*smiAccess.TrafficTarget{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "buyers", Namespace: "bookstore"},
+			Spec: smiAccess.TrafficTargetSpec{
+				Destination: smiAccess.IdentityBindingSubject{Kind: serviceAccountKind, Name: "bookstore", Namespace: "bookstore"},
+				Sources: []smiAccess.IdentityBindingSubject{
+					{Kind: serviceAccountKind, Name: "bookbuyer", Namespace: "bookbuyer"},
+					{Kind: serviceAccountKind, Name: "bookthief", Namespace: "bookthief"},
+				},
+				Rules: []smiAccess.TrafficTargetRule{
+					{Kind: httpRouteGroupKind, Name: "bookstore-routes", Matches: []string{"buy-books", "missing-match"}},
+					{Kind: tcpRouteKind, Name: "metrics"},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "restockers", Namespace: "bookstore"},
+			Spec: smiAccess.TrafficTargetSpec{
+				Destination: smiAccess.IdentityBindingSubject{Kind: serviceAccountKind, Name: "bookstore", Namespace: "bookstore"},
+				Sources:     []smiAccess.IdentityBindingSubject{{Kind: serviceAccountKind, Name: "bookbuyer", Namespace: "bookbuyer"}},
+				Rules: []smiAccess.TrafficTargetRule{
+					{Kind: httpRouteGroupKind, Name: "bookstore-routes"},
+					{Kind: httpRouteGroupKind, Name: "missing-routes"},
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-destination", Namespace: "bookstore"},
+			Spec: smiAccess.TrafficTargetSpec{
+				Destination: smiAccess.IdentityBindingSubject{Kind: serviceAccountKind, Name: "bookwarehouse", Namespace: "bookstore"},
+				Sources:     []smiAccess.IdentityBindingSubject{{Kind: serviceAccountKind, Name: "bookthief", Namespace: "bookthief"}},
+				Rules:       []smiAccess.TrafficTargetRule{{Kind: httpRouteGroupKind, Name: "bookstore-routes"}},
+			},
+		},
+	}
+	httpRouteGroup := &smiSpecs.HTTPRouteGroup{
+		ObjectMeta: metav1.ObjectMeta{Name: "bookstore-routes", Namespace: "bookstore"},
+		Spec: smiSpecs.HTTPRouteGroupSpec{
+			Matches: []smiSpecs.HTTPMatch{
+				{Name: "buy-books", Methods: []string{"GET"}, PathRegex: "/books"},
+				{Name: "restock", Methods: []string{"POST"}, PathRegex: "/restock", Headers: map[string]string{"user-agent": "restock-.*"}},
+			},
+		},
+	}
+
+	testCases := []struct {
+		name            string
+		destination     string
+		destinationKind string
+		permissive      string
+		expectErr       bool
+		expectedOut     []string
+		unexpectedOut   []string
+	}{
+		{
+			name:            "routes of a pod grouped by source",
+			destination:     "bookstore/bookstore-1",
+			destinationKind: destinationKindPod,
+			permissive:      "false",
+			expectErr:       false,
+			expectedOut: []string{
+				"[+] Service account 'bookbuyer/bookbuyer' is allowed to call the following routes of service account 'bookstore/bookstore':",
+				"TRAFFIC TARGET",
+				"user-agent=restock-.*",
+				"[+] Service account 'bookthief/bookthief' is allowed to call the following routes of service account 'bookstore/bookstore':",
+				`    Match "missing-match" of HTTPRouteGroup bookstore-routes referenced by SMI TrafficTarget policy "buyers": not found`,
+				`    HTTPRouteGroup missing-routes referenced by SMI TrafficTarget policy "restockers": not found`,
+			},
+			unexpectedOut: []string{"other-destination", "metrics"},
+		},
+		{
+			name:            "routes of a service account",
+			destination:     "bookstore/bookstore",
+			destinationKind: destinationKindServiceAccount,
+			permissive:      "false",
+			expectErr:       false,
+			expectedOut: []string{
+				"[+] Service account 'bookbuyer/bookbuyer' is allowed to call the following routes of service account 'bookstore/bookstore':",
+			},
+		},
+		{
+			name:            "no source allowed",
+			destination:     "bookstore/bookwarehouse-sa",
+			destinationKind: destinationKindServiceAccount,
+			permissive:      "false",
+			expectErr:       false,
+			expectedOut: []string{
+				"[-] No source is allowed to call HTTP routes of service account 'bookstore/bookwarehouse-sa'",
+			},
+		},
+		{
+			name:            "permissive traffic policy mode",
+			destination:     "bookstore/bookstore-1",
+			destinationKind: destinationKindPod,
+			permissive:      "true",
+			expectErr:       false,
+			expectedOut: []string{
+				"[+] Permissive mode enabled, every source is allowed to call every route of service account 'bookstore/bookstore'",
+			},
+		},
+		{
+			name:            "service account not found",
+			destination:     "bookstore/missing",
+			destinationKind: destinationKindServiceAccount,
+			permissive:      "false",
+			expectErr:       true,
+			expectedOut:     nil,
+		},
+		{
+			name:            "invalid destination kind",
+			destination:     "bookstore/bookstore-1",
+			destinationKind: destinationKindService,
+			permissive:      "false",
+			expectErr:       true,
+			expectedOut:     nil,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			out := new(bytes.Buffer)
+			cmd := newTestListRoutesCmd(out, tc.destination, tc.destinationKind, newMeshConfig(tc.permissive), dstPod, trafficTargets, httpRouteGroup)
+
+			err := cmd.run()
+			assert.Equal(tc.expectErr, err != nil)
+			for _, expected := range tc.expectedOut {
+				assert.Contains(out.String(), expected)
+			}
+			for _, unexpected := range tc.unexpectedOut {
+				assert.NotContains(out.String(), unexpected)
+			}
+		})
+	}
+
+	t.Run("JSON output", func(t *testing.T) {
+		assert := tassert.New(t)
+
+		out := new(bytes.Buffer)
+		cmd := newTestListRoutesCmd(out, "bookstore/bookstore-1", destinationKindPod, newMeshConfig("false"), dstPod, trafficTargets, httpRouteGroup)
+		cmd.output = outputFormatJSON
+		assert.Nil(cmd.run())
+
+		var routes destinationRoutes
+		assert.Nil(json.Unmarshal(out.Bytes(), &routes))
+		assert.Equal("bookstore", routes.Namespace)
+		assert.Equal("bookstore", routes.ServiceAccount)
+		assert.False(routes.PermissiveMode)
+		assert.Len(routes.UnresolvedRoutes, 2)
+		assert.Equal([]sourceRoutes{
+			{
+				Namespace:      "bookbuyer",
+				ServiceAccount: "bookbuyer",
+				Routes: []allowedRoute{
+					{TrafficTarget: "buyers", HTTPRouteGroup: "bookstore-routes", Match: "buy-books", Methods: []string{"GET"}, PathRegex: "/books"},
+					{TrafficTarget: "restockers", HTTPRouteGroup: "bookstore-routes", Match: "buy-books", Methods: []string{"GET"}, PathRegex: "/books"},
+					{TrafficTarget: "restockers", HTTPRouteGroup: "bookstore-routes", Match: "restock", Methods: []string{"POST"}, PathRegex: "/restock", Headers: map[string]string{"user-agent": "restock-.*"}},
+				},
+			},
+			{
+				Namespace:      "bookthief",
+				ServiceAccount: "bookthief",
+				Routes: []allowedRoute{
+					{TrafficTarget: "buyers", HTTPRouteGroup: "bookstore-routes", Match: "buy-books", Methods: []string{"GET"}, PathRegex: "/books"},
+				},
+			},
+		}, routes.Sources)
+	})
+}
+
+func newTestListRoutesCmd(out *bytes.Buffer, destination, destinationKind string, meshConfig *corev1.ConfigMap, dstPod *corev1.Pod, trafficTargets []*smiAccess.TrafficTarget, httpRouteGroup *smiSpecs.HTTPRouteGroup) *trafficPolicyListRoutesCmd {
+	accessClient := fakeAccessClient.NewSimpleClientset()
+	for _, trafficTarget := range trafficTargets {
+		_ = accessClient.Tracker().Add(trafficTarget)
+	}
+	return &trafficPolicyListRoutesCmd{
+		out:             out,
+		destination:     destination,
+		destinationKind: destinationKind,
+		meshConfigName:  osmConfigMapName,
+		clientSet: fake.NewSimpleClientset(
+			&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "bookstore"}},
+			&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "bookstore", Namespace: "bookstore"}},
+			&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "bookwarehouse-sa", Namespace: "bookstore"}},
+			dstPod,
+			meshConfig,
+		),
+		smiAccessClient: accessClient,
+		smiSpecClient:   fakeSpecClient.NewSimpleClientset(httpRouteGroup),
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"io"
+
+	"github.com/spf13/cobra"
+)
+
+const upgradeCmdDescription = `
+This command consists of subcommands related to upgrading the control plane of
+a mesh. The control plane is upgraded with 'osm mesh upgrade'.
+`
+
+func newUpgradeCmd(out io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "upgrade",
+		Short: "prepare the upgrade of the control plane",
+		Long:  upgradeCmdDescription,
+		Args:  cobra.NoArgs,
+	}
+	cmd.AddCommand(newUpgradeCheckCmd(out))
+
+	return cmd
+}
@@ -0,0 +1,319 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+	"github.com/openservicemesh/osm/pkg/constants"
+	"github.com/openservicemesh/osm/pkg/injector"
+)
+
+const upgradeCheckDescription = `
+This command checks whether the mesh is ready for its control plane to be
+upgraded to the version of this CLI, before running 'osm mesh upgrade'. It
+inventories the mesh and reports the following probes, with the same statuses
+as 'osm check':
+
+  Meshed pods: the number of meshed pods, i.e. of sidecar proxies, in the
+    namespaces monitored by the mesh
+  Envoy images: the Envoy images run by the sidecar proxies, which warns when
+    several images are in use, and fails when more than --max-envoy-images
+    images are in use, as the proxies were not restarted after previous
+    upgrades
+  Mesh config: the keys of the mesh config, which fails when keys are not
+    supported by the version of this CLI, as their settings would be ignored
+    by the upgraded control plane
+  Namespace settings: the annotations of the monitored namespaces and of the
+    meshed pods overriding the mesh config whose behavior depends on the
+    version of OSM or Envoy, which warns when any is set
+
+The meshed pods are the pods with the osm-proxy-uuid label. The label and the
+annotations take the annotation prefix the sidecar injector of the mesh is
+configured with, unless given with --annotation-prefix.
+
+The command prints a go/no-go summary, and exits with a non-zero exit code when
+any of the probes fails, i.e. when an issue blocks the upgrade.
+`
+
+const upgradeCheckExample = `
+# Check whether the mesh whose control plane runs in the 'osm-system' namespace is ready to be upgraded
+osm upgrade check
+
+# Check whether the mesh named 'prod' is ready to be upgraded, failing when more than 3 Envoy images are in use
+osm upgrade check --mesh-name prod --max-envoy-images 3
+`
+
+// defaultMaxEnvoyImages is the default number of Envoy images run by the sidecar proxies above which the upgrade is
+// blocked, i.e. the images of the current and of the previous version of OSM
+const defaultMaxEnvoyImages = 2
+
+// upgradeSensitiveAnnotations are the annotations of namespaces and pods overriding the mesh config whose behavior
+// depends on the version of OSM or Envoy, along with the reason why
+var upgradeSensitiveAnnotations = map[string]string{
+	constants.EnvoyImageAnnotation:    "pins the Envoy image, which is not upgraded along with the control plane",
+	constants.EnvoyLogLevelAnnotation: "overrides the Envoy log level, whose supported values depend on the version of OSM",
+}
+
+type upgradeCheckCmd struct {
+	out            io.Writer
+	clientSet      kubernetes.Interface
+	meshName       string
+	meshConfigName string
+	maxEnvoyImages int
+	// annotationPrefix is the prefix of the annotations overriding the mesh config and of the proxy UUID label
+	annotationPrefix string
+}
+
+func newUpgradeCheckCmd(out io.Writer) *cobra.Command {
+	checkCmd := &upgradeCheckCmd{
+		out: out,
+	}
+
+	cmd := &cobra.Command{
+		Use:         "check",
+		Short:       "check whether the mesh is ready to be upgraded",
+		Long:        upgradeCheckDescription,
+		Args:        cobra.NoArgs,
+		Annotations: map[string]string{skipVersionCheckAnnotation: "true"},
+		RunE: func(_ *cobra.Command, _ []string) error {
+			config, err := settings.RESTClientGetter().ToRESTConfig()
+			if err != nil {
+				return errors.Errorf("Error fetching kubeconfig: %s", err)
+			}
+
+			clientset, err := kubernetes.NewForConfig(config)
+			if err != nil {
+				return errors.Errorf("Could not access Kubernetes cluster, check kubeconfig: %s", err)
+			}
+			checkCmd.clientSet = clientset
+			return checkCmd.run()
+		},
+		Example: upgradeCheckExample,
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&checkCmd.meshName, "mesh-name", "", "Name of the mesh to check, the mesh running in the namespace given with --osm-namespace if unset")
+	f.StringVar(&checkCmd.meshConfigName, "mesh-config-name", osmConfigMapName, "Name of the ConfigMap holding the configuration of the mesh")
+	f.IntVar(&checkCmd.maxEnvoyImages, "max-envoy-images", defaultMaxEnvoyImages, "Number of Envoy images run by the sidecar proxies above which the upgrade is blocked")
+	f.StringVar(&checkCmd.annotationPrefix, "annotation-prefix", "", "Prefix of the annotations and the proxy UUID label, the prefix the sidecar injector of the mesh is configured with if unset")
+
+	return cmd
+}
+
+func (cmd *upgradeCheckCmd) run() error {
+	if cmd.maxEnvoyImages < 1 {
+		return errors.Errorf("Invalid value %d for flag --max-envoy-images, must be at least 1", cmd.maxEnvoyImages)
+	}
+
+	osmNamespace, err := getMeshNamespace(cmd.clientSet, cmd.meshName)
+	if err != nil {
+		return err
+	}
+	prefix, err := getAnnotationPrefix(cmd.clientSet, cmd.meshName, cmd.annotationPrefix)
+	if err != nil {
+		return err
+	}
+	cmd.annotationPrefix = prefix
+	namespaces, pods, err := cmd.listMeshedPods()
+	if err != nil {
+		return err
+	}
+
+	probes := []probe{
+		{name: "Meshed pods", run: func() probeResult { return checkMeshedPods(namespaces, pods) }},
+		{name: "Envoy images", run: func() probeResult { return checkEnvoyImages(pods, cmd.maxEnvoyImages) }},
+		{name: "Mesh config", run: func() probeResult { return cmd.checkMeshConfigKeys(osmNamespace) }},
+		{name: "Namespace settings", run: func() probeResult { return checkUpgradeSensitiveAnnotations(namespaces, pods, cmd.annotationPrefix) }},
+	}
+
+	failed, warned := runProbes(cmd.out, probes)
+	if failed > 0 {
+		fmt.Fprintf(cmd.out, "Upgrade readiness: no-go, %d blocking issues and %d warnings\n", failed, warned)
+		return errors.Errorf("%d of %d upgrade checks failed", failed, len(probes))
+	}
+	fmt.Fprintf(cmd.out, "Upgrade readiness: go, %d warnings\n", warned)
+	return nil
+}
+
+// listMeshedPods returns the namespaces monitored by the mesh given with --mesh-name, or by any mesh when no mesh name
+// is set, along with the meshed pods of these namespaces
+func (cmd *upgradeCheckCmd) listMeshedPods() ([]corev1.Namespace, []corev1.Pod, error) {
+	selector := constants.OSMKubeResourceMonitorAnnotation
+	if cmd.meshName != "" {
+		selector = fmt.Sprintf("%s=%s", selector, cmd.meshName)
+	}
+	namespaces, err := cmd.clientSet.CoreV1().Namespaces().List(context.TODO(), metav1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, nil, errors.Errorf("Error listing the monitored namespaces: %s", err)
+	}
+
+	var pods []corev1.Pod
+	for _, namespace := range namespaces.Items {
+		meshedPods, err := cmd.clientSet.CoreV1().Pods(namespace.Name).List(context.TODO(), metav1.ListOptions{
+			LabelSelector: injector.ProxyUUIDLabel(cmd.annotationPrefix),
+		})
+		if err != nil {
+			return nil, nil, errors.Errorf("Error listing pods in namespace %s: %s", namespace.Name, err)
+		}
+		pods = append(pods, meshedPods.Items...)
+	}
+	return namespaces.Items, pods, nil
+}
+
+// checkMeshedPods reports the number of meshed pods in the given monitored namespaces
+func checkMeshedPods(namespaces []corev1.Namespace, pods []corev1.Pod) probeResult {
+	if len(namespaces) == 0 {
+		return probeResult{
+			status:  probeStatusWarn,
+			message: "No monitored namespaces found",
+			hint:    "Check the mesh name given with --mesh-name, the namespaces of a mesh are listed by osm namespace list",
+		}
+	}
+	return probeResult{
+		status:  probeStatusPass,
+		message: fmt.Sprintf("%d meshed pods in %d monitored namespaces", len(pods), len(namespaces)),
+	}
+}
+
+// checkEnvoyImages reports the Envoy images run by the sidecar proxies of the given pods, it warns when several images
+// are in use and fails when more than 'maxImages' are in use
+func checkEnvoyImages(pods []corev1.Pod, maxImages int) probeResult {
+	podsByImage := make(map[string]int)
+	for _, pod := range pods {
+		if image := getProxyInfo(pod).EnvoyImage; image != "" {
+			podsByImage[image]++
+		}
+	}
+
+	var images []string
+	for image := range podsByImage {
+		images = append(images, image)
+	}
+	// Most used images first
+	sort.Slice(images, func(i, j int) bool {
+		if podsByImage[images[i]] != podsByImage[images[j]] {
+			return podsByImage[images[i]] > podsByImage[images[j]]
+		}
+		return images[i] < images[j]
+	})
+
+	switch {
+	case len(images) == 0:
+		return probeResult{
+			status:  probeStatusPass,
+			message: "No sidecar proxies found",
+		}
+	case len(images) == 1:
+		return probeResult{
+			status:  probeStatusPass,
+			message: fmt.Sprintf("All the sidecar proxies run image %s", images[0]),
+		}
+	}
+
+	var described []string
+	for _, image := range images {
+		described = append(described, fmt.Sprintf("%s (%d pods)", image, podsByImage[image]))
+	}
+	result := probeResult{
+		status:  probeStatusWarn,
+		message: fmt.Sprintf("%d Envoy images are in use: %s", len(images), strings.Join(described, ", ")),
+		hint:    "Restart the pods running an older Envoy image, ex. with kubectl rollout restart, so that all the proxies run the same version",
+	}
+	if len(images) > maxImages {
+		result.status = probeStatusFail
+		result.message = fmt.Sprintf("%s, more than the %d images allowed by --max-envoy-images", result.message, maxImages)
+	}
+	return result
+}
+
+// checkMeshConfigKeys fails when the mesh config has keys not supported by the version of this CLI
+func (cmd *upgradeCheckCmd) checkMeshConfigKeys(osmNamespace string) probeResult {
+	configMap, err := getMeshConfig(cmd.clientSet, osmNamespace, cmd.meshConfigName)
+	if err != nil {
+		return probeResult{
+			status:  probeStatusFail,
+			message: err.Error(),
+			hint:    fmt.Sprintf("Check that OSM is installed in namespace %s, or use --osm-namespace with the namespace of the control plane", osmNamespace),
+		}
+	}
+
+	supported := make(map[string]bool)
+	for _, key := range configurator.GetConfigMapKeys() {
+		supported[key] = true
+	}
+	var unsupported []string
+	for key := range configMap.Data {
+		if !supported[key] {
+			unsupported = append(unsupported, key)
+		}
+	}
+	sort.Strings(unsupported)
+
+	if len(unsupported) > 0 {
+		return probeResult{
+			status:  probeStatusFail,
+			message: fmt.Sprintf("Mesh config %s/%s has keys not supported by the upgraded version: %s", configMap.Namespace, configMap.Name, strings.Join(unsupported, ", ")),
+			hint:    "Remove these keys from the mesh config, their settings are ignored by the upgraded control plane",
+		}
+	}
+	return probeResult{
+		status:  probeStatusPass,
+		message: fmt.Sprintf("All the keys of mesh config %s/%s are supported by the upgraded version", configMap.Namespace, configMap.Name),
+	}
+}
+
+// checkUpgradeSensitiveAnnotations warns when the given namespaces or pods have annotations whose behavior depends on
+// the version of OSM or Envoy, the annotations taking the given annotation prefix
+func checkUpgradeSensitiveAnnotations(namespaces []corev1.Namespace, pods []corev1.Pod, annotationPrefix string) probeResult {
+	sensitive := make(map[string]string, len(upgradeSensitiveAnnotations))
+	for annotation, reason := range upgradeSensitiveAnnotations {
+		sensitive[injector.ResolveAnnotation(annotationPrefix, annotation)] = reason
+	}
+
+	var found []string
+	for _, namespace := range namespaces {
+		for _, annotation := range getUpgradeSensitiveAnnotations(namespace.Annotations, sensitive) {
+			found = append(found, fmt.Sprintf("namespace %s has annotation %s which %s", namespace.Name, annotation, sensitive[annotation]))
+		}
+	}
+	for _, pod := range pods {
+		for _, annotation := range getUpgradeSensitiveAnnotations(pod.Annotations, sensitive) {
+			found = append(found, fmt.Sprintf("pod %s/%s has annotation %s which %s", pod.Namespace, pod.Name, annotation, sensitive[annotation]))
+		}
+	}
+
+	if len(found) > 0 {
+		return probeResult{
+			status:  probeStatusWarn,
+			message: strings.Join(found, "; "),
+			hint:    "Review these annotations against the release notes of the upgraded version",
+		}
+	}
+	return probeResult{
+		status:  probeStatusPass,
+		message: "No namespace or pod overrides settings whose behavior depends on the version",
+	}
+}
+
+// getUpgradeSensitiveAnnotations returns the sorted annotations among the given annotations that are keys of the given
+// upgrade sensitive annotations
+func getUpgradeSensitiveAnnotations(annotations, sensitive map[string]string) []string {
+	var found []string
+	for annotation := range annotations {
+		if _, ok := sensitive[annotation]; ok {
+			found = append(found, annotation)
+		}
+	}
+	sort.Strings(found)
+	return found
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+func TestUpgradeCheck(t *testing.T) {
+	osmNamespace := settings.Namespace()
+	newMeshConfig := func(data map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: osmConfigMapName, Namespace: osmNamespace},
+			Data:       data,
+		}
+	}
+	newMeshedPod := func(namespace, name, envoyImage string, annotations map[string]string) *corev1.Pod {
+		pod := newTestPod(namespace, name, name, true)
+		pod.Annotations = annotations
+		pod.Spec.Containers = []corev1.Container{
+			{Name: "app", Image: "app"},
+			{Name: constants.EnvoyContainerName, Image: envoyImage},
+		}
+		return pod
+	}
+	meshConfig := newMeshConfig(map[string]string{configurator.PermissiveTrafficPolicyModeKey: "false", "envoy_log_level": "error"})
+
+	testCases := []struct {
+		name              string
+		objects           []runtime.Object
+		maxEnvoyImages    int
+		expectedOutSubstr []string
+		expectErr         bool
+	}{
+		{
+			name: "mesh ready to be upgraded",
+			objects: []runtime.Object{
+				meshConfig,
+				newNamespace("bookstore", nil),
+				newMeshedPod("bookstore", "bookstore-1", "envoy:v1", nil),
+				newMeshedPod("bookstore", "bookstore-2", "envoy:v1", nil),
+			},
+			expectedOutSubstr: []string{
+				"[pass] Meshed pods: 2 meshed pods in 1 monitored namespaces",
+				"[pass] Envoy images: All the sidecar proxies run image envoy:v1",
+				"[pass] Mesh config: All the keys of mesh config",
+				"[pass] Namespace settings",
+				"Upgrade readiness: go, 0 warnings",
+			},
+			expectErr: false,
+		},
+		{
+			name: "several Envoy images in use",
+			objects: []runtime.Object{
+				meshConfig,
+				newNamespace("bookstore", nil),
+				newMeshedPod("bookstore", "bookstore-1", "envoy:v1", nil),
+				newMeshedPod("bookstore", "bookstore-2", "envoy:v2", nil),
+				newMeshedPod("bookstore", "bookstore-3", "envoy:v2", nil),
+			},
+			expectedOutSubstr: []string{
+				"[warn] Envoy images: 2 Envoy images are in use: envoy:v2 (2 pods), envoy:v1 (1 pods)",
+				"Upgrade readiness: go, 1 warnings",
+			},
+			expectErr: false,
+		},
+		{
+			name: "more Envoy images in use than allowed",
+			objects: []runtime.Object{
+				meshConfig,
+				newNamespace("bookstore", nil),
+				newMeshedPod("bookstore", "bookstore-1", "envoy:v1", nil),
+				newMeshedPod("bookstore", "bookstore-2", "envoy:v2", nil),
+				newMeshedPod("bookstore", "bookstore-3", "envoy:v3", nil),
+			},
+			expectedOutSubstr: []string{
+				"[fail] Envoy images: 3 Envoy images are in use",
+				"more than the 2 images allowed by --max-envoy-images",
+				"Upgrade readiness: no-go, 1 blocking issues and 0 warnings",
+			},
+			expectErr: true,
+		},
+		{
+			name: "more Envoy images allowed",
+			objects: []runtime.Object{
+				meshConfig,
+				newNamespace("bookstore", nil),
+				newMeshedPod("bookstore", "bookstore-1", "envoy:v1", nil),
+				newMeshedPod("bookstore", "bookstore-2", "envoy:v2", nil),
+				newMeshedPod("bookstore", "bookstore-3", "envoy:v3", nil),
+			},
+			maxEnvoyImages: 3,
+			expectedOutSubstr: []string{
+				"[warn] Envoy images: 3 Envoy images are in use",
+				"Upgrade readiness: go, 1 warnings",
+			},
+			expectErr: false,
+		},
+		{
+			name: "mesh config keys not supported",
+			objects: []runtime.Object{
+				newMeshConfig(map[string]string{configurator.PermissiveTrafficPolicyModeKey: "false", "legacy_b": "true", "legacy_a": "true"}),
+				newNamespace("bookstore", nil),
+			},
+			expectedOutSubstr: []string{
+				fmt.Sprintf("[fail] Mesh config: Mesh config %s/%s has keys not supported by the upgraded version: legacy_a, legacy_b", osmNamespace, osmConfigMapName),
+				"Upgrade readiness: no-go",
+			},
+			expectErr: true,
+		},
+		{
+			name: "mesh config not found",
+			objects: []runtime.Object{
+				newNamespace("bookstore", nil),
+			},
+			expectedOutSubstr: []string{
+				"[fail] Mesh config:",
+				"Upgrade readiness: no-go",
+			},
+			expectErr: true,
+		},
+		{
+			name: "namespace and pod settings depending on the version",
+			objects: []runtime.Object{
+				meshConfig,
+				newNamespace("bookstore", map[string]string{constants.EnvoyLogLevelAnnotation: "debug"}),
+				newMeshedPod("bookstore", "bookstore-1", "envoy:v1", map[string]string{constants.EnvoyImageAnnotation: "envoy:v1"}),
+			},
+			expectedOutSubstr: []string{
+				"[warn] Namespace settings: namespace bookstore has annotation openservicemesh.io/envoy-log-level",
+				"pod bookstore/bookstore-1 has annotation openservicemesh.io/envoy-image",
+				"Upgrade readiness: go, 1 warnings",
+			},
+			expectErr: false,
+		},
+		{
+			name: "annotation prefix of the sidecar injector",
+			objects: func() []runtime.Object {
+				prefixedPod := newMeshedPod("bookstore", "bookstore-1", "envoy:v1", map[string]string{"mesh.example.com/envoy-image": "envoy:v1"})
+				prefixedPod.Labels = map[string]string{"mesh.example.com/" + constants.EnvoyUniqueIDLabelName: "bookstore-1-uuid"}
+				return []runtime.Object{
+					meshConfig,
+					newInjectorDeployment(osmNamespace, testMesh, "--annotation-prefix", "mesh.example.com"),
+					newNamespace("bookstore", map[string]string{constants.EnvoyLogLevelAnnotation: "debug"}),
+					prefixedPod,
+					newMeshedPod("bookstore", "bookstore-2", "envoy:v2", nil),
+				}
+			}(),
+			expectedOutSubstr: []string{
+				"[pass] Meshed pods: 1 meshed pods in 1 monitored namespaces",
+				"[pass] Envoy images: All the sidecar proxies run image envoy:v1",
+				"[warn] Namespace settings: pod bookstore/bookstore-1 has annotation mesh.example.com/envoy-image",
+				"Upgrade readiness: go, 1 warnings",
+			},
+			expectErr: false,
+		},
+		{
+			name: "no monitored namespaces",
+			objects: []runtime.Object{
+				meshConfig,
+			},
+			expectedOutSubstr: []string{
+				"[warn] Meshed pods: No monitored namespaces found",
+				"[pass] Envoy images: No sidecar proxies found",
+			},
+			expectErr: false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			maxEnvoyImages := tc.maxEnvoyImages
+			if maxEnvoyImages == 0 {
+				maxEnvoyImages = defaultMaxEnvoyImages
+			}
+
+			out := new(bytes.Buffer)
+			cmd := &upgradeCheckCmd{
+				out:            out,
+				clientSet:      fake.NewSimpleClientset(tc.objects...),
+				meshConfigName: osmConfigMapName,
+				maxEnvoyImages: maxEnvoyImages,
+			}
+
+			err := cmd.run()
+			assert.Equal(tc.expectErr, err != nil)
+			for _, substr := range tc.expectedOutSubstr {
+				assert.Contains(out.String(), substr)
+			}
+		})
+	}
+}
+
+func TestUpgradeCheckInvalidMaxEnvoyImages(t *testing.T) {
+	assert := tassert.New(t)
+
+	cmd := &upgradeCheckCmd{
+		out:            new(bytes.Buffer),
+		clientSet:      fake.NewSimpleClientset(),
+		maxEnvoyImages: 0,
+	}
+	assert.NotNil(cmd.run())
+}
@@ -2,11 +2,16 @@ package main
 
 import (
 	"bufio"
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 
 	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
 )
 
 // confirm displays a prompt `s` to the user and returns a bool indicating yes / no
@@ -61,3 +66,90 @@ func annotateErrorMessageWithActionableMessage(actionableMessage string, errMsgF
 
 	return errors.Errorf(errMsgFormat+actionableMessage, args...)
 }
+
+// levenshteinDistance returns the minimum number of single character insertions, deletions or substitutions
+// required to change string `a` into string `b`
+func levenshteinDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	// prev holds the distances between the first i-1 runes of `a` and every prefix of `b`
+	prev := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr := make([]int, len(rb)+1)
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = prev[j] + 1 // deletion
+			if insertion := curr[j-1] + 1; insertion < curr[j] {
+				curr[j] = insertion
+			}
+			if substitution := prev[j-1] + cost; substitution < curr[j] {
+				curr[j] = substitution
+			}
+		}
+		prev = curr
+	}
+
+	return prev[len(rb)]
+}
+
+// getClosestMatches returns the candidates within `maxDistance` edits of `s`, closest first
+func getClosestMatches(s string, candidates []string, maxDistance int) []string {
+	type match struct {
+		name     string
+		distance int
+	}
+
+	var matches []match
+	for _, candidate := range candidates {
+		if distance := levenshteinDistance(s, candidate); distance <= maxDistance {
+			matches = append(matches, match{name: candidate, distance: distance})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].distance < matches[j].distance
+	})
+
+	var names []string
+	for _, m := range matches {
+		names = append(names, m.name)
+	}
+	return names
+}
+
+// manifest is a document of YAML or JSON manifests, along with its type
+type manifest struct {
+	typeMeta metav1.TypeMeta
+	raw      []byte
+}
+
+// readManifests returns the documents of the given YAML or JSON manifests, in order. Empty and null documents, e.g.
+// the empty document before a leading '---', are skipped.
+func readManifests(in io.Reader) ([]manifest, error) {
+	var manifests []manifest
+	decoder := k8syaml.NewYAMLOrJSONDecoder(in, 4096)
+	for {
+		var raw json.RawMessage
+		if err := decoder.Decode(&raw); err == io.EOF {
+			return manifests, nil
+		} else if err != nil {
+			return nil, err
+		}
+		if trimmed := bytes.TrimSpace(raw); len(trimmed) == 0 || bytes.Equal(trimmed, []byte("null")) {
+			continue
+		}
+
+		var typeMeta metav1.TypeMeta
+		if err := json.Unmarshal(raw, &typeMeta); err != nil {
+			return nil, err
+		}
+		manifests = append(manifests, manifest{typeMeta: typeMeta, raw: raw})
+	}
+}
@@ -2,11 +2,79 @@ package main
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
 	tassert "github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+	"github.com/openservicemesh/osm/pkg/identity"
+	"github.com/openservicemesh/osm/pkg/tests"
 )
 
+// newTestPod returns a running pod of the given service account, meshed with a sidecar proxy whose UUID is derived
+// from the name of the pod if meshed is true
+func newTestPod(namespace, name, serviceAccount string, meshed bool) *corev1.Pod {
+	var labels map[string]string
+	if meshed {
+		labels = map[string]string{constants.EnvoyUniqueIDLabelName: name + "-uuid"}
+	}
+	pod := tests.NewPodFixture(namespace, name, serviceAccount, labels)
+	pod.Status.Phase = corev1.PodRunning
+	return &pod
+}
+
+// newNamespace returns a namespace monitored by the test mesh, with the given annotations
+func newNamespace(name string, annotations map[string]string) *corev1.Namespace {
+	ns := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   name,
+			Labels: map[string]string{constants.OSMKubeResourceMonitorAnnotation: testMesh},
+		},
+	}
+
+	if annotations != nil {
+		ns.Annotations = annotations
+	}
+
+	return ns
+}
+
+// newTestTrafficTarget returns a TrafficTarget in the namespace of the destination service account, allowing the
+// source service account to access the destination with the given rules
+func newTestTrafficTarget(name string, source, destination identity.K8sServiceAccount, rules ...smiAccess.TrafficTargetRule) *smiAccess.TrafficTarget {
+	return &smiAccess.TrafficTarget{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: destination.Namespace},
+		Spec: smiAccess.TrafficTargetSpec{
+			Destination: smiAccess.IdentityBindingSubject{Kind: serviceAccountKind, Name: destination.Name, Namespace: destination.Namespace},
+			Sources:     []smiAccess.IdentityBindingSubject{{Kind: serviceAccountKind, Name: source.Name, Namespace: source.Namespace}},
+			Rules:       rules,
+		},
+	}
+}
+
+// newInjectorDeployment returns the deployment of the sidecar injector of the given mesh, run with the given arguments
+func newInjectorDeployment(namespace, meshName string, args ...string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      injectorLabel,
+			Namespace: namespace,
+			Labels:    map[string]string{"app": injectorLabel, "meshName": meshName},
+		},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: injectorLabel, Args: args}},
+				},
+			},
+		},
+	}
+}
+
 func TestAnnotateErrorMessageWithActionableMessage(t *testing.T) {
 	assert := tassert.New(t)
 
@@ -73,3 +141,86 @@ func TestAnnotateErrorMessageWithOsmNamespace(t *testing.T) {
 		})
 	}
 }
+
+func TestLevenshteinDistance(t *testing.T) {
+	assert := tassert.New(t)
+
+	testCases := []struct {
+		a        string
+		b        string
+		distance int
+	}{
+		{"bookstore", "bookstore", 0},
+		{"bookstor", "bookstore", 1},
+		{"bookstroe", "bookstore", 2},
+		{"", "bookstore", 9},
+		{"bookbuyer", "", 9},
+		{"kitten", "sitting", 3},
+	}
+
+	for _, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing distance between %q and %q", tc.a, tc.b), func(t *testing.T) {
+			assert.Equal(tc.distance, levenshteinDistance(tc.a, tc.b))
+		})
+	}
+}
+
+func TestGetClosestMatches(t *testing.T) {
+	assert := tassert.New(t)
+
+	candidates := []string{"bookbuyer", "bookstore", "bookstore-v2", "default", "osm-system"}
+
+	// bookbuyer and bookstore-v2 are both 4 edits away from bookstor, ties keep the order of the candidates
+	assert.Equal([]string{"bookstore", "bookbuyer", "bookstore-v2"}, getClosestMatches("bookstor", candidates, 4))
+	assert.Equal([]string{"bookstore", "bookstore-v2", "bookbuyer"}, getClosestMatches("bookstor", []string{"bookstore-v2", "bookbuyer", "bookstore"}, 4))
+	assert.Equal([]string{"bookstore"}, getClosestMatches("bookstor", candidates, 3))
+	assert.Nil(getClosestMatches("kube-system", candidates, 3))
+}
+
+func TestReadManifests(t *testing.T) {
+	testCases := []struct {
+		name          string
+		manifests     string
+		expectedKinds []string
+		expectErr     bool
+	}{
+		{
+			name:          "documents with a leading separator",
+			manifests:     "---\napiVersion: v1\nkind: Namespace\n---\napiVersion: v1\nkind: Pod\n",
+			expectedKinds: []string{"Namespace", "Pod"},
+		},
+		{
+			name:          "empty and null documents are skipped",
+			manifests:     "---\n# comment\n---\nnull\n---\nkind: Service\n---\n",
+			expectedKinds: []string{"Service"},
+		},
+		{
+			name:          "JSON documents",
+			manifests:     `{"apiVersion": "v1", "kind": "Namespace"}`,
+			expectedKinds: []string{"Namespace"},
+		},
+		{
+			name:      "invalid document",
+			manifests: "kind: [Namespace\n",
+			expectErr: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			manifests, err := readManifests(strings.NewReader(tc.manifests))
+			if tc.expectErr {
+				assert.NotNil(err)
+				return
+			}
+			assert.Nil(err)
+			var kinds []string
+			for _, m := range manifests {
+				kinds = append(kinds, m.typeMeta.Kind)
+			}
+			assert.Equal(tc.expectedKinds, kinds)
+		})
+	}
+}
@@ -20,10 +20,11 @@ func PrintCliVersion(out io.Writer) {
 
 func newVersionCmd(out io.Writer) *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "version",
-		Short: "osm cli version",
-		Long:  versionHelp,
-		Args:  cobra.NoArgs,
+		Use:         "version",
+		Short:       "osm cli version",
+		Long:        versionHelp,
+		Annotations: map[string]string{skipVersionCheckAnnotation: "true"},
+		Args:        cobra.NoArgs,
 		Run: func(cmd *cobra.Command, args []string) {
 			PrintCliVersion(out)
 		},
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+	"github.com/openservicemesh/osm/pkg/version"
+)
+
+// skipVersionCheckAnnotation is the annotation of the commands that don't contact the control plane, or are expected
+// to run against a control plane of a different version, for which the version compatibility check is skipped
+const skipVersionCheckAnnotation = "skip-version-check"
+
+// checkVersionEnvVar is the env var enabling the version compatibility check when set to true, unless overridden with
+// --check-version
+const checkVersionEnvVar = "OSM_CHECK_VERSION"
+
+// versionCheckTimeout bounds the lookup of the version of the control plane, so that an unreachable cluster does not
+// delay the command itself
+const versionCheckTimeout = 5 * time.Second
+
+// isVersionCheckEnabled returns whether the version compatibility check is enabled with the OSM_CHECK_VERSION env var,
+// the check being disabled when the env var is unset or is not a boolean
+func isVersionCheckEnabled() bool {
+	enabled, _ := strconv.ParseBool(os.Getenv(checkVersionEnvVar))
+	return enabled
+}
+
+// warnOnVersionSkew prints a warning to the error stream of the given command when the version of the CLI is not
+// compatible with the version of the OSM control plane in the OSM namespace. The check is best effort, errors reaching
+// the cluster are left to the command itself to report.
+func warnOnVersionSkew(cmd *cobra.Command) {
+	if skipsVersionCheck(cmd) {
+		return
+	}
+
+	config, err := settings.RESTClientGetter().ToRESTConfig()
+	if err != nil {
+		return
+	}
+	clientSet, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return
+	}
+	warning, err := getVersionSkewWarning(clientSet, settings.Namespace(), version.Version)
+	if err != nil {
+		return
+	}
+	printVersionSkewWarning(cmd.ErrOrStderr(), warning)
+}
+
+// skipsVersionCheck returns whether the version compatibility check is skipped for the given command, because it or
+// one of its parents is annotated with skipVersionCheckAnnotation, or because it runs offline from a snapshot
+func skipsVersionCheck(cmd *cobra.Command) bool {
+	switch cmd.Name() {
+	case "help", cobra.ShellCompRequestCmd, cobra.ShellCompNoDescRequestCmd:
+		return true
+	}
+	for c := cmd; c != nil; c = c.Parent() {
+		if _, ok := c.Annotations[skipVersionCheckAnnotation]; ok {
+			return true
+		}
+	}
+	return cmd.Flags().Changed("from-snapshot")
+}
+
+func printVersionSkewWarning(out io.Writer, warning string) {
+	if warning != "" {
+		fmt.Fprintf(out, "Warning: %s, use a CLI matching the version of the control plane\n", warning)
+	}
+}
+
+// getVersionSkewWarning returns why the given CLI version is not compatible with the version of the OSM controller
+// deployed in the given namespace, or an empty string if the versions are compatible or can't be compared
+func getVersionSkewWarning(clientSet kubernetes.Interface, namespace, cliVersion string) (string, error) {
+	listOptions := metav1.ListOptions{
+		LabelSelector: labels.Set(map[string]string{"app": constants.OSMControllerName}).String(),
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), versionCheckTimeout)
+	defer cancel()
+	deployments, err := clientSet.AppsV1().Deployments(namespace).List(ctx, listOptions)
+	if err != nil {
+		return "", errors.Errorf("Error listing OSM controller deployments in namespace %s: %s", namespace, err)
+	}
+
+	for _, deployment := range deployments.Items {
+		controllerVersion := deployment.Labels[constants.OSMAppVersionLabelKey]
+		compatible, ok := isVersionCompatible(cliVersion, controllerVersion)
+		if ok && !compatible {
+			return fmt.Sprintf("osm CLI version %s is not compatible with version %s of the OSM controller in namespace %s",
+				cliVersion, controllerVersion, namespace), nil
+		}
+	}
+	return "", nil
+}
+
+// isVersionCompatible returns whether the given versions share the same major and minor versions, and whether both
+// versions could be parsed, e.g. development builds without a release version can't be compared
+func isVersionCompatible(cliVersion, controllerVersion string) (compatible bool, ok bool) {
+	cliMajor, cliMinor, err := parseMajorMinorVersion(cliVersion)
+	if err != nil {
+		return false, false
+	}
+	controllerMajor, controllerMinor, err := parseMajorMinorVersion(controllerVersion)
+	if err != nil {
+		return false, false
+	}
+	return cliMajor == controllerMajor && cliMinor == controllerMinor, true
+}
+
+// parseMajorMinorVersion returns the major and minor versions of the given semantic version, e.g. 'v0.8.3'
+func parseMajorMinorVersion(semver string) (int, int, error) {
+	parts := strings.SplitN(strings.TrimPrefix(semver, "v"), ".", 3)
+	if len(parts) < 2 {
+		return 0, 0, errors.Errorf("Invalid version %q", semver)
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return 0, 0, errors.Errorf("Invalid major version in %q: %s", semver, err)
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, errors.Errorf("Invalid minor version in %q: %s", semver, err)
+	}
+	return major, minor, nil
+}
@@ -0,0 +1,297 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/spf13/cobra"
+	tassert "github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+func newControllerDeployment(namespace, version string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      constants.OSMControllerName,
+			Namespace: namespace,
+			Labels: map[string]string{
+				"app":                           constants.OSMControllerName,
+				constants.OSMAppVersionLabelKey: version,
+			},
+		},
+	}
+}
+
+func TestIsVersionCompatible(t *testing.T) {
+	testCases := []struct {
+		name               string
+		cliVersion         string
+		controllerVersion  string
+		expectedCompatible bool
+		expectedOk         bool
+	}{
+		{
+			name:               "same version",
+			cliVersion:         "v0.8.3",
+			controllerVersion:  "v0.8.3",
+			expectedCompatible: true,
+			expectedOk:         true,
+		},
+		{
+			name:               "different patch versions",
+			cliVersion:         "v0.8.0",
+			controllerVersion:  "0.8.3",
+			expectedCompatible: true,
+			expectedOk:         true,
+		},
+		{
+			name:               "pre-release of the same minor version",
+			cliVersion:         "v0.8.0-rc.1",
+			controllerVersion:  "v0.8.3",
+			expectedCompatible: true,
+			expectedOk:         true,
+		},
+		{
+			name:               "different minor versions",
+			cliVersion:         "v0.7.0",
+			controllerVersion:  "v0.8.3",
+			expectedCompatible: false,
+			expectedOk:         true,
+		},
+		{
+			name:               "different major versions",
+			cliVersion:         "v1.8.0",
+			controllerVersion:  "v0.8.0",
+			expectedCompatible: false,
+			expectedOk:         true,
+		},
+		{
+			name:               "development CLI build",
+			cliVersion:         "dev",
+			controllerVersion:  "v0.8.3",
+			expectedCompatible: false,
+			expectedOk:         false,
+		},
+		{
+			name:               "controller without version",
+			cliVersion:         "v0.8.3",
+			controllerVersion:  "",
+			expectedCompatible: false,
+			expectedOk:         false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			compatible, ok := isVersionCompatible(tc.cliVersion, tc.controllerVersion)
+			assert.Equal(tc.expectedCompatible, compatible)
+			assert.Equal(tc.expectedOk, ok)
+		})
+	}
+}
+
+func TestGetVersionSkewWarning(t *testing.T) {
+	testCases := []struct {
+		name            string
+		objects         []runtime.Object
+		cliVersion      string
+		expectedWarning string
+	}{
+		{
+			name:            "no control plane",
+			cliVersion:      "v0.8.3",
+			expectedWarning: "",
+		},
+		{
+			name:            "compatible control plane",
+			objects:         []runtime.Object{newControllerDeployment("osm-system", "v0.8.1")},
+			cliVersion:      "v0.8.3",
+			expectedWarning: "",
+		},
+		{
+			name:            "incompatible control plane",
+			objects:         []runtime.Object{newControllerDeployment("osm-system", "v0.9.0")},
+			cliVersion:      "v0.8.3",
+			expectedWarning: "osm CLI version v0.8.3 is not compatible with version v0.9.0 of the OSM controller in namespace osm-system",
+		},
+		{
+			name:            "incompatible control plane in another namespace",
+			objects:         []runtime.Object{newControllerDeployment("other-osm-system", "v0.9.0")},
+			cliVersion:      "v0.8.3",
+			expectedWarning: "",
+		},
+		{
+			name:            "development CLI build",
+			objects:         []runtime.Object{newControllerDeployment("osm-system", "v0.9.0")},
+			cliVersion:      "dev",
+			expectedWarning: "",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			warning, err := getVersionSkewWarning(fake.NewSimpleClientset(tc.objects...), "osm-system", tc.cliVersion)
+			assert.Nil(err)
+			assert.Equal(tc.expectedWarning, warning)
+
+			out := new(bytes.Buffer)
+			printVersionSkewWarning(out, warning)
+			if tc.expectedWarning == "" {
+				assert.Empty(out.String())
+			} else {
+				assert.Contains(out.String(), tc.expectedWarning)
+			}
+		})
+	}
+}
+
+func TestSkipsVersionCheck(t *testing.T) {
+	root := &cobra.Command{Use: "osm"}
+	root.AddCommand(
+		newInjectorCmd(new(bytes.Buffer), new(bytes.Buffer)),
+		newUpgradeCmd(new(bytes.Buffer)),
+		newTrafficPolicyCmd(new(bytes.Buffer), new(bytes.Buffer)),
+		newVersionCmd(new(bytes.Buffer)),
+	)
+
+	testCases := []struct {
+		name         string
+		args         []string
+		expectedSkip bool
+	}{
+		{
+			name:         "command annotated to skip the check",
+			args:         []string{"version"},
+			expectedSkip: true,
+		},
+		{
+			name:         "offline render of the sidecar injection",
+			args:         []string{"injector", "render", "-f", "pod.yaml"},
+			expectedSkip: true,
+		},
+		{
+			name:         "upgrade readiness check",
+			args:         []string{"upgrade", "check"},
+			expectedSkip: true,
+		},
+		{
+			name:         "traffic policy check against the cluster",
+			args:         []string{"policy", "check-pods", "bookbuyer/bookbuyer", "bookstore/bookstore"},
+			expectedSkip: false,
+		},
+		{
+			name:         "traffic policy check from a snapshot",
+			args:         []string{"policy", "check-pods", "bookbuyer/bookbuyer", "bookstore/bookstore", "--from-snapshot", "snapshot"},
+			expectedSkip: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			cmd, args, err := root.Find(tc.args)
+			assert.Nil(err)
+			assert.Nil(cmd.ParseFlags(args))
+			assert.Equal(tc.expectedSkip, skipsVersionCheck(cmd))
+		})
+	}
+}
+
+func TestIsVersionCheckEnabled(t *testing.T) {
+	testCases := []struct {
+		name            string
+		env             string
+		expectedEnabled bool
+	}{
+		{
+			name:            "env var unset",
+			env:             "",
+			expectedEnabled: false,
+		},
+		{
+			name:            "env var set to true",
+			env:             "true",
+			expectedEnabled: true,
+		},
+		{
+			name:            "env var set to false",
+			env:             "false",
+			expectedEnabled: false,
+		},
+		{
+			name:            "env var not a boolean",
+			env:             "yes please",
+			expectedEnabled: false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			oldv, found := os.LookupEnv(checkVersionEnvVar)
+			defer func() {
+				if found {
+					assert.Nil(os.Setenv(checkVersionEnvVar, oldv))
+				} else {
+					assert.Nil(os.Unsetenv(checkVersionEnvVar))
+				}
+			}()
+			assert.Nil(os.Setenv(checkVersionEnvVar, tc.env))
+
+			assert.Equal(tc.expectedEnabled, isVersionCheckEnabled())
+		})
+	}
+}
+
+func TestChainPersistentPreRun(t *testing.T) {
+	assert := tassert.New(t)
+
+	var calls []string
+	root := &cobra.Command{Use: "osm"}
+	parent := &cobra.Command{
+		Use: "parent",
+		PersistentPreRun: func(cmd *cobra.Command, _ []string) {
+			calls = append(calls, "parent "+cmd.Name())
+		},
+	}
+	child := &cobra.Command{
+		Use: "child",
+		PersistentPreRunE: func(cmd *cobra.Command, _ []string) error {
+			calls = append(calls, "child "+cmd.Name())
+			return nil
+		},
+		RunE: func(*cobra.Command, []string) error { return nil },
+	}
+	sibling := &cobra.Command{
+		Use:  "sibling",
+		RunE: func(*cobra.Command, []string) error { return nil },
+	}
+	parent.AddCommand(child, sibling)
+	root.AddCommand(parent)
+	chainPersistentPreRun(root, func(cmd *cobra.Command) {
+		calls = append(calls, "root "+cmd.Name())
+	})
+
+	// The closest hook runs after the hook of the root command, once
+	root.SetArgs([]string{"parent", "child"})
+	assert.Nil(root.Execute())
+	assert.Equal([]string{"root child", "child child"}, calls)
+
+	calls = nil
+	root.SetArgs([]string{"parent", "sibling"})
+	assert.Nil(root.Execute())
+	assert.Equal([]string{"root sibling", "parent sibling"}, calls)
+}
@@ -14,6 +14,7 @@ import (
 	"strings"
 
 	"github.com/pkg/errors"
+	smiTrafficSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned"
 	"github.com/spf13/pflag"
 	admissionv1 "k8s.io/api/admissionregistration/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -208,8 +209,9 @@ func main() {
 	proxyRegistry := registry.NewProxyRegistry()
 	proxyRegistry.ReleaseCertificateHandler(certManager)
 
-	// Create the configMap validating webhook
-	if err := configurator.NewValidatingWebhook(kubeClient, certManager, osmNamespace, webhookConfigName, stop); err != nil {
+	// Create the configMap and TrafficTarget validating webhooks
+	smiSpecClient := smiTrafficSpecClient.NewForConfigOrDie(kubeConfig)
+	if err := configurator.NewValidatingWebhook(kubeClient, smiSpecClient, certManager, osmNamespace, webhookConfigName, stop); err != nil {
 		events.GenericEventRecorder().FatalEvent(err, events.InitializationError, "Error creating osm-config validating webhook")
 	}
 
@@ -70,6 +70,8 @@ func init() {
 	flags.IntVar(&injectorConfig.ListenPort, "webhook-port", constants.InjectorWebhookPort, "Webhook port for sidecar-injector")
 	flags.StringVar(&injectorConfig.InitContainerImage, "init-container-image", "", "InitContainer image")
 	flags.StringVar(&injectorConfig.SidecarImage, "sidecar-image", "", "Sidecar proxy Container image")
+	flags.BoolVar(&injectorConfig.NamespaceExclusionRequiresClusterIP, "namespace-exclusion-requires-cluster-ip", false, "Reject pods excluding outbound traffic to a namespace without a service having a cluster IP")
+	flags.StringVar(&injectorConfig.AnnotationPrefix, "annotation-prefix", injector.DefaultAnnotationPrefix, "Prefix of the keys of the OSM annotations read by the sidecar injector")
 
 	// Generic certificate manager/provider options
 	flags.StringVar(&certProviderKind, "certificate-manager", providers.TresorKind.String(), fmt.Sprintf("Certificate manager, one of [%v]", providers.ValidCertificateProviders))
@@ -229,6 +231,10 @@ func validateCLIParams() error {
 		return errors.Errorf("Please specify the sidecar image using --sidecar-image")
 	}
 
+	if err := injector.ValidateAnnotationPrefix(injectorConfig.AnnotationPrefix); err != nil {
+		return errors.Errorf("Please specify a valid annotation prefix using --annotation-prefix: %s", err)
+	}
+
 	if webhookConfigName == "" {
 		return errors.Errorf("Please specify the mutatingwebhookconfiguration name using --webhook-config-name value")
 	}
@@ -11,6 +11,7 @@ require (
 	github.com/docker/docker v1.4.2-0.20200203170920-46ec8731fbce
 	github.com/dustin/go-humanize v1.0.0
 	github.com/envoyproxy/go-control-plane v0.9.8
+	github.com/evanphx/json-patch v4.9.0+incompatible
 	github.com/fatih/color v1.10.0
 	github.com/go-logr/logr v0.2.1 // indirect
 	github.com/golang/mock v1.4.1
@@ -32,6 +33,7 @@ require (
 	github.com/onsi/gomega v1.11.0
 	github.com/pkg/browser v0.0.0-20180916011732-0a3d74bf9ce4
 	github.com/pkg/errors v0.9.1
+	github.com/pmezard/go-difflib v1.0.0
 	github.com/prometheus/client_golang v1.7.1
 	github.com/prometheus/common v0.10.0
 	github.com/rs/zerolog v1.18.0
@@ -58,6 +60,7 @@ require (
 	mvdan.cc/gofumpt v0.1.0 // indirect
 	sigs.k8s.io/controller-runtime v0.6.3
 	sigs.k8s.io/kind v0.9.0
+	sigs.k8s.io/yaml v1.2.0
 )
 
 replace (
@@ -1,6 +1,9 @@
 package v1alpha1
 
-import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
 
 // MeshConfig is the configuration for the service mesh overall
 // +genclient
@@ -23,10 +26,30 @@ type MeshConfigSpec struct {
 
 // SidecarSpec is the spec for OSM's sidecar configuration
 type SidecarSpec struct {
-	EnablePrivilegedInitContainer bool   `json:"enablePrivilegedInitContainer,omitempty" yaml:"enablePrivilegedInitContainer,omitempty"`
-	LogLevel                      string `json:"logLevel,omitempty" yaml:"logLevel,omitempty" default:"error"`
-	MaxDataPlaneConnections       int    `json:"maxMaxPlaneConnections,omitempty" yaml:"max_data_plane_connections,omitempty"`
-	ConfigResyncInterval          string `json:"configResyncInterval,omitempty" yaml:"config_resync_interval,omitempty"`
+	EnablePrivilegedInitContainer bool                 `json:"enablePrivilegedInitContainer,omitempty" yaml:"enablePrivilegedInitContainer,omitempty"`
+	LogLevel                      string               `json:"logLevel,omitempty" yaml:"logLevel,omitempty" default:"error"`
+	MaxDataPlaneConnections       int                  `json:"maxMaxPlaneConnections,omitempty" yaml:"max_data_plane_connections,omitempty"`
+	ConfigResyncInterval          string               `json:"configResyncInterval,omitempty" yaml:"config_resync_interval,omitempty"`
+	InjectorFailurePolicy         string               `json:"injectorFailurePolicy,omitempty" yaml:"injectorFailurePolicy,omitempty" default:"fail"`
+	ImagePullPolicy               string               `json:"imagePullPolicy,omitempty" yaml:"imagePullPolicy,omitempty" default:"Always"`
+	ImagePullSecrets              []string             `json:"imagePullSecrets,omitempty" yaml:"imagePullSecrets,omitempty"`
+	ProxyDrainTimeout             string               `json:"proxyDrainTimeout,omitempty" yaml:"proxyDrainTimeout,omitempty"`
+	InitContainerName             string               `json:"initContainerName,omitempty" yaml:"initContainerName,omitempty" default:"osm-init"`
+	PodLabels                     map[string]string    `json:"podLabels,omitempty" yaml:"podLabels,omitempty"`
+	PodAnnotations                map[string]string    `json:"podAnnotations,omitempty" yaml:"podAnnotations,omitempty"`
+	EnableCNI                     bool                 `json:"enableCNI,omitempty" yaml:"enableCNI,omitempty"`
+	Env                           map[string]string    `json:"env,omitempty" yaml:"env,omitempty"`
+	ServiceNodeTemplate           string               `json:"serviceNodeTemplate,omitempty" yaml:"serviceNodeTemplate,omitempty"`
+	ServiceClusterTemplate        string               `json:"serviceClusterTemplate,omitempty" yaml:"serviceClusterTemplate,omitempty"`
+	WaitForProxyReady             bool                 `json:"waitForProxyReady,omitempty" yaml:"waitForProxyReady,omitempty"`
+	ConfigPath                    string               `json:"configPath,omitempty" yaml:"configPath,omitempty"`
+	ProxyUID                      int                  `json:"proxyUID,omitempty" yaml:"proxyUID,omitempty"`
+	ProbeInitialDelaySeconds      int                  `json:"probeInitialDelaySeconds,omitempty" yaml:"probeInitialDelaySeconds,omitempty"`
+	ProbePeriodSeconds            int                  `json:"probePeriodSeconds,omitempty" yaml:"probePeriodSeconds,omitempty" default:"5"`
+	ProbeFailureThreshold         int                  `json:"probeFailureThreshold,omitempty" yaml:"probeFailureThreshold,omitempty" default:"24"`
+	CABundle                      string               `json:"caBundle,omitempty" yaml:"caBundle,omitempty"`
+	Volumes                       []corev1.Volume      `json:"volumes,omitempty" yaml:"volumes,omitempty"`
+	VolumeMounts                  []corev1.VolumeMount `json:"volumeMounts,omitempty" yaml:"volumeMounts,omitempty"`
 }
 
 // TrafficSpec is the spec for OSM's traffic management configuration
@@ -39,9 +62,11 @@ type TrafficSpec struct {
 
 // ObservabilitySpec is the spec for OSM's observability related configuration
 type ObservabilitySpec struct {
-	EnableDebugServer  bool        `json:"enableDebugServer,omitempty" yaml:"enableDebugServer,omitempty" default:"true"`
-	PrometheusScraping bool        `json:"prometheusScraping,omitempty" yaml:"prometheusScraping,omitempty" default:"true"`
-	Tracing            TracingSpec `json:"tracing,omitempty" yaml:"tracing,omitempty"`
+	EnableDebugServer    bool        `json:"enableDebugServer,omitempty" yaml:"enableDebugServer,omitempty" default:"true"`
+	PrometheusScraping   bool        `json:"prometheusScraping,omitempty" yaml:"prometheusScraping,omitempty" default:"true"`
+	PrometheusScrapePath string      `json:"prometheusScrapePath,omitempty" yaml:"prometheusScrapePath,omitempty" default:"/stats/prometheus"`
+	EnableProxyStatsTags bool        `json:"enableProxyStatsTags,omitempty" yaml:"enableProxyStatsTags,omitempty"`
+	Tracing              TracingSpec `json:"tracing,omitempty" yaml:"tracing,omitempty"`
 }
 
 // TracingSpec is the spec for OSM's tracing configuration
@@ -61,6 +61,21 @@ func listServiceNames(meshServices []service.MeshService) (serviceNames []string
 	return serviceNames
 }
 
+// getProxyUUIDLabel returns the proxy UUID the sidecar injector labeled the given pod with. The injector prefixes the
+// label with its annotation prefix when one is configured, which the controller does not know, so the label is
+// looked up under any prefix.
+func getProxyUUIDLabel(pod *v1.Pod) (string, bool) {
+	if uuid, ok := pod.Labels[constants.EnvoyUniqueIDLabelName]; ok {
+		return uuid, true
+	}
+	for key, uuid := range pod.Labels {
+		if strings.HasSuffix(key, "/"+constants.EnvoyUniqueIDLabelName) {
+			return uuid, true
+		}
+	}
+	return "", false
+}
+
 // GetPodFromCertificate returns the Kubernetes Pod object for a given certificate.
 func GetPodFromCertificate(cn certificate.CommonName, kubecontroller k8s.Controller) (*v1.Pod, error) {
 	cnMeta, err := getCertificateCommonNameMeta(cn)
@@ -75,7 +90,7 @@ func GetPodFromCertificate(cn certificate.CommonName, kubecontroller k8s.Control
 		if pod.Namespace != cnMeta.Namespace {
 			continue
 		}
-		if uuid, labelFound := pod.Labels[constants.EnvoyUniqueIDLabelName]; labelFound && uuid == cnMeta.ProxyUUID.String() {
+		if uuid, labelFound := getProxyUUIDLabel(pod); labelFound && uuid == cnMeta.ProxyUUID.String() {
 			pods = append(pods, *pod)
 		}
 	}
@@ -166,6 +166,26 @@ var _ = Describe("Test XDS certificate tooling", func() {
 		})
 	})
 
+	Context("Test GetPodFromCertificate()", func() {
+		It("finds the pod labeled with a prefixed proxy UUID label", func() {
+			proxyUUID := uuid.New()
+			namespace := uuid.New().String()
+			mockKubeController := k8s.NewMockController(mockCtrl)
+
+			newPod := tests.NewPodFixture(namespace, uuid.New().String(), tests.BookstoreServiceAccountName, map[string]string{
+				tests.SelectorKey: tests.SelectorValue,
+				"mesh.example.com/" + constants.EnvoyUniqueIDLabelName: proxyUUID.String(),
+			})
+
+			newCN := certificate.CommonName(fmt.Sprintf("%s.%s.%s", proxyUUID, tests.BookstoreServiceAccountName, namespace))
+
+			mockKubeController.EXPECT().ListPods().Return([]*v1.Pod{&newPod})
+			actualPod, err := GetPodFromCertificate(newCN, mockKubeController)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(actualPod).To(Equal(&newPod))
+		})
+	})
+
 	Context("Test GetPodFromCertificate()", func() {
 		It("fails with invalid certificate", func() {
 			namespace := uuid.New().String()
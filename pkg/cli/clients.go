@@ -0,0 +1,76 @@
+package cli
+
+import (
+	"github.com/pkg/errors"
+	smiAccessClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/access/clientset/versioned"
+	smiSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned"
+	smiSplitClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/split/clientset/versioned"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	configClient "github.com/openservicemesh/osm/pkg/gen/client/config/clientset/versioned"
+	policyClient "github.com/openservicemesh/osm/pkg/gen/client/policy/clientset/versioned"
+)
+
+// Clients holds the typed clients used by the OSM cli to access the cluster, along with the REST config they are
+// created from
+type Clients struct {
+	// RESTConfig is the config the clients are created from, for commands sending requests of their own, e.g. through
+	// port forwarding
+	RESTConfig *rest.Config
+
+	// KubeClient is the client of the Kubernetes API
+	KubeClient kubernetes.Interface
+
+	// SMIAccessClient is the client of the SMI Access API, e.g. for TrafficTarget policies
+	SMIAccessClient smiAccessClient.Interface
+
+	// SMISpecClient is the client of the SMI Specs API, e.g. for HTTPRouteGroup and TCPRoute policies
+	SMISpecClient smiSpecClient.Interface
+
+	// SMISplitClient is the client of the SMI Split API, e.g. for TrafficSplit policies
+	SMISplitClient smiSplitClient.Interface
+
+	// PolicyClient is the client of the OSM Policy API, e.g. for Egress policies
+	PolicyClient policyClient.Interface
+
+	// ConfigClient is the client of the OSM Config API, e.g. for MeshConfigs
+	ConfigClient configClient.Interface
+}
+
+// NewClients returns the clients accessing the cluster of the kubeconfig given by the environment settings
+func NewClients(settings *EnvSettings) (*Clients, error) {
+	config, err := settings.RESTClientGetter().ToRESTConfig()
+	if err != nil {
+		return nil, errors.Errorf("Error fetching kubeconfig: %s", err)
+	}
+	return NewClientsForConfig(config)
+}
+
+// NewClientsForConfig returns the clients accessing the cluster of the given REST config, for commands customizing the
+// config of the environment settings, e.g. to impersonate a user
+func NewClientsForConfig(config *rest.Config) (*Clients, error) {
+	clients := &Clients{RESTConfig: config}
+	var err error
+
+	if clients.KubeClient, err = kubernetes.NewForConfig(config); err != nil {
+		return nil, errors.Errorf("Could not access Kubernetes cluster, check kubeconfig: %s", err)
+	}
+	if clients.SMIAccessClient, err = smiAccessClient.NewForConfig(config); err != nil {
+		return nil, errors.Errorf("Could not initialize SMI Access client: %s", err)
+	}
+	if clients.SMISpecClient, err = smiSpecClient.NewForConfig(config); err != nil {
+		return nil, errors.Errorf("Could not initialize SMI Specs client: %s", err)
+	}
+	if clients.SMISplitClient, err = smiSplitClient.NewForConfig(config); err != nil {
+		return nil, errors.Errorf("Could not initialize SMI Split client: %s", err)
+	}
+	if clients.PolicyClient, err = policyClient.NewForConfig(config); err != nil {
+		return nil, errors.Errorf("Could not initialize OSM Policy client: %s", err)
+	}
+	if clients.ConfigClient, err = configClient.NewForConfig(config); err != nil {
+		return nil, errors.Errorf("Could not initialize MeshConfig client: %s", err)
+	}
+
+	return clients, nil
+}
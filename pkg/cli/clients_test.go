@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+	"k8s.io/client-go/rest"
+)
+
+const testKubeConfig = `
+apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: https://test-cluster:6443
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+current-context: test
+users:
+- name: test
+  user:
+    token: test-token
+`
+
+func TestNewClients(t *testing.T) {
+	assert := tassert.New(t)
+
+	tmp, err := ioutil.TempDir(os.TempDir(), "osm-test")
+	assert.Nil(err)
+	defer func() {
+		if err := os.RemoveAll(tmp); err != nil {
+			t.Log("error cleaning up temp dir:", err)
+		}
+	}()
+
+	kubeConfigPath := filepath.Join(tmp, "kubeconfig")
+	assert.Nil(ioutil.WriteFile(kubeConfigPath, []byte(testKubeConfig), 0600))
+
+	env := New()
+	env.config.KubeConfig = &kubeConfigPath
+
+	clients, err := NewClients(env)
+	assert.Nil(err)
+	assert.Equal("https://test-cluster:6443", clients.RESTConfig.Host)
+	assert.Equal("test-token", clients.RESTConfig.BearerToken)
+	assert.NotNil(clients.KubeClient)
+	assert.NotNil(clients.SMIAccessClient)
+	assert.NotNil(clients.SMISpecClient)
+	assert.NotNil(clients.SMISplitClient)
+	assert.NotNil(clients.PolicyClient)
+	assert.NotNil(clients.ConfigClient)
+}
+
+func TestNewClientsErr(t *testing.T) {
+	assert := tassert.New(t)
+
+	env := New()
+	kubeConfigPath := "This doesn't even look like a valid path name"
+	env.config.KubeConfig = &kubeConfigPath
+
+	clients, err := NewClients(env)
+	assert.Nil(clients)
+	assert.Contains(err.Error(), "Error fetching kubeconfig")
+}
+
+func TestNewClientsForConfig(t *testing.T) {
+	testCases := []struct {
+		name      string
+		config    *rest.Config
+		expectErr bool
+	}{
+		{
+			name:      "valid config",
+			config:    &rest.Config{Host: "https://test-cluster:6443"},
+			expectErr: false,
+		},
+		{
+			name: "rate limited config without burst",
+			config: &rest.Config{
+				Host: "https://test-cluster:6443",
+				QPS:  10,
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := tassert.New(t)
+
+			clients, err := NewClientsForConfig(tc.config)
+			assert.Equal(tc.expectErr, err != nil)
+			if tc.expectErr {
+				assert.Nil(clients)
+				return
+			}
+			assert.Same(tc.config, clients.RESTConfig)
+		})
+	}
+}
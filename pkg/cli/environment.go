@@ -43,6 +43,7 @@ environment variables: https://github.com/helm/helm/blob/master/pkg/cli/environm
 package cli
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/spf13/pflag"
@@ -52,37 +53,51 @@ import (
 const (
 	defaultOSMNamespace = "osm-system"
 	osmNamespaceEnvVar  = "OSM_NAMESPACE"
+	osmNamespaceFlag    = "osm-namespace"
+
+	insecureSkipTLSVerifyFlag = "insecure-skip-tls-verify"
 )
 
 // EnvSettings describes all of the cli environment settings
 type EnvSettings struct {
+	// namespace is the value of --osm-namespace, which defaults to the value of the OSM_NAMESPACE env var
 	namespace string
 	config    *genericclioptions.ConfigFlags
+
+	// insecureSkipTLSVerify is the value of --insecure-skip-tls-verify, which overrides the TLS settings of the cluster
+	// of the kube context to skip verifying the certificate of the Kubernetes API server
+	insecureSkipTLSVerify bool
+
+	// namespaceFlag is the --osm-namespace flag, it is nil until the flags are bound with AddFlags
+	namespaceFlag *pflag.Flag
 }
 
 // New relevant environment variables set and returns EnvSettings
 func New() *EnvSettings {
 	env := &EnvSettings{
-		namespace: envOr(osmNamespaceEnvVar, defaultOSMNamespace),
+		namespace: os.Getenv(osmNamespaceEnvVar),
 	}
 
-	// bind to kubernetes config flags
+	// bind to kubernetes config flags, an empty namespace does not override the namespace of the kube context, and
+	// skipping TLS verification clears the certificate authority of the cluster of the kube context
 	env.config = &genericclioptions.ConfigFlags{
 		Namespace: &env.namespace,
+		Insecure:  &env.insecureSkipTLSVerify,
 	}
 	return env
 }
 
-func envOr(name, defaultVal string) string {
-	if v, ok := os.LookupEnv(name); ok {
-		return v
-	}
-	return defaultVal
-}
-
 // AddFlags binds flags to the given flagset.
 func (s *EnvSettings) AddFlags(fs *pflag.FlagSet) {
-	fs.StringVar(&s.namespace, "osm-namespace", s.namespace, "namespace for osm control plane")
+	fs.StringVar(&s.namespace, osmNamespaceFlag, s.namespace, fmt.Sprintf("namespace for osm control plane, defaults to the %s env var, then to the namespace of the current kube context, then to %s. A kube context setting a namespace takes precedence over %s, set this flag or %s to keep using %s", osmNamespaceEnvVar, defaultOSMNamespace, defaultOSMNamespace, osmNamespaceEnvVar, defaultOSMNamespace))
+	s.namespaceFlag = fs.Lookup(osmNamespaceFlag)
+	fs.BoolVar(&s.insecureSkipTLSVerify, insecureSkipTLSVerifyFlag, false, "skip verifying the certificate of the Kubernetes API server, making the connections to it insecure, e.g. for lab clusters with self-signed certificates")
+}
+
+// InsecureSkipTLSVerify returns whether the certificate of the Kubernetes API server is not verified, as set with
+// --insecure-skip-tls-verify
+func (s *EnvSettings) InsecureSkipTLSVerify() bool {
+	return s.insecureSkipTLSVerify
 }
 
 // EnvVars returns a map of all OSM related environment variables
@@ -99,8 +114,31 @@ func (s *EnvSettings) RESTClientGetter() genericclioptions.RESTClientGetter {
 
 // Namespace gets the namespace from the configuration
 func (s *EnvSettings) Namespace() string {
-	if ns, _, err := s.config.ToRawKubeConfigLoader().Namespace(); err == nil {
-		return ns
+	namespace, _ := s.ResolveNamespace()
+	return namespace
+}
+
+// ResolveNamespace returns the namespace of the control plane along with a description of where it is resolved from,
+// in order of precedence: the --osm-namespace flag, the OSM_NAMESPACE env var, the namespace of the current kube
+// context, and the osm-system namespace. The osm-system namespace is also returned when the kubeconfig can't be loaded.
+func (s *EnvSettings) ResolveNamespace() (string, string) {
+	if s.namespace != "" {
+		if s.namespaceFlag != nil && s.namespaceFlag.Changed {
+			return s.namespace, fmt.Sprintf("flag --%s", osmNamespaceFlag)
+		}
+		return s.namespace, fmt.Sprintf("env var %s", osmNamespaceEnvVar)
+	}
+
+	kubeConfig, err := s.config.ToRawKubeConfigLoader().RawConfig()
+	if err != nil {
+		return defaultOSMNamespace, fmt.Sprintf("default OSM namespace, the kubeconfig could not be loaded: %s", err)
+	}
+	currentContext := kubeConfig.CurrentContext
+	if s.config.Context != nil && *s.config.Context != "" {
+		currentContext = *s.config.Context
+	}
+	if context, ok := kubeConfig.Contexts[currentContext]; ok && context.Namespace != "" {
+		return context.Namespace, fmt.Sprintf("kube context %s", currentContext)
 	}
-	return "default"
+	return defaultOSMNamespace, "default OSM namespace"
 }
@@ -1,7 +1,10 @@
 package cli
 
 import (
+	"fmt"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/spf13/pflag"
@@ -75,6 +78,120 @@ func TestNew(t *testing.T) {
 	}
 }
 
+const testKubeConfigWithNamespace = `
+apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: https://test-cluster:6443
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+    namespace: osm-context
+current-context: test
+users:
+- name: test
+  user:
+    token: test-token
+`
+
+func TestResolveNamespace(t *testing.T) {
+	tmp, err := ioutil.TempDir(os.TempDir(), "osm-test")
+	tassert.Nil(t, err)
+	defer func() {
+		if err := os.RemoveAll(tmp); err != nil {
+			t.Log("error cleaning up temp dir:", err)
+		}
+	}()
+
+	tests := []struct {
+		name              string
+		args              []string
+		envNamespace      string
+		kubeConfig        string
+		expectedNamespace string
+		expectedSource    string
+	}{
+		{
+			name:              "flag overrides env var and kube context",
+			args:              []string{"--osm-namespace=osm-ns"},
+			envNamespace:      "osm-env",
+			kubeConfig:        testKubeConfigWithNamespace,
+			expectedNamespace: "osm-ns",
+			expectedSource:    "flag --osm-namespace",
+		},
+		{
+			name:              "flag set to the default namespace overrides env var",
+			args:              []string{"--osm-namespace=osm-system"},
+			envNamespace:      "osm-env",
+			kubeConfig:        testKubeConfigWithNamespace,
+			expectedNamespace: "osm-system",
+			expectedSource:    "flag --osm-namespace",
+		},
+		{
+			name:              "env var overrides kube context",
+			args:              nil,
+			envNamespace:      "osm-env",
+			kubeConfig:        testKubeConfigWithNamespace,
+			expectedNamespace: "osm-env",
+			expectedSource:    "env var OSM_NAMESPACE",
+		},
+		{
+			name:              "kube context overrides default",
+			args:              nil,
+			envNamespace:      "",
+			kubeConfig:        testKubeConfigWithNamespace,
+			expectedNamespace: "osm-context",
+			expectedSource:    "kube context test",
+		},
+		{
+			name:              "default when the kube context has no namespace",
+			args:              nil,
+			envNamespace:      "",
+			kubeConfig:        testKubeConfig,
+			expectedNamespace: defaultOSMNamespace,
+			expectedSource:    "default OSM namespace",
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := tassert.New(t)
+
+			oldv, found := os.LookupEnv(osmNamespaceEnvVar)
+			defer func() {
+				if found {
+					assert.Nil(os.Setenv(osmNamespaceEnvVar, oldv))
+				} else {
+					assert.Nil(os.Unsetenv(osmNamespaceEnvVar))
+				}
+			}()
+			if test.envNamespace != "" {
+				assert.Nil(os.Setenv(osmNamespaceEnvVar, test.envNamespace))
+			} else {
+				assert.Nil(os.Unsetenv(osmNamespaceEnvVar))
+			}
+
+			kubeConfigPath := filepath.Join(tmp, fmt.Sprintf("kubeconfig-%d", i))
+			assert.Nil(ioutil.WriteFile(kubeConfigPath, []byte(test.kubeConfig), 0600))
+
+			settings := New()
+			settings.config.KubeConfig = &kubeConfigPath
+			flags := pflag.NewFlagSet("test-resolve-namespace", pflag.ContinueOnError)
+			settings.AddFlags(flags)
+			assert.Nil(flags.Parse(test.args))
+
+			namespace, source := settings.ResolveNamespace()
+			assert.Equal(test.expectedNamespace, namespace)
+			assert.Equal(test.expectedSource, source)
+			assert.Equal(test.expectedNamespace, settings.Namespace())
+		})
+	}
+}
+
 func TestNamespaceErr(t *testing.T) {
 	env := New()
 
@@ -85,7 +202,9 @@ func TestNamespaceErr(t *testing.T) {
 	kConfigPath := "This doesn't even look like a valid path name"
 	env.config.KubeConfig = &kConfigPath
 
-	tassert.Equal(t, env.Namespace(), "default")
+	namespace, source := env.ResolveNamespace()
+	tassert.Equal(t, defaultOSMNamespace, namespace)
+	tassert.Contains(t, source, "default OSM namespace, the kubeconfig could not be loaded")
 }
 
 func TestEnvVars(t *testing.T) {
@@ -97,3 +216,93 @@ func TestRESTClientGetter(t *testing.T) {
 	env := New()
 	tassert.Same(t, env.config, env.RESTClientGetter())
 }
+
+const testKubeConfigWithCA = `
+apiVersion: v1
+kind: Config
+clusters:
+- name: test
+  cluster:
+    server: https://test-cluster:6443
+    certificate-authority-data: dGVzdC1jYQ==
+- name: lab
+  cluster:
+    server: https://lab-cluster:6443
+    certificate-authority-data: bGFiLWNh
+contexts:
+- name: test
+  context:
+    cluster: test
+    user: test
+- name: lab
+  context:
+    cluster: lab
+    user: test
+current-context: test
+users:
+- name: test
+  user:
+    token: test-token
+`
+
+func TestInsecureSkipTLSVerify(t *testing.T) {
+	tmp, err := ioutil.TempDir(os.TempDir(), "osm-test")
+	tassert.Nil(t, err)
+	defer func() {
+		if err := os.RemoveAll(tmp); err != nil {
+			t.Log("error cleaning up temp dir:", err)
+		}
+	}()
+	kubeConfigPath := filepath.Join(tmp, "kubeconfig")
+	tassert.Nil(t, ioutil.WriteFile(kubeConfigPath, []byte(testKubeConfigWithCA), 0600))
+
+	tests := []struct {
+		name             string
+		args             []string
+		context          string
+		expectedHost     string
+		expectedInsecure bool
+		expectedCAData   string
+	}{
+		{
+			name:             "certificate verified with the CA of the kube context by default",
+			args:             nil,
+			expectedHost:     "https://test-cluster:6443",
+			expectedInsecure: false,
+			expectedCAData:   "test-ca",
+		},
+		{
+			name:             "flag skips verifying the certificate",
+			args:             []string{"--insecure-skip-tls-verify"},
+			expectedHost:     "https://test-cluster:6443",
+			expectedInsecure: true,
+		},
+		{
+			name:             "flag skips verifying the certificate of the cluster of another kube context",
+			args:             []string{"--insecure-skip-tls-verify"},
+			context:          "lab",
+			expectedHost:     "https://lab-cluster:6443",
+			expectedInsecure: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert := tassert.New(t)
+
+			settings := New()
+			settings.config.KubeConfig = &kubeConfigPath
+			settings.config.Context = &test.context
+			flags := pflag.NewFlagSet("test-insecure-skip-tls-verify", pflag.ContinueOnError)
+			settings.AddFlags(flags)
+			assert.Nil(flags.Parse(test.args))
+			assert.Equal(test.expectedInsecure, settings.InsecureSkipTLSVerify())
+
+			config, err := settings.RESTClientGetter().ToRESTConfig()
+			assert.Nil(err)
+			assert.Equal(test.expectedHost, config.Host)
+			assert.Equal(test.expectedInsecure, config.Insecure)
+			assert.Equal(test.expectedCAData, string(config.CAData))
+		})
+	}
+}
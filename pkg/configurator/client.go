@@ -2,6 +2,8 @@ package configurator
 
 import (
 	"fmt"
+	"reflect"
+	"sort"
 	"strconv"
 
 	v1 "k8s.io/api/core/v1"
@@ -20,8 +22,8 @@ const (
 	// PermissiveTrafficPolicyModeKey is the key name used for permissive mode in the ConfigMap
 	PermissiveTrafficPolicyModeKey = "permissive_traffic_policy_mode"
 
-	// egressKey is the key name used for egress in the ConfigMap
-	egressKey = "egress"
+	// EgressKey is the key name used for egress in the ConfigMap
+	EgressKey = "egress"
 
 	// enableDebugServer is the key name used for the debug server in the ConfigMap
 	enableDebugServer = "enable_debug_server"
@@ -29,6 +31,12 @@ const (
 	// prometheusScrapingKey is the key name used for prometheus scraping in the ConfigMap
 	prometheusScrapingKey = "prometheus_scraping"
 
+	// prometheusScrapePathKey is the key name used to specify the path Prometheus scrapes the sidecar proxy metrics from
+	prometheusScrapePathKey = "prometheus_scrape_path"
+
+	// proxyStatsTagsEnabledKey is the key name used to enable the extraction of tags from the sidecar proxy stats
+	proxyStatsTagsEnabledKey = "proxy_stats_tags_enabled"
+
 	// useHTTPSIngressKey is the key name used for HTTPS ingress in the ConfigMap
 	useHTTPSIngressKey = "use_https_ingress"
 
@@ -61,6 +69,67 @@ const (
 
 	// configResyncInterval is the key name used to configure the resync interval for regular proxy broadcast updates
 	configResyncInterval = "config_resync_interval"
+
+	// injectorFailurePolicyKey is the key name used to specify how the sidecar injector responds when it fails to inject a pod
+	injectorFailurePolicyKey = "injector_failure_policy"
+
+	// proxyImagePullPolicyKey is the key name used to specify the image pull policy of the containers injected by the sidecar injector
+	proxyImagePullPolicyKey = "proxy_image_pull_policy"
+
+	// proxyImagePullSecretsKey is the key name used to specify the image pull secrets added to pods by the sidecar injector
+	proxyImagePullSecretsKey = "proxy_image_pull_secrets"
+
+	// proxyDrainTimeoutKey is the key name used to specify the duration for which the sidecar proxy drains connections on pod termination
+	proxyDrainTimeoutKey = "proxy_drain_timeout"
+
+	// initContainerNameKey is the key name used to specify the name of the init container injected by the sidecar injector
+	initContainerNameKey = "init_container_name"
+
+	// injectedPodLabelsKey is the key name used to specify the labels added to pods by the sidecar injector
+	injectedPodLabelsKey = "injected_pod_labels"
+
+	// injectedPodAnnotationsKey is the key name used to specify the annotations added to pods by the sidecar injector
+	injectedPodAnnotationsKey = "injected_pod_annotations"
+
+	// enableCNIKey is the key name used to specify whether a CNI plugin sets up the traffic redirection of meshed pods
+	enableCNIKey = "enable_cni"
+
+	// proxyEnvKey is the key name used to specify the env vars added to the Envoy sidecar container
+	proxyEnvKey = "proxy_env"
+
+	// proxyServiceNodeTemplateKey is the key name used to specify the template of the node name passed to Envoy with --service-node
+	proxyServiceNodeTemplateKey = "proxy_service_node_template"
+
+	// proxyServiceClusterTemplateKey is the key name used to specify the template of the cluster name passed to Envoy with --service-cluster
+	proxyServiceClusterTemplateKey = "proxy_service_cluster_template"
+
+	// waitForProxyReadyKey is the key name used to specify whether the containers of meshed pods wait for the sidecar proxy to be ready before starting
+	waitForProxyReadyKey = "wait_for_proxy_ready"
+
+	// envoyConfigPathKey is the key name used to specify the path of the bootstrap config file of the Envoy sidecar
+	envoyConfigPathKey = "envoy_config_path"
+
+	// proxyUIDKey is the key name used to specify the UID the sidecar proxy runs as
+	proxyUIDKey = "proxy_uid"
+
+	// proxyProbeInitialDelaySecondsKey is the key name used to specify the initialDelaySeconds of the probes of the sidecar proxy
+	proxyProbeInitialDelaySecondsKey = "proxy_probe_initial_delay_seconds"
+
+	// proxyProbePeriodSecondsKey is the key name used to specify the periodSeconds of the probes of the sidecar proxy
+	proxyProbePeriodSecondsKey = "proxy_probe_period_seconds"
+
+	// proxyProbeFailureThresholdKey is the key name used to specify the failureThreshold of the probes of the sidecar proxy
+	proxyProbeFailureThresholdKey = "proxy_probe_failure_threshold"
+
+	// proxyCABundleKey is the key name used to specify the secret or ConfigMap holding the CA bundle mounted in the
+	// sidecar proxy
+	proxyCABundleKey = "proxy_ca_bundle"
+
+	// proxyVolumesKey is the key name used to specify the volumes added to pods for the sidecar proxy
+	proxyVolumesKey = "proxy_volumes"
+
+	// proxyVolumeMountsKey is the key name used to specify the volume mounts added to the sidecar proxy
+	proxyVolumeMountsKey = "proxy_volume_mounts"
 )
 
 // NewConfigurator implements configurator.Configurator and creates the Kubernetes client to manage namespaces.
@@ -101,6 +170,20 @@ func newConfigurator(kubeClient kubernetes.Interface, stop <-chan struct{}, osmN
 	return &client
 }
 
+// NewStaticConfigurator implements configurator.Configurator for the given ConfigMap, which is never updated. It
+// starts no informer, and is meant for the components rendering the mesh config without access to a cluster.
+func NewStaticConfigurator(configMap *v1.ConfigMap) Configurator {
+	store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+	if err := store.Add(configMap); err != nil {
+		log.Error().Err(err).Msgf("Error adding ConfigMap %s/%s to the static configurator", configMap.Namespace, configMap.Name)
+	}
+	return &Client{
+		cache:            store,
+		osmNamespace:     configMap.Namespace,
+		osmConfigMapName: configMap.Name,
+	}
+}
+
 // Listens to ConfigMap events and notifies dispatcher to issue config updates to the envoys based
 // on config seen on the configmap.
 // It is guaranteed upon return that the listener routine is ready to receive events.
@@ -170,6 +253,7 @@ func (c *Client) configMapListener(stop <-chan struct{}) {
 					triggerGlobalBroadcast = triggerGlobalBroadcast || (prevConfigMap.TracingEndpoint != newConfigMap.TracingEndpoint)
 					triggerGlobalBroadcast = triggerGlobalBroadcast || (prevConfigMap.TracingPort != newConfigMap.TracingPort)
 					triggerGlobalBroadcast = triggerGlobalBroadcast || (prevConfigMap.PrometheusScraping != newConfigMap.PrometheusScraping)
+					triggerGlobalBroadcast = triggerGlobalBroadcast || (prevConfigMap.PrometheusScrapePath != newConfigMap.PrometheusScrapePath)
 
 					if triggerGlobalBroadcast {
 						log.Debug().Msgf("[%s] OSM ConfigMap update triggered global proxy broadcast",
@@ -211,6 +295,13 @@ type osmConfig struct {
 	// PrometheusScraping is a bool toggle used to enable or disable metrics scraping by Prometheus
 	PrometheusScraping bool `yaml:"prometheus_scraping"`
 
+	// PrometheusScrapePath is the path Prometheus scrapes the sidecar proxy metrics from
+	PrometheusScrapePath string `yaml:"prometheus_scrape_path"`
+
+	// ProxyStatsTagsEnabled is a bool toggle used to extract the cluster and route dimensions of the sidecar proxy
+	// stats as tags
+	ProxyStatsTagsEnabled bool `yaml:"proxy_stats_tags_enabled"`
+
 	// UseHTTPSIngress is a bool toggle enabling HTTPS protocol between ingress and backend pods
 	UseHTTPSIngress bool `yaml:"use_https_ingress"`
 
@@ -244,6 +335,79 @@ type osmConfig struct {
 
 	// ConfigResyncInterval is a flag to configure resync interval for regular proxy broadcast updates
 	ConfigResyncInterval string `yaml:"config_resync_interval"`
+
+	// InjectorFailurePolicy is how the sidecar injector responds when it fails to inject a pod
+	InjectorFailurePolicy string `yaml:"injector_failure_policy"`
+
+	// ProxyImagePullPolicy is the image pull policy of the containers injected by the sidecar injector
+	ProxyImagePullPolicy string `yaml:"proxy_image_pull_policy"`
+
+	// ProxyImagePullSecrets is the comma separated list of image pull secrets added to pods by the sidecar injector
+	ProxyImagePullSecrets string `yaml:"proxy_image_pull_secrets"`
+
+	// ProxyDrainTimeout is the duration for which the sidecar proxy drains connections on pod termination
+	ProxyDrainTimeout string `yaml:"proxy_drain_timeout"`
+
+	// InitContainerName is the name of the init container injected by the sidecar injector
+	InitContainerName string `yaml:"init_container_name"`
+
+	// InjectedPodLabels is the comma separated list of key=value labels added to pods by the sidecar injector
+	InjectedPodLabels string `yaml:"injected_pod_labels"`
+
+	// InjectedPodAnnotations is the comma separated list of key=value annotations added to pods by the sidecar injector
+	InjectedPodAnnotations string `yaml:"injected_pod_annotations"`
+
+	// EnableCNI is a bool toggle used to let a CNI plugin set up the traffic redirection instead of the init container
+	EnableCNI bool `yaml:"enable_cni"`
+
+	// ProxyEnv is the comma separated list of NAME=value env vars added to the Envoy sidecar container
+	ProxyEnv string `yaml:"proxy_env"`
+
+	// ProxyServiceNodeTemplate is the template of the node name passed to Envoy with --service-node
+	ProxyServiceNodeTemplate string `yaml:"proxy_service_node_template"`
+
+	// ProxyServiceClusterTemplate is the template of the cluster name passed to Envoy with --service-cluster
+	ProxyServiceClusterTemplate string `yaml:"proxy_service_cluster_template"`
+
+	// WaitForProxyReady is a bool toggle used to start the containers of meshed pods once the sidecar proxy is ready
+	WaitForProxyReady bool `yaml:"wait_for_proxy_ready"`
+
+	// EnvoyConfigPath is the path of the bootstrap config file of the Envoy sidecar, passed to Envoy with --config-path
+	EnvoyConfigPath string `yaml:"envoy_config_path"`
+
+	// ProxyUID is the UID the sidecar proxy runs as, whose traffic is not intercepted
+	ProxyUID int `yaml:"proxy_uid"`
+
+	// ProxyProbeInitialDelaySeconds is the initialDelaySeconds of the startup and liveness probes of the sidecar proxy
+	ProxyProbeInitialDelaySeconds int `yaml:"proxy_probe_initial_delay_seconds"`
+
+	// ProxyProbePeriodSeconds is the periodSeconds of the startup and liveness probes of the sidecar proxy
+	ProxyProbePeriodSeconds int `yaml:"proxy_probe_period_seconds"`
+
+	// ProxyProbeFailureThreshold is the failureThreshold of the startup and liveness probes of the sidecar proxy
+	ProxyProbeFailureThreshold int `yaml:"proxy_probe_failure_threshold"`
+
+	// ProxyCABundle is the secret or ConfigMap holding the CA bundle mounted in the sidecar proxy, of the form
+	// secret/<name> or configmap/<name>
+	ProxyCABundle string `yaml:"proxy_ca_bundle"`
+
+	// ProxyVolumes is the JSON list of volumes added to pods for the sidecar proxy
+	ProxyVolumes string `yaml:"proxy_volumes"`
+
+	// ProxyVolumeMounts is the JSON list of volume mounts added to the sidecar proxy, referencing ProxyVolumes or the
+	// volumes of the pod by name
+	ProxyVolumeMounts string `yaml:"proxy_volume_mounts"`
+}
+
+// GetConfigMapKeys returns the keys of the osm-config ConfigMap supported by this version of OSM, sorted
+func GetConfigMapKeys() []string {
+	var keys []string
+	configType := reflect.TypeOf(osmConfig{})
+	for i := 0; i < configType.NumField(); i++ {
+		keys = append(keys, configType.Field(i).Tag.Get("yaml"))
+	}
+	sort.Strings(keys)
+	return keys
 }
 
 func (c *Client) run(stop <-chan struct{}) {
@@ -288,9 +452,11 @@ func parseOSMConfigMap(configMap *v1.ConfigMap) *osmConfig {
 	// is implemented.
 	osmConfigMap := osmConfig{}
 	osmConfigMap.PermissiveTrafficPolicyMode, _ = GetBoolValueForKey(configMap, PermissiveTrafficPolicyModeKey)
-	osmConfigMap.Egress, _ = GetBoolValueForKey(configMap, egressKey)
+	osmConfigMap.Egress, _ = GetBoolValueForKey(configMap, EgressKey)
 	osmConfigMap.EnableDebugServer, _ = GetBoolValueForKey(configMap, enableDebugServer)
 	osmConfigMap.PrometheusScraping, _ = GetBoolValueForKey(configMap, prometheusScrapingKey)
+	osmConfigMap.PrometheusScrapePath, _ = GetStringValueForKey(configMap, prometheusScrapePathKey)
+	osmConfigMap.ProxyStatsTagsEnabled, _ = GetBoolValueForKey(configMap, proxyStatsTagsEnabledKey)
 	osmConfigMap.UseHTTPSIngress, _ = GetBoolValueForKey(configMap, useHTTPSIngressKey)
 	osmConfigMap.MaxDataPlaneConnections, _ = GetIntValueForKey(configMap, maxDataPlaneConnectionsKey)
 	osmConfigMap.TracingEnable, _ = GetBoolValueForKey(configMap, tracingEnableKey)
@@ -299,6 +465,26 @@ func parseOSMConfigMap(configMap *v1.ConfigMap) *osmConfig {
 	osmConfigMap.OutboundIPRangeExclusionList, _ = GetStringValueForKey(configMap, outboundIPRangeExclusionListKey)
 	osmConfigMap.EnablePrivilegedInitContainer, _ = GetBoolValueForKey(configMap, enablePrivilegedInitContainer)
 	osmConfigMap.ConfigResyncInterval, _ = GetStringValueForKey(configMap, configResyncInterval)
+	osmConfigMap.InjectorFailurePolicy, _ = GetStringValueForKey(configMap, injectorFailurePolicyKey)
+	osmConfigMap.ProxyImagePullPolicy, _ = GetStringValueForKey(configMap, proxyImagePullPolicyKey)
+	osmConfigMap.ProxyImagePullSecrets, _ = GetStringValueForKey(configMap, proxyImagePullSecretsKey)
+	osmConfigMap.ProxyDrainTimeout, _ = GetStringValueForKey(configMap, proxyDrainTimeoutKey)
+	osmConfigMap.InitContainerName, _ = GetStringValueForKey(configMap, initContainerNameKey)
+	osmConfigMap.InjectedPodLabels, _ = GetStringValueForKey(configMap, injectedPodLabelsKey)
+	osmConfigMap.InjectedPodAnnotations, _ = GetStringValueForKey(configMap, injectedPodAnnotationsKey)
+	osmConfigMap.EnableCNI, _ = GetBoolValueForKey(configMap, enableCNIKey)
+	osmConfigMap.ProxyEnv, _ = GetStringValueForKey(configMap, proxyEnvKey)
+	osmConfigMap.ProxyServiceNodeTemplate, _ = GetStringValueForKey(configMap, proxyServiceNodeTemplateKey)
+	osmConfigMap.ProxyServiceClusterTemplate, _ = GetStringValueForKey(configMap, proxyServiceClusterTemplateKey)
+	osmConfigMap.WaitForProxyReady, _ = GetBoolValueForKey(configMap, waitForProxyReadyKey)
+	osmConfigMap.EnvoyConfigPath, _ = GetStringValueForKey(configMap, envoyConfigPathKey)
+	osmConfigMap.ProxyUID, _ = GetIntValueForKey(configMap, proxyUIDKey)
+	osmConfigMap.ProxyProbeInitialDelaySeconds, _ = GetIntValueForKey(configMap, proxyProbeInitialDelaySecondsKey)
+	osmConfigMap.ProxyProbePeriodSeconds, _ = GetIntValueForKey(configMap, proxyProbePeriodSecondsKey)
+	osmConfigMap.ProxyProbeFailureThreshold, _ = GetIntValueForKey(configMap, proxyProbeFailureThresholdKey)
+	osmConfigMap.ProxyCABundle, _ = GetStringValueForKey(configMap, proxyCABundleKey)
+	osmConfigMap.ProxyVolumes, _ = GetStringValueForKey(configMap, proxyVolumesKey)
+	osmConfigMap.ProxyVolumeMounts, _ = GetStringValueForKey(configMap, proxyVolumeMountsKey)
 
 	if osmConfigMap.TracingEnable {
 		osmConfigMap.TracingAddress, _ = GetStringValueForKey(configMap, tracingAddressKey)
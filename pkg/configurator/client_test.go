@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"reflect"
+	"sort"
 	"testing"
 	"time"
 
@@ -54,9 +55,11 @@ var _ = Describe("Test OSM ConfigMap parsing", func() {
 		It("Tag matches const key for all fields of OSM ConfigMap struct", func() {
 			fieldNameTag := map[string]string{
 				"PermissiveTrafficPolicyMode":   PermissiveTrafficPolicyModeKey,
-				"Egress":                        egressKey,
+				"Egress":                        EgressKey,
 				"EnableDebugServer":             enableDebugServer,
 				"PrometheusScraping":            prometheusScrapingKey,
+				"PrometheusScrapePath":          prometheusScrapePathKey,
+				"ProxyStatsTagsEnabled":         proxyStatsTagsEnabledKey,
 				"TracingEnable":                 tracingEnableKey,
 				"TracingAddress":                tracingAddressKey,
 				"TracingPort":                   tracingPortKey,
@@ -68,6 +71,26 @@ var _ = Describe("Test OSM ConfigMap parsing", func() {
 				"OutboundIPRangeExclusionList":  outboundIPRangeExclusionListKey,
 				"EnablePrivilegedInitContainer": enablePrivilegedInitContainer,
 				"ConfigResyncInterval":          configResyncInterval,
+				"InjectorFailurePolicy":         injectorFailurePolicyKey,
+				"ProxyImagePullPolicy":          proxyImagePullPolicyKey,
+				"ProxyImagePullSecrets":         proxyImagePullSecretsKey,
+				"ProxyDrainTimeout":             proxyDrainTimeoutKey,
+				"InitContainerName":             initContainerNameKey,
+				"InjectedPodLabels":             injectedPodLabelsKey,
+				"InjectedPodAnnotations":        injectedPodAnnotationsKey,
+				"EnableCNI":                     enableCNIKey,
+				"ProxyEnv":                      proxyEnvKey,
+				"ProxyServiceNodeTemplate":      proxyServiceNodeTemplateKey,
+				"ProxyServiceClusterTemplate":   proxyServiceClusterTemplateKey,
+				"WaitForProxyReady":             waitForProxyReadyKey,
+				"EnvoyConfigPath":               envoyConfigPathKey,
+				"ProxyUID":                      proxyUIDKey,
+				"ProxyProbeInitialDelaySeconds": proxyProbeInitialDelaySecondsKey,
+				"ProxyProbePeriodSeconds":       proxyProbePeriodSecondsKey,
+				"ProxyProbeFailureThreshold":    proxyProbeFailureThresholdKey,
+				"ProxyCABundle":                 proxyCABundleKey,
+				"ProxyVolumes":                  proxyVolumesKey,
+				"ProxyVolumeMounts":             proxyVolumeMountsKey,
 			}
 			t := reflect.TypeOf(osmConfig{})
 
@@ -93,7 +116,7 @@ var _ = Describe("Test OSM ConfigMap parsing", func() {
 			Expect(val).To(BeTrue())
 			Expect(err).To(BeNil())
 
-			val, err = GetBoolValueForKey(cm, egressKey)
+			val, err = GetBoolValueForKey(cm, EgressKey)
 			Expect(val).To(BeFalse())
 			Expect(err).To(HaveOccurred())
 		})
@@ -105,7 +128,7 @@ var _ = Describe("Test OSM ConfigMap parsing", func() {
 			Expect(err).To(BeNil())
 
 			cm0 := &v1.ConfigMap{Data: map[string]string{}}
-			val, err = GetIntValueForKey(cm0, egressKey)
+			val, err = GetIntValueForKey(cm0, EgressKey)
 			Expect(val).To(Equal(0))
 			Expect(err).To(HaveOccurred())
 		})
@@ -165,7 +188,7 @@ func TestConfigMapEventTriggers(t *testing.T) {
 	}{
 		{
 			deltaConfigMapContents: map[string]string{
-				egressKey: "true",
+				EgressKey: "true",
 			},
 			expectProxyBroadcast: true,
 		},
@@ -211,6 +234,12 @@ func TestConfigMapEventTriggers(t *testing.T) {
 			},
 			expectProxyBroadcast: true,
 		},
+		{
+			deltaConfigMapContents: map[string]string{
+				prometheusScrapePathKey: "/metrics",
+			},
+			expectProxyBroadcast: true,
+		},
 		{
 			deltaConfigMapContents: map[string]string{
 				configResyncInterval: "24h",
@@ -276,3 +305,30 @@ func TestConfigMapEventTriggers(t *testing.T) {
 		assert.Equal(t.expectProxyBroadcast, proxyEventReceived)
 	}
 }
+
+func TestGetConfigMapKeys(t *testing.T) {
+	assert := tassert.New(t)
+
+	keys := GetConfigMapKeys()
+	assert.Len(keys, reflect.TypeOf(osmConfig{}).NumField())
+	assert.Contains(keys, PermissiveTrafficPolicyModeKey)
+	assert.Contains(keys, envoyLogLevel)
+	assert.Contains(keys, proxyVolumeMountsKey)
+	assert.True(sort.StringsAreSorted(keys))
+}
+
+func TestNewStaticConfigurator(t *testing.T) {
+	assert := tassert.New(t)
+
+	cfg := NewStaticConfigurator(&v1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Namespace: osmNamespace, Name: osmConfigMapName},
+		Data: map[string]string{
+			PermissiveTrafficPolicyModeKey: "true",
+			envoyLogLevel:                  "debug",
+		},
+	})
+	assert.Equal(osmNamespace, cfg.GetOSMNamespace())
+	assert.True(cfg.IsPermissiveTrafficPolicyMode())
+	assert.Equal("debug", cfg.GetEnvoyLogLevel())
+	assert.False(cfg.IsEgressEnabled())
+}
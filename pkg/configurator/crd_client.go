@@ -1,6 +1,9 @@
 package configurator
 
 import (
+	"encoding/json"
+	"fmt"
+	"sort"
 	"strings"
 
 	"k8s.io/client-go/tools/cache"
@@ -115,11 +118,39 @@ func parseOSMMeshConfig(meshConfig *v1alpha1.MeshConfig) *osmConfig {
 	osmConfig.Egress = meshConfig.Spec.Traffic.EnableEgress
 	osmConfig.EnableDebugServer = meshConfig.Spec.Observability.EnableDebugServer
 	osmConfig.UseHTTPSIngress = meshConfig.Spec.Traffic.UseHTTPSIngress
+	osmConfig.PrometheusScrapePath = meshConfig.Spec.Observability.PrometheusScrapePath
+	osmConfig.ProxyStatsTagsEnabled = meshConfig.Spec.Observability.EnableProxyStatsTags
 	osmConfig.TracingEnable = meshConfig.Spec.Observability.Tracing.Enable
 	osmConfig.EnvoyLogLevel = meshConfig.Spec.Sidecar.LogLevel
 	osmConfig.ServiceCertValidityDuration = meshConfig.Spec.Certificate.ServiceCertValidityDuration
 	osmConfig.OutboundIPRangeExclusionList = strings.Join(meshConfig.Spec.Traffic.OutboundIPRangeExclusionList, ",")
 	osmConfig.EnablePrivilegedInitContainer = meshConfig.Spec.Sidecar.EnablePrivilegedInitContainer
+	osmConfig.InjectorFailurePolicy = meshConfig.Spec.Sidecar.InjectorFailurePolicy
+	osmConfig.ProxyImagePullPolicy = meshConfig.Spec.Sidecar.ImagePullPolicy
+	osmConfig.ProxyImagePullSecrets = strings.Join(meshConfig.Spec.Sidecar.ImagePullSecrets, ",")
+	osmConfig.ProxyDrainTimeout = meshConfig.Spec.Sidecar.ProxyDrainTimeout
+	osmConfig.InitContainerName = meshConfig.Spec.Sidecar.InitContainerName
+	osmConfig.InjectedPodLabels = joinKeyValues(meshConfig.Spec.Sidecar.PodLabels)
+	osmConfig.InjectedPodAnnotations = joinKeyValues(meshConfig.Spec.Sidecar.PodAnnotations)
+	osmConfig.EnableCNI = meshConfig.Spec.Sidecar.EnableCNI
+	osmConfig.ProxyEnv = joinKeyValues(meshConfig.Spec.Sidecar.Env)
+	osmConfig.ProxyServiceNodeTemplate = meshConfig.Spec.Sidecar.ServiceNodeTemplate
+	osmConfig.ProxyServiceClusterTemplate = meshConfig.Spec.Sidecar.ServiceClusterTemplate
+	osmConfig.WaitForProxyReady = meshConfig.Spec.Sidecar.WaitForProxyReady
+	osmConfig.EnvoyConfigPath = meshConfig.Spec.Sidecar.ConfigPath
+	osmConfig.ProxyUID = meshConfig.Spec.Sidecar.ProxyUID
+	osmConfig.ProxyProbeInitialDelaySeconds = meshConfig.Spec.Sidecar.ProbeInitialDelaySeconds
+	osmConfig.ProxyProbePeriodSeconds = meshConfig.Spec.Sidecar.ProbePeriodSeconds
+	osmConfig.ProxyProbeFailureThreshold = meshConfig.Spec.Sidecar.ProbeFailureThreshold
+	osmConfig.ProxyCABundle = meshConfig.Spec.Sidecar.CABundle
+	if len(meshConfig.Spec.Sidecar.Volumes) > 0 {
+		volumes, _ := json.Marshal(meshConfig.Spec.Sidecar.Volumes)
+		osmConfig.ProxyVolumes = string(volumes)
+	}
+	if len(meshConfig.Spec.Sidecar.VolumeMounts) > 0 {
+		volumeMounts, _ := json.Marshal(meshConfig.Spec.Sidecar.VolumeMounts)
+		osmConfig.ProxyVolumeMounts = string(volumeMounts)
+	}
 
 	if osmConfig.TracingEnable {
 		osmConfig.TracingAddress = meshConfig.Spec.Observability.Tracing.Address
@@ -175,6 +206,7 @@ func meshConfigUpdatedMessageHandler(psubMsg *events.PubSubMessage) {
 	triggerGlobalBroadcast = triggerGlobalBroadcast || (prevMeshConfig.TracingAddress != newMeshConfig.TracingAddress)
 	triggerGlobalBroadcast = triggerGlobalBroadcast || (prevMeshConfig.TracingEndpoint != newMeshConfig.TracingEndpoint)
 	triggerGlobalBroadcast = triggerGlobalBroadcast || (prevMeshConfig.TracingPort != newMeshConfig.TracingPort)
+	triggerGlobalBroadcast = triggerGlobalBroadcast || (prevMeshConfig.PrometheusScrapePath != newMeshConfig.PrometheusScrapePath)
 
 	if triggerGlobalBroadcast {
 		log.Debug().Msgf("[%s] OSM MeshConfig update triggered global proxy broadcast",
@@ -189,3 +221,13 @@ func meshConfigUpdatedMessageHandler(psubMsg *events.PubSubMessage) {
 			psubMsg.AnnouncementType)
 	}
 }
+
+// joinKeyValues returns the given map as a comma separated list of key=value pairs sorted by key
+func joinKeyValues(keyValues map[string]string) string {
+	var pairs []string
+	for key, value := range keyValues {
+		pairs = append(pairs, fmt.Sprintf("%s=%s", key, value))
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
@@ -2,6 +2,7 @@ package configurator
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"reflect"
 	"strconv"
@@ -58,9 +59,11 @@ var _ = Describe("Test OSM MeshConfig parsing", func() {
 		It("Tag matches const key for all fields of OSM MeshConfig struct", func() {
 			fieldNameTag := map[string]string{
 				"PermissiveTrafficPolicyMode":   PermissiveTrafficPolicyModeKey,
-				"Egress":                        egressKey,
+				"Egress":                        EgressKey,
 				"EnableDebugServer":             enableDebugServer,
 				"PrometheusScraping":            prometheusScrapingKey,
+				"PrometheusScrapePath":          prometheusScrapePathKey,
+				"ProxyStatsTagsEnabled":         proxyStatsTagsEnabledKey,
 				"TracingEnable":                 tracingEnableKey,
 				"TracingAddress":                tracingAddressKey,
 				"TracingPort":                   tracingPortKey,
@@ -71,6 +74,26 @@ var _ = Describe("Test OSM MeshConfig parsing", func() {
 				"OutboundIPRangeExclusionList":  outboundIPRangeExclusionListKey,
 				"EnablePrivilegedInitContainer": enablePrivilegedInitContainer,
 				"ConfigResyncInterval":          configResyncInterval,
+				"InjectorFailurePolicy":         injectorFailurePolicyKey,
+				"ProxyImagePullPolicy":          proxyImagePullPolicyKey,
+				"ProxyImagePullSecrets":         proxyImagePullSecretsKey,
+				"ProxyDrainTimeout":             proxyDrainTimeoutKey,
+				"InitContainerName":             initContainerNameKey,
+				"InjectedPodLabels":             injectedPodLabelsKey,
+				"InjectedPodAnnotations":        injectedPodAnnotationsKey,
+				"EnableCNI":                     enableCNIKey,
+				"ProxyEnv":                      proxyEnvKey,
+				"ProxyServiceNodeTemplate":      proxyServiceNodeTemplateKey,
+				"ProxyServiceClusterTemplate":   proxyServiceClusterTemplateKey,
+				"WaitForProxyReady":             waitForProxyReadyKey,
+				"EnvoyConfigPath":               envoyConfigPathKey,
+				"ProxyUID":                      proxyUIDKey,
+				"ProxyProbeInitialDelaySeconds": proxyProbeInitialDelaySecondsKey,
+				"ProxyProbePeriodSeconds":       proxyProbePeriodSecondsKey,
+				"ProxyProbeFailureThreshold":    proxyProbeFailureThresholdKey,
+				"ProxyCABundle":                 proxyCABundleKey,
+				"ProxyVolumes":                  proxyVolumesKey,
+				"ProxyVolumeMounts":             proxyVolumeMountsKey,
 				"MaxDataPlaneConnections":       maxDataPlaneConnectionsKey,
 			}
 			t := reflect.TypeOf(osmConfig{})
@@ -132,7 +155,7 @@ func TestMeshConfigEventTriggers(t *testing.T) {
 	}{
 		{
 			deltaMeshConfigContents: map[string]string{
-				egressKey: "true",
+				EgressKey: "true",
 			},
 			expectProxyBroadcast: true,
 		},
@@ -172,6 +195,18 @@ func TestMeshConfigEventTriggers(t *testing.T) {
 			},
 			expectProxyBroadcast: true,
 		},
+		{
+			deltaMeshConfigContents: map[string]string{
+				prometheusScrapePathKey: "/metrics",
+			},
+			expectProxyBroadcast: true,
+		},
+		{
+			deltaMeshConfigContents: map[string]string{
+				proxyStatsTagsEnabledKey: "true",
+			},
+			expectProxyBroadcast: false,
+		},
 		{
 			deltaMeshConfigContents: map[string]string{
 				envoyLogLevel: "warn",
@@ -214,7 +249,7 @@ func TestMeshConfigEventTriggers(t *testing.T) {
 		// merge meshconfig
 		for mapKey, mapVal := range tc.deltaMeshConfigContents {
 			switch mapKey {
-			case egressKey:
+			case EgressKey:
 				meshConfig.Spec.Traffic.EnableEgress, _ = strconv.ParseBool(mapVal)
 			case PermissiveTrafficPolicyModeKey:
 				meshConfig.Spec.Traffic.EnablePermissiveTrafficPolicyMode, _ = strconv.ParseBool(mapVal)
@@ -229,6 +264,10 @@ func TestMeshConfigEventTriggers(t *testing.T) {
 			case tracingPortKey:
 				port, _ := strconv.ParseInt(mapVal, 10, 16)
 				meshConfig.Spec.Observability.Tracing.Port = int16(port)
+			case prometheusScrapePathKey:
+				meshConfig.Spec.Observability.PrometheusScrapePath = mapVal
+			case proxyStatsTagsEnabledKey:
+				meshConfig.Spec.Observability.EnableProxyStatsTags, _ = strconv.ParseBool(mapVal)
 			case envoyLogLevel:
 				meshConfig.Spec.Sidecar.LogLevel = mapVal
 			case enableDebugServer:
@@ -239,6 +278,46 @@ func TestMeshConfigEventTriggers(t *testing.T) {
 				meshConfig.Spec.Sidecar.EnablePrivilegedInitContainer, _ = strconv.ParseBool(mapVal)
 			case outboundIPRangeExclusionListKey:
 				meshConfig.Spec.Traffic.OutboundIPRangeExclusionList = strings.Split(mapVal, ",")
+			case injectorFailurePolicyKey:
+				meshConfig.Spec.Sidecar.InjectorFailurePolicy = mapVal
+			case proxyImagePullPolicyKey:
+				meshConfig.Spec.Sidecar.ImagePullPolicy = mapVal
+			case proxyImagePullSecretsKey:
+				meshConfig.Spec.Sidecar.ImagePullSecrets = strings.Split(mapVal, ",")
+			case proxyDrainTimeoutKey:
+				meshConfig.Spec.Sidecar.ProxyDrainTimeout = mapVal
+			case initContainerNameKey:
+				meshConfig.Spec.Sidecar.InitContainerName = mapVal
+			case injectedPodLabelsKey:
+				meshConfig.Spec.Sidecar.PodLabels = parseKeyValues(mapVal)
+			case injectedPodAnnotationsKey:
+				meshConfig.Spec.Sidecar.PodAnnotations = parseKeyValues(mapVal)
+			case enableCNIKey:
+				meshConfig.Spec.Sidecar.EnableCNI, _ = strconv.ParseBool(mapVal)
+			case proxyEnvKey:
+				meshConfig.Spec.Sidecar.Env = parseKeyValues(mapVal)
+			case proxyServiceNodeTemplateKey:
+				meshConfig.Spec.Sidecar.ServiceNodeTemplate = mapVal
+			case proxyServiceClusterTemplateKey:
+				meshConfig.Spec.Sidecar.ServiceClusterTemplate = mapVal
+			case waitForProxyReadyKey:
+				meshConfig.Spec.Sidecar.WaitForProxyReady, _ = strconv.ParseBool(mapVal)
+			case envoyConfigPathKey:
+				meshConfig.Spec.Sidecar.ConfigPath = mapVal
+			case proxyUIDKey:
+				meshConfig.Spec.Sidecar.ProxyUID, _ = strconv.Atoi(mapVal)
+			case proxyProbeInitialDelaySecondsKey:
+				meshConfig.Spec.Sidecar.ProbeInitialDelaySeconds, _ = strconv.Atoi(mapVal)
+			case proxyProbePeriodSecondsKey:
+				meshConfig.Spec.Sidecar.ProbePeriodSeconds, _ = strconv.Atoi(mapVal)
+			case proxyProbeFailureThresholdKey:
+				meshConfig.Spec.Sidecar.ProbeFailureThreshold, _ = strconv.Atoi(mapVal)
+			case proxyCABundleKey:
+				meshConfig.Spec.Sidecar.CABundle = mapVal
+			case proxyVolumesKey:
+				_ = json.Unmarshal([]byte(mapVal), &meshConfig.Spec.Sidecar.Volumes)
+			case proxyVolumeMountsKey:
+				_ = json.Unmarshal([]byte(mapVal), &meshConfig.Spec.Sidecar.VolumeMounts)
 			}
 		}
 
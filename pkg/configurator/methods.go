@@ -1,17 +1,61 @@
 package configurator
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"math"
+	"path"
+	"sort"
 	"strings"
+	"text/template"
 	"time"
 
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
 	"github.com/openservicemesh/osm/pkg/constants"
 )
 
 const (
 	// defaultServiceCertValidityDuration is the default validity duration for service certificates
 	defaultServiceCertValidityDuration = 24 * time.Hour
+
+	// InjectorFailurePolicyFail is the injector failure policy denying the pods the sidecar injector fails to inject
+	InjectorFailurePolicyFail = "fail"
+
+	// InjectorFailurePolicyIgnore is the injector failure policy admitting the pods the sidecar injector fails to inject,
+	// unmodified
+	InjectorFailurePolicyIgnore = "ignore"
+
+	// DefaultProxyServiceNodeTemplate is the default template of the node name passed to Envoy with --service-node
+	DefaultProxyServiceNodeTemplate = "{{.ServiceAccount}}"
+
+	// DefaultProxyServiceClusterTemplate is the default template of the cluster name passed to Envoy with --service-cluster
+	DefaultProxyServiceClusterTemplate = "{{.ServiceAccount}}.{{.Namespace}}"
+
+	// MinProxyUID is the lowest UID the sidecar proxy can run as, excluding the root UID
+	MinProxyUID = 1
+
+	// DefaultProxyProbeInitialDelaySeconds is the default initialDelaySeconds of the probes of the sidecar proxy
+	DefaultProxyProbeInitialDelaySeconds = 0
+
+	// DefaultProxyProbePeriodSeconds is the default periodSeconds of the probes of the sidecar proxy
+	DefaultProxyProbePeriodSeconds = 5
+
+	// DefaultProxyProbeFailureThreshold is the default failureThreshold of the probes of the sidecar proxy, giving the
+	// proxy 2 minutes to start with the default period
+	DefaultProxyProbeFailureThreshold = 24
+
+	// MaxProxyUID is the highest UID the sidecar proxy can run as, the highest UID accepted by Kubernetes for runAsUser
+	MaxProxyUID = 2147483647
+
+	// ProxyCABundleKindSecret is the kind of a CA bundle held in a secret
+	ProxyCABundleKindSecret = "secret"
+
+	// ProxyCABundleKindConfigMap is the kind of a CA bundle held in a ConfigMap
+	ProxyCABundleKindConfigMap = "configmap"
 )
 
 // The functions in this file implement the configurator.Configurator interface
@@ -58,6 +102,38 @@ func (c *Client) IsPrometheusScrapingEnabled() bool {
 	return c.getConfigMap().PrometheusScraping
 }
 
+// GetPrometheusScrapePath returns the path Prometheus scrapes the sidecar proxy metrics from, defaults to
+// /stats/prometheus
+func (c *Client) GetPrometheusScrapePath() string {
+	scrapePath := c.getConfigMap().PrometheusScrapePath
+	if scrapePath == "" {
+		return constants.PrometheusScrapePath
+	}
+	if err := ValidatePrometheusScrapePath(scrapePath); err != nil {
+		log.Error().Err(err).Msgf("Invalid %s=%s, using the default path %s", prometheusScrapePathKey, scrapePath, constants.PrometheusScrapePath)
+		return constants.PrometheusScrapePath
+	}
+	return scrapePath
+}
+
+// ValidatePrometheusScrapePath returns an error if the given path Prometheus scrapes the sidecar proxy metrics from
+// is not a clean absolute URL path, without query or fragment
+func ValidatePrometheusScrapePath(scrapePath string) error {
+	if !path.IsAbs(scrapePath) || path.Clean(scrapePath) != scrapePath {
+		return errors.Errorf("Prometheus scrape path %q must be a clean absolute path", scrapePath)
+	}
+	if strings.ContainsAny(scrapePath, "?# \t\n") {
+		return errors.Errorf("Prometheus scrape path %q must not contain a query, a fragment or whitespace", scrapePath)
+	}
+	return nil
+}
+
+// GetProxyStatsTagsEnabled returns whether the cluster and route dimensions of the sidecar proxy stats are extracted
+// as tags, so that they are exposed as Prometheus labels
+func (c *Client) GetProxyStatsTagsEnabled() bool {
+	return c.getConfigMap().ProxyStatsTagsEnabled
+}
+
 // IsTracingEnabled returns whether tracing is enabled
 func (c *Client) IsTracingEnabled() bool {
 	return c.getConfigMap().TracingEnable
@@ -121,7 +197,7 @@ func (c *Client) GetServiceCertValidityPeriod() time.Duration {
 	return validityDuration
 }
 
-// GetOutboundIPRangeExclusionList returns the list of IP ranges of the form x.x.x.x/y to exclude from outbound sidecar interception
+// GetOutboundIPRangeExclusionList returns the list of IPv4 or IPv6 IP ranges of the form x.x.x.x/y or x:x::x/y to exclude from outbound sidecar interception
 func (c *Client) GetOutboundIPRangeExclusionList() []string {
 	ipRangesStr := c.getConfigMap().OutboundIPRangeExclusionList
 	if ipRangesStr == "" {
@@ -152,3 +228,343 @@ func (c *Client) GetConfigResyncInterval() time.Duration {
 	}
 	return duration
 }
+
+// GetInjectorFailurePolicy returns how the sidecar injector responds when it fails to inject a pod, defaults to denying the pod
+func (c *Client) GetInjectorFailurePolicy() string {
+	failurePolicy := c.getConfigMap().InjectorFailurePolicy
+	if failurePolicy != "" {
+		return failurePolicy
+	}
+	return InjectorFailurePolicyFail
+}
+
+// GetProxyImagePullPolicy returns the image pull policy of the containers injected by the sidecar injector, defaults to Always
+func (c *Client) GetProxyImagePullPolicy() corev1.PullPolicy {
+	pullPolicy := c.getConfigMap().ProxyImagePullPolicy
+	if pullPolicy != "" {
+		return corev1.PullPolicy(pullPolicy)
+	}
+	return corev1.PullAlways
+}
+
+// GetProxyImagePullSecrets returns the names of the image pull secrets added to pods by the sidecar injector
+func (c *Client) GetProxyImagePullSecrets() []string {
+	secretsStr := c.getConfigMap().ProxyImagePullSecrets
+	if secretsStr == "" {
+		return nil
+	}
+
+	var secrets []string
+	for _, secret := range strings.Split(secretsStr, ",") {
+		if secret = strings.TrimSpace(secret); secret != "" {
+			secrets = append(secrets, secret)
+		}
+	}
+
+	return secrets
+}
+
+// GetProxyDrainTimeout returns the duration for which the sidecar proxy drains connections on pod termination.
+// If error or non-parsable value, returns 0 duration, which disables draining
+func (c *Client) GetProxyDrainTimeout() time.Duration {
+	drainTimeout := c.getConfigMap().ProxyDrainTimeout
+	if drainTimeout == "" {
+		return time.Duration(0)
+	}
+	duration, err := time.ParseDuration(drainTimeout)
+	if err != nil {
+		log.Debug().Err(err).Msgf("Error parsing proxy drain timeout: %s", drainTimeout)
+		return time.Duration(0)
+	}
+	return duration
+}
+
+// GetInitContainerName returns the name of the init container injected by the sidecar injector, defaults to osm-init
+func (c *Client) GetInitContainerName() string {
+	initContainerName := c.getConfigMap().InitContainerName
+	if initContainerName != "" {
+		return initContainerName
+	}
+	return constants.InitContainerName
+}
+
+// GetInjectedPodLabels returns the labels added to pods by the sidecar injector
+func (c *Client) GetInjectedPodLabels() map[string]string {
+	return parseKeyValues(c.getConfigMap().InjectedPodLabels)
+}
+
+// GetInjectedPodAnnotations returns the annotations added to pods by the sidecar injector
+func (c *Client) GetInjectedPodAnnotations() map[string]string {
+	return parseKeyValues(c.getConfigMap().InjectedPodAnnotations)
+}
+
+// GetCNIEnabled returns whether a CNI plugin sets up the traffic redirection of meshed pods
+func (c *Client) GetCNIEnabled() bool {
+	return c.getConfigMap().EnableCNI
+}
+
+// GetProxyEnv returns the env vars added to the Envoy sidecar container, sorted by name
+func (c *Client) GetProxyEnv() []corev1.EnvVar {
+	env := parseKeyValues(c.getConfigMap().ProxyEnv)
+	if len(env) == 0 {
+		return nil
+	}
+
+	var envVars []corev1.EnvVar
+	for name, value := range env {
+		envVars = append(envVars, corev1.EnvVar{Name: name, Value: value})
+	}
+	sort.Slice(envVars, func(i, j int) bool {
+		return envVars[i].Name < envVars[j].Name
+	})
+	return envVars
+}
+
+// GetProxyServiceNodeTemplate returns the template of the node name passed to Envoy with --service-node, and the
+// default template in case of an invalid template
+func (c *Client) GetProxyServiceNodeTemplate() string {
+	return getProxyServiceNameTemplate(proxyServiceNodeTemplateKey, c.getConfigMap().ProxyServiceNodeTemplate, DefaultProxyServiceNodeTemplate)
+}
+
+// GetProxyServiceClusterTemplate returns the template of the cluster name passed to Envoy with --service-cluster, and
+// the default template in case of an invalid template
+func (c *Client) GetProxyServiceClusterTemplate() string {
+	return getProxyServiceNameTemplate(proxyServiceClusterTemplateKey, c.getConfigMap().ProxyServiceClusterTemplate, DefaultProxyServiceClusterTemplate)
+}
+
+// IsWaitForProxyReadyEnabled returns whether the containers of meshed pods wait for the Envoy sidecar to be ready before starting
+func (c *Client) IsWaitForProxyReadyEnabled() bool {
+	return c.getConfigMap().WaitForProxyReady
+}
+
+// GetEnvoyConfigPath returns the path of the bootstrap config file of the Envoy sidecar, defaults to /etc/envoy/bootstrap.yaml
+func (c *Client) GetEnvoyConfigPath() string {
+	configPath := c.getConfigMap().EnvoyConfigPath
+	if configPath == "" {
+		return constants.EnvoyConfigPath
+	}
+	if err := ValidateEnvoyConfigPath(configPath); err != nil {
+		log.Error().Err(err).Msgf("Invalid %s=%s, using the default path %s", envoyConfigPathKey, configPath, constants.EnvoyConfigPath)
+		return constants.EnvoyConfigPath
+	}
+	return configPath
+}
+
+// ValidateEnvoyConfigPath returns an error if the given path of the bootstrap config file of the Envoy sidecar is not
+// a clean absolute path to a file, or if the file is at the root of the filesystem. The directory of the file is
+// mounted from the bootstrap config secret, so it must not be shared with other files of the Envoy image.
+func ValidateEnvoyConfigPath(configPath string) error {
+	if !path.IsAbs(configPath) || path.Clean(configPath) != configPath {
+		return errors.Errorf("Envoy config path %q must be a clean absolute path", configPath)
+	}
+	if path.Dir(configPath) == "/" {
+		return errors.Errorf("Envoy config path %q must not be at the root of the filesystem", configPath)
+	}
+	return nil
+}
+
+// GetProxyUID returns the UID the sidecar proxy runs as, defaults to 1500
+func (c *Client) GetProxyUID() int64 {
+	proxyUID := int64(c.getConfigMap().ProxyUID)
+	if proxyUID == 0 {
+		return constants.EnvoyUID
+	}
+	if err := ValidateProxyUID(proxyUID); err != nil {
+		log.Error().Err(err).Msgf("Invalid %s=%d, using the default UID %d", proxyUIDKey, proxyUID, constants.EnvoyUID)
+		return constants.EnvoyUID
+	}
+	return proxyUID
+}
+
+// ValidateProxyUID returns an error if the given UID of the sidecar proxy is not between MinProxyUID and MaxProxyUID.
+// The root UID is not allowed, since the traffic of the processes running as the proxy UID is not intercepted.
+func ValidateProxyUID(proxyUID int64) error {
+	if proxyUID < MinProxyUID || proxyUID > MaxProxyUID {
+		return errors.Errorf("Proxy UID %d must be between %d and %d", proxyUID, MinProxyUID, MaxProxyUID)
+	}
+	return nil
+}
+
+// GetProxyProbeSettings returns the timing of the startup and liveness probes of the sidecar proxy, defaults to no
+// initial delay, a period of 5 seconds and a failure threshold of 24. Unset or invalid values fall back to the defaults.
+func (c *Client) GetProxyProbeSettings() ProxyProbeSettings {
+	cfg := c.getConfigMap()
+	return ProxyProbeSettings{
+		InitialDelaySeconds: getProxyProbeValue(proxyProbeInitialDelaySecondsKey, cfg.ProxyProbeInitialDelaySeconds, DefaultProxyProbeInitialDelaySeconds),
+		PeriodSeconds:       getProxyProbeValue(proxyProbePeriodSecondsKey, cfg.ProxyProbePeriodSeconds, DefaultProxyProbePeriodSeconds),
+		FailureThreshold:    getProxyProbeValue(proxyProbeFailureThresholdKey, cfg.ProxyProbeFailureThreshold, DefaultProxyProbeFailureThreshold),
+	}
+}
+
+// getProxyProbeValue returns the given value of a probe setting, or the given default value if the value is unset or
+// is not a positive 32-bit integer
+func getProxyProbeValue(key string, value int, defaultValue int32) int32 {
+	if value == 0 {
+		return defaultValue
+	}
+	if value < 0 || value > math.MaxInt32 {
+		log.Error().Msgf("Invalid %s=%d, using the default value %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return int32(value)
+}
+
+// GetProxyCABundle returns the secret or ConfigMap holding the CA bundle mounted in the sidecar proxy, or nil if no CA
+// bundle is configured or the configured CA bundle is invalid
+func (c *Client) GetProxyCABundle() *ProxyCABundle {
+	value := c.getConfigMap().ProxyCABundle
+	if value == "" {
+		return nil
+	}
+	caBundle, err := ParseProxyCABundle(value)
+	if err != nil {
+		log.Error().Err(err).Msgf("Invalid %s=%s, no CA bundle is mounted in the sidecar proxy", proxyCABundleKey, value)
+		return nil
+	}
+	return caBundle
+}
+
+// ParseProxyCABundle returns the CA bundle referenced by the given value of the form secret/<name> or
+// configmap/<name>, where the name is a valid DNS-1123 subdomain
+func ParseProxyCABundle(value string) (*ProxyCABundle, error) {
+	chunks := strings.Split(value, "/")
+	if len(chunks) != 2 || (chunks[0] != ProxyCABundleKindSecret && chunks[0] != ProxyCABundleKindConfigMap) {
+		return nil, errors.Errorf("CA bundle %q must be of the form %s/<name> or %s/<name>", value, ProxyCABundleKindSecret, ProxyCABundleKindConfigMap)
+	}
+	if errs := validation.IsDNS1123Subdomain(chunks[1]); len(errs) > 0 {
+		return nil, errors.Errorf("Invalid name %q of CA bundle %q: %s", chunks[1], value, strings.Join(errs, "; "))
+	}
+	return &ProxyCABundle{Kind: chunks[0], Name: chunks[1]}, nil
+}
+
+// GetProxyVolumes returns the volumes added to pods for the sidecar proxy, along with the volume mounts added to the
+// sidecar proxy, or nil if they are invalid
+func (c *Client) GetProxyVolumes() ([]corev1.Volume, []corev1.VolumeMount) {
+	cfg := c.getConfigMap()
+	volumes, volumeMounts, err := ParseProxyVolumes(cfg.ProxyVolumes, cfg.ProxyVolumeMounts)
+	if err != nil {
+		log.Error().Err(err).Msgf("Invalid %s or %s, no volumes are added to the sidecar proxy", proxyVolumesKey, proxyVolumeMountsKey)
+		return nil, nil
+	}
+	return volumes, volumeMounts
+}
+
+// ParseProxyVolumes returns the volumes and volume mounts given as JSON lists, either of which can be empty. The
+// names of the volumes must be unique DNS-1123 labels, and the mount paths of the volume mounts unique absolute paths.
+// A volume mount can reference a volume of the pod that is not among the given volumes.
+func ParseProxyVolumes(volumesJSON, volumeMountsJSON string) ([]corev1.Volume, []corev1.VolumeMount, error) {
+	var volumes []corev1.Volume
+	if volumesJSON != "" {
+		if err := json.Unmarshal([]byte(volumesJSON), &volumes); err != nil {
+			return nil, nil, errors.Errorf("Error parsing volumes %s: %s", volumesJSON, err)
+		}
+	}
+	var volumeMounts []corev1.VolumeMount
+	if volumeMountsJSON != "" {
+		if err := json.Unmarshal([]byte(volumeMountsJSON), &volumeMounts); err != nil {
+			return nil, nil, errors.Errorf("Error parsing volume mounts %s: %s", volumeMountsJSON, err)
+		}
+	}
+
+	volumeNames := make(map[string]bool, len(volumes))
+	for _, volume := range volumes {
+		if errs := validation.IsDNS1123Label(volume.Name); len(errs) > 0 {
+			return nil, nil, errors.Errorf("Invalid volume name %q: %s", volume.Name, strings.Join(errs, "; "))
+		}
+		if volumeNames[volume.Name] {
+			return nil, nil, errors.Errorf("Volume %s is listed more than once", volume.Name)
+		}
+		volumeNames[volume.Name] = true
+	}
+
+	mountPaths := make(map[string]bool, len(volumeMounts))
+	for _, volumeMount := range volumeMounts {
+		if volumeMount.Name == "" {
+			return nil, nil, errors.Errorf("Volume mount at %s does not reference a volume", volumeMount.MountPath)
+		}
+		if !path.IsAbs(volumeMount.MountPath) {
+			return nil, nil, errors.Errorf("Mount path %q of volume %s must be an absolute path", volumeMount.MountPath, volumeMount.Name)
+		}
+		if mountPaths[path.Clean(volumeMount.MountPath)] {
+			return nil, nil, errors.Errorf("Mount path %s is used by more than one volume mount", volumeMount.MountPath)
+		}
+		mountPaths[path.Clean(volumeMount.MountPath)] = true
+	}
+
+	return volumes, volumeMounts, nil
+}
+
+// getProxyServiceNameTemplate returns the given template if it is valid, and the given default template otherwise
+func getProxyServiceNameTemplate(key, tmpl, defaultTmpl string) string {
+	if tmpl == "" {
+		return defaultTmpl
+	}
+	if err := ValidateProxyServiceNameTemplate(tmpl); err != nil {
+		log.Error().Err(err).Msgf("Invalid %s=%s, using the default template %s", key, tmpl, defaultTmpl)
+		return defaultTmpl
+	}
+	return tmpl
+}
+
+// ValidateProxyServiceNameTemplate returns an error if the given template of a name passed to Envoy with
+// --service-node or --service-cluster cannot be parsed, references unknown variables, or renders an invalid name
+func ValidateProxyServiceNameTemplate(tmpl string) error {
+	_, err := RenderProxyServiceName(tmpl, ProxyServiceNameVars{
+		ServiceAccount: "service-account",
+		Namespace:      "namespace",
+		WorkloadKind:   "ReplicaSet",
+		WorkloadName:   "workload",
+	})
+	return err
+}
+
+// RenderProxyServiceName returns the name passed to Envoy with --service-node or --service-cluster rendered from the
+// given template. The rendered name must not be empty, and must not contain whitespace or the separator of the
+// fields of the Envoy service node.
+func RenderProxyServiceName(tmpl string, vars ProxyServiceNameVars) (string, error) {
+	t, err := template.New("proxy-service-name").Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", errors.Errorf("Error parsing template %q: %s", tmpl, err)
+	}
+
+	var name bytes.Buffer
+	if err := t.Execute(&name, vars); err != nil {
+		return "", errors.Errorf("Error rendering template %q: %s", tmpl, err)
+	}
+
+	rendered := name.String()
+	if rendered == "" {
+		return "", errors.Errorf("Template %q renders an empty name", tmpl)
+	}
+	if strings.ContainsAny(rendered, " \t\n"+constants.EnvoyServiceNodeSeparator) {
+		return "", errors.Errorf("Template %q renders the name %q, which must not contain whitespace or %q", tmpl, rendered, constants.EnvoyServiceNodeSeparator)
+	}
+	return rendered, nil
+}
+
+// parseKeyValues returns the pairs of the given comma separated list of key=value pairs, ignoring empty entries and
+// entries without a key
+func parseKeyValues(keyValuesStr string) map[string]string {
+	if keyValuesStr == "" {
+		return nil
+	}
+
+	keyValues := make(map[string]string)
+	for _, pair := range strings.Split(keyValuesStr, ",") {
+		keyValue := strings.SplitN(pair, "=", 2)
+		key := strings.TrimSpace(keyValue[0])
+		if key == "" {
+			continue
+		}
+		var value string
+		if len(keyValue) == 2 {
+			value = strings.TrimSpace(keyValue[1])
+		}
+		keyValues[key] = value
+	}
+
+	if len(keyValues) == 0 {
+		return nil
+	}
+	return keyValues
+}
@@ -45,7 +45,7 @@ func TestCreateUpdateConfig(t *testing.T) {
 			name: "default",
 			initialConfigMapData: map[string]string{
 				PermissiveTrafficPolicyModeKey: "false",
-				egressKey:                      "true",
+				EgressKey:                      "true",
 				enableDebugServer:              "true",
 				prometheusScrapingKey:          "true",
 				tracingEnableKey:               "true",
@@ -96,13 +96,13 @@ func TestCreateUpdateConfig(t *testing.T) {
 		{
 			name: "IsEgressEnabled",
 			initialConfigMapData: map[string]string{
-				egressKey: "true",
+				EgressKey: "true",
 			},
 			checkCreate: func(assert *tassert.Assertions, cfg Configurator) {
 				assert.True(cfg.IsEgressEnabled())
 			},
 			updatedConfigMapData: map[string]string{
-				egressKey: "false",
+				EgressKey: "false",
 			},
 			checkUpdate: func(assert *tassert.Assertions, cfg Configurator) {
 				assert.False(cfg.IsEgressEnabled())
@@ -138,6 +138,48 @@ func TestCreateUpdateConfig(t *testing.T) {
 				assert.False(cfg.IsPrometheusScrapingEnabled())
 			},
 		},
+		{
+			name:                 "GetPrometheusScrapePath",
+			initialConfigMapData: map[string]string{},
+			checkCreate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal(constants.PrometheusScrapePath, cfg.GetPrometheusScrapePath())
+			},
+			updatedConfigMapData: map[string]string{
+				prometheusScrapePathKey: "/metrics",
+			},
+			checkUpdate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal("/metrics", cfg.GetPrometheusScrapePath())
+			},
+		},
+		{
+			name: "GetPrometheusScrapePath with an invalid path",
+			initialConfigMapData: map[string]string{
+				prometheusScrapePathKey: "metrics",
+			},
+			checkCreate: func(assert *tassert.Assertions, cfg Configurator) {
+				// Invalid paths fall back to the default path
+				assert.Equal(constants.PrometheusScrapePath, cfg.GetPrometheusScrapePath())
+			},
+			updatedConfigMapData: map[string]string{
+				prometheusScrapePathKey: "/metrics?format=prometheus",
+			},
+			checkUpdate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal(constants.PrometheusScrapePath, cfg.GetPrometheusScrapePath())
+			},
+		},
+		{
+			name:                 "GetProxyStatsTagsEnabled",
+			initialConfigMapData: map[string]string{},
+			checkCreate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.False(cfg.GetProxyStatsTagsEnabled())
+			},
+			updatedConfigMapData: map[string]string{
+				proxyStatsTagsEnabledKey: "true",
+			},
+			checkUpdate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.True(cfg.GetProxyStatsTagsEnabled())
+			},
+		},
 		{
 			name: "IsTracingEnabled",
 			initialConfigMapData: map[string]string{
@@ -279,6 +321,323 @@ func TestCreateUpdateConfig(t *testing.T) {
 				assert.Equal(1000, cfg.GetMaxDataPlaneConnections())
 			},
 		},
+		{
+			name:                 "GetInjectorFailurePolicy",
+			initialConfigMapData: map[string]string{},
+			checkCreate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal(InjectorFailurePolicyFail, cfg.GetInjectorFailurePolicy())
+			},
+			updatedConfigMapData: map[string]string{
+				injectorFailurePolicyKey: InjectorFailurePolicyIgnore,
+			},
+			checkUpdate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal(InjectorFailurePolicyIgnore, cfg.GetInjectorFailurePolicy())
+			},
+		},
+		{
+			name:                 "GetProxyImagePullPolicy",
+			initialConfigMapData: map[string]string{},
+			checkCreate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal(v1.PullAlways, cfg.GetProxyImagePullPolicy())
+			},
+			updatedConfigMapData: map[string]string{
+				proxyImagePullPolicyKey: string(v1.PullIfNotPresent),
+			},
+			checkUpdate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal(v1.PullIfNotPresent, cfg.GetProxyImagePullPolicy())
+			},
+		},
+		{
+			name:                 "GetProxyImagePullSecrets",
+			initialConfigMapData: map[string]string{},
+			checkCreate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Nil(cfg.GetProxyImagePullSecrets())
+			},
+			updatedConfigMapData: map[string]string{
+				proxyImagePullSecretsKey: "registry-a, registry-b,",
+			},
+			checkUpdate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal([]string{"registry-a", "registry-b"}, cfg.GetProxyImagePullSecrets())
+			},
+		},
+		{
+			name:                 "GetProxyDrainTimeout",
+			initialConfigMapData: map[string]string{},
+			checkCreate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal(time.Duration(0), cfg.GetProxyDrainTimeout())
+			},
+			updatedConfigMapData: map[string]string{
+				proxyDrainTimeoutKey: "45s",
+			},
+			checkUpdate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal(45*time.Second, cfg.GetProxyDrainTimeout())
+			},
+		},
+		{
+			name:                 "GetInitContainerName",
+			initialConfigMapData: map[string]string{},
+			checkCreate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal(constants.InitContainerName, cfg.GetInitContainerName())
+			},
+			updatedConfigMapData: map[string]string{
+				initContainerNameKey: "mesh-init",
+			},
+			checkUpdate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal("mesh-init", cfg.GetInitContainerName())
+			},
+		},
+		{
+			name:                 "GetInjectedPodLabels",
+			initialConfigMapData: map[string]string{},
+			checkCreate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Nil(cfg.GetInjectedPodLabels())
+			},
+			updatedConfigMapData: map[string]string{
+				injectedPodLabelsKey: "team=payments, example.com/cost-center=42,,empty=",
+			},
+			checkUpdate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal(map[string]string{"team": "payments", "example.com/cost-center": "42", "empty": ""}, cfg.GetInjectedPodLabels())
+			},
+		},
+		{
+			name:                 "GetInjectedPodAnnotations",
+			initialConfigMapData: map[string]string{},
+			checkCreate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Nil(cfg.GetInjectedPodAnnotations())
+			},
+			updatedConfigMapData: map[string]string{
+				injectedPodAnnotationsKey: "example.com/owner=Payments team,example.com/query=a=b",
+			},
+			checkUpdate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal(map[string]string{"example.com/owner": "Payments team", "example.com/query": "a=b"}, cfg.GetInjectedPodAnnotations())
+			},
+		},
+		{
+			name:                 "GetCNIEnabled",
+			initialConfigMapData: map[string]string{},
+			checkCreate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.False(cfg.GetCNIEnabled())
+			},
+			updatedConfigMapData: map[string]string{
+				enableCNIKey: "true",
+			},
+			checkUpdate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.True(cfg.GetCNIEnabled())
+			},
+		},
+		{
+			name:                 "GetProxyEnv",
+			initialConfigMapData: map[string]string{},
+			checkCreate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Nil(cfg.GetProxyEnv())
+			},
+			updatedConfigMapData: map[string]string{
+				proxyEnvKey: "FEATURE_FLAGS=a=b, ENVOY_UID=1500,",
+			},
+			checkUpdate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal([]v1.EnvVar{
+					{Name: "ENVOY_UID", Value: "1500"},
+					{Name: "FEATURE_FLAGS", Value: "a=b"},
+				}, cfg.GetProxyEnv())
+			},
+		},
+		{
+			name:                 "GetProxyServiceNodeTemplate",
+			initialConfigMapData: map[string]string{},
+			checkCreate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal(DefaultProxyServiceNodeTemplate, cfg.GetProxyServiceNodeTemplate())
+			},
+			updatedConfigMapData: map[string]string{
+				proxyServiceNodeTemplateKey: "{{.ServiceAccount}}.{{.Namespace}}",
+			},
+			checkUpdate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal("{{.ServiceAccount}}.{{.Namespace}}", cfg.GetProxyServiceNodeTemplate())
+			},
+		},
+		{
+			name: "GetProxyServiceClusterTemplate",
+			initialConfigMapData: map[string]string{
+				proxyServiceClusterTemplateKey: "{{.Namespace}}.{{.WorkloadName}}",
+			},
+			checkCreate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal("{{.Namespace}}.{{.WorkloadName}}", cfg.GetProxyServiceClusterTemplate())
+			},
+			updatedConfigMapData: map[string]string{
+				proxyServiceClusterTemplateKey: "{{.Pod}}.{{.Namespace}}",
+			},
+			checkUpdate: func(assert *tassert.Assertions, cfg Configurator) {
+				// Invalid templates fall back to the default template
+				assert.Equal(DefaultProxyServiceClusterTemplate, cfg.GetProxyServiceClusterTemplate())
+			},
+		},
+		{
+			name:                 "GetEnvoyConfigPath",
+			initialConfigMapData: map[string]string{},
+			checkCreate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal(constants.EnvoyConfigPath, cfg.GetEnvoyConfigPath())
+			},
+			updatedConfigMapData: map[string]string{
+				envoyConfigPathKey: "/config/envoy.yaml",
+			},
+			checkUpdate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal("/config/envoy.yaml", cfg.GetEnvoyConfigPath())
+			},
+		},
+		{
+			name: "GetEnvoyConfigPath with an invalid path",
+			initialConfigMapData: map[string]string{
+				envoyConfigPathKey: "/bootstrap.yaml",
+			},
+			checkCreate: func(assert *tassert.Assertions, cfg Configurator) {
+				// Invalid paths fall back to the default path
+				assert.Equal(constants.EnvoyConfigPath, cfg.GetEnvoyConfigPath())
+			},
+			updatedConfigMapData: map[string]string{
+				envoyConfigPathKey: "/etc/envoy/../envoy.yaml",
+			},
+			checkUpdate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal(constants.EnvoyConfigPath, cfg.GetEnvoyConfigPath())
+			},
+		},
+		{
+			name:                 "GetProxyUID",
+			initialConfigMapData: map[string]string{},
+			checkCreate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal(constants.EnvoyUID, cfg.GetProxyUID())
+			},
+			updatedConfigMapData: map[string]string{
+				proxyUIDKey: "1000680000",
+			},
+			checkUpdate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal(int64(1000680000), cfg.GetProxyUID())
+			},
+		},
+		{
+			name: "GetProxyUID with an invalid UID",
+			initialConfigMapData: map[string]string{
+				proxyUIDKey: "-1",
+			},
+			checkCreate: func(assert *tassert.Assertions, cfg Configurator) {
+				// Invalid UIDs fall back to the default UID
+				assert.Equal(constants.EnvoyUID, cfg.GetProxyUID())
+			},
+			updatedConfigMapData: map[string]string{
+				proxyUIDKey: "2147483648",
+			},
+			checkUpdate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal(constants.EnvoyUID, cfg.GetProxyUID())
+			},
+		},
+		{
+			name:                 "GetProxyProbeSettings",
+			initialConfigMapData: map[string]string{},
+			checkCreate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal(ProxyProbeSettings{
+					InitialDelaySeconds: DefaultProxyProbeInitialDelaySeconds,
+					PeriodSeconds:       DefaultProxyProbePeriodSeconds,
+					FailureThreshold:    DefaultProxyProbeFailureThreshold,
+				}, cfg.GetProxyProbeSettings())
+			},
+			updatedConfigMapData: map[string]string{
+				proxyProbeInitialDelaySecondsKey: "10",
+				proxyProbePeriodSecondsKey:       "15",
+				proxyProbeFailureThresholdKey:    "-1",
+			},
+			checkUpdate: func(assert *tassert.Assertions, cfg Configurator) {
+				// Invalid values fall back to the default values
+				assert.Equal(ProxyProbeSettings{
+					InitialDelaySeconds: 10,
+					PeriodSeconds:       15,
+					FailureThreshold:    DefaultProxyProbeFailureThreshold,
+				}, cfg.GetProxyProbeSettings())
+			},
+		},
+		{
+			name:                 "GetProxyCABundle",
+			initialConfigMapData: map[string]string{},
+			checkCreate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Nil(cfg.GetProxyCABundle())
+			},
+			updatedConfigMapData: map[string]string{
+				proxyCABundleKey: "secret/vault-ca",
+			},
+			checkUpdate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Equal(&ProxyCABundle{Kind: ProxyCABundleKindSecret, Name: "vault-ca"}, cfg.GetProxyCABundle())
+			},
+		},
+		{
+			name: "GetProxyCABundle with an invalid CA bundle",
+			initialConfigMapData: map[string]string{
+				proxyCABundleKey: "configmap/Vault_CA",
+			},
+			checkCreate: func(assert *tassert.Assertions, cfg Configurator) {
+				// No CA bundle is mounted when the configured one is invalid
+				assert.Nil(cfg.GetProxyCABundle())
+			},
+			updatedConfigMapData: map[string]string{
+				proxyCABundleKey: "volume/vault-ca",
+			},
+			checkUpdate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.Nil(cfg.GetProxyCABundle())
+			},
+		},
+		{
+			name:                 "GetProxyVolumes",
+			initialConfigMapData: map[string]string{},
+			checkCreate: func(assert *tassert.Assertions, cfg Configurator) {
+				volumes, volumeMounts := cfg.GetProxyVolumes()
+				assert.Nil(volumes)
+				assert.Nil(volumeMounts)
+			},
+			updatedConfigMapData: map[string]string{
+				proxyVolumesKey:      `[{"name":"envoy-sockets","emptyDir":{}}]`,
+				proxyVolumeMountsKey: `[{"name":"envoy-sockets","mountPath":"/var/run/envoy-sockets"},{"name":"app-certs","mountPath":"/etc/app-certs","readOnly":true}]`,
+			},
+			checkUpdate: func(assert *tassert.Assertions, cfg Configurator) {
+				volumes, volumeMounts := cfg.GetProxyVolumes()
+				assert.Equal([]v1.Volume{{
+					Name:         "envoy-sockets",
+					VolumeSource: v1.VolumeSource{EmptyDir: &v1.EmptyDirVolumeSource{}},
+				}}, volumes)
+				assert.Equal([]v1.VolumeMount{
+					{Name: "envoy-sockets", MountPath: "/var/run/envoy-sockets"},
+					{Name: "app-certs", MountPath: "/etc/app-certs", ReadOnly: true},
+				}, volumeMounts)
+			},
+		},
+		{
+			name: "GetProxyVolumes with invalid volumes",
+			initialConfigMapData: map[string]string{
+				proxyVolumesKey: `[{"name":"envoy-sockets","emptyDir":{}},{"name":"envoy-sockets","emptyDir":{}}]`,
+			},
+			checkCreate: func(assert *tassert.Assertions, cfg Configurator) {
+				// No volumes are added when the configured ones are invalid
+				volumes, volumeMounts := cfg.GetProxyVolumes()
+				assert.Nil(volumes)
+				assert.Nil(volumeMounts)
+			},
+			updatedConfigMapData: map[string]string{
+				proxyVolumeMountsKey: `[{"name":"envoy-sockets","mountPath":"var/run/envoy-sockets"}]`,
+			},
+			checkUpdate: func(assert *tassert.Assertions, cfg Configurator) {
+				volumes, volumeMounts := cfg.GetProxyVolumes()
+				assert.Nil(volumes)
+				assert.Nil(volumeMounts)
+			},
+		},
+		{
+			name:                 "IsWaitForProxyReadyEnabled",
+			initialConfigMapData: map[string]string{},
+			checkCreate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.False(cfg.IsWaitForProxyReadyEnabled())
+			},
+			updatedConfigMapData: map[string]string{
+				waitForProxyReadyKey: "true",
+			},
+			checkUpdate: func(assert *tassert.Assertions, cfg Configurator) {
+				assert.True(cfg.IsWaitForProxyReadyEnabled())
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -327,3 +686,66 @@ func TestCreateUpdateConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestRenderProxyServiceName(t *testing.T) {
+	vars := ProxyServiceNameVars{
+		ServiceAccount: "bookstore",
+		Namespace:      "bookstore-ns",
+		WorkloadKind:   "ReplicaSet",
+		WorkloadName:   "bookstore-v1-5d5f",
+	}
+
+	testCases := []struct {
+		name         string
+		template     string
+		expectedName string
+		expectedErr  bool
+	}{
+		{
+			name:         "default service node template",
+			template:     DefaultProxyServiceNodeTemplate,
+			expectedName: "bookstore",
+		},
+		{
+			name:         "default service cluster template",
+			template:     DefaultProxyServiceClusterTemplate,
+			expectedName: "bookstore.bookstore-ns",
+		},
+		{
+			name:         "custom template",
+			template:     "{{.WorkloadKind}}-{{.WorkloadName}}.{{.Namespace}}",
+			expectedName: "ReplicaSet-bookstore-v1-5d5f.bookstore-ns",
+		},
+		{
+			name:        "unparsable template",
+			template:    "{{.ServiceAccount",
+			expectedErr: true,
+		},
+		{
+			name:        "unknown variable",
+			template:    "{{.PodName}}",
+			expectedErr: true,
+		},
+		{
+			name:        "empty name",
+			template:    "{{if false}}{{.ServiceAccount}}{{end}}",
+			expectedErr: true,
+		},
+		{
+			name:        "name containing the service node separator",
+			template:    "{{.Namespace}}/{{.ServiceAccount}}",
+			expectedErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := tassert.New(t)
+
+			name, err := RenderProxyServiceName(tc.template, vars)
+			assert.Equal(tc.expectedErr, err != nil)
+			assert.Equal(tc.expectedName, name)
+			assert.Equal(tc.expectedErr, ValidateProxyServiceNameTemplate(tc.template) != nil)
+		})
+	}
+}
@@ -9,6 +9,7 @@ import (
 	time "time"
 
 	gomock "github.com/golang/mock/gomock"
+	v1 "k8s.io/api/core/v1"
 )
 
 // MockConfigurator is a mock of Configurator interface
@@ -34,6 +35,20 @@ func (m *MockConfigurator) EXPECT() *MockConfiguratorMockRecorder {
 	return m.recorder
 }
 
+// GetCNIEnabled mocks base method
+func (m *MockConfigurator) GetCNIEnabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCNIEnabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// GetCNIEnabled indicates an expected call of GetCNIEnabled
+func (mr *MockConfiguratorMockRecorder) GetCNIEnabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCNIEnabled", reflect.TypeOf((*MockConfigurator)(nil).GetCNIEnabled))
+}
+
 // GetConfigMap mocks base method
 func (m *MockConfigurator) GetConfigMap() ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -63,6 +78,20 @@ func (mr *MockConfiguratorMockRecorder) GetConfigResyncInterval() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetConfigResyncInterval", reflect.TypeOf((*MockConfigurator)(nil).GetConfigResyncInterval))
 }
 
+// GetEnvoyConfigPath mocks base method
+func (m *MockConfigurator) GetEnvoyConfigPath() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEnvoyConfigPath")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetEnvoyConfigPath indicates an expected call of GetEnvoyConfigPath
+func (mr *MockConfiguratorMockRecorder) GetEnvoyConfigPath() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEnvoyConfigPath", reflect.TypeOf((*MockConfigurator)(nil).GetEnvoyConfigPath))
+}
+
 // GetEnvoyLogLevel mocks base method
 func (m *MockConfigurator) GetEnvoyLogLevel() string {
 	m.ctrl.T.Helper()
@@ -77,6 +106,62 @@ func (mr *MockConfiguratorMockRecorder) GetEnvoyLogLevel() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEnvoyLogLevel", reflect.TypeOf((*MockConfigurator)(nil).GetEnvoyLogLevel))
 }
 
+// GetInitContainerName mocks base method
+func (m *MockConfigurator) GetInitContainerName() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInitContainerName")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetInitContainerName indicates an expected call of GetInitContainerName
+func (mr *MockConfiguratorMockRecorder) GetInitContainerName() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInitContainerName", reflect.TypeOf((*MockConfigurator)(nil).GetInitContainerName))
+}
+
+// GetInjectedPodAnnotations mocks base method
+func (m *MockConfigurator) GetInjectedPodAnnotations() map[string]string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInjectedPodAnnotations")
+	ret0, _ := ret[0].(map[string]string)
+	return ret0
+}
+
+// GetInjectedPodAnnotations indicates an expected call of GetInjectedPodAnnotations
+func (mr *MockConfiguratorMockRecorder) GetInjectedPodAnnotations() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInjectedPodAnnotations", reflect.TypeOf((*MockConfigurator)(nil).GetInjectedPodAnnotations))
+}
+
+// GetInjectedPodLabels mocks base method
+func (m *MockConfigurator) GetInjectedPodLabels() map[string]string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInjectedPodLabels")
+	ret0, _ := ret[0].(map[string]string)
+	return ret0
+}
+
+// GetInjectedPodLabels indicates an expected call of GetInjectedPodLabels
+func (mr *MockConfiguratorMockRecorder) GetInjectedPodLabels() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInjectedPodLabels", reflect.TypeOf((*MockConfigurator)(nil).GetInjectedPodLabels))
+}
+
+// GetInjectorFailurePolicy mocks base method
+func (m *MockConfigurator) GetInjectorFailurePolicy() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetInjectorFailurePolicy")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetInjectorFailurePolicy indicates an expected call of GetInjectorFailurePolicy
+func (mr *MockConfiguratorMockRecorder) GetInjectorFailurePolicy() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetInjectorFailurePolicy", reflect.TypeOf((*MockConfigurator)(nil).GetInjectorFailurePolicy))
+}
+
 // GetMaxDataPlaneConnections mocks base method
 func (m *MockConfigurator) GetMaxDataPlaneConnections() int {
 	m.ctrl.T.Helper()
@@ -119,6 +204,175 @@ func (mr *MockConfiguratorMockRecorder) GetOutboundIPRangeExclusionList() *gomoc
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOutboundIPRangeExclusionList", reflect.TypeOf((*MockConfigurator)(nil).GetOutboundIPRangeExclusionList))
 }
 
+// GetPrometheusScrapePath mocks base method
+func (m *MockConfigurator) GetPrometheusScrapePath() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrometheusScrapePath")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetPrometheusScrapePath indicates an expected call of GetPrometheusScrapePath
+func (mr *MockConfiguratorMockRecorder) GetPrometheusScrapePath() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrometheusScrapePath", reflect.TypeOf((*MockConfigurator)(nil).GetPrometheusScrapePath))
+}
+
+// GetProxyDrainTimeout mocks base method
+func (m *MockConfigurator) GetProxyDrainTimeout() time.Duration {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProxyDrainTimeout")
+	ret0, _ := ret[0].(time.Duration)
+	return ret0
+}
+
+// GetProxyDrainTimeout indicates an expected call of GetProxyDrainTimeout
+func (mr *MockConfiguratorMockRecorder) GetProxyDrainTimeout() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProxyDrainTimeout", reflect.TypeOf((*MockConfigurator)(nil).GetProxyDrainTimeout))
+}
+
+// GetProxyEnv mocks base method
+func (m *MockConfigurator) GetProxyEnv() []v1.EnvVar {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProxyEnv")
+	ret0, _ := ret[0].([]v1.EnvVar)
+	return ret0
+}
+
+// GetProxyEnv indicates an expected call of GetProxyEnv
+func (mr *MockConfiguratorMockRecorder) GetProxyEnv() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProxyEnv", reflect.TypeOf((*MockConfigurator)(nil).GetProxyEnv))
+}
+
+// GetProxyImagePullPolicy mocks base method
+func (m *MockConfigurator) GetProxyImagePullPolicy() v1.PullPolicy {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProxyImagePullPolicy")
+	ret0, _ := ret[0].(v1.PullPolicy)
+	return ret0
+}
+
+// GetProxyImagePullPolicy indicates an expected call of GetProxyImagePullPolicy
+func (mr *MockConfiguratorMockRecorder) GetProxyImagePullPolicy() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProxyImagePullPolicy", reflect.TypeOf((*MockConfigurator)(nil).GetProxyImagePullPolicy))
+}
+
+// GetProxyImagePullSecrets mocks base method
+func (m *MockConfigurator) GetProxyImagePullSecrets() []string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProxyImagePullSecrets")
+	ret0, _ := ret[0].([]string)
+	return ret0
+}
+
+// GetProxyImagePullSecrets indicates an expected call of GetProxyImagePullSecrets
+func (mr *MockConfiguratorMockRecorder) GetProxyImagePullSecrets() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProxyImagePullSecrets", reflect.TypeOf((*MockConfigurator)(nil).GetProxyImagePullSecrets))
+}
+
+// GetProxyServiceClusterTemplate mocks base method
+func (m *MockConfigurator) GetProxyServiceClusterTemplate() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProxyServiceClusterTemplate")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetProxyServiceClusterTemplate indicates an expected call of GetProxyServiceClusterTemplate
+func (mr *MockConfiguratorMockRecorder) GetProxyServiceClusterTemplate() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProxyServiceClusterTemplate", reflect.TypeOf((*MockConfigurator)(nil).GetProxyServiceClusterTemplate))
+}
+
+// GetProxyServiceNodeTemplate mocks base method
+func (m *MockConfigurator) GetProxyServiceNodeTemplate() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProxyServiceNodeTemplate")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// GetProxyServiceNodeTemplate indicates an expected call of GetProxyServiceNodeTemplate
+func (mr *MockConfiguratorMockRecorder) GetProxyServiceNodeTemplate() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProxyServiceNodeTemplate", reflect.TypeOf((*MockConfigurator)(nil).GetProxyServiceNodeTemplate))
+}
+
+// GetProxyCABundle mocks base method
+func (m *MockConfigurator) GetProxyCABundle() *ProxyCABundle {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProxyCABundle")
+	ret0, _ := ret[0].(*ProxyCABundle)
+	return ret0
+}
+
+// GetProxyCABundle indicates an expected call of GetProxyCABundle
+func (mr *MockConfiguratorMockRecorder) GetProxyCABundle() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProxyCABundle", reflect.TypeOf((*MockConfigurator)(nil).GetProxyCABundle))
+}
+
+// GetProxyVolumes mocks base method
+func (m *MockConfigurator) GetProxyVolumes() ([]v1.Volume, []v1.VolumeMount) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProxyVolumes")
+	ret0, _ := ret[0].([]v1.Volume)
+	ret1, _ := ret[1].([]v1.VolumeMount)
+	return ret0, ret1
+}
+
+// GetProxyVolumes indicates an expected call of GetProxyVolumes
+func (mr *MockConfiguratorMockRecorder) GetProxyVolumes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProxyVolumes", reflect.TypeOf((*MockConfigurator)(nil).GetProxyVolumes))
+}
+
+// GetProxyProbeSettings mocks base method
+func (m *MockConfigurator) GetProxyProbeSettings() ProxyProbeSettings {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProxyProbeSettings")
+	ret0, _ := ret[0].(ProxyProbeSettings)
+	return ret0
+}
+
+// GetProxyProbeSettings indicates an expected call of GetProxyProbeSettings
+func (mr *MockConfiguratorMockRecorder) GetProxyProbeSettings() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProxyProbeSettings", reflect.TypeOf((*MockConfigurator)(nil).GetProxyProbeSettings))
+}
+
+// GetProxyStatsTagsEnabled mocks base method
+func (m *MockConfigurator) GetProxyStatsTagsEnabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProxyStatsTagsEnabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// GetProxyStatsTagsEnabled indicates an expected call of GetProxyStatsTagsEnabled
+func (mr *MockConfiguratorMockRecorder) GetProxyStatsTagsEnabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProxyStatsTagsEnabled", reflect.TypeOf((*MockConfigurator)(nil).GetProxyStatsTagsEnabled))
+}
+
+// GetProxyUID mocks base method
+func (m *MockConfigurator) GetProxyUID() int64 {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProxyUID")
+	ret0, _ := ret[0].(int64)
+	return ret0
+}
+
+// GetProxyUID indicates an expected call of GetProxyUID
+func (mr *MockConfiguratorMockRecorder) GetProxyUID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProxyUID", reflect.TypeOf((*MockConfigurator)(nil).GetProxyUID))
+}
+
 // GetServiceCertValidityPeriod mocks base method
 func (m *MockConfigurator) GetServiceCertValidityPeriod() time.Duration {
 	m.ctrl.T.Helper()
@@ -259,6 +513,20 @@ func (mr *MockConfiguratorMockRecorder) IsTracingEnabled() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsTracingEnabled", reflect.TypeOf((*MockConfigurator)(nil).IsTracingEnabled))
 }
 
+// IsWaitForProxyReadyEnabled mocks base method
+func (m *MockConfigurator) IsWaitForProxyReadyEnabled() bool {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsWaitForProxyReadyEnabled")
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// IsWaitForProxyReadyEnabled indicates an expected call of IsWaitForProxyReadyEnabled
+func (mr *MockConfiguratorMockRecorder) IsWaitForProxyReadyEnabled() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsWaitForProxyReadyEnabled", reflect.TypeOf((*MockConfigurator)(nil).IsWaitForProxyReadyEnabled))
+}
+
 // UseHTTPSIngress mocks base method
 func (m *MockConfigurator) UseHTTPSIngress() bool {
 	m.ctrl.T.Helper()
@@ -4,6 +4,7 @@ package configurator
 import (
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/client-go/tools/cache"
 
 	"github.com/openservicemesh/osm/pkg/logger"
@@ -51,6 +52,13 @@ type Configurator interface {
 	// IsPrometheusScrapingEnabled determines whether Prometheus is enabled for scraping metrics
 	IsPrometheusScrapingEnabled() bool
 
+	// GetPrometheusScrapePath returns the path Prometheus scrapes the sidecar proxy metrics from
+	GetPrometheusScrapePath() string
+
+	// GetProxyStatsTagsEnabled returns whether the cluster and route dimensions of the sidecar proxy stats are
+	// extracted as tags
+	GetProxyStatsTagsEnabled() bool
+
 	// IsTracingEnabled returns whether tracing is enabled
 	IsTracingEnabled() bool
 
@@ -75,7 +83,7 @@ type Configurator interface {
 	// GetServiceCertValidityPeriod returns the validity duration for service certificates
 	GetServiceCertValidityPeriod() time.Duration
 
-	// GetOutboundIPRangeExclusionList returns the list of IP ranges of the form x.x.x.x/y to exclude from outbound sidecar interception
+	// GetOutboundIPRangeExclusionList returns the list of IPv4 or IPv6 IP ranges of the form x.x.x.x/y or x:x::x/y to exclude from outbound sidecar interception
 	GetOutboundIPRangeExclusionList() []string
 
 	// IsPrivilegedInitContainer determines whether init containers should be privileged
@@ -84,4 +92,100 @@ type Configurator interface {
 	// GetConfigResyncInterval returns the duration for resync interval.
 	// If error or non-parsable value, returns 0 duration
 	GetConfigResyncInterval() time.Duration
+
+	// GetInjectorFailurePolicy returns how the sidecar injector responds when it fails to inject a pod
+	GetInjectorFailurePolicy() string
+
+	// GetProxyImagePullPolicy returns the image pull policy of the containers injected by the sidecar injector
+	GetProxyImagePullPolicy() corev1.PullPolicy
+
+	// GetProxyImagePullSecrets returns the names of the image pull secrets added to pods by the sidecar injector
+	GetProxyImagePullSecrets() []string
+
+	// GetProxyDrainTimeout returns the duration for which the sidecar proxy drains connections on pod termination.
+	// If error or non-parsable value, returns 0 duration, which disables draining
+	GetProxyDrainTimeout() time.Duration
+
+	// GetInitContainerName returns the name of the init container injected by the sidecar injector
+	GetInitContainerName() string
+
+	// GetInjectedPodLabels returns the labels added to pods by the sidecar injector
+	GetInjectedPodLabels() map[string]string
+
+	// GetInjectedPodAnnotations returns the annotations added to pods by the sidecar injector
+	GetInjectedPodAnnotations() map[string]string
+
+	// GetCNIEnabled returns whether a CNI plugin sets up the traffic redirection of meshed pods, in which case the
+	// sidecar injector does not inject the init container
+	GetCNIEnabled() bool
+
+	// GetProxyEnv returns the env vars added to the Envoy sidecar container, sorted by name
+	GetProxyEnv() []corev1.EnvVar
+
+	// GetProxyServiceNodeTemplate returns the template of the node name passed to Envoy with --service-node
+	GetProxyServiceNodeTemplate() string
+
+	// GetProxyServiceClusterTemplate returns the template of the cluster name passed to Envoy with --service-cluster
+	GetProxyServiceClusterTemplate() string
+
+	// IsWaitForProxyReadyEnabled returns whether the containers of meshed pods wait for the Envoy sidecar to be ready
+	// before starting, in which case the sidecar injector injects the sidecar as the first container of the pod
+	IsWaitForProxyReadyEnabled() bool
+
+	// GetEnvoyConfigPath returns the path of the bootstrap config file of the Envoy sidecar, passed to Envoy with
+	// --config-path. The directory of the file is the mount path of the bootstrap config secret volume.
+	GetEnvoyConfigPath() string
+
+	// GetProxyUID returns the UID the sidecar proxy runs as, whose outbound traffic is not intercepted
+	GetProxyUID() int64
+
+	// GetProxyProbeSettings returns the timing of the startup and liveness probes of the sidecar proxy
+	GetProxyProbeSettings() ProxyProbeSettings
+
+	// GetProxyCABundle returns the secret or ConfigMap holding the CA bundle mounted in the sidecar proxy, or nil if
+	// no CA bundle is configured
+	GetProxyCABundle() *ProxyCABundle
+
+	// GetProxyVolumes returns the volumes added to pods for the sidecar proxy, along with the volume mounts added to
+	// the sidecar proxy, referencing these volumes or the volumes of the pod by name
+	GetProxyVolumes() ([]corev1.Volume, []corev1.VolumeMount)
+}
+
+// ProxyCABundle is the secret or ConfigMap holding the CA bundle mounted in the sidecar proxy, e.g. the trust bundle
+// of an external certificate provider. It must exist in the namespace of each meshed pod.
+type ProxyCABundle struct {
+	// Kind is the kind of the resource holding the CA bundle, ProxyCABundleKindSecret or ProxyCABundleKindConfigMap
+	Kind string
+
+	// Name is the name of the resource holding the CA bundle
+	Name string
+}
+
+// ProxyProbeSettings is the timing of the startup and liveness probes of the sidecar proxy, so that slow-starting
+// environments do not restart the proxy prematurely
+type ProxyProbeSettings struct {
+	// InitialDelaySeconds is the number of seconds after the proxy has started before the probes are initiated
+	InitialDelaySeconds int32
+
+	// PeriodSeconds is how often the probes are performed
+	PeriodSeconds int32
+
+	// FailureThreshold is the number of consecutive failures of a probe after which the proxy is restarted
+	FailureThreshold int32
+}
+
+// ProxyServiceNameVars are the variables available to the templates of the names passed to Envoy with --service-node
+// and --service-cluster
+type ProxyServiceNameVars struct {
+	// ServiceAccount is the service account of the pod
+	ServiceAccount string
+
+	// Namespace is the namespace of the pod
+	Namespace string
+
+	// WorkloadKind is the kind of the controller of the pod, e.g. ReplicaSet
+	WorkloadKind string
+
+	// WorkloadName is the name of the controller of the pod
+	WorkloadName string
 }
@@ -11,6 +11,8 @@ import (
 	"strings"
 	"time"
 
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiTrafficSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned"
 	admissionv1 "k8s.io/api/admission/v1"
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -18,10 +20,12 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes"
 
 	"github.com/openservicemesh/osm/pkg/certificate"
 	"github.com/openservicemesh/osm/pkg/constants"
+	"github.com/openservicemesh/osm/pkg/smi"
 	"github.com/openservicemesh/osm/pkg/webhook"
 )
 
@@ -30,11 +34,15 @@ var (
 	deserializer = codecs.UniversalDeserializer()
 
 	// boolFields are the fields in osm-config that take in a boolean
-	boolFields = []string{"egress", "enable_debug_server", "permissive_traffic_policy_mode", "prometheus_scraping", "tracing_enable", "use_https_ingress", "enable_privileged_init_container"}
+	boolFields = []string{"egress", "enable_debug_server", "permissive_traffic_policy_mode", "prometheus_scraping", "tracing_enable", "use_https_ingress", "enable_privileged_init_container", "enable_cni", "wait_for_proxy_ready", "proxy_stats_tags_enabled"}
 
 	// ValidEnvoyLogLevels is a list of envoy log levels
 	ValidEnvoyLogLevels = []string{"trace", "debug", "info", "warning", "warn", "error", "critical", "off"}
 
+	// ReservedProxyEnvNames are the names of the env vars of the Envoy sidecar container managed by OSM, which cannot
+	// be set with proxy_env
+	ReservedProxyEnvNames = []string{"POD_UID", "POD_NAME", "POD_NAMESPACE", "POD_IP", "SERVICE_ACCOUNT"}
+
 	// defaultFields are the default fields in osm-config
 	defaultFields = []string{"egress", "enable_debug_server", "permissive_traffic_policy_mode", "prometheus_scraping", "use_https_ingress", "envoy_log_level", "service_cert_validity_duration", "tracing_enable", "enable_privileged_init_container", "max_data_plane_connections"}
 )
@@ -43,9 +51,15 @@ const (
 	// ValidatingWebhookName is the name of the validating webhook used for validating osm-config
 	ValidatingWebhookName = "osm-config-webhook.k8s.io"
 
+	// TrafficTargetValidatingWebhookName is the name of the validating webhook used for validating the references of SMI TrafficTargets
+	TrafficTargetValidatingWebhookName = "osm-traffic-target-webhook.k8s.io"
+
 	// webhookUpdateConfigMapis the HTTP path at which the webhook expects to receive configmap update events
 	webhookUpdateConfigMap = "/validate-webhook"
 
+	// webhookValidateTrafficTarget is the HTTP path at which the webhook expects to receive TrafficTarget create and update events
+	webhookValidateTrafficTarget = "/validate-traffic-target"
+
 	// listenPort is the validating webhook server port
 	listenPort = 9093
 
@@ -67,7 +81,49 @@ const (
 	// mustBeInPortRange is the reason for denial for tracing_port field
 	mustBeInPortRange = ": must be between 0 and 65535"
 
-	mustBeValidIPRange = ": must be a list of valid IP addresses of the form a.b.c.d/x"
+	mustBeValidIPRange = ": must be a list of valid IP addresses of the form a.b.c.d/x or a:b::c/x"
+
+	// mustBeValidFailurePolicy is the reason for denial for injector_failure_policy field
+	mustBeValidFailurePolicy = ": must be one of " + InjectorFailurePolicyFail + ", " + InjectorFailurePolicyIgnore
+
+	// mustBeValidPullPolicy is the reason for denial for proxy_image_pull_policy field
+	mustBeValidPullPolicy = ": must be one of Always, IfNotPresent, Never"
+
+	// mustBeValidContainerName is the reason for denial for init_container_name field
+	mustBeValidContainerName = ": must be a valid DNS-1123 label"
+
+	// mustBeValidLabels is the reason for denial for injected_pod_labels field
+	mustBeValidLabels = ": must be a list of valid labels of the form key=value"
+
+	// mustBeValidAnnotations is the reason for denial for injected_pod_annotations field
+	mustBeValidAnnotations = ": must be a list of annotations of the form key=value with valid keys"
+
+	// mustBeValidProxyEnv is the reason for denial for proxy_env field
+	mustBeValidProxyEnv = ": must be a list of env vars of the form NAME=value with valid names"
+
+	// mustNotSetReservedProxyEnv is the reason for denial for proxy_env field setting an env var managed by OSM
+	mustNotSetReservedProxyEnv = ": must not set the env vars managed by OSM: POD_UID, POD_NAME, POD_NAMESPACE, POD_IP, SERVICE_ACCOUNT"
+
+	// mustBeValidProxyServiceNameTemplate is the reason for denial for proxy_service_node_template and proxy_service_cluster_template fields
+	mustBeValidProxyServiceNameTemplate = ": must be a valid template using the variables .ServiceAccount, .Namespace, .WorkloadKind and .WorkloadName, rendering a name without whitespace or '/'"
+
+	// mustBeValidEnvoyConfigPath is the reason for denial for envoy_config_path field
+	mustBeValidEnvoyConfigPath = ": must be a clean absolute path to a file outside of the root directory"
+
+	// mustBeValidPrometheusScrapePath is the reason for denial for prometheus_scrape_path field
+	mustBeValidPrometheusScrapePath = ": must be a clean absolute path without query, fragment or whitespace"
+
+	// mustBeValidProxyUID is the reason for denial for proxy_uid field
+	mustBeValidProxyUID = ": must be an integer between 1 and 2147483647"
+
+	// mustBeValidProxyCABundle is the reason for denial for proxy_ca_bundle field
+	mustBeValidProxyCABundle = ": must be of the form secret/<name> or configmap/<name>"
+
+	// mustBeValidProxyVolumes is the reason for denial for proxy_volumes field
+	mustBeValidProxyVolumes = ": must be a JSON list of volumes with unique names"
+
+	// mustBeValidProxyVolumeMounts is the reason for denial for proxy_volume_mounts field
+	mustBeValidProxyVolumeMounts = ": must be a JSON list of volume mounts referencing a volume by name, with unique absolute mount paths"
 
 	// cannotChangeMetadata is the reason for denial for changes to configmap metadata
 	cannotChangeMetadata = ": cannot change metadata"
@@ -81,14 +137,15 @@ const (
 )
 
 type webhookConfig struct {
-	kubeClient   kubernetes.Interface
-	cert         certificate.Certificater
-	certManager  certificate.Manager
-	osmNamespace string
+	kubeClient    kubernetes.Interface
+	smiSpecClient smiTrafficSpecClient.Interface
+	cert          certificate.Certificater
+	certManager   certificate.Manager
+	osmNamespace  string
 }
 
 // NewValidatingWebhook  starts a new web server handling requests from the  ValidatingWebhookConfiguration
-func NewValidatingWebhook(kubeClient kubernetes.Interface, certManager certificate.Manager, osmNamespace, webhookConfigName string, stop <-chan struct{}) error {
+func NewValidatingWebhook(kubeClient kubernetes.Interface, smiSpecClient smiTrafficSpecClient.Interface, certManager certificate.Manager, osmNamespace, webhookConfigName string, stop <-chan struct{}) error {
 	cn := certificate.CommonName(fmt.Sprintf("%s.%s.svc", validatorServiceName, osmNamespace))
 	cert, err := certManager.IssueCertificate(cn, constants.XDSCertificateValidityPeriod)
 	if err != nil {
@@ -97,10 +154,11 @@ func NewValidatingWebhook(kubeClient kubernetes.Interface, certManager certifica
 	}
 
 	whc := &webhookConfig{
-		kubeClient:   kubeClient,
-		certManager:  certManager,
-		osmNamespace: osmNamespace,
-		cert:         cert,
+		kubeClient:    kubeClient,
+		smiSpecClient: smiSpecClient,
+		certManager:   certManager,
+		osmNamespace:  osmNamespace,
+		cert:          cert,
 	}
 
 	// Start the ValidatingWebhook web server
@@ -121,6 +179,7 @@ func (whc *webhookConfig) runValidatingWebhook(stop <-chan struct{}) {
 	mux := http.NewServeMux()
 
 	mux.HandleFunc(webhookUpdateConfigMap, whc.configMapHandler)
+	mux.HandleFunc(webhookValidateTrafficTarget, whc.trafficTargetHandler)
 
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", listenPort),
@@ -162,6 +221,15 @@ func (whc *webhookConfig) runValidatingWebhook(stop <-chan struct{}) {
 	}
 }
 func (whc *webhookConfig) configMapHandler(w http.ResponseWriter, req *http.Request) {
+	whc.handleAdmissionRequest(w, req, "configmap", whc.validateConfigMap)
+}
+
+func (whc *webhookConfig) trafficTargetHandler(w http.ResponseWriter, req *http.Request) {
+	whc.handleAdmissionRequest(w, req, "TrafficTarget", whc.validateTrafficTarget)
+}
+
+// handleAdmissionRequest responds to the admission request with the response of the given validation function
+func (whc *webhookConfig) handleAdmissionRequest(w http.ResponseWriter, req *http.Request, resource string, validate func(*admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse) {
 	log.Trace().Msgf("Received validating webhook request: Method=%v, URL=%v", req.Method, req.URL)
 
 	admissionRequestBody, err := webhook.GetAdmissionRequestBody(w, req)
@@ -170,27 +238,27 @@ func (whc *webhookConfig) configMapHandler(w http.ResponseWriter, req *http.Requ
 		return
 	}
 
-	requestForNamespace, admissionResp := whc.getAdmissionReqResp(admissionRequestBody)
+	requestForNamespace, admissionResp := getAdmissionReqResp(admissionRequestBody, validate)
 
 	resp, err := json.Marshal(&admissionResp)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("Error marshalling admission response: %s", err), http.StatusInternalServerError)
-		log.Error().Err(err).Msgf("Error marshalling admission response; Responded to admission request for configmap in namespace %s with HTTP %v", requestForNamespace, http.StatusInternalServerError)
+		log.Error().Err(err).Msgf("Error marshalling admission response; Responded to admission request for %s in namespace %s with HTTP %v", resource, requestForNamespace, http.StatusInternalServerError)
 		return
 	}
 
 	if _, err := w.Write(resp); err != nil {
-		log.Error().Err(err).Msgf("Error writing admission response for pod in namespace %s", requestForNamespace)
+		log.Error().Err(err).Msgf("Error writing admission response for %s in namespace %s", resource, requestForNamespace)
 	}
 }
 
-func (whc *webhookConfig) getAdmissionReqResp(admissionRequestBody []byte) (requestForNamespace string, admissionResp admissionv1.AdmissionReview) {
+func getAdmissionReqResp(admissionRequestBody []byte, validate func(*admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse) (requestForNamespace string, admissionResp admissionv1.AdmissionReview) {
 	var admissionReq admissionv1.AdmissionReview
 	if _, _, err := deserializer.Decode(admissionRequestBody, nil, &admissionReq); err != nil {
 		log.Error().Err(err).Msg("Error decoding admission request body")
 		admissionResp.Response = webhook.AdmissionError(err)
 	} else {
-		admissionResp.Response = whc.validateConfigMap(admissionReq.Request)
+		admissionResp.Response = validate(admissionReq.Request)
 	}
 	admissionResp.TypeMeta = admissionReq.TypeMeta
 	admissionResp.Kind = admissionReq.Kind
@@ -231,6 +299,39 @@ func (whc *webhookConfig) validateConfigMap(req *admissionv1.AdmissionRequest) *
 	return resp
 }
 
+func (whc *webhookConfig) validateTrafficTarget(req *admissionv1.AdmissionRequest) *admissionv1.AdmissionResponse {
+	if req == nil {
+		log.Error().Msg("nil admission request")
+		return webhook.AdmissionError(errNilAdmissionRequest)
+	}
+
+	// Decode the TrafficTarget from the request
+	var trafficTarget smiAccess.TrafficTarget
+	if _, _, err := deserializer.Decode(req.Object.Raw, nil, &trafficTarget); err != nil {
+		log.Error().Err(err).Msgf("Error unmarshaling request to TrafficTarget in namespace %s", req.Namespace)
+		return webhook.AdmissionError(err)
+	}
+	if trafficTarget.Namespace == "" {
+		trafficTarget.Namespace = req.Namespace
+	}
+
+	resp := &admissionv1.AdmissionResponse{
+		Allowed: true,
+		Result:  &metav1.Status{Reason: ""},
+		UID:     req.UID,
+	}
+
+	if err := smi.ValidateTrafficTargetReferences(&trafficTarget, whc.kubeClient, whc.smiSpecClient); err != nil {
+		log.Error().Err(err).Msgf("Rejecting TrafficTarget %s/%s", trafficTarget.Namespace, trafficTarget.Name)
+		resp.Allowed = false
+		resp.Result = &metav1.Status{
+			Reason:  metav1.StatusReasonInvalid,
+			Message: err.Error(),
+		}
+	}
+	return resp
+}
+
 // checkDefaultFields checks that all default fields for osm-config exist
 func checkDefaultFields(configMap corev1.ConfigMap, resp *admissionv1.AdmissionResponse) *admissionv1.AdmissionResponse {
 	data := make(map[string]struct{})
@@ -255,7 +356,7 @@ func (whc *webhookConfig) validateFields(configMap corev1.ConfigMap, resp *admis
 		if field == envoyLogLevel && !checkEnvoyLogLevels(field, value) {
 			reasonForDenial(resp, mustBeValidLogLvl, field)
 		}
-		if field == serviceCertValidityDurationKey || field == configResyncInterval {
+		if field == serviceCertValidityDurationKey || field == configResyncInterval || field == proxyDrainTimeoutKey {
 			_, err := time.ParseDuration(value)
 			if err != nil {
 				reasonForDenial(resp, mustBeValidTime, field)
@@ -273,12 +374,70 @@ func (whc *webhookConfig) validateFields(configMap corev1.ConfigMap, resp *admis
 		if field == outboundIPRangeExclusionListKey && !checkOutboundIPRangeExclusionList(value) {
 			reasonForDenial(resp, mustBeValidIPRange, field)
 		}
+		if field == injectorFailurePolicyKey && value != InjectorFailurePolicyFail && value != InjectorFailurePolicyIgnore {
+			reasonForDenial(resp, mustBeValidFailurePolicy, field)
+		}
+		if field == proxyImagePullPolicyKey && !checkPullPolicy(value) {
+			reasonForDenial(resp, mustBeValidPullPolicy, field)
+		}
+		if field == initContainerNameKey && len(validation.IsDNS1123Label(value)) > 0 {
+			reasonForDenial(resp, mustBeValidContainerName, field)
+		}
+		if field == injectedPodLabelsKey && !checkKeyValues(value, true) {
+			reasonForDenial(resp, mustBeValidLabels, field)
+		}
+		if field == injectedPodAnnotationsKey && !checkKeyValues(value, false) {
+			reasonForDenial(resp, mustBeValidAnnotations, field)
+		}
+		if field == proxyEnvKey {
+			if !checkProxyEnv(value) {
+				reasonForDenial(resp, mustBeValidProxyEnv, field)
+			} else if setsReservedProxyEnv(value) {
+				reasonForDenial(resp, mustNotSetReservedProxyEnv, field)
+			}
+		}
+		if (field == proxyServiceNodeTemplateKey || field == proxyServiceClusterTemplateKey) && ValidateProxyServiceNameTemplate(value) != nil {
+			reasonForDenial(resp, mustBeValidProxyServiceNameTemplate, field)
+		}
+		if field == envoyConfigPathKey && ValidateEnvoyConfigPath(value) != nil {
+			reasonForDenial(resp, mustBeValidEnvoyConfigPath, field)
+		}
+		if field == prometheusScrapePathKey && ValidatePrometheusScrapePath(value) != nil {
+			reasonForDenial(resp, mustBeValidPrometheusScrapePath, field)
+		}
 		if field == maxDataPlaneConnectionsKey {
 			maxNum, err := strconv.Atoi(value)
 			if err != nil || maxNum < 0 {
 				reasonForDenial(resp, mustBePositiveInt, field)
 			}
 		}
+		if field == proxyProbeInitialDelaySecondsKey || field == proxyProbePeriodSecondsKey || field == proxyProbeFailureThresholdKey {
+			probeValue, err := strconv.ParseInt(value, 10, 32)
+			if err != nil || probeValue < 0 {
+				reasonForDenial(resp, mustBePositiveInt, field)
+			}
+		}
+		if field == proxyUIDKey {
+			proxyUID, err := strconv.ParseInt(value, 10, 64)
+			if err != nil || ValidateProxyUID(proxyUID) != nil {
+				reasonForDenial(resp, mustBeValidProxyUID, field)
+			}
+		}
+		if field == proxyCABundleKey && value != "" {
+			if _, err := ParseProxyCABundle(value); err != nil {
+				reasonForDenial(resp, mustBeValidProxyCABundle, field)
+			}
+		}
+		if field == proxyVolumesKey {
+			if _, _, err := ParseProxyVolumes(value, ""); err != nil {
+				reasonForDenial(resp, mustBeValidProxyVolumes, field)
+			}
+		}
+		if field == proxyVolumeMountsKey {
+			if _, _, err := ParseProxyVolumes("", value); err != nil {
+				reasonForDenial(resp, mustBeValidProxyVolumeMounts, field)
+			}
+		}
 	}
 
 	defConfigMap, _ := whc.kubeClient.CoreV1().ConfigMaps(whc.osmNamespace).Get(context.TODO(), constants.OSMConfigMap, metav1.GetOptions{})
@@ -307,6 +466,16 @@ func checkEnvoyLogLevels(configMapField, configMapValue string) bool {
 	return valid
 }
 
+// checkPullPolicy checks that the field value is a valid image pull policy
+func checkPullPolicy(configMapValue string) bool {
+	switch corev1.PullPolicy(configMapValue) {
+	case corev1.PullAlways, corev1.PullIfNotPresent, corev1.PullNever:
+		return true
+	default:
+		return false
+	}
+}
+
 func checkOutboundIPRangeExclusionList(ipRangesStr string) bool {
 	exclusionList := strings.Split(ipRangesStr, ",")
 	for i := range exclusionList {
@@ -318,6 +487,50 @@ func checkOutboundIPRangeExclusionList(ipRangesStr string) bool {
 	return true
 }
 
+// checkKeyValues checks that the field value is a list of key=value pairs with valid label or annotation keys,
+// and valid label values when checking labels
+func checkKeyValues(keyValuesStr string, isLabel bool) bool {
+	for _, pair := range strings.Split(keyValuesStr, ",") {
+		keyValue := strings.SplitN(pair, "=", 2)
+		if len(keyValue) != 2 {
+			return false
+		}
+		if len(validation.IsQualifiedName(strings.TrimSpace(keyValue[0]))) > 0 {
+			return false
+		}
+		if isLabel && len(validation.IsValidLabelValue(strings.TrimSpace(keyValue[1]))) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// checkProxyEnv checks that the field value is a list of NAME=value pairs with valid env var names
+func checkProxyEnv(envStr string) bool {
+	for _, pair := range strings.Split(envStr, ",") {
+		nameValue := strings.SplitN(pair, "=", 2)
+		if len(nameValue) != 2 {
+			return false
+		}
+		if len(validation.IsEnvVarName(strings.TrimSpace(nameValue[0]))) > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// setsReservedProxyEnv returns whether the given list of NAME=value pairs sets an env var managed by OSM
+func setsReservedProxyEnv(envStr string) bool {
+	for name := range parseKeyValues(envStr) {
+		for _, reserved := range ReservedProxyEnvNames {
+			if name == reserved {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // checkBoolFields checks that the value is a boolean for fields that take in a boolean
 func checkBoolFields(configMapField, configMapValue string, fields []string) bool {
 	for _, f := range fields {
@@ -341,37 +554,47 @@ func reasonForDenial(resp *admissionv1.AdmissionResponse, mustBe string, field s
 }
 
 // getPartialValidatingWebhookConfiguration returns only the portion of the ValidatingWebhookConfiguration that needs to be updated.
-func getPartialValidatingWebhookConfiguration(webhookName string, cert certificate.Certificater, webhookConfigName string) admissionregv1.ValidatingWebhookConfiguration {
-	return admissionregv1.ValidatingWebhookConfiguration{
+func getPartialValidatingWebhookConfiguration(cert certificate.Certificater, webhookConfigName string, webhookNames ...string) admissionregv1.ValidatingWebhookConfiguration {
+	config := admissionregv1.ValidatingWebhookConfiguration{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: webhookConfigName,
 		},
-		Webhooks: []admissionregv1.ValidatingWebhook{
-			{
-				Name: webhookName,
-				ClientConfig: admissionregv1.WebhookClientConfig{
-					CABundle: cert.GetCertificateChain(),
-				},
-				SideEffects: func() *admissionregv1.SideEffectClass {
-					sideEffect := admissionregv1.SideEffectClassNone
-					return &sideEffect
-				}(),
-				AdmissionReviewVersions: []string{"v1"},
+	}
+	for _, webhookName := range webhookNames {
+		config.Webhooks = append(config.Webhooks, admissionregv1.ValidatingWebhook{
+			Name: webhookName,
+			ClientConfig: admissionregv1.WebhookClientConfig{
+				CABundle: cert.GetCertificateChain(),
 			},
-		},
+			SideEffects: func() *admissionregv1.SideEffectClass {
+				sideEffect := admissionregv1.SideEffectClassNone
+				return &sideEffect
+			}(),
+			AdmissionReviewVersions: []string{"v1"},
+		})
 	}
+	return config
 }
 
 // updateValidatingWebhookCABundle updates the existing ValidatingWebhookConfiguration with the CA this OSM instance runs with.
 // It is necessary to perform this patch because the original ValidatingWebhookConfig YAML does not contain the root certificate.
 func updateValidatingWebhookCABundle(cert certificate.Certificater, webhookName string, clientSet kubernetes.Interface) error {
 	vwc := clientSet.AdmissionregistrationV1().ValidatingWebhookConfigurations()
-	if _, err := vwc.Get(context.Background(), webhookName, metav1.GetOptions{}); err != nil {
+	existingConfig, err := vwc.Get(context.Background(), webhookName, metav1.GetOptions{})
+	if err != nil {
 		log.Error().Err(err).Msgf("Error getting ValidatingWebhookConfiguration %s; Will not update CA Bundle for webhook", webhookName)
 		return err
 	}
 
-	patchJSON, err := json.Marshal(getPartialValidatingWebhookConfiguration(ValidatingWebhookName, cert, webhookName))
+	// The TrafficTarget webhook is optional, only patch it when it is part of the configuration
+	webhookNames := []string{ValidatingWebhookName}
+	for _, existingWebhook := range existingConfig.Webhooks {
+		if existingWebhook.Name == TrafficTargetValidatingWebhookName {
+			webhookNames = append(webhookNames, TrafficTargetValidatingWebhookName)
+		}
+	}
+
+	patchJSON, err := json.Marshal(getPartialValidatingWebhookConfiguration(cert, webhookName, webhookNames...))
 	if err != nil {
 		return err
 	}
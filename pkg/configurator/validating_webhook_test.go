@@ -2,6 +2,7 @@ package configurator
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -12,6 +13,9 @@ import (
 
 	"github.com/golang/mock/gomock"
 	"github.com/pkg/errors"
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiSpecs "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/specs/v1alpha4"
+	fakeSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned/fake"
 	tassert "github.com/stretchr/testify/assert"
 	admissionv1 "k8s.io/api/admission/v1"
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
@@ -85,7 +89,7 @@ func TestNewValidatingWebhook(t *testing.T) {
 	}
 	for _, tc := range testCases {
 		t.Run(tc.testName, func(t *testing.T) {
-			res := NewValidatingWebhook(kubeClient, certManager, whc.osmNamespace, tc.webhookName, stop)
+			res := NewValidatingWebhook(kubeClient, fakeSpecClient.NewSimpleClientset(), certManager, whc.osmNamespace, tc.webhookName, stop)
 			_ = tc.mockCall
 			assert.Equal(tc.expErr, res.Error())
 		})
@@ -110,7 +114,7 @@ func TestConfigMapHandler(t *testing.T) {
 func TestGetAdmissionReqResp(t *testing.T) {
 	assert := tassert.New(t)
 
-	requestForNamespace, admissionResp := whc.getAdmissionReqResp([]byte(admissionRequestBody))
+	requestForNamespace, admissionResp := getAdmissionReqResp([]byte(admissionRequestBody), whc.validateConfigMap)
 
 	expectedAdmissionResponse := admissionv1.AdmissionReview{
 		TypeMeta: metav1.TypeMeta{Kind: "AdmissionReview", APIVersion: "admission.k8s.io/v1"},
@@ -384,6 +388,30 @@ func TestValidateFields(t *testing.T) {
 				Result:  &metav1.Status{Reason: "\noutbound_ip_range_exclusion_list" + mustBeValidIPRange},
 			},
 		},
+		{
+			testName: "Reject configmap with invalid IPv6 outbound IP range exclusions",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"outbound_ip_range_exclusion_list": "10.0.0.0/8, 2001:db8::/129",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result:  &metav1.Status{Reason: "\noutbound_ip_range_exclusion_list" + mustBeValidIPRange},
+			},
+		},
+		{
+			testName: "Accept valid IPv4 and IPv6 outbound IP range exclusions",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"outbound_ip_range_exclusion_list": "10.0.0.0/8, 2001:db8::/32",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: true,
+				Result:  &metav1.Status{Reason: ""},
+			},
+		},
 		{
 			testName: "Reject invalid max_data_plane_connections update",
 			configMap: corev1.ConfigMap{
@@ -408,6 +436,384 @@ func TestValidateFields(t *testing.T) {
 				Result:  &metav1.Status{Reason: "\nmax_data_plane_connections" + mustBePositiveInt},
 			},
 		},
+		{
+			testName: "Reject invalid injector_failure_policy update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"injector_failure_policy": "allow",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result:  &metav1.Status{Reason: "\ninjector_failure_policy" + mustBeValidFailurePolicy},
+			},
+		},
+		{
+			testName: "Reject invalid proxy_image_pull_policy update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"proxy_image_pull_policy": "Sometimes",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result:  &metav1.Status{Reason: "\nproxy_image_pull_policy" + mustBeValidPullPolicy},
+			},
+		},
+		{
+			testName: "Reject invalid proxy_drain_timeout update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"proxy_drain_timeout": "30",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result:  &metav1.Status{Reason: "\nproxy_drain_timeout" + mustBeValidTime},
+			},
+		},
+		{
+			testName: "Reject invalid init_container_name update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"init_container_name": "Mesh_Init",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result:  &metav1.Status{Reason: "\ninit_container_name" + mustBeValidContainerName},
+			},
+		},
+		{
+			testName: "Reject invalid injected_pod_labels update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"injected_pod_labels": "team=payments,cost center=42",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result:  &metav1.Status{Reason: "\ninjected_pod_labels" + mustBeValidLabels},
+			},
+		},
+		{
+			testName: "Reject invalid injected_pod_annotations update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"injected_pod_annotations": "example.com/owner",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result:  &metav1.Status{Reason: "\ninjected_pod_annotations" + mustBeValidAnnotations},
+			},
+		},
+		{
+			testName: "Accept valid injected_pod_labels and injected_pod_annotations update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"injected_pod_labels":      "team=payments, example.com/cost-center=42",
+					"injected_pod_annotations": "example.com/owner=Payments team",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: true,
+				Result:  &metav1.Status{Reason: ""},
+			},
+		},
+		{
+			testName: "Reject invalid proxy_env update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"proxy_env": "ENVOY_UID=1500,1_INVALID=true",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result:  &metav1.Status{Reason: "\nproxy_env" + mustBeValidProxyEnv},
+			},
+		},
+		{
+			testName: "Reject proxy_env update setting an env var managed by OSM",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"proxy_env": "ENVOY_UID=1500, POD_NAME=bookstore",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result:  &metav1.Status{Reason: "\nproxy_env" + mustNotSetReservedProxyEnv},
+			},
+		},
+		{
+			testName: "Reject invalid proxy_service_node_template update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"proxy_service_node_template": "{{.Namespace}}/{{.ServiceAccount}}",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result:  &metav1.Status{Reason: "\nproxy_service_node_template" + mustBeValidProxyServiceNameTemplate},
+			},
+		},
+		{
+			testName: "Reject invalid proxy_service_cluster_template update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"proxy_service_cluster_template": "{{.ServiceAccount}.{{.Namespace}}",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result:  &metav1.Status{Reason: "\nproxy_service_cluster_template" + mustBeValidProxyServiceNameTemplate},
+			},
+		},
+		{
+			testName: "Reject relative envoy_config_path update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"envoy_config_path": "envoy/bootstrap.yaml",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result:  &metav1.Status{Reason: "\nenvoy_config_path" + mustBeValidEnvoyConfigPath},
+			},
+		},
+		{
+			testName: "Reject envoy_config_path update at the root directory",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"envoy_config_path": "/bootstrap.yaml",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result:  &metav1.Status{Reason: "\nenvoy_config_path" + mustBeValidEnvoyConfigPath},
+			},
+		},
+		{
+			testName: "Accept valid envoy_config_path update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"envoy_config_path": "/config/envoy.yaml",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: true,
+				Result:  &metav1.Status{Reason: ""},
+			},
+		},
+		{
+			testName: "Reject prometheus_scrape_path update with a query",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"prometheus_scrape_path": "/stats/prometheus?usedonly",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result:  &metav1.Status{Reason: "\nprometheus_scrape_path" + mustBeValidPrometheusScrapePath},
+			},
+		},
+		{
+			testName: "Reject relative prometheus_scrape_path update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"prometheus_scrape_path": "metrics",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result:  &metav1.Status{Reason: "\nprometheus_scrape_path" + mustBeValidPrometheusScrapePath},
+			},
+		},
+		{
+			testName: "Accept valid prometheus_scrape_path and proxy_stats_tags_enabled update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"prometheus_scrape_path":   "/metrics",
+					"proxy_stats_tags_enabled": "true",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: true,
+				Result:  &metav1.Status{Reason: ""},
+			},
+		},
+		{
+			testName: "Reject negative proxy_probe_failure_threshold update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"proxy_probe_failure_threshold": "-3",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result:  &metav1.Status{Reason: "\nproxy_probe_failure_threshold" + mustBePositiveInt},
+			},
+		},
+		{
+			testName: "Reject non-integer proxy_probe_period_seconds update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"proxy_probe_period_seconds": "5s",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result:  &metav1.Status{Reason: "\nproxy_probe_period_seconds" + mustBePositiveInt},
+			},
+		},
+		{
+			testName: "Accept valid proxy probe settings update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"proxy_probe_initial_delay_seconds": "0",
+					"proxy_probe_period_seconds":        "10",
+					"proxy_probe_failure_threshold":     "30",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: true,
+				Result:  &metav1.Status{Reason: ""},
+			},
+		},
+		{
+			testName: "Reject root proxy_uid update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"proxy_uid": "0",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result:  &metav1.Status{Reason: "\nproxy_uid" + mustBeValidProxyUID},
+			},
+		},
+		{
+			testName: "Reject invalid proxy_uid update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"proxy_uid": "2147483648",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result:  &metav1.Status{Reason: "\nproxy_uid" + mustBeValidProxyUID},
+			},
+		},
+		{
+			testName: "Accept valid proxy_uid update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"proxy_uid": "1000680000",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: true,
+				Result:  &metav1.Status{Reason: ""},
+			},
+		},
+		{
+			testName: "Reject invalid proxy_ca_bundle update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"proxy_ca_bundle": "vault-ca",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result:  &metav1.Status{Reason: "\nproxy_ca_bundle" + mustBeValidProxyCABundle},
+			},
+		},
+		{
+			testName: "Accept valid proxy_ca_bundle update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"proxy_ca_bundle": "configmap/vault-ca",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: true,
+				Result:  &metav1.Status{Reason: ""},
+			},
+		},
+		{
+			testName: "Reject invalid proxy_volumes update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"proxy_volumes": `[{"name":"Envoy_Sockets","emptyDir":{}}]`,
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result:  &metav1.Status{Reason: "\nproxy_volumes" + mustBeValidProxyVolumes},
+			},
+		},
+		{
+			testName: "Reject invalid proxy_volume_mounts update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"proxy_volume_mounts": `[{"name":"envoy-sockets","mountPath":"/var/run/sockets"},{"name":"app-sockets","mountPath":"/var/run/sockets/"}]`,
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: false,
+				Result:  &metav1.Status{Reason: "\nproxy_volume_mounts" + mustBeValidProxyVolumeMounts},
+			},
+		},
+		{
+			testName: "Accept valid proxy_volumes and proxy_volume_mounts update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"proxy_volumes":       `[{"name":"envoy-sockets","emptyDir":{}}]`,
+					"proxy_volume_mounts": `[{"name":"envoy-sockets","mountPath":"/var/run/sockets"}]`,
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: true,
+				Result:  &metav1.Status{Reason: ""},
+			},
+		},
+		{
+			testName: "Accept valid proxy service name templates update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"proxy_service_node_template":    "{{.WorkloadName}}",
+					"proxy_service_cluster_template": "{{.ServiceAccount}}.{{.Namespace}}.svc",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: true,
+				Result:  &metav1.Status{Reason: ""},
+			},
+		},
+		{
+			testName: "Accept valid proxy_env update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"proxy_env": "ENVOY_UID=1500, FEATURE_FLAGS=a=b",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: true,
+				Result:  &metav1.Status{Reason: ""},
+			},
+		},
+		{
+			testName: "Accept valid proxy_image_pull_policy update",
+			configMap: corev1.ConfigMap{
+				Data: map[string]string{
+					"proxy_image_pull_policy": "IfNotPresent",
+				},
+			},
+			expectedResponse: &admissionv1.AdmissionResponse{
+				Allowed: true,
+				Result:  &metav1.Status{Reason: ""},
+			},
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.testName, func(t *testing.T) {
@@ -452,12 +858,82 @@ func TestCheckBoolFields(t *testing.T) {
 	}
 }
 
+func TestValidateTrafficTarget(t *testing.T) {
+	trafficTargetWhc := &webhookConfig{
+		kubeClient: fake.NewSimpleClientset(
+			&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "bookstore", Namespace: "bookstore"}},
+			&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "bookbuyer", Namespace: "bookbuyer"}},
+		),
+		smiSpecClient: fakeSpecClient.NewSimpleClientset(
+			&smiSpecs.HTTPRouteGroup{ObjectMeta: metav1.ObjectMeta{Name: "bookstore-routes", Namespace: "bookstore"}},
+		),
+		osmNamespace: "-osm-namespace-",
+	}
+
+	testCases := []struct {
+		name            string
+		routeGroup      string
+		source          string
+		expectedAllowed bool
+		expectedMessage string
+	}{
+		{
+			name:            "references exist",
+			routeGroup:      "bookstore-routes",
+			source:          "bookbuyer",
+			expectedAllowed: true,
+		},
+		{
+			name:            "HTTPRouteGroup does not exist",
+			routeGroup:      "missing-routes",
+			source:          "bookbuyer",
+			expectedAllowed: false,
+			expectedMessage: "TrafficTarget bookstore/bookstore references resources that do not exist: HTTPRouteGroup bookstore/missing-routes",
+		},
+		{
+			name:            "source service account does not exist",
+			routeGroup:      "bookstore-routes",
+			source:          "bookthief",
+			expectedAllowed: false,
+			expectedMessage: "TrafficTarget bookstore/bookstore references resources that do not exist: ServiceAccount bookbuyer/bookthief",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := tassert.New(t)
+
+			trafficTarget := smiAccess.TrafficTarget{
+				TypeMeta: metav1.TypeMeta{APIVersion: "access.smi-spec.io/v1alpha3", Kind: "TrafficTarget"},
+				// The namespace is only set on the admission request
+				ObjectMeta: metav1.ObjectMeta{Name: "bookstore"},
+				Spec: smiAccess.TrafficTargetSpec{
+					Destination: smiAccess.IdentityBindingSubject{Kind: "ServiceAccount", Name: "bookstore", Namespace: "bookstore"},
+					Rules:       []smiAccess.TrafficTargetRule{{Kind: "HTTPRouteGroup", Name: tc.routeGroup}},
+					Sources:     []smiAccess.IdentityBindingSubject{{Kind: "ServiceAccount", Name: tc.source, Namespace: "bookbuyer"}},
+				},
+			}
+			raw, err := json.Marshal(trafficTarget)
+			assert.Nil(err)
+
+			resp := trafficTargetWhc.validateTrafficTarget(&admissionv1.AdmissionRequest{
+				UID:       "11111111-2222-3333-4444-555555555555",
+				Namespace: "bookstore",
+				Object:    runtime.RawExtension{Raw: raw},
+			})
+			assert.Equal(tc.expectedAllowed, resp.Allowed)
+			assert.Equal(tc.expectedMessage, resp.Result.Message)
+		})
+	}
+}
+
 func TestGetPartialValidatingWebhookConfiguration(t *testing.T) {
 	assert := tassert.New(t)
 	cert := mockCertificate{}
 	webhookConfigName := "-webhook-config-name-"
-	res := getPartialValidatingWebhookConfiguration(ValidatingWebhookName, cert, webhookConfigName)
+	res := getPartialValidatingWebhookConfiguration(cert, webhookConfigName, ValidatingWebhookName, TrafficTargetValidatingWebhookName)
 
+	sideEffect := admissionregv1.SideEffectClassNone
 	expectedRes := admissionregv1.ValidatingWebhookConfiguration{
 		ObjectMeta: metav1.ObjectMeta{
 			Name: webhookConfigName,
@@ -468,10 +944,15 @@ func TestGetPartialValidatingWebhookConfiguration(t *testing.T) {
 				ClientConfig: admissionregv1.WebhookClientConfig{
 					CABundle: cert.GetCertificateChain(),
 				},
-				SideEffects: func() *admissionregv1.SideEffectClass {
-					sideEffect := admissionregv1.SideEffectClassNone
-					return &sideEffect
-				}(),
+				SideEffects:             &sideEffect,
+				AdmissionReviewVersions: []string{"v1"},
+			},
+			{
+				Name: TrafficTargetValidatingWebhookName,
+				ClientConfig: admissionregv1.WebhookClientConfig{
+					CABundle: cert.GetCertificateChain(),
+				},
+				SideEffects:             &sideEffect,
 				AdmissionReviewVersions: []string{"v1"},
 			},
 		},
@@ -510,6 +991,12 @@ func TestUpdateValidatingWebhookCABundle(t *testing.T) {
 	})
 	err := updateValidatingWebhookCABundle(cert, webhookName, kubeClient)
 	assert.Nil(err)
+
+	// The optional TrafficTarget webhook is not added when missing from the configuration
+	updated, err := kubeClient.AdmissionregistrationV1().ValidatingWebhookConfigurations().Get(context.TODO(), webhookName, metav1.GetOptions{})
+	assert.Nil(err)
+	assert.Len(updated.Webhooks, 1)
+	assert.Equal(cert.GetCertificateChain(), updated.Webhooks[0].ClientConfig.CABundle)
 }
 
 type mockCertificate struct{}
@@ -61,6 +61,9 @@ const (
 	// InjectorWebhookPort is the port on which the sidecar injection webhook listens
 	InjectorWebhookPort = 9090
 
+	// InjectorBootstrapRotationPath is the HTTP path at which the sidecar injector regenerates the bootstrap config of a proxy
+	InjectorBootstrapRotationPath = "/rotate-bootstrap"
+
 	// OSMHTTPServerPort is the port on which osm-controller and osm-injector serve HTTP requests for metrics, health probes etc.
 	OSMHTTPServerPort = 9091
 
@@ -109,6 +112,14 @@ const (
 	// EnvoyUniqueIDLabelName is the label applied to pods with the unique ID of the Envoy sidecar.
 	EnvoyUniqueIDLabelName = "osm-proxy-uuid"
 
+	// EnvoyImageVersionLabelName is the label applied to pods with the version of the image of the Envoy sidecar,
+	// i.e. the tag of the image, or its digest when the image is pinned by digest.
+	EnvoyImageVersionLabelName = "osm-proxy-image-version"
+
+	// EnvoyBootstrapConfigSecretPrefix is the prefix of the name of the secret holding the bootstrap config of an Envoy sidecar,
+	// the name of the secret is the prefix followed by the unique ID of the Envoy sidecar.
+	EnvoyBootstrapConfigSecretPrefix = "envoy-bootstrap-config-"
+
 	// TimeDateLayout is the layout for time.Parse used in this repo
 	TimeDateLayout = "2006-01-02T15:04:05.000Z"
 
@@ -135,6 +146,9 @@ const (
 	// InitContainerName is the name of the init container
 	InitContainerName = "osm-init"
 
+	// EnvoyConfigPath is the default path of the bootstrap config file of the Envoy sidecar
+	EnvoyConfigPath = "/etc/envoy/bootstrap.yaml"
+
 	// EnvoyServiceNodeSeparator is the character separating the strings used to create an Envoy service node parameter.
 	// Example use: envoy --service-node 52883c80-6e0d-4c64-b901-cbcb75134949/bookstore/10.144.2.91/bookstore-v1/bookstore-v1
 	EnvoyServiceNodeSeparator = "/"
@@ -150,6 +164,32 @@ const (
 
 	// MetricsAnnotation is the annotation used for enabling/disabling metrics
 	MetricsAnnotation = "openservicemesh.io/metrics"
+
+	// ProxyDrainTimeoutAnnotation is the annotation used to override the duration for which the sidecar proxy drains connections on pod termination
+	ProxyDrainTimeoutAnnotation = "openservicemesh.io/proxy-drain-timeout"
+
+	// ProxyUUIDAnnotation is the annotation used to request a fixed UUID for the sidecar proxy of a pod in place of a random one.
+	// It is meant for bare pods only: the UUID must be unique among the pods of the namespace, so pods created from a
+	// template carrying it, e.g. the replicas of a Deployment, are rejected once the first of them exists.
+	ProxyUUIDAnnotation = "openservicemesh.io/proxy-uuid"
+
+	// EnvoyLogLevelAnnotation is the namespace annotation used to override the log level of the sidecar proxies injected in the namespace
+	EnvoyLogLevelAnnotation = "openservicemesh.io/envoy-log-level"
+
+	// OutboundNamespaceExclusionAnnotation is the annotation used to exclude the outbound traffic to the given comma separated namespaces from interception
+	OutboundNamespaceExclusionAnnotation = "openservicemesh.io/outbound-namespace-exclusion"
+
+	// OutboundPortExclusionListAnnotation is the annotation used to exclude the outbound traffic to the given comma separated ports from interception
+	OutboundPortExclusionListAnnotation = "openservicemesh.io/outbound-port-exclusion-list"
+
+	// EnvoyImageAnnotation is the annotation used to override the image of the sidecar proxy injected in a pod
+	EnvoyImageAnnotation = "openservicemesh.io/envoy-image"
+
+	// ProxyUIDAnnotation is the annotation used to override the UID the sidecar proxy injected in a pod runs as
+	ProxyUIDAnnotation = "openservicemesh.io/proxy-uid"
+
+	// EnvoyExtraArgsAnnotation is the annotation used to append the given whitespace separated args to the command line of the sidecar proxy injected in a pod
+	EnvoyExtraArgsAnnotation = "openservicemesh.io/envoy-extra-args"
 )
 
 // Annotations used for Metrics
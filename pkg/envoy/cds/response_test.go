@@ -342,3 +342,69 @@ func TestNewResponse(t *testing.T) {
 
 	assert.ElementsMatch(expectedClusters, foundClusters)
 }
+
+func TestNewResponseTracingCluster(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		tracingEnabled       bool
+		expectTracingCluster bool
+	}{
+		{
+			name:                 "tracing cluster is programmed when tracing is enabled",
+			tracingEnabled:       true,
+			expectTracingCluster: true,
+		},
+		{
+			name:                 "tracing cluster is not programmed when tracing is disabled",
+			tracingEnabled:       false,
+			expectTracingCluster: false,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+			require := trequire.New(t)
+
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockConfigurator := configurator.NewMockConfigurator(mockCtrl)
+			mockCatalog := catalog.NewMockMeshCataloger(mockCtrl)
+
+			proxyUUID := uuid.New()
+			xdsCertificate := certificate.CommonName(fmt.Sprintf("%s.%s.%s.foo.bar", proxyUUID, tests.BookbuyerServiceAccountName, tests.Namespace))
+			proxy := envoy.NewProxy(xdsCertificate, certificate.SerialNumber("123456"), nil)
+
+			mockCatalog.EXPECT().GetServicesForProxy(proxy).Return([]service.MeshService{tests.BookbuyerService}, nil).AnyTimes()
+			mockCatalog.EXPECT().ListAllowedOutboundServicesForIdentity(tests.BookbuyerServiceIdentity).Return(nil).AnyTimes()
+			mockCatalog.EXPECT().GetTargetPortToProtocolMappingForService(tests.BookbuyerService).Return(map[uint32]string{uint32(80): "protocol"}, nil).AnyTimes()
+			mockConfigurator.EXPECT().IsPermissiveTrafficPolicyMode().Return(false).AnyTimes()
+			mockConfigurator.EXPECT().IsEgressEnabled().Return(false).AnyTimes()
+			mockConfigurator.EXPECT().IsPrometheusScrapingEnabled().Return(false).AnyTimes()
+			mockConfigurator.EXPECT().IsTracingEnabled().Return(tc.tracingEnabled).AnyTimes()
+			mockConfigurator.EXPECT().GetTracingHost().Return(constants.DefaultTracingHost).AnyTimes()
+			mockConfigurator.EXPECT().GetTracingPort().Return(constants.DefaultTracingPort).AnyTimes()
+
+			resp, err := NewResponse(mockCatalog, proxy, nil, mockConfigurator, nil)
+			require.Nil(err)
+
+			var tracingCluster *xds_cluster.Cluster
+			for _, resource := range resp {
+				cluster, ok := resource.(*xds_cluster.Cluster)
+				require.True(ok)
+				if cluster.Name == constants.EnvoyTracingCluster {
+					tracingCluster = cluster
+				}
+			}
+
+			if !tc.expectTracingCluster {
+				assert.Nil(tracingCluster)
+				return
+			}
+			require.NotNil(tracingCluster)
+			socketAddress := tracingCluster.LoadAssignment.Endpoints[0].LbEndpoints[0].GetEndpoint().Address.GetSocketAddress()
+			assert.Equal(constants.DefaultTracingHost, socketAddress.Address)
+			assert.Equal(constants.DefaultTracingPort, socketAddress.GetPortValue())
+		})
+	}
+}
@@ -115,7 +115,9 @@ func getHTTPConnectionManager(routeName string, cfg configurator.Configurator, h
 	return connManager
 }
 
-func getPrometheusConnectionManager() *xds_hcm.HttpConnectionManager {
+// getPrometheusConnectionManager returns the connection manager of the Prometheus listener, serving the stats of the
+// Envoy admin interface at the given scrape path
+func getPrometheusConnectionManager(scrapePath string) *xds_hcm.HttpConnectionManager {
 	return &xds_hcm.HttpConnectionManager{
 		StatPrefix: prometheusHTTPConnManagerStatPrefix,
 		CodecType:  xds_hcm.HttpConnectionManager_AUTO,
@@ -130,7 +132,7 @@ func getPrometheusConnectionManager() *xds_hcm.HttpConnectionManager {
 					Routes: []*xds_route.Route{{
 						Match: &xds_route.RouteMatch{
 							PathSpecifier: &xds_route.RouteMatch_Prefix{
-								Prefix: constants.PrometheusScrapePath,
+								Prefix: scrapePath,
 							},
 						},
 						Action: &xds_route.Route_Route{
@@ -76,12 +76,21 @@ var _ = Describe("Construct inbound listeners", func() {
 
 	Context("Test creation of Prometheus listener", func() {
 		It("Tests the Prometheus listener config", func() {
-			connManager := getPrometheusConnectionManager()
+			connManager := getPrometheusConnectionManager(constants.PrometheusScrapePath)
 			listener, _ := buildPrometheusListener(connManager)
 			Expect(listener.Address).To(Equal(envoy.GetAddress(constants.WildcardIPAddr, constants.EnvoyPrometheusInboundListenerPort)))
 			Expect(len(listener.ListenerFilters)).To(Equal(0)) //  no listener filters
 			Expect(listener.TrafficDirection).To(Equal(xds_core.TrafficDirection_INBOUND))
 		})
+
+		It("Serves the stats of the Envoy admin interface at the configured scrape path", func() {
+			connManager := getPrometheusConnectionManager("/metrics")
+			routes := connManager.GetRouteConfig().GetVirtualHosts()[0].GetRoutes()
+			Expect(routes).To(HaveLen(1))
+			Expect(routes[0].GetMatch().GetPrefix()).To(Equal("/metrics"))
+			Expect(routes[0].GetRoute().GetPrefixRewrite()).To(Equal(constants.PrometheusScrapePath))
+			Expect(routes[0].GetRoute().GetCluster()).To(Equal(constants.EnvoyMetricsCluster))
+		})
 	})
 })
 
@@ -87,7 +87,7 @@ func NewResponse(meshCatalog catalog.MeshCataloger, proxy *envoy.Proxy, _ *xds_d
 
 	if cfg.IsPrometheusScrapingEnabled() {
 		// Build Prometheus listener config
-		prometheusConnManager := getPrometheusConnectionManager()
+		prometheusConnManager := getPrometheusConnectionManager(cfg.GetPrometheusScrapePath())
 		if prometheusListener, err := buildPrometheusListener(prometheusConnManager); err != nil {
 			log.Error().Err(err).Msgf("Error building Prometheus listener config for proxy with XDS Certificate SerialNumber=%s on Pod with UID=%s",
 				proxy.GetCertificateSerialNumber(), proxy.GetPodUID())
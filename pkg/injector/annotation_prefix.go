@@ -0,0 +1,52 @@
+package injector
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+// DefaultAnnotationPrefix is the prefix of the OSM annotations read by the sidecar injector, unless configured otherwise
+const DefaultAnnotationPrefix = "openservicemesh.io"
+
+// ValidateAnnotationPrefix returns an error if the given annotation prefix can't prefix the keys of annotations, i.e. if
+// it is not a DNS-1123 subdomain
+func ValidateAnnotationPrefix(prefix string) error {
+	if errs := validation.IsDNS1123Subdomain(prefix); len(errs) > 0 {
+		return errors.Errorf("Invalid annotation prefix %q: %s", prefix, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// ResolveAnnotation returns the key of the given OSM annotation, one of the annotation constants prefixed with the
+// default annotation prefix, with its prefix replaced by the given prefix. The default prefix is kept when the given
+// prefix is empty. The annotations written to injected pods that are not OSM annotations, e.g. prometheus.io/scrape,
+// keep their keys.
+func ResolveAnnotation(prefix, annotation string) string {
+	if prefix == "" || prefix == DefaultAnnotationPrefix {
+		return annotation
+	}
+	return prefix + strings.TrimPrefix(annotation, DefaultAnnotationPrefix)
+}
+
+// ProxyUUIDLabel returns the key of the label holding the proxy UUID of the pods injected by a sidecar injector
+// configured with the given annotation prefix: osm-proxy-uuid with the default prefix, <prefix>/osm-proxy-uuid otherwise
+func ProxyUUIDLabel(prefix string) string {
+	if prefix == "" || prefix == DefaultAnnotationPrefix {
+		return constants.EnvoyUniqueIDLabelName
+	}
+	return prefix + "/" + constants.EnvoyUniqueIDLabelName
+}
+
+// annotation returns the key of the given OSM annotation for the annotation prefix the sidecar injector is configured with
+func (wh *mutatingWebhook) annotation(annotation string) string {
+	return ResolveAnnotation(wh.config.AnnotationPrefix, annotation)
+}
+
+// proxyUUIDLabel returns the key of the proxy UUID label for the annotation prefix the sidecar injector is configured with
+func (wh *mutatingWebhook) proxyUUIDLabel() string {
+	return ProxyUUIDLabel(wh.config.AnnotationPrefix)
+}
@@ -0,0 +1,104 @@
+package injector
+
+import (
+	"fmt"
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+func TestResolveAnnotation(t *testing.T) {
+	testCases := []struct {
+		name       string
+		prefix     string
+		annotation string
+		expected   string
+	}{
+		{
+			name:       "empty prefix keeps the default prefix",
+			prefix:     "",
+			annotation: constants.SidecarInjectionAnnotation,
+			expected:   "openservicemesh.io/sidecar-injection",
+		},
+		{
+			name:       "default prefix",
+			prefix:     DefaultAnnotationPrefix,
+			annotation: constants.ProxyUIDAnnotation,
+			expected:   "openservicemesh.io/proxy-uid",
+		},
+		{
+			name:       "custom prefix",
+			prefix:     "mesh.example.com",
+			annotation: constants.OutboundPortExclusionListAnnotation,
+			expected:   "mesh.example.com/outbound-port-exclusion-list",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+			assert.Equal(tc.expected, ResolveAnnotation(tc.prefix, tc.annotation))
+		})
+	}
+}
+
+func TestProxyUUIDLabel(t *testing.T) {
+	assert := tassert.New(t)
+
+	assert.Equal(constants.EnvoyUniqueIDLabelName, ProxyUUIDLabel(""))
+	assert.Equal(constants.EnvoyUniqueIDLabelName, ProxyUUIDLabel(DefaultAnnotationPrefix))
+	assert.Equal("mesh.example.com/osm-proxy-uuid", ProxyUUIDLabel("mesh.example.com"))
+}
+
+func TestValidateAnnotationPrefix(t *testing.T) {
+	testCases := []struct {
+		prefix    string
+		expectErr bool
+	}{
+		{prefix: DefaultAnnotationPrefix, expectErr: false},
+		{prefix: "mesh.example.com", expectErr: false},
+		{prefix: "", expectErr: true},
+		{prefix: "example.com/", expectErr: true},
+		{prefix: "Example.com", expectErr: true},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %q", i, tc.prefix), func(t *testing.T) {
+			assert := tassert.New(t)
+			assert.Equal(tc.expectErr, ValidateAnnotationPrefix(tc.prefix) != nil)
+		})
+	}
+}
+
+func TestAnnotationsWithCustomPrefix(t *testing.T) {
+	assert := tassert.New(t)
+
+	wh := &mutatingWebhook{config: Config{AnnotationPrefix: "example.com"}}
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{
+				"example.com/envoy-image":                     "envoyproxy/envoy:custom",
+				"example.com/outbound-port-exclusion-list":    "6379",
+				constants.EnvoyImageAnnotation:                "envoyproxy/envoy:ignored",
+				constants.OutboundPortExclusionListAnnotation: "7070",
+				"example.com/proxy-uuid":                      "not-a-uuid",
+			},
+		},
+	}
+
+	image, err := getEnvoyImage(pod, wh.annotation(constants.EnvoyImageAnnotation), "envoyproxy/envoy:mesh")
+	assert.Nil(err)
+	assert.Equal("envoyproxy/envoy:custom", image)
+
+	ports, err := getPortExclusionListForPod(pod, wh.annotation(constants.OutboundPortExclusionListAnnotation))
+	assert.Nil(err)
+	assert.Equal([]int{6379}, ports)
+
+	_, err = getRequestedProxyUUID(pod, wh.annotation(constants.ProxyUUIDAnnotation))
+	assert.NotNil(err)
+	assert.Contains(err.Error(), "example.com/proxy-uuid")
+}
@@ -0,0 +1,122 @@
+package injector
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/openservicemesh/osm/pkg/certificate"
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+// BootstrapConfigParams are the parameters of the bootstrap config of an Envoy sidecar, stored in the
+// 'envoy-bootstrap-config-<proxy UUID>' secret mounted in the sidecar.
+//
+// The node ID of the proxy and the port of its metrics listener are not a part of the bootstrap config: the node ID is
+// passed to Envoy with the --service-node argument of the sidecar container, and the metrics listener is programmed
+// over xDS along with all the other dynamic resources.
+type BootstrapConfigParams struct {
+	// AdminPort is the port of the Envoy admin interface, listening on localhost
+	AdminPort int
+
+	// XDSClusterName is the name of the static cluster of the xDS server
+	XDSClusterName string
+
+	// XDSHost and XDSPort are the address of the xDS server
+	XDSHost string
+	XDSPort int
+
+	// IssuingCA, CertificateChain and PrivateKey are the PEM encoded TLS material used by Envoy to connect to the
+	// xDS server, inlined in the bootstrap config
+	IssuingCA        []byte
+	CertificateChain []byte
+	PrivateKey       []byte
+
+	// LivenessProbe, ReadinessProbe and StartupProbe are the original health probes of the application container,
+	// served by static listeners of the bootstrap config. A nil probe is not served.
+	LivenessProbe  *HealthProbe
+	ReadinessProbe *HealthProbe
+	StartupProbe   *HealthProbe
+
+	// StatsTagsEnabled adds the stats config extracting the cluster and route dimensions of the Envoy stats as tags
+	StatsTagsEnabled bool
+}
+
+// HealthProbe is a health probe of the application container served by the Envoy sidecar
+type HealthProbe struct {
+	// Path is the path of an HTTP probe
+	Path string
+
+	// Port is the port of the application container serving the probe
+	Port int32
+
+	// IsHTTP is true for an HTTP probe, and false for a TCP probe
+	IsHTTP bool
+}
+
+// NewBootstrapConfigParams returns the parameters of the bootstrap config of an Envoy sidecar connecting to the
+// osm-controller in the given namespace with the given certificate, without health probes
+func NewBootstrapConfigParams(osmNamespace string, cert certificate.Certificater) BootstrapConfigParams {
+	return BootstrapConfigParams{
+		AdminPort:        constants.EnvoyAdminPort,
+		XDSClusterName:   constants.OSMControllerName,
+		XDSHost:          fmt.Sprintf("%s.%s.svc.cluster.local", constants.OSMControllerName, osmNamespace),
+		XDSPort:          constants.OSMControllerPort,
+		IssuingCA:        cert.GetIssuingCA(),
+		CertificateChain: cert.GetCertificateChain(),
+		PrivateKey:       cert.GetPrivateKey(),
+	}
+}
+
+// GenerateBootstrapConfig returns the YAML bootstrap config of an Envoy sidecar for the given parameters. This is the
+// bootstrap config generated by the sidecar injector, so tools generating it with the same parameters get an identical
+// config.
+func GenerateBootstrapConfig(params BootstrapConfigParams) ([]byte, error) {
+	if params.AdminPort <= 0 {
+		return nil, errors.Errorf("Invalid Envoy admin port %d", params.AdminPort)
+	}
+	if params.XDSClusterName == "" || params.XDSHost == "" {
+		return nil, errors.New("The xDS cluster name and host are required")
+	}
+	if params.XDSPort <= 0 {
+		return nil, errors.Errorf("Invalid xDS port %d", params.XDSPort)
+	}
+	if len(params.IssuingCA) == 0 || len(params.CertificateChain) == 0 || len(params.PrivateKey) == 0 {
+		return nil, errors.New("The issuing CA, certificate chain and private key to connect to xDS are required")
+	}
+
+	return getEnvoyConfigYAML(envoyBootstrapConfigMeta{
+		EnvoyAdminPort: params.AdminPort,
+		XDSClusterName: params.XDSClusterName,
+
+		RootCert: base64.StdEncoding.EncodeToString(params.IssuingCA),
+		Cert:     base64.StdEncoding.EncodeToString(params.CertificateChain),
+		Key:      base64.StdEncoding.EncodeToString(params.PrivateKey),
+
+		XDSHost: params.XDSHost,
+		XDSPort: params.XDSPort,
+
+		OriginalHealthProbes: healthProbes{
+			liveness:  params.LivenessProbe.toHealthProbe(),
+			readiness: params.ReadinessProbe.toHealthProbe(),
+			startup:   params.StartupProbe.toHealthProbe(),
+		},
+
+		StatsTagsEnabled: params.StatsTagsEnabled,
+	}, nil)
+}
+
+func (probe *HealthProbe) toHealthProbe() *healthProbe {
+	if probe == nil {
+		return nil
+	}
+	return &healthProbe{path: probe.Path, port: probe.Port, isHTTP: probe.IsHTTP}
+}
+
+func (probe *healthProbe) toHealthProbe() *HealthProbe {
+	if probe == nil {
+		return nil
+	}
+	return &HealthProbe{Path: probe.path, Port: probe.port, IsHTTP: probe.isHTTP}
+}
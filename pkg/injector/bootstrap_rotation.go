@@ -0,0 +1,255 @@
+package injector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/envoyproxy/go-control-plane/pkg/wellknown"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openservicemesh/osm/pkg/catalog"
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+// bearerTokenPrefix is the prefix of the Authorization header of the requests authenticated with a bearer token
+const bearerTokenPrefix = "Bearer "
+
+// bootstrapConfigProbes is the subset of an Envoy bootstrap config describing the static listeners and clusters
+// serving the health probes of the pod
+type bootstrapConfigProbes struct {
+	StaticResources struct {
+		Listeners []struct {
+			Name         string `yaml:"name"`
+			FilterChains []struct {
+				Filters []struct {
+					Name        string `yaml:"name"`
+					TypedConfig struct {
+						RouteConfig struct {
+							VirtualHosts []struct {
+								Routes []struct {
+									Route struct {
+										PrefixRewrite string `yaml:"prefix_rewrite"`
+									} `yaml:"route"`
+								} `yaml:"routes"`
+							} `yaml:"virtual_hosts"`
+						} `yaml:"route_config"`
+					} `yaml:"typed_config"`
+				} `yaml:"filters"`
+			} `yaml:"filter_chains"`
+		} `yaml:"listeners"`
+		Clusters []struct {
+			Name           string `yaml:"name"`
+			LoadAssignment struct {
+				Endpoints []struct {
+					LbEndpoints []struct {
+						Endpoint struct {
+							Address struct {
+								SocketAddress struct {
+									PortValue int32 `yaml:"port_value"`
+								} `yaml:"socket_address"`
+							} `yaml:"address"`
+						} `yaml:"endpoint"`
+					} `yaml:"lb_endpoints"`
+				} `yaml:"endpoints"`
+			} `yaml:"load_assignment"`
+		} `yaml:"clusters"`
+	} `yaml:"static_resources"`
+}
+
+// bootstrapRotationHandler regenerates the bootstrap config of the proxy of the pod given by the 'namespace' and 'pod'
+// query parameters of the POST request. The request must carry the bearer token of a user allowed to update the
+// secrets of the namespace, which must be monitored by the mesh.
+func (wh *mutatingWebhook) bootstrapRotationHandler(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		http.Error(w, fmt.Sprintf("Invalid method %s; Expected %s", req.Method, http.MethodPost), http.StatusMethodNotAllowed)
+		return
+	}
+
+	namespace := req.URL.Query().Get("namespace")
+	podName := req.URL.Query().Get("pod")
+	if namespace == "" || podName == "" {
+		http.Error(w, "Query parameters namespace and pod are required", http.StatusBadRequest)
+		return
+	}
+
+	if status, err := wh.authorizeBootstrapRotation(req, namespace); err != nil {
+		log.Error().Err(err).Msgf("Unauthorized bootstrap config rotation for pod %s in namespace %s", podName, namespace)
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	secretName, warning, err := wh.rotateBootstrapConfig(namespace, podName)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if cause := errors.Cause(err); cause == errPodNotMeshed {
+			status = http.StatusBadRequest
+		} else if apierrors.IsNotFound(cause) {
+			status = http.StatusNotFound
+		}
+		log.Error().Err(err).Msgf("Error rotating bootstrap config for pod %s in namespace %s", podName, namespace)
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	response := fmt.Sprintf("Regenerated bootstrap config secret %s/%s\n", namespace, secretName)
+	if warning != "" {
+		response += fmt.Sprintf("Warning: %s\n", warning)
+	}
+	if _, err := w.Write([]byte(response)); err != nil {
+		log.Error().Err(err).Msgf("Error writing bootstrap rotation response for pod %s in namespace %s", podName, namespace)
+	}
+}
+
+// authorizeBootstrapRotation authenticates the bearer token of the given request with a TokenReview, and checks with a
+// SubjectAccessReview that its user is allowed to update the secrets of the given namespace holding the bootstrap
+// configs. The namespace must be monitored by the mesh. The HTTP status to respond with is returned along with the error.
+func (wh *mutatingWebhook) authorizeBootstrapRotation(req *http.Request, namespace string) (int, error) {
+	authorization := req.Header.Get("Authorization")
+	token := strings.TrimPrefix(authorization, bearerTokenPrefix)
+	if !strings.HasPrefix(authorization, bearerTokenPrefix) || token == "" {
+		return http.StatusUnauthorized, errors.New("A bearer token is required to rotate a bootstrap config")
+	}
+
+	tokenReview, err := wh.kubeClient.AuthenticationV1().TokenReviews().Create(context.Background(), &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return http.StatusInternalServerError, errors.Errorf("Error reviewing bearer token: %s", err)
+	}
+	if !tokenReview.Status.Authenticated {
+		return http.StatusUnauthorized, errors.Errorf("Invalid bearer token: %s", tokenReview.Status.Error)
+	}
+
+	user := tokenReview.Status.User
+	extra := make(map[string]authorizationv1.ExtraValue)
+	for key, value := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	accessReview, err := wh.kubeClient.AuthorizationV1().SubjectAccessReviews().Create(context.Background(), &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			UID:    user.UID,
+			Groups: user.Groups,
+			Extra:  extra,
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: namespace,
+				Verb:      "update",
+				Resource:  "secrets",
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return http.StatusInternalServerError, errors.Errorf("Error reviewing access of user %s: %s", user.Username, err)
+	}
+	if !accessReview.Status.Allowed {
+		return http.StatusForbidden, errors.Errorf("User %s is not allowed to update secrets in namespace %s", user.Username, namespace)
+	}
+
+	if !wh.kubeController.IsMonitoredNamespace(namespace) {
+		return http.StatusForbidden, errors.Errorf("Namespace %s is not monitored by mesh %s", namespace, wh.meshName)
+	}
+	return http.StatusOK, nil
+}
+
+// rotateBootstrapConfig issues a new certificate for the proxy of the given pod to connect to XDS and regenerates its
+// bootstrap config secret. The health probes served by the existing bootstrap config are preserved when it can be parsed,
+// otherwise a warning is returned along with the name of the regenerated secret.
+func (wh *mutatingWebhook) rotateBootstrapConfig(namespace, podName string) (string, string, error) {
+	pod, err := wh.kubeClient.CoreV1().Pods(namespace).Get(context.Background(), podName, metav1.GetOptions{})
+	if err != nil {
+		return "", "", errors.Wrapf(err, "Error fetching pod %s in namespace %s", podName, namespace)
+	}
+
+	proxyUUIDLabel, ok := pod.Labels[wh.proxyUUIDLabel()]
+	if !ok {
+		return "", "", errors.Wrapf(errPodNotMeshed, "Pod %s in namespace %s does not have the label %s", podName, namespace, wh.proxyUUIDLabel())
+	}
+	proxyUUID, err := uuid.Parse(proxyUUIDLabel)
+	if err != nil {
+		return "", "", errors.Wrapf(errPodNotMeshed, "Invalid value %q for label %s of pod %s in namespace %s", proxyUUIDLabel, wh.proxyUUIDLabel(), podName, namespace)
+	}
+
+	secretName := constants.EnvoyBootstrapConfigSecretPrefix + proxyUUID.String()
+	secret, err := wh.kubeClient.CoreV1().Secrets(namespace).Get(context.Background(), secretName, metav1.GetOptions{})
+	if err != nil {
+		return "", "", errors.Wrapf(err, "Error fetching bootstrap config secret %s in namespace %s", secretName, namespace)
+	}
+
+	var warning string
+	originalHealthProbes, err := getBootstrapHealthProbes(secret.Data[envoyBootstrapConfigFile])
+	if err != nil {
+		log.Warn().Err(err).Msgf("Regenerating bootstrap config secret %s in namespace %s without health probes", secretName, namespace)
+		warning = fmt.Sprintf("the health probes of the existing bootstrap config could not be preserved: %s", err)
+	}
+
+	cn := catalog.NewCertCommonNameWithProxyID(proxyUUID, pod.Spec.ServiceAccountName, namespace)
+	bootstrapCertificate, err := wh.certManager.IssueCertificate(cn, constants.XDSCertificateValidityPeriod)
+	if err != nil {
+		return "", "", errors.Errorf("Error issuing bootstrap certificate for Envoy with CN=%s: %s", cn, err)
+	}
+
+	if _, err := wh.createEnvoyBootstrapConfig(secretName, namespace, wh.osmNamespace, bootstrapCertificate, originalHealthProbes); err != nil {
+		return "", "", errors.Errorf("Error updating bootstrap config secret %s in namespace %s: %s", secretName, namespace, err)
+	}
+
+	log.Info().Msgf("Regenerated bootstrap config secret %s for pod %s in namespace %s", secretName, podName, namespace)
+	return secretName, warning, nil
+}
+
+// getBootstrapHealthProbes returns the original health probes of the pod served by the given Envoy bootstrap config
+func getBootstrapHealthProbes(bootstrapYAML []byte) (healthProbes, error) {
+	var config bootstrapConfigProbes
+	if err := yaml.Unmarshal(bootstrapYAML, &config); err != nil {
+		return healthProbes{}, errors.Errorf("Error parsing Envoy bootstrap config: %s", err)
+	}
+
+	clusterPorts := make(map[string]int32)
+	for _, cluster := range config.StaticResources.Clusters {
+		for _, endpoints := range cluster.LoadAssignment.Endpoints {
+			for _, lbEndpoint := range endpoints.LbEndpoints {
+				clusterPorts[cluster.Name] = lbEndpoint.Endpoint.Address.SocketAddress.PortValue
+			}
+		}
+	}
+
+	var probes healthProbes
+	for _, listener := range config.StaticResources.Listeners {
+		probe := &healthProbe{}
+		switch listener.Name {
+		case livenessListener:
+			probe.port = clusterPorts[livenessCluster]
+			probes.liveness = probe
+		case readinessListener:
+			probe.port = clusterPorts[readinessCluster]
+			probes.readiness = probe
+		case startupListener:
+			probe.port = clusterPorts[startupCluster]
+			probes.startup = probe
+		default:
+			continue
+		}
+
+		for _, filterChain := range listener.FilterChains {
+			for _, filter := range filterChain.Filters {
+				if filter.Name == wellknown.TCPProxy {
+					continue
+				}
+				probe.isHTTP = true
+				for _, virtualHost := range filter.TypedConfig.RouteConfig.VirtualHosts {
+					for _, route := range virtualHost.Routes {
+						probe.path = route.Route.PrefixRewrite
+					}
+				}
+			}
+		}
+	}
+	return probes, nil
+}
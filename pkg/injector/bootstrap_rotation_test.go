@@ -0,0 +1,220 @@
+package injector
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	tassert "github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/openservicemesh/osm/pkg/certificate/providers/tresor"
+	"github.com/openservicemesh/osm/pkg/configurator"
+	"github.com/openservicemesh/osm/pkg/constants"
+	k8s "github.com/openservicemesh/osm/pkg/kubernetes"
+)
+
+func TestGetBootstrapHealthProbes(t *testing.T) {
+	assert := tassert.New(t)
+
+	probes := healthProbes{
+		liveness:  &healthProbe{path: "/liveness", port: 81, isHTTP: true},
+		readiness: &healthProbe{port: 82, isHTTP: false},
+		startup:   &healthProbe{path: "/startup", port: 83, isHTTP: true},
+	}
+	bootstrapYAML, err := getEnvoyConfigYAML(envoyBootstrapConfigMeta{
+		EnvoyAdminPort:       constants.EnvoyAdminPort,
+		XDSClusterName:       constants.OSMControllerName,
+		XDSHost:              "osm-controller.osm-system.svc.cluster.local",
+		XDSPort:              constants.OSMControllerPort,
+		OriginalHealthProbes: probes,
+	}, nil)
+	assert.Nil(err)
+
+	actual, err := getBootstrapHealthProbes(bootstrapYAML)
+	assert.Nil(err)
+	assert.Equal(probes, actual)
+
+	actual, err = getBootstrapHealthProbes([]byte("static_resources: [corrupted"))
+	assert.NotNil(err)
+	assert.Equal(healthProbes{}, actual)
+}
+
+func TestRotateBootstrapConfig(t *testing.T) {
+	const namespace = "bookstore"
+	proxyUUID := uuid.New()
+	secretName := constants.EnvoyBootstrapConfigSecretPrefix + proxyUUID.String()
+
+	newPod := func(name string, labels map[string]string) *corev1.Pod {
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+			Spec:       corev1.PodSpec{ServiceAccountName: "bookstore"},
+		}
+	}
+	probes := healthProbes{liveness: &healthProbe{path: "/liveness", port: 81, isHTTP: true}}
+	existingBootstrapYAML, err := getEnvoyConfigYAML(envoyBootstrapConfigMeta{OriginalHealthProbes: probes}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		name                string
+		pod                 *corev1.Pod
+		existingBootstrap   []byte
+		expectedStatus      int
+		expectedResponse    string
+		expectedProbes      healthProbes
+		expectSecretRotated bool
+		withoutToken        bool
+		unauthenticated     bool
+		forbidden           bool
+		notMonitored        bool
+	}{
+		{
+			name:                "bootstrap config is regenerated preserving the health probes",
+			pod:                 newPod("meshed", map[string]string{constants.EnvoyUniqueIDLabelName: proxyUUID.String()}),
+			existingBootstrap:   existingBootstrapYAML,
+			expectedStatus:      http.StatusOK,
+			expectedResponse:    fmt.Sprintf("Regenerated bootstrap config secret %s/%s\n", namespace, secretName),
+			expectedProbes:      probes,
+			expectSecretRotated: true,
+		},
+		{
+			name:                "corrupted bootstrap config is regenerated without health probes",
+			pod:                 newPod("meshed", map[string]string{constants.EnvoyUniqueIDLabelName: proxyUUID.String()}),
+			existingBootstrap:   []byte("static_resources: [corrupted"),
+			expectedStatus:      http.StatusOK,
+			expectedResponse:    "Warning: the health probes of the existing bootstrap config could not be preserved",
+			expectedProbes:      healthProbes{},
+			expectSecretRotated: true,
+		},
+		{
+			name:              "pod is not meshed",
+			pod:               newPod("not-meshed", nil),
+			existingBootstrap: existingBootstrapYAML,
+			expectedStatus:    http.StatusBadRequest,
+			expectedResponse:  "does not have the label osm-proxy-uuid: pod is not a part of the mesh",
+		},
+		{
+			name:              "request without a bearer token",
+			pod:               newPod("meshed", map[string]string{constants.EnvoyUniqueIDLabelName: proxyUUID.String()}),
+			existingBootstrap: existingBootstrapYAML,
+			withoutToken:      true,
+			expectedStatus:    http.StatusUnauthorized,
+			expectedResponse:  "A bearer token is required",
+		},
+		{
+			name:              "invalid bearer token",
+			pod:               newPod("meshed", map[string]string{constants.EnvoyUniqueIDLabelName: proxyUUID.String()}),
+			existingBootstrap: existingBootstrapYAML,
+			unauthenticated:   true,
+			expectedStatus:    http.StatusUnauthorized,
+			expectedResponse:  "Invalid bearer token",
+		},
+		{
+			name:              "user not allowed to update the secrets of the namespace",
+			pod:               newPod("meshed", map[string]string{constants.EnvoyUniqueIDLabelName: proxyUUID.String()}),
+			existingBootstrap: existingBootstrapYAML,
+			forbidden:         true,
+			expectedStatus:    http.StatusForbidden,
+			expectedResponse:  "User alice is not allowed to update secrets in namespace bookstore",
+		},
+		{
+			name:              "namespace not monitored",
+			pod:               newPod("meshed", map[string]string{constants.EnvoyUniqueIDLabelName: proxyUUID.String()}),
+			existingBootstrap: existingBootstrapYAML,
+			notMonitored:      true,
+			expectedStatus:    http.StatusForbidden,
+			expectedResponse:  "Namespace bookstore is not monitored by mesh osm",
+		},
+		{
+			name:              "bootstrap config secret does not exist",
+			pod:               newPod("meshed", map[string]string{constants.EnvoyUniqueIDLabelName: uuid.New().String()}),
+			existingBootstrap: existingBootstrapYAML,
+			expectedStatus:    http.StatusNotFound,
+			expectedResponse:  "Error fetching bootstrap config secret",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+
+			kubeClient := fake.NewSimpleClientset(tc.pod, &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: namespace},
+				Data:       map[string][]byte{envoyBootstrapConfigFile: tc.existingBootstrap},
+			})
+			kubeClient.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				tokenReview := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+				tokenReview.Status.Authenticated = !tc.unauthenticated && tokenReview.Spec.Token == "token"
+				tokenReview.Status.User = authenticationv1.UserInfo{Username: "alice"}
+				return true, tokenReview, nil
+			})
+			kubeClient.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+				accessReview := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+				attributes := accessReview.Spec.ResourceAttributes
+				accessReview.Status.Allowed = !tc.forbidden && accessReview.Spec.User == "alice" &&
+					attributes.Namespace == namespace && attributes.Verb == "update" && attributes.Resource == "secrets"
+				return true, accessReview, nil
+			})
+			mockConfigurator := configurator.NewMockConfigurator(mockCtrl)
+			mockConfigurator.EXPECT().GetProxyStatsTagsEnabled().Return(false).AnyTimes()
+			mockKubeController := k8s.NewMockController(mockCtrl)
+			mockKubeController.EXPECT().IsMonitoredNamespace(namespace).Return(!tc.notMonitored).AnyTimes()
+			wh := &mutatingWebhook{
+				kubeClient:     kubeClient,
+				kubeController: mockKubeController,
+				certManager:    tresor.NewFakeCertManager(mockConfigurator),
+				osmNamespace:   "osm-system",
+				meshName:       "osm",
+				configurator:   mockConfigurator,
+			}
+
+			req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("%s?namespace=%s&pod=%s", constants.InjectorBootstrapRotationPath, namespace, tc.pod.Name), nil)
+			if !tc.withoutToken {
+				req.Header.Set("Authorization", "Bearer token")
+			}
+			w := httptest.NewRecorder()
+			wh.bootstrapRotationHandler(w, req)
+
+			assert.Equal(tc.expectedStatus, w.Code)
+			assert.Contains(w.Body.String(), tc.expectedResponse)
+
+			secret, err := kubeClient.CoreV1().Secrets(namespace).Get(context.TODO(), secretName, metav1.GetOptions{})
+			assert.Nil(err)
+			if !tc.expectSecretRotated {
+				assert.Equal(tc.existingBootstrap, secret.Data[envoyBootstrapConfigFile])
+				return
+			}
+			assert.NotEqual(tc.existingBootstrap, secret.Data[envoyBootstrapConfigFile])
+			actualProbes, err := getBootstrapHealthProbes(secret.Data[envoyBootstrapConfigFile])
+			assert.Nil(err)
+			assert.Equal(tc.expectedProbes, actualProbes)
+		})
+	}
+}
+
+func TestBootstrapRotationHandlerInvalidRequests(t *testing.T) {
+	assert := tassert.New(t)
+	wh := &mutatingWebhook{kubeClient: fake.NewSimpleClientset()}
+
+	w := httptest.NewRecorder()
+	wh.bootstrapRotationHandler(w, httptest.NewRequest(http.MethodGet, constants.InjectorBootstrapRotationPath+"?namespace=ns&pod=pod", nil))
+	assert.Equal(http.StatusMethodNotAllowed, w.Code)
+
+	w = httptest.NewRecorder()
+	wh.bootstrapRotationHandler(w, httptest.NewRequest(http.MethodPost, constants.InjectorBootstrapRotationPath+"?namespace=ns", nil))
+	assert.Equal(http.StatusBadRequest, w.Code)
+}
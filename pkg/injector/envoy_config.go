@@ -2,8 +2,6 @@ package injector
 
 import (
 	"context"
-	"encoding/base64"
-	"fmt"
 	"strconv"
 
 	"gopkg.in/yaml.v2"
@@ -54,6 +52,10 @@ func getEnvoyConfigYAML(config envoyBootstrapConfigMeta, cfg configurator.Config
 
 	m["static_resources"] = getStaticResources(config)
 
+	if config.StatsTagsEnabled {
+		m["stats_config"] = getStatsConfig()
+	}
+
 	configYAML, err := yaml.Marshal(&m)
 	if err != nil {
 		log.Error().Err(err).Msgf("Error marshaling Envoy config struct into YAML")
@@ -64,6 +66,8 @@ func getEnvoyConfigYAML(config envoyBootstrapConfigMeta, cfg configurator.Config
 
 // getStaticResources returns STATIC resources included in the bootstrap Envoy config.
 // These will not change during the lifetime of the Pod.
+// Configuration that may change at runtime, such as the tracing cluster, is programmed over xDS instead.
+// A static cluster would conflict with the cluster of the same name sent over CDS.
 func getStaticResources(config envoyBootstrapConfigMeta) map[string]interface{} {
 	// This slice is the list of listeners for liveness, readiness, startup IF these have been configured in the Pod Spec
 	var listeners []map[string]interface{}
@@ -102,23 +106,33 @@ func getStaticResources(config envoyBootstrapConfigMeta) map[string]interface{}
 	return staticResources
 }
 
-func (wh *mutatingWebhook) createEnvoyBootstrapConfig(name, namespace, osmNamespace string, cert certificate.Certificater, originalHealthProbes healthProbes) (*corev1.Secret, error) {
-	configMeta := envoyBootstrapConfigMeta{
-		EnvoyAdminPort: constants.EnvoyAdminPort,
-		XDSClusterName: constants.OSMControllerName,
+// getStatsConfig returns the stats config of the bootstrap Envoy config, extracting the cluster and route dimensions
+// of the Envoy stats as tags so that they are exposed as Prometheus labels rather than as a part of the metric names.
+// Envoy's default tags cover the cluster, virtual host and virtual cluster dimensions, the route configuration of the
+// HTTP connection managers is extracted with an additional tag.
+func getStatsConfig() map[string]interface{} {
+	return map[string]interface{}{
+		"use_all_default_tags": true,
+		"stats_tags": []map[string]interface{}{
+			{
+				"tag_name": envoyRouteConfigStatsTag,
+				"regex":    envoyRouteConfigStatsTagRegex,
+			},
+		},
+	}
+}
 
-		RootCert: base64.StdEncoding.EncodeToString(cert.GetIssuingCA()),
-		Cert:     base64.StdEncoding.EncodeToString(cert.GetCertificateChain()),
-		Key:      base64.StdEncoding.EncodeToString(cert.GetPrivateKey()),
+func (wh *mutatingWebhook) createEnvoyBootstrapConfig(name, namespace, osmNamespace string, cert certificate.Certificater, originalHealthProbes healthProbes) (*corev1.Secret, error) {
+	params := NewBootstrapConfigParams(osmNamespace, cert)
 
-		XDSHost: fmt.Sprintf("%s.%s.svc.cluster.local", constants.OSMControllerName, osmNamespace),
-		XDSPort: constants.OSMControllerPort,
+	// The original health probes stores the path and port for liveness, readiness, and startup health probes as
+	// initially defined on the Pod Spec.
+	params.LivenessProbe = originalHealthProbes.liveness.toHealthProbe()
+	params.ReadinessProbe = originalHealthProbes.readiness.toHealthProbe()
+	params.StartupProbe = originalHealthProbes.startup.toHealthProbe()
+	params.StatsTagsEnabled = wh.configurator.GetProxyStatsTagsEnabled()
 
-		// OriginalHealthProbes stores the path and port for liveness, readiness, and startup health probes as initially
-		// defined on the Pod Spec.
-		OriginalHealthProbes: originalHealthProbes,
-	}
-	yamlContent, err := getEnvoyConfigYAML(configMeta, wh.configurator)
+	yamlContent, err := GenerateBootstrapConfig(params)
 	if err != nil {
 		log.Error().Err(err).Msg("Error creating Envoy bootstrap YAML")
 		return nil, err
@@ -40,6 +40,9 @@ var _ = Describe("Test functions creating Envoy bootstrap configuration", func()
 		expectedEnvoyBootstrapConfigFileName        = "expected_envoy_bootstrap_config.yaml"
 		actualGeneratedEnvoyBootstrapConfigFileName = "actual_envoy_bootstrap_config.yaml"
 
+		expectedEnvoyBootstrapConfigWithoutProbesFileName = "expected_envoy_bootstrap_config_without_probes.yaml"
+		actualEnvoyBootstrapConfigWithoutProbesFileName   = "actual_envoy_bootstrap_config_without_probes.yaml"
+
 		expectedXDSClusterWithoutProbesFileName = "expected_xds_cluster_without_probes.yaml"
 		actualXDSClusterWithoutProbesFileName   = "actual_xds_cluster_without_probes.yaml"
 
@@ -137,6 +140,57 @@ var _ = Describe("Test functions creating Envoy bootstrap configuration", func()
 		OriginalHealthProbes: probes,
 	}
 
+	Context("Test GenerateBootstrapConfig()", func() {
+		It("generates the bootstrap config with health probes", func() {
+			params := NewBootstrapConfigParams("b", cert)
+			params.LivenessProbe = &HealthProbe{Path: "/liveness", Port: 81, IsHTTP: true}
+			params.ReadinessProbe = &HealthProbe{Path: "/readiness", Port: 82, IsHTTP: true}
+			params.StartupProbe = &HealthProbe{Path: "/startup", Port: 83, IsHTTP: true}
+
+			actual, err := GenerateBootstrapConfig(params)
+			Expect(err).ToNot(HaveOccurred())
+			saveActualEnvoyYAML(actualGeneratedEnvoyBootstrapConfigFileName, actual)
+
+			expectedEnvoyConfig := getExpectedEnvoyYAML(expectedEnvoyBootstrapConfigFileName)
+			Expect(string(actual)).To(Equal(expectedEnvoyConfig),
+				fmt.Sprintf("Compare files %s and %s\nExpected:\n%s\nActual:\n%s\n",
+					expectedEnvoyBootstrapConfigFileName, actualGeneratedEnvoyBootstrapConfigFileName, expectedEnvoyConfig, string(actual)))
+		})
+
+		It("generates the bootstrap config without health probes", func() {
+			actual, err := GenerateBootstrapConfig(NewBootstrapConfigParams("b", cert))
+			Expect(err).ToNot(HaveOccurred())
+			saveActualEnvoyYAML(actualEnvoyBootstrapConfigWithoutProbesFileName, actual)
+
+			expectedEnvoyConfig := getExpectedEnvoyYAML(expectedEnvoyBootstrapConfigWithoutProbesFileName)
+			Expect(string(actual)).To(Equal(expectedEnvoyConfig),
+				fmt.Sprintf("Compare files %s and %s\nExpected:\n%s\nActual:\n%s\n",
+					expectedEnvoyBootstrapConfigWithoutProbesFileName, actualEnvoyBootstrapConfigWithoutProbesFileName, expectedEnvoyConfig, string(actual)))
+		})
+
+		It("rejects incomplete parameters", func() {
+			params := NewBootstrapConfigParams("b", cert)
+			params.AdminPort = 0
+			_, err := GenerateBootstrapConfig(params)
+			Expect(err).To(HaveOccurred())
+
+			params = NewBootstrapConfigParams("b", cert)
+			params.XDSHost = ""
+			_, err = GenerateBootstrapConfig(params)
+			Expect(err).To(HaveOccurred())
+
+			params = NewBootstrapConfigParams("b", cert)
+			params.XDSPort = -1
+			_, err = GenerateBootstrapConfig(params)
+			Expect(err).To(HaveOccurred())
+
+			params = NewBootstrapConfigParams("b", cert)
+			params.PrivateKey = nil
+			_, err = GenerateBootstrapConfig(params)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
 	Context("Test getEnvoyConfigYAML()", func() {
 		It("creates Envoy bootstrap config", func() {
 			config.OriginalHealthProbes = probes
@@ -151,13 +205,49 @@ var _ = Describe("Test functions creating Envoy bootstrap configuration", func()
 					expectedEnvoyBootstrapConfigFileName, actualGeneratedEnvoyBootstrapConfigFileName, expectedEnvoyConfig, string(actual)))
 		})
 
+		It("does not include the tracing config, which is programmed over xDS", func() {
+			mockConfigurator.EXPECT().IsTracingEnabled().Return(true).AnyTimes()
+			mockConfigurator.EXPECT().GetTracingHost().Return(constants.DefaultTracingHost).AnyTimes()
+			mockConfigurator.EXPECT().GetTracingPort().Return(constants.DefaultTracingPort).AnyTimes()
+
+			actual, err := getEnvoyConfigYAML(config, mockConfigurator)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(actual)).ToNot(ContainSubstring(constants.EnvoyTracingCluster))
+			Expect(string(actual)).ToNot(ContainSubstring("tracing"))
+		})
+
+		It("includes the stats tags config only when stats tags are enabled", func() {
+			actual, err := getEnvoyConfigYAML(config, mockConfigurator)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(actual)).ToNot(ContainSubstring("stats_config"))
+
+			statsTagsConfig := config
+			statsTagsConfig.StatsTagsEnabled = true
+			actual, err = getEnvoyConfigYAML(statsTagsConfig, mockConfigurator)
+			Expect(err).ToNot(HaveOccurred())
+
+			var bootstrap map[string]interface{}
+			Expect(yaml.Unmarshal(actual, &bootstrap)).To(Succeed())
+			Expect(bootstrap).To(HaveKeyWithValue("stats_config", map[interface{}]interface{}{
+				"use_all_default_tags": true,
+				"stats_tags": []interface{}{
+					map[interface{}]interface{}{
+						"tag_name": envoyRouteConfigStatsTag,
+						"regex":    envoyRouteConfigStatsTagRegex,
+					},
+				},
+			}))
+		})
+
 		It("Creates bootstrap config for the Envoy proxy", func() {
 			wh := &mutatingWebhook{
 				kubeClient:          fake.NewSimpleClientset(),
 				kubeController:      k8s.NewMockController(gomock.NewController(GinkgoT())),
 				nonInjectNamespaces: mapset.NewSet(),
 				meshName:            "some-mesh",
+				configurator:        mockConfigurator,
 			}
+			mockConfigurator.EXPECT().GetProxyStatsTagsEnabled().Return(false).Times(1)
 			name := uuid.New().String()
 			namespace := "a"
 			osmNamespace := "b"
@@ -244,8 +334,11 @@ var _ = Describe("Test functions creating Envoy bootstrap configuration", func()
 
 	Context("test getEnvoySidecarContainerSpec()", func() {
 		It("creates Envoy sidecar spec", func() {
-			mockConfigurator.EXPECT().GetEnvoyLogLevel().Return("debug").Times(1)
-			actual := getEnvoySidecarContainerSpec(pod, envoyImage, mockConfigurator, originalHealthProbes)
+			mockConfigurator.EXPECT().GetProxyImagePullPolicy().Return(corev1.PullAlways).Times(1)
+			mockConfigurator.EXPECT().GetProxyServiceNodeTemplate().Return(configurator.DefaultProxyServiceNodeTemplate).Times(1)
+			mockConfigurator.EXPECT().GetProxyServiceClusterTemplate().Return(configurator.DefaultProxyServiceClusterTemplate).Times(1)
+			mockConfigurator.EXPECT().GetProxyProbeSettings().Return(configurator.ProxyProbeSettings{InitialDelaySeconds: 10, PeriodSeconds: 15, FailureThreshold: 20}).Times(1)
+			actual := getEnvoySidecarContainerSpec(pod, envoyImage, "debug", constants.EnvoyConfigPath, constants.EnvoyUID, mockConfigurator, originalHealthProbes)
 
 			expected := corev1.Container{
 				Name:            constants.EnvoyContainerName,
@@ -258,11 +351,31 @@ var _ = Describe("Test functions creating Envoy bootstrap configuration", func()
 					}(),
 				},
 				Ports: expectedRewrittenContainerPorts,
+				StartupProbe: &corev1.Probe{
+					Handler: corev1.Handler{
+						Exec: &corev1.ExecAction{
+							Command: []string{"wget", "-q", "-O", "/dev/null", "http://127.0.0.1:15000/ready"},
+						},
+					},
+					InitialDelaySeconds: 10,
+					PeriodSeconds:       15,
+					FailureThreshold:    20,
+				},
+				LivenessProbe: &corev1.Probe{
+					Handler: corev1.Handler{
+						Exec: &corev1.ExecAction{
+							Command: []string{"wget", "-q", "-O", "/dev/null", "http://127.0.0.1:15000/server_info"},
+						},
+					},
+					InitialDelaySeconds: 10,
+					PeriodSeconds:       15,
+					FailureThreshold:    20,
+				},
 				VolumeMounts: []corev1.VolumeMount{
 					{
 						Name:      envoyBootstrapConfigVolume,
 						ReadOnly:  true,
-						MountPath: envoyProxyConfigPath,
+						MountPath: path.Dir(constants.EnvoyConfigPath),
 					},
 				},
 				Command: []string{
@@ -346,5 +459,19 @@ var _ = Describe("Test functions creating Envoy bootstrap configuration", func()
 
 			Expect(actual).To(Equal(expected))
 		})
+
+		It("sets the env vars reserved by the configurator", func() {
+			mockConfigurator.EXPECT().GetProxyImagePullPolicy().Return(corev1.PullAlways).Times(1)
+			mockConfigurator.EXPECT().GetProxyServiceNodeTemplate().Return(configurator.DefaultProxyServiceNodeTemplate).Times(1)
+			mockConfigurator.EXPECT().GetProxyServiceClusterTemplate().Return(configurator.DefaultProxyServiceClusterTemplate).Times(1)
+			mockConfigurator.EXPECT().GetProxyProbeSettings().Return(configurator.ProxyProbeSettings{InitialDelaySeconds: 10, PeriodSeconds: 15, FailureThreshold: 20}).Times(1)
+			actual := getEnvoySidecarContainerSpec(pod, envoyImage, "debug", constants.EnvoyConfigPath, constants.EnvoyUID, mockConfigurator, originalHealthProbes)
+
+			var names []string
+			for _, envVar := range actual.Env {
+				names = append(names, envVar.Name)
+			}
+			Expect(names).To(ConsistOf(configurator.ReservedProxyEnvNames))
+		})
 	})
 })
@@ -2,7 +2,7 @@ package injector
 
 import (
 	"fmt"
-	"strings"
+	"path"
 
 	corev1 "k8s.io/api/core/v1"
 
@@ -11,17 +11,10 @@ import (
 	"github.com/openservicemesh/osm/pkg/envoy"
 )
 
-const (
-	envoyBootstrapConfigFile = "bootstrap.yaml"
-	envoyProxyConfigPath     = "/etc/envoy"
-)
-
-func getEnvoySidecarContainerSpec(pod *corev1.Pod, envoyImage string, cfg configurator.Configurator, originalHealthProbes healthProbes) corev1.Container {
-	// nodeID and clusterID are required for Envoy proxy to start.
-	nodeID := pod.Spec.ServiceAccountName
-	// cluster ID will be used as an identifier to the tracing sink
-	clusterID := fmt.Sprintf("%s.%s", pod.Spec.ServiceAccountName, pod.Namespace)
+// envoyBootstrapConfigFile is the key of the bootstrap config in the bootstrap config secret
+const envoyBootstrapConfigFile = "bootstrap.yaml"
 
+func getEnvoySidecarContainerSpec(pod *corev1.Pod, envoyImage, envoyLogLevel, envoyConfigPath string, proxyUID int64, cfg configurator.Configurator, originalHealthProbes healthProbes) corev1.Container {
 	var workloadKind string
 	var workloadName string
 	for _, ref := range pod.GetOwnerReferences() {
@@ -32,26 +25,37 @@ func getEnvoySidecarContainerSpec(pod *corev1.Pod, envoyImage string, cfg config
 		}
 	}
 
+	serviceNameVars := configurator.ProxyServiceNameVars{
+		ServiceAccount: pod.Spec.ServiceAccountName,
+		Namespace:      pod.Namespace,
+		WorkloadKind:   workloadKind,
+		WorkloadName:   workloadName,
+	}
+	// nodeID and clusterID are required for Envoy proxy to start.
+	nodeID := getProxyServiceName(cfg.GetProxyServiceNodeTemplate(), serviceNameVars, pod.Spec.ServiceAccountName)
+	// cluster ID will be used as an identifier to the tracing sink
+	clusterID := getProxyServiceName(cfg.GetProxyServiceClusterTemplate(), serviceNameVars, fmt.Sprintf("%s.%s", pod.Spec.ServiceAccountName, pod.Namespace))
+	probeSettings := cfg.GetProxyProbeSettings()
+
 	return corev1.Container{
 		Name:            constants.EnvoyContainerName,
 		Image:           envoyImage,
-		ImagePullPolicy: corev1.PullAlways,
+		ImagePullPolicy: cfg.GetProxyImagePullPolicy(),
 		SecurityContext: &corev1.SecurityContext{
-			RunAsUser: func() *int64 {
-				uid := constants.EnvoyUID
-				return &uid
-			}(),
+			RunAsUser: &proxyUID,
 		},
-		Ports: getEnvoyContainerPorts(originalHealthProbes),
+		Ports:         getEnvoyContainerPorts(originalHealthProbes),
+		StartupProbe:  getEnvoyStartupProbe(probeSettings),
+		LivenessProbe: getEnvoyLivenessProbe(probeSettings),
 		VolumeMounts: []corev1.VolumeMount{{
 			Name:      envoyBootstrapConfigVolume,
 			ReadOnly:  true,
-			MountPath: envoyProxyConfigPath,
+			MountPath: path.Dir(envoyConfigPath),
 		}},
 		Command: []string{"envoy"},
 		Args: []string{
-			"--log-level", cfg.GetEnvoyLogLevel(),
-			"--config-path", strings.Join([]string{envoyProxyConfigPath, envoyBootstrapConfigFile}, "/"),
+			"--log-level", envoyLogLevel,
+			"--config-path", envoyConfigPath,
 			"--service-node", envoy.GetEnvoyServiceNodeID(nodeID, workloadKind, workloadName),
 			"--service-cluster", clusterID,
 			"--bootstrap-version 3",
@@ -101,6 +105,35 @@ func getEnvoySidecarContainerSpec(pod *corev1.Pod, envoyImage string, cfg config
 	}
 }
 
+// getProxyServiceName returns the name passed to Envoy with --service-node or --service-cluster rendered from the given
+// template, or the given default name if the template renders an invalid name
+func getProxyServiceName(tmpl string, vars configurator.ProxyServiceNameVars, defaultName string) string {
+	name, err := configurator.RenderProxyServiceName(tmpl, vars)
+	if err != nil {
+		log.Error().Err(err).Msgf("Error rendering the Envoy service name for service account %s/%s, using %s", vars.Namespace, vars.ServiceAccount, defaultName)
+		return defaultName
+	}
+	return name
+}
+
+// appendProxyEnv returns the env of the Envoy sidecar container followed by the given env vars configured for the
+// sidecar, in their order. The configured env vars do not override the env vars managed by OSM.
+func appendProxyEnv(env []corev1.EnvVar, proxyEnv []corev1.EnvVar) []corev1.EnvVar {
+	managed := make(map[string]bool, len(env))
+	for _, envVar := range env {
+		managed[envVar.Name] = true
+	}
+
+	for _, envVar := range proxyEnv {
+		if managed[envVar.Name] {
+			log.Warn().Msgf("Ignoring env var %s configured for the Envoy sidecar, it is managed by OSM", envVar.Name)
+			continue
+		}
+		env = append(env, envVar)
+	}
+	return env
+}
+
 func getEnvoyContainerPorts(originalHealthProbes healthProbes) []corev1.ContainerPort {
 	containerPorts := []corev1.ContainerPort{
 		{
@@ -0,0 +1,40 @@
+package injector
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// managedEnvoyFlags are the flags of the Envoy command line set by OSM, along with their short forms and the flags
+// overriding them, that the extra args of a pod can't set
+var managedEnvoyFlags = map[string]bool{
+	"--log-level":         true,
+	"-l":                  true,
+	"--config-path":       true,
+	"-c":                  true,
+	"--config-yaml":       true,
+	"--service-node":      true,
+	"--service-cluster":   true,
+	"--bootstrap-version": true,
+}
+
+// getEnvoyExtraArgs returns the args set by the pod annotation with the given key, separated by whitespace, to append
+// to the args of the Envoy sidecar. An error is returned if they set a flag managed by OSM.
+func getEnvoyExtraArgs(pod *corev1.Pod, annotation string) ([]string, error) {
+	value, ok := pod.Annotations[annotation]
+	if !ok {
+		return nil, nil
+	}
+
+	args := strings.Fields(value)
+	for _, arg := range args {
+		// Flags can be given with their value, e.g. --config-path=/etc/envoy/bootstrap.yaml
+		flag := strings.SplitN(arg, "=", 2)[0]
+		if managedEnvoyFlags[flag] {
+			return nil, errors.Errorf("Invalid value %q for annotation %s, flag %s is managed by OSM", value, annotation, flag)
+		}
+	}
+	return args, nil
+}
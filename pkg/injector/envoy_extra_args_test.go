@@ -0,0 +1,72 @@
+package injector
+
+import (
+	"fmt"
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+func TestGetEnvoyExtraArgs(t *testing.T) {
+	testCases := []struct {
+		name         string
+		annotations  map[string]string
+		expectedArgs []string
+		expectErr    bool
+	}{
+		{
+			name:         "no annotation",
+			annotations:  nil,
+			expectedArgs: nil,
+		},
+		{
+			name:         "args are passed through",
+			annotations:  map[string]string{constants.EnvoyExtraArgsAnnotation: "--drain-strategy immediate  --concurrency=2\n--disable-hot-restart"},
+			expectedArgs: []string{"--drain-strategy", "immediate", "--concurrency=2", "--disable-hot-restart"},
+		},
+		{
+			name:         "empty annotation",
+			annotations:  map[string]string{constants.EnvoyExtraArgsAnnotation: " "},
+			expectedArgs: []string{},
+		},
+		{
+			name:        "managed flag is overridden",
+			annotations: map[string]string{constants.EnvoyExtraArgsAnnotation: "--drain-strategy immediate --config-path /tmp/bootstrap.yaml"},
+			expectErr:   true,
+		},
+		{
+			name:        "managed flag is overridden with its value",
+			annotations: map[string]string{constants.EnvoyExtraArgsAnnotation: "--service-node=bookstore"},
+			expectErr:   true,
+		},
+		{
+			name:        "managed flag is overridden with its short form",
+			annotations: map[string]string{constants.EnvoyExtraArgsAnnotation: "-l trace"},
+			expectErr:   true,
+		},
+		{
+			name:        "bootstrap config is overridden",
+			annotations: map[string]string{constants.EnvoyExtraArgsAnnotation: "--config-yaml {}"},
+			expectErr:   true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			args, err := getEnvoyExtraArgs(pod, constants.EnvoyExtraArgsAnnotation)
+			if tc.expectErr {
+				assert.NotNil(err)
+				return
+			}
+			assert.Nil(err)
+			assert.Equal(tc.expectedArgs, args)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package injector
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// imageNameComponent is a path component of an image name, lowercase alphanumerics separated by '.', '_', '__' or dashes
+	imageNameComponent = `[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*`
+
+	// imageDomain is the registry of an image, a host name with an optional port
+	imageDomain = `(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])(?:\.(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9]))*(?::[0-9]+)?`
+
+	// imageTag is the tag of an image
+	imageTag = `[\w][\w.-]{0,127}`
+
+	// imageDigest is the digest of an image, ex. sha256:<hex>
+	imageDigest = `[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,}`
+
+	// maxImageNameLength is the maximum length of the name of an image, excluding its tag and digest
+	maxImageNameLength = 255
+
+	// defaultImageTag is the tag of an image referenced without tag nor digest
+	defaultImageTag = "latest"
+)
+
+// imageReferenceRegexp matches an image reference of the form [domain/]name[:tag][@digest], following the grammar
+// of the references accepted by container runtimes, and captures its name, tag and digest
+var imageReferenceRegexp = regexp.MustCompile(`^((?:` + imageDomain + `/)?` + imageNameComponent + `(?:/` + imageNameComponent + `)*)` +
+	`(?::(` + imageTag + `))?(?:@(` + imageDigest + `))?$`)
+
+// invalidLabelValueCharsRegexp matches the characters that are not allowed in the value of a label
+var invalidLabelValueCharsRegexp = regexp.MustCompile(`[^A-Za-z0-9_.-]`)
+
+// getEnvoyImage returns the image of the Envoy sidecar injected in the given pod. The pod annotation with the given key
+// overrides the image of the mesh, e.g. to canary a new proxy build on a single workload.
+func getEnvoyImage(pod *corev1.Pod, annotation, meshImage string) (string, error) {
+	image, ok := pod.Annotations[annotation]
+	if !ok {
+		return meshImage, nil
+	}
+
+	if !isValidImageReference(image) {
+		return "", errors.Errorf("Invalid value %q for annotation %s, must be a valid image reference", image, annotation)
+	}
+	return image, nil
+}
+
+// isValidImageReference returns true if the given string is a well-formed image reference
+func isValidImageReference(image string) bool {
+	match := imageReferenceRegexp.FindStringSubmatch(image)
+	return match != nil && len(match[1]) <= maxImageNameLength
+}
+
+// getEnvoyImageVersion returns the version of the given Envoy image as a valid label value, so that the injected proxies
+// can be selected by version. The version is the digest of the image when pinned by digest, since it identifies the
+// image, or its tag otherwise. The whole image is used when it is not a well-formed image reference. The characters
+// not allowed in a label value are replaced with dashes, and the version is truncated to the maximum length of a label
+// value.
+func getEnvoyImageVersion(image string) string {
+	version := image
+	if match := imageReferenceRegexp.FindStringSubmatch(image); match != nil {
+		switch {
+		case match[3] != "":
+			version = match[3]
+		case match[2] != "":
+			version = match[2]
+		default:
+			version = defaultImageTag
+		}
+	}
+
+	version = invalidLabelValueCharsRegexp.ReplaceAllString(version, "-")
+	if len(version) > validation.LabelValueMaxLength {
+		version = version[:validation.LabelValueMaxLength]
+	}
+	// A label value must begin and end with an alphanumeric character
+	return strings.Trim(version, "_.-")
+}
@@ -0,0 +1,132 @@
+package injector
+
+import (
+	"fmt"
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+func TestGetEnvoyImage(t *testing.T) {
+	const meshImage = "envoyproxy/envoy-alpine:v1.17.1"
+
+	testCases := []struct {
+		name          string
+		annotations   map[string]string
+		expectedImage string
+		expectErr     bool
+	}{
+		{
+			name:          "image of the mesh without annotation",
+			annotations:   nil,
+			expectedImage: meshImage,
+			expectErr:     false,
+		},
+		{
+			name:          "image overridden by the annotation",
+			annotations:   map[string]string{constants.EnvoyImageAnnotation: "envoyproxy/envoy-alpine:v1.18.0"},
+			expectedImage: "envoyproxy/envoy-alpine:v1.18.0",
+			expectErr:     false,
+		},
+		{
+			name:          "image of a private registry with a port",
+			annotations:   map[string]string{constants.EnvoyImageAnnotation: "localhost:5000/envoy"},
+			expectedImage: "localhost:5000/envoy",
+			expectErr:     false,
+		},
+		{
+			name:          "image pinned by digest",
+			annotations:   map[string]string{constants.EnvoyImageAnnotation: "docker.io/envoyproxy/envoy@sha256:" + fmt.Sprintf("%064d", 0)},
+			expectedImage: "docker.io/envoyproxy/envoy@sha256:" + fmt.Sprintf("%064d", 0),
+			expectErr:     false,
+		},
+		{
+			name:        "empty image",
+			annotations: map[string]string{constants.EnvoyImageAnnotation: ""},
+			expectErr:   true,
+		},
+		{
+			name:        "image with uppercase characters in its name",
+			annotations: map[string]string{constants.EnvoyImageAnnotation: "envoyproxy/Envoy:v1.18.0"},
+			expectErr:   true,
+		},
+		{
+			name:        "image with an empty tag",
+			annotations: map[string]string{constants.EnvoyImageAnnotation: "envoyproxy/envoy:"},
+			expectErr:   true,
+		},
+		{
+			name:        "image with whitespace",
+			annotations: map[string]string{constants.EnvoyImageAnnotation: "envoyproxy/envoy:v1.18.0 --privileged"},
+			expectErr:   true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			image, err := getEnvoyImage(pod, constants.EnvoyImageAnnotation, meshImage)
+
+			assert.Equal(tc.expectErr, err != nil)
+			assert.Equal(tc.expectedImage, image)
+		})
+	}
+}
+
+func TestGetEnvoyImageVersion(t *testing.T) {
+	digest := "sha256:" + fmt.Sprintf("%064d", 0)
+
+	testCases := []struct {
+		name            string
+		image           string
+		expectedVersion string
+	}{
+		{
+			name:            "tag of the image",
+			image:           "envoyproxy/envoy-alpine:v1.18.3",
+			expectedVersion: "v1.18.3",
+		},
+		{
+			name:            "tag of an image of a private registry with a port",
+			image:           "localhost:5000/envoy:v1.18.3-canary",
+			expectedVersion: "v1.18.3-canary",
+		},
+		{
+			name:            "image without tag",
+			image:           "localhost:5000/envoy",
+			expectedVersion: "latest",
+		},
+		{
+			name:            "digest of an image pinned by digest, truncated to the maximum length of a label value",
+			image:           "docker.io/envoyproxy/envoy:v1.18.3@" + digest,
+			expectedVersion: ("sha256-" + fmt.Sprintf("%064d", 0))[:63],
+		},
+		{
+			name:            "tag starting with an underscore",
+			image:           "envoyproxy/envoy:_canary",
+			expectedVersion: "canary",
+		},
+		{
+			name:            "invalid image reference",
+			image:           "Envoy Proxy",
+			expectedVersion: "Envoy-Proxy",
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			version := getEnvoyImageVersion(tc.image)
+			assert.Equal(tc.expectedVersion, version)
+			assert.Empty(validation.IsValidLabelValue(version))
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package injector
+
+import (
+	"github.com/pkg/errors"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+// getEnvoyLogLevel returns the log level of the Envoy sidecars injected in the given namespace.
+// The namespace annotation overrides the mesh-wide log level.
+func (wh *mutatingWebhook) getEnvoyLogLevel(namespace string) (string, error) {
+	ns := wh.kubeController.GetNamespace(namespace)
+	if ns == nil {
+		log.Error().Err(errNamespaceNotFound).Msgf("Error retrieving namespace %s", namespace)
+		return "", errNamespaceNotFound
+	}
+
+	annotation := wh.annotation(constants.EnvoyLogLevelAnnotation)
+	logLevel, ok := ns.Annotations[annotation]
+	if !ok {
+		return wh.configurator.GetEnvoyLogLevel(), nil
+	}
+
+	log.Trace().Msgf("Envoy log level annotation: '%s:%s'", annotation, logLevel)
+	for _, validLogLevel := range configurator.ValidEnvoyLogLevels {
+		if logLevel == validLogLevel {
+			return logLevel, nil
+		}
+	}
+	return "", errors.Errorf("Invalid value %q for annotation %s on namespace %s, must be one of %v", logLevel, annotation, namespace, configurator.ValidEnvoyLogLevels)
+}
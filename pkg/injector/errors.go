@@ -6,4 +6,5 @@ var (
 	errNamespaceNotFound   = errors.New("namespace not found")
 	errParseWebhookTimeout = errors.New("could not read webhook timeout")
 	errNilAdmissionRequest = errors.New("nil admission request")
+	errPodNotMeshed        = errors.New("pod is not a part of the mesh")
 )
@@ -0,0 +1,60 @@
+package injector
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// eventSource is the name of the component recording the sidecar injection events
+	eventSource = "osm-injector"
+
+	// eventReasonSidecarInjected signifies that the Envoy sidecar was injected into a pod
+	eventReasonSidecarInjected = "SidecarInjected"
+
+	// eventReasonSidecarInjectionSkipped signifies that the Envoy sidecar was not injected into a pod
+	eventReasonSidecarInjectionSkipped = "SidecarInjectionSkipped"
+
+	// eventReasonSidecarInjectionFailed signifies that the admission of a pod failed while injecting the Envoy sidecar
+	eventReasonSidecarInjectionFailed = "SidecarInjectionFailed"
+)
+
+// newEventRecorder returns an EventRecorder posting Kubernetes events to the namespace of the objects they are recorded for
+func newEventRecorder(kubeClient kubernetes.Interface) record.EventRecorder {
+	eventBroadcaster := record.NewBroadcaster()
+	eventBroadcaster.StartRecordingToSink(
+		&typedcorev1.EventSinkImpl{
+			Interface: kubeClient.CoreV1().Events("")})
+	return eventBroadcaster.NewRecorder(
+		scheme.Scheme,
+		corev1.EventSource{Component: eventSource})
+}
+
+// recordInjectionEvent records a Kubernetes event about the sidecar injection of the given pod in the given namespace.
+// The event is recorded for the pod, or for its namespace when the pod is not named yet, e.g. when its name is
+// generated by the API server.
+func (wh *mutatingWebhook) recordInjectionEvent(pod *corev1.Pod, namespace string, proxyUUID uuid.UUID, eventType, reason, message string) {
+	if wh.eventRecorder == nil {
+		return
+	}
+
+	podName := pod.Name
+	var object runtime.Object = &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+	if podName != "" {
+		podRef := pod.DeepCopy()
+		podRef.Namespace = namespace
+		object = podRef
+	} else {
+		podName = fmt.Sprintf("%s<generated>", pod.GenerateName)
+	}
+
+	wh.eventRecorder.Eventf(object, eventType, reason, "Pod %s/%s with proxy UUID %s: %s", namespace, podName, proxyUUID, message)
+}
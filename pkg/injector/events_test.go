@@ -0,0 +1,112 @@
+package injector
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	mapset "github.com/deckarep/golang-set"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+	"github.com/openservicemesh/osm/pkg/constants"
+	k8s "github.com/openservicemesh/osm/pkg/kubernetes"
+)
+
+var _ = Describe("Testing sidecar injection events", func() {
+	const namespace = "events-namespace"
+	proxyUUID := uuid.New()
+
+	var (
+		wh                 *mutatingWebhook
+		mockKubeController *k8s.MockController
+		mockConfigurator   *configurator.MockConfigurator
+		recorder           *record.FakeRecorder
+	)
+
+	newRequest := func(pod corev1.Pod) *admissionv1.AdmissionRequest {
+		raw, err := json.Marshal(pod)
+		Expect(err).ToNot(HaveOccurred())
+		return &admissionv1.AdmissionRequest{Namespace: namespace, Object: runtime.RawExtension{Raw: raw}}
+	}
+
+	BeforeEach(func() {
+		mockCtrl := gomock.NewController(GinkgoT())
+		mockKubeController = k8s.NewMockController(mockCtrl)
+		mockConfigurator = configurator.NewMockConfigurator(mockCtrl)
+		recorder = record.NewFakeRecorder(10)
+		wh = &mutatingWebhook{
+			kubeController:      mockKubeController,
+			configurator:        mockConfigurator,
+			eventRecorder:       recorder,
+			nonInjectNamespaces: mapset.NewSet(),
+		}
+	})
+
+	It("records a skipped injection for a pod in a namespace that is not part of the mesh", func() {
+		mockKubeController.EXPECT().IsMonitoredNamespace(namespace).Return(false).Times(1)
+		pod := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod"}}
+
+		resp := wh.mutate(newRequest(pod), proxyUUID)
+
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(recorder.Events).To(Receive(Equal(
+			"Normal SidecarInjectionSkipped Pod events-namespace/pod with proxy UUID " + proxyUUID.String() +
+				": Sidecar injection skipped, namespace events-namespace is not part of the mesh")))
+	})
+
+	It("records a skipped injection for a pod that already has the sidecar", func() {
+		mockKubeController.EXPECT().IsMonitoredNamespace(namespace).Return(true).Times(1)
+		mockKubeController.EXPECT().GetNamespace(namespace).Return(&corev1.Namespace{}).Times(1)
+		pod := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "pod-",
+				Annotations:  map[string]string{constants.SidecarInjectionAnnotation: "enabled"},
+			},
+			Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: constants.EnvoyContainerName}}},
+		}
+
+		resp := wh.mutate(newRequest(pod), proxyUUID)
+
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(recorder.Events).To(Receive(And(
+			HavePrefix("Normal SidecarInjectionSkipped Pod events-namespace/pod-<generated> with proxy UUID "+proxyUUID.String()),
+			ContainSubstring("Sidecar injection skipped, pod already has a container named \"envoy\""),
+		)))
+	})
+
+	It("records a failed injection when the admission of the pod fails", func() {
+		mockKubeController.EXPECT().IsMonitoredNamespace(namespace).Return(true).Times(1)
+		pod := corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "pod",
+				Annotations: map[string]string{constants.SidecarInjectionAnnotation: "invalid-value"},
+			},
+		}
+		mockConfigurator.EXPECT().GetInjectorFailurePolicy().Return(configurator.InjectorFailurePolicyFail).Times(1)
+
+		resp := wh.mutate(newRequest(pod), proxyUUID)
+
+		Expect(resp.Allowed).To(BeFalse())
+		Expect(recorder.Events).To(Receive(HavePrefix(
+			"Warning SidecarInjectionFailed Pod events-namespace/pod with proxy UUID " + proxyUUID.String() +
+				": Error checking if the sidecar must be injected")))
+	})
+
+	It("does not record events without an event recorder", func() {
+		mockKubeController.EXPECT().IsMonitoredNamespace(namespace).Return(false).Times(1)
+		wh.eventRecorder = nil
+
+		resp := wh.mutate(newRequest(corev1.Pod{}), proxyUUID)
+
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(recorder.Events).ToNot(Receive())
+	})
+})
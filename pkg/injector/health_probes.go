@@ -31,24 +31,76 @@ type healthProbe struct {
 // healthProbes is to serve as an indication whether the given healthProbe has been rewritten
 type healthProbes struct {
 	liveness, readiness, startup *healthProbe
+
+	// excludedPorts are the ports of the probes that could not be rewritten to be served by the proxy, to exclude from
+	// inbound interception so that the kubelet reaches them directly
+	excludedPorts []int
 }
 
+// rewriteHealthProbes rewrites the httpGet and tcpSocket probes of the containers of the given pod, whose ports would
+// be intercepted by the proxy, and returns the original probes to be served by the proxy. The proxy serves a single
+// probe of each type, so the probe of the first container defining a probe of a type is rewritten to be served by the
+// proxy, while the ports of the probes of the same type of the other containers are excluded from inbound interception.
+// Probes whose named port does not match any port of their container are left unchanged.
 func rewriteHealthProbes(pod *corev1.Pod) healthProbes {
 	probes := healthProbes{}
 	for idx := range pod.Spec.Containers {
-		if probe := rewriteLiveness(&pod.Spec.Containers[idx]); probe != nil {
-			probes.liveness = probe
+		container := &pod.Spec.Containers[idx]
+		if probes.liveness == nil {
+			probes.liveness = rewriteLiveness(container)
+		} else {
+			probes.excludeProbePort(container.LivenessProbe, "liveness", container)
 		}
-		if probe := rewriteReadiness(&pod.Spec.Containers[idx]); probe != nil {
-			probes.readiness = probe
+		if probes.readiness == nil {
+			probes.readiness = rewriteReadiness(container)
+		} else {
+			probes.excludeProbePort(container.ReadinessProbe, "readiness", container)
 		}
-		if probe := rewriteStartup(&pod.Spec.Containers[idx]); probe != nil {
-			probes.startup = probe
+		if probes.startup == nil {
+			probes.startup = rewriteStartup(container)
+		} else {
+			probes.excludeProbePort(container.StartupProbe, "startup", container)
 		}
 	}
 	return probes
 }
 
+// excludeProbePort records the port of the given probe of the given container, left unchanged as the proxy already
+// serves a probe of the same type, to be excluded from inbound interception
+func (probes *healthProbes) excludeProbePort(probe *corev1.Probe, probeType string, container *corev1.Container) {
+	definedPort := getProbePort(probe)
+	if definedPort == nil {
+		return
+	}
+	port, err := getPort(*definedPort, &container.Ports)
+	if err != nil {
+		log.Warn().Err(err).Msgf("Error finding a matching port for the %s probe port %s of container %s, the probe is left unchanged", probeType, definedPort.String(), container.Name)
+		return
+	}
+
+	for _, excludedPort := range probes.excludedPorts {
+		if excludedPort == int(port) {
+			return
+		}
+	}
+	log.Debug().Msgf("Excluding port %d of the %s probe of container %s from inbound interception, the proxy already serves a %s probe", port, probeType, container.Name, probeType)
+	probes.excludedPorts = append(probes.excludedPorts, int(port))
+}
+
+// getProbePort returns the port of the given httpGet or tcpSocket probe, or nil for any other probe
+func getProbePort(probe *corev1.Probe) *intstr.IntOrString {
+	switch {
+	case probe == nil:
+		return nil
+	case probe.HTTPGet != nil:
+		return &probe.HTTPGet.Port
+	case probe.TCPSocket != nil:
+		return &probe.TCPSocket.Port
+	default:
+		return nil
+	}
+}
+
 func rewriteLiveness(container *corev1.Container) *healthProbe {
 	return rewriteProbe(container.LivenessProbe, "liveness", livenessProbePath, livenessProbePort, &container.Ports)
 }
@@ -66,27 +118,27 @@ func rewriteProbe(probe *corev1.Probe, probeType, path string, port int32, conta
 		return nil
 	}
 
-	originalProbe := &healthProbe{}
+	definedPort := getProbePort(probe)
+	if definedPort == nil {
+		return nil
+	}
+
+	// A probe whose port can't be resolved fails regardless of the proxy, so it is left unchanged
+	originalPort, err := getPort(*definedPort, containerPorts)
+	if err != nil {
+		log.Err(err).Msgf("Error finding a matching port for %+v on container %+v, the %s probe is left unchanged", *definedPort, containerPorts, probeType)
+		return nil
+	}
+
+	originalProbe := &healthProbe{port: originalPort}
 	var newPath string
-	var definedPort *intstr.IntOrString
 	if probe.HTTPGet != nil {
-		definedPort = &probe.HTTPGet.Port
 		originalProbe.isHTTP = len(probe.HTTPGet.Scheme) == 0 || probe.HTTPGet.Scheme == corev1.URISchemeHTTP
 		originalProbe.path = probe.HTTPGet.Path
 		if originalProbe.isHTTP {
 			probe.HTTPGet.Path = path
 			newPath = probe.HTTPGet.Path
 		}
-	} else if probe.TCPSocket != nil {
-		definedPort = &probe.TCPSocket.Port
-	} else {
-		return nil
-	}
-
-	var err error
-	originalProbe.port, err = getPort(*definedPort, containerPorts)
-	if err != nil {
-		log.Err(err).Msgf("Error finding a matching port for %+v on container %+v", *definedPort, containerPorts)
 	}
 	*definedPort = intstr.IntOrString{Type: intstr.Int, IntVal: port}
 
@@ -197,6 +197,84 @@ func TestRewriteProbe(t *testing.T) {
 	})
 }
 
+func TestRewriteHealthProbesMultipleContainers(t *testing.T) {
+	assert := tassert.New(t)
+
+	pod := &v1.Pod{
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name: "app",
+					LivenessProbe: &v1.Probe{
+						Handler: v1.Handler{HTTPGet: &v1.HTTPGetAction{Path: "/healthz", Port: intstr.FromInt(8080)}},
+					},
+					ReadinessProbe: &v1.Probe{
+						Handler: v1.Handler{TCPSocket: &v1.TCPSocketAction{Port: intstr.FromString("grpc")}},
+					},
+					Ports: []v1.ContainerPort{{Name: "grpc", ContainerPort: 9090}},
+				},
+				{
+					Name: "sidecar",
+					LivenessProbe: &v1.Probe{
+						Handler: v1.Handler{HTTPGet: &v1.HTTPGetAction{Path: "/live", Port: intstr.FromString("admin")}},
+					},
+					ReadinessProbe: &v1.Probe{
+						Handler: v1.Handler{TCPSocket: &v1.TCPSocketAction{Port: intstr.FromInt(9091)}},
+					},
+					StartupProbe: &v1.Probe{
+						Handler: v1.Handler{TCPSocket: &v1.TCPSocketAction{Port: intstr.FromInt(9091)}},
+					},
+					Ports: []v1.ContainerPort{{Name: "admin", ContainerPort: 8081}},
+				},
+			},
+		},
+	}
+
+	actual := rewriteHealthProbes(pod)
+	expected := healthProbes{
+		liveness: &healthProbe{
+			path:   "/healthz",
+			port:   8080,
+			isHTTP: true,
+		},
+		readiness: &healthProbe{
+			port: 9090,
+		},
+		startup: &healthProbe{
+			port: 9091,
+		},
+		// The probes of the second container of the types already served by the proxy are excluded, once per port
+		excludedPorts: []int{8081, 9091},
+	}
+	assert.Equal(expected, actual)
+
+	// The probes of the first container are served by the proxy
+	assert.Equal(intstr.FromInt(int(livenessProbePort)), pod.Spec.Containers[0].LivenessProbe.HTTPGet.Port)
+	assert.Equal(livenessProbePath, pod.Spec.Containers[0].LivenessProbe.HTTPGet.Path)
+	assert.Equal(intstr.FromInt(int(readinessProbePort)), pod.Spec.Containers[0].ReadinessProbe.TCPSocket.Port)
+
+	// The probes of the second container of the types already served by the proxy are left unchanged
+	assert.Equal(intstr.FromString("admin"), pod.Spec.Containers[1].LivenessProbe.HTTPGet.Port)
+	assert.Equal("/live", pod.Spec.Containers[1].LivenessProbe.HTTPGet.Path)
+	assert.Equal(intstr.FromInt(9091), pod.Spec.Containers[1].ReadinessProbe.TCPSocket.Port)
+	assert.Equal(intstr.FromInt(int(startupProbePort)), pod.Spec.Containers[1].StartupProbe.TCPSocket.Port)
+}
+
+func TestRewriteProbeUnresolvedPort(t *testing.T) {
+	assert := tassert.New(t)
+
+	probe := &v1.Probe{
+		Handler: v1.Handler{HTTPGet: &v1.HTTPGetAction{Path: "/healthz", Port: intstr.FromString("missing")}},
+	}
+	containerPorts := []v1.ContainerPort{{Name: "http", ContainerPort: 8080}}
+
+	assert.Nil(rewriteProbe(probe, "liveness", livenessProbePath, livenessProbePort, &containerPorts))
+
+	// The probe is left unchanged
+	assert.Equal(intstr.FromString("missing"), probe.HTTPGet.Port)
+	assert.Equal("/healthz", probe.HTTPGet.Path)
+}
+
 func TestGetPort(t *testing.T) {
 	containerPorts := &[]v1.ContainerPort{
 		{
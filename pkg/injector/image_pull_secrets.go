@@ -0,0 +1,21 @@
+package injector
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// addImagePullSecrets adds the given image pull secrets to the pod, skipping the secrets already referenced by the pod
+func addImagePullSecrets(pod *corev1.Pod, secretNames []string) {
+	existing := make(map[string]bool)
+	for _, secret := range pod.Spec.ImagePullSecrets {
+		existing[secret.Name] = true
+	}
+
+	for _, name := range secretNames {
+		if existing[name] {
+			continue
+		}
+		pod.Spec.ImagePullSecrets = append(pod.Spec.ImagePullSecrets, corev1.LocalObjectReference{Name: name})
+		existing[name] = true
+	}
+}
@@ -6,13 +6,14 @@ import (
 	corev1 "k8s.io/api/core/v1"
 )
 
-func getInitContainerSpec(containerName string, containerImage string, outboundIPRangeExclusionList []string, enablePrivilegedInitContainer bool) corev1.Container {
-	iptablesInitCommandsList := generateIptablesCommands(outboundIPRangeExclusionList)
+func getInitContainerSpec(containerName string, containerImage string, outboundIPRangeExclusionList []string, outboundPortExclusionList []int, inboundPortExclusionList []int, proxyUID int64, enablePrivilegedInitContainer bool, pullPolicy corev1.PullPolicy) corev1.Container {
+	iptablesInitCommandsList := generateIptablesCommands(outboundIPRangeExclusionList, outboundPortExclusionList, inboundPortExclusionList, proxyUID)
 	iptablesInitCommand := strings.Join(iptablesInitCommandsList, " && ")
 
 	return corev1.Container{
-		Name:  containerName,
-		Image: containerImage,
+		Name:            containerName,
+		Image:           containerImage,
+		ImagePullPolicy: pullPolicy,
 		SecurityContext: &corev1.SecurityContext{
 			Privileged: &enablePrivilegedInitContainer,
 			Capabilities: &corev1.Capabilities{
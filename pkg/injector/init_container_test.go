@@ -6,6 +6,8 @@ import (
 
 	tassert "github.com/stretchr/testify/assert"
 	v1 "k8s.io/api/core/v1"
+
+	"github.com/openservicemesh/osm/pkg/constants"
 )
 
 func TestGetInitContainerSpec(t *testing.T) {
@@ -21,16 +23,19 @@ func TestGetInitContainerSpec(t *testing.T) {
 		name                         string
 		outboundIPRangeExclusionList []string
 		privileged                   bool
+		pullPolicy                   v1.PullPolicy
 		expectedSpec                 v1.Container
 	}{
 		{
 			name:                         "init container without outbound exclusion list",
 			outboundIPRangeExclusionList: nil,
 			privileged:                   privilegedFalse,
+			pullPolicy:                   v1.PullAlways,
 			expectedSpec: v1.Container{
-				Name:    "-container-name-",
-				Image:   "-init-container-image-",
-				Command: []string{"/bin/sh"},
+				Name:            "-container-name-",
+				Image:           "-init-container-image-",
+				ImagePullPolicy: v1.PullAlways,
+				Command:         []string{"/bin/sh"},
 				Args: []string{
 					"-c",
 					"iptables -t nat -N PROXY_INBOUND && iptables -t nat -N PROXY_IN_REDIRECT && iptables -t nat -N PROXY_OUTPUT && iptables -t nat -N PROXY_REDIRECT && iptables -t nat -A PROXY_REDIRECT -p tcp -j REDIRECT --to-port 15001 && iptables -t nat -A PROXY_REDIRECT -p tcp --dport 15000 -j ACCEPT && iptables -t nat -A OUTPUT -p tcp -j PROXY_OUTPUT && iptables -t nat -A PROXY_OUTPUT -m owner --uid-owner 1500 -j RETURN && iptables -t nat -A PROXY_OUTPUT -d 127.0.0.1/32 -j RETURN && iptables -t nat -A PROXY_OUTPUT -j PROXY_REDIRECT && iptables -t nat -A PROXY_IN_REDIRECT -p tcp -j REDIRECT --to-port 15003 && iptables -t nat -A PREROUTING -p tcp -j PROXY_INBOUND && iptables -t nat -A PROXY_INBOUND -p tcp --dport 15010 -j RETURN && iptables -t nat -A PROXY_INBOUND -p tcp --dport 15901 -j RETURN && iptables -t nat -A PROXY_INBOUND -p tcp --dport 15902 -j RETURN && iptables -t nat -A PROXY_INBOUND -p tcp --dport 15903 -j RETURN && iptables -t nat -A PROXY_INBOUND -p tcp -j PROXY_IN_REDIRECT",
@@ -54,10 +59,12 @@ func TestGetInitContainerSpec(t *testing.T) {
 			name:                         "init container with outbound exclusion list",
 			outboundIPRangeExclusionList: []string{"1.1.1.1/32", "10.0.0.10/24"},
 			privileged:                   privilegedFalse,
+			pullPolicy:                   v1.PullAlways,
 			expectedSpec: v1.Container{
-				Name:    "-container-name-",
-				Image:   "-init-container-image-",
-				Command: []string{"/bin/sh"},
+				Name:            "-container-name-",
+				Image:           "-init-container-image-",
+				ImagePullPolicy: v1.PullAlways,
+				Command:         []string{"/bin/sh"},
 				Args: []string{
 					"-c",
 					"iptables -t nat -N PROXY_INBOUND && iptables -t nat -N PROXY_IN_REDIRECT && iptables -t nat -N PROXY_OUTPUT && iptables -t nat -N PROXY_REDIRECT && iptables -t nat -A PROXY_REDIRECT -p tcp -j REDIRECT --to-port 15001 && iptables -t nat -A PROXY_REDIRECT -p tcp --dport 15000 -j ACCEPT && iptables -t nat -A OUTPUT -p tcp -j PROXY_OUTPUT && iptables -t nat -A PROXY_OUTPUT -m owner --uid-owner 1500 -j RETURN && iptables -t nat -A PROXY_OUTPUT -d 127.0.0.1/32 -j RETURN && iptables -t nat -A PROXY_OUTPUT -j PROXY_REDIRECT && iptables -t nat -A PROXY_IN_REDIRECT -p tcp -j REDIRECT --to-port 15003 && iptables -t nat -A PREROUTING -p tcp -j PROXY_INBOUND && iptables -t nat -A PROXY_INBOUND -p tcp --dport 15010 -j RETURN && iptables -t nat -A PROXY_INBOUND -p tcp --dport 15901 -j RETURN && iptables -t nat -A PROXY_INBOUND -p tcp --dport 15902 -j RETURN && iptables -t nat -A PROXY_INBOUND -p tcp --dport 15903 -j RETURN && iptables -t nat -A PROXY_INBOUND -p tcp -j PROXY_IN_REDIRECT && iptables -t nat -I PROXY_OUTPUT -d 1.1.1.1/32 -j RETURN && iptables -t nat -I PROXY_OUTPUT -d 10.0.0.10/24 -j RETURN",
@@ -81,10 +88,12 @@ func TestGetInitContainerSpec(t *testing.T) {
 			name:                         "init container with privileged true",
 			outboundIPRangeExclusionList: nil,
 			privileged:                   privilegedTrue,
+			pullPolicy:                   v1.PullIfNotPresent,
 			expectedSpec: v1.Container{
-				Name:    "-container-name-",
-				Image:   "-init-container-image-",
-				Command: []string{"/bin/sh"},
+				Name:            "-container-name-",
+				Image:           "-init-container-image-",
+				ImagePullPolicy: v1.PullIfNotPresent,
+				Command:         []string{"/bin/sh"},
 				Args: []string{
 					"-c",
 					"iptables -t nat -N PROXY_INBOUND && iptables -t nat -N PROXY_IN_REDIRECT && iptables -t nat -N PROXY_OUTPUT && iptables -t nat -N PROXY_REDIRECT && iptables -t nat -A PROXY_REDIRECT -p tcp -j REDIRECT --to-port 15001 && iptables -t nat -A PROXY_REDIRECT -p tcp --dport 15000 -j ACCEPT && iptables -t nat -A OUTPUT -p tcp -j PROXY_OUTPUT && iptables -t nat -A PROXY_OUTPUT -m owner --uid-owner 1500 -j RETURN && iptables -t nat -A PROXY_OUTPUT -d 127.0.0.1/32 -j RETURN && iptables -t nat -A PROXY_OUTPUT -j PROXY_REDIRECT && iptables -t nat -A PROXY_IN_REDIRECT -p tcp -j REDIRECT --to-port 15003 && iptables -t nat -A PREROUTING -p tcp -j PROXY_INBOUND && iptables -t nat -A PROXY_INBOUND -p tcp --dport 15010 -j RETURN && iptables -t nat -A PROXY_INBOUND -p tcp --dport 15901 -j RETURN && iptables -t nat -A PROXY_INBOUND -p tcp --dport 15902 -j RETURN && iptables -t nat -A PROXY_INBOUND -p tcp --dport 15903 -j RETURN && iptables -t nat -A PROXY_INBOUND -p tcp -j PROXY_IN_REDIRECT",
@@ -108,7 +117,7 @@ func TestGetInitContainerSpec(t *testing.T) {
 
 	for i, tc := range testCases {
 		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
-			actual := getInitContainerSpec(containerName, containerImage, tc.outboundIPRangeExclusionList, tc.privileged)
+			actual := getInitContainerSpec(containerName, containerImage, tc.outboundIPRangeExclusionList, nil, nil, constants.EnvoyUID, tc.privileged, tc.pullPolicy)
 			assert.Equal(tc.expectedSpec, actual)
 		})
 	}
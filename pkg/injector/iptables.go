@@ -2,10 +2,18 @@ package injector
 
 import (
 	"fmt"
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
 
 	"github.com/openservicemesh/osm/pkg/constants"
 )
 
+// maxMultiportPorts is the maximum number of ports matched by a single iptables multiport rule
+const maxMultiportPorts = 15
+
 // iptablesRedirectionChains is the list of iptables chains created for traffic redirection via the proxy sidecar
 var iptablesRedirectionChains = []string{
 	// Chain to intercept inbound traffic
@@ -21,27 +29,30 @@ var iptablesRedirectionChains = []string{
 	"iptables -t nat -N PROXY_REDIRECT",
 }
 
-// iptablesOutboundStaticRules is the list of iptables rules related to outbound traffic interception and redirection
-var iptablesOutboundStaticRules = []string{
-	// Redirects outbound TCP traffic hitting PROXY_REDIRECT chain to Envoy's outbound listener port
-	fmt.Sprintf("iptables -t nat -A PROXY_REDIRECT -p tcp -j REDIRECT --to-port %d", constants.EnvoyOutboundListenerPort),
+// getIptablesOutboundStaticRules returns the list of iptables rules related to outbound traffic interception and
+// redirection, for the proxy running as the given UID
+func getIptablesOutboundStaticRules(proxyUID int64) []string {
+	return []string{
+		// Redirects outbound TCP traffic hitting PROXY_REDIRECT chain to Envoy's outbound listener port
+		fmt.Sprintf("iptables -t nat -A PROXY_REDIRECT -p tcp -j REDIRECT --to-port %d", constants.EnvoyOutboundListenerPort),
 
-	// Traffic to the Proxy Admin port flows to the Proxy -- not redirected
-	fmt.Sprintf("iptables -t nat -A PROXY_REDIRECT -p tcp --dport %d -j ACCEPT", constants.EnvoyAdminPort),
+		// Traffic to the Proxy Admin port flows to the Proxy -- not redirected
+		fmt.Sprintf("iptables -t nat -A PROXY_REDIRECT -p tcp --dport %d -j ACCEPT", constants.EnvoyAdminPort),
 
-	// For outbound TCP traffic jump from OUTPUT chain to PROXY_OUTPUT chain
-	"iptables -t nat -A OUTPUT -p tcp -j PROXY_OUTPUT",
+		// For outbound TCP traffic jump from OUTPUT chain to PROXY_OUTPUT chain
+		"iptables -t nat -A OUTPUT -p tcp -j PROXY_OUTPUT",
 
-	// TODO(#1266): Redirect app back calls to itself using PROXY_UID
+		// TODO(#1266): Redirect app back calls to itself using PROXY_UID
 
-	// Don't redirect Envoy traffic back to itself, return it to the next chain for processing
-	fmt.Sprintf("iptables -t nat -A PROXY_OUTPUT -m owner --uid-owner %d -j RETURN", constants.EnvoyUID),
+		// Don't redirect Envoy traffic back to itself, return it to the next chain for processing
+		fmt.Sprintf("iptables -t nat -A PROXY_OUTPUT -m owner --uid-owner %d -j RETURN", proxyUID),
 
-	// Skip localhost traffic, doesn't need to be routed via the proxy
-	"iptables -t nat -A PROXY_OUTPUT -d 127.0.0.1/32 -j RETURN",
+		// Skip localhost traffic, doesn't need to be routed via the proxy
+		"iptables -t nat -A PROXY_OUTPUT -d 127.0.0.1/32 -j RETURN",
 
-	// Redirect remaining outbound traffic to Envoy
-	"iptables -t nat -A PROXY_OUTPUT -j PROXY_REDIRECT",
+		// Redirect remaining outbound traffic to Envoy
+		"iptables -t nat -A PROXY_OUTPUT -j PROXY_REDIRECT",
+	}
 }
 
 // iptablesInboundStaticRules is the list of iptables rules related to inbound traffic interception and redirection
@@ -66,26 +77,88 @@ var iptablesInboundStaticRules = []string{
 	"iptables -t nat -A PROXY_INBOUND -p tcp -j PROXY_IN_REDIRECT",
 }
 
-// generateIptablesCommands generates a list of iptables commands to set up sidecar interception and redirection
-func generateIptablesCommands(outboundIPRangeExclusionList []string) []string {
+// generateIptablesCommands generates a list of iptables commands to set up sidecar interception and redirection for
+// the proxy running as the given UID
+func generateIptablesCommands(outboundIPRangeExclusionList []string, outboundPortExclusionList []int, inboundPortExclusionList []int, proxyUID int64) []string {
 	var cmd []string
 
 	// 1. Create redirection chains
 	cmd = append(cmd, iptablesRedirectionChains...)
 
 	// 2. Create outbound rules
-	cmd = append(cmd, iptablesOutboundStaticRules...)
+	cmd = append(cmd, getIptablesOutboundStaticRules(proxyUID)...)
 
 	// 3. Create inbound rules
 	cmd = append(cmd, iptablesInboundStaticRules...)
 
 	// 4. Create dynamic outbound exclusion rules
 	for _, cidr := range outboundIPRangeExclusionList {
+		if !isIPv4Range(cidr) {
+			// IPv6 traffic is not redirected by the iptables rules above, so IPv6 ranges are not intercepted already
+			log.Warn().Msgf("Skipping IPv6 range %s excluded from outbound interception: only IPv4 traffic is intercepted, no ip6tables rule is generated", cidr)
+			continue
+		}
+
 		// *Note: it is important to use the insert option '-I' instead of the append option '-A' to ensure the exclusion
 		// rules take precedence over the static redirection rules. Iptables rules are evaluated in order.
 		rule := fmt.Sprintf("iptables -t nat -I PROXY_OUTPUT -d %s -j RETURN", cidr)
 		cmd = append(cmd, rule)
 	}
 
+	// 5. Create dynamic outbound port exclusion rules
+	cmd = append(cmd, getPortExclusionRules("PROXY_OUTPUT", outboundPortExclusionList)...)
+
+	// 6. Create dynamic inbound port exclusion rules
+	cmd = append(cmd, getPortExclusionRules("PROXY_INBOUND", inboundPortExclusionList)...)
+
 	return cmd
 }
+
+// getPortExclusionRules returns the iptables rules returning the TCP traffic to the given destination ports from the
+// given chain before it is redirected, the multiport match accepts a limited number of ports per rule
+func getPortExclusionRules(chain string, portExclusionList []int) []string {
+	var rules []string
+	for start := 0; start < len(portExclusionList); start += maxMultiportPorts {
+		end := start + maxMultiportPorts
+		if end > len(portExclusionList) {
+			end = len(portExclusionList)
+		}
+
+		var ports []string
+		for _, port := range portExclusionList[start:end] {
+			ports = append(ports, strconv.Itoa(port))
+		}
+		rules = append(rules, fmt.Sprintf("iptables -t nat -I %s -p tcp --match multiport --dports %s -j RETURN", chain, strings.Join(ports, ",")))
+	}
+	return rules
+}
+
+// mergeIPRangeExclusionLists returns the IP ranges of the given lists excluded from outbound interception, normalized
+// to their network address in CIDR notation, e.g. 10.0.0.1/8 to 10.0.0.0/8 or 2001:DB8:0::/32 to 2001:db8::/32. Equivalent
+// ranges are listed once, in the order of their first occurrence. Both IPv4 and IPv6 ranges are supported, an error is
+// returned for any range that is not a valid CIDR.
+func mergeIPRangeExclusionLists(exclusionLists ...[]string) ([]string, error) {
+	var merged []string
+	seen := make(map[string]bool)
+	for _, exclusionList := range exclusionLists {
+		for _, ipRange := range exclusionList {
+			_, ipNet, err := net.ParseCIDR(strings.TrimSpace(ipRange))
+			if err != nil {
+				return nil, errors.Errorf("Invalid IP range %q excluded from outbound interception, must be of the form a.b.c.d/x or a:b::c/x: %s", ipRange, err)
+			}
+			normalized := ipNet.String()
+			if seen[normalized] {
+				continue
+			}
+			seen[normalized] = true
+			merged = append(merged, normalized)
+		}
+	}
+	return merged, nil
+}
+
+// isIPv4Range returns whether the given IP range in CIDR notation is an IPv4 range
+func isIPv4Range(cidr string) bool {
+	ip, _, err := net.ParseCIDR(cidr)
+	return err == nil && ip.To4() != nil
+}
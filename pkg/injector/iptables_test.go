@@ -0,0 +1,103 @@
+package injector
+
+import (
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+func TestMergeIPRangeExclusionLists(t *testing.T) {
+	testCases := []struct {
+		name           string
+		exclusionLists [][]string
+		expected       []string
+		expectErr      bool
+	}{
+		{
+			name:           "no exclusions",
+			exclusionLists: [][]string{nil, nil},
+			expected:       nil,
+			expectErr:      false,
+		},
+		{
+			name:           "IPv4 and IPv6 ranges",
+			exclusionLists: [][]string{{"1.1.1.1/32", "2001:db8::/32"}, {"10.0.0.0/8"}},
+			expected:       []string{"1.1.1.1/32", "2001:db8::/32", "10.0.0.0/8"},
+			expectErr:      false,
+		},
+		{
+			name:           "ranges are normalized to their network address",
+			exclusionLists: [][]string{{" 10.0.0.1/8", "2001:DB8:0::1/32 "}},
+			expected:       []string{"10.0.0.0/8", "2001:db8::/32"},
+			expectErr:      false,
+		},
+		{
+			name:           "equivalent ranges are listed once",
+			exclusionLists: [][]string{{"10.0.0.0/8", "2001:db8::/32"}, {"10.1.2.3/8", "2001:db8:0:0::/32", "fd00::/8"}},
+			expected:       []string{"10.0.0.0/8", "2001:db8::/32", "fd00::/8"},
+			expectErr:      false,
+		},
+		{
+			name:           "IPv4 address without a prefix length",
+			exclusionLists: [][]string{{"1.1.1.1"}},
+			expectErr:      true,
+		},
+		{
+			name:           "IPv6 range with an invalid prefix length",
+			exclusionLists: [][]string{{"10.0.0.0/8"}, {"2001:db8::/129"}},
+			expectErr:      true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := tassert.New(t)
+
+			actual, err := mergeIPRangeExclusionLists(tc.exclusionLists...)
+			assert.Equal(tc.expectErr, err != nil)
+			assert.Equal(tc.expected, actual)
+		})
+	}
+}
+
+func TestGenerateIptablesCommandsSkipsIPv6Ranges(t *testing.T) {
+	assert := tassert.New(t)
+
+	cmds := generateIptablesCommands([]string{"10.0.0.0/8", "2001:db8::/32"}, nil, nil, constants.EnvoyUID)
+
+	assert.Contains(cmds, "iptables -t nat -I PROXY_OUTPUT -d 10.0.0.0/8 -j RETURN")
+	for _, cmd := range cmds {
+		assert.NotContains(cmd, "2001:db8::/32")
+	}
+}
+
+func TestGenerateIptablesCommandsExcludesPorts(t *testing.T) {
+	assert := tassert.New(t)
+
+	var ports []int
+	for port := 8000; port < 8017; port++ {
+		ports = append(ports, port)
+	}
+	cmds := generateIptablesCommands(nil, ports, nil, constants.EnvoyUID)
+
+	// The ports are split across rules, the multiport match accepting a limited number of ports per rule
+	assert.Contains(cmds, "iptables -t nat -I PROXY_OUTPUT -p tcp --match multiport --dports 8000,8001,8002,8003,8004,8005,8006,8007,8008,8009,8010,8011,8012,8013,8014 -j RETURN")
+	assert.Contains(cmds, "iptables -t nat -I PROXY_OUTPUT -p tcp --match multiport --dports 8015,8016 -j RETURN")
+
+	for _, cmd := range generateIptablesCommands(nil, nil, nil, constants.EnvoyUID) {
+		assert.NotContains(cmd, "multiport")
+	}
+}
+
+func TestGenerateIptablesCommandsExcludesInboundPorts(t *testing.T) {
+	assert := tassert.New(t)
+
+	cmds := generateIptablesCommands(nil, nil, []int{8081, 9091}, constants.EnvoyUID)
+
+	assert.Contains(cmds, "iptables -t nat -I PROXY_INBOUND -p tcp --match multiport --dports 8081,9091 -j RETURN")
+	for _, cmd := range cmds {
+		assert.NotContains(cmd, "PROXY_OUTPUT -p tcp --match multiport")
+	}
+}
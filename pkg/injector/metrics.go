@@ -15,12 +15,13 @@ func (wh *mutatingWebhook) isMetricsEnabled(namespace string) (enabled bool, err
 		return false, errNamespaceNotFound
 	}
 
-	metrics, ok := ns.Annotations[constants.MetricsAnnotation]
+	annotation := wh.annotation(constants.MetricsAnnotation)
+	metrics, ok := ns.Annotations[annotation]
 	if !ok {
 		return false, nil
 	}
 
-	log.Trace().Msgf("Metrics annotation: '%s:%s'", constants.MetricsAnnotation, metrics)
+	log.Trace().Msgf("Metrics annotation: '%s:%s'", annotation, metrics)
 	metrics = strings.ToLower(metrics)
 	if metrics != "" {
 		switch metrics {
@@ -29,7 +30,7 @@ func (wh *mutatingWebhook) isMetricsEnabled(namespace string) (enabled bool, err
 		case "disabled", "no", "false":
 			enabled = false
 		default:
-			err = errors.Errorf("Invalid value specified for annotation %q: %s", constants.MetricsAnnotation, metrics)
+			err = errors.Errorf("Invalid value specified for annotation %q: %s", annotation, metrics)
 		}
 	}
 	return
@@ -0,0 +1,95 @@
+package injector
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+// getOutboundNamespaceExclusionList returns the IP ranges of the namespaces annotated on the given pod to exclude from
+// outbound traffic interception. The IP ranges are a snapshot of the cluster IPs of the services and of the endpoint
+// addresses in the namespaces at the time of injection.
+func (wh *mutatingWebhook) getOutboundNamespaceExclusionList(pod *corev1.Pod) ([]string, error) {
+	annotation := wh.annotation(constants.OutboundNamespaceExclusionAnnotation)
+	value, ok := pod.Annotations[annotation]
+	if !ok {
+		return nil, nil
+	}
+
+	var exclusionList []string
+	for _, namespace := range strings.Split(value, ",") {
+		namespace = strings.TrimSpace(namespace)
+		if namespace == "" {
+			continue
+		}
+
+		if _, err := wh.kubeClient.CoreV1().Namespaces().Get(context.Background(), namespace, metav1.GetOptions{}); err != nil {
+			return nil, errors.Errorf("Error fetching namespace %s excluded by annotation %s: %s", namespace, annotation, err)
+		}
+		services, err := wh.kubeClient.CoreV1().Services(namespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return nil, errors.Errorf("Error listing services in namespace %s excluded by annotation %s: %s", namespace, annotation, err)
+		}
+		endpoints, err := wh.kubeClient.CoreV1().Endpoints(namespace).List(context.Background(), metav1.ListOptions{})
+		if err != nil {
+			return nil, errors.Errorf("Error listing endpoints in namespace %s excluded by annotation %s: %s", namespace, annotation, err)
+		}
+
+		ipRanges, hasClusterIP := getNamespaceIPRanges(services.Items, endpoints.Items)
+		if !hasClusterIP && wh.config.NamespaceExclusionRequiresClusterIP {
+			return nil, errors.Errorf("Namespace %s excluded by annotation %s has no service with a cluster IP, its endpoint addresses are not stable", namespace, annotation)
+		}
+		log.Debug().Msgf("Excluding IP ranges %v of namespace %s from outbound interception for pod %s/%s", ipRanges, namespace, pod.Namespace, pod.Name)
+		exclusionList = append(exclusionList, ipRanges...)
+	}
+	return exclusionList, nil
+}
+
+// getNamespaceIPRanges returns the sorted IPv4 ranges of the cluster IPs of the given services and of the addresses of
+// the given endpoints, and whether any of the services has a cluster IP
+func getNamespaceIPRanges(services []corev1.Service, endpoints []corev1.Endpoints) ([]string, bool) {
+	ipRanges := make(map[string]struct{})
+	addIPRange := func(address string) {
+		ip := net.ParseIP(address)
+		if ip == nil || ip.To4() == nil {
+			// Not an IPv4 address, e.g. the 'None' cluster IP of a headless service
+			return
+		}
+		ipRanges[fmt.Sprintf("%s/32", ip.To4())] = struct{}{}
+	}
+
+	hasClusterIP := false
+	for _, svc := range services {
+		if svc.Spec.ClusterIP == "" || svc.Spec.ClusterIP == corev1.ClusterIPNone {
+			continue
+		}
+		hasClusterIP = true
+		addIPRange(svc.Spec.ClusterIP)
+	}
+
+	for _, ep := range endpoints {
+		for _, subset := range ep.Subsets {
+			for _, address := range subset.Addresses {
+				addIPRange(address.IP)
+			}
+			for _, address := range subset.NotReadyAddresses {
+				addIPRange(address.IP)
+			}
+		}
+	}
+
+	sortedIPRanges := make([]string, 0, len(ipRanges))
+	for ipRange := range ipRanges {
+		sortedIPRanges = append(sortedIPRanges, ipRange)
+	}
+	sort.Strings(sortedIPRanges)
+	return sortedIPRanges, hasClusterIP
+}
@@ -0,0 +1,158 @@
+package injector
+
+import (
+	"fmt"
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+func newExclusionService(namespace, name, clusterIP string) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Spec:       corev1.ServiceSpec{ClusterIP: clusterIP},
+	}
+}
+
+func newExclusionEndpoints(namespace, name string, ready []string, notReady []string) *corev1.Endpoints {
+	subset := corev1.EndpointSubset{}
+	for _, ip := range ready {
+		subset.Addresses = append(subset.Addresses, corev1.EndpointAddress{IP: ip})
+	}
+	for _, ip := range notReady {
+		subset.NotReadyAddresses = append(subset.NotReadyAddresses, corev1.EndpointAddress{IP: ip})
+	}
+	return &corev1.Endpoints{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Subsets:    []corev1.EndpointSubset{subset},
+	}
+}
+
+func TestGetNamespaceIPRanges(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		services             []corev1.Service
+		endpoints            []corev1.Endpoints
+		expectedIPRanges     []string
+		expectedHasClusterIP bool
+	}{
+		{
+			name:                 "no services or endpoints",
+			expectedIPRanges:     []string{},
+			expectedHasClusterIP: false,
+		},
+		{
+			name: "cluster IPs and endpoint addresses are resolved to sorted and deduplicated IP ranges",
+			services: []corev1.Service{
+				*newExclusionService("ns", "s1", "10.0.0.2"),
+				*newExclusionService("ns", "s2", "10.0.0.1"),
+			},
+			endpoints: []corev1.Endpoints{
+				*newExclusionEndpoints("ns", "s1", []string{"10.1.0.5", "10.1.0.4"}, []string{"10.1.0.6"}),
+				*newExclusionEndpoints("ns", "s2", []string{"10.1.0.4"}, nil),
+			},
+			expectedIPRanges:     []string{"10.0.0.1/32", "10.0.0.2/32", "10.1.0.4/32", "10.1.0.5/32", "10.1.0.6/32"},
+			expectedHasClusterIP: true,
+		},
+		{
+			name: "headless services only resolve endpoint addresses",
+			services: []corev1.Service{
+				*newExclusionService("ns", "headless", corev1.ClusterIPNone),
+				*newExclusionService("ns", "external", ""),
+			},
+			endpoints: []corev1.Endpoints{
+				*newExclusionEndpoints("ns", "headless", []string{"10.1.0.4"}, nil),
+			},
+			expectedIPRanges:     []string{"10.1.0.4/32"},
+			expectedHasClusterIP: false,
+		},
+		{
+			name: "invalid and IPv6 addresses are ignored",
+			services: []corev1.Service{
+				*newExclusionService("ns", "s1", "fd00::1"),
+			},
+			endpoints: []corev1.Endpoints{
+				*newExclusionEndpoints("ns", "s1", []string{"fd00::2", "not-an-ip", "10.1.0.4"}, nil),
+			},
+			expectedIPRanges:     []string{"10.1.0.4/32"},
+			expectedHasClusterIP: true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			ipRanges, hasClusterIP := getNamespaceIPRanges(tc.services, tc.endpoints)
+			assert.Equal(tc.expectedIPRanges, ipRanges)
+			assert.Equal(tc.expectedHasClusterIP, hasClusterIP)
+		})
+	}
+}
+
+func TestGetOutboundNamespaceExclusionList(t *testing.T) {
+	objects := []runtime.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "monitoring"}},
+		newExclusionService("monitoring", "prometheus", "10.0.0.1"),
+		newExclusionEndpoints("monitoring", "prometheus", []string{"10.1.0.1"}, nil),
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "logging"}},
+		newExclusionService("logging", "fluentd", corev1.ClusterIPNone),
+		newExclusionEndpoints("logging", "fluentd", []string{"10.1.0.2"}, nil),
+	}
+
+	testCases := []struct {
+		name               string
+		annotations        map[string]string
+		requireClusterIP   bool
+		expectedExclusions []string
+		expectedErr        bool
+	}{
+		{
+			name:               "pod without the annotation",
+			expectedExclusions: nil,
+		},
+		{
+			name:               "multiple namespaces are resolved",
+			annotations:        map[string]string{constants.OutboundNamespaceExclusionAnnotation: "monitoring, logging"},
+			expectedExclusions: []string{"10.0.0.1/32", "10.1.0.1/32", "10.1.0.2/32"},
+		},
+		{
+			name:        "namespace does not exist",
+			annotations: map[string]string{constants.OutboundNamespaceExclusionAnnotation: "monitoring,unknown"},
+			expectedErr: true,
+		},
+		{
+			name:               "namespace with a cluster IP is allowed when cluster IPs are required",
+			annotations:        map[string]string{constants.OutboundNamespaceExclusionAnnotation: "monitoring"},
+			requireClusterIP:   true,
+			expectedExclusions: []string{"10.0.0.1/32", "10.1.0.1/32"},
+		},
+		{
+			name:             "namespace without a cluster IP is refused when cluster IPs are required",
+			annotations:      map[string]string{constants.OutboundNamespaceExclusionAnnotation: "logging"},
+			requireClusterIP: true,
+			expectedErr:      true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+			wh := &mutatingWebhook{
+				kubeClient: fake.NewSimpleClientset(objects...),
+				config:     Config{NamespaceExclusionRequiresClusterIP: tc.requireClusterIP},
+			}
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "bookstore", Annotations: tc.annotations}}
+
+			exclusions, err := wh.getOutboundNamespaceExclusionList(pod)
+			assert.Equal(tc.expectedErr, err != nil)
+			assert.Equal(tc.expectedExclusions, exclusions)
+		})
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
 
 	"github.com/openservicemesh/osm/pkg/catalog"
+	"github.com/openservicemesh/osm/pkg/configurator"
 	"github.com/openservicemesh/osm/pkg/constants"
 	"github.com/openservicemesh/osm/pkg/metricsstore"
 )
@@ -20,6 +21,73 @@ import (
 func (wh *mutatingWebhook) createPatch(pod *corev1.Pod, req *admissionv1.AdmissionRequest, proxyUUID uuid.UUID) ([]byte, error) {
 	namespace := req.Namespace
 
+	// Validate the annotations and settings of the pod and its namespace before making any out-of-band change for the
+	// pod, such as issuing its bootstrap certificate or creating its bootstrap config Secret, so that an invalid pod is
+	// rejected without leaving anything behind
+	drainTimeout, err := getProxyDrainTimeout(pod, wh.annotation(constants.ProxyDrainTimeoutAnnotation), wh.configurator)
+	if err != nil {
+		log.Error().Err(err).Msgf("Error getting proxy drain timeout for pod with UUID %s in namespace %s", proxyUUID, namespace)
+		return nil, err
+	}
+
+	proxyUID, err := getProxyUID(pod, wh.annotation(constants.ProxyUIDAnnotation), wh.configurator)
+	if err != nil {
+		log.Error().Err(err).Msgf("Error getting proxy UID for pod with UUID %s in namespace %s", proxyUUID, namespace)
+		return nil, err
+	}
+
+	envoyImage, err := getEnvoyImage(pod, wh.annotation(constants.EnvoyImageAnnotation), wh.config.SidecarImage)
+	if err != nil {
+		log.Error().Err(err).Msgf("Error getting Envoy image for pod with UUID %s in namespace %s", proxyUUID, namespace)
+		return nil, err
+	}
+
+	envoyExtraArgs, err := getEnvoyExtraArgs(pod, wh.annotation(constants.EnvoyExtraArgsAnnotation))
+	if err != nil {
+		log.Error().Err(err).Msgf("Error getting Envoy extra args for pod with UUID %s in namespace %s", proxyUUID, namespace)
+		return nil, err
+	}
+
+	envoyLogLevel, err := wh.getEnvoyLogLevel(namespace)
+	if err != nil {
+		log.Error().Err(err).Msgf("Error getting Envoy log level for pod with UUID %s in namespace %s", proxyUUID, namespace)
+		return nil, err
+	}
+
+	// When a CNI plugin sets up the traffic redirection of the pod, the init container is not injected
+	cniEnabled := wh.configurator.GetCNIEnabled()
+
+	// Resolve the IP ranges of the namespaces excluded from outbound interception, and merge them with the IP ranges
+	// excluded mesh-wide
+	var outboundIPRangeExclusionList []string
+	var outboundPortExclusionList []int
+	if !cniEnabled {
+		outboundPortExclusionList, err = getPortExclusionListForPod(pod, wh.annotation(constants.OutboundPortExclusionListAnnotation))
+		if err != nil {
+			log.Error().Err(err).Msgf("Error getting outbound port exclusion list for pod with UUID %s in namespace %s", proxyUUID, namespace)
+			return nil, err
+		}
+
+		namespaceExclusionList, err := wh.getOutboundNamespaceExclusionList(pod)
+		if err != nil {
+			log.Error().Err(err).Msgf("Error getting outbound namespace exclusion list for pod with UUID %s in namespace %s", proxyUUID, namespace)
+			return nil, err
+		}
+		outboundIPRangeExclusionList, err = mergeIPRangeExclusionLists(wh.configurator.GetOutboundIPRangeExclusionList(), namespaceExclusionList)
+		if err != nil {
+			log.Error().Err(err).Msgf("Error merging outbound IP range exclusion lists for pod with UUID %s in namespace %s", proxyUUID, namespace)
+			return nil, err
+		}
+	}
+
+	// Validate the volumes configured for the sidecar proxy against the volumes of the pod
+	envoyConfigPath := wh.configurator.GetEnvoyConfigPath()
+	proxyVolumes, proxyVolumeMounts := wh.configurator.GetProxyVolumes()
+	if err := validateProxyVolumes(pod, proxyVolumes, proxyVolumeMounts, envoyConfigPath); err != nil {
+		log.Error().Err(err).Msgf("Invalid volumes configured for the sidecar proxy of pod with UUID %s in namespace %s", proxyUUID, namespace)
+		return nil, err
+	}
+
 	// Issue a certificate for the proxy sidecar - used for Envoy to connect to XDS (not Envoy-to-Envoy connections)
 	cn := catalog.NewCertCommonNameWithProxyID(proxyUUID, pod.Spec.ServiceAccountName, namespace)
 	log.Debug().Msgf("Patching POD spec: service-account=%s, namespace=%s with certificate CN=%s", pod.Spec.ServiceAccountName, namespace, cn)
@@ -35,9 +103,12 @@ func (wh *mutatingWebhook) createPatch(pod *corev1.Pod, req *admissionv1.Admissi
 	metricsstore.DefaultMetricsStore.CertIssuedTime.
 		WithLabelValues().Observe(elapsed.Seconds())
 	originalHealthProbes := rewriteHealthProbes(pod)
+	if cniEnabled && len(originalHealthProbes.excludedPorts) > 0 {
+		log.Warn().Msgf("Ports %v of health probes of pod with UUID %s in namespace %s can't be excluded from inbound interception when the CNI plugin is enabled, these probes are intercepted by the proxy", originalHealthProbes.excludedPorts, proxyUUID, namespace)
+	}
 
 	// Create the bootstrap configuration for the Envoy proxy for the given pod
-	envoyBootstrapConfigName := fmt.Sprintf("envoy-bootstrap-config-%s", proxyUUID)
+	envoyBootstrapConfigName := constants.EnvoyBootstrapConfigSecretPrefix + proxyUUID.String()
 
 	// The webhook has a side effect (making out-of-band changes) of creating k8s secret
 	// corresponding to the Envoy bootstrap config. Such a side effect needs to be skipped
@@ -51,15 +122,51 @@ func (wh *mutatingWebhook) createPatch(pod *corev1.Pod, req *admissionv1.Admissi
 	}
 
 	// Create volume for envoy TLS secret
-	pod.Spec.Volumes = append(pod.Spec.Volumes, getVolumeSpec(envoyBootstrapConfigName)...)
+	pod.Spec.Volumes = append(pod.Spec.Volumes, getVolumeSpec(envoyBootstrapConfigName, envoyConfigPath)...)
+
+	// Create volume for the CA bundle mounted in the Envoy sidecar, if any
+	caBundle := wh.configurator.GetProxyCABundle()
+	if caBundle != nil {
+		pod.Spec.Volumes = append(pod.Spec.Volumes, getCABundleVolume(caBundle))
+	}
+
+	// Add the volumes configured for the sidecar proxy
+	pod.Spec.Volumes = append(pod.Spec.Volumes, proxyVolumes...)
+
+	// Add the Init Container, unless the CNI plugin sets up the iptables rules redirecting the traffic of the pod
+	if !cniEnabled {
+		initContainer := getInitContainerSpec(wh.configurator.GetInitContainerName(), wh.config.InitContainerImage, outboundIPRangeExclusionList, outboundPortExclusionList, originalHealthProbes.excludedPorts, proxyUID, wh.configurator.IsPrivilegedInitContainer(), wh.configurator.GetProxyImagePullPolicy())
+		pod.Spec.InitContainers = append(pod.Spec.InitContainers, initContainer)
+	}
 
-	// Add the Init Container
-	initContainer := getInitContainerSpec(constants.InitContainerName, wh.config.InitContainerImage, wh.configurator.GetOutboundIPRangeExclusionList(), wh.configurator.IsPrivilegedInitContainer())
-	pod.Spec.InitContainers = append(pod.Spec.InitContainers, initContainer)
+	// Add the Envoy sidecar, draining its connections on pod termination when a drain timeout is set, and delaying
+	// the start of the containers of the pod until it is ready when configured to
+	sidecar := getEnvoySidecarContainerSpec(pod, envoyImage, envoyLogLevel, envoyConfigPath, proxyUID, wh.configurator, originalHealthProbes)
+	sidecar.Args = append(sidecar.Args, envoyExtraArgs...)
+	sidecar.Env = appendProxyEnv(sidecar.Env, wh.configurator.GetProxyEnv())
+	if caBundle != nil {
+		sidecar.VolumeMounts = append(sidecar.VolumeMounts, corev1.VolumeMount{
+			Name:      envoyCABundleVolume,
+			ReadOnly:  true,
+			MountPath: envoyCABundleMountPath,
+		})
+	}
+	sidecar.VolumeMounts = append(sidecar.VolumeMounts, proxyVolumeMounts...)
+	if drainTimeout > 0 {
+		sidecar.Lifecycle = getEnvoyDrainLifecycle(drainTimeout)
+	}
+	addEnvoySidecar(pod, sidecar, wh.configurator.IsWaitForProxyReadyEnabled())
+	if drainTimeout > 0 {
+		ensureTerminationGracePeriod(pod, drainTimeout)
+	}
 
-	// Add the Envoy sidecar
-	sidecar := getEnvoySidecarContainerSpec(pod, wh.config.SidecarImage, wh.configurator, originalHealthProbes)
-	pod.Spec.Containers = append(pod.Spec.Containers, sidecar)
+	// Add the secrets required to pull the images of the injected containers
+	addImagePullSecrets(pod, wh.configurator.GetProxyImagePullSecrets())
+
+	// Add the labels and annotations configured for meshed pods, without overwriting the ones set on the pod.
+	// The labels and annotations managed by OSM added below take precedence over both.
+	pod.Labels = addMissingKeys(pod.Labels, wh.configurator.GetInjectedPodLabels())
+	pod.Annotations = addMissingKeys(pod.Annotations, wh.configurator.GetInjectedPodAnnotations())
 
 	enableMetrics, err := wh.isMetricsEnabled(namespace)
 	if err != nil {
@@ -72,7 +179,7 @@ func (wh *mutatingWebhook) createPatch(pod *corev1.Pod, req *admissionv1.Admissi
 		}
 		pod.Annotations[constants.PrometheusScrapeAnnotation] = strconv.FormatBool(true)
 		pod.Annotations[constants.PrometheusPortAnnotation] = strconv.Itoa(constants.EnvoyPrometheusInboundListenerPort)
-		pod.Annotations[constants.PrometheusPathAnnotation] = constants.PrometheusScrapePath
+		pod.Annotations[constants.PrometheusPathAnnotation] = wh.configurator.GetPrometheusScrapePath()
 	}
 
 	// This will append a label to the pod, which points to the unique Envoy ID used in the
@@ -81,7 +188,11 @@ func (wh *mutatingWebhook) createPatch(pod *corev1.Pod, req *admissionv1.Admissi
 	if pod.Labels == nil {
 		pod.Labels = make(map[string]string)
 	}
-	pod.Labels[constants.EnvoyUniqueIDLabelName] = proxyUUID.String()
+	pod.Labels[wh.proxyUUIDLabel()] = proxyUUID.String()
+
+	// Record the version of the injected Envoy image, so that the proxies can be selected by version, e.g. by the
+	// PodDisruptionBudgets of a canary rollout of a new Envoy image
+	pod.Labels[constants.EnvoyImageVersionLabelName] = getEnvoyImageVersion(envoyImage)
 
 	return json.Marshal(makePatches(req, pod))
 }
@@ -95,3 +206,23 @@ func makePatches(req *admissionv1.AdmissionRequest, pod *corev1.Pod) []jsonpatch
 	admissionResponse := admission.PatchResponseFromRaw(original, current)
 	return admissionResponse.Patches
 }
+
+// getInjectedSidecarReason returns why the given pod is considered to already have the Envoy sidecar,
+// or an empty string if the sidecar has not been injected. The proxy UUID is read from the given label.
+func getInjectedSidecarReason(pod *corev1.Pod, cfg configurator.Configurator, proxyUUIDLabel string) string {
+	for _, container := range pod.Spec.Containers {
+		if container.Name == constants.EnvoyContainerName {
+			return fmt.Sprintf("pod already has a container named %q", constants.EnvoyContainerName)
+		}
+	}
+	if proxyUUID, ok := pod.Labels[proxyUUIDLabel]; ok {
+		return fmt.Sprintf("pod already has the label %s=%s", proxyUUIDLabel, proxyUUID)
+	}
+	initContainerName := cfg.GetInitContainerName()
+	for _, container := range pod.Spec.InitContainers {
+		if container.Name == initContainerName {
+			return fmt.Sprintf("pod already has an init container named %q", initContainerName)
+		}
+	}
+	return ""
+}
@@ -2,21 +2,30 @@ package injector
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
 	mapset "github.com/deckarep/golang-set"
+	jsonpatchapply "github.com/evanphx/json-patch"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
+	"gomodules.xyz/jsonpatch/v2"
 	admissionv1 "k8s.io/api/admission/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
 
 	"github.com/openservicemesh/osm/pkg/certificate/providers/tresor"
 	"github.com/openservicemesh/osm/pkg/configurator"
+	"github.com/openservicemesh/osm/pkg/constants"
+	"github.com/openservicemesh/osm/pkg/envoy"
 	k8s "github.com/openservicemesh/osm/pkg/kubernetes"
 	"github.com/openservicemesh/osm/pkg/tests"
 )
@@ -36,7 +45,7 @@ var _ = Describe("Test all patch operations", func() {
 			mockCtrl := gomock.NewController(GinkgoT())
 			mockConfigurator := configurator.NewMockConfigurator(mockCtrl)
 			mockNsController := k8s.NewMockController(mockCtrl)
-			mockNsController.EXPECT().GetNamespace(namespace).Return(&corev1.Namespace{})
+			mockNsController.EXPECT().GetNamespace(namespace).Return(&corev1.Namespace{}).Times(2)
 			testNamespace := &corev1.Namespace{
 				ObjectMeta: metav1.ObjectMeta{
 					Name: "default",
@@ -58,6 +67,23 @@ var _ = Describe("Test all patch operations", func() {
 			mockConfigurator.EXPECT().GetEnvoyLogLevel().Return("").Times(1)
 			mockConfigurator.EXPECT().IsPrivilegedInitContainer().Return(false).Times(1)
 			mockConfigurator.EXPECT().GetOutboundIPRangeExclusionList().Return(nil).Times(1)
+			mockConfigurator.EXPECT().GetProxyImagePullPolicy().Return(corev1.PullAlways).Times(2)
+			mockConfigurator.EXPECT().GetInitContainerName().Return(constants.InitContainerName).Times(2)
+			mockConfigurator.EXPECT().GetCNIEnabled().Return(false).Times(1)
+			mockConfigurator.EXPECT().GetProxyEnv().Return(nil).Times(1)
+			mockConfigurator.EXPECT().GetProxyServiceNodeTemplate().Return(configurator.DefaultProxyServiceNodeTemplate).Times(1)
+			mockConfigurator.EXPECT().GetProxyServiceClusterTemplate().Return(configurator.DefaultProxyServiceClusterTemplate).Times(1)
+			mockConfigurator.EXPECT().GetInjectedPodLabels().Return(nil).Times(1)
+			mockConfigurator.EXPECT().GetInjectedPodAnnotations().Return(nil).Times(1)
+			mockConfigurator.EXPECT().GetProxyImagePullSecrets().Return(nil).Times(1)
+			mockConfigurator.EXPECT().GetProxyDrainTimeout().Return(time.Duration(0)).Times(1)
+			mockConfigurator.EXPECT().IsWaitForProxyReadyEnabled().Return(false).Times(1)
+			mockConfigurator.EXPECT().GetEnvoyConfigPath().Return(constants.EnvoyConfigPath).Times(1)
+			mockConfigurator.EXPECT().GetProxyUID().Return(constants.EnvoyUID).Times(1)
+			mockConfigurator.EXPECT().GetProxyProbeSettings().Return(configurator.ProxyProbeSettings{}).Times(1)
+			mockConfigurator.EXPECT().GetProxyStatsTagsEnabled().Return(false).Times(1)
+			mockConfigurator.EXPECT().GetProxyCABundle().Return(nil).Times(1)
+			mockConfigurator.EXPECT().GetProxyVolumes().Return(nil, nil).Times(1)
 
 			req := &admissionv1.AdmissionRequest{Namespace: namespace}
 			jsonPatches, err := wh.createPatch(&pod, req, proxyUUID)
@@ -102,4 +128,1086 @@ var _ = Describe("Test all patch operations", func() {
 				fmt.Sprintf("Actual: %s", jsonPatches))
 		})
 	})
+
+	Context("test getInjectedSidecarReason() function", func() {
+		var mockConfigurator *configurator.MockConfigurator
+
+		BeforeEach(func() {
+			mockCtrl := gomock.NewController(GinkgoT())
+			mockConfigurator = configurator.NewMockConfigurator(mockCtrl)
+			mockConfigurator.EXPECT().GetInitContainerName().Return("mesh-init").AnyTimes()
+		})
+
+		It("returns the reason for a pod with an envoy container", func() {
+			pod := tests.NewPodFixture(namespace, podName, tests.BookstoreServiceAccountName, nil)
+			pod.Spec.Containers = []corev1.Container{{Name: "bookstore"}, {Name: constants.EnvoyContainerName}}
+
+			Expect(getInjectedSidecarReason(&pod, mockConfigurator, constants.EnvoyUniqueIDLabelName)).To(Equal(`pod already has a container named "envoy"`))
+		})
+
+		It("returns the reason for a pod with a proxy UUID label", func() {
+			pod := tests.NewPodFixture(namespace, podName, tests.BookstoreServiceAccountName, map[string]string{
+				constants.EnvoyUniqueIDLabelName: proxyUUID.String(),
+			})
+
+			Expect(getInjectedSidecarReason(&pod, mockConfigurator, constants.EnvoyUniqueIDLabelName)).To(Equal(
+				fmt.Sprintf("pod already has the label %s=%s", constants.EnvoyUniqueIDLabelName, proxyUUID)))
+		})
+
+		It("returns the reason for a pod with an init container with the configured name", func() {
+			pod := tests.NewPodFixture(namespace, podName, tests.BookstoreServiceAccountName, nil)
+			pod.Spec.InitContainers = []corev1.Container{{Name: "mesh-init"}}
+
+			Expect(getInjectedSidecarReason(&pod, mockConfigurator, constants.EnvoyUniqueIDLabelName)).To(Equal(`pod already has an init container named "mesh-init"`))
+		})
+
+		It("returns an empty reason for a pod without the sidecar", func() {
+			pod := tests.NewPodFixture(namespace, podName, tests.BookstoreServiceAccountName, nil)
+			pod.Spec.InitContainers = []corev1.Container{{Name: constants.InitContainerName}}
+
+			Expect(getInjectedSidecarReason(&pod, mockConfigurator, constants.EnvoyUniqueIDLabelName)).To(BeEmpty())
+		})
+	})
+
+	Context("test createPatch() patches", func() {
+		var (
+			wh                *mutatingWebhook
+			mockConfigurator  *configurator.MockConfigurator
+			req               *admissionv1.AdmissionRequest
+			pullPolicy        corev1.PullPolicy
+			initContainerName string
+			cniEnabled        bool
+			proxyEnv          []corev1.EnvVar
+			nodeTemplate      string
+			clusterTemplate   string
+			podEnvoyImage     string
+			podLabels         map[string]string
+			podAnnotations    map[string]string
+			pullSecrets       []string
+			podPullSecrets    []corev1.LocalObjectReference
+			drainTimeout      time.Duration
+			waitForProxyReady bool
+			envoyConfigPath   string
+			proxyUID          int64
+			podProxyUID       string
+			probeSettings     configurator.ProxyProbeSettings
+			scrapePath        string
+			statsTagsEnabled  bool
+			caBundle          *configurator.ProxyCABundle
+			proxyVolumes      []corev1.Volume
+			proxyVolumeMounts []corev1.VolumeMount
+			nsAnnotations     map[string]string
+			recorder          *record.FakeRecorder
+		)
+
+		// Each format is expected to emit the operations in the order createPatch mutates the pod
+		expectedOperations := []string{
+			"add /spec/volumes",
+			"add /spec/initContainers",
+			"add /spec/containers/1",
+			"add /metadata/annotations",
+			"add /metadata/labels",
+		}
+
+		newPod := func() corev1.Pod {
+			pod := tests.NewPodFixture(namespace, podName, tests.BookstoreServiceAccountName, nil)
+			pod.Spec.Containers = []corev1.Container{{Name: "bookstore", Image: "bookstore"}}
+			pod.Spec.ImagePullSecrets = podPullSecrets
+			if podEnvoyImage != "" {
+				if pod.Annotations == nil {
+					pod.Annotations = make(map[string]string)
+				}
+				pod.Annotations[constants.EnvoyImageAnnotation] = podEnvoyImage
+			}
+			if podProxyUID != "" {
+				if pod.Annotations == nil {
+					pod.Annotations = make(map[string]string)
+				}
+				pod.Annotations[constants.ProxyUIDAnnotation] = podProxyUID
+			}
+			return pod
+		}
+
+		// createPodPatch creates the patch for a new pod and returns the patch along with the pod mutated by createPatch
+		createPodPatch := func() ([]byte, corev1.Pod) {
+			pod := newPod()
+			raw, err := json.Marshal(pod)
+			Expect(err).ToNot(HaveOccurred())
+			req = &admissionv1.AdmissionRequest{Namespace: namespace, Object: runtime.RawExtension{Raw: raw}}
+
+			patch, err := wh.createPatch(&pod, req, proxyUUID)
+			Expect(err).ToNot(HaveOccurred())
+			return patch, pod
+		}
+
+		operationsOf := func(patch []byte) []string {
+			var patches []jsonpatch.JsonPatchOperation
+			Expect(json.Unmarshal(patch, &patches)).To(Succeed())
+			var operations []string
+			for _, p := range patches {
+				operations = append(operations, fmt.Sprintf("%s %s", p.Operation, p.Path))
+			}
+			return operations
+		}
+
+		applyPatch := func(patch []byte) []byte {
+			decoded, err := jsonpatchapply.DecodePatch(patch)
+			Expect(err).ToNot(HaveOccurred())
+			patched, err := decoded.Apply(req.Object.Raw)
+			Expect(err).ToNot(HaveOccurred())
+			return patched
+		}
+
+		BeforeEach(func() {
+			mockCtrl := gomock.NewController(GinkgoT())
+			mockConfigurator = configurator.NewMockConfigurator(mockCtrl)
+			mockNsController := k8s.NewMockController(mockCtrl)
+			nsAnnotations = map[string]string{constants.MetricsAnnotation: "enabled"}
+			mockNsController.EXPECT().GetNamespace(namespace).DoAndReturn(func(string) *corev1.Namespace {
+				return &corev1.Namespace{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:        namespace,
+						Annotations: nsAnnotations,
+					},
+				}
+			}).AnyTimes()
+			mockNsController.EXPECT().IsMonitoredNamespace(namespace).Return(true).AnyTimes()
+
+			recorder = record.NewFakeRecorder(10)
+			podEnvoyImage = ""
+			podProxyUID = ""
+			wh = &mutatingWebhook{
+				config:              Config{SidecarImage: "envoyproxy/envoy-alpine:v1.17.1"},
+				kubeClient:          fake.NewSimpleClientset(),
+				kubeController:      mockNsController,
+				certManager:         tresor.NewFakeCertManager(mockConfigurator),
+				configurator:        mockConfigurator,
+				eventRecorder:       recorder,
+				nonInjectNamespaces: mapset.NewSet(),
+			}
+
+			mockConfigurator.EXPECT().GetEnvoyLogLevel().Return("error").AnyTimes()
+			mockConfigurator.EXPECT().IsPrivilegedInitContainer().Return(false).AnyTimes()
+			mockConfigurator.EXPECT().GetOutboundIPRangeExclusionList().Return(nil).AnyTimes()
+
+			pullPolicy = corev1.PullAlways
+			mockConfigurator.EXPECT().GetProxyImagePullPolicy().DoAndReturn(func() corev1.PullPolicy {
+				return pullPolicy
+			}).AnyTimes()
+
+			initContainerName = constants.InitContainerName
+			mockConfigurator.EXPECT().GetInitContainerName().DoAndReturn(func() string {
+				return initContainerName
+			}).AnyTimes()
+
+			cniEnabled = false
+			mockConfigurator.EXPECT().GetCNIEnabled().DoAndReturn(func() bool {
+				return cniEnabled
+			}).AnyTimes()
+
+			proxyEnv = nil
+			mockConfigurator.EXPECT().GetProxyEnv().DoAndReturn(func() []corev1.EnvVar {
+				return proxyEnv
+			}).AnyTimes()
+
+			nodeTemplate = configurator.DefaultProxyServiceNodeTemplate
+			mockConfigurator.EXPECT().GetProxyServiceNodeTemplate().DoAndReturn(func() string {
+				return nodeTemplate
+			}).AnyTimes()
+
+			clusterTemplate = configurator.DefaultProxyServiceClusterTemplate
+			mockConfigurator.EXPECT().GetProxyServiceClusterTemplate().DoAndReturn(func() string {
+				return clusterTemplate
+			}).AnyTimes()
+
+			podLabels = nil
+			mockConfigurator.EXPECT().GetInjectedPodLabels().DoAndReturn(func() map[string]string {
+				return podLabels
+			}).AnyTimes()
+
+			podAnnotations = nil
+			mockConfigurator.EXPECT().GetInjectedPodAnnotations().DoAndReturn(func() map[string]string {
+				return podAnnotations
+			}).AnyTimes()
+
+			pullSecrets = nil
+			podPullSecrets = nil
+			mockConfigurator.EXPECT().GetProxyImagePullSecrets().DoAndReturn(func() []string {
+				return pullSecrets
+			}).AnyTimes()
+
+			drainTimeout = 0
+			mockConfigurator.EXPECT().GetProxyDrainTimeout().DoAndReturn(func() time.Duration {
+				return drainTimeout
+			}).AnyTimes()
+
+			waitForProxyReady = false
+			mockConfigurator.EXPECT().IsWaitForProxyReadyEnabled().DoAndReturn(func() bool {
+				return waitForProxyReady
+			}).AnyTimes()
+
+			envoyConfigPath = constants.EnvoyConfigPath
+			mockConfigurator.EXPECT().GetEnvoyConfigPath().DoAndReturn(func() string {
+				return envoyConfigPath
+			}).AnyTimes()
+
+			proxyUID = constants.EnvoyUID
+			mockConfigurator.EXPECT().GetProxyUID().DoAndReturn(func() int64 {
+				return proxyUID
+			}).AnyTimes()
+
+			probeSettings = configurator.ProxyProbeSettings{
+				InitialDelaySeconds: configurator.DefaultProxyProbeInitialDelaySeconds,
+				PeriodSeconds:       configurator.DefaultProxyProbePeriodSeconds,
+				FailureThreshold:    configurator.DefaultProxyProbeFailureThreshold,
+			}
+			mockConfigurator.EXPECT().GetProxyProbeSettings().DoAndReturn(func() configurator.ProxyProbeSettings {
+				return probeSettings
+			}).AnyTimes()
+
+			scrapePath = constants.PrometheusScrapePath
+			mockConfigurator.EXPECT().GetPrometheusScrapePath().DoAndReturn(func() string {
+				return scrapePath
+			}).AnyTimes()
+
+			statsTagsEnabled = false
+			mockConfigurator.EXPECT().GetProxyStatsTagsEnabled().DoAndReturn(func() bool {
+				return statsTagsEnabled
+			}).AnyTimes()
+
+			caBundle = nil
+			mockConfigurator.EXPECT().GetProxyCABundle().DoAndReturn(func() *configurator.ProxyCABundle {
+				return caBundle
+			}).AnyTimes()
+
+			proxyVolumes = nil
+			proxyVolumeMounts = nil
+			mockConfigurator.EXPECT().GetProxyVolumes().DoAndReturn(func() ([]corev1.Volume, []corev1.VolumeMount) {
+				return proxyVolumes, proxyVolumeMounts
+			}).AnyTimes()
+		})
+
+		It("creates a JSON Patch transforming the pod into the mutated pod", func() {
+			patch, pod := createPodPatch()
+			Expect(operationsOf(patch)).To(ConsistOf(expectedOperations))
+
+			mutated, err := json.Marshal(pod)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(applyPatch(patch)).To(MatchJSON(mutated))
+		})
+
+		It("uses the configured image pull policy for the injected containers", func() {
+			pullPolicy = corev1.PullIfNotPresent
+
+			patch, _ := createPodPatch()
+
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+			Expect(patched.Spec.InitContainers).To(HaveLen(1))
+			Expect(patched.Spec.InitContainers[0].ImagePullPolicy).To(Equal(corev1.PullIfNotPresent))
+			Expect(patched.Spec.Containers).To(HaveLen(2))
+			Expect(patched.Spec.Containers[1].Name).To(Equal(constants.EnvoyContainerName))
+			Expect(patched.Spec.Containers[1].ImagePullPolicy).To(Equal(corev1.PullIfNotPresent))
+		})
+
+		It("adds the configured image pull secrets to the pod", func() {
+			pullSecrets = []string{"registry-a", "registry-b"}
+
+			patch, _ := createPodPatch()
+			Expect(operationsOf(patch)).To(ConsistOf([]string{
+				"add /spec/volumes",
+				"add /spec/initContainers",
+				"add /spec/containers/1",
+				"add /spec/imagePullSecrets",
+				"add /metadata/annotations",
+				"add /metadata/labels",
+			}))
+
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+			Expect(patched.Spec.ImagePullSecrets).To(Equal([]corev1.LocalObjectReference{{Name: "registry-a"}, {Name: "registry-b"}}))
+		})
+
+		It("does not duplicate the image pull secrets already referenced by the pod", func() {
+			pullSecrets = []string{"registry-a", "registry-b"}
+			podPullSecrets = []corev1.LocalObjectReference{{Name: "registry-b"}}
+
+			patch, _ := createPodPatch()
+
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+			Expect(patched.Spec.ImagePullSecrets).To(Equal([]corev1.LocalObjectReference{{Name: "registry-b"}, {Name: "registry-a"}}))
+		})
+
+		It("does not patch the image pull secrets when the pod already references all of them", func() {
+			pullSecrets = []string{"registry-a"}
+			podPullSecrets = []corev1.LocalObjectReference{{Name: "registry-a"}}
+
+			patch, _ := createPodPatch()
+			Expect(operationsOf(patch)).To(ConsistOf(expectedOperations))
+		})
+
+		It("uses the configured init container name", func() {
+			initContainerName = "mesh-init"
+
+			patch, _ := createPodPatch()
+			Expect(operationsOf(patch)).To(ConsistOf(expectedOperations))
+
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+			Expect(patched.Spec.InitContainers).To(HaveLen(1))
+			Expect(patched.Spec.InitContainers[0].Name).To(Equal("mesh-init"))
+		})
+
+		It("injects a pod with an init container named after the default when a custom name is configured", func() {
+			initContainerName = "mesh-init"
+			podInitContainers := []corev1.Container{{Name: constants.InitContainerName, Image: "other-mesh-init"}}
+			pod := newPod()
+			pod.Spec.InitContainers = podInitContainers
+			raw, err := json.Marshal(pod)
+			Expect(err).ToNot(HaveOccurred())
+			req = &admissionv1.AdmissionRequest{Namespace: namespace, Object: runtime.RawExtension{Raw: raw}}
+
+			patch, err := wh.createPatch(&pod, req, proxyUUID)
+			Expect(err).ToNot(HaveOccurred())
+
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+			Expect(patched.Spec.InitContainers).To(HaveLen(2))
+			Expect(patched.Spec.InitContainers[0].Name).To(Equal(constants.InitContainerName))
+			Expect(patched.Spec.InitContainers[1].Name).To(Equal("mesh-init"))
+		})
+
+		It("does not add the init container when a CNI plugin redirects the traffic of the pod", func() {
+			cniEnabled = false
+			patch, _ := createPodPatch()
+			var expected corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &expected)).To(Succeed())
+			Expect(expected.Spec.InitContainers).To(HaveLen(1))
+			expected.Spec.InitContainers = nil
+
+			cniEnabled = true
+			patch, _ = createPodPatch()
+			Expect(operationsOf(patch)).To(ConsistOf([]string{
+				"add /spec/volumes",
+				"add /spec/containers/1",
+				"add /metadata/annotations",
+				"add /metadata/labels",
+			}))
+
+			// The rest of the patch is unchanged: the pod gets the same volumes, containers, labels and annotations
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+			Expect(patched).To(Equal(expected))
+		})
+
+		It("appends the configured env vars to the env of the Envoy sidecar", func() {
+			proxyEnv = []corev1.EnvVar{{Name: "ENVOY_UID", Value: "1500"}, {Name: "FEATURE_FLAGS", Value: "a=b"}}
+
+			patch, _ := createPodPatch()
+			Expect(operationsOf(patch)).To(ConsistOf(expectedOperations))
+
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+			Expect(patched.Spec.Containers).To(HaveLen(2))
+			env := patched.Spec.Containers[1].Env
+			Expect(env).To(HaveLen(len(configurator.ReservedProxyEnvNames) + 2))
+			Expect(env[len(env)-2:]).To(Equal(proxyEnv))
+		})
+
+		It("does not override the env vars of the Envoy sidecar managed by OSM", func() {
+			proxyEnv = []corev1.EnvVar{{Name: "POD_NAME", Value: "bookstore"}, {Name: "ENVOY_UID", Value: "1500"}}
+
+			patch, _ := createPodPatch()
+
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+			env := patched.Spec.Containers[1].Env
+			Expect(env).To(HaveLen(len(configurator.ReservedProxyEnvNames) + 1))
+			Expect(env).To(ContainElement(corev1.EnvVar{
+				Name:      "POD_NAME",
+				ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "metadata.name"}},
+			}))
+			Expect(env).ToNot(ContainElement(proxyEnv[0]))
+			Expect(env[len(env)-1]).To(Equal(proxyEnv[1]))
+		})
+
+		It("names the Envoy service node and cluster with the default templates", func() {
+			patch, _ := createPodPatch()
+
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+			args := patched.Spec.Containers[1].Args
+			Expect(args).To(ContainElement(envoy.GetEnvoyServiceNodeID(tests.BookstoreServiceAccountName, "", "")))
+			Expect(args).To(ContainElement(fmt.Sprintf("%s.%s", tests.BookstoreServiceAccountName, namespace)))
+		})
+
+		It("names the Envoy service node and cluster with the configured templates", func() {
+			nodeTemplate = "{{.Namespace}}.{{.ServiceAccount}}"
+			clusterTemplate = "{{.ServiceAccount}}.{{.Namespace}}.svc"
+
+			patch, _ := createPodPatch()
+
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+			args := patched.Spec.Containers[1].Args
+			Expect(args).To(ContainElement(envoy.GetEnvoyServiceNodeID(fmt.Sprintf("%s.%s", namespace, tests.BookstoreServiceAccountName), "", "")))
+			Expect(args).To(ContainElement(fmt.Sprintf("%s.%s.svc", tests.BookstoreServiceAccountName, namespace)))
+		})
+
+		It("falls back to the default names when a configured template renders an invalid name", func() {
+			nodeTemplate = "{{.Namespace}}/{{.ServiceAccount}}"
+
+			patch, _ := createPodPatch()
+
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+			Expect(patched.Spec.Containers[1].Args).To(ContainElement(envoy.GetEnvoyServiceNodeID(tests.BookstoreServiceAccountName, "", "")))
+		})
+
+		It("mounts the bootstrap config at the configured Envoy config path", func() {
+			testCases := []struct {
+				configPath        string
+				expectedMountPath string
+				expectedItems     []corev1.KeyToPath
+			}{
+				{
+					configPath:        constants.EnvoyConfigPath,
+					expectedMountPath: "/etc/envoy",
+					expectedItems:     nil,
+				},
+				{
+					configPath:        "/config/bootstrap.yaml",
+					expectedMountPath: "/config",
+					expectedItems:     nil,
+				},
+				{
+					configPath:        "/etc/envoy/envoy.yaml",
+					expectedMountPath: "/etc/envoy",
+					expectedItems:     []corev1.KeyToPath{{Key: envoyBootstrapConfigFile, Path: "envoy.yaml"}},
+				},
+			}
+
+			for _, tc := range testCases {
+				envoyConfigPath = tc.configPath
+
+				patch, _ := createPodPatch()
+
+				var patched corev1.Pod
+				Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+				sidecar := patched.Spec.Containers[1]
+				Expect(sidecar.Args).To(ContainElements("--config-path", tc.configPath))
+				Expect(sidecar.VolumeMounts).To(Equal([]corev1.VolumeMount{{
+					Name:      envoyBootstrapConfigVolume,
+					ReadOnly:  true,
+					MountPath: tc.expectedMountPath,
+				}}))
+
+				// The volume keeps its name regardless of the config path
+				Expect(patched.Spec.Volumes).To(HaveLen(1))
+				Expect(patched.Spec.Volumes[0].Name).To(Equal(envoyBootstrapConfigVolume))
+				Expect(patched.Spec.Volumes[0].Secret.Items).To(Equal(tc.expectedItems))
+			}
+		})
+
+		It("runs the proxy as the configured UID, unless overridden by the pod annotation", func() {
+			testCases := []struct {
+				configProxyUID   int64
+				podProxyUID      string
+				expectedProxyUID int64
+			}{
+				{
+					configProxyUID:   constants.EnvoyUID,
+					podProxyUID:      "",
+					expectedProxyUID: constants.EnvoyUID,
+				},
+				{
+					configProxyUID:   1000680000,
+					podProxyUID:      "",
+					expectedProxyUID: 1000680000,
+				},
+				{
+					configProxyUID:   1000680000,
+					podProxyUID:      "2500",
+					expectedProxyUID: 2500,
+				},
+			}
+
+			for _, tc := range testCases {
+				proxyUID = tc.configProxyUID
+				podProxyUID = tc.podProxyUID
+
+				patch, _ := createPodPatch()
+
+				var patched corev1.Pod
+				Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+
+				// The traffic of the proxy is not redirected back to itself by the init container
+				Expect(patched.Spec.InitContainers).To(HaveLen(1))
+				Expect(patched.Spec.InitContainers[0].Args[1]).To(ContainSubstring(fmt.Sprintf("--uid-owner %d -j RETURN", tc.expectedProxyUID)))
+
+				Expect(patched.Spec.Containers).To(HaveLen(2))
+				Expect(patched.Spec.Containers[1].SecurityContext.RunAsUser).To(Equal(&tc.expectedProxyUID))
+			}
+		})
+
+		It("mounts the configured CA bundle in the Envoy sidecar", func() {
+			testCases := []struct {
+				caBundle       *configurator.ProxyCABundle
+				expectedVolume *corev1.Volume
+			}{
+				{
+					caBundle:       nil,
+					expectedVolume: nil,
+				},
+				{
+					caBundle: &configurator.ProxyCABundle{Kind: configurator.ProxyCABundleKindSecret, Name: "vault-ca"},
+					expectedVolume: &corev1.Volume{
+						Name:         envoyCABundleVolume,
+						VolumeSource: corev1.VolumeSource{Secret: &corev1.SecretVolumeSource{SecretName: "vault-ca"}},
+					},
+				},
+				{
+					caBundle: &configurator.ProxyCABundle{Kind: configurator.ProxyCABundleKindConfigMap, Name: "vault-ca"},
+					expectedVolume: &corev1.Volume{
+						Name: envoyCABundleVolume,
+						VolumeSource: corev1.VolumeSource{ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{Name: "vault-ca"},
+						}},
+					},
+				},
+			}
+
+			for _, tc := range testCases {
+				caBundle = tc.caBundle
+
+				patch, _ := createPodPatch()
+				Expect(operationsOf(patch)).To(ConsistOf(expectedOperations))
+
+				var patched corev1.Pod
+				Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+				sidecar := patched.Spec.Containers[1]
+				bootstrapMount := corev1.VolumeMount{
+					Name:      envoyBootstrapConfigVolume,
+					ReadOnly:  true,
+					MountPath: "/etc/envoy",
+				}
+
+				// Without a CA bundle, only the bootstrap config is mounted
+				if tc.expectedVolume == nil {
+					Expect(patched.Spec.Volumes).To(HaveLen(1))
+					Expect(sidecar.VolumeMounts).To(Equal([]corev1.VolumeMount{bootstrapMount}))
+					continue
+				}
+
+				Expect(patched.Spec.Volumes).To(HaveLen(2))
+				Expect(patched.Spec.Volumes[0].Name).To(Equal(envoyBootstrapConfigVolume))
+				Expect(patched.Spec.Volumes[1]).To(Equal(*tc.expectedVolume))
+				Expect(sidecar.VolumeMounts).To(Equal([]corev1.VolumeMount{bootstrapMount, {
+					Name:      envoyCABundleVolume,
+					ReadOnly:  true,
+					MountPath: envoyCABundleMountPath,
+				}}))
+			}
+		})
+
+		It("mounts the volumes configured for the sidecar proxy", func() {
+			socketsVolume := corev1.Volume{
+				Name:         "envoy-sockets",
+				VolumeSource: corev1.VolumeSource{EmptyDir: &corev1.EmptyDirVolumeSource{}},
+			}
+			socketsMount := corev1.VolumeMount{Name: "envoy-sockets", MountPath: "/var/run/envoy-sockets"}
+			proxyVolumes = []corev1.Volume{socketsVolume}
+			proxyVolumeMounts = []corev1.VolumeMount{socketsMount}
+
+			patch, _ := createPodPatch()
+			Expect(operationsOf(patch)).To(ConsistOf(expectedOperations))
+
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+
+			// The configured volume is added along with the bootstrap config volume
+			Expect(patched.Spec.Volumes).To(HaveLen(2))
+			Expect(patched.Spec.Volumes[0].Name).To(Equal(envoyBootstrapConfigVolume))
+			Expect(patched.Spec.Volumes[1]).To(Equal(socketsVolume))
+
+			sidecar := patched.Spec.Containers[1]
+			Expect(sidecar.VolumeMounts).To(Equal([]corev1.VolumeMount{
+				{Name: envoyBootstrapConfigVolume, ReadOnly: true, MountPath: "/etc/envoy"},
+				socketsMount,
+			}))
+		})
+
+		It("returns an error when the volumes configured for the sidecar proxy are invalid for the pod", func() {
+			testCases := []struct {
+				volumes      []corev1.Volume
+				volumeMounts []corev1.VolumeMount
+			}{
+				{
+					// The mounted volume is neither in the pod nor configured
+					volumeMounts: []corev1.VolumeMount{{Name: "envoy-sockets", MountPath: "/var/run/envoy-sockets"}},
+				},
+				{
+					// The configured volume collides with the bootstrap config volume
+					volumes: []corev1.Volume{{Name: envoyBootstrapConfigVolume}},
+				},
+				{
+					// The mount path collides with the mount path of the bootstrap config
+					volumes:      []corev1.Volume{{Name: "envoy-sockets"}},
+					volumeMounts: []corev1.VolumeMount{{Name: "envoy-sockets", MountPath: "/etc/envoy/"}},
+				},
+			}
+
+			for _, tc := range testCases {
+				proxyVolumes = tc.volumes
+				proxyVolumeMounts = tc.volumeMounts
+				pod := newPod()
+
+				_, err := wh.createPatch(&pod, &admissionv1.AdmissionRequest{Namespace: namespace}, proxyUUID)
+				Expect(err).To(HaveOccurred())
+				Expect(pod.Spec.Containers).To(HaveLen(1))
+				Expect(pod.Spec.Volumes).To(BeEmpty())
+			}
+		})
+
+		It("excludes the ports listed by the pod annotation from outbound interception", func() {
+			pod := newPod()
+			pod.Annotations = map[string]string{constants.OutboundPortExclusionListAnnotation: " 6379, 7070,6379"}
+			raw, err := json.Marshal(pod)
+			Expect(err).ToNot(HaveOccurred())
+			req = &admissionv1.AdmissionRequest{Namespace: namespace, Object: runtime.RawExtension{Raw: raw}}
+
+			patch, err := wh.createPatch(&pod, req, proxyUUID)
+			Expect(err).ToNot(HaveOccurred())
+
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+			Expect(patched.Spec.InitContainers).To(HaveLen(1))
+			Expect(patched.Spec.InitContainers[0].Args[1]).To(HaveSuffix("iptables -t nat -I PROXY_OUTPUT -p tcp --match multiport --dports 6379,7070 -j RETURN"))
+		})
+
+		It("returns an error naming the offending value when the port exclusion annotation is invalid", func() {
+			for _, value := range []string{"redis", "0", "65536", "6379,70000"} {
+				pod := newPod()
+				pod.Annotations = map[string]string{constants.OutboundPortExclusionListAnnotation: value}
+
+				_, err := wh.createPatch(&pod, &admissionv1.AdmissionRequest{Namespace: namespace}, proxyUUID)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring(constants.OutboundPortExclusionListAnnotation))
+				Expect(pod.Spec.Containers).To(HaveLen(1))
+			}
+		})
+
+		It("returns an error when the port exclusion annotation lists a port reserved for the sidecar proxy", func() {
+			for _, value := range []string{"15000", "6379,15003", "15010"} {
+				pod := newPod()
+				pod.Annotations = map[string]string{constants.OutboundPortExclusionListAnnotation: value}
+
+				_, err := wh.createPatch(&pod, &admissionv1.AdmissionRequest{Namespace: namespace}, proxyUUID)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("reserved for the"))
+				Expect(pod.Spec.Containers).To(HaveLen(1))
+				Expect(pod.Spec.InitContainers).To(BeEmpty())
+			}
+		})
+
+		It("returns an error when the proxy UID annotation is not a valid UID", func() {
+			for _, value := range []string{"envoy", "0", "2147483648"} {
+				pod := newPod()
+				pod.Annotations = map[string]string{constants.ProxyUIDAnnotation: value}
+
+				_, err := wh.createPatch(&pod, &admissionv1.AdmissionRequest{Namespace: namespace}, proxyUUID)
+				Expect(err).To(HaveOccurred())
+				Expect(pod.Spec.Containers).To(HaveLen(1))
+			}
+		})
+
+		It("adds the configured labels and annotations to the pod", func() {
+			podLabels = map[string]string{"team": "payments", "example.com/cost-center": "42"}
+			podAnnotations = map[string]string{"example.com/owner": "payments"}
+
+			patch, _ := createPodPatch()
+			Expect(operationsOf(patch)).To(ConsistOf(expectedOperations))
+
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+			Expect(patched.Labels).To(Equal(map[string]string{
+				"team":                               "payments",
+				"example.com/cost-center":            "42",
+				constants.EnvoyUniqueIDLabelName:     proxyUUID.String(),
+				constants.EnvoyImageVersionLabelName: "v1.17.1",
+			}))
+			Expect(patched.Annotations).To(HaveKeyWithValue("example.com/owner", "payments"))
+			Expect(patched.Annotations).To(HaveKeyWithValue(constants.PrometheusScrapeAnnotation, "true"))
+		})
+
+		It("does not overwrite the labels and annotations set on the pod or managed by OSM", func() {
+			podLabels = map[string]string{
+				"team":                           "payments",
+				"example.com/cost-center":        "42",
+				constants.EnvoyUniqueIDLabelName: "not-the-proxy-uuid",
+			}
+			podAnnotations = map[string]string{
+				"example.com/owner":                  "payments",
+				constants.PrometheusScrapeAnnotation: "false",
+			}
+
+			pod := newPod()
+			pod.Labels = map[string]string{"team": "checkout"}
+			pod.Annotations = map[string]string{"example.com/owner": "checkout"}
+			raw, err := json.Marshal(pod)
+			Expect(err).ToNot(HaveOccurred())
+			req = &admissionv1.AdmissionRequest{Namespace: namespace, Object: runtime.RawExtension{Raw: raw}}
+
+			patch, err := wh.createPatch(&pod, req, proxyUUID)
+			Expect(err).ToNot(HaveOccurred())
+
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+			Expect(patched.Labels).To(Equal(map[string]string{
+				"team":                               "checkout",
+				"example.com/cost-center":            "42",
+				constants.EnvoyUniqueIDLabelName:     proxyUUID.String(),
+				constants.EnvoyImageVersionLabelName: "v1.17.1",
+			}))
+			Expect(patched.Annotations).To(HaveKeyWithValue("example.com/owner", "checkout"))
+			Expect(patched.Annotations).To(HaveKeyWithValue(constants.PrometheusScrapeAnnotation, "true"))
+		})
+
+		It("drains the proxy connections on termination for the configured drain timeout", func() {
+			drainTimeout = 45 * time.Second
+
+			patch, _ := createPodPatch()
+			Expect(operationsOf(patch)).To(ConsistOf([]string{
+				"add /spec/volumes",
+				"add /spec/initContainers",
+				"add /spec/containers/1",
+				"add /spec/terminationGracePeriodSeconds",
+				"add /metadata/annotations",
+				"add /metadata/labels",
+			}))
+
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+			Expect(*patched.Spec.TerminationGracePeriodSeconds).To(Equal(int64(45)))
+			Expect(patched.Spec.Containers[1].Lifecycle).To(Equal(getEnvoyDrainLifecycle(45 * time.Second)))
+		})
+
+		It("injects the Envoy sidecar first with a postStart hook waiting for it to be ready when configured to", func() {
+			waitForProxyReady = true
+			drainTimeout = 45 * time.Second
+
+			patch, _ := createPodPatch()
+
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+			Expect(patched.Spec.Containers).To(HaveLen(2))
+			Expect(patched.Spec.Containers[0].Name).To(Equal(constants.EnvoyContainerName))
+			Expect(patched.Spec.Containers[1].Name).To(Equal("bookstore"))
+
+			lifecycle := patched.Spec.Containers[0].Lifecycle
+			Expect(lifecycle).ToNot(BeNil())
+			Expect(lifecycle.PostStart).To(Equal(getEnvoyReadyPostStartHandler()))
+			Expect(lifecycle.PreStop).To(Equal(getEnvoyDrainLifecycle(45 * time.Second).PreStop))
+		})
+
+		It("sets the configured scrape path on the Prometheus path annotation", func() {
+			scrapePath = "/metrics"
+
+			patch, _ := createPodPatch()
+
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+			Expect(patched.Annotations).To(HaveKeyWithValue(constants.PrometheusPathAnnotation, "/metrics"))
+			Expect(patched.Annotations).To(HaveKeyWithValue(constants.PrometheusPortAnnotation, "15010"))
+		})
+
+		It("adds the stats tags config to the bootstrap config only when stats tags are enabled", func() {
+			secretName := constants.EnvoyBootstrapConfigSecretPrefix + proxyUUID.String()
+			for _, enabled := range []bool{false, true} {
+				statsTagsEnabled = enabled
+				_, _ = createPodPatch()
+
+				secret, err := wh.kubeClient.CoreV1().Secrets(namespace).Get(context.TODO(), secretName, metav1.GetOptions{})
+				Expect(err).ToNot(HaveOccurred())
+				bootstrap := string(secret.Data[envoyBootstrapConfigFile])
+				if enabled {
+					Expect(bootstrap).To(ContainSubstring("stats_config:"))
+					Expect(bootstrap).To(ContainSubstring(envoyRouteConfigStatsTag))
+				} else {
+					Expect(bootstrap).ToNot(ContainSubstring("stats_config:"))
+				}
+			}
+		})
+
+		It("sets the configured timing on the startup and liveness probes of the Envoy sidecar", func() {
+			probeSettings = configurator.ProxyProbeSettings{InitialDelaySeconds: 5, PeriodSeconds: 10, FailureThreshold: 60}
+
+			patch, _ := createPodPatch()
+
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+			Expect(patched.Spec.Containers).To(HaveLen(2))
+			sidecar := patched.Spec.Containers[1]
+			Expect(sidecar.Name).To(Equal(constants.EnvoyContainerName))
+			for _, probe := range []*corev1.Probe{sidecar.StartupProbe, sidecar.LivenessProbe} {
+				Expect(probe).ToNot(BeNil())
+				Expect(probe.InitialDelaySeconds).To(Equal(int32(5)))
+				Expect(probe.PeriodSeconds).To(Equal(int32(10)))
+				Expect(probe.FailureThreshold).To(Equal(int32(60)))
+			}
+			Expect(sidecar.StartupProbe.Exec.Command).To(ContainElement("http://127.0.0.1:15000/ready"))
+			Expect(sidecar.LivenessProbe.Exec.Command).To(ContainElement("http://127.0.0.1:15000/server_info"))
+		})
+
+		It("returns an error when the drain timeout annotation is not a duration", func() {
+			pod := newPod()
+			pod.Annotations = map[string]string{constants.ProxyDrainTimeoutAnnotation: "forever"}
+
+			_, err := wh.createPatch(&pod, &admissionv1.AdmissionRequest{Namespace: namespace}, proxyUUID)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("uses the Envoy image of the mesh when the pod does not override it", func() {
+			patch, _ := createPodPatch()
+
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+			Expect(patched.Spec.Containers).To(HaveLen(2))
+			Expect(patched.Spec.Containers[1].Image).To(Equal("envoyproxy/envoy-alpine:v1.17.1"))
+		})
+
+		It("uses the Envoy image overridden by the pod annotation", func() {
+			podEnvoyImage = "registry.example.com:5000/envoy-canary:v1.18.0-rc1"
+
+			patch, _ := createPodPatch()
+
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+			Expect(patched.Spec.Containers).To(HaveLen(2))
+			Expect(patched.Spec.Containers[1].Name).To(Equal(constants.EnvoyContainerName))
+			Expect(patched.Spec.Containers[1].Image).To(Equal("registry.example.com:5000/envoy-canary:v1.18.0-rc1"))
+			Expect(patched.Labels).To(HaveKeyWithValue(constants.EnvoyImageVersionLabelName, "v1.18.0-rc1"))
+		})
+
+		It("labels the pod with the sanitized digest of the Envoy image pinned by digest", func() {
+			digest := fmt.Sprintf("%064d", 0)
+			podEnvoyImage = "envoyproxy/envoy-alpine:v1.18.3@sha256:" + digest
+
+			patch, _ := createPodPatch()
+
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+			Expect(patched.Labels).To(HaveKeyWithValue(constants.EnvoyImageVersionLabelName, ("sha256-" + digest)[:63]))
+			Expect(validation.IsValidLabelValue(patched.Labels[constants.EnvoyImageVersionLabelName])).To(BeEmpty())
+		})
+
+		It("returns an error when the Envoy image annotation is not a valid image reference", func() {
+			pod := newPod()
+			pod.Annotations = map[string]string{constants.EnvoyImageAnnotation: "Envoy:latest"}
+
+			_, err := wh.createPatch(&pod, &admissionv1.AdmissionRequest{Namespace: namespace}, proxyUUID)
+			Expect(err).To(HaveOccurred())
+			Expect(pod.Spec.Containers).To(HaveLen(1))
+		})
+
+		It("appends the Envoy extra args of the annotation to the args of the sidecar", func() {
+			pod := newPod()
+			pod.Annotations = map[string]string{constants.EnvoyExtraArgsAnnotation: "--drain-strategy immediate"}
+			raw, err := json.Marshal(pod)
+			Expect(err).ToNot(HaveOccurred())
+			req = &admissionv1.AdmissionRequest{Namespace: namespace, Object: runtime.RawExtension{Raw: raw}}
+
+			patch, err := wh.createPatch(&pod, req, proxyUUID)
+			Expect(err).ToNot(HaveOccurred())
+
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+			Expect(patched.Spec.Containers).To(HaveLen(2))
+			args := patched.Spec.Containers[1].Args
+			Expect(args[len(args)-2:]).To(Equal([]string{"--drain-strategy", "immediate"}))
+			Expect(args[:2]).To(Equal([]string{"--log-level", "error"}))
+		})
+
+		It("returns an error when the Envoy extra args annotation overrides a flag managed by OSM", func() {
+			for _, value := range []string{"--config-path /tmp/bootstrap.yaml", "--service-node=bookstore", "--drain-strategy immediate -l trace"} {
+				pod := newPod()
+				pod.Annotations = map[string]string{constants.EnvoyExtraArgsAnnotation: value}
+
+				_, err := wh.createPatch(&pod, &admissionv1.AdmissionRequest{Namespace: namespace}, proxyUUID)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("is managed by OSM"))
+				Expect(pod.Spec.Containers).To(HaveLen(1))
+			}
+		})
+
+		It("uses the Envoy log level of the mesh when the namespace does not override it", func() {
+			patch, _ := createPodPatch()
+
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+			Expect(patched.Spec.Containers).To(HaveLen(2))
+			Expect(patched.Spec.Containers[1].Args[:2]).To(Equal([]string{"--log-level", "error"}))
+		})
+
+		It("uses the Envoy log level overridden by the namespace annotation", func() {
+			nsAnnotations[constants.EnvoyLogLevelAnnotation] = "debug"
+
+			patch, _ := createPodPatch()
+			Expect(operationsOf(patch)).To(ConsistOf(expectedOperations))
+
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(patch), &patched)).To(Succeed())
+			Expect(patched.Spec.Containers).To(HaveLen(2))
+			Expect(patched.Spec.Containers[1].Args[:2]).To(Equal([]string{"--log-level", "debug"}))
+		})
+
+		It("returns an error when the namespace annotation is not a valid Envoy log level", func() {
+			nsAnnotations[constants.EnvoyLogLevelAnnotation] = "verbose"
+			pod := newPod()
+
+			patch, err := wh.createPatch(&pod, &admissionv1.AdmissionRequest{Namespace: namespace}, proxyUUID)
+			Expect(err).To(HaveOccurred())
+			Expect(patch).To(BeNil())
+			Expect(pod.Spec.Containers).To(HaveLen(1))
+		})
+
+		It("uses the proxy UUID requested by the pod annotation", func() {
+			requestedUUID := "0b4f7c3e-5a4e-4a8e-9f4b-2f1d3c6b7a90"
+			pod := newPod()
+			pod.Annotations = map[string]string{
+				constants.SidecarInjectionAnnotation: "enabled",
+				constants.ProxyUUIDAnnotation:        requestedUUID,
+			}
+			raw, err := json.Marshal(pod)
+			Expect(err).ToNot(HaveOccurred())
+			req = &admissionv1.AdmissionRequest{Namespace: namespace, Object: runtime.RawExtension{Raw: raw}}
+
+			resp := wh.mutate(req, uuid.New())
+			Expect(resp.Allowed).To(BeTrue())
+
+			var patched corev1.Pod
+			Expect(json.Unmarshal(applyPatch(resp.Patch), &patched)).To(Succeed())
+			Expect(patched.Spec.Volumes).To(HaveLen(1))
+			Expect(patched.Spec.Volumes[0].Secret.SecretName).To(Equal("envoy-bootstrap-config-" + requestedUUID))
+			Expect(patched.Labels[constants.EnvoyUniqueIDLabelName]).To(Equal(requestedUUID))
+			Expect(recorder.Events).To(Receive(Equal(
+				"Normal SidecarInjected Pod -namespace-/-pod-name- with proxy UUID " + requestedUUID + ": Sidecar injected")))
+		})
+
+		It("responds to a failed injection according to the injector failure policy", func() {
+			// The volumes configured for the sidecar proxy mount a volume that does not exist, failing the injection
+			proxyVolumeMounts = []corev1.VolumeMount{{Name: "envoy-sockets", MountPath: "/var/run/envoy-sockets"}}
+			pod := newPod()
+			pod.Annotations = map[string]string{constants.SidecarInjectionAnnotation: "enabled"}
+			raw, err := json.Marshal(pod)
+			Expect(err).ToNot(HaveOccurred())
+			req = &admissionv1.AdmissionRequest{UID: "test-uid", Namespace: namespace, Object: runtime.RawExtension{Raw: raw}}
+
+			testCases := []struct {
+				failurePolicy   string
+				expectedAllowed bool
+			}{
+				{
+					failurePolicy:   configurator.InjectorFailurePolicyFail,
+					expectedAllowed: false,
+				},
+				{
+					failurePolicy:   configurator.InjectorFailurePolicyIgnore,
+					expectedAllowed: true,
+				},
+			}
+
+			for _, tc := range testCases {
+				mockConfigurator.EXPECT().GetInjectorFailurePolicy().Return(tc.failurePolicy).Times(1)
+
+				resp := wh.mutate(req, uuid.New())
+				Expect(resp.Allowed).To(Equal(tc.expectedAllowed))
+				Expect(resp.Patch).To(BeNil())
+				Expect(resp.PatchType).To(BeNil())
+				Expect(resp.Result.Message).To(ContainSubstring("Volume envoy-sockets mounted in the sidecar proxy"))
+				Expect(recorder.Events).To(Receive(HavePrefix("Warning SidecarInjectionFailed Pod -namespace-/-pod-name- with proxy UUID ")))
+
+				if tc.expectedAllowed {
+					// The pod is admitted unmodified, with a warning returned to the client
+					Expect(resp.UID).To(Equal(req.UID))
+					Expect(resp.Warnings).To(HaveLen(1))
+					Expect(resp.Warnings[0]).To(HavePrefix("Sidecar injection failed, the pod is admitted without a sidecar: "))
+				} else {
+					Expect(resp.Warnings).To(BeEmpty())
+				}
+			}
+		})
+
+		It("rejects a pod requesting an invalid proxy UUID", func() {
+			pod := newPod()
+			pod.Annotations = map[string]string{
+				constants.SidecarInjectionAnnotation: "enabled",
+				constants.ProxyUUIDAnnotation:        "not-a-uuid",
+			}
+			raw, err := json.Marshal(pod)
+			Expect(err).ToNot(HaveOccurred())
+			req = &admissionv1.AdmissionRequest{Namespace: namespace, Object: runtime.RawExtension{Raw: raw}}
+
+			mockConfigurator.EXPECT().GetInjectorFailurePolicy().Return(configurator.InjectorFailurePolicyFail).Times(1)
+			resp := wh.mutate(req, uuid.New())
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(resp.Patch).To(BeNil())
+			Expect(recorder.Events).To(Receive(And(
+				HavePrefix("Warning SidecarInjectionFailed Pod -namespace-/-pod-name- with proxy UUID "),
+				ContainSubstring(`Invalid value "not-a-uuid" for annotation openservicemesh.io/proxy-uuid`),
+			)))
+
+			// The pod is admitted without a sidecar with the 'ignore' injector failure policy
+			mockConfigurator.EXPECT().GetInjectorFailurePolicy().Return(configurator.InjectorFailurePolicyIgnore).Times(1)
+			resp = wh.mutate(req, uuid.New())
+			Expect(resp.Allowed).To(BeTrue())
+			Expect(resp.Patch).To(BeNil())
+			Expect(resp.Warnings).To(HaveLen(1))
+		})
+
+		It("rejects a pod requesting the proxy UUID of an existing pod", func() {
+			requestedUUID := "0b4f7c3e-5a4e-4a8e-9f4b-2f1d3c6b7a90"
+			existing := &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "replica-1",
+					Namespace: namespace,
+					Labels:    map[string]string{constants.EnvoyUniqueIDLabelName: requestedUUID},
+				},
+				Status: corev1.PodStatus{Phase: corev1.PodRunning},
+			}
+			_, err := wh.kubeClient.CoreV1().Pods(namespace).Create(context.Background(), existing, metav1.CreateOptions{})
+			Expect(err).ToNot(HaveOccurred())
+
+			pod := newPod()
+			pod.Annotations = map[string]string{
+				constants.SidecarInjectionAnnotation: "enabled",
+				constants.ProxyUUIDAnnotation:        requestedUUID,
+			}
+			raw, err := json.Marshal(pod)
+			Expect(err).ToNot(HaveOccurred())
+			req = &admissionv1.AdmissionRequest{Namespace: namespace, Object: runtime.RawExtension{Raw: raw}}
+
+			mockConfigurator.EXPECT().GetInjectorFailurePolicy().Return(configurator.InjectorFailurePolicyFail).Times(1)
+			resp := wh.mutate(req, uuid.New())
+			Expect(resp.Allowed).To(BeFalse())
+			Expect(resp.Patch).To(BeNil())
+			Expect(recorder.Events).To(Receive(ContainSubstring("is already used by pod replica-1")))
+
+			// The pod is admitted without a sidecar with the 'ignore' injector failure policy
+			mockConfigurator.EXPECT().GetInjectorFailurePolicy().Return(configurator.InjectorFailurePolicyIgnore).Times(1)
+			resp = wh.mutate(req, uuid.New())
+			Expect(resp.Allowed).To(BeTrue())
+			Expect(resp.Patch).To(BeNil())
+			Expect(recorder.Events).To(Receive(ContainSubstring("is already used by pod replica-1")))
+
+			// The UUID can be requested again once the pod using it has terminated
+			existing.Status.Phase = corev1.PodSucceeded
+			_, err = wh.kubeClient.CoreV1().Pods(namespace).Update(context.Background(), existing, metav1.UpdateOptions{})
+			Expect(err).ToNot(HaveOccurred())
+
+			resp = wh.mutate(req, uuid.New())
+			Expect(resp.Allowed).To(BeTrue())
+		})
+	})
 })
@@ -0,0 +1,18 @@
+package injector
+
+// addMissingKeys adds the given key value pairs to the given labels or annotations of a pod, skipping the keys
+// already set on the pod, and returns the resulting map
+func addMissingKeys(existing map[string]string, keyValues map[string]string) map[string]string {
+	if len(keyValues) == 0 {
+		return existing
+	}
+	if existing == nil {
+		existing = make(map[string]string)
+	}
+	for key, value := range keyValues {
+		if _, ok := existing[key]; !ok {
+			existing[key] = value
+		}
+	}
+	return existing
+}
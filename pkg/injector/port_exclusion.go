@@ -0,0 +1,60 @@
+package injector
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+const (
+	// minPort and maxPort are the bounds of the ports that can be excluded from interception
+	minPort = 1
+	maxPort = 65535
+)
+
+// ReservedProxyPorts are the ports the sidecar proxy listens on, along with their use, which can't be excluded from
+// interception nor be listened on by the containers of a meshed pod without breaking the proxy
+var ReservedProxyPorts = map[int]string{
+	constants.EnvoyAdminPort:                     "admin interface",
+	constants.EnvoyOutboundListenerPort:          "outbound listener",
+	constants.EnvoyInboundListenerPort:           "inbound listener",
+	constants.EnvoyPrometheusInboundListenerPort: "metrics listener",
+}
+
+// getPortExclusionListForPod returns the ports listed in the given comma separated port list annotation of the pod.
+// Whitespace around the ports is ignored and duplicate ports are listed once, in the order of their first occurrence.
+// An error naming the offending value is returned for any port that is not an integer between 1 and 65535, or that is
+// reserved for the sidecar proxy.
+func getPortExclusionListForPod(pod *corev1.Pod, annotation string) ([]int, error) {
+	value, ok := pod.Annotations[annotation]
+	if !ok {
+		return nil, nil
+	}
+
+	var ports []int
+	seen := make(map[int]bool)
+	for _, portStr := range strings.Split(value, ",") {
+		portStr = strings.TrimSpace(portStr)
+		if portStr == "" {
+			continue
+		}
+
+		port, err := strconv.Atoi(portStr)
+		if err != nil || port < minPort || port > maxPort {
+			return nil, errors.Errorf("Invalid port %q in annotation %s, must be an integer between %d and %d", portStr, annotation, minPort, maxPort)
+		}
+		if use, ok := ReservedProxyPorts[port]; ok {
+			return nil, errors.Errorf("Port %d in annotation %s is reserved for the %s of the sidecar proxy, excluding it from interception breaks the proxy", port, annotation, use)
+		}
+		if seen[port] {
+			continue
+		}
+		seen[port] = true
+		ports = append(ports, port)
+	}
+	return ports, nil
+}
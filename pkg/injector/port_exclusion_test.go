@@ -0,0 +1,138 @@
+package injector
+
+import (
+	"fmt"
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+func TestGetPortExclusionListForPod(t *testing.T) {
+	testCases := []struct {
+		name          string
+		annotations   map[string]string
+		expectedPorts []int
+		expectErr     bool
+	}{
+		{
+			name:          "no annotation",
+			annotations:   nil,
+			expectedPorts: nil,
+			expectErr:     false,
+		},
+		{
+			name:          "valid ports",
+			annotations:   map[string]string{constants.OutboundPortExclusionListAnnotation: "6379,7070"},
+			expectedPorts: []int{6379, 7070},
+			expectErr:     false,
+		},
+		{
+			name:          "whitespace-padded ports and empty entries",
+			annotations:   map[string]string{constants.OutboundPortExclusionListAnnotation: " 6379 ,\t7070, ,"},
+			expectedPorts: []int{6379, 7070},
+			expectErr:     false,
+		},
+		{
+			name:          "duplicate ports are listed once",
+			annotations:   map[string]string{constants.OutboundPortExclusionListAnnotation: "7070,6379, 7070,6379"},
+			expectedPorts: []int{7070, 6379},
+			expectErr:     false,
+		},
+		{
+			name:          "bounds of the port range",
+			annotations:   map[string]string{constants.OutboundPortExclusionListAnnotation: "1,65535"},
+			expectedPorts: []int{1, 65535},
+			expectErr:     false,
+		},
+		{
+			name:        "non-numeric port",
+			annotations: map[string]string{constants.OutboundPortExclusionListAnnotation: "6379,redis"},
+			expectErr:   true,
+		},
+		{
+			name:        "port range instead of a port",
+			annotations: map[string]string{constants.OutboundPortExclusionListAnnotation: "6379-6380"},
+			expectErr:   true,
+		},
+		{
+			name:        "port 0 is out of range",
+			annotations: map[string]string{constants.OutboundPortExclusionListAnnotation: "0"},
+			expectErr:   true,
+		},
+		{
+			name:        "port above 65535 is out of range",
+			annotations: map[string]string{constants.OutboundPortExclusionListAnnotation: "6379,65536"},
+			expectErr:   true,
+		},
+		{
+			name:        "Envoy admin port is reserved",
+			annotations: map[string]string{constants.OutboundPortExclusionListAnnotation: "6379,15000"},
+			expectErr:   true,
+		},
+		{
+			name:        "Envoy inbound listener port is reserved",
+			annotations: map[string]string{constants.OutboundPortExclusionListAnnotation: "15003"},
+			expectErr:   true,
+		},
+		{
+			name:        "Envoy metrics listener port is reserved",
+			annotations: map[string]string{constants.OutboundPortExclusionListAnnotation: " 15010 ,7070"},
+			expectErr:   true,
+		},
+		{
+			name:          "ports next to the reserved ports",
+			annotations:   map[string]string{constants.OutboundPortExclusionListAnnotation: "14999,15002,15011"},
+			expectedPorts: []int{14999, 15002, 15011},
+			expectErr:     false,
+		},
+		{
+			name:        "negative port is out of range",
+			annotations: map[string]string{constants.OutboundPortExclusionListAnnotation: "-80"},
+			expectErr:   true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			ports, err := getPortExclusionListForPod(pod, constants.OutboundPortExclusionListAnnotation)
+
+			assert.Equal(tc.expectErr, err != nil)
+			assert.Equal(tc.expectedPorts, ports)
+		})
+	}
+}
+
+func TestGetPortExclusionListForPodErrorNamesValue(t *testing.T) {
+	assert := tassert.New(t)
+
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{constants.OutboundPortExclusionListAnnotation: "6379, 70000 "}}}
+	_, err := getPortExclusionListForPod(pod, constants.OutboundPortExclusionListAnnotation)
+
+	assert.NotNil(err)
+	assert.Contains(err.Error(), `"70000"`)
+	assert.Contains(err.Error(), constants.OutboundPortExclusionListAnnotation)
+}
+
+func TestGetPortExclusionListForPodReservedPorts(t *testing.T) {
+	for port, use := range ReservedProxyPorts {
+		t.Run(fmt.Sprintf("Testing reserved port %d", port), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{constants.OutboundPortExclusionListAnnotation: fmt.Sprintf("6379,%d", port)}}}
+			ports, err := getPortExclusionListForPod(pod, constants.OutboundPortExclusionListAnnotation)
+
+			assert.Nil(ports)
+			assert.NotNil(err)
+			assert.Contains(err.Error(), fmt.Sprintf("Port %d", port))
+			assert.Contains(err.Error(), use)
+			assert.Contains(err.Error(), constants.OutboundPortExclusionListAnnotation)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package injector
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+// getProxyDrainTimeout returns the duration for which the proxy of the given pod drains connections on termination.
+// The pod annotation with the given key overrides the mesh-wide value, a duration of 0 disables draining.
+func getProxyDrainTimeout(pod *corev1.Pod, annotation string, cfg configurator.Configurator) (time.Duration, error) {
+	value, ok := pod.Annotations[annotation]
+	if !ok {
+		return cfg.GetProxyDrainTimeout(), nil
+	}
+
+	drainTimeout, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, errors.Errorf("Invalid value %q for annotation %s, must be a duration: %s", value, annotation, err)
+	}
+	if drainTimeout < 0 {
+		return 0, errors.Errorf("Invalid value %q for annotation %s, must not be negative", value, annotation)
+	}
+	return drainTimeout, nil
+}
+
+// getEnvoyDrainLifecycle returns the lifecycle of the Envoy sidecar, failing its health check and gracefully draining
+// its listeners before waiting for the drain timeout when the pod terminates
+func getEnvoyDrainLifecycle(drainTimeout time.Duration) *corev1.Lifecycle {
+	adminURL := fmt.Sprintf("http://127.0.0.1:%d", constants.EnvoyAdminPort)
+	drainCommand := fmt.Sprintf("wget -q -O /dev/null --post-data '' %s/healthcheck/fail && wget -q -O /dev/null --post-data '' '%s/drain_listeners?graceful' && sleep %d",
+		adminURL, adminURL, durationToSeconds(drainTimeout))
+
+	return &corev1.Lifecycle{
+		PreStop: &corev1.Handler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"/bin/sh", "-c", drainCommand},
+			},
+		},
+	}
+}
+
+// ensureTerminationGracePeriod increases the termination grace period of the pod to the given drain timeout if lower
+func ensureTerminationGracePeriod(pod *corev1.Pod, drainTimeout time.Duration) {
+	gracePeriod := int64(corev1.DefaultTerminationGracePeriodSeconds)
+	if pod.Spec.TerminationGracePeriodSeconds != nil {
+		gracePeriod = *pod.Spec.TerminationGracePeriodSeconds
+	}
+
+	if drainSeconds := durationToSeconds(drainTimeout); gracePeriod < drainSeconds {
+		pod.Spec.TerminationGracePeriodSeconds = &drainSeconds
+	}
+}
+
+// durationToSeconds returns the given duration in seconds, rounded up
+func durationToSeconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
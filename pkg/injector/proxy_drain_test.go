@@ -0,0 +1,130 @@
+package injector
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+func TestGetProxyDrainTimeout(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		annotations          map[string]string
+		configDrainTimeout   time.Duration
+		expectedDrainTimeout time.Duration
+		expectErr            bool
+	}{
+		{
+			name:                 "drain timeout from the configurator without annotation",
+			annotations:          nil,
+			configDrainTimeout:   10 * time.Second,
+			expectedDrainTimeout: 10 * time.Second,
+			expectErr:            false,
+		},
+		{
+			name:                 "drain timeout overridden by the annotation",
+			annotations:          map[string]string{constants.ProxyDrainTimeoutAnnotation: "1m"},
+			expectedDrainTimeout: time.Minute,
+			expectErr:            false,
+		},
+		{
+			name:                 "drain disabled by the annotation",
+			annotations:          map[string]string{constants.ProxyDrainTimeoutAnnotation: "0s"},
+			expectedDrainTimeout: 0,
+			expectErr:            false,
+		},
+		{
+			name:        "annotation is not a duration",
+			annotations: map[string]string{constants.ProxyDrainTimeoutAnnotation: "30"},
+			expectErr:   true,
+		},
+		{
+			name:        "annotation is a negative duration",
+			annotations: map[string]string{constants.ProxyDrainTimeoutAnnotation: "-5s"},
+			expectErr:   true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockConfigurator := configurator.NewMockConfigurator(mockCtrl)
+			mockConfigurator.EXPECT().GetProxyDrainTimeout().Return(tc.configDrainTimeout).AnyTimes()
+
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			drainTimeout, err := getProxyDrainTimeout(pod, constants.ProxyDrainTimeoutAnnotation, mockConfigurator)
+
+			assert.Equal(tc.expectErr, err != nil)
+			assert.Equal(tc.expectedDrainTimeout, drainTimeout)
+		})
+	}
+}
+
+func TestGetEnvoyDrainLifecycle(t *testing.T) {
+	assert := tassert.New(t)
+
+	lifecycle := getEnvoyDrainLifecycle(1500 * time.Millisecond)
+
+	assert.Nil(lifecycle.PostStart)
+	assert.Equal([]string{
+		"/bin/sh", "-c",
+		"wget -q -O /dev/null --post-data '' http://127.0.0.1:15000/healthcheck/fail && wget -q -O /dev/null --post-data '' 'http://127.0.0.1:15000/drain_listeners?graceful' && sleep 2",
+	}, lifecycle.PreStop.Exec.Command)
+}
+
+func TestEnsureTerminationGracePeriod(t *testing.T) {
+	int64Ptr := func(i int64) *int64 { return &i }
+
+	testCases := []struct {
+		name                string
+		gracePeriod         *int64
+		drainTimeout        time.Duration
+		expectedGracePeriod *int64
+	}{
+		{
+			name:                "grace period lower than the drain timeout is increased",
+			gracePeriod:         int64Ptr(10),
+			drainTimeout:        45 * time.Second,
+			expectedGracePeriod: int64Ptr(45),
+		},
+		{
+			name:                "grace period higher than the drain timeout is unchanged",
+			gracePeriod:         int64Ptr(60),
+			drainTimeout:        45 * time.Second,
+			expectedGracePeriod: int64Ptr(60),
+		},
+		{
+			name:                "unset grace period defaults to 30s and is increased",
+			gracePeriod:         nil,
+			drainTimeout:        45 * time.Second,
+			expectedGracePeriod: int64Ptr(45),
+		},
+		{
+			name:                "unset grace period defaults to 30s and is unchanged",
+			gracePeriod:         nil,
+			drainTimeout:        20 * time.Second,
+			expectedGracePeriod: nil,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+
+			pod := &corev1.Pod{Spec: corev1.PodSpec{TerminationGracePeriodSeconds: tc.gracePeriod}}
+			ensureTerminationGracePeriod(pod, tc.drainTimeout)
+
+			assert.Equal(tc.expectedGracePeriod, pod.Spec.TerminationGracePeriodSeconds)
+		})
+	}
+}
@@ -0,0 +1,52 @@
+package injector
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+const (
+	// envoyReadyPath is the path of the Envoy admin endpoint responding with 200 once Envoy is live, i.e. once its
+	// initial xDS configuration is received, and with 503 otherwise
+	envoyReadyPath = "/ready"
+
+	// envoyServerInfoPath is the path of the Envoy admin endpoint responding with 200 as long as the admin server is
+	// responsive, including while Envoy is draining
+	envoyServerInfoPath = "/server_info"
+)
+
+// getEnvoyAdminURL returns the URL of the given path of the Envoy admin server, which only listens on localhost
+func getEnvoyAdminURL(path string) string {
+	return fmt.Sprintf("http://%s:%d%s", constants.LocalhostIPAddress, constants.EnvoyAdminPort, path)
+}
+
+// getEnvoyStartupProbe returns the startup probe of the Envoy sidecar, succeeding once Envoy is live. The kubelet
+// restarts the sidecar if it is not live within the failure threshold of the given settings. Since the Envoy admin
+// server only listens on localhost, the probe is run in the sidecar.
+func getEnvoyStartupProbe(settings configurator.ProxyProbeSettings) *corev1.Probe {
+	return getEnvoyAdminProbe(envoyReadyPath, settings)
+}
+
+// getEnvoyLivenessProbe returns the liveness probe of the Envoy sidecar, failing when the Envoy admin server is no
+// longer responsive. Unlike the startup probe, it succeeds while Envoy is draining its connections on termination.
+func getEnvoyLivenessProbe(settings configurator.ProxyProbeSettings) *corev1.Probe {
+	return getEnvoyAdminProbe(envoyServerInfoPath, settings)
+}
+
+// getEnvoyAdminProbe returns a probe requesting the given path of the Envoy admin server with the given settings
+func getEnvoyAdminProbe(path string, settings configurator.ProxyProbeSettings) *corev1.Probe {
+	return &corev1.Probe{
+		Handler: corev1.Handler{
+			Exec: &corev1.ExecAction{
+				Command: []string{"wget", "-q", "-O", "/dev/null", getEnvoyAdminURL(path)},
+			},
+		},
+		InitialDelaySeconds: settings.InitialDelaySeconds,
+		PeriodSeconds:       settings.PeriodSeconds,
+		FailureThreshold:    settings.FailureThreshold,
+	}
+}
@@ -0,0 +1,51 @@
+package injector
+
+import (
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// envoyReadyTimeout is the duration for which the postStart hook of the Envoy sidecar waits for Envoy to be ready,
+	// after which the hook fails and the sidecar is restarted
+	envoyReadyTimeout = 2 * time.Minute
+
+	// envoyReadyState is the server state reported by the Envoy admin /ready endpoint once its initial xDS
+	// configuration is received
+	envoyReadyState = "LIVE"
+)
+
+// getEnvoyReadyPostStartHandler returns the postStart hook of the Envoy sidecar, waiting for the Envoy admin /ready
+// endpoint to report that Envoy is live. Since the kubelet starts the containers of a pod in order and does not start
+// a container until the postStart hook of the previous one completes, the containers following the sidecar only start
+// once Envoy has received its initial configuration from xDS. Unlike a readiness gate, which only delays the traffic
+// sent to the pod, the hook delays the start of the containers of the pod themselves.
+func getEnvoyReadyPostStartHandler() *corev1.Handler {
+	readyCommand := fmt.Sprintf("for i in $(seq %d); do wget -q -O - %s | grep -q %s && exit 0; sleep 1; done; exit 1",
+		durationToSeconds(envoyReadyTimeout), getEnvoyAdminURL(envoyReadyPath), envoyReadyState)
+
+	return &corev1.Handler{
+		Exec: &corev1.ExecAction{
+			Command: []string{"/bin/sh", "-c", readyCommand},
+		},
+	}
+}
+
+// addEnvoySidecar adds the given Envoy sidecar to the containers of the pod. When the containers of the pod wait for
+// the sidecar to be ready, the sidecar is added first with a postStart hook waiting for Envoy to be live.
+func addEnvoySidecar(pod *corev1.Pod, sidecar corev1.Container, waitForProxyReady bool) {
+	if !waitForProxyReady {
+		pod.Spec.Containers = append(pod.Spec.Containers, sidecar)
+		return
+	}
+
+	var lifecycle corev1.Lifecycle
+	if sidecar.Lifecycle != nil {
+		lifecycle = *sidecar.Lifecycle
+	}
+	lifecycle.PostStart = getEnvoyReadyPostStartHandler()
+	sidecar.Lifecycle = &lifecycle
+	pod.Spec.Containers = append([]corev1.Container{sidecar}, pod.Spec.Containers...)
+}
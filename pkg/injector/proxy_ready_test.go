@@ -0,0 +1,49 @@
+package injector
+
+import (
+	"testing"
+	"time"
+
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+func TestGetEnvoyReadyPostStartHandler(t *testing.T) {
+	assert := tassert.New(t)
+
+	handler := getEnvoyReadyPostStartHandler()
+	assert.NotNil(handler.Exec)
+	assert.Equal([]string{"/bin/sh", "-c",
+		"for i in $(seq 120); do wget -q -O - http://127.0.0.1:15000/ready | grep -q LIVE && exit 0; sleep 1; done; exit 1"}, handler.Exec.Command)
+}
+
+func TestAddEnvoySidecar(t *testing.T) {
+	sidecar := corev1.Container{Name: constants.EnvoyContainerName}
+	drainLifecycle := getEnvoyDrainLifecycle(30 * time.Second)
+
+	t.Run("sidecar appended without postStart hook", func(t *testing.T) {
+		assert := tassert.New(t)
+		pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}}}
+
+		addEnvoySidecar(pod, sidecar, false)
+		assert.Len(pod.Spec.Containers, 2)
+		assert.Equal("app", pod.Spec.Containers[0].Name)
+		assert.Equal(sidecar, pod.Spec.Containers[1])
+	})
+
+	t.Run("sidecar prepended with postStart hook", func(t *testing.T) {
+		assert := tassert.New(t)
+		pod := &corev1.Pod{Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "app"}}}}
+		drainingSidecar := sidecar
+		drainingSidecar.Lifecycle = drainLifecycle
+
+		addEnvoySidecar(pod, drainingSidecar, true)
+		assert.Len(pod.Spec.Containers, 2)
+		assert.Equal(constants.EnvoyContainerName, pod.Spec.Containers[0].Name)
+		assert.Equal("app", pod.Spec.Containers[1].Name)
+		assert.Equal(getEnvoyReadyPostStartHandler(), pod.Spec.Containers[0].Lifecycle.PostStart)
+		assert.Equal(drainLifecycle.PreStop, pod.Spec.Containers[0].Lifecycle.PreStop)
+	})
+}
@@ -0,0 +1,25 @@
+package injector
+
+import (
+	"strconv"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+)
+
+// getProxyUID returns the UID the proxy of the given pod runs as. The pod annotation with the given key overrides the
+// mesh-wide value.
+func getProxyUID(pod *corev1.Pod, annotation string, cfg configurator.Configurator) (int64, error) {
+	value, ok := pod.Annotations[annotation]
+	if !ok {
+		return cfg.GetProxyUID(), nil
+	}
+
+	proxyUID, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || configurator.ValidateProxyUID(proxyUID) != nil {
+		return 0, errors.Errorf("Invalid value %q for annotation %s, must be an integer between %d and %d", value, annotation, configurator.MinProxyUID, configurator.MaxProxyUID)
+	}
+	return proxyUID, nil
+}
@@ -0,0 +1,70 @@
+package injector
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+func TestGetProxyUID(t *testing.T) {
+	testCases := []struct {
+		name             string
+		annotations      map[string]string
+		configProxyUID   int64
+		expectedProxyUID int64
+		expectErr        bool
+	}{
+		{
+			name:             "proxy UID from the configurator without annotation",
+			annotations:      nil,
+			configProxyUID:   constants.EnvoyUID,
+			expectedProxyUID: constants.EnvoyUID,
+			expectErr:        false,
+		},
+		{
+			name:             "proxy UID overridden by the annotation",
+			annotations:      map[string]string{constants.ProxyUIDAnnotation: "1000680000"},
+			configProxyUID:   constants.EnvoyUID,
+			expectedProxyUID: 1000680000,
+			expectErr:        false,
+		},
+		{
+			name:        "annotation is not an integer",
+			annotations: map[string]string{constants.ProxyUIDAnnotation: "envoy"},
+			expectErr:   true,
+		},
+		{
+			name:        "annotation is the root UID",
+			annotations: map[string]string{constants.ProxyUIDAnnotation: "0"},
+			expectErr:   true,
+		},
+		{
+			name:        "annotation is out of range",
+			annotations: map[string]string{constants.ProxyUIDAnnotation: "2147483648"},
+			expectErr:   true,
+		},
+	}
+
+	for i, tc := range testCases {
+		t.Run(fmt.Sprintf("Testing test case %d: %s", i, tc.name), func(t *testing.T) {
+			assert := tassert.New(t)
+			mockCtrl := gomock.NewController(t)
+			defer mockCtrl.Finish()
+			mockConfigurator := configurator.NewMockConfigurator(mockCtrl)
+			mockConfigurator.EXPECT().GetProxyUID().Return(tc.configProxyUID).AnyTimes()
+
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Annotations: tc.annotations}}
+			proxyUID, err := getProxyUID(pod, constants.ProxyUIDAnnotation, mockConfigurator)
+
+			assert.Equal(tc.expectErr, err != nil)
+			assert.Equal(tc.expectedProxyUID, proxyUID)
+		})
+	}
+}
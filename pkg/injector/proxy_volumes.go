@@ -0,0 +1,54 @@
+package injector
+
+import (
+	"path"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// validateProxyVolumes returns an error if the given volumes configured for the sidecar proxy collide with the
+// volumes of the pod or the volumes managed by OSM, or if the given volume mounts collide with the mount paths managed
+// by OSM or reference a volume neither in the pod nor among the given volumes
+func validateProxyVolumes(pod *corev1.Pod, volumes []corev1.Volume, volumeMounts []corev1.VolumeMount, envoyConfigPath string) error {
+	reservedVolumes := map[string]bool{
+		envoyBootstrapConfigVolume: true,
+		envoyCABundleVolume:        true,
+	}
+	reservedMountPaths := map[string]bool{
+		path.Dir(envoyConfigPath): true,
+		envoyCABundleMountPath:    true,
+	}
+
+	podVolumes := make(map[string]bool, len(pod.Spec.Volumes))
+	for _, volume := range pod.Spec.Volumes {
+		podVolumes[volume.Name] = true
+	}
+
+	availableVolumes := make(map[string]bool, len(podVolumes)+len(volumes))
+	for name := range podVolumes {
+		availableVolumes[name] = true
+	}
+	for _, volume := range volumes {
+		if reservedVolumes[volume.Name] {
+			return errors.Errorf("Volume %s configured for the sidecar proxy is managed by OSM", volume.Name)
+		}
+		if podVolumes[volume.Name] {
+			return errors.Errorf("Volume %s configured for the sidecar proxy collides with a volume of the pod", volume.Name)
+		}
+		availableVolumes[volume.Name] = true
+	}
+
+	for _, volumeMount := range volumeMounts {
+		if reservedVolumes[volumeMount.Name] {
+			return errors.Errorf("Volume %s mounted in the sidecar proxy is managed by OSM", volumeMount.Name)
+		}
+		if reservedMountPaths[path.Clean(volumeMount.MountPath)] {
+			return errors.Errorf("Mount path %s of volume %s configured for the sidecar proxy is managed by OSM", volumeMount.MountPath, volumeMount.Name)
+		}
+		if !availableVolumes[volumeMount.Name] {
+			return errors.Errorf("Volume %s mounted in the sidecar proxy at %s is neither a volume of the pod nor a volume configured for the sidecar proxy", volumeMount.Name, volumeMount.MountPath)
+		}
+	}
+	return nil
+}
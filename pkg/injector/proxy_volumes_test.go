@@ -0,0 +1,75 @@
+package injector
+
+import (
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+func TestValidateProxyVolumes(t *testing.T) {
+	pod := &corev1.Pod{
+		Spec: corev1.PodSpec{
+			Volumes: []corev1.Volume{{Name: "app-sockets"}},
+		},
+	}
+
+	testCases := []struct {
+		name         string
+		volumes      []corev1.Volume
+		volumeMounts []corev1.VolumeMount
+		expectErr    bool
+	}{
+		{
+			name:      "no volumes",
+			expectErr: false,
+		},
+		{
+			name:         "mount of a configured volume",
+			volumes:      []corev1.Volume{{Name: "envoy-sockets"}},
+			volumeMounts: []corev1.VolumeMount{{Name: "envoy-sockets", MountPath: "/var/run/envoy-sockets"}},
+			expectErr:    false,
+		},
+		{
+			name:         "mount of a volume of the pod",
+			volumeMounts: []corev1.VolumeMount{{Name: "app-sockets", MountPath: "/var/run/app-sockets"}},
+			expectErr:    false,
+		},
+		{
+			name:         "mount of an unknown volume",
+			volumeMounts: []corev1.VolumeMount{{Name: "envoy-sockets", MountPath: "/var/run/envoy-sockets"}},
+			expectErr:    true,
+		},
+		{
+			name:      "configured volume colliding with a volume of the pod",
+			volumes:   []corev1.Volume{{Name: "app-sockets"}},
+			expectErr: true,
+		},
+		{
+			name:      "configured volume colliding with the CA bundle volume",
+			volumes:   []corev1.Volume{{Name: envoyCABundleVolume}},
+			expectErr: true,
+		},
+		{
+			name:         "mount of the bootstrap config volume",
+			volumeMounts: []corev1.VolumeMount{{Name: envoyBootstrapConfigVolume, MountPath: "/config"}},
+			expectErr:    true,
+		},
+		{
+			name:         "mount path of the CA bundle",
+			volumeMounts: []corev1.VolumeMount{{Name: "app-sockets", MountPath: envoyCABundleMountPath}},
+			expectErr:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := tassert.New(t)
+
+			err := validateProxyVolumes(pod, tc.volumes, tc.volumeMounts, constants.EnvoyConfigPath)
+			assert.Equal(tc.expectErr, err != nil)
+		})
+	}
+}
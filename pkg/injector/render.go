@@ -0,0 +1,106 @@
+package injector
+
+import (
+	"encoding/json"
+
+	mapset "github.com/deckarep/golang-set"
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"github.com/openservicemesh/osm/pkg/certificate/providers/tresor"
+	"github.com/openservicemesh/osm/pkg/configurator"
+	"github.com/openservicemesh/osm/pkg/constants"
+	k8s "github.com/openservicemesh/osm/pkg/kubernetes"
+)
+
+// renderController is the Kubernetes controller of the webhook rendering an injection, which only knows the
+// namespace of the pod. The webhook only looks up namespaces while creating the patch, the other methods of the
+// controller are not implemented.
+type renderController struct {
+	k8s.Controller
+	namespace *corev1.Namespace
+}
+
+// GetNamespace returns the namespace of the pod, or nil for any other namespace
+func (c renderController) GetNamespace(namespace string) *corev1.Namespace {
+	if namespace != c.namespace.Name {
+		return nil
+	}
+	return c.namespace
+}
+
+// IsMonitoredNamespace returns whether the given namespace is the namespace of the pod
+func (c renderController) IsMonitoredNamespace(namespace string) bool {
+	return namespace == c.namespace.Name
+}
+
+// Render returns the JSON patch the sidecar injector creates for the given pod, along with the pod it results in,
+// without access to a cluster. The mesh config is read from the given osm-config ConfigMap of the OSM namespace, and
+// the namespace of the pod holds the annotations overriding the mesh config for its pods. The pod is injected as if
+// its namespace is part of the mesh, regardless of the sidecar injection annotations. The request is handled as a
+// dry-run, so no bootstrap config secret is created, and the bootstrap certificate is issued by a throwaway CA. No
+// informer is started, the mesh config and the namespace are read as given.
+func Render(pod *corev1.Pod, namespace *corev1.Namespace, meshConfig *corev1.ConfigMap, config Config, meshName, osmNamespace string) ([]byte, *corev1.Pod, error) {
+	namespace = namespace.DeepCopy()
+	if namespace.Labels == nil {
+		namespace.Labels = make(map[string]string)
+	}
+	namespace.Labels[constants.OSMKubeResourceMonitorAnnotation] = meshName
+
+	meshConfig = meshConfig.DeepCopy()
+	meshConfig.Namespace = osmNamespace
+	if meshConfig.Name == "" {
+		meshConfig.Name = constants.OSMConfigMap
+	}
+
+	kubeClient := fake.NewSimpleClientset(namespace, meshConfig)
+	cfg := configurator.NewStaticConfigurator(meshConfig)
+
+	wh := &mutatingWebhook{
+		config:              config,
+		kubeClient:          kubeClient,
+		certManager:         tresor.NewFakeCertManager(cfg),
+		kubeController:      renderController{namespace: namespace},
+		osmNamespace:        osmNamespace,
+		meshName:            meshName,
+		configurator:        cfg,
+		nonInjectNamespaces: mapset.NewSet(),
+	}
+
+	pod = pod.DeepCopy()
+	pod.Namespace = namespace.Name
+	raw, err := json.Marshal(pod)
+	if err != nil {
+		return nil, nil, errors.Errorf("Error marshaling pod: %s", err)
+	}
+	dryRun := true
+	req := &admissionv1.AdmissionRequest{
+		Namespace: namespace.Name,
+		Object:    runtime.RawExtension{Raw: raw},
+		DryRun:    &dryRun,
+	}
+
+	// Use the proxy UUID requested by the pod, if any, as the webhook does
+	proxyUUID, err := getRequestedProxyUUID(pod, wh.annotation(constants.ProxyUUIDAnnotation))
+	if err != nil {
+		return nil, nil, err
+	}
+	if proxyUUID == uuid.Nil {
+		proxyUUID = uuid.New()
+	}
+
+	// Leave a pod that already has the sidecar unchanged, as the webhook does
+	if getInjectedSidecarReason(pod, cfg, wh.proxyUUIDLabel()) != "" {
+		return []byte("[]"), pod, nil
+	}
+
+	patch, err := wh.createPatch(pod, req, proxyUUID)
+	if err != nil {
+		return nil, nil, err
+	}
+	return patch, pod, nil
+}
@@ -0,0 +1,117 @@
+package injector
+
+import (
+	"testing"
+
+	tassert "github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/openservicemesh/osm/pkg/constants"
+)
+
+func TestRender(t *testing.T) {
+	const proxyUUID = "0b4f7c3e-5a4e-4a8e-9f4b-2f1d3c6b7a90"
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "bookstore",
+			Annotations: map[string]string{constants.ProxyUUIDAnnotation: proxyUUID},
+		},
+		Spec: corev1.PodSpec{
+			ServiceAccountName: "bookstore",
+			Containers:         []corev1.Container{{Name: "bookstore", Image: "bookstore"}},
+		},
+	}
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "bookstore-ns",
+			Annotations: map[string]string{constants.MetricsAnnotation: "enabled"},
+		},
+	}
+	meshConfig := &corev1.ConfigMap{
+		Data: map[string]string{
+			"init_container_name":              "custom-init",
+			"outbound_ip_range_exclusion_list": "10.0.0.0/8",
+		},
+	}
+	config := Config{SidecarImage: "envoy:test", InitContainerImage: "init:test"}
+
+	testCases := []struct {
+		name         string
+		pod          *corev1.Pod
+		expectInject bool
+	}{
+		{
+			name:         "pod without sidecar",
+			pod:          pod,
+			expectInject: true,
+		},
+		{
+			name: "pod with a sidecar",
+			pod: func() *corev1.Pod {
+				injected := pod.DeepCopy()
+				injected.Spec.Containers = append(injected.Spec.Containers, corev1.Container{Name: constants.EnvoyContainerName})
+				return injected
+			}(),
+			expectInject: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert := tassert.New(t)
+
+			patch, injectedPod, err := Render(tc.pod, namespace, meshConfig, config, "osm", "osm-system")
+			assert.Nil(err)
+			assert.Empty(tc.pod.Labels, "the given pod must not be mutated")
+
+			if !tc.expectInject {
+				assert.JSONEq("[]", string(patch))
+				assert.Equal(tc.pod.Spec.Containers, injectedPod.Spec.Containers)
+				return
+			}
+
+			assert.NotEqual("[]", string(patch))
+			assert.Equal("bookstore-ns", injectedPod.Namespace)
+			assert.Equal(proxyUUID, injectedPod.Labels[constants.EnvoyUniqueIDLabelName])
+			assert.Equal("true", injectedPod.Annotations[constants.PrometheusScrapeAnnotation])
+
+			assert.Len(injectedPod.Spec.Containers, 2)
+			assert.Equal(constants.EnvoyContainerName, injectedPod.Spec.Containers[1].Name)
+			assert.Equal("envoy:test", injectedPod.Spec.Containers[1].Image)
+
+			assert.Len(injectedPod.Spec.InitContainers, 1)
+			assert.Equal("custom-init", injectedPod.Spec.InitContainers[0].Name)
+			assert.Equal("init:test", injectedPod.Spec.InitContainers[0].Image)
+			assert.Contains(injectedPod.Spec.InitContainers[0].Args[len(injectedPod.Spec.InitContainers[0].Args)-1], "-d 10.0.0.0/8 -j RETURN")
+		})
+	}
+}
+
+func TestRenderWithAnnotationPrefix(t *testing.T) {
+	assert := tassert.New(t)
+
+	const proxyUUID = "0b4f7c3e-5a4e-4a8e-9f4b-2f1d3c6b7a90"
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "bookstore",
+			Annotations: map[string]string{"mesh.example.com/proxy-uuid": proxyUUID},
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "bookstore", Image: "bookstore"}}},
+	}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "bookstore"}}
+	config := Config{SidecarImage: "envoy:test", InitContainerImage: "init:test", AnnotationPrefix: "mesh.example.com"}
+
+	_, injectedPod, err := Render(pod, namespace, &corev1.ConfigMap{}, config, "osm", "osm-system")
+	assert.Nil(err)
+	assert.Equal(proxyUUID, injectedPod.Labels["mesh.example.com/osm-proxy-uuid"])
+	assert.NotContains(injectedPod.Labels, constants.EnvoyUniqueIDLabelName)
+
+	// A pod with the prefixed label is considered injected
+	labeledPod := pod.DeepCopy()
+	labeledPod.Labels = map[string]string{"mesh.example.com/osm-proxy-uuid": proxyUUID}
+	patch, _, err := Render(labeledPod, namespace, &corev1.ConfigMap{}, config, "osm", "osm-system")
+	assert.Nil(err)
+	assert.JSONEq("[]", string(patch))
+}
@@ -5,6 +5,7 @@ package injector
 import (
 	mapset "github.com/deckarep/golang-set"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/record"
 
 	"github.com/openservicemesh/osm/pkg/certificate"
 	"github.com/openservicemesh/osm/pkg/configurator"
@@ -14,6 +15,21 @@ import (
 
 const (
 	envoyBootstrapConfigVolume = "envoy-bootstrap-config-volume"
+
+	// envoyCABundleVolume is the name of the volume of the CA bundle mounted in the Envoy sidecar
+	envoyCABundleVolume = "envoy-ca-bundle-volume"
+
+	// envoyCABundleMountPath is the directory the CA bundle is mounted at in the Envoy sidecar
+	envoyCABundleMountPath = "/etc/envoy-ca-bundle"
+
+	// envoyRouteConfigStatsTag is the name of the tag of the Envoy stats holding the route configuration of an HTTP
+	// connection manager, exposed as the envoy_rds_route_config Prometheus label
+	envoyRouteConfigStatsTag = "envoy.rds_route_config"
+
+	// envoyRouteConfigStatsTagRegex extracts the route configuration from the RDS stats of an HTTP connection manager,
+	// e.g. rds-outbound from http.mesh-http-conn-manager.rds-outbound.rds.rds-outbound.update_success. The first capture
+	// group is removed from the stat name and the second one is the value of the tag.
+	envoyRouteConfigStatsTagRegex = `^http\..*\.rds\.(([^.]+)\.)\w+$`
 )
 
 var log = logger.New("sidecar-injector")
@@ -28,6 +44,7 @@ type mutatingWebhook struct {
 	meshName       string
 	cert           certificate.Certificater
 	configurator   configurator.Configurator
+	eventRecorder  record.EventRecorder
 
 	nonInjectNamespaces mapset.Set
 }
@@ -40,6 +57,15 @@ type Config struct {
 	InitContainerImage string
 
 	SidecarImage string
+
+	// NamespaceExclusionRequiresClusterIP refuses to exclude the outbound traffic to a namespace without a service
+	// having a cluster IP, whose endpoint addresses are not stable
+	NamespaceExclusionRequiresClusterIP bool
+
+	// AnnotationPrefix is the prefix of the keys of the OSM annotations read by the sidecar injector in place of
+	// openservicemesh.io, e.g. to tell apart the annotations of several meshes. The default prefix is used if empty.
+	// It also prefixes the proxy UUID label of the injected pods, but not the annotations written by the injector.
+	AnnotationPrefix string
 }
 
 // Context needed to compose the Envoy bootstrap YAML.
@@ -57,4 +83,7 @@ type envoyBootstrapConfigMeta struct {
 	// The bootstrap Envoy config will be affected by the liveness, readiness, startup probes set on
 	// the pod this Envoy is fronting.
 	OriginalHealthProbes healthProbes
+
+	// Whether the cluster and route dimensions of the Envoy stats are extracted as tags
+	StatsTagsEnabled bool
 }
@@ -1,19 +1,48 @@
 package injector
 
 import (
+	"path"
+
 	corev1 "k8s.io/api/core/v1"
+
+	"github.com/openservicemesh/osm/pkg/configurator"
 )
 
-// getVolumeSpec returns a list of volumes to add to the POD
-func getVolumeSpec(envoyBootstrapConfigName string) []corev1.Volume {
+// getVolumeSpec returns a list of volumes to add to the POD. The bootstrap config is projected to the file name of the
+// given Envoy config path when it differs from the key of the bootstrap config in the secret.
+func getVolumeSpec(envoyBootstrapConfigName, envoyConfigPath string) []corev1.Volume {
+	secretVolumeSource := &corev1.SecretVolumeSource{
+		SecretName: envoyBootstrapConfigName,
+	}
+	if configFile := path.Base(envoyConfigPath); configFile != envoyBootstrapConfigFile {
+		secretVolumeSource.Items = []corev1.KeyToPath{{
+			Key:  envoyBootstrapConfigFile,
+			Path: configFile,
+		}}
+	}
+
 	return []corev1.Volume{
 		{
 			Name: envoyBootstrapConfigVolume,
 			VolumeSource: corev1.VolumeSource{
-				Secret: &corev1.SecretVolumeSource{
-					SecretName: envoyBootstrapConfigName,
-				},
+				Secret: secretVolumeSource,
 			},
 		},
 	}
 }
+
+// getCABundleVolume returns the volume of the CA bundle held in the given secret or ConfigMap, mounted in the Envoy
+// sidecar at envoyCABundleMountPath
+func getCABundleVolume(caBundle *configurator.ProxyCABundle) corev1.Volume {
+	volume := corev1.Volume{Name: envoyCABundleVolume}
+	if caBundle.Kind == configurator.ProxyCABundleKindConfigMap {
+		volume.ConfigMap = &corev1.ConfigMapVolumeSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: caBundle.Name},
+		}
+	} else {
+		volume.Secret = &corev1.SecretVolumeSource{
+			SecretName: caBundle.Name,
+		}
+	}
+	return volume
+}
@@ -5,17 +5,36 @@ import (
 	. "github.com/onsi/gomega"
 
 	v1 "k8s.io/api/core/v1"
+
+	"github.com/openservicemesh/osm/pkg/constants"
 )
 
 var _ = Describe("Test volume functions", func() {
 	Context("Test getVolumeSpec", func() {
 		It("creates volume spec", func() {
-			actual := getVolumeSpec("-envoy-config-")
+			actual := getVolumeSpec("-envoy-config-", constants.EnvoyConfigPath)
+			expected := []v1.Volume{{
+				Name: "envoy-bootstrap-config-volume",
+				VolumeSource: v1.VolumeSource{
+					Secret: &v1.SecretVolumeSource{
+						SecretName: "-envoy-config-",
+					},
+				},
+			}}
+			Expect(actual).To(Equal(expected))
+		})
+
+		It("projects the bootstrap config to the file name of the Envoy config path", func() {
+			actual := getVolumeSpec("-envoy-config-", "/config/envoy.yaml")
 			expected := []v1.Volume{{
 				Name: "envoy-bootstrap-config-volume",
 				VolumeSource: v1.VolumeSource{
 					Secret: &v1.SecretVolumeSource{
 						SecretName: "-envoy-config-",
+						Items: []v1.KeyToPath{{
+							Key:  "bootstrap.yaml",
+							Path: "envoy.yaml",
+						}},
 					},
 				},
 			}}
@@ -16,6 +16,7 @@ import (
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 	"k8s.io/apimachinery/pkg/types"
@@ -83,6 +84,7 @@ func NewMutatingWebhook(config Config, kubeClient kubernetes.Interface, certMana
 		meshName:       meshName,
 		cert:           webhookHandlerCert,
 		configurator:   cfg,
+		eventRecorder:  newEventRecorder(kubeClient),
 
 		// Envoy sidecars should never be injected in these namespaces
 		nonInjectNamespaces: mapset.NewSetFromSlice([]interface{}{
@@ -114,6 +116,8 @@ func (wh *mutatingWebhook) run(stop <-chan struct{}) {
 	// because of the specifics of MutatingWebhookConfiguration template in this repository.
 	mux.HandleFunc(webhookCreatePod, wh.podCreationHandler)
 
+	mux.HandleFunc(constants.InjectorBootstrapRotationPath, wh.bootstrapRotationHandler)
+
 	server := &http.Server{
 		Addr:    fmt.Sprintf(":%d", wh.config.ListenPort),
 		Handler: mux,
@@ -231,6 +235,7 @@ func (wh *mutatingWebhook) mutate(req *admissionv1.AdmissionRequest, proxyUUID u
 	var pod corev1.Pod
 	if err := json.Unmarshal(req.Object.Raw, &pod); err != nil {
 		log.Error().Err(err).Msgf("Error unmarshaling request to pod with UUID %s in namespace %s", proxyUUID, req.Namespace)
+		wh.recordInjectionEvent(&pod, req.Namespace, proxyUUID, corev1.EventTypeWarning, eventReasonSidecarInjectionFailed, fmt.Sprintf("Error decoding pod: %s", err))
 		return webhook.AdmissionError(err)
 	}
 
@@ -241,25 +246,115 @@ func (wh *mutatingWebhook) mutate(req *admissionv1.AdmissionRequest, proxyUUID u
 	}
 
 	// Check if we must inject the sidecar
-	if inject, err := wh.mustInject(&pod, req.Namespace); err != nil {
+	if inject, skipReason, err := wh.mustInject(&pod, req.Namespace); err != nil {
 		log.Error().Err(err).Msgf("Error checking if sidecar must be injected for pod with UUID %s in namespace %s", proxyUUID, req.Namespace)
-		return webhook.AdmissionError(err)
+		wh.recordInjectionEvent(&pod, req.Namespace, proxyUUID, corev1.EventTypeWarning, eventReasonSidecarInjectionFailed, fmt.Sprintf("Error checking if the sidecar must be injected: %s", err))
+		return wh.injectionFailureResponse(req, err)
 	} else if !inject {
-		log.Trace().Msgf("Skipping sidecar injection for pod with UUID %s in namespace %s", proxyUUID, req.Namespace)
+		log.Trace().Msgf("Skipping sidecar injection for pod with UUID %s in namespace %s: %s", proxyUUID, req.Namespace, skipReason)
+		wh.recordInjectionEvent(&pod, req.Namespace, proxyUUID, corev1.EventTypeNormal, eventReasonSidecarInjectionSkipped, fmt.Sprintf("Sidecar injection skipped, %s", skipReason))
+		return resp
+	}
+
+	// Check if the sidecar has already been injected
+	if reason := getInjectedSidecarReason(&pod, wh.configurator, wh.proxyUUIDLabel()); reason != "" {
+		log.Info().Msgf("Skipping sidecar injection for pod with UUID %s in namespace %s: %s", proxyUUID, req.Namespace, reason)
+		wh.recordInjectionEvent(&pod, req.Namespace, proxyUUID, corev1.EventTypeNormal, eventReasonSidecarInjectionSkipped, fmt.Sprintf("Sidecar injection skipped, %s", reason))
+		resp.Result = &metav1.Status{Message: fmt.Sprintf("Sidecar injection skipped, %s", reason)}
 		return resp
 	}
 
+	// Use the proxy UUID requested by the pod, if any, in place of the generated one
+	requestedUUID, err := getRequestedProxyUUID(&pod, wh.annotation(constants.ProxyUUIDAnnotation))
+	if err != nil {
+		log.Error().Err(err).Msgf("Invalid proxy UUID requested for pod with UUID %s in namespace %s", proxyUUID, req.Namespace)
+		wh.recordInjectionEvent(&pod, req.Namespace, proxyUUID, corev1.EventTypeWarning, eventReasonSidecarInjectionFailed, err.Error())
+		return wh.injectionFailureResponse(req, err)
+	}
+	if requestedUUID != uuid.Nil {
+		// A proxy UUID in use is handled as any other injection failure: with the 'ignore' policy, the pod is admitted
+		// without a sidecar, so two proxies never share the same UUID
+		if err := wh.checkProxyUUIDNotInUse(req.Namespace, requestedUUID); err != nil {
+			log.Error().Err(err).Msgf("Proxy UUID requested for pod with UUID %s in namespace %s can't be used", proxyUUID, req.Namespace)
+			wh.recordInjectionEvent(&pod, req.Namespace, proxyUUID, corev1.EventTypeWarning, eventReasonSidecarInjectionFailed, err.Error())
+			return wh.injectionFailureResponse(req, err)
+		}
+		log.Debug().Msgf("Using proxy UUID %s requested by annotation %s in place of %s for pod in namespace %s", requestedUUID, wh.annotation(constants.ProxyUUIDAnnotation), proxyUUID, req.Namespace)
+		proxyUUID = requestedUUID
+	}
+
 	patchBytes, err := wh.createPatch(&pod, req, proxyUUID)
 	if err != nil {
 		log.Error().Err(err).Msgf("Failed to create patch for pod with UUID %s in namespace %s", proxyUUID, req.Namespace)
-		return webhook.AdmissionError(err)
+		wh.recordInjectionEvent(&pod, req.Namespace, proxyUUID, corev1.EventTypeWarning, eventReasonSidecarInjectionFailed, fmt.Sprintf("Error injecting the sidecar: %s", err))
+		return wh.injectionFailureResponse(req, err)
 	}
 
 	patchAdmissionResponse(resp, patchBytes)
+	wh.recordInjectionEvent(&pod, req.Namespace, proxyUUID, corev1.EventTypeNormal, eventReasonSidecarInjected, "Sidecar injected")
 	log.Trace().Msgf("Done creating patch admission response for pod with UUID %s in namespace %s", proxyUUID, req.Namespace)
 	return resp
 }
 
+// injectionFailureResponse returns the response to the given request when the sidecar injector fails to inject the pod
+// with the given error, according to the injector failure policy: the pod is denied with the 'fail' policy, or
+// admitted unmodified with a warning with the 'ignore' policy
+func (wh *mutatingWebhook) injectionFailureResponse(req *admissionv1.AdmissionRequest, err error) *admissionv1.AdmissionResponse {
+	if wh.configurator.GetInjectorFailurePolicy() != configurator.InjectorFailurePolicyIgnore {
+		return webhook.AdmissionError(err)
+	}
+
+	message := fmt.Sprintf("Sidecar injection failed, the pod is admitted without a sidecar: %s", err)
+	log.Warn().Msgf("%s, as the injector failure policy is %s", message, configurator.InjectorFailurePolicyIgnore)
+	return &admissionv1.AdmissionResponse{
+		Allowed:  true,
+		UID:      req.UID,
+		Result:   &metav1.Status{Message: message},
+		Warnings: []string{message},
+	}
+}
+
+// getRequestedProxyUUID returns the proxy UUID requested by the annotation of the given pod with the given key, or
+// uuid.Nil if the pod does not request one. An error is returned if the annotation is not a valid UUID.
+func getRequestedProxyUUID(pod *corev1.Pod, annotation string) (uuid.UUID, error) {
+	value, ok := pod.Annotations[annotation]
+	if !ok {
+		return uuid.Nil, nil
+	}
+
+	requestedUUID, err := uuid.Parse(value)
+	if err != nil {
+		return uuid.Nil, errors.Errorf("Invalid value %q for annotation %s, must be a UUID: %s", value, annotation, err)
+	}
+	if requestedUUID == uuid.Nil {
+		return uuid.Nil, errors.Errorf("Invalid value %q for annotation %s, must not be the nil UUID", value, annotation)
+	}
+	return requestedUUID, nil
+}
+
+// checkProxyUUIDNotInUse returns an error if a pod of the given namespace, that is neither terminated nor being deleted,
+// already has a sidecar proxy with the given UUID. The UUID names the bootstrap config Secret of the proxy, so pods
+// sharing a requested UUID, e.g. the replicas of a Deployment whose template is annotated, would overwrite each other's
+// bootstrap config.
+func (wh *mutatingWebhook) checkProxyUUIDNotInUse(namespace string, proxyUUID uuid.UUID) error {
+	listOptions := metav1.ListOptions{
+		LabelSelector: labels.Set(map[string]string{wh.proxyUUIDLabel(): proxyUUID.String()}).String(),
+	}
+	pods, err := wh.kubeClient.CoreV1().Pods(namespace).List(context.Background(), listOptions)
+	if err != nil {
+		return errors.Errorf("Error listing pods with proxy UUID %s in namespace %s: %s", proxyUUID, namespace, err)
+	}
+
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil || pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		return errors.Errorf("Proxy UUID %s requested by annotation %s is already used by pod %s in namespace %s, the annotation is meant for bare pods only",
+			proxyUUID, wh.annotation(constants.ProxyUUIDAnnotation), pod.Name, namespace)
+	}
+	return nil
+}
+
 func (wh *mutatingWebhook) isNamespaceInjectable(namespace string) bool {
 	// Never inject pods in the OSM Controller namespace or kube-public or kube-system
 	isInjectableNS := !wh.nonInjectNamespaces.Contains(namespace)
@@ -274,50 +369,54 @@ func (wh *mutatingWebhook) isNamespaceInjectable(namespace string) bool {
 // 1. The pod is explicitly annotated with enabled/yes/true for sidecar injection, or
 // 2. The namespace is annotated for sidecar injection and the pod is not explicitly annotated with disabled/no/false
 //
-// The function returns an error when it is unable to determine whether to perform sidecar injection.
-func (wh *mutatingWebhook) mustInject(pod *corev1.Pod, namespace string) (bool, error) {
+// The function returns why the sidecar must not be injected when it returns false, and an error when it is unable to
+// determine whether to perform sidecar injection.
+func (wh *mutatingWebhook) mustInject(pod *corev1.Pod, namespace string) (bool, string, error) {
 	if !wh.isNamespaceInjectable(namespace) {
 		log.Warn().Msgf("Mutation request is for pod with UID %s; Injection in Namespace %s is not permitted", pod.ObjectMeta.UID, namespace)
-		return false, nil
+		return false, fmt.Sprintf("namespace %s is not part of the mesh", namespace), nil
 	}
 
 	// Check if the pod is annotated for injection
-	podInjectAnnotationExists, podInject, err := isAnnotatedForInjection(pod.Annotations, pod.Kind, fmt.Sprintf("%s/%s", pod.Namespace, pod.Name))
+	annotation := wh.annotation(constants.SidecarInjectionAnnotation)
+	podInjectAnnotationExists, podInject, err := isAnnotatedForInjection(pod.Annotations, annotation, pod.Kind, fmt.Sprintf("%s/%s", pod.Namespace, pod.Name))
 	if err != nil {
 		log.Error().Err(err).Msg("Error determining if the pod is enabled for sidecar injection")
-		return false, err
+		return false, "", err
 	}
 
 	// Check if the namespace is annotated for injection
 	ns := wh.kubeController.GetNamespace(namespace)
 	if ns == nil {
 		log.Error().Err(errNamespaceNotFound).Msgf("Error retrieving namespace %s", namespace)
-		return false, err
+		return false, "", err
 	}
-	nsInjectAnnotationExists, nsInject, err := isAnnotatedForInjection(ns.Annotations, ns.Kind, ns.Name)
+	nsInjectAnnotationExists, nsInject, err := isAnnotatedForInjection(ns.Annotations, annotation, ns.Kind, ns.Name)
 	if err != nil {
 		log.Error().Err(err).Msgf("Error determining if namespace %s is enabled for sidecar injection", namespace)
-		return false, err
+		return false, "", err
 	}
 
-	if podInjectAnnotationExists && podInject {
-		// Pod is explicitly annotated to enable sidecar injection
-		return true, nil
-	} else if nsInjectAnnotationExists && nsInject {
-		// Namespace is annotated to enable sidecar injection
-		if !podInjectAnnotationExists || podInject {
-			// If pod annotation doesn't exist or if an annotation exists to enable injection, enable it
-			return true, nil
+	// The pod annotation takes precedence over the namespace annotation, so that a single pod can opt in or out of
+	// sidecar injection regardless of its namespace
+	if podInjectAnnotationExists {
+		if podInject {
+			return true, "", nil
 		}
+		return false, fmt.Sprintf("sidecar injection is disabled for the pod by the annotation %s", annotation), nil
+	}
+	if nsInjectAnnotationExists && nsInject {
+		// Namespace is annotated to enable sidecar injection and the pod does not override it
+		return true, "", nil
 	}
 
 	// Conditions to inject the sidecar are not met
-	return false, nil
+	return false, "sidecar injection is not enabled for the pod or its namespace", nil
 }
 
-func isAnnotatedForInjection(annotations map[string]string, objectKind string, objectName string) (exists bool, enabled bool, err error) {
-	inject := strings.ToLower(annotations[constants.SidecarInjectionAnnotation])
-	log.Trace().Msgf("%s %s has sidecar injection annotation: '%s:%s'", objectKind, objectName, constants.SidecarInjectionAnnotation, inject)
+func isAnnotatedForInjection(annotations map[string]string, annotation string, objectKind string, objectName string) (exists bool, enabled bool, err error) {
+	inject := strings.ToLower(annotations[annotation])
+	log.Trace().Msgf("%s %s has sidecar injection annotation: '%s:%s'", objectKind, objectName, annotation, inject)
 	if inject != "" {
 		exists = true
 		switch inject {
@@ -326,7 +425,7 @@ func isAnnotatedForInjection(annotations map[string]string, objectKind string, o
 		case "disabled", "no", "false":
 			enabled = false
 		default:
-			err = errors.Errorf("Invalid annotation value for key %q: %s", constants.SidecarInjectionAnnotation, inject)
+			err = errors.Errorf("Invalid annotation value for key %q: %s", annotation, inject)
 		}
 	}
 	return
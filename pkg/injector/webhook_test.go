@@ -2,6 +2,7 @@ package injector
 
 import (
 	"context"
+	"encoding/json"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -18,7 +19,9 @@ import (
 	admissionregv1 "k8s.io/api/admissionregistration/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/record"
 
 	"github.com/openservicemesh/osm/pkg/certificate"
 	"github.com/openservicemesh/osm/pkg/certificate/providers/tresor"
@@ -106,7 +109,7 @@ var _ = Describe("Testing isAnnotatedForInjection", func() {
 	Context("when the inject annotation is one of enabled/yes/true", func() {
 		It("should return true to enable sidecar injection", func() {
 			annotation := map[string]string{constants.SidecarInjectionAnnotation: "enabled"}
-			exists, enabled, err := isAnnotatedForInjection(annotation, "-kind-", "-name-")
+			exists, enabled, err := isAnnotatedForInjection(annotation, constants.SidecarInjectionAnnotation, "-kind-", "-name-")
 			Expect(exists).To(BeTrue())
 			Expect(enabled).To(BeTrue())
 			Expect(err).To(BeNil())
@@ -114,7 +117,7 @@ var _ = Describe("Testing isAnnotatedForInjection", func() {
 
 		It("should return true to enable sidecar injection", func() {
 			annotation := map[string]string{constants.SidecarInjectionAnnotation: "yes"}
-			exists, enabled, err := isAnnotatedForInjection(annotation, "-kind-", "-name-")
+			exists, enabled, err := isAnnotatedForInjection(annotation, constants.SidecarInjectionAnnotation, "-kind-", "-name-")
 			Expect(exists).To(BeTrue())
 			Expect(enabled).To(BeTrue())
 			Expect(err).To(BeNil())
@@ -122,7 +125,7 @@ var _ = Describe("Testing isAnnotatedForInjection", func() {
 
 		It("should return true to enable sidecar injection", func() {
 			annotation := map[string]string{constants.SidecarInjectionAnnotation: "true"}
-			exists, enabled, err := isAnnotatedForInjection(annotation, "-kind-", "-name-")
+			exists, enabled, err := isAnnotatedForInjection(annotation, constants.SidecarInjectionAnnotation, "-kind-", "-name-")
 			Expect(exists).To(BeTrue())
 			Expect(enabled).To(BeTrue())
 			Expect(err).To(BeNil())
@@ -132,7 +135,7 @@ var _ = Describe("Testing isAnnotatedForInjection", func() {
 	Context("when the inject annotation is one of disabled/no/false", func() {
 		It("should return false to disable sidecar injection", func() {
 			annotation := map[string]string{constants.SidecarInjectionAnnotation: "disabled"}
-			exists, enabled, err := isAnnotatedForInjection(annotation, "-kind-", "-name-")
+			exists, enabled, err := isAnnotatedForInjection(annotation, constants.SidecarInjectionAnnotation, "-kind-", "-name-")
 			Expect(exists).To(BeTrue())
 			Expect(enabled).To(BeFalse())
 			Expect(err).To(BeNil())
@@ -140,7 +143,7 @@ var _ = Describe("Testing isAnnotatedForInjection", func() {
 
 		It("should return false to disable sidecar injection", func() {
 			annotation := map[string]string{constants.SidecarInjectionAnnotation: "no"}
-			exists, enabled, err := isAnnotatedForInjection(annotation, "-kind-", "-name-")
+			exists, enabled, err := isAnnotatedForInjection(annotation, constants.SidecarInjectionAnnotation, "-kind-", "-name-")
 			Expect(exists).To(BeTrue())
 			Expect(enabled).To(BeFalse())
 			Expect(err).To(BeNil())
@@ -148,7 +151,7 @@ var _ = Describe("Testing isAnnotatedForInjection", func() {
 
 		It("should return false to disable sidecar injection", func() {
 			annotation := map[string]string{constants.SidecarInjectionAnnotation: "false"}
-			exists, enabled, err := isAnnotatedForInjection(annotation, "-kind-", "-name-")
+			exists, enabled, err := isAnnotatedForInjection(annotation, constants.SidecarInjectionAnnotation, "-kind-", "-name-")
 			Expect(exists).To(BeTrue())
 			Expect(enabled).To(BeFalse())
 			Expect(err).To(BeNil())
@@ -158,7 +161,7 @@ var _ = Describe("Testing isAnnotatedForInjection", func() {
 	Context("when the inject annotation does not exist", func() {
 		It("should return false to indicate the annotation does not exist", func() {
 			annotation := map[string]string{}
-			exists, enabled, err := isAnnotatedForInjection(annotation, "-kind-", "-name-")
+			exists, enabled, err := isAnnotatedForInjection(annotation, constants.SidecarInjectionAnnotation, "-kind-", "-name-")
 			Expect(exists).To(BeFalse())
 			Expect(enabled).To(BeFalse())
 			Expect(err).To(BeNil())
@@ -168,7 +171,7 @@ var _ = Describe("Testing isAnnotatedForInjection", func() {
 	Context("when an invalid inject annotation is specified", func() {
 		It("should return an error", func() {
 			annotation := map[string]string{constants.SidecarInjectionAnnotation: "invalid-value"}
-			_, _, err := isAnnotatedForInjection(annotation, "-kind-", "-name-")
+			_, _, err := isAnnotatedForInjection(annotation, constants.SidecarInjectionAnnotation, "-kind-", "-name-")
 			Expect(err).To(HaveOccurred())
 		})
 	})
@@ -228,7 +231,7 @@ var _ = Describe("Testing mustInject, isNamespaceInjectable", func() {
 		mockKubeController.EXPECT().IsMonitoredNamespace(namespace).Return(true).Times(1)
 		mockKubeController.EXPECT().GetNamespace(namespace).Return(retNs)
 
-		inject, err := wh.mustInject(podWithInjectAnnotationEnabled, namespace)
+		inject, _, err := wh.mustInject(podWithInjectAnnotationEnabled, namespace)
 
 		Expect(err).ToNot(HaveOccurred())
 		Expect(inject).To(BeTrue())
@@ -260,7 +263,7 @@ var _ = Describe("Testing mustInject, isNamespaceInjectable", func() {
 		mockKubeController.EXPECT().IsMonitoredNamespace(namespace).Return(true).Times(1)
 		mockKubeController.EXPECT().GetNamespace(namespace).Return(retNs)
 
-		inject, err := wh.mustInject(podWithInjectAnnotationEnabled, namespace)
+		inject, _, err := wh.mustInject(podWithInjectAnnotationEnabled, namespace)
 
 		Expect(err).ToNot(HaveOccurred())
 		Expect(inject).To(BeFalse())
@@ -292,7 +295,7 @@ var _ = Describe("Testing mustInject, isNamespaceInjectable", func() {
 		mockKubeController.EXPECT().IsMonitoredNamespace(namespace).Return(true).Times(1)
 		mockKubeController.EXPECT().GetNamespace(namespace).Return(retNs)
 
-		inject, err := wh.mustInject(podWithInjectAnnotationEnabled, namespace)
+		inject, _, err := wh.mustInject(podWithInjectAnnotationEnabled, namespace)
 
 		Expect(err).ToNot(HaveOccurred())
 		Expect(inject).To(BeTrue())
@@ -327,10 +330,49 @@ var _ = Describe("Testing mustInject, isNamespaceInjectable", func() {
 		mockKubeController.EXPECT().IsMonitoredNamespace(namespace).Return(true).Times(1)
 		mockKubeController.EXPECT().GetNamespace(namespace).Return(retNs)
 
-		inject, err := wh.mustInject(podWithInjectAnnotationEnabled, namespace)
+		inject, skipReason, err := wh.mustInject(podWithInjectAnnotationEnabled, namespace)
 
 		Expect(err).ToNot(HaveOccurred())
 		Expect(inject).To(BeFalse())
+		Expect(skipReason).To(Equal("sidecar injection is disabled for the pod by the annotation openservicemesh.io/sidecar-injection"))
+	})
+
+	It("should not patch a pod disabled for injection in a namespace enabled for injection", func() {
+		testNamespace := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: namespace,
+				Annotations: map[string]string{
+					constants.SidecarInjectionAnnotation: "enabled",
+				},
+			},
+		}
+		pod := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "debug-shell",
+				Namespace: namespace,
+				Annotations: map[string]string{
+					constants.SidecarInjectionAnnotation: "disabled",
+				},
+			},
+		}
+		podJSON, err := json.Marshal(pod)
+		Expect(err).ToNot(HaveOccurred())
+
+		recorder := record.NewFakeRecorder(1)
+		wh.eventRecorder = recorder
+		mockKubeController.EXPECT().IsMonitoredNamespace(namespace).Return(true).Times(1)
+		mockKubeController.EXPECT().GetNamespace(namespace).Return(testNamespace)
+
+		resp := wh.mutate(&admissionv1.AdmissionRequest{
+			UID:       "test-uid",
+			Namespace: namespace,
+			Object:    runtime.RawExtension{Raw: podJSON},
+		}, uuid.New())
+
+		Expect(resp.Allowed).To(BeTrue())
+		Expect(resp.Patch).To(BeNil())
+		Expect(resp.PatchType).To(BeNil())
+		Expect(recorder.Events).To(Receive(ContainSubstring("sidecar injection is disabled for the pod by the annotation openservicemesh.io/sidecar-injection")))
 	})
 
 	It("should return false when the pod's namespace is not being monitored", func() {
@@ -358,10 +400,11 @@ var _ = Describe("Testing mustInject, isNamespaceInjectable", func() {
 
 		mockKubeController.EXPECT().IsMonitoredNamespace(namespace).Return(false).Times(1)
 
-		inject, err := wh.mustInject(podWithInjectAnnotationEnabled, namespace)
+		inject, skipReason, err := wh.mustInject(podWithInjectAnnotationEnabled, namespace)
 
 		Expect(err).ToNot(HaveOccurred())
 		Expect(inject).To(BeFalse())
+		Expect(skipReason).To(Equal("namespace test is not part of the mesh"))
 	})
 
 	It("should return an error when an invalid annotation is specified", func() {
@@ -389,12 +432,52 @@ var _ = Describe("Testing mustInject, isNamespaceInjectable", func() {
 
 		mockKubeController.EXPECT().IsMonitoredNamespace(namespace).Return(true).Times(1)
 
-		inject, err := wh.mustInject(podWithInjectAnnotationEnabled, namespace)
+		inject, _, err := wh.mustInject(podWithInjectAnnotationEnabled, namespace)
 
 		Expect(err).To(HaveOccurred())
 		Expect(inject).To(BeFalse())
 	})
 
+	It("should honor the sidecar injection annotation with the configured annotation prefix only", func() {
+		wh.config.AnnotationPrefix = "example.com"
+		testNamespace := &corev1.Namespace{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: namespace,
+			},
+		}
+		retNs, err := fakeClientSet.CoreV1().Namespaces().Create(context.TODO(), testNamespace, metav1.CreateOptions{})
+		Expect(err).ToNot(HaveOccurred())
+
+		podWithCustomPrefix := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "pod-with-custom-prefix",
+				Annotations: map[string]string{
+					"example.com/sidecar-injection": "enabled",
+				},
+			},
+		}
+		podWithDefaultPrefix := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "pod-with-default-prefix",
+				Annotations: map[string]string{
+					constants.SidecarInjectionAnnotation: "enabled",
+				},
+			},
+		}
+
+		mockKubeController.EXPECT().IsMonitoredNamespace(namespace).Return(true).Times(2)
+		mockKubeController.EXPECT().GetNamespace(namespace).Return(retNs).Times(2)
+
+		inject, _, err := wh.mustInject(podWithCustomPrefix, namespace)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(inject).To(BeTrue())
+
+		inject, skipReason, err := wh.mustInject(podWithDefaultPrefix, namespace)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(inject).To(BeFalse())
+		Expect(skipReason).To(Equal("sidecar injection is not enabled for the pod or its namespace"))
+	})
+
 	It("Should allow a monitored app namespace", func() {
 		testNamespace := &corev1.Namespace{
 			ObjectMeta: metav1.ObjectMeta{
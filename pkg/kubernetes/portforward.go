@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"time"
 
 	"github.com/pkg/errors"
 	"k8s.io/apimachinery/pkg/util/httpstream"
@@ -42,6 +43,13 @@ func NewPortForwarder(dialer httpstream.Dialer, portSpec string) (*PortForwarder
 
 // Start starts the port forwarding and calls the readyFunc callback function when port forwarding is ready
 func (pf *PortForwarder) Start(readyFunc func(pf *PortForwarder) error) error {
+	return pf.StartWithTimeout(0, readyFunc)
+}
+
+// StartWithTimeout starts the port forwarding and calls the readyFunc callback function when port forwarding is ready.
+// The port forwarding is stopped and an error is returned if it is not ready within the given timeout, a timeout of 0
+// waits indefinitely.
+func (pf *PortForwarder) StartWithTimeout(timeout time.Duration, readyFunc func(pf *PortForwarder) error) error {
 	// Set up a channel to process OS signals
 	pf.sigChan = make(chan os.Signal, 1)
 	signal.Notify(pf.sigChan, os.Interrupt)
@@ -61,6 +69,14 @@ func (pf *PortForwarder) Start(readyFunc func(pf *PortForwarder) error) error {
 		pf.Stop()
 	}()
 
+	// A nil channel never receives, waiting indefinitely for port forwarding to be ready
+	var timeoutChan <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		timeoutChan = timer.C
+	}
+
 	// Process signals and call readyFunc
 	select {
 	case <-pf.readyChan:
@@ -68,6 +84,10 @@ func (pf *PortForwarder) Start(readyFunc func(pf *PortForwarder) error) error {
 
 	case err := <-errChan:
 		return errors.Errorf("Error during port forwarding: %s", err)
+
+	case <-timeoutChan:
+		pf.Stop()
+		return errors.Errorf("Timed out after %s waiting for port forwarding to be ready", timeout)
 	}
 }
 
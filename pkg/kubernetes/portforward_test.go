@@ -102,3 +102,35 @@ func TestPortForwardStoppedBySignal(t *testing.T) {
 	pf.sigChan <- os.Interrupt
 	<-pf.Done()
 }
+
+type blockingDialer struct {
+	unblock chan struct{}
+}
+
+func (d *blockingDialer) Dial(protocols ...string) (httpstream.Connection, string, error) {
+	<-d.unblock
+	return nil, "", errors.New("unblocked")
+}
+
+func TestPortForwardTimeout(t *testing.T) {
+	dialer := &blockingDialer{unblock: make(chan struct{})}
+	defer close(dialer.unblock)
+
+	pf, err := NewPortForwarder(dialer, ":80")
+	if err != nil {
+		t.Fatal("error creating PortForwarder:", err)
+	}
+
+	err = pf.StartWithTimeout(10*time.Millisecond, func(*PortForwarder) error {
+		t.Error("Expected PortForwarder not to become ready but it did")
+		return nil
+	})
+
+	if err == nil {
+		t.Fatal("Expected port forward to time out but it succeeded")
+	}
+	if !strings.Contains(err.Error(), "Timed out after 10ms waiting for port forwarding to be ready") {
+		t.Errorf("Expected timeout error, got %q", err)
+	}
+	<-pf.Done()
+}
@@ -0,0 +1,73 @@
+package smi
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiTrafficSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// HTTPRouteGroupKind is the kind of the SMI HTTPRouteGroup resource referenced by TrafficTarget rules
+	HTTPRouteGroupKind = "HTTPRouteGroup"
+
+	// TCPRouteKind is the kind of the SMI TCPRoute resource referenced by TrafficTarget rules
+	TCPRouteKind = "TCPRoute"
+
+	// ServiceAccountKind is the kind of the subjects of a TrafficTarget
+	ServiceAccountKind = "ServiceAccount"
+)
+
+// ValidateTrafficTargetReferences checks that the route resources referenced by the rules of the given TrafficTarget,
+// and the service accounts of its destination and sources exist. The returned error names each reference that does
+// not resolve to an existing resource.
+func ValidateTrafficTargetReferences(trafficTarget *smiAccess.TrafficTarget, kubeClient kubernetes.Interface, smiSpecClient smiTrafficSpecClient.Interface) error {
+	var dangling []string
+
+	for _, rule := range trafficTarget.Spec.Rules {
+		var err error
+		switch rule.Kind {
+		case HTTPRouteGroupKind:
+			_, err = smiSpecClient.SpecsV1alpha4().HTTPRouteGroups(trafficTarget.Namespace).Get(context.TODO(), rule.Name, metav1.GetOptions{})
+		case TCPRouteKind:
+			_, err = smiSpecClient.SpecsV1alpha4().TCPRoutes(trafficTarget.Namespace).Get(context.TODO(), rule.Name, metav1.GetOptions{})
+		default:
+			dangling = append(dangling, fmt.Sprintf("rule of unsupported kind %q %s/%s", rule.Kind, trafficTarget.Namespace, rule.Name))
+			continue
+		}
+		if apierrors.IsNotFound(err) {
+			dangling = append(dangling, fmt.Sprintf("%s %s/%s", rule.Kind, trafficTarget.Namespace, rule.Name))
+		} else if err != nil {
+			return errors.Errorf("Error fetching SMI %s %s/%s: %s", rule.Kind, trafficTarget.Namespace, rule.Name, err)
+		}
+	}
+
+	subjects := append([]smiAccess.IdentityBindingSubject{trafficTarget.Spec.Destination}, trafficTarget.Spec.Sources...)
+	for _, subject := range subjects {
+		namespace := subject.Namespace
+		if namespace == "" {
+			namespace = trafficTarget.Namespace
+		}
+		if subject.Kind != ServiceAccountKind {
+			dangling = append(dangling, fmt.Sprintf("subject of unsupported kind %q %s/%s", subject.Kind, namespace, subject.Name))
+			continue
+		}
+		_, err := kubeClient.CoreV1().ServiceAccounts(namespace).Get(context.TODO(), subject.Name, metav1.GetOptions{})
+		if apierrors.IsNotFound(err) {
+			dangling = append(dangling, fmt.Sprintf("%s %s/%s", ServiceAccountKind, namespace, subject.Name))
+		} else if err != nil {
+			return errors.Errorf("Error fetching %s %s/%s: %s", ServiceAccountKind, namespace, subject.Name, err)
+		}
+	}
+
+	if len(dangling) > 0 {
+		return errors.Errorf("TrafficTarget %s/%s references resources that do not exist: %s", trafficTarget.Namespace, trafficTarget.Name, strings.Join(dangling, ", "))
+	}
+	return nil
+}
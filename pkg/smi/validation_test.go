@@ -0,0 +1,75 @@
+package smi
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	smiAccess "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/access/v1alpha3"
+	smiSpecs "github.com/servicemeshinterface/smi-sdk-go/pkg/apis/specs/v1alpha4"
+	testTrafficSpecClient "github.com/servicemeshinterface/smi-sdk-go/pkg/gen/client/specs/clientset/versioned/fake"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testclient "k8s.io/client-go/kubernetes/fake"
+)
+
+var _ = Describe("When validating the references of a TrafficTarget", func() {
+	kubeClient := testclient.NewSimpleClientset(
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "bookstore", Namespace: "bookstore"}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "bookbuyer", Namespace: "bookbuyer"}},
+	)
+	smiSpecClient := testTrafficSpecClient.NewSimpleClientset(
+		&smiSpecs.HTTPRouteGroup{ObjectMeta: metav1.ObjectMeta{Name: "bookstore-routes", Namespace: "bookstore"}},
+		&smiSpecs.TCPRoute{ObjectMeta: metav1.ObjectMeta{Name: "bookstore-tcp", Namespace: "bookstore"}},
+	)
+
+	newTrafficTarget := func(rules []smiAccess.TrafficTargetRule, sources ...string) *smiAccess.TrafficTarget {
+		trafficTarget := &smiAccess.TrafficTarget{
+			ObjectMeta: metav1.ObjectMeta{Name: "bookstore", Namespace: "bookstore"},
+			Spec: smiAccess.TrafficTargetSpec{
+				Destination: smiAccess.IdentityBindingSubject{Kind: ServiceAccountKind, Name: "bookstore", Namespace: "bookstore"},
+				Rules:       rules,
+			},
+		}
+		for _, source := range sources {
+			trafficTarget.Spec.Sources = append(trafficTarget.Spec.Sources, smiAccess.IdentityBindingSubject{Kind: ServiceAccountKind, Name: source, Namespace: "bookbuyer"})
+		}
+		return trafficTarget
+	}
+
+	It("should accept a TrafficTarget whose references exist", func() {
+		trafficTarget := newTrafficTarget([]smiAccess.TrafficTargetRule{
+			{Kind: HTTPRouteGroupKind, Name: "bookstore-routes"},
+			{Kind: TCPRouteKind, Name: "bookstore-tcp"},
+		}, "bookbuyer")
+
+		Expect(ValidateTrafficTargetReferences(trafficTarget, kubeClient, smiSpecClient)).To(Succeed())
+	})
+
+	It("should name the rules referencing routes that do not exist", func() {
+		trafficTarget := newTrafficTarget([]smiAccess.TrafficTargetRule{
+			{Kind: HTTPRouteGroupKind, Name: "missing-routes"},
+			{Kind: TCPRouteKind, Name: "missing-tcp"},
+		}, "bookbuyer")
+
+		err := ValidateTrafficTargetReferences(trafficTarget, kubeClient, smiSpecClient)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("TrafficTarget bookstore/bookstore references resources that do not exist: HTTPRouteGroup bookstore/missing-routes, TCPRoute bookstore/missing-tcp"))
+	})
+
+	It("should name the service accounts that do not exist", func() {
+		trafficTarget := newTrafficTarget([]smiAccess.TrafficTargetRule{{Kind: HTTPRouteGroupKind, Name: "bookstore-routes"}}, "bookbuyer", "bookthief")
+		trafficTarget.Spec.Destination.Name = "bookstore-v2"
+
+		err := ValidateTrafficTargetReferences(trafficTarget, kubeClient, smiSpecClient)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("TrafficTarget bookstore/bookstore references resources that do not exist: ServiceAccount bookstore/bookstore-v2, ServiceAccount bookbuyer/bookthief"))
+	})
+
+	It("should reject references of unsupported kinds", func() {
+		trafficTarget := newTrafficTarget([]smiAccess.TrafficTargetRule{{Kind: "UDPRoute", Name: "bookstore-udp"}}, "bookbuyer")
+		trafficTarget.Spec.Sources[0].Kind = "Group"
+
+		err := ValidateTrafficTargetReferences(trafficTarget, kubeClient, smiSpecClient)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("TrafficTarget bookstore/bookstore references resources that do not exist: rule of unsupported kind \"UDPRoute\" bookstore/bookstore-udp, subject of unsupported kind \"Group\" bookbuyer/bookbuyer"))
+	})
+})